@@ -0,0 +1,177 @@
+/*
+block-signer keeps the blockchain secret key of a block publisher node out of the node's
+command line and config.
+
+It creates encrypted key files, which the node loads with -block-signer-key-file,
+and serves a key file to the node over a unix socket, which the node connects to with -block-signer-addr.
+*/
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/crypto"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+var help = fmt.Sprintf(`block-signer keeps the blockchain secret key of a block publisher node
+out of the node's command line and config.
+
+Commands:
+
+  keygen -key-file <file> -password-file <file> [-seckey-file <file>] [-crypto-type %s]
+	Encrypt the secret key of -seckey-file, or a new secret key, into a new key file and print its public key.
+	Load the key file in the node with -block-signer-key-file and -block-signer-password-file.
+
+  serve -key-file <file> -password-file <file> -addr <socket path>
+	Serve the secret key of the key file on a unix socket to a node started with -block-signer-addr.
+	The socket is only accessible to the user running block-signer, the node must run as the same user.
+`, crypto.DefaultCryptoType)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "%s\nUsage of %s:\n", help, os.Args[0])
+		flag.PrintDefaults()
+	}
+}
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "keygen":
+		err = keygenCmd(args)
+	case "serve":
+		err = serveCmd(args)
+	default:
+		err = fmt.Errorf("unknown command %q", cmd)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// readSecretFile reads a secret from a file, without the trailing newline
+func readSecretFile(filename string) ([]byte, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(b, "\r\n"), nil
+}
+
+func keygenCmd(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "key file to create")
+	passwordFile := fs.String("password-file", "", "file containing the password of the key file")
+	seckeyFile := fs.String("seckey-file", "", "file containing the hex encoded secret key to encrypt. A new secret key is generated if not set")
+	cryptoType := fs.String("crypto-type", string(crypto.DefaultCryptoType), "encryption of the key file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *keyFile == "" || *passwordFile == "" {
+		return fmt.Errorf("keygen requires -key-file and -password-file")
+	}
+
+	ct, err := crypto.CryptoTypeFromString(*cryptoType)
+	if err != nil {
+		return err
+	}
+
+	password, err := readSecretFile(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	var seckey cipher.SecKey
+	if *seckeyFile != "" {
+		b, err := readSecretFile(*seckeyFile)
+		if err != nil {
+			return err
+		}
+
+		seckey, err = cipher.SecKeyFromHex(strings.TrimSpace(string(b)))
+		if err != nil {
+			return err
+		}
+	} else {
+		_, seckey = cipher.GenerateKeyPair()
+	}
+
+	if err := visor.SaveBlockSignerKeyFile(*keyFile, seckey, password, ct); err != nil {
+		return err
+	}
+
+	fmt.Println(cipher.MustPubKeyFromSecKey(seckey).Hex())
+	return nil
+}
+
+func serveCmd(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	keyFile := fs.String("key-file", "", "key file created with keygen")
+	passwordFile := fs.String("password-file", "", "file containing the password of the key file")
+	addr := fs.String("addr", "", "path of the unix socket to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *keyFile == "" || *passwordFile == "" || *addr == "" {
+		return fmt.Errorf("serve requires -key-file, -password-file and -addr")
+	}
+
+	password, err := readSecretFile(*passwordFile)
+	if err != nil {
+		return err
+	}
+
+	signer, err := visor.LoadBlockSignerKeyFile(*keyFile, password)
+	if err != nil {
+		return err
+	}
+
+	pubkey, err := signer.PubKey()
+	if err != nil {
+		return err
+	}
+
+	l, err := visor.ListenBlockSigner(*addr)
+	if err != nil {
+		return err
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		<-quit
+		close(done)
+		l.Close()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving block signer %s on %s\n", pubkey.Hex(), *addr)
+
+	err = visor.ServeBlockSigner(l, signer)
+	select {
+	case <-done:
+		return nil
+	default:
+		return err
+	}
+}
@@ -0,0 +1,305 @@
+/*
+netcapture reads and replays captures of the messages exchanged by a skycoin node with its peers.
+
+A node records a capture when it is started with the -capture-file option.
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/capture"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+)
+
+const (
+	defaultConnectTimeout = "5s"
+	defaultWait           = "5s"
+)
+
+var (
+	maxMessageLength = gnet.NewConfig().MaxIncomingMessageLength
+
+	help = fmt.Sprintf(`netcapture reads and replays captures of the messages exchanged by a skycoin node with its peers.
+
+Record a capture by starting a node with -capture-file=<file>.
+
+Commands:
+
+  print [-conn id] [-type prefix] <file>
+	Print the messages in the capture as JSON, one message per line.
+
+  conns <file>
+	List the connections in the capture, with their message counts.
+
+  replay [-conn id] [-speed n] [-timeout %s] [-wait %s] [-v] <node ip:port> <file>
+	Connect to a node and send it the messages that were received on a connection in the capture,
+	reproducing that peer's side of the connection. -speed scales the delay between messages;
+	-speed=0 sends all of the messages without delay. With -v, the messages sent by the node are printed.
+`, defaultConnectTimeout, defaultWait)
+)
+
+func init() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "%s\nUsage of %s:\n", help, os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	c := daemon.NewMessagesConfig()
+	c.Register()
+}
+
+func main() {
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch cmd, args := flag.Arg(0), flag.Args()[1:]; cmd {
+	case "print":
+		err = printCmd(args)
+	case "conns":
+		err = connsCmd(args)
+	case "replay":
+		err = replayCmd(args)
+	default:
+		err = fmt.Errorf("unknown command %q", cmd)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func openCapture(filename string) (*capture.Reader, io.Closer, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := capture.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	return r, f, nil
+}
+
+func printCmd(args []string) error {
+	fs := flag.NewFlagSet("print", flag.ExitOnError)
+	connID := fs.Uint64("conn", 0, "only print messages of this connection ID")
+	msgType := fs.String("type", "", "only print messages with this prefix, e.g. GIVB")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("print requires a capture file")
+	}
+
+	r, c, err := openCapture(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if *connID != 0 && rec.ConnID != *connID {
+			continue
+		}
+		if *msgType != "" && rec.Prefix() != *msgType {
+			continue
+		}
+
+		if err := enc.Encode(capture.NewRecordJSON(rec)); err != nil {
+			return err
+		}
+	}
+}
+
+func connsCmd(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("conns requires a capture file")
+	}
+
+	records, err := capture.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	type conn struct {
+		addr     string
+		first    int64
+		last     int64
+		received int
+		sent     int
+	}
+
+	conns := make(map[uint64]*conn)
+	for _, r := range records {
+		c, ok := conns[r.ConnID]
+		if !ok {
+			c = &conn{
+				addr:  r.Addr,
+				first: r.Time,
+			}
+			conns[r.ConnID] = c
+		}
+		c.last = r.Time
+		if r.Sent {
+			c.sent++
+		} else {
+			c.received++
+		}
+	}
+
+	ids := capture.Connections(records)
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	fmt.Printf("%-8s\t%-25s\t%-8s\t%-8s\t%s\n", "Conn", "Address", "Received", "Sent", "Duration")
+	for _, id := range ids {
+		c := conns[id]
+		fmt.Printf("%-8d\t%-25s\t%-8d\t%-8d\t%v\n", id, c.addr, c.received, c.sent, time.Duration(c.last-c.first))
+	}
+
+	return nil
+}
+
+func replayCmd(args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	connID := fs.Uint64("conn", 0, "connection ID to replay. Defaults to the first connection in the capture")
+	speed := fs.Float64("speed", 1, "replay speed multiplier. 0 sends all messages without delay")
+	timeoutStr := fs.String("timeout", defaultConnectTimeout, "connect timeout")
+	waitStr := fs.String("wait", defaultWait, "how long to wait for the node's responses after the last message is sent")
+	verbose := fs.Bool("v", false, "print the messages sent by the node")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("replay requires a node address and a capture file")
+	}
+
+	timeout, err := time.ParseDuration(*timeoutStr)
+	if err != nil {
+		return fmt.Errorf("bad timeout %q: %v", *timeoutStr, err)
+	}
+	wait, err := time.ParseDuration(*waitStr)
+	if err != nil {
+		return fmt.Errorf("bad wait %q: %v", *waitStr, err)
+	}
+
+	records, err := capture.ReadFile(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if *connID == 0 {
+		ids := capture.Connections(records)
+		if len(ids) == 0 {
+			return fmt.Errorf("capture has no messages")
+		}
+		*connID = ids[0]
+	}
+
+	received := capture.Received(records, *connID)
+	if len(received) == 0 {
+		return fmt.Errorf("connection %d has no received messages to replay", *connID)
+	}
+
+	conn, err := net.DialTimeout("tcp", fs.Arg(0), timeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		readResponses(conn, *verbose)
+	}()
+
+	quit := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			close(quit)
+		case <-done:
+		}
+	}()
+
+	fmt.Fprintf(os.Stderr, "Replaying %d messages of connection %d to %s\n", len(received), *connID, fs.Arg(0))
+	if err := capture.Replay(conn, received, *speed, quit); err != nil {
+		return err
+	}
+
+	select {
+	case <-quit:
+	case <-done:
+		fmt.Fprintln(os.Stderr, "Node closed the connection")
+	case <-time.After(wait):
+	}
+
+	return nil
+}
+
+// readResponses reads messages from the node until the connection is closed, printing them if verbose
+func readResponses(conn net.Conn, verbose bool) {
+	enc := json.NewEncoder(os.Stdout)
+	for {
+		var n [4]byte
+		if _, err := io.ReadFull(conn, n[:]); err != nil {
+			return
+		}
+
+		length, _, err := encoder.DeserializeUint32(n[:])
+		if err != nil || int(length) > maxMessageLength {
+			fmt.Fprintf(os.Stderr, "Invalid message length %d\n", length)
+			return
+		}
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(conn, data); err != nil {
+			return
+		}
+
+		if !verbose {
+			continue
+		}
+
+		if err := enc.Encode(capture.NewRecordJSON(capture.Record{
+			Time:   time.Now().UTC().UnixNano(),
+			Addr:   conn.RemoteAddr().String(),
+			Sent:   false,
+			ConnID: 0,
+			Data:   data,
+		})); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
@@ -2,11 +2,18 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/readable"
 )
 
+// addressResourcesPrefix is the prefix of the URIs of the resources of an address,
+// /api/v2/address/{addr}/{resource}
+const addressResourcesPrefix = "/api/v2/address/"
+
 // VerifyAddressRequest is the request data for POST /api/v2/address/verify
 type VerifyAddressRequest struct {
 	Address string `json:"address"`
@@ -54,3 +61,78 @@ func addressVerifyHandler(w http.ResponseWriter, r *http.Request) {
 		},
 	})
 }
+
+// parseAddressResourcePath splits a /api/v2/address/{addr}/{resource} URI into the address and the resource.
+// Returns false if the URI does not have this form.
+func parseAddressResourcePath(path string) (string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(path, addressResourcesPrefix), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
+
+// addressResourcesHandler serves the resources of an address
+// URI: /api/v2/address/{addr}/{resource}
+// Resources:
+//
+//	stats: see addressStatsHandler
+func addressResourcesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		addrStr, resource, ok := parseAddressResourcePath(r.URL.Path)
+		if !ok {
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, ""))
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(addrStr)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		switch resource {
+		case "stats":
+			addressStatsHandler(w, r, gateway, addr)
+		default:
+			writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusNotFound, ""))
+		}
+	}
+}
+
+// addressStatsHandler returns the stats of an address: the first and last blocks with its transactions,
+// its number of transactions and the coins and hours that it received and sent.
+// Returns 404 if the address has no transactions.
+// Method: GET
+// URI: /api/v2/address/{addr}/stats
+func addressStatsHandler(w http.ResponseWriter, r *http.Request, gateway Gatewayer, addr cipher.Address) {
+	if r.Method != http.MethodGet {
+		resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	stats, err := gateway.GetAddressStats(addr)
+	if err != nil {
+		writeError500Response(w, err.Error())
+		return
+	}
+
+	if stats == nil {
+		resp := NewHTTPErrorResponse(http.StatusNotFound, "address has no transactions")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	rStats, err := readable.NewAddressStats(addr, *stats)
+	if err != nil {
+		writeError500Response(w, err.Error())
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: rStats,
+	})
+}
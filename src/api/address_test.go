@@ -2,12 +2,17 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/visor/historydb"
 )
 
 func toJSON(t *testing.T, r interface{}) string {
@@ -146,3 +151,144 @@ func TestVerifyAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestAddressStats(t *testing.T) {
+	addr := "2RxP5N26GhDqHrP6SK45ZzEMSmSpeUeWxsS"
+	stats := &historydb.AddressStats{
+		FirstBlockSeq: 1,
+		LastBlockSeq:  10,
+		TxnCount:      3,
+		CoinsReceived: 10e6,
+		HoursReceived: 100,
+		CoinsSent:     1500000,
+		HoursSent:     20,
+	}
+
+	cases := []struct {
+		name               string
+		method             string
+		endpoint           string
+		status             int
+		getAddressStatsArg cipher.Address
+		getAddressStatsRet *historydb.AddressStats
+		getAddressStatsErr error
+		httpResponse       HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			endpoint:     "/api/v2/address/" + addr + "/stats",
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+
+		{
+			name:         "400 - invalid address",
+			method:       http.MethodGet,
+			endpoint:     "/api/v2/address/foo/stats",
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid address: Invalid address length"),
+		},
+
+		{
+			name:         "404 - unknown resource",
+			method:       http.MethodGet,
+			endpoint:     "/api/v2/address/" + addr + "/foo",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+
+		{
+			name:         "404 - missing resource",
+			method:       http.MethodGet,
+			endpoint:     "/api/v2/address/" + addr,
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+
+		{
+			name:         "404 - extra path",
+			method:       http.MethodGet,
+			endpoint:     "/api/v2/address/" + addr + "/stats/foo",
+			status:       http.StatusNotFound,
+			httpResponse: NewHTTPErrorResponse(http.StatusNotFound, ""),
+		},
+
+		{
+			name:               "404 - address has no transactions",
+			method:             http.MethodGet,
+			endpoint:           "/api/v2/address/" + addr + "/stats",
+			status:             http.StatusNotFound,
+			getAddressStatsArg: cipher.MustDecodeBase58Address(addr),
+			httpResponse:       NewHTTPErrorResponse(http.StatusNotFound, "address has no transactions"),
+		},
+
+		{
+			name:               "500 - gateway error",
+			method:             http.MethodGet,
+			endpoint:           "/api/v2/address/" + addr + "/stats",
+			status:             http.StatusInternalServerError,
+			getAddressStatsArg: cipher.MustDecodeBase58Address(addr),
+			getAddressStatsErr: errors.New("GetAddressStats failed"),
+			httpResponse:       NewHTTPErrorResponse(http.StatusInternalServerError, "GetAddressStats failed"),
+		},
+
+		{
+			name:               "200",
+			method:             http.MethodGet,
+			endpoint:           "/api/v2/address/" + addr + "/stats",
+			status:             http.StatusOK,
+			getAddressStatsArg: cipher.MustDecodeBase58Address(addr),
+			getAddressStatsRet: stats,
+			httpResponse: HTTPResponse{
+				Data: readable.AddressStats{
+					Address:       addr,
+					FirstBlockSeq: 1,
+					LastBlockSeq:  10,
+					TxnCount:      3,
+					CoinsReceived: "10.000000",
+					HoursReceived: 100,
+					CoinsSent:     "1.500000",
+					HoursSent:     20,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetAddressStats", tc.getAddressStatsArg).Return(tc.getAddressStatsRet, tc.getAddressStatsErr)
+
+			req, err := http.NewRequest(tc.method, tc.endpoint, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var statsRsp readable.AddressStats
+				err := json.Unmarshal(rsp.Data, &statsRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(readable.AddressStats), statsRsp)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package api
+
+// APIs for API keys
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// contextKey is the type of the request context keys of the package
+type contextKey string
+
+// apiKeyIDContextKey is the request context key of the ID of the API key that authenticated the request
+const apiKeyIDContextKey contextKey = "apiKeyID"
+
+// APIKeyResponse is an API key, without the hash of its secret
+type APIKeyResponse struct {
+	ID        string   `json:"id"`
+	Label     string   `json:"label"`
+	APISets   []string `json:"api_sets"`
+	Wallets   []string `json:"wallets"`
+	CreatedAt int64    `json:"created_at"`
+	ExpiresAt int64    `json:"expires_at"`
+	RevokedAt int64    `json:"revoked_at"`
+	Active    bool     `json:"active"`
+}
+
+// NewAPIKeyResponse creates an APIKeyResponse
+func NewAPIKeyResponse(k APIKey) APIKeyResponse {
+	wallets := k.Wallets
+	if wallets == nil {
+		wallets = []string{}
+	}
+
+	return APIKeyResponse{
+		ID:        k.ID,
+		Label:     k.Label,
+		APISets:   k.APISets,
+		Wallets:   wallets,
+		CreatedAt: k.CreatedAt,
+		ExpiresAt: k.ExpiresAt,
+		RevokedAt: k.RevokedAt,
+		Active:    k.Active(time.Now()),
+	}
+}
+
+// APIKeyCreateRequest is the request data for POST /api/v2/apikeys
+type APIKeyCreateRequest struct {
+	Label   string   `json:"label"`
+	APISets []string `json:"api_sets"`
+	Wallets []string `json:"wallets"`
+	// ExpiresIn is the lifetime of the key in seconds, the key does not expire if zero
+	ExpiresIn uint64 `json:"expires_in"`
+}
+
+// APIKeyCreateResponse is the response data for POST /api/v2/apikeys
+type APIKeyCreateResponse struct {
+	Key APIKeyResponse `json:"key"`
+	// Secret is only returned when the key is created
+	Secret string `json:"secret"`
+}
+
+// APIKeyRevokeRequest is the request data for POST /api/v2/apikeys/revoke
+type APIKeyRevokeRequest struct {
+	ID string `json:"id"`
+}
+
+// Lists the API keys, or creates an API key.
+// API keys are only available with -web-interface-api-keys, and can only be managed
+// with the web interface username and password.
+// Method: GET, POST
+// URI: /api/v2/apikeys
+// Args (POST):
+//     label: label of the key [optional]
+//     api_sets: API sets that the key can access [required]
+//     wallets: wallets that the key can access, all wallets if empty [optional]
+//     expires_in: lifetime of the key in seconds, the key does not expire if zero [optional]
+func apiKeysHandler(c muxConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if c.apiKeys == nil {
+			resp := NewHTTPErrorResponse(http.StatusForbidden, "Endpoint is disabled")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			keys := c.apiKeys.Keys()
+			resp := make([]APIKeyResponse, len(keys))
+			for i, k := range keys {
+				resp[i] = NewAPIKeyResponse(k)
+			}
+
+			writeHTTPResponse(w, HTTPResponse{
+				Data: resp,
+			})
+			return
+		}
+
+		var req APIKeyCreateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		p := APIKeyParams{
+			Label:   req.Label,
+			APISets: req.APISets,
+			Wallets: req.Wallets,
+		}
+
+		if err := p.Validate(); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ExpiresIn != 0 {
+			p.ExpiresAt = time.Now().Add(time.Duration(req.ExpiresIn) * time.Second)
+		}
+
+		k, secret, err := c.apiKeys.Create(p)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		logger.WithField("id", k.ID).Infof("Created API key with API sets %v", k.APISets)
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: APIKeyCreateResponse{
+				Key:    NewAPIKeyResponse(*k),
+				Secret: secret,
+			},
+		})
+	}
+}
+
+// Revokes an API key. Requests made with the key are rejected from then on.
+// Method: POST
+// URI: /api/v2/apikeys/revoke
+// Args:
+//     id: API key id [required]
+func apiKeyRevokeHandler(c muxConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if c.apiKeys == nil {
+			resp := NewHTTPErrorResponse(http.StatusForbidden, "Endpoint is disabled")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req APIKeyRevokeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		k, err := c.apiKeys.Revoke(req.ID)
+		if err != nil {
+			var resp HTTPResponse
+			switch err {
+			case ErrAPIKeyNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			case ErrAPIKeyRevoked:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		logger.WithField("id", k.ID).Info("Revoked API key")
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: NewAPIKeyResponse(*k),
+		})
+	}
+}
+
+// apiKeyMuxes serves the requests made with API keys. Each key has its own http.ServeMux,
+// limited to the API sets and the wallets of the key.
+type apiKeyMuxes struct {
+	sync.Mutex
+	c       muxConfig
+	gateway Gatewayer
+	muxes   map[string]*http.ServeMux
+}
+
+// mux returns the http.ServeMux of an API key, creating it on first use
+func (m *apiKeyMuxes) mux(k APIKey) *http.ServeMux {
+	m.Lock()
+	defer m.Unlock()
+
+	if mux, ok := m.muxes[k.ID]; ok {
+		return mux
+	}
+
+	c := m.c
+	// The key is authenticated before reaching its mux, and can't manage API keys
+	c.username = ""
+	c.password = ""
+	c.apiKeys = nil
+
+	// The key can only access the API sets that are enabled on the node
+	c.enabledAPISets = make(map[string]struct{}, len(k.APISets))
+	for _, s := range k.APISets {
+		if _, ok := m.c.enabledAPISets[s]; ok {
+			c.enabledAPISets[s] = struct{}{}
+		}
+	}
+
+	var gateway Gatewayer = m.gateway
+	if len(k.Wallets) != 0 {
+		gateway = newWalletScopedGateway(m.gateway, k)
+	}
+
+	mux := newServerMux(c, gateway)
+	m.muxes[k.ID] = mux
+	return mux
+}
+
+// apiKeyAuth serves requests authenticated with an API key with the mux of the key.
+// Other requests are served by f, which authenticates them with the web interface username and password.
+// API keys are sent as the username and password of an "Authorization: Basic" header,
+// with the key id as username and the key secret as password.
+func apiKeyAuth(c muxConfig, gateway Gatewayer, f http.Handler) http.HandlerFunc {
+	muxes := &apiKeyMuxes{
+		c:       c,
+		gateway: gateway,
+		muxes:   make(map[string]*http.ServeMux),
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user == c.username {
+			f.ServeHTTP(w, r)
+			return
+		}
+
+		k, ok := c.apiKeys.Authenticate(user, pass)
+		if !ok {
+			// f rejects the request
+			f.ServeHTTP(w, r)
+			return
+		}
+
+		// The mux of the key does not authenticate requests,
+		// the key is identified by the request context instead
+		r = r.Clone(context.WithValue(r.Context(), apiKeyIDContextKey, k.ID))
+		r.Header.Del("Authorization")
+
+		muxes.mux(*k).ServeHTTP(w, r)
+	}
+}
+
+// newServerHandler creates the http.Handler of the server, adding API key
+// authentication to the http.ServeMux of newServerMux if API keys are enabled
+func newServerHandler(c muxConfig, gateway Gatewayer) http.Handler {
+	mux := newServerMux(c, gateway)
+	if c.apiKeys == nil {
+		return mux
+	}
+
+	return apiKeyAuth(c, gateway, mux)
+}
@@ -0,0 +1,294 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// ErrAPIKeyWalletScope is returned when an API key that is limited to specific wallets
+// is used to create or import wallets
+var ErrAPIKeyWalletScope = wallet.NewError(errors.New("API key is limited to specific wallets"))
+
+// walletScopedGateway is a Gatewayer that only gives access to the wallets of an API key.
+// Other wallets are reported as not existing.
+// Every Gatewayer method operating on wallets must be overridden here.
+type walletScopedGateway struct {
+	Gatewayer
+	key APIKey
+}
+
+func newWalletScopedGateway(gateway Gatewayer, key APIKey) *walletScopedGateway {
+	return &walletScopedGateway{
+		Gatewayer: gateway,
+		key:       key,
+	}
+}
+
+func (gw *walletScopedGateway) checkWallet(wltID string) error {
+	if !gw.key.HasWallet(wltID) {
+		return wallet.ErrWalletNotExist
+	}
+	return nil
+}
+
+// Visorer methods
+
+// GetWalletUnconfirmedTransactions implements Visorer
+func (gw *walletScopedGateway) GetWalletUnconfirmedTransactions(wltID string) ([]visor.UnconfirmedTransaction, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.GetWalletUnconfirmedTransactions(wltID)
+}
+
+// GetWalletUnconfirmedTransactionsVerbose implements Visorer
+func (gw *walletScopedGateway) GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, nil, err
+	}
+	return gw.Gatewayer.GetWalletUnconfirmedTransactionsVerbose(wltID)
+}
+
+// GetWalletHistory implements Visorer
+func (gw *walletScopedGateway) GetWalletHistory(wltID string, order visor.SortOrder, page *visor.PageIndex) ([]visor.WalletTransaction, uint64, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, 0, err
+	}
+	return gw.Gatewayer.GetWalletHistory(wltID, order, page)
+}
+
+// GetWalletBalance implements Visorer
+func (gw *walletScopedGateway) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return wallet.BalancePair{}, nil, err
+	}
+	return gw.Gatewayer.GetWalletBalance(wltID)
+}
+
+// GetWalletAccountBalance implements Visorer
+func (gw *walletScopedGateway) GetWalletAccountBalance(wltID string, account uint32) (wallet.BalancePair, wallet.AddressBalances, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return wallet.BalancePair{}, nil, err
+	}
+	return gw.Gatewayer.GetWalletAccountBalance(wltID, account)
+}
+
+// GetWalletBalanceAt implements Visorer
+func (gw *walletScopedGateway) GetWalletBalanceAt(wltID string, at visor.BlockAt) (*visor.HistoricalBalance, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.GetWalletBalanceAt(wltID, at)
+}
+
+// GetWalletBalanceSeries implements Visorer
+func (gw *walletScopedGateway) GetWalletBalanceSeries(wltID string, q visor.BalanceSeriesQuery) ([]visor.BalanceSeriesPoint, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.GetWalletBalanceSeries(wltID, q)
+}
+
+// WalletCreateTransaction implements Visorer
+func (gw *walletScopedGateway) WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, nil, err
+	}
+	return gw.Gatewayer.WalletCreateTransaction(wltID, p, wp)
+}
+
+// WalletCreateTransactionSigned implements Visorer
+func (gw *walletScopedGateway) WalletCreateTransactionSigned(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, nil, err
+	}
+	return gw.Gatewayer.WalletCreateTransactionSigned(wltID, password, p, wp)
+}
+
+// WalletSignTransaction implements Visorer
+func (gw *walletScopedGateway) WalletSignTransaction(wltID string, password []byte, txn *coin.Transaction, signIndexes []int) (*coin.Transaction, []visor.TransactionInput, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, nil, err
+	}
+	return gw.Gatewayer.WalletSignTransaction(wltID, password, txn, signIndexes)
+}
+
+// ScanWalletAddresses implements Visorer
+func (gw *walletScopedGateway) ScanWalletAddresses(wltID string, password []byte, num uint64) ([]cipher.Address, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.ScanWalletAddresses(wltID, password, num)
+}
+
+// Walleter methods
+
+// UnloadWallet implements Walleter
+func (gw *walletScopedGateway) UnloadWallet(wltID string) error {
+	if err := gw.checkWallet(wltID); err != nil {
+		return err
+	}
+	return gw.Gatewayer.UnloadWallet(wltID)
+}
+
+// EncryptWallet implements Walleter
+func (gw *walletScopedGateway) EncryptWallet(wltID string, password []byte) (wallet.Wallet, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.EncryptWallet(wltID, password)
+}
+
+// DecryptWallet implements Walleter
+func (gw *walletScopedGateway) DecryptWallet(wltID string, password []byte) (wallet.Wallet, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.DecryptWallet(wltID, password)
+}
+
+// GetWalletSeed implements Walleter
+func (gw *walletScopedGateway) GetWalletSeed(wltID string, password []byte) (string, string, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return "", "", err
+	}
+	return gw.Gatewayer.GetWalletSeed(wltID, password)
+}
+
+// CreateWallet implements Walleter
+func (gw *walletScopedGateway) CreateWallet(wltName string, options wallet.Options) (wallet.Wallet, error) {
+	return nil, ErrAPIKeyWalletScope
+}
+
+// RecoverWallet implements Walleter
+func (gw *walletScopedGateway) RecoverWallet(wltID, seed, seedPassphrase string, password []byte) (wallet.Wallet, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.RecoverWallet(wltID, seed, seedPassphrase, password)
+}
+
+// NewAddresses implements Walleter
+func (gw *walletScopedGateway) NewAddresses(wltID string, password []byte, options ...wallet.Option) ([]cipher.Address, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.NewAddresses(wltID, password, options...)
+}
+
+// ScanAddresses implements Walleter
+func (gw *walletScopedGateway) ScanAddresses(wltID string, password []byte, n uint64, tf wallet.TransactionsFinder) ([]cipher.Address, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.ScanAddresses(wltID, password, n, tf)
+}
+
+// GetWallet implements Walleter
+func (gw *walletScopedGateway) GetWallet(wltID string) (wallet.Wallet, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.GetWallet(wltID)
+}
+
+// GetWallets implements Walleter, returning the wallets of the API key only
+func (gw *walletScopedGateway) GetWallets() (wallet.Wallets, error) {
+	wlts, err := gw.Gatewayer.GetWallets()
+	if err != nil {
+		return nil, err
+	}
+
+	for id := range wlts {
+		if !gw.key.HasWallet(id) {
+			delete(wlts, id)
+		}
+	}
+
+	return wlts, nil
+}
+
+// UpdateWalletLabel implements Walleter
+func (gw *walletScopedGateway) UpdateWalletLabel(wltID, label string) error {
+	if err := gw.checkWallet(wltID); err != nil {
+		return err
+	}
+	return gw.Gatewayer.UpdateWalletLabel(wltID, label)
+}
+
+// CreateAccount implements Walleter
+func (gw *walletScopedGateway) CreateAccount(wltID string, password []byte, name string) (wallet.Bip44Account, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return wallet.Bip44Account{}, err
+	}
+	return gw.Gatewayer.CreateAccount(wltID, password, name)
+}
+
+// UpdateAccountName implements Walleter
+func (gw *walletScopedGateway) UpdateAccountName(wltID string, account uint32, name string) error {
+	if err := gw.checkWallet(wltID); err != nil {
+		return err
+	}
+	return gw.Gatewayer.UpdateAccountName(wltID, account, name)
+}
+
+// UpdateAddressMeta implements Walleter
+func (gw *walletScopedGateway) UpdateAddressMeta(wltID string, addr cipher.Address, m wallet.EntryMeta) error {
+	if err := gw.checkWallet(wltID); err != nil {
+		return err
+	}
+	return gw.Gatewayer.UpdateAddressMeta(wltID, addr, m)
+}
+
+// SetSpendingPolicy implements Walleter
+func (gw *walletScopedGateway) SetSpendingPolicy(wltID string, password []byte, p *wallet.SpendingPolicy) (*wallet.SpendingPolicy, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.SetSpendingPolicy(wltID, password, p)
+}
+
+// GetSpendingPolicy implements Walleter
+func (gw *walletScopedGateway) GetSpendingPolicy(wltID string) (*wallet.SpendingPolicy, []wallet.PolicySpend, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, nil, err
+	}
+	return gw.Gatewayer.GetSpendingPolicy(wltID)
+}
+
+// UnlockWallet implements Walleter
+func (gw *walletScopedGateway) UnlockWallet(wltID string, password []byte, timeout time.Duration) (*wallet.UnlockSession, error) {
+	if err := gw.checkWallet(wltID); err != nil {
+		return nil, err
+	}
+	return gw.Gatewayer.UnlockWallet(wltID, password, timeout)
+}
+
+// LockWallet implements Walleter
+func (gw *walletScopedGateway) LockWallet(wltID string) error {
+	if err := gw.checkWallet(wltID); err != nil {
+		return err
+	}
+	return gw.Gatewayer.LockWallet(wltID)
+}
+
+// ExportWallets implements Walleter
+func (gw *walletScopedGateway) ExportWallets(wltIDs []string, notes map[string]map[string]string, password []byte) ([]byte, error) {
+	for _, id := range wltIDs {
+		if err := gw.checkWallet(id); err != nil {
+			return nil, err
+		}
+	}
+	return gw.Gatewayer.ExportWallets(wltIDs, notes, password)
+}
+
+// ImportWallets implements Walleter
+func (gw *walletScopedGateway) ImportWallets(data, password []byte, conflict wallet.ImportConflict, walletPasswords map[string][]byte) (*wallet.ImportResult, error) {
+	return nil, ErrAPIKeyWalletScope
+}
@@ -0,0 +1,289 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+const (
+	// apiKeyIDPrefix is the prefix of API key IDs
+	apiKeyIDPrefix = "ak_"
+	// apiKeyIDSize is the number of random bytes of an API key ID
+	apiKeyIDSize = 8
+	// apiKeySecretSize is the number of random bytes of an API key secret
+	apiKeySecretSize = 32
+	// apiKeysFileMode is the file mode of the API keys file
+	apiKeysFileMode = 0600
+	// maxAPIKeyLabelLen is the maximum length of an API key label
+	maxAPIKeyLabelLen = 100
+)
+
+var (
+	// ErrAPIKeyNotExist is returned when an API key does not exist
+	ErrAPIKeyNotExist = errors.New("API key does not exist")
+	// ErrAPIKeyRevoked is returned when revoking an API key that is already revoked
+	ErrAPIKeyRevoked = errors.New("API key is already revoked")
+
+	// apiKeyAPISets are the API sets that can be granted to an API key
+	apiKeyAPISets = []string{
+		EndpointsRead,
+		EndpointsStatus,
+		EndpointsTransaction,
+		EndpointsWallet,
+		EndpointsInsecureWalletSeed,
+		EndpointsNetCtrl,
+		EndpointsStorage,
+		EndpointsPrometheus,
+	}
+)
+
+// APIKey is an API key. Only the hash of its secret is stored.
+type APIKey struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	// Hash is the hex encoded SHA256 hash of the secret
+	Hash string `json:"hash"`
+	// APISets are the API sets that the key can access, if they are enabled on the node
+	APISets []string `json:"api_sets"`
+	// Wallets are the wallets that the key can access. If empty, the key can access all wallets.
+	Wallets   []string `json:"wallets,omitempty"`
+	CreatedAt int64    `json:"created_at"`
+	// ExpiresAt is the unix time when the key expires, zero if the key does not expire
+	ExpiresAt int64 `json:"expires_at,omitempty"`
+	// RevokedAt is the unix time when the key was revoked, zero if the key is not revoked
+	RevokedAt int64 `json:"revoked_at,omitempty"`
+}
+
+// Active returns true if the key is not revoked and not expired at t
+func (k APIKey) Active(t time.Time) bool {
+	if k.RevokedAt != 0 {
+		return false
+	}
+	return k.ExpiresAt == 0 || t.Unix() < k.ExpiresAt
+}
+
+// HasWallet returns true if the key can access the wallet
+func (k APIKey) HasWallet(wltID string) bool {
+	if len(k.Wallets) == 0 {
+		return true
+	}
+
+	for _, w := range k.Wallets {
+		if w == wltID {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyParams are the params of a new API key
+type APIKeyParams struct {
+	Label     string
+	APISets   []string
+	Wallets   []string
+	ExpiresAt time.Time
+}
+
+// Validate validates APIKeyParams
+func (p APIKeyParams) Validate() error {
+	if len(p.Label) > maxAPIKeyLabelLen {
+		return fmt.Errorf("label exceeds %d characters", maxAPIKeyLabelLen)
+	}
+
+	if len(p.APISets) == 0 {
+		return errors.New("api_sets is required")
+	}
+
+	seen := make(map[string]struct{}, len(p.APISets))
+	for _, s := range p.APISets {
+		valid := false
+		for _, k := range apiKeyAPISets {
+			if s == k {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid API set %q", s)
+		}
+
+		if _, ok := seen[s]; ok {
+			return fmt.Errorf("duplicate API set %q", s)
+		}
+		seen[s] = struct{}{}
+	}
+
+	seen = make(map[string]struct{}, len(p.Wallets))
+	for _, w := range p.Wallets {
+		if w == "" {
+			return errors.New("empty wallet id")
+		}
+
+		if _, ok := seen[w]; ok {
+			return fmt.Errorf("duplicate wallet %q", w)
+		}
+		seen[w] = struct{}{}
+	}
+
+	return nil
+}
+
+// APIKeyStore stores API keys in a JSON file
+type APIKeyStore struct {
+	sync.RWMutex
+	filename string
+	keys     map[string]APIKey
+}
+
+// NewAPIKeyStore creates an APIKeyStore, loading the keys of filename if it exists
+func NewAPIKeyStore(filename string) (*APIKeyStore, error) {
+	s := &APIKeyStore{
+		filename: filename,
+		keys:     make(map[string]APIKey),
+	}
+
+	exists, err := file.Exists(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return s, nil
+	}
+
+	var keys []APIKey
+	if err := file.LoadJSON(filename, &keys); err != nil {
+		return nil, fmt.Errorf("load API keys file %s failed: %v", filename, err)
+	}
+
+	for _, k := range keys {
+		s.keys[k.ID] = k
+	}
+
+	return s, nil
+}
+
+// Create creates an API key and returns it with its secret. The secret is not stored
+// and can't be recovered.
+func (s *APIKeyStore) Create(p APIKeyParams) (*APIKey, string, error) {
+	if err := p.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	now := time.Now()
+	if !p.ExpiresAt.IsZero() && !p.ExpiresAt.After(now) {
+		return nil, "", errors.New("expiry must be in the future")
+	}
+
+	secret := hex.EncodeToString(cipher.RandByte(apiKeySecretSize))
+	hash := cipher.SumSHA256([]byte(secret))
+
+	k := APIKey{
+		ID:        apiKeyIDPrefix + hex.EncodeToString(cipher.RandByte(apiKeyIDSize)),
+		Label:     p.Label,
+		Hash:      hash.Hex(),
+		APISets:   append([]string(nil), p.APISets...),
+		Wallets:   append([]string(nil), p.Wallets...),
+		CreatedAt: now.Unix(),
+	}
+	if !p.ExpiresAt.IsZero() {
+		k.ExpiresAt = p.ExpiresAt.Unix()
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.keys[k.ID] = k
+	if err := s.save(); err != nil {
+		delete(s.keys, k.ID)
+		return nil, "", err
+	}
+
+	return &k, secret, nil
+}
+
+// Revoke revokes an API key
+func (s *APIKeyStore) Revoke(id string) (*APIKey, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, ErrAPIKeyNotExist
+	}
+
+	if k.RevokedAt != 0 {
+		return nil, ErrAPIKeyRevoked
+	}
+
+	prev := k
+	k.RevokedAt = time.Now().Unix()
+	s.keys[id] = k
+
+	if err := s.save(); err != nil {
+		s.keys[id] = prev
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// Keys returns the API keys sorted by creation time
+func (s *APIKeyStore) Keys() []APIKey {
+	s.RLock()
+	defer s.RUnlock()
+
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt == keys[j].CreatedAt {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt < keys[j].CreatedAt
+	})
+
+	return keys
+}
+
+// Authenticate returns the API key with the given ID if the secret matches and the key is active
+func (s *APIKeyStore) Authenticate(id, secret string) (*APIKey, bool) {
+	s.RLock()
+	k, ok := s.keys[id]
+	s.RUnlock()
+
+	if !ok || !k.Active(time.Now()) {
+		return nil, false
+	}
+
+	hash := cipher.SumSHA256([]byte(secret))
+	if subtle.ConstantTimeCompare([]byte(hash.Hex()), []byte(k.Hash)) != 1 {
+		return nil, false
+	}
+
+	return &k, true
+}
+
+// save writes the keys to the file. The lock must be held.
+func (s *APIKeyStore) save() error {
+	keys := make([]APIKey, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ID < keys[j].ID
+	})
+
+	return file.SaveJSON(s.filename, keys, apiKeysFileMode)
+}
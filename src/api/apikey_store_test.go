@@ -0,0 +1,184 @@
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAPIKeyStore(t *testing.T) (*APIKeyStore, func()) {
+	dir, err := ioutil.TempDir("", "apikeys")
+	require.NoError(t, err)
+
+	s, err := NewAPIKeyStore(filepath.Join(dir, "apikeys.json"))
+	require.NoError(t, err)
+
+	return s, func() {
+		os.RemoveAll(dir) //nolint:errcheck
+	}
+}
+
+func TestAPIKeyParamsValidate(t *testing.T) {
+	tt := []struct {
+		name string
+		p    APIKeyParams
+		err  string
+	}{
+		{
+			name: "ok",
+			p: APIKeyParams{
+				Label:   "dashboard",
+				APISets: []string{EndpointsRead, EndpointsStatus},
+				Wallets: []string{"foo.wlt"},
+			},
+		},
+		{
+			name: "label too long",
+			p: APIKeyParams{
+				Label:   string(make([]byte, maxAPIKeyLabelLen+1)),
+				APISets: []string{EndpointsRead},
+			},
+			err: "label exceeds 100 characters",
+		},
+		{
+			name: "no api sets",
+			err:  "api_sets is required",
+		},
+		{
+			name: "invalid api set",
+			p: APIKeyParams{
+				APISets: []string{"FOO"},
+			},
+			err: `invalid API set "FOO"`,
+		},
+		{
+			name: "duplicate api set",
+			p: APIKeyParams{
+				APISets: []string{EndpointsRead, EndpointsRead},
+			},
+			err: `duplicate API set "READ"`,
+		},
+		{
+			name: "empty wallet",
+			p: APIKeyParams{
+				APISets: []string{EndpointsWallet},
+				Wallets: []string{""},
+			},
+			err: "empty wallet id",
+		},
+		{
+			name: "duplicate wallet",
+			p: APIKeyParams{
+				APISets: []string{EndpointsWallet},
+				Wallets: []string{"foo.wlt", "foo.wlt"},
+			},
+			err: `duplicate wallet "foo.wlt"`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.p.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestAPIKeyStore(t *testing.T) {
+	s, cleanup := newTestAPIKeyStore(t)
+	defer cleanup()
+
+	require.Empty(t, s.Keys())
+
+	k1, secret1, err := s.Create(APIKeyParams{
+		Label:   "dashboard",
+		APISets: []string{EndpointsRead},
+	})
+	require.NoError(t, err)
+	require.Len(t, secret1, 2*apiKeySecretSize)
+	require.Equal(t, "dashboard", k1.Label)
+	require.Equal(t, []string{EndpointsRead}, k1.APISets)
+	require.NotEqual(t, secret1, k1.Hash)
+	require.Zero(t, k1.ExpiresAt)
+
+	k2, secret2, err := s.Create(APIKeyParams{
+		Label:     "payouts",
+		APISets:   []string{EndpointsWallet},
+		Wallets:   []string{"payouts.wlt"},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, k1.ID, k2.ID)
+	require.NotEqual(t, secret1, secret2)
+	require.NotZero(t, k2.ExpiresAt)
+
+	_, _, err = s.Create(APIKeyParams{
+		APISets:   []string{EndpointsRead},
+		ExpiresAt: time.Now().Add(-time.Second),
+	})
+	require.EqualError(t, err, "expiry must be in the future")
+
+	// Authenticate
+	k, ok := s.Authenticate(k1.ID, secret1)
+	require.True(t, ok)
+	require.Equal(t, k1, k)
+
+	_, ok = s.Authenticate(k1.ID, secret2)
+	require.False(t, ok)
+	_, ok = s.Authenticate("ak_foo", secret1)
+	require.False(t, ok)
+
+	// The keys are persisted
+	s2, err := NewAPIKeyStore(s.filename)
+	require.NoError(t, err)
+	require.Equal(t, s.Keys(), s2.Keys())
+	k, ok = s2.Authenticate(k2.ID, secret2)
+	require.True(t, ok)
+	require.Equal(t, k2, k)
+
+	// Revoke
+	_, err = s.Revoke("ak_foo")
+	require.Equal(t, ErrAPIKeyNotExist, err)
+
+	k, err = s.Revoke(k1.ID)
+	require.NoError(t, err)
+	require.NotZero(t, k.RevokedAt)
+	require.False(t, k.Active(time.Now()))
+
+	_, err = s.Revoke(k1.ID)
+	require.Equal(t, ErrAPIKeyRevoked, err)
+
+	_, ok = s.Authenticate(k1.ID, secret1)
+	require.False(t, ok)
+
+	s2, err = NewAPIKeyStore(s.filename)
+	require.NoError(t, err)
+	_, ok = s2.Authenticate(k1.ID, secret1)
+	require.False(t, ok)
+
+	keys := s.Keys()
+	require.Len(t, keys, 2)
+}
+
+func TestAPIKeyActive(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	require.True(t, APIKey{}.Active(now))
+	require.True(t, APIKey{ExpiresAt: now.Unix() + 1}.Active(now))
+	require.False(t, APIKey{ExpiresAt: now.Unix()}.Active(now))
+	require.False(t, APIKey{RevokedAt: now.Unix() - 1}.Active(now))
+}
+
+func TestAPIKeyHasWallet(t *testing.T) {
+	require.True(t, APIKey{}.HasWallet("foo.wlt"))
+	require.True(t, APIKey{Wallets: []string{"foo.wlt"}}.HasWallet("foo.wlt"))
+	require.False(t, APIKey{Wallets: []string{"foo.wlt"}}.HasWallet("bar.wlt"))
+}
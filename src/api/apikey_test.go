@@ -0,0 +1,414 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func doAPIKeyRequest(t *testing.T, c muxConfig, gateway *MockGatewayer, method, endpoint, body, username, password string) *httptest.ResponseRecorder {
+	req, err := http.NewRequest(method, endpoint, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", ContentTypeJSON)
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	rr := httptest.NewRecorder()
+	handler := newServerHandler(c, gateway)
+	handler.ServeHTTP(rr, req)
+
+	return rr
+}
+
+func apiKeyMuxConfig(s *APIKeyStore) muxConfig {
+	c := defaultMuxConfig()
+	c.username = "admin"
+	c.password = "pwd"
+	c.apiKeys = s
+	return c
+}
+
+func TestAPIKeysHandler(t *testing.T) {
+	tt := []struct {
+		name     string
+		method   string
+		body     string
+		disabled bool
+		status   int
+		err      *HTTPError
+	}{
+		{
+			name:   "405",
+			method: http.MethodDelete,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:     "403 - api keys disabled",
+			method:   http.MethodGet,
+			disabled: true,
+			status:   http.StatusForbidden,
+			err:      &HTTPError{Code: http.StatusForbidden, Message: "Endpoint is disabled"},
+		},
+		{
+			name:   "400 - invalid json",
+			method: http.MethodPost,
+			body:   "{",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "unexpected EOF"},
+		},
+		{
+			name:   "400 - missing api sets",
+			method: http.MethodPost,
+			body:   `{"label":"dashboard"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "api_sets is required"},
+		},
+		{
+			name:   "400 - invalid api set",
+			method: http.MethodPost,
+			body:   `{"api_sets":["READ","FOO"]}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: `invalid API set "FOO"`},
+		},
+		{
+			name:   "200 - create",
+			method: http.MethodPost,
+			body:   `{"label":"payouts","api_sets":["WALLET"],"wallets":["foo.wlt"],"expires_in":3600}`,
+			status: http.StatusOK,
+		},
+		{
+			name:   "200 - list",
+			method: http.MethodGet,
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, cleanup := newTestAPIKeyStore(t)
+			defer cleanup()
+
+			k, _, err := s.Create(APIKeyParams{
+				Label:   "dashboard",
+				APISets: []string{EndpointsRead},
+			})
+			require.NoError(t, err)
+
+			c := apiKeyMuxConfig(s)
+			if tc.disabled {
+				c.apiKeys = nil
+			}
+
+			rr := doAPIKeyRequest(t, c, &MockGatewayer{}, tc.method, "/api/v2/apikeys", tc.body, "admin", "pwd")
+			require.Equal(t, tc.status, rr.Code)
+
+			var rsp ReceivedHTTPResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rsp))
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			switch tc.method {
+			case http.MethodGet:
+				var keys []APIKeyResponse
+				require.NoError(t, json.Unmarshal(rsp.Data, &keys))
+				require.Equal(t, []APIKeyResponse{NewAPIKeyResponse(*k)}, keys)
+
+			case http.MethodPost:
+				var created APIKeyCreateResponse
+				require.NoError(t, json.Unmarshal(rsp.Data, &created))
+				require.Equal(t, "payouts", created.Key.Label)
+				require.Equal(t, []string{EndpointsWallet}, created.Key.APISets)
+				require.Equal(t, []string{"foo.wlt"}, created.Key.Wallets)
+				require.True(t, created.Key.Active)
+				require.InDelta(t, time.Now().Unix()+3600, created.Key.ExpiresAt, 5)
+
+				k, ok := s.Authenticate(created.Key.ID, created.Secret)
+				require.True(t, ok)
+				require.Equal(t, created.Key, NewAPIKeyResponse(*k))
+				require.Len(t, s.Keys(), 2)
+			}
+		})
+	}
+}
+
+func TestAPIKeyRevokeHandler(t *testing.T) {
+	tt := []struct {
+		name     string
+		method   string
+		body     string
+		disabled bool
+		status   int
+		err      *HTTPError
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:     "403 - api keys disabled",
+			method:   http.MethodPost,
+			body:     `{"id":"ak_foo"}`,
+			disabled: true,
+			status:   http.StatusForbidden,
+			err:      &HTTPError{Code: http.StatusForbidden, Message: "Endpoint is disabled"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodPost,
+			body:   `{}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "404 - key not found",
+			method: http.MethodPost,
+			body:   `{"id":"ak_foo"}`,
+			status: http.StatusNotFound,
+			err:    &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:   "400 - key already revoked",
+			method: http.MethodPost,
+			body:   `{"id":"revoked"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "API key is already revoked"},
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			body:   `{"id":"active"}`,
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			s, cleanup := newTestAPIKeyStore(t)
+			defer cleanup()
+
+			active, _, err := s.Create(APIKeyParams{APISets: []string{EndpointsRead}})
+			require.NoError(t, err)
+			revoked, _, err := s.Create(APIKeyParams{APISets: []string{EndpointsRead}})
+			require.NoError(t, err)
+			_, err = s.Revoke(revoked.ID)
+			require.NoError(t, err)
+
+			body := strings.Replace(tc.body, `"active"`, `"`+active.ID+`"`, 1)
+			body = strings.Replace(body, `"revoked"`, `"`+revoked.ID+`"`, 1)
+
+			c := apiKeyMuxConfig(s)
+			if tc.disabled {
+				c.apiKeys = nil
+			}
+
+			rr := doAPIKeyRequest(t, c, &MockGatewayer{}, tc.method, "/api/v2/apikeys/revoke", body, "admin", "pwd")
+			require.Equal(t, tc.status, rr.Code)
+
+			var rsp ReceivedHTTPResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rsp))
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var k APIKeyResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &k))
+			require.Equal(t, active.ID, k.ID)
+			require.False(t, k.Active)
+			require.NotZero(t, k.RevokedAt)
+		})
+	}
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	s, cleanup := newTestAPIKeyStore(t)
+	defer cleanup()
+
+	readKey, readSecret, err := s.Create(APIKeyParams{
+		Label:   "dashboard",
+		APISets: []string{EndpointsRead, EndpointsStatus},
+	})
+	require.NoError(t, err)
+
+	walletKey, walletSecret, err := s.Create(APIKeyParams{
+		Label:   "payouts",
+		APISets: []string{EndpointsWallet},
+		Wallets: []string{"foo.wlt"},
+	})
+	require.NoError(t, err)
+
+	revokedKey, revokedSecret, err := s.Create(APIKeyParams{
+		APISets: []string{EndpointsWallet},
+	})
+	require.NoError(t, err)
+	_, err = s.Revoke(revokedKey.ID)
+	require.NoError(t, err)
+
+	// Disabled on the node
+	seedKey, seedSecret, err := s.Create(APIKeyParams{
+		APISets: []string{EndpointsInsecureWalletSeed},
+	})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name          string
+		method        string
+		endpoint      string
+		username      string
+		password      string
+		getBalanceArg string
+		status        int
+		body          string
+	}{
+		{
+			name:     "401 - no auth",
+			method:   http.MethodGet,
+			endpoint: "/api/v1/wallet/balance?id=foo.wlt",
+			status:   http.StatusUnauthorized,
+			body:     "401 Unauthorized",
+		},
+		{
+			name:          "200 - web interface credentials",
+			method:        http.MethodGet,
+			endpoint:      "/api/v1/wallet/balance?id=bar.wlt",
+			username:      "admin",
+			password:      "pwd",
+			getBalanceArg: "bar.wlt",
+			status:        http.StatusOK,
+		},
+		{
+			name:     "401 - invalid web interface password",
+			method:   http.MethodGet,
+			endpoint: "/api/v1/wallet/balance?id=foo.wlt",
+			username: "admin",
+			password: "foo",
+			status:   http.StatusUnauthorized,
+			body:     "401 Unauthorized",
+		},
+		{
+			name:     "403 - api set of the key",
+			method:   http.MethodGet,
+			endpoint: "/api/v1/wallet/balance?id=foo.wlt",
+			username: readKey.ID,
+			password: readSecret,
+			status:   http.StatusForbidden,
+			body:     "403 Forbidden - Endpoint is disabled",
+		},
+		{
+			name:     "403 - api set disabled on the node",
+			method:   http.MethodPost,
+			endpoint: "/api/v1/wallet/seed",
+			username: seedKey.ID,
+			password: seedSecret,
+			status:   http.StatusForbidden,
+			body:     "403 Forbidden - Endpoint is disabled",
+		},
+		{
+			name:          "200 - wallet of the key",
+			method:        http.MethodGet,
+			endpoint:      "/api/v1/wallet/balance?id=foo.wlt",
+			username:      walletKey.ID,
+			password:      walletSecret,
+			getBalanceArg: "foo.wlt",
+			status:        http.StatusOK,
+		},
+		{
+			name:     "404 - wallet not in the key",
+			method:   http.MethodGet,
+			endpoint: "/api/v1/wallet/balance?id=bar.wlt",
+			username: walletKey.ID,
+			password: walletSecret,
+			status:   http.StatusNotFound,
+			body:     "404 Not Found",
+		},
+		{
+			name:     "401 - invalid secret",
+			method:   http.MethodGet,
+			endpoint: "/api/v1/wallet/balance?id=foo.wlt",
+			username: walletKey.ID,
+			password: readSecret,
+			status:   http.StatusUnauthorized,
+			body:     "401 Unauthorized",
+		},
+		{
+			name:     "401 - revoked key",
+			method:   http.MethodGet,
+			endpoint: "/api/v1/wallet/balance?id=foo.wlt",
+			username: revokedKey.ID,
+			password: revokedSecret,
+			status:   http.StatusUnauthorized,
+			body:     "401 Unauthorized",
+		},
+		{
+			name:     "403 - keys can't manage keys",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/apikeys",
+			username: readKey.ID,
+			password: readSecret,
+			status:   http.StatusForbidden,
+			body:     "{\n    \"error\": {\n        \"message\": \"Endpoint is disabled\",\n        \"code\": 403\n    }\n}",
+		},
+	}
+
+	c := apiKeyMuxConfig(s)
+	c.enabledAPISets = map[string]struct{}{
+		EndpointsRead:   {},
+		EndpointsStatus: {},
+		EndpointsWallet: {},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.getBalanceArg != "" {
+				gateway.On("GetWalletBalance", tc.getBalanceArg).Return(wallet.BalancePair{}, wallet.AddressBalances{}, nil)
+			}
+
+			rr := doAPIKeyRequest(t, c, gateway, tc.method, tc.endpoint, "", tc.username, tc.password)
+			require.Equal(t, tc.status, rr.Code, rr.Body.String())
+			if tc.body != "" {
+				require.Equal(t, tc.body, strings.TrimSpace(rr.Body.String()))
+			}
+			gateway.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWalletScopedGateway(t *testing.T) {
+	gateway := &MockGatewayer{}
+	gateway.On("GetWallets").Return(wallet.Wallets{
+		"foo.wlt": nil,
+		"bar.wlt": nil,
+	}, nil)
+
+	gw := newWalletScopedGateway(gateway, APIKey{Wallets: []string{"foo.wlt"}})
+
+	wlts, err := gw.GetWallets()
+	require.NoError(t, err)
+	require.Equal(t, wallet.Wallets{"foo.wlt": nil}, wlts)
+
+	_, err = gw.GetWallet("bar.wlt")
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	_, err = gw.ExportWallets([]string{"foo.wlt", "bar.wlt"}, nil, []byte("pwd"))
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	_, err = gw.CreateWallet("baz.wlt", wallet.Options{})
+	require.Equal(t, ErrAPIKeyWalletScope, err)
+
+	_, err = gw.ImportWallets(nil, []byte("pwd"), wallet.ImportConflictSkip, nil)
+	require.Equal(t, ErrAPIKeyWalletScope, err)
+}
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// BalanceAtResponse is returned by GET /api/v2/balance/at and GET /api/v2/wallet/balance/at
+type BalanceAtResponse struct {
+	BlockSeq  uint64                      `json:"block_seq"`
+	BlockTime uint64                      `json:"block_time"`
+	Balance   readable.Balance            `json:"balance"`
+	Addresses map[string]readable.Balance `json:"addresses"`
+}
+
+// NewBalanceAtResponse creates a BalanceAtResponse from a visor.HistoricalBalance
+func NewBalanceAtResponse(b *visor.HistoricalBalance) BalanceAtResponse {
+	addrs := make(map[string]readable.Balance, len(b.Addresses))
+	for a, bal := range b.Addresses {
+		addrs[a.String()] = readable.NewBalance(bal)
+	}
+
+	return BalanceAtResponse{
+		BlockSeq:  b.BlockSeq,
+		BlockTime: b.BlockTime,
+		Balance:   readable.NewBalance(b.Balance),
+		Addresses: addrs,
+	}
+}
+
+// BalanceSeriesPoint is the balance at the end of a bucket of a balance series
+type BalanceSeriesPoint struct {
+	BucketStart uint64           `json:"bucket_start"`
+	BucketEnd   uint64           `json:"bucket_end"`
+	BlockSeq    uint64           `json:"block_seq"`
+	BlockTime   uint64           `json:"block_time"`
+	Balance     readable.Balance `json:"balance"`
+}
+
+// BalanceSeriesResponse is returned by GET /api/v2/balance/series and GET /api/v2/wallet/balance/series
+type BalanceSeriesResponse struct {
+	Bucket string               `json:"bucket"`
+	Points []BalanceSeriesPoint `json:"points"`
+}
+
+// NewBalanceSeriesResponse creates a BalanceSeriesResponse from visor.BalanceSeriesPoints
+func NewBalanceSeriesResponse(bucket visor.BalanceBucket, points []visor.BalanceSeriesPoint) BalanceSeriesResponse {
+	rPoints := make([]BalanceSeriesPoint, len(points))
+	for i, p := range points {
+		rPoints[i] = BalanceSeriesPoint{
+			BucketStart: p.BucketStart,
+			BucketEnd:   p.BucketEnd,
+			BlockSeq:    p.BlockSeq,
+			BlockTime:   p.BlockTime,
+			Balance:     readable.NewBalance(p.Balance),
+		}
+	}
+
+	return BalanceSeriesResponse{
+		Bucket: string(bucket),
+		Points: rPoints,
+	}
+}
+
+// balanceAtHandler returns the confirmed balance of addresses as of a past block
+// URI: /api/v2/balance/at
+// Method: GET
+// Args:
+//     addrs: comma-separated list of addresses [required]
+//     seq: block seq [optional]
+//     time: unix time or RFC3339 time, selects the last block created at or before the time [optional]
+//     The head block is selected if seq and time are not set
+func balanceAtHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		addrs, err := parseAddressesFromStr(r.FormValue("addrs"))
+		if err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		if len(addrs) == 0 {
+			writeError400Response(w, "addrs is required")
+			return
+		}
+
+		at, err := parseBlockAt(r)
+		if err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		b, err := gateway.GetBalanceOfAddressesAt(addrs, at)
+		if err != nil {
+			writeBalanceHistoryError(w, err)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: NewBalanceAtResponse(b),
+		})
+	}
+}
+
+// walletBalanceAtHandler returns the confirmed balance of all addresses of a wallet as of a past block
+// URI: /api/v2/wallet/balance/at
+// Method: GET
+// Args:
+//     id: wallet id [required]
+//     seq: block seq [optional]
+//     time: unix time or RFC3339 time, selects the last block created at or before the time [optional]
+//     The head block is selected if seq and time are not set
+func walletBalanceAtHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			writeError400Response(w, "id is required")
+			return
+		}
+
+		at, err := parseBlockAt(r)
+		if err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		b, err := gateway.GetWalletBalanceAt(wltID, at)
+		if err != nil {
+			writeBalanceHistoryError(w, err)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: NewBalanceAtResponse(b),
+		})
+	}
+}
+
+// balanceSeriesHandler returns the confirmed balance of addresses at the end of each bucket of a period
+// URI: /api/v2/balance/series
+// Method: GET
+// Args:
+//     addrs: comma-separated list of addresses [required]
+//     bucket: hour, day, week, month, quarter or year [required]
+//     start: unix time or RFC3339 time of the start of the period, the time of the genesis block by default [optional]
+//     end: unix time or RFC3339 time of the end of the period, the time of the head block by default [optional]
+func balanceSeriesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		addrs, err := parseAddressesFromStr(r.FormValue("addrs"))
+		if err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		if len(addrs) == 0 {
+			writeError400Response(w, "addrs is required")
+			return
+		}
+
+		q, err := parseBalanceSeriesQuery(r)
+		if err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		points, err := gateway.GetBalanceSeriesOfAddresses(addrs, q)
+		if err != nil {
+			writeBalanceHistoryError(w, err)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: NewBalanceSeriesResponse(q.Bucket, points),
+		})
+	}
+}
+
+// walletBalanceSeriesHandler returns the confirmed balance of all addresses of a wallet
+// at the end of each bucket of a period
+// URI: /api/v2/wallet/balance/series
+// Method: GET
+// Args:
+//     id: wallet id [required]
+//     bucket: hour, day, week, month, quarter or year [required]
+//     start: unix time or RFC3339 time of the start of the period, the time of the genesis block by default [optional]
+//     end: unix time or RFC3339 time of the end of the period, the time of the head block by default [optional]
+func walletBalanceSeriesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			writeError400Response(w, "id is required")
+			return
+		}
+
+		q, err := parseBalanceSeriesQuery(r)
+		if err != nil {
+			writeError400Response(w, err.Error())
+			return
+		}
+
+		points, err := gateway.GetWalletBalanceSeries(wltID, q)
+		if err != nil {
+			writeBalanceHistoryError(w, err)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: NewBalanceSeriesResponse(q.Bucket, points),
+		})
+	}
+}
+
+func writeBalanceHistoryError(w http.ResponseWriter, err error) {
+	var resp HTTPResponse
+	switch err {
+	case wallet.ErrWalletNotExist:
+		resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+	case wallet.ErrWalletAPIDisabled:
+		resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+	default:
+		switch err.(type) {
+		case visor.UserError:
+			resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		default:
+			resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		}
+	}
+	writeHTTPResponse(w, resp)
+}
+
+// parseBlockAt parses the seq and time params that select a block
+func parseBlockAt(r *http.Request) (visor.BlockAt, error) {
+	var at visor.BlockAt
+
+	if s := r.FormValue("seq"); s != "" {
+		seq, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return visor.BlockAt{}, fmt.Errorf("invalid 'seq' value: %v", err)
+		}
+		at.Seq = &seq
+	}
+
+	if s := r.FormValue("time"); s != "" {
+		t, err := parseTimeParam("time", s)
+		if err != nil {
+			return visor.BlockAt{}, err
+		}
+		at.Time = &t
+	}
+
+	if at.Seq != nil && at.Time != nil {
+		return visor.BlockAt{}, visor.ErrBlockAtSeqAndTime
+	}
+
+	return at, nil
+}
+
+// parseBalanceSeriesQuery parses the bucket, start and end params of a balance series
+func parseBalanceSeriesQuery(r *http.Request) (visor.BalanceSeriesQuery, error) {
+	q := visor.BalanceSeriesQuery{
+		Bucket: visor.BalanceBucket(r.FormValue("bucket")),
+	}
+
+	if q.Bucket == "" {
+		return visor.BalanceSeriesQuery{}, errors.New("bucket is required")
+	}
+
+	if s := r.FormValue("start"); s != "" {
+		t, err := parseTimeParam("start", s)
+		if err != nil {
+			return visor.BalanceSeriesQuery{}, err
+		}
+		q.Start = t
+	}
+
+	if s := r.FormValue("end"); s != "" {
+		t, err := parseTimeParam("end", s)
+		if err != nil {
+			return visor.BalanceSeriesQuery{}, err
+		}
+		q.End = t
+	}
+
+	return q, nil
+}
+
+// parseTimeParam parses a unix time or an RFC3339 time
+func parseTimeParam(name, s string) (uint64, error) {
+	if t, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil || t.Unix() < 0 {
+		return 0, fmt.Errorf("invalid '%s' value, must be a unix time or an RFC3339 time", name)
+	}
+
+	return uint64(t.Unix()), nil
+}
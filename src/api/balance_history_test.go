@@ -0,0 +1,423 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestBalanceAt(t *testing.T) {
+	addr1 := "2RxP5N26GhDqHrP6SK45ZzEMSmSpeUeWxsS"
+	addr2 := "2kvLEyXwAYvHfJuFCkjnYNRTUfHPyWgVwKt"
+	addrs := []cipher.Address{
+		cipher.MustDecodeBase58Address(addr1),
+		cipher.MustDecodeBase58Address(addr2),
+	}
+
+	seq := uint64(10)
+	tm := uint64(1585699199)
+
+	balance := &visor.HistoricalBalance{
+		BlockSeq:  10,
+		BlockTime: 1585699000,
+		Balance:   wallet.Balance{Coins: 5e6, Hours: 14},
+		Addresses: map[cipher.Address]wallet.Balance{
+			addrs[0]: {Coins: 4e6, Hours: 14},
+			addrs[1]: {Coins: 1e6, Hours: 0},
+		},
+	}
+
+	expect := &BalanceAtResponse{
+		BlockSeq:  10,
+		BlockTime: 1585699000,
+		Balance:   readable.Balance{Coins: 5e6, Hours: 14},
+		Addresses: map[string]readable.Balance{
+			addr1: {Coins: 4e6, Hours: 14},
+			addr2: {Coins: 1e6, Hours: 0},
+		},
+	}
+
+	tt := []struct {
+		name       string
+		method     string
+		endpoint   string
+		query      string
+		addrs      []cipher.Address
+		id         string
+		at         visor.BlockAt
+		balance    *visor.HistoricalBalance
+		balanceErr error
+		status     int
+		err        *HTTPError
+		expect     *BalanceAtResponse
+	}{
+		{
+			name:     "405",
+			method:   http.MethodPost,
+			endpoint: "/api/v2/balance/at",
+			status:   http.StatusMethodNotAllowed,
+			err:      &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:     "400 - missing addrs",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "addrs is required"},
+		},
+		{
+			name:     "400 - invalid address",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			query:    "addrs=foo",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "address \"foo\" is invalid: Invalid address length"},
+		},
+		{
+			name:     "400 - invalid seq",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			query:    "addrs=" + addr1 + "&seq=a",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "invalid 'seq' value: strconv.ParseUint: parsing \"a\": invalid syntax"},
+		},
+		{
+			name:     "400 - invalid time",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			query:    "addrs=" + addr1 + "&time=2020-03-31",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "invalid 'time' value, must be a unix time or an RFC3339 time"},
+		},
+		{
+			name:     "400 - seq and time",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			query:    "addrs=" + addr1 + "&seq=1&time=1585699199",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "seq and time cannot be combined"},
+		},
+		{
+			name:       "400 - time before the genesis block",
+			method:     http.MethodGet,
+			endpoint:   "/api/v2/balance/at",
+			query:      "addrs=" + addr1 + "," + addr2 + "&time=1585699199",
+			addrs:      addrs,
+			at:         visor.BlockAt{Time: &tm},
+			balanceErr: visor.ErrBlockAtTimeNotFound,
+			status:     http.StatusBadRequest,
+			err:        &HTTPError{Code: http.StatusBadRequest, Message: "no block was created at or before the time"},
+		},
+		{
+			name:       "500 - get balance failed",
+			method:     http.MethodGet,
+			endpoint:   "/api/v2/balance/at",
+			query:      "addrs=" + addr1 + "," + addr2,
+			addrs:      addrs,
+			balanceErr: errors.New("failed"),
+			status:     http.StatusInternalServerError,
+			err:        &HTTPError{Code: http.StatusInternalServerError, Message: "failed"},
+		},
+		{
+			name:     "200 - head block",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			query:    "addrs=" + addr1 + "," + addr2,
+			addrs:    addrs,
+			balance:  balance,
+			status:   http.StatusOK,
+			expect:   expect,
+		},
+		{
+			name:     "200 - seq",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			query:    "addrs=" + addr1 + "," + addr2 + "&seq=10",
+			addrs:    addrs,
+			at:       visor.BlockAt{Seq: &seq},
+			balance:  balance,
+			status:   http.StatusOK,
+			expect:   expect,
+		},
+		{
+			name:     "200 - RFC3339 time",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/at",
+			query:    "addrs=" + addr1 + "," + addr2 + "&time=2020-03-31T23:59:59Z",
+			addrs:    addrs,
+			at:       visor.BlockAt{Time: &tm},
+			balance:  balance,
+			status:   http.StatusOK,
+			expect:   expect,
+		},
+		{
+			name:     "400 - missing wallet id",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/wallet/balance/at",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:       "404 - wallet not found",
+			method:     http.MethodGet,
+			endpoint:   "/api/v2/wallet/balance/at",
+			query:      "id=foo.wlt",
+			id:         "foo.wlt",
+			balanceErr: wallet.ErrWalletNotExist,
+			status:     http.StatusNotFound,
+			err:        &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:       "403 - wallet api disabled",
+			method:     http.MethodGet,
+			endpoint:   "/api/v2/wallet/balance/at",
+			query:      "id=foo.wlt",
+			id:         "foo.wlt",
+			balanceErr: wallet.ErrWalletAPIDisabled,
+			status:     http.StatusForbidden,
+			err:        &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:     "200 - wallet",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/wallet/balance/at",
+			query:    "id=foo.wlt&time=1585699199",
+			id:       "foo.wlt",
+			at:       visor.BlockAt{Time: &tm},
+			balance:  balance,
+			status:   http.StatusOK,
+			expect:   expect,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.addrs != nil {
+				gateway.On("GetBalanceOfAddressesAt", tc.addrs, tc.at).Return(tc.balance, tc.balanceErr)
+			}
+			if tc.id != "" {
+				gateway.On("GetWalletBalanceAt", tc.id, tc.at).Return(tc.balance, tc.balanceErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, tc.endpoint+"?"+tc.query, "")
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var resp BalanceAtResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &resp))
+			require.Equal(t, *tc.expect, resp)
+		})
+	}
+}
+
+func TestBalanceSeries(t *testing.T) {
+	addr := "2RxP5N26GhDqHrP6SK45ZzEMSmSpeUeWxsS"
+	addrs := []cipher.Address{cipher.MustDecodeBase58Address(addr)}
+
+	points := []visor.BalanceSeriesPoint{
+		{
+			BucketStart: 1577836800,
+			BucketEnd:   1585699200,
+			BlockSeq:    10,
+			BlockTime:   1585699000,
+			Balance:     wallet.Balance{Coins: 5e6, Hours: 14},
+		},
+		{
+			BucketStart: 1585699200,
+			BucketEnd:   1593561600,
+			BlockSeq:    20,
+			BlockTime:   1593560000,
+			Balance:     wallet.Balance{Coins: 3e6, Hours: 20},
+		},
+	}
+
+	expect := &BalanceSeriesResponse{
+		Bucket: "quarter",
+		Points: []BalanceSeriesPoint{
+			{
+				BucketStart: 1577836800,
+				BucketEnd:   1585699200,
+				BlockSeq:    10,
+				BlockTime:   1585699000,
+				Balance:     readable.Balance{Coins: 5e6, Hours: 14},
+			},
+			{
+				BucketStart: 1585699200,
+				BucketEnd:   1593561600,
+				BlockSeq:    20,
+				BlockTime:   1593560000,
+				Balance:     readable.Balance{Coins: 3e6, Hours: 20},
+			},
+		},
+	}
+
+	tt := []struct {
+		name      string
+		method    string
+		endpoint  string
+		query     string
+		addrs     []cipher.Address
+		id        string
+		q         visor.BalanceSeriesQuery
+		points    []visor.BalanceSeriesPoint
+		pointsErr error
+		status    int
+		err       *HTTPError
+		expect    *BalanceSeriesResponse
+	}{
+		{
+			name:     "405",
+			method:   http.MethodPost,
+			endpoint: "/api/v2/balance/series",
+			status:   http.StatusMethodNotAllowed,
+			err:      &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:     "400 - missing addrs",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/series",
+			query:    "bucket=day",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "addrs is required"},
+		},
+		{
+			name:     "400 - missing bucket",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/series",
+			query:    "addrs=" + addr,
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "bucket is required"},
+		},
+		{
+			name:     "400 - invalid start",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/series",
+			query:    "addrs=" + addr + "&bucket=day&start=yesterday",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "invalid 'start' value, must be a unix time or an RFC3339 time"},
+		},
+		{
+			name:     "400 - invalid end",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/series",
+			query:    "addrs=" + addr + "&bucket=day&end=-1",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "invalid 'end' value, must be a unix time or an RFC3339 time"},
+		},
+		{
+			name:      "400 - invalid bucket",
+			method:    http.MethodGet,
+			endpoint:  "/api/v2/balance/series",
+			query:     "addrs=" + addr + "&bucket=minute",
+			addrs:     addrs,
+			q:         visor.BalanceSeriesQuery{Bucket: "minute"},
+			pointsErr: visor.ErrInvalidBalanceBucket,
+			status:    http.StatusBadRequest,
+			err:       &HTTPError{Code: http.StatusBadRequest, Message: "invalid bucket, must be hour, day, week, month, quarter or year"},
+		},
+		{
+			name:      "500 - get series failed",
+			method:    http.MethodGet,
+			endpoint:  "/api/v2/balance/series",
+			query:     "addrs=" + addr + "&bucket=day",
+			addrs:     addrs,
+			q:         visor.BalanceSeriesQuery{Bucket: visor.BalanceBucketDay},
+			pointsErr: errors.New("failed"),
+			status:    http.StatusInternalServerError,
+			err:       &HTTPError{Code: http.StatusInternalServerError, Message: "failed"},
+		},
+		{
+			name:     "200",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/series",
+			query:    "addrs=" + addr + "&bucket=quarter&start=1577836800&end=2020-06-30T00:00:00Z",
+			addrs:    addrs,
+			q: visor.BalanceSeriesQuery{
+				Start:  1577836800,
+				End:    1593475200,
+				Bucket: visor.BalanceBucketQuarter,
+			},
+			points: points,
+			status: http.StatusOK,
+			expect: expect,
+		},
+		{
+			name:     "200 - no points",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/balance/series",
+			query:    "addrs=" + addr + "&bucket=day",
+			addrs:    addrs,
+			q:        visor.BalanceSeriesQuery{Bucket: visor.BalanceBucketDay},
+			status:   http.StatusOK,
+			expect: &BalanceSeriesResponse{
+				Bucket: "day",
+				Points: []BalanceSeriesPoint{},
+			},
+		},
+		{
+			name:     "400 - missing wallet id",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/wallet/balance/series",
+			query:    "bucket=day",
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:      "404 - wallet not found",
+			method:    http.MethodGet,
+			endpoint:  "/api/v2/wallet/balance/series",
+			query:     "id=foo.wlt&bucket=day",
+			id:        "foo.wlt",
+			q:         visor.BalanceSeriesQuery{Bucket: visor.BalanceBucketDay},
+			pointsErr: wallet.ErrWalletNotExist,
+			status:    http.StatusNotFound,
+			err:       &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:     "200 - wallet",
+			method:   http.MethodGet,
+			endpoint: "/api/v2/wallet/balance/series",
+			query:    "id=foo.wlt&bucket=quarter",
+			id:       "foo.wlt",
+			q:        visor.BalanceSeriesQuery{Bucket: visor.BalanceBucketQuarter},
+			points:   points,
+			status:   http.StatusOK,
+			expect:   expect,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.addrs != nil {
+				gateway.On("GetBalanceSeriesOfAddresses", tc.addrs, tc.q).Return(tc.points, tc.pointsErr)
+			}
+			if tc.id != "" {
+				gateway.On("GetWalletBalanceSeries", tc.id, tc.q).Return(tc.points, tc.pointsErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, tc.endpoint+"?"+tc.query, "")
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var resp BalanceSeriesResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &resp))
+			require.Equal(t, *tc.expect, resp)
+		})
+	}
+}
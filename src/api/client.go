@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -818,9 +819,10 @@ type Receiver struct {
 
 // WalletCreateTransactionRequest is sent to /api/v1/wallet/transaction
 type WalletCreateTransactionRequest struct {
-	Unsigned bool   `json:"unsigned"`
-	WalletID string `json:"wallet_id"`
-	Password string `json:"password"`
+	Unsigned bool    `json:"unsigned"`
+	WalletID string  `json:"wallet_id"`
+	Password string  `json:"password"`
+	Account  *uint32 `json:"account,omitempty"`
 	CreateTransactionRequest
 }
 
@@ -943,6 +945,17 @@ func (c *Client) WalletSeed(id string, password string) (*WalletSeedResponse, er
 	return &r, nil
 }
 
+// WalletSeedShares makes a request to POST /api/v2/wallet/seed/shares
+func (c *Client) WalletSeedShares(req WalletSeedSharesRequest) (*WalletSeedSharesResponse, error) {
+	var rsp WalletSeedSharesResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/seed/shares", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // NetworkConnection makes a request to GET /api/v1/network/connection
 func (c *Client) NetworkConnection(addr string) (*readable.Connection, error) {
 	v := url.Values{}
@@ -1033,6 +1046,17 @@ func (c *Client) PendingTransactionsVerbose() ([]readable.UnconfirmedTransaction
 	return v, nil
 }
 
+// MempoolStats makes a request to GET /api/v2/mempool/stats
+func (c *Client) MempoolStats() (*MempoolStatsResponse, error) {
+	var rsp MempoolStatsResponse
+	ok, err := c.GetV2("/api/v2/mempool/stats", &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
 // Transaction makes a request to GET /api/v1/transaction
 func (c *Client) Transaction(txid string) (*readable.TransactionWithStatus, error) {
 	v := url.Values{}
@@ -1267,6 +1291,123 @@ func (c *Client) VerifyAddress(addr string) (*VerifyAddressResponse, error) {
 	return nil, err
 }
 
+// BalanceAt makes a request to GET /api/v2/balance/at.
+// The block is selected with the "seq" or "time" args, the head block is selected if neither is set.
+func (c *Client) BalanceAt(addrs []string, args ...RequestArg) (*BalanceAtResponse, error) {
+	v := url.Values{}
+	v.Add("addrs", strings.Join(addrs, ","))
+	for _, arg := range args {
+		v.Add(arg.Key, arg.Value)
+	}
+	endpoint := "/api/v2/balance/at?" + v.Encode()
+
+	var rsp BalanceAtResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// BalanceSeries makes a request to GET /api/v2/balance/series.
+// The period is selected with the "start" and "end" args.
+func (c *Client) BalanceSeries(addrs []string, bucket string, args ...RequestArg) (*BalanceSeriesResponse, error) {
+	v := url.Values{}
+	v.Add("addrs", strings.Join(addrs, ","))
+	v.Add("bucket", bucket)
+	for _, arg := range args {
+		v.Add(arg.Key, arg.Value)
+	}
+	endpoint := "/api/v2/balance/series?" + v.Encode()
+
+	var rsp BalanceSeriesResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// AddressStats makes a request to GET /api/v2/address/{addr}/stats
+func (c *Client) AddressStats(addr string) (*readable.AddressStats, error) {
+	var stats readable.AddressStats
+	ok, err := c.GetV2(fmt.Sprintf("/api/v2/address/%s/stats", url.PathEscape(addr)), &stats)
+	if ok {
+		return &stats, err
+	}
+
+	return nil, err
+}
+
+// Search makes a request to GET /api/v2/search
+func (c *Client) Search(q string) (*SearchResponse, error) {
+	v := url.Values{}
+	v.Add("q", q)
+	endpoint := "/api/v2/search?" + v.Encode()
+
+	var rsp SearchResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// ExportParams are arguments to the /api/v2/export endpoint
+type ExportParams struct {
+	Table  ExportTable
+	Format ExportFormat
+	Start  uint64
+	// End is the seq of the last block, the head block is used if nil
+	End *uint64
+}
+
+// Export makes a request to GET /api/v2/export and copies the exported rows to w.
+// Returns the seq of the block to start the next export from.
+// The server exports at most MaxExportBlocks blocks per request.
+func (c *Client) Export(w io.Writer, params ExportParams) (uint64, error) {
+	v := url.Values{}
+	v.Add("table", string(params.Table))
+	if params.Format != "" {
+		v.Add("format", string(params.Format))
+	}
+	v.Add("start", fmt.Sprint(params.Start))
+	if params.End != nil {
+		v.Add("end", fmt.Sprint(*params.End))
+	}
+
+	resp, err := c.get("/api/v2/export?" + v.Encode())
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return 0, err
+		}
+
+		return 0, NewClientError(resp.Status, resp.StatusCode, string(body))
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return 0, err
+	}
+
+	// The trailer is only sent once the export is complete
+	nextSeq := resp.Trailer.Get("X-Export-Next-Seq")
+	if nextSeq == "" {
+		return 0, errors.New("export response is incomplete")
+	}
+
+	return strconv.ParseUint(nextSeq, 10, 64)
+}
+
 // RichlistParams are arguments to the /richlist endpoint
 type RichlistParams struct {
 	N                   int
@@ -1359,6 +1500,343 @@ func (c *Client) RecoverWallet(req WalletRecoverRequest) (*WalletResponse, error
 	return nil, err
 }
 
+// WalletAccounts makes a request to GET /api/v2/wallet/accounts
+func (c *Client) WalletAccounts(id string) ([]WalletAccount, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	endpoint := "/api/v2/wallet/accounts?" + v.Encode()
+
+	var accounts []WalletAccount
+	ok, err := c.GetV2(endpoint, &accounts)
+	if !ok {
+		return nil, err
+	}
+
+	return accounts, err
+}
+
+// CreateWalletAccount makes a request to POST /api/v2/wallet/accounts
+func (c *Client) CreateWalletAccount(req WalletAccountCreateRequest) (*WalletAccount, error) {
+	var rsp WalletAccount
+	ok, err := c.PostJSONV2("/api/v2/wallet/accounts", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// UpdateWalletAccount makes a request to POST /api/v2/wallet/accounts/update
+func (c *Client) UpdateWalletAccount(req WalletAccountUpdateRequest) error {
+	_, err := c.PostJSONV2("/api/v2/wallet/accounts/update", req, nil)
+	return err
+}
+
+// WalletAccountBalance makes a request to GET /api/v2/wallet/accounts/balance
+func (c *Client) WalletAccountBalance(id string, account uint32) (*BalanceResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("account", fmt.Sprint(account))
+	endpoint := "/api/v2/wallet/accounts/balance?" + v.Encode()
+
+	var b BalanceResponse
+	ok, err := c.GetV2(endpoint, &b)
+	if !ok {
+		return nil, err
+	}
+
+	return &b, err
+}
+
+// NewWalletAccountAddresses makes a request to POST /api/v2/wallet/accounts/addresses
+func (c *Client) NewWalletAccountAddresses(req WalletAccountNewAddressesRequest) ([]string, error) {
+	var rsp struct {
+		Addresses []string `json:"addresses"`
+	}
+	ok, err := c.PostJSONV2("/api/v2/wallet/accounts/addresses", req, &rsp)
+	if ok {
+		return rsp.Addresses, err
+	}
+
+	return nil, err
+}
+
+// WalletExport makes a request to POST /api/v2/wallet/export and returns the encrypted wallet archive
+func (c *Client) WalletExport(req WalletExportRequest) ([]byte, error) {
+	var archive json.RawMessage
+	ok, err := c.PostJSONV2("/api/v2/wallet/export", req, &archive)
+	if ok {
+		return archive, err
+	}
+
+	return nil, err
+}
+
+// WalletImport makes a request to POST /api/v2/wallet/import
+func (c *Client) WalletImport(req WalletImportRequest) (*WalletImportResponse, error) {
+	var rsp WalletImportResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/import", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletHistory makes a request to GET /api/v2/wallet/history
+func (c *Client) WalletHistory(id string, args ...RequestArg) (*WalletHistoryResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	for _, arg := range args {
+		v.Add(arg.Key, arg.Value)
+	}
+	endpoint := "/api/v2/wallet/history?" + v.Encode()
+
+	var rsp WalletHistoryResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletBalanceAt makes a request to GET /api/v2/wallet/balance/at.
+// The block is selected with the "seq" or "time" args, the head block is selected if neither is set.
+func (c *Client) WalletBalanceAt(id string, args ...RequestArg) (*BalanceAtResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	for _, arg := range args {
+		v.Add(arg.Key, arg.Value)
+	}
+	endpoint := "/api/v2/wallet/balance/at?" + v.Encode()
+
+	var rsp BalanceAtResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletBalanceSeries makes a request to GET /api/v2/wallet/balance/series.
+// The period is selected with the "start" and "end" args.
+func (c *Client) WalletBalanceSeries(id, bucket string, args ...RequestArg) (*BalanceSeriesResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("bucket", bucket)
+	for _, arg := range args {
+		v.Add(arg.Key, arg.Value)
+	}
+	endpoint := "/api/v2/wallet/balance/series?" + v.Encode()
+
+	var rsp BalanceSeriesResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletHistoryCSV makes a request to GET /api/v2/wallet/history?format=csv
+func (c *Client) WalletHistoryCSV(id string, args ...RequestArg) ([]byte, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	v.Add("format", "csv")
+	for _, arg := range args {
+		v.Add(arg.Key, arg.Value)
+	}
+	endpoint := "/api/v2/wallet/history?" + v.Encode()
+
+	resp, err := c.get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, NewClientError(resp.Status, resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
+
+// WalletAddresses makes a request to GET /api/v2/wallet/addresses.
+// If label or tag are not empty, only the addresses matching them are returned.
+func (c *Client) WalletAddresses(id, label, tag string) (*WalletAddressesResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	if label != "" {
+		v.Add("label", label)
+	}
+	if tag != "" {
+		v.Add("tag", tag)
+	}
+	endpoint := "/api/v2/wallet/addresses?" + v.Encode()
+
+	var rsp WalletAddressesResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// UpdateWalletAddress makes a request to POST /api/v2/wallet/address/update
+func (c *Client) UpdateWalletAddress(req WalletAddressUpdateRequest) (*WalletAddressMeta, error) {
+	var rsp WalletAddressMeta
+	ok, err := c.PostJSONV2("/api/v2/wallet/address/update", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// WalletPolicy makes a request to GET /api/v2/wallet/policy
+func (c *Client) WalletPolicy(id string) (*WalletPolicyResponse, error) {
+	v := url.Values{}
+	v.Add("id", id)
+	endpoint := "/api/v2/wallet/policy?" + v.Encode()
+
+	var rsp WalletPolicyResponse
+	ok, err := c.GetV2(endpoint, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// UpdateWalletPolicy makes a request to POST /api/v2/wallet/policy
+func (c *Client) UpdateWalletPolicy(req WalletPolicyUpdateRequest) (*WalletPolicyResponse, error) {
+	var rsp WalletPolicyResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/policy", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// UnlockWallet makes a request to POST /api/v2/wallet/unlock.
+// The timeout is rounded down to seconds, 0 uses the default timeout of the node.
+func (c *Client) UnlockWallet(id, password string, timeout time.Duration) (*WalletUnlockResponse, error) {
+	req := WalletUnlockRequest{
+		ID:       id,
+		Password: password,
+		Timeout:  uint64(timeout / time.Second),
+	}
+
+	var rsp WalletUnlockResponse
+	ok, err := c.PostJSONV2("/api/v2/wallet/unlock", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+
+	return nil, err
+}
+
+// LockWallet makes a request to POST /api/v2/wallet/lock
+func (c *Client) LockWallet(id string) error {
+	_, err := c.PostJSONV2("/api/v2/wallet/lock", WalletLockRequest{
+		ID: id,
+	}, nil)
+	return err
+}
+
+// APIKeys makes a request to GET /api/v2/apikeys
+func (c *Client) APIKeys() ([]APIKeyResponse, error) {
+	var keys []APIKeyResponse
+	ok, err := c.GetV2("/api/v2/apikeys", &keys)
+	if ok {
+		return keys, err
+	}
+	return nil, err
+}
+
+// CreateAPIKey makes a request to POST /api/v2/apikeys
+func (c *Client) CreateAPIKey(req APIKeyCreateRequest) (*APIKeyCreateResponse, error) {
+	var rsp APIKeyCreateResponse
+	ok, err := c.PostJSONV2("/api/v2/apikeys", req, &rsp)
+	if ok {
+		return &rsp, err
+	}
+	return nil, err
+}
+
+// RevokeAPIKey makes a request to POST /api/v2/apikeys/revoke
+func (c *Client) RevokeAPIKey(id string) (*APIKeyResponse, error) {
+	var rsp APIKeyResponse
+	ok, err := c.PostJSONV2("/api/v2/apikeys/revoke", APIKeyRevokeRequest{
+		ID: id,
+	}, &rsp)
+	if ok {
+		return &rsp, err
+	}
+	return nil, err
+}
+
+// RPC makes a JSON-RPC 2.0 request to /rpc. params are passed by name and can be nil.
+// The result of the method is decoded into result, which can be nil.
+// Returns an *RPCError if the method failed.
+func (c *Client) RPC(method string, params, result interface{}) error {
+	req := RPCRequest{
+		JSONRPC: RPCVersion,
+		ID:      json.RawMessage("1"),
+		Method:  method,
+	}
+
+	if params != nil {
+		b, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = b
+	}
+
+	var resp RPCResponse
+	if err := c.PostJSON("/rpc", req, &resp); err != nil {
+		return err
+	}
+
+	if resp.Error != nil {
+		return resp.Error
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(resp.Result, result)
+}
+
+// RPCBatch makes a batch of JSON-RPC 2.0 requests to /rpc.
+// All requests must have an ID, the responses may be in any order.
+func (c *Client) RPCBatch(reqs []RPCRequest) ([]RPCResponse, error) {
+	for _, req := range reqs {
+		if len(req.ID) == 0 {
+			return nil, errors.New("RPC batch requests must have an ID")
+		}
+	}
+
+	var resps []RPCResponse
+	if err := c.PostJSON("/rpc", reqs, &resps); err != nil {
+		return nil, err
+	}
+
+	return resps, nil
+}
+
 // Disconnect disconnect a connections by ID
 func (c *Client) Disconnect(id uint64) error {
 	v := url.Values{}
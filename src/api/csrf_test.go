@@ -62,7 +62,7 @@ func TestCSRFWrapper(t *testing.T) {
 
 					setCSRFParameters(t, c, req)
 
-					isAPIV2 := strings.HasPrefix(endpoint, "/api/v2")
+					isAPIV2 := isAPIV2Endpoint(endpoint)
 					if isAPIV2 {
 						req.Header.Set("Content-Type", ContentTypeJSON)
 					}
@@ -133,7 +133,7 @@ func TestCSRFWrapperConcurrent(t *testing.T) {
 
 							setCSRFParameters(t, c, req)
 
-							isAPIV2 := strings.HasPrefix(endpoint, "/api/v2")
+							isAPIV2 := isAPIV2Endpoint(endpoint)
 							if isAPIV2 {
 								req.Header.Set("Content-Type", ContentTypeJSON)
 							}
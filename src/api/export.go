@@ -0,0 +1,491 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+// MaxExportBlocks is the maximum number of blocks exported by a request to GET /api/v2/export
+const MaxExportBlocks = 10000
+
+// ExportTable is a table of a chain export
+type ExportTable string
+
+const (
+	// ExportTableBlocks has a row for each block
+	ExportTableBlocks ExportTable = "blocks"
+	// ExportTableTransactions has a row for each transaction
+	ExportTableTransactions ExportTable = "transactions"
+	// ExportTableInputs has a row for each output spent by a transaction
+	ExportTableInputs ExportTable = "inputs"
+	// ExportTableOutputs has a row for each output created by a transaction
+	ExportTableOutputs ExportTable = "outputs"
+)
+
+// ExportTables are the tables of a chain export
+var ExportTables = []ExportTable{
+	ExportTableBlocks,
+	ExportTableTransactions,
+	ExportTableInputs,
+	ExportTableOutputs,
+}
+
+// ExportFormat is the file format of a chain export
+type ExportFormat string
+
+const (
+	// ExportFormatJSONL writes a JSON object per line
+	ExportFormatJSONL ExportFormat = "jsonl"
+	// ExportFormatCSV writes CSV with a header line
+	ExportFormatCSV ExportFormat = "csv"
+)
+
+var exportCSVHeaders = map[ExportTable][]string{
+	ExportTableBlocks: {
+		"seq", "hash", "prev_hash", "body_hash", "time", "fee", "txn_count",
+	},
+	ExportTableTransactions: {
+		"block_seq", "index", "txid", "inner_hash", "type", "length",
+		"input_count", "output_count", "coins", "hours_in", "hours_out", "fee",
+	},
+	ExportTableInputs: {
+		"block_seq", "txid", "index", "uxid", "src_txid", "src_block_seq",
+		"address", "coins", "hours", "calculated_hours",
+	},
+	ExportTableOutputs: {
+		"block_seq", "txid", "index", "uxid", "address", "coins", "hours",
+	},
+}
+
+// ExportBlock is a row of the blocks table of a chain export
+type ExportBlock struct {
+	Seq      uint64 `json:"seq"`
+	Hash     string `json:"hash"`
+	PrevHash string `json:"prev_hash"`
+	BodyHash string `json:"body_hash"`
+	Time     uint64 `json:"time"`
+	Fee      uint64 `json:"fee"`
+	TxnCount int    `json:"txn_count"`
+}
+
+func (b ExportBlock) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(b.Seq, 10),
+		b.Hash,
+		b.PrevHash,
+		b.BodyHash,
+		strconv.FormatUint(b.Time, 10),
+		strconv.FormatUint(b.Fee, 10),
+		strconv.Itoa(b.TxnCount),
+	}
+}
+
+// ExportTransaction is a row of the transactions table of a chain export
+type ExportTransaction struct {
+	BlockSeq    uint64 `json:"block_seq"`
+	Index       int    `json:"index"`
+	TxID        string `json:"txid"`
+	InnerHash   string `json:"inner_hash"`
+	Type        uint8  `json:"type"`
+	Length      uint32 `json:"length"`
+	InputCount  int    `json:"input_count"`
+	OutputCount int    `json:"output_count"`
+	Coins       string `json:"coins"`
+	HoursIn     uint64 `json:"hours_in"`
+	HoursOut    uint64 `json:"hours_out"`
+	Fee         uint64 `json:"fee"`
+}
+
+func (t ExportTransaction) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(t.BlockSeq, 10),
+		strconv.Itoa(t.Index),
+		t.TxID,
+		t.InnerHash,
+		strconv.FormatUint(uint64(t.Type), 10),
+		strconv.FormatUint(uint64(t.Length), 10),
+		strconv.Itoa(t.InputCount),
+		strconv.Itoa(t.OutputCount),
+		t.Coins,
+		strconv.FormatUint(t.HoursIn, 10),
+		strconv.FormatUint(t.HoursOut, 10),
+		strconv.FormatUint(t.Fee, 10),
+	}
+}
+
+// ExportInput is a row of the inputs table of a chain export
+type ExportInput struct {
+	BlockSeq        uint64 `json:"block_seq"`
+	TxID            string `json:"txid"`
+	Index           int    `json:"index"`
+	UxID            string `json:"uxid"`
+	SrcTxID         string `json:"src_txid"`
+	SrcBlockSeq     uint64 `json:"src_block_seq"`
+	Address         string `json:"address"`
+	Coins           string `json:"coins"`
+	Hours           uint64 `json:"hours"`
+	CalculatedHours uint64 `json:"calculated_hours"`
+}
+
+func (in ExportInput) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(in.BlockSeq, 10),
+		in.TxID,
+		strconv.Itoa(in.Index),
+		in.UxID,
+		in.SrcTxID,
+		strconv.FormatUint(in.SrcBlockSeq, 10),
+		in.Address,
+		in.Coins,
+		strconv.FormatUint(in.Hours, 10),
+		strconv.FormatUint(in.CalculatedHours, 10),
+	}
+}
+
+// ExportOutput is a row of the outputs table of a chain export
+type ExportOutput struct {
+	BlockSeq uint64 `json:"block_seq"`
+	TxID     string `json:"txid"`
+	Index    int    `json:"index"`
+	UxID     string `json:"uxid"`
+	Address  string `json:"address"`
+	Coins    string `json:"coins"`
+	Hours    uint64 `json:"hours"`
+}
+
+func (out ExportOutput) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(out.BlockSeq, 10),
+		out.TxID,
+		strconv.Itoa(out.Index),
+		out.UxID,
+		out.Address,
+		out.Coins,
+		strconv.FormatUint(out.Hours, 10),
+	}
+}
+
+// exportRow is a row of a table of a chain export
+type exportRow interface {
+	csvRecord() []string
+}
+
+// ExportRows are the rows of the tables of a chain export for a block
+type ExportRows struct {
+	Block        ExportBlock
+	Transactions []ExportTransaction
+	Inputs       []ExportInput
+	Outputs      []ExportOutput
+}
+
+// NewExportRows creates the rows of the tables of a chain export for a block
+func NewExportRows(b *visor.ExportedBlock) (*ExportRows, error) {
+	blk := b.Block.Block
+	seq := blk.Seq()
+
+	rows := &ExportRows{
+		Block: ExportBlock{
+			Seq:      seq,
+			Hash:     blk.HashHeader().Hex(),
+			PrevHash: blk.Head.PrevHash.Hex(),
+			BodyHash: blk.Head.BodyHash.Hex(),
+			Time:     blk.Time(),
+			Fee:      blk.Head.Fee,
+			TxnCount: len(blk.Body.Transactions),
+		},
+	}
+
+	if len(b.Inputs) != len(blk.Body.Transactions) {
+		return nil, fmt.Errorf("block %d has %d transactions but inputs for %d transactions", seq, len(blk.Body.Transactions), len(b.Inputs))
+	}
+
+	for i := range blk.Body.Transactions {
+		txn := &blk.Body.Transactions[i]
+		txid := txn.Hash()
+
+		var hoursIn, hoursOut, coins uint64
+		for j, in := range b.Inputs[i] {
+			ux := in.UxOut
+			inCoins, err := droplet.ToString(ux.Body.Coins)
+			if err != nil {
+				return nil, err
+			}
+
+			rows.Inputs = append(rows.Inputs, ExportInput{
+				BlockSeq:        seq,
+				TxID:            txid.Hex(),
+				Index:           j,
+				UxID:            ux.Hash().Hex(),
+				SrcTxID:         ux.Body.SrcTransaction.Hex(),
+				SrcBlockSeq:     ux.Head.BkSeq,
+				Address:         ux.Body.Address.String(),
+				Coins:           inCoins,
+				Hours:           ux.Body.Hours,
+				CalculatedHours: in.CalculatedHours,
+			})
+
+			hoursIn += in.CalculatedHours
+		}
+
+		for j, o := range txn.Out {
+			outCoins, err := droplet.ToString(o.Coins)
+			if err != nil {
+				return nil, err
+			}
+
+			rows.Outputs = append(rows.Outputs, ExportOutput{
+				BlockSeq: seq,
+				TxID:     txid.Hex(),
+				Index:    j,
+				UxID:     o.UxID(txid).Hex(),
+				Address:  o.Address.String(),
+				Coins:    outCoins,
+				Hours:    o.Hours,
+			})
+
+			coins += o.Coins
+			hoursOut += o.Hours
+		}
+
+		txnCoins, err := droplet.ToString(coins)
+		if err != nil {
+			return nil, err
+		}
+
+		// The genesis transaction creates hours without inputs, and has no fee
+		var fee uint64
+		if hoursIn > hoursOut {
+			fee = hoursIn - hoursOut
+		}
+
+		rows.Transactions = append(rows.Transactions, ExportTransaction{
+			BlockSeq:    seq,
+			Index:       i,
+			TxID:        txid.Hex(),
+			InnerHash:   txn.InnerHash.Hex(),
+			Type:        txn.Type,
+			Length:      txn.Length,
+			InputCount:  len(txn.In),
+			OutputCount: len(txn.Out),
+			Coins:       txnCoins,
+			HoursIn:     hoursIn,
+			HoursOut:    hoursOut,
+			Fee:         fee,
+		})
+	}
+
+	return rows, nil
+}
+
+// table returns the rows of a table
+func (rows *ExportRows) table(t ExportTable) []exportRow {
+	var r []exportRow
+	switch t {
+	case ExportTableBlocks:
+		r = append(r, rows.Block)
+	case ExportTableTransactions:
+		for _, x := range rows.Transactions {
+			r = append(r, x)
+		}
+	case ExportTableInputs:
+		for _, x := range rows.Inputs {
+			r = append(r, x)
+		}
+	case ExportTableOutputs:
+		for _, x := range rows.Outputs {
+			r = append(r, x)
+		}
+	}
+	return r
+}
+
+// ExportWriter writes a table of a chain export as JSON lines or CSV
+type ExportWriter struct {
+	table ExportTable
+	json  *json.Encoder
+	csv   *csv.Writer
+}
+
+// NewExportWriter creates an ExportWriter for a table.
+// For the CSV format, the header line is written if header is true.
+func NewExportWriter(w io.Writer, table ExportTable, format ExportFormat, header bool) (*ExportWriter, error) {
+	csvHeader, ok := exportCSVHeaders[table]
+	if !ok {
+		return nil, fmt.Errorf("invalid table %q, must be blocks, transactions, inputs or outputs", table)
+	}
+
+	ew := &ExportWriter{
+		table: table,
+	}
+
+	switch format {
+	case ExportFormatJSONL:
+		ew.json = json.NewEncoder(w)
+	case ExportFormatCSV:
+		ew.csv = csv.NewWriter(w)
+		if header {
+			if err := ew.csv.Write(csvHeader); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid format %q, must be jsonl or csv", format)
+	}
+
+	return ew, nil
+}
+
+// Write writes the rows of the table
+func (ew *ExportWriter) Write(rows *ExportRows) error {
+	for _, row := range rows.table(ew.table) {
+		if ew.json != nil {
+			if err := ew.json.Encode(row); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := ew.csv.Write(row.csvRecord()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Flush writes any buffered data to the underlying io.Writer
+func (ew *ExportWriter) Flush() error {
+	if ew.csv == nil {
+		return nil
+	}
+
+	ew.csv.Flush()
+	return ew.csv.Error()
+}
+
+// exportHandler streams a table of the blockchain as JSON lines or CSV, for loading it into analytics databases.
+// At most MaxExportBlocks blocks are exported per request. To continue an export, request again with start
+// set to the value of the X-Export-Next-Seq trailer, or to one more than the last block_seq received.
+// URI: /api/v2/export
+// Method: GET
+// Args:
+//     table: blocks, transactions, inputs or outputs [required]
+//     format: jsonl or csv [optional, defaults to jsonl]
+//     start: seq of the first block [optional, defaults to 0]
+//     end: seq of the last block [optional, defaults to the head block]
+func exportHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		table := ExportTable(r.FormValue("table"))
+		if table == "" {
+			writeError400Response(w, "table is required")
+			return
+		}
+		if _, ok := exportCSVHeaders[table]; !ok {
+			writeError400Response(w, "invalid 'table' value, must be blocks, transactions, inputs or outputs")
+			return
+		}
+
+		format := ExportFormat(r.FormValue("format"))
+		var contentType string
+		switch format {
+		case "":
+			format = ExportFormatJSONL
+			contentType = "application/x-ndjson"
+		case ExportFormatJSONL:
+			contentType = "application/x-ndjson"
+		case ExportFormatCSV:
+			contentType = "text/csv"
+		default:
+			writeError400Response(w, "invalid 'format' value, must be jsonl or csv")
+			return
+		}
+
+		var start uint64
+		if s := r.FormValue("start"); s != "" {
+			var err error
+			start, err = strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError400Response(w, fmt.Sprintf("invalid 'start' value: %v", err))
+				return
+			}
+		}
+
+		end := start + MaxExportBlocks - 1
+		if end < start {
+			end = ^uint64(0)
+		}
+		if s := r.FormValue("end"); s != "" {
+			e, err := strconv.ParseUint(s, 10, 64)
+			if err != nil {
+				writeError400Response(w, fmt.Sprintf("invalid 'end' value: %v", err))
+				return
+			}
+			if e < end {
+				end = e
+			}
+		}
+
+		var ew *ExportWriter
+		var nextSeq uint64
+		err := gateway.ExportChain(start, end, r.Context().Done(), func(b *visor.ExportedBlock) error {
+			rows, err := NewExportRows(b)
+			if err != nil {
+				return err
+			}
+
+			// The headers are written with the first block, so that errors found before can be returned as JSON
+			if ew == nil {
+				w.Header().Set("Content-Type", contentType)
+				w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s.%s", table, format)))
+				w.Header().Set("Trailer", "X-Export-Next-Seq")
+
+				ew, err = NewExportWriter(w, table, format, true)
+				if err != nil {
+					return err
+				}
+			}
+
+			if err := ew.Write(rows); err != nil {
+				return err
+			}
+
+			nextSeq = rows.Block.Seq + 1
+			return nil
+		})
+
+		if ew == nil {
+			switch err.(type) {
+			case nil:
+			case visor.UserError:
+				writeError400Response(w, err.Error())
+			default:
+				writeError500Response(w, err.Error())
+			}
+			return
+		}
+
+		if err == nil {
+			err = ew.Flush()
+		}
+
+		if err != nil {
+			// The response has started, abort it so that the client does not mistake it for a complete export
+			logger.WithError(err).Error("exportHandler: export failed")
+			panic(http.ErrAbortHandler)
+		}
+
+		w.Header().Set("X-Export-Next-Seq", strconv.FormatUint(nextSeq, 10))
+	}
+}
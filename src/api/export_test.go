@@ -0,0 +1,428 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func makeExportedBlocks(t *testing.T) []visor.ExportedBlock {
+	addr := testutil.MakeAddress()
+
+	genesisTxn := coin.Transaction{
+		Out: []coin.TransactionOutput{
+			{
+				Address: addr,
+				Coins:   100e6,
+				Hours:   1000,
+			},
+		},
+	}
+	genesisUx := coin.UxOut{
+		Head: coin.UxHead{
+			Time:  1000,
+			BkSeq: 0,
+		},
+		Body: coin.UxBody{
+			SrcTransaction: genesisTxn.Hash(),
+			Address:        addr,
+			Coins:          100e6,
+			Hours:          1000,
+		},
+	}
+
+	txn := coin.Transaction{
+		Length:    100,
+		InnerHash: testutil.RandSHA256(t),
+		In:        []cipher.SHA256{genesisUx.Hash()},
+		Out: []coin.TransactionOutput{
+			{
+				Address: addr,
+				Coins:   60e6,
+				Hours:   200,
+			},
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   40e6,
+				Hours:   150,
+			},
+		},
+	}
+
+	genesis := coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				Time:     1000,
+				BodyHash: testutil.RandSHA256(t),
+			},
+			Body: coin.BlockBody{
+				Transactions: coin.Transactions{genesisTxn},
+			},
+		},
+	}
+
+	return []visor.ExportedBlock{
+		{
+			Block:  genesis,
+			Inputs: make([][]visor.TransactionInput, 1),
+		},
+		{
+			Block: coin.SignedBlock{
+				Block: coin.Block{
+					Head: coin.BlockHeader{
+						BkSeq:    1,
+						Time:     4600,
+						Fee:      1650,
+						PrevHash: genesis.HashHeader(),
+						BodyHash: testutil.RandSHA256(t),
+					},
+					Body: coin.BlockBody{
+						Transactions: coin.Transactions{txn},
+					},
+				},
+			},
+			Inputs: [][]visor.TransactionInput{
+				{
+					{
+						UxOut:           genesisUx,
+						CalculatedHours: 2000,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestNewExportRows(t *testing.T) {
+	blocks := makeExportedBlocks(t)
+	genesisTxn := blocks[0].Block.Body.Transactions[0]
+	txn := blocks[1].Block.Body.Transactions[0]
+	ux := blocks[1].Inputs[0][0].UxOut
+
+	rows, err := NewExportRows(&blocks[0])
+	require.NoError(t, err)
+	require.Equal(t, &ExportRows{
+		Block: ExportBlock{
+			Seq:      0,
+			Hash:     blocks[0].Block.HashHeader().Hex(),
+			PrevHash: cipher.SHA256{}.Hex(),
+			BodyHash: blocks[0].Block.Head.BodyHash.Hex(),
+			Time:     1000,
+			TxnCount: 1,
+		},
+		Transactions: []ExportTransaction{
+			{
+				BlockSeq:    0,
+				TxID:        genesisTxn.Hash().Hex(),
+				InnerHash:   cipher.SHA256{}.Hex(),
+				OutputCount: 1,
+				Coins:       "100.000000",
+				HoursOut:    1000,
+			},
+		},
+		Outputs: []ExportOutput{
+			{
+				BlockSeq: 0,
+				TxID:     genesisTxn.Hash().Hex(),
+				UxID:     ux.Hash().Hex(),
+				Address:  ux.Body.Address.String(),
+				Coins:    "100.000000",
+				Hours:    1000,
+			},
+		},
+	}, rows)
+
+	rows, err = NewExportRows(&blocks[1])
+	require.NoError(t, err)
+	txid := txn.Hash()
+	require.Equal(t, &ExportRows{
+		Block: ExportBlock{
+			Seq:      1,
+			Hash:     blocks[1].Block.HashHeader().Hex(),
+			PrevHash: blocks[0].Block.HashHeader().Hex(),
+			BodyHash: blocks[1].Block.Head.BodyHash.Hex(),
+			Time:     4600,
+			Fee:      1650,
+			TxnCount: 1,
+		},
+		Transactions: []ExportTransaction{
+			{
+				BlockSeq:    1,
+				TxID:        txid.Hex(),
+				InnerHash:   txn.InnerHash.Hex(),
+				Length:      100,
+				InputCount:  1,
+				OutputCount: 2,
+				Coins:       "100.000000",
+				HoursIn:     2000,
+				HoursOut:    350,
+				Fee:         1650,
+			},
+		},
+		Inputs: []ExportInput{
+			{
+				BlockSeq:        1,
+				TxID:            txid.Hex(),
+				UxID:            ux.Hash().Hex(),
+				SrcTxID:         genesisTxn.Hash().Hex(),
+				SrcBlockSeq:     0,
+				Address:         ux.Body.Address.String(),
+				Coins:           "100.000000",
+				Hours:           1000,
+				CalculatedHours: 2000,
+			},
+		},
+		Outputs: []ExportOutput{
+			{
+				BlockSeq: 1,
+				TxID:     txid.Hex(),
+				Index:    0,
+				UxID:     txn.Out[0].UxID(txid).Hex(),
+				Address:  txn.Out[0].Address.String(),
+				Coins:    "60.000000",
+				Hours:    200,
+			},
+			{
+				BlockSeq: 1,
+				TxID:     txid.Hex(),
+				Index:    1,
+				UxID:     txn.Out[1].UxID(txid).Hex(),
+				Address:  txn.Out[1].Address.String(),
+				Coins:    "40.000000",
+				Hours:    150,
+			},
+		},
+	}, rows)
+
+	_, err = NewExportRows(&visor.ExportedBlock{
+		Block: blocks[1].Block,
+	})
+	require.EqualError(t, err, "block 1 has 1 transactions but inputs for 0 transactions")
+}
+
+func TestExportWriter(t *testing.T) {
+	blocks := makeExportedBlocks(t)
+	rows := make([]*ExportRows, len(blocks))
+	for i := range blocks {
+		var err error
+		rows[i], err = NewExportRows(&blocks[i])
+		require.NoError(t, err)
+	}
+
+	var buf bytes.Buffer
+	ew, err := NewExportWriter(&buf, ExportTableOutputs, ExportFormatCSV, true)
+	require.NoError(t, err)
+	for _, r := range rows {
+		require.NoError(t, ew.Write(r))
+	}
+	require.NoError(t, ew.Flush())
+
+	lines := []string{"block_seq,txid,index,uxid,address,coins,hours"}
+	for _, r := range rows {
+		for _, o := range r.Outputs {
+			lines = append(lines, fmt.Sprintf("%d,%s,%d,%s,%s,%s,%d", o.BlockSeq, o.TxID, o.Index, o.UxID, o.Address, o.Coins, o.Hours))
+		}
+	}
+	require.Equal(t, strings.Join(lines, "\n")+"\n", buf.String())
+
+	// The header is not written when appending to an export
+	buf.Reset()
+	ew, err = NewExportWriter(&buf, ExportTableBlocks, ExportFormatCSV, false)
+	require.NoError(t, err)
+	require.NoError(t, ew.Write(rows[1]))
+	require.NoError(t, ew.Flush())
+	b := rows[1].Block
+	require.Equal(t, fmt.Sprintf("1,%s,%s,%s,4600,1650,1\n", b.Hash, b.PrevHash, b.BodyHash), buf.String())
+
+	buf.Reset()
+	ew, err = NewExportWriter(&buf, ExportTableTransactions, ExportFormatJSONL, true)
+	require.NoError(t, err)
+	for _, r := range rows {
+		require.NoError(t, ew.Write(r))
+	}
+	require.NoError(t, ew.Flush())
+
+	dec := json.NewDecoder(&buf)
+	for _, r := range rows {
+		var txn ExportTransaction
+		require.NoError(t, dec.Decode(&txn))
+		require.Equal(t, r.Transactions[0], txn)
+	}
+	require.False(t, dec.More())
+
+	_, err = NewExportWriter(&buf, "foo", ExportFormatCSV, true)
+	require.EqualError(t, err, `invalid table "foo", must be blocks, transactions, inputs or outputs`)
+
+	_, err = NewExportWriter(&buf, ExportTableBlocks, "xml", true)
+	require.EqualError(t, err, `invalid format "xml", must be jsonl or csv`)
+}
+
+func TestExport(t *testing.T) {
+	blocks := makeExportedBlocks(t)
+
+	var blocksCSV bytes.Buffer
+	ew, err := NewExportWriter(&blocksCSV, ExportTableBlocks, ExportFormatCSV, true)
+	require.NoError(t, err)
+	for i := range blocks {
+		rows, err := NewExportRows(&blocks[i])
+		require.NoError(t, err)
+		require.NoError(t, ew.Write(rows))
+	}
+	require.NoError(t, ew.Flush())
+
+	cases := []struct {
+		name        string
+		method      string
+		query       string
+		status      int
+		start       uint64
+		end         uint64
+		exportErr   error
+		err         string
+		contentType string
+		body        string
+		nextSeq     string
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "Method Not Allowed",
+		},
+
+		{
+			name:   "400 - missing table",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    "table is required",
+		},
+
+		{
+			name:   "400 - invalid table",
+			method: http.MethodGet,
+			query:  "table=foo",
+			status: http.StatusBadRequest,
+			err:    "invalid 'table' value, must be blocks, transactions, inputs or outputs",
+		},
+
+		{
+			name:   "400 - invalid format",
+			method: http.MethodGet,
+			query:  "table=blocks&format=xml",
+			status: http.StatusBadRequest,
+			err:    "invalid 'format' value, must be jsonl or csv",
+		},
+
+		{
+			name:   "400 - invalid start",
+			method: http.MethodGet,
+			query:  "table=blocks&start=-1",
+			status: http.StatusBadRequest,
+			err:    "invalid 'start' value: strconv.ParseUint: parsing \"-1\": invalid syntax",
+		},
+
+		{
+			name:      "400 - start above the head block",
+			method:    http.MethodGet,
+			query:     "table=blocks&start=10",
+			status:    http.StatusBadRequest,
+			start:     10,
+			end:       10 + MaxExportBlocks - 1,
+			exportErr: visor.ErrExportStartNotFound,
+			err:       "start is above the head block",
+		},
+
+		{
+			name:      "500 - gateway error",
+			method:    http.MethodGet,
+			query:     "table=blocks",
+			status:    http.StatusInternalServerError,
+			end:       MaxExportBlocks - 1,
+			exportErr: errors.New("ExportChain failed"),
+			err:       "ExportChain failed",
+		},
+
+		{
+			name:        "200 - csv",
+			method:      http.MethodGet,
+			query:       "table=blocks&format=csv&start=0&end=1",
+			status:      http.StatusOK,
+			end:         1,
+			contentType: "text/csv",
+			body:        blocksCSV.String(),
+			nextSeq:     "2",
+		},
+
+		{
+			name:        "200 - end above the block limit",
+			method:      http.MethodGet,
+			query:       "table=blocks&format=csv&start=0&end=100000",
+			status:      http.StatusOK,
+			end:         MaxExportBlocks - 1,
+			contentType: "text/csv",
+			body:        blocksCSV.String(),
+			nextSeq:     "2",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("ExportChain", tc.start, tc.end, mock.Anything, mock.Anything).Return(
+				func(start, end uint64, quit <-chan struct{}, f func(*visor.ExportedBlock) error) error {
+					if tc.exportErr != nil {
+						return tc.exportErr
+					}
+					for i := range blocks {
+						if err := f(&blocks[i]); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+
+			endpoint := "/api/v2/export"
+			if tc.query != "" {
+				endpoint += "?" + tc.query
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				var rsp ReceivedHTTPResponse
+				err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.NotNil(t, rsp.Error)
+				require.Equal(t, tc.err, rsp.Error.Message)
+				return
+			}
+
+			require.Equal(t, tc.contentType, rr.Header().Get("Content-Type"))
+			require.Equal(t, tc.body, rr.Body.String())
+			require.Equal(t, tc.nextSeq, rr.Header().Get("X-Export-Next-Seq"))
+		})
+	}
+}
@@ -74,12 +74,18 @@ type Visorer interface {
 	GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [][][]visor.TransactionInput, error)
 	GetUnspentOutputsSummary(filters []visor.OutputsFilter) (*visor.UnspentOutputsSummary, error)
 	GetBalanceOfAddresses(addrs []cipher.Address) ([]wallet.BalancePair, error)
+	GetBalanceOfAddressesAt(addrs []cipher.Address, at visor.BlockAt) (*visor.HistoricalBalance, error)
+	GetBalanceSeriesOfAddresses(addrs []cipher.Address, q visor.BalanceSeriesQuery) ([]visor.BalanceSeriesPoint, error)
 	VerifyTxnVerbose(txn *coin.Transaction, signed transaction.TxnSignedFlag) ([]visor.TransactionInput, bool, error)
 	AddressCount() (uint64, error)
 	GetUxOutByID(id cipher.SHA256) (*historydb.UxOut, uint64, error)
 	GetSpentOutputsForAddresses(addr []cipher.Address) ([][]historydb.UxOut, uint64, error)
+	GetAddressStats(addr cipher.Address) (*historydb.AddressStats, error)
+	Search(q string) ([]visor.SearchResult, bool, error)
+	ExportChain(start, end uint64, quit <-chan struct{}, f func(*visor.ExportedBlock) error) error
 	GetRichlist(includeDistribution bool) (visor.Richlist, error)
 	GetAllUnconfirmedTransactions() ([]visor.UnconfirmedTransaction, error)
+	GetMempoolStats(now time.Time) (*visor.MempoolStats, error)
 	GetAllUnconfirmedTransactionsVerbose() ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
 	GetTransaction(txid cipher.SHA256) (*visor.Transaction, error)
 	GetTransactionWithInputs(txid cipher.SHA256) (*visor.Transaction, []visor.TransactionInput, error)
@@ -88,7 +94,11 @@ type Visorer interface {
 	GetTransactionsNum() (uint64, error)
 	GetWalletUnconfirmedTransactions(wltID string) ([]visor.UnconfirmedTransaction, error)
 	GetWalletUnconfirmedTransactionsVerbose(wltID string) ([]visor.UnconfirmedTransaction, [][]visor.TransactionInput, error)
+	GetWalletHistory(wltID string, order visor.SortOrder, page *visor.PageIndex) ([]visor.WalletTransaction, uint64, error)
 	GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error)
+	GetWalletAccountBalance(wltID string, account uint32) (wallet.BalancePair, wallet.AddressBalances, error)
+	GetWalletBalanceAt(wltID string, at visor.BlockAt) (*visor.HistoricalBalance, error)
+	GetWalletBalanceSeries(wltID string, q visor.BalanceSeriesQuery) ([]visor.BalanceSeriesPoint, error)
 	CreateTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletCreateTransaction(wltID string, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
 	WalletCreateTransactionSigned(wltID string, password []byte, p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error)
@@ -110,6 +120,15 @@ type Walleter interface {
 	GetWallet(wltID string) (wallet.Wallet, error)
 	GetWallets() (wallet.Wallets, error)
 	UpdateWalletLabel(wltID, label string) error
+	CreateAccount(wltID string, password []byte, name string) (wallet.Bip44Account, error)
+	UpdateAccountName(wltID string, account uint32, name string) error
+	UpdateAddressMeta(wltID string, addr cipher.Address, m wallet.EntryMeta) error
+	SetSpendingPolicy(wltID string, password []byte, p *wallet.SpendingPolicy) (*wallet.SpendingPolicy, error)
+	GetSpendingPolicy(wltID string) (*wallet.SpendingPolicy, []wallet.PolicySpend, error)
+	UnlockWallet(wltID string, password []byte, timeout time.Duration) (*wallet.UnlockSession, error)
+	LockWallet(wltID string) error
+	ExportWallets(wltIDs []string, notes map[string]map[string]string, password []byte) ([]byte, error)
+	ImportWallets(data, password []byte, conflict wallet.ImportConflict, walletPasswords map[string][]byte) (*wallet.ImportResult, error)
 	WalletDir() (string, error)
 }
 
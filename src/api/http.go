@@ -58,6 +58,8 @@ const (
 	EndpointsNetCtrl = "NET_CTRL"
 	// EndpointsStorage endpoints implement interface for key-value storage for arbitrary data
 	EndpointsStorage = "STORAGE"
+	// EndpointsPrometheus endpoints export metrics in the Prometheus text format, for monitoring systems
+	EndpointsPrometheus = "PROMETHEUS"
 )
 
 // Server exposes an HTTP API
@@ -82,6 +84,11 @@ type Config struct {
 	EnabledAPISets     map[string]struct{}
 	Username           string
 	Password           string
+	// APIKeysFile is the file of the API keys. API keys are disabled if empty.
+	// API keys require Username and Password, which are used to manage the keys.
+	APIKeysFile string
+	// RateLimit configures the rate limits of the clients and the concurrency limit of the heavy endpoints
+	RateLimit RateLimitConfig
 }
 
 // HealthConfig configuration data exposed in /health
@@ -103,6 +110,8 @@ type muxConfig struct {
 	hostWhitelist      []string
 	username           string
 	password           string
+	apiKeys            *APIKeyStore
+	rateLimiter        *rateLimiter
 	health             HealthConfig
 }
 
@@ -198,6 +207,30 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		c.IdleTimeout = defaultIdleTimeout
 	}
 
+	var apiKeys *APIKeyStore
+	if c.APIKeysFile != "" {
+		if c.Username == "" || c.Password == "" {
+			return nil, errors.New("API keys require a username and password to manage the keys")
+		}
+
+		var err error
+		apiKeys, err = NewAPIKeyStore(c.APIKeysFile)
+		if err != nil {
+			return nil, err
+		}
+		logger.Infof("API keys file: %s", c.APIKeysFile)
+	}
+
+	var rl *rateLimiter
+	if c.RateLimit.Enabled() {
+		if err := c.RateLimit.Validate(); err != nil {
+			return nil, err
+		}
+		rl = newRateLimiter(c.RateLimit)
+		logger.Infof("Rate limit: %d requests per minute, endpoint rate limits: %v, max heavy requests: %d",
+			c.RateLimit.Rate, c.RateLimit.EndpointRates, c.RateLimit.MaxHeavyRequests)
+	}
+
 	mc := muxConfig{
 		host:               host,
 		appLoc:             appLoc,
@@ -210,11 +243,12 @@ func create(host string, c Config, gateway Gatewayer) (*Server, error) {
 		hostWhitelist:      c.HostWhitelist,
 		username:           c.Username,
 		password:           c.Password,
+		apiKeys:            apiKeys,
+		rateLimiter:        rl,
 	}
 
-	srvMux := newServerMux(mc, gateway)
 	srv := &http.Server{
-		Handler:      srvMux,
+		Handler:      newServerHandler(mc, gateway),
 		ReadTimeout:  c.ReadTimeout,
 		WriteTimeout: c.WriteTimeout,
 		IdleTimeout:  c.IdleTimeout,
@@ -411,12 +445,25 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 			handler = ContentTypeJSONRequired(handler)
 		}
 
+		if c.rateLimiter != nil {
+			handler = rateLimitCheck(apiVersion, endpoint, c.rateLimiter, handler)
+		}
+
 		handler = basicAuth(apiVersion, c.username, c.password, "skycoin daemon", handler)
 		handler = gziphandler.New(handler)
 		mux.Handle(endpoint, handler)
 	}
 
+	// routes are the API routes, described by the OpenAPI specification
+	var routes []openAPIRoute
+
 	webHandler := func(apiVersion, endpoint string, handler http.Handler, methodAPISets map[string][]string) {
+		routes = append(routes, openAPIRoute{
+			apiVersion:    apiVersion,
+			endpoint:      endpoint,
+			methodAPISets: methodAPISets,
+		})
+
 		// methodAPISets can be nil to ignore the concept of API sets for an endpoint. It will always be enabled.
 		// Explicitly check nil, caller should not pass empty initialized map
 		if methodAPISets != nil {
@@ -438,7 +485,12 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	if !c.disableCSP {
 		indexHandler = CSPHandler(indexHandler, ContentSecurityPolicy)
 	}
-	webHandler(apiVersion1, "/", indexHandler, nil)
+	// The GUI is not part of the API, it is always enabled
+	guiHandler := func(endpoint string, handler http.Handler) {
+		webHandlerWithOptionals(apiVersion1, endpoint, handler, true, !c.disableHeaderCheck)
+	}
+
+	guiHandler("/", indexHandler)
 
 	if c.enableGUI {
 		fileInfos, err := ioutil.ReadDir(c.appLoc)
@@ -457,12 +509,16 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 				route = route + "/"
 			}
 
-			webHandler(apiVersion1, route, fs, nil)
+			guiHandler(route, fs)
 		}
 	}
 
 	// get the current CSRF token
 	csrfHandlerV1 := func(endpoint string, handler http.Handler) {
+		routes = append(routes, openAPIRoute{
+			apiVersion: apiVersion1,
+			endpoint:   "/api/v1" + endpoint,
+		})
 		webHandlerWithOptionals(apiVersion1, "/api/v1"+endpoint, handler, false, !c.disableHeaderCheck)
 	}
 	csrfHandlerV1("/csrf", getCSRFToken(c.disableCSRF)) // csrf is always available, regardless of the API set
@@ -473,6 +529,18 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		http.MethodGet: {EndpointsRead, EndpointsStatus},
 	})
 
+	// Prometheus metrics, served at the conventional /metrics path
+	webHandler(apiVersion1, "/metrics", metricsHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsPrometheus},
+	})
+
+	// JSON-RPC 2.0 interface, its methods are enabled by the API sets of their REST endpoints
+	webHandler(apiVersion2, "/rpc", rpcHandler(c, gateway), nil)
+
+	// API key management, enabled by -web-interface-api-keys instead of an API set
+	webHandlerV2("/apikeys", apiKeysHandler(c), nil)
+	webHandlerV2("/apikeys/revoke", apiKeyRevokeHandler(c), nil)
+
 	// Wallet endpoints
 	webHandlerV1("/wallet", walletHandler(gateway), map[string][]string{
 		http.MethodGet: {EndpointsWallet},
@@ -519,6 +587,9 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/wallet/seed/verify", http.HandlerFunc(walletVerifySeedHandler), map[string][]string{
 		http.MethodPost: {EndpointsWallet},
 	})
+	webHandlerV2("/wallet/seed/shares", walletSeedSharesHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsInsecureWalletSeed},
+	})
 
 	webHandlerV1("/wallet/unload", walletUnloadHandler(gateway), map[string][]string{
 		http.MethodPost: {EndpointsWallet},
@@ -532,6 +603,50 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/wallet/recover", walletRecoverHandler(gateway), map[string][]string{
 		http.MethodPost: {EndpointsWallet},
 	})
+	webHandlerV2("/wallet/accounts", walletAccountsHandler(gateway), map[string][]string{
+		http.MethodGet:  {EndpointsWallet},
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/accounts/update", walletAccountUpdateHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/accounts/balance", walletAccountBalanceHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/accounts/addresses", walletAccountNewAddressesHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/history", walletHistoryHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/balance/at", walletBalanceAtHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/balance/series", walletBalanceSeriesHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/addresses", walletAddressesHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/address/update", walletAddressUpdateHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/policy", walletPolicyHandler(gateway), map[string][]string{
+		http.MethodGet:  {EndpointsWallet},
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/unlock", walletUnlockHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/lock", walletLockHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/export", walletExportHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsWallet},
+	})
+	webHandlerV2("/wallet/import", walletImportHandler(gateway), map[string][]string{
+		http.MethodPost: {EndpointsWallet},
+	})
 
 	// Blockchain interface
 	webHandlerV1("/blockchain/metadata", blockchainMetadataHandler(gateway), map[string][]string{
@@ -577,6 +692,9 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV1("/pendingTxs", pendingTxnsHandler(gateway), map[string][]string{
 		http.MethodGet: {EndpointsRead},
 	})
+	webHandlerV2("/mempool/stats", mempoolStatsHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsRead},
+	})
 	webHandlerV1("/transaction", transactionHandler(gateway), map[string][]string{
 		http.MethodGet: {EndpointsRead},
 	})
@@ -628,6 +746,27 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 	webHandlerV2("/address/verify", http.HandlerFunc(addressVerifyHandler), map[string][]string{
 		http.MethodPost: {EndpointsRead},
 	})
+	webHandlerV2("/address/", addressResourcesHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsRead},
+	})
+
+	// Historical balance endpoints
+	webHandlerV2("/balance/at", balanceAtHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsRead},
+	})
+	webHandlerV2("/balance/series", balanceSeriesHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsRead},
+	})
+
+	// Search endpoint
+	webHandlerV2("/search", searchHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsRead},
+	})
+
+	// Export endpoint
+	webHandlerV2("/export", exportHandler(gateway), map[string][]string{
+		http.MethodGet: {EndpointsRead},
+	})
 
 	// Explorer endpoints
 	webHandlerV1("/coinSupply", coinSupplyHandler(gateway), map[string][]string{
@@ -647,6 +786,9 @@ func newServerMux(c muxConfig, gateway Gatewayer) *http.ServeMux {
 		http.MethodDelete: {EndpointsStorage},
 	})
 
+	// OpenAPI specification of the routes above, always available
+	webHandler(apiVersion1, openAPIEndpoint, openAPIHandler(c.health.BuildInfo.Version, &routes), nil)
+
 	return mux
 }
 
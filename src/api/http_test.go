@@ -22,6 +22,7 @@ var allAPISetsEnabled = map[string]struct{}{
 	EndpointsInsecureWalletSeed: struct{}{},
 	EndpointsNetCtrl:            struct{}{},
 	EndpointsStorage:            struct{}{},
+	EndpointsPrometheus:         struct{}{},
 }
 
 func defaultMuxConfig() muxConfig {
@@ -153,25 +154,109 @@ var endpointsMethods = map[string][]string{
 	"/api/v1/wallets/folderName": []string{
 		http.MethodGet,
 	},
+	"/metrics": []string{
+		http.MethodGet,
+	},
+	"/rpc": []string{
+		http.MethodPost,
+	},
+	"/api/openapi.json": []string{
+		http.MethodGet,
+	},
 
+	"/api/v2/apikeys": []string{
+		http.MethodGet,
+		http.MethodPost,
+	},
+	"/api/v2/apikeys/revoke": []string{
+		http.MethodPost,
+	},
 	"/api/v2/transaction/verify": []string{
 		http.MethodPost,
 	},
 	"/api/v2/address/verify": []string{
 		http.MethodPost,
 	},
+	"/api/v2/address/{addr}/stats": []string{
+		http.MethodGet,
+	},
+	"/api/v2/balance/at": []string{
+		http.MethodGet,
+	},
+	"/api/v2/balance/series": []string{
+		http.MethodGet,
+	},
+	"/api/v2/search": []string{
+		http.MethodGet,
+	},
+	"/api/v2/export": []string{
+		http.MethodGet,
+	},
+	"/api/v2/mempool/stats": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/balance/at": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/balance/series": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/accounts": []string{
+		http.MethodGet,
+		http.MethodPost,
+	},
+	"/api/v2/wallet/accounts/addresses": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/accounts/balance": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/accounts/update": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/address/update": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/addresses": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/export": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/history": []string{
+		http.MethodGet,
+	},
+	"/api/v2/wallet/import": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/lock": []string{
+		http.MethodPost,
+	},
+	"/api/v2/wallet/policy": []string{
+		http.MethodGet,
+		http.MethodPost,
+	},
 	"/api/v2/wallet/recover": []string{
 		http.MethodPost,
 	},
+	"/api/v2/wallet/seed/shares": []string{
+		http.MethodPost,
+	},
 	"/api/v2/wallet/seed/verify": []string{
 		http.MethodPost,
 	},
 	"/api/v2/wallet/transaction/sign": []string{
 		http.MethodPost,
 	},
+	"/api/v2/wallet/unlock": []string{
+		http.MethodPost,
+	},
 	"/api/v2/transaction": []string{
 		http.MethodPost,
 	},
+	"/api/v2/transactions": []string{
+		http.MethodGet,
+	},
 
 	"/api/v2/data": []string{
 		http.MethodGet,
@@ -180,6 +265,11 @@ var endpointsMethods = map[string][]string{
 	},
 }
 
+// isAPIV2Endpoint returns true if the endpoint responds in the API v2 format
+func isAPIV2Endpoint(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "/api/v2") || endpoint == "/rpc"
+}
+
 func allEndpoints() []string {
 	endpoints := make([]string, len(endpointsMethods))
 	i := 0
@@ -292,10 +382,10 @@ func TestEnableGUI(t *testing.T) {
 
 func TestAPISetDisabled(t *testing.T) {
 	tf := func(t *testing.T, endpoint, method string, disableCSRF bool) {
-		req, err := http.NewRequest(method, endpoint, nil)
+		req, err := http.NewRequest(method, endpoint, http.NoBody)
 		require.NoError(t, err)
 
-		isAPIV2 := strings.HasPrefix(endpoint, "/api/v2/")
+		isAPIV2 := isAPIV2Endpoint(endpoint)
 		if isAPIV2 {
 			req.Header.Set("Content-Type", ContentTypeJSON)
 		}
@@ -310,7 +400,7 @@ func TestAPISetDisabled(t *testing.T) {
 		handler.ServeHTTP(rr, req)
 
 		switch endpoint {
-		case "/api/v1/csrf", "/api/v1/version": // always enabled
+		case "/api/v1/csrf", "/api/v1/version", "/rpc", "/api/openapi.json": // always enabled
 			require.Equal(t, http.StatusOK, rr.Code)
 		default:
 			require.Equal(t, http.StatusForbidden, rr.Code)
@@ -374,7 +464,7 @@ func TestCORS(t *testing.T) {
 
 					setCSRFParameters(t, tokenValid, req)
 
-					isAPIV2 := strings.HasPrefix(e, "/api/v2/")
+					isAPIV2 := isAPIV2Endpoint(e)
 					if isAPIV2 {
 						req.Header.Set("Content-Type", ContentTypeJSON)
 					}
@@ -516,7 +606,7 @@ func TestHTTPBasicAuthInvalid(t *testing.T) {
 
 				if !tc.authorized {
 					require.Equal(t, http.StatusUnauthorized, rr.Code)
-					if strings.HasPrefix(e, "/api/v2") {
+					if isAPIV2Endpoint(e) {
 						require.Equal(t, "{\n    \"error\": {\n        \"message\": \"Unauthorized\",\n        \"code\": 401\n    }\n}", rr.Body.String())
 					} else {
 						require.Equal(t, "401 Unauthorized", strings.TrimSpace(rr.Body.String()))
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/skycoin/skycoin/src/util/timeutil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+// MempoolFeeRateBucket is a bucket of the fee rate histogram of MempoolStatsResponse
+type MempoolFeeRateBucket struct {
+	// MinFeeRate is the inclusive lower bound of the bucket, in coin hours per kB
+	MinFeeRate uint64 `json:"min_fee_rate"`
+	// MaxFeeRate is the exclusive upper bound of the bucket, omitted for the last bucket
+	MaxFeeRate *uint64 `json:"max_fee_rate,omitempty"`
+	Count      uint64  `json:"count"`
+	Bytes      uint64  `json:"bytes"`
+}
+
+// MempoolAgeBucket is a bucket of the age distribution of MempoolStatsResponse
+type MempoolAgeBucket struct {
+	// MinAge is the inclusive lower bound of the bucket, in seconds
+	MinAge uint64 `json:"min_age"`
+	// MaxAge is the exclusive upper bound of the bucket, in seconds, omitted for the last bucket
+	MaxAge *uint64 `json:"max_age,omitempty"`
+	Count  uint64  `json:"count"`
+}
+
+// MempoolOldestTransaction is the transaction of the pool that was received the longest ago
+type MempoolOldestTransaction struct {
+	TxID     string    `json:"txid"`
+	Received time.Time `json:"received"`
+	// Age is the time since the transaction was received, in seconds
+	Age     uint64 `json:"age"`
+	IsValid bool   `json:"is_valid"`
+}
+
+// MempoolStatsResponse is returned by GET /api/v2/mempool/stats
+type MempoolStatsResponse struct {
+	Count        uint64                    `json:"count"`
+	Bytes        uint64                    `json:"bytes"`
+	ValidCount   uint64                    `json:"valid_count"`
+	ValidBytes   uint64                    `json:"valid_bytes"`
+	InvalidCount uint64                    `json:"invalid_count"`
+	FeeRates     []MempoolFeeRateBucket    `json:"fee_rates"`
+	Ages         []MempoolAgeBucket        `json:"ages"`
+	Invalid      map[string]uint64         `json:"invalid_reasons"`
+	Oldest       *MempoolOldestTransaction `json:"oldest,omitempty"`
+	// BlocksToClear is the projected number of blocks to confirm the valid transactions
+	BlocksToClear            uint64 `json:"blocks_to_clear"`
+	MaxBlockTransactionsSize uint32 `json:"max_block_transactions_size"`
+}
+
+// NewMempoolStatsResponse creates a MempoolStatsResponse from visor.MempoolStats, with ages calculated at now
+func NewMempoolStatsResponse(s *visor.MempoolStats, now time.Time) *MempoolStatsResponse {
+	rsp := &MempoolStatsResponse{
+		Count:                    s.Count,
+		Bytes:                    s.Bytes,
+		ValidCount:               s.ValidCount,
+		ValidBytes:               s.ValidBytes,
+		InvalidCount:             s.Count - s.ValidCount,
+		FeeRates:                 make([]MempoolFeeRateBucket, len(s.FeeRateCounts)),
+		Ages:                     make([]MempoolAgeBucket, len(s.AgeCounts)),
+		Invalid:                  s.InvalidReasons,
+		BlocksToClear:            s.BlocksToClear,
+		MaxBlockTransactionsSize: s.MaxBlockTransactionsSize,
+	}
+
+	for i := range s.FeeRateCounts {
+		b := MempoolFeeRateBucket{
+			Count: s.FeeRateCounts[i],
+			Bytes: s.FeeRateBytes[i],
+		}
+		if i > 0 {
+			b.MinFeeRate = visor.MempoolFeeRateBounds[i-1]
+		}
+		if i < len(visor.MempoolFeeRateBounds) {
+			max := visor.MempoolFeeRateBounds[i]
+			b.MaxFeeRate = &max
+		}
+		rsp.FeeRates[i] = b
+	}
+
+	for i := range s.AgeCounts {
+		b := MempoolAgeBucket{
+			Count: s.AgeCounts[i],
+		}
+		if i > 0 {
+			b.MinAge = uint64(visor.MempoolAgeBounds[i-1] / time.Second)
+		}
+		if i < len(visor.MempoolAgeBounds) {
+			max := uint64(visor.MempoolAgeBounds[i] / time.Second)
+			b.MaxAge = &max
+		}
+		rsp.Ages[i] = b
+	}
+
+	if s.Oldest != nil {
+		received := timeutil.NanoToTime(s.Oldest.Received)
+		var age uint64
+		if d := now.Sub(received); d > 0 {
+			age = uint64(d / time.Second)
+		}
+
+		rsp.Oldest = &MempoolOldestTransaction{
+			TxID:     s.Oldest.Transaction.Hash().Hex(),
+			Received: received,
+			Age:      age,
+			IsValid:  visor.IsValid(*s.Oldest),
+		}
+	}
+
+	return rsp
+}
+
+// mempoolStatsHandler returns aggregated statistics of the unconfirmed transaction pool
+// URI: /api/v2/mempool/stats
+// Method: GET
+func mempoolStatsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		now := time.Now()
+		stats, err := gateway.GetMempoolStats(now)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: NewMempoolStatsResponse(stats, now),
+		})
+	}
+}
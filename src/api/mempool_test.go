@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestMempoolStats(t *testing.T) {
+	txn := coin.Transaction{
+		InnerHash: testutil.RandSHA256(t),
+	}
+	received := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	stats := &visor.MempoolStats{
+		Count:                    3,
+		Bytes:                    600,
+		ValidCount:               2,
+		ValidBytes:               400,
+		FeeRateCounts:            []uint64{0, 0, 1, 1, 0, 0, 0, 0},
+		FeeRateBytes:             []uint64{0, 0, 200, 200, 0, 0, 0, 0},
+		AgeCounts:                []uint64{1, 0, 0, 0, 0, 2},
+		InvalidReasons:           map[string]uint64{visor.InvalidReasonMissingInput: 1},
+		BlocksToClear:            1,
+		MaxBlockTransactionsSize: 32768,
+		Oldest: &visor.UnconfirmedTransaction{
+			Transaction: txn,
+			Received:    received.UnixNano(),
+			IsValid:     1,
+		},
+	}
+
+	u := func(v uint64) *uint64 {
+		return &v
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		status   int
+		stats    *visor.MempoolStats
+		statsErr error
+		err      string
+		rsp      *MempoolStatsResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "Method Not Allowed",
+		},
+
+		{
+			name:     "500 - gateway error",
+			method:   http.MethodGet,
+			status:   http.StatusInternalServerError,
+			statsErr: errors.New("GetMempoolStats failed"),
+			err:      "GetMempoolStats failed",
+		},
+
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			stats:  stats,
+			rsp: &MempoolStatsResponse{
+				Count:        3,
+				Bytes:        600,
+				ValidCount:   2,
+				ValidBytes:   400,
+				InvalidCount: 1,
+				FeeRates: []MempoolFeeRateBucket{
+					{MinFeeRate: 0, MaxFeeRate: u(1)},
+					{MinFeeRate: 1, MaxFeeRate: u(10)},
+					{MinFeeRate: 10, MaxFeeRate: u(100), Count: 1, Bytes: 200},
+					{MinFeeRate: 100, MaxFeeRate: u(1000), Count: 1, Bytes: 200},
+					{MinFeeRate: 1000, MaxFeeRate: u(10000)},
+					{MinFeeRate: 10000, MaxFeeRate: u(100000)},
+					{MinFeeRate: 100000, MaxFeeRate: u(1000000)},
+					{MinFeeRate: 1000000},
+				},
+				Ages: []MempoolAgeBucket{
+					{MinAge: 0, MaxAge: u(60), Count: 1},
+					{MinAge: 60, MaxAge: u(600)},
+					{MinAge: 600, MaxAge: u(3600)},
+					{MinAge: 3600, MaxAge: u(21600)},
+					{MinAge: 21600, MaxAge: u(86400)},
+					{MinAge: 86400, Count: 2},
+				},
+				Invalid: map[string]uint64{
+					"missing_input": 1,
+				},
+				Oldest: &MempoolOldestTransaction{
+					TxID:     txn.Hash().Hex(),
+					Received: received,
+					IsValid:  true,
+				},
+				BlocksToClear:            1,
+				MaxBlockTransactionsSize: 32768,
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetMempoolStats", mock.AnythingOfType("time.Time")).Return(tc.stats, tc.statsErr)
+
+			req, err := http.NewRequest(tc.method, "/api/v2/mempool/stats", nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			if tc.status != http.StatusOK {
+				require.NotNil(t, rsp.Error)
+				require.Equal(t, tc.err, rsp.Error.Message)
+				return
+			}
+
+			var statsRsp MempoolStatsResponse
+			err = json.Unmarshal(rsp.Data, &statsRsp)
+			require.NoError(t, err)
+
+			// The age of the oldest transaction depends on the time of the request
+			require.NotNil(t, statsRsp.Oldest)
+			require.True(t, statsRsp.Oldest.Age >= uint64(time.Since(received)/time.Second)-60)
+			statsRsp.Oldest.Age = 0
+			statsRsp.Oldest.Received = statsRsp.Oldest.Received.UTC()
+
+			require.Equal(t, *tc.rsp, statsRsp)
+		})
+	}
+}
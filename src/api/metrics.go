@@ -0,0 +1,120 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/daemon"
+	wh "github.com/skycoin/skycoin/src/util/http"
+	"github.com/skycoin/skycoin/src/util/metrics"
+)
+
+// getNodeMetrics returns gauges of the node's current state, which are sampled when /metrics is requested
+func getNodeMetrics(gateway Gatewayer) ([]metrics.Metric, error) {
+	metadata, err := gateway.GetBlockchainMetadata()
+	if err != nil {
+		return nil, fmt.Errorf("gateway.GetBlockchainMetadata failed: %v", err)
+	}
+
+	headSeq := metrics.NewGauge("skycoin_blockchain_head_seq", "Sequence number of the head block")
+	headSeq.Set(float64(metadata.HeadBlock.Head.BkSeq))
+
+	headTime := metrics.NewGauge("skycoin_blockchain_head_time_seconds", "Timestamp of the head block")
+	headTime.Set(float64(metadata.HeadBlock.Head.Time))
+
+	unspents := metrics.NewGauge("skycoin_blockchain_unspent_outputs", "Number of unspent outputs")
+	unspents.Set(float64(metadata.Unspents))
+
+	txns, err := gateway.GetAllUnconfirmedTransactions()
+	if err != nil {
+		return nil, fmt.Errorf("gateway.GetAllUnconfirmedTransactions failed: %v", err)
+	}
+
+	var txnsBytes uint64
+	for _, txn := range txns {
+		size, err := txn.Transaction.Size()
+		if err != nil {
+			return nil, fmt.Errorf("txn.Size failed: %v", err)
+		}
+		txnsBytes += uint64(size)
+	}
+
+	unconfirmed := metrics.NewGauge("skycoin_unconfirmed_transactions", "Number of transactions in the unconfirmed pool")
+	unconfirmed.Set(float64(len(txns)))
+
+	unconfirmedBytes := metrics.NewGauge("skycoin_unconfirmed_transactions_bytes", "Serialized size of the transactions in the unconfirmed pool")
+	unconfirmedBytes.Set(float64(txnsBytes))
+
+	conns, err := gateway.GetConnections(func(c daemon.Connection) bool {
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gateway.GetConnections failed: %v", err)
+	}
+
+	connections := metrics.NewGaugeVec("skycoin_connections", "Number of peer connections, by direction and state", "direction", "state")
+	// Report every direction and state, so that the series exist when there are no connections
+	for _, direction := range []string{"incoming", "outgoing"} {
+		for _, state := range []daemon.ConnectionState{
+			daemon.ConnectionStatePending,
+			daemon.ConnectionStateConnected,
+			daemon.ConnectionStateIntroduced,
+		} {
+			connections.WithLabelValues(direction, string(state))
+		}
+	}
+	for _, c := range conns {
+		direction := "incoming"
+		if c.Outgoing {
+			direction = "outgoing"
+		}
+		connections.WithLabelValues(direction, string(c.State)).Add(1)
+	}
+
+	return []metrics.Metric{
+		headSeq,
+		headTime,
+		unspents,
+		unconfirmed,
+		unconfirmedBytes,
+		connections,
+	}, nil
+}
+
+// metricsHandler returns node metrics in the Prometheus text exposition format.
+// The blockchain, unconfirmed pool and connection gauges are sampled on request,
+// the other metrics are updated by the node as events happen.
+// URI: /metrics
+// Method: GET
+func metricsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		ms, err := getNodeMetrics(gateway)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		ms = append(ms, metrics.DefaultRegistry.Metrics()...)
+		sort.Slice(ms, func(i, j int) bool {
+			return ms[i].Name() < ms[j].Name()
+		})
+
+		var buf bytes.Buffer
+		if err := metrics.WriteText(&buf, ms...); err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		w.Header().Set("Content-Type", metrics.ContentType)
+		if _, err := w.Write(buf.Bytes()); err != nil {
+			logger.WithError(err).Error("http Write failed")
+		}
+	}
+}
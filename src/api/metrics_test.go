@@ -0,0 +1,181 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/metrics"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	metadata := &visor.BlockchainMetadata{
+		HeadBlock: coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: 21175,
+					Time:  1523168686,
+				},
+			},
+		},
+		Unspents:    10,
+		Unconfirmed: 2,
+	}
+
+	txn := coin.Transaction{
+		In: []cipher.SHA256{testutil.RandSHA256(t)},
+	}
+	txnSize, err := txn.Size()
+	require.NoError(t, err)
+
+	unconfirmed := []visor.UnconfirmedTransaction{
+		{Transaction: txn},
+		{Transaction: txn},
+	}
+
+	conns := []daemon.Connection{
+		{
+			ConnectionDetails: daemon.ConnectionDetails{
+				Outgoing: true,
+				State:    daemon.ConnectionStateIntroduced,
+			},
+		},
+		{
+			ConnectionDetails: daemon.ConnectionDetails{
+				Outgoing: true,
+				State:    daemon.ConnectionStateIntroduced,
+			},
+		},
+		{
+			ConnectionDetails: daemon.ConnectionDetails{
+				Outgoing: false,
+				State:    daemon.ConnectionStatePending,
+			},
+		},
+	}
+
+	cases := []struct {
+		name                             string
+		method                           string
+		status                           int
+		err                              string
+		getBlockchainMetadataErr         error
+		getAllUnconfirmedTransactionsErr error
+		getConnectionsErr                error
+		disabled                         bool
+		contains                         []string
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    "405 Method Not Allowed",
+		},
+		{
+			name:     "403 endpoint disabled",
+			method:   http.MethodGet,
+			status:   http.StatusForbidden,
+			err:      "403 Forbidden - Endpoint is disabled",
+			disabled: true,
+		},
+		{
+			name:                     "500 gateway.GetBlockchainMetadata failed",
+			method:                   http.MethodGet,
+			status:                   http.StatusInternalServerError,
+			err:                      "500 Internal Server Error - gateway.GetBlockchainMetadata failed: GetBlockchainMetadata failed",
+			getBlockchainMetadataErr: errors.New("GetBlockchainMetadata failed"),
+		},
+		{
+			name:                             "500 gateway.GetAllUnconfirmedTransactions failed",
+			method:                           http.MethodGet,
+			status:                           http.StatusInternalServerError,
+			err:                              "500 Internal Server Error - gateway.GetAllUnconfirmedTransactions failed: GetAllUnconfirmedTransactions failed",
+			getAllUnconfirmedTransactionsErr: errors.New("GetAllUnconfirmedTransactions failed"),
+		},
+		{
+			name:              "500 gateway.GetConnections failed",
+			method:            http.MethodGet,
+			status:            http.StatusInternalServerError,
+			err:               "500 Internal Server Error - gateway.GetConnections failed: GetConnections failed",
+			getConnectionsErr: errors.New("GetConnections failed"),
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			status: http.StatusOK,
+			contains: []string{
+				"# TYPE skycoin_blockchain_head_seq gauge\nskycoin_blockchain_head_seq 21175\n",
+				"skycoin_blockchain_head_time_seconds 1.523168686e+09\n",
+				"skycoin_blockchain_unspent_outputs 10\n",
+				"skycoin_unconfirmed_transactions 2\n",
+				fmt.Sprintf("skycoin_unconfirmed_transactions_bytes %d\n", 2*txnSize),
+				`skycoin_connections{direction="incoming",state="connected"} 0` + "\n",
+				`skycoin_connections{direction="incoming",state="pending"} 1` + "\n",
+				`skycoin_connections{direction="outgoing",state="introduced"} 2` + "\n",
+				"# TYPE skycoin_test_total counter\nskycoin_test_total 3\n",
+			},
+		},
+	}
+
+	// Metrics registered with the default registry are included in the response
+	c := metrics.MustRegisterCounter(metrics.NewCounter("skycoin_test_total", "A test counter"))
+	defer metrics.DefaultRegistry.Unregister("skycoin_test_total")
+	c.Add(3)
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetBlockchainMetadata").Return(metadata, tc.getBlockchainMetadataErr)
+			if tc.getAllUnconfirmedTransactionsErr != nil {
+				gateway.On("GetAllUnconfirmedTransactions").Return(nil, tc.getAllUnconfirmedTransactionsErr)
+			} else {
+				gateway.On("GetAllUnconfirmedTransactions").Return(unconfirmed, nil)
+			}
+			if tc.getConnectionsErr != nil {
+				gateway.On("GetConnections", mock.Anything).Return(nil, tc.getConnectionsErr)
+			} else {
+				gateway.On("GetConnections", mock.Anything).Return(conns, nil)
+			}
+
+			req, err := http.NewRequest(tc.method, "/metrics", nil)
+			require.NoError(t, err)
+
+			cfg := defaultMuxConfig()
+			if tc.disabled {
+				cfg.enabledAPISets = map[string]struct{}{
+					EndpointsRead:   struct{}{},
+					EndpointsStatus: struct{}{},
+				}
+			}
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(cfg, gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			if status != http.StatusOK {
+				require.Equal(t, tc.err, strings.TrimSpace(rr.Body.String()))
+				return
+			}
+
+			require.Equal(t, metrics.ContentType, rr.Header().Get("Content-Type"))
+			body := rr.Body.String()
+			for _, s := range tc.contains {
+				require.Contains(t, body, s)
+			}
+		})
+	}
+}
@@ -80,7 +80,7 @@ func TestOriginRefererCheck(t *testing.T) {
 
 				setCSRFParameters(t, tokenValid, req)
 
-				isAPIV2 := strings.HasPrefix(endpoint, "/api/v2")
+				isAPIV2 := isAPIV2Endpoint(endpoint)
 				if isAPIV2 {
 					req.Header.Set("Content-Type", ContentTypeJSON)
 				}
@@ -164,12 +164,12 @@ func TestHostCheck(t *testing.T) {
 				t.Run(name, func(t *testing.T) {
 					gateway := &MockGatewayer{}
 
-					req, err := http.NewRequest(m, endpoint, nil)
+					req, err := http.NewRequest(m, endpoint, http.NoBody)
 					require.NoError(t, err)
 
 					setCSRFParameters(t, tokenValid, req)
 
-					isAPIV2 := strings.HasPrefix(endpoint, "/api/v2")
+					isAPIV2 := isAPIV2Endpoint(endpoint)
 					if isAPIV2 {
 						req.Header.Set("Content-Type", ContentTypeJSON)
 					}
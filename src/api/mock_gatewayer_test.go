@@ -63,6 +63,27 @@ func (_m *MockGatewayer) AddressCount() (uint64, error) {
 	return r0, r1
 }
 
+// CreateAccount provides a mock function with given fields: wltID, password, name
+func (_m *MockGatewayer) CreateAccount(wltID string, password []byte, name string) (wallet.Bip44Account, error) {
+	ret := _m.Called(wltID, password, name)
+
+	var r0 wallet.Bip44Account
+	if rf, ok := ret.Get(0).(func(string, []byte, string) wallet.Bip44Account); ok {
+		r0 = rf(wltID, password, name)
+	} else {
+		r0 = ret.Get(0).(wallet.Bip44Account)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, string) error); ok {
+		r1 = rf(wltID, password, name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateTransaction provides a mock function with given fields: p, wp
 func (_m *MockGatewayer) CreateTransaction(p transaction.Params, wp visor.CreateTransactionParams) (*coin.Transaction, []visor.TransactionInput, error) {
 	ret := _m.Called(p, wp)
@@ -192,6 +213,66 @@ func (_m *MockGatewayer) EncryptWallet(wltID string, password []byte) (wallet.Wa
 	return r0, r1
 }
 
+// ExportChain provides a mock function with given fields: start, end, quit, f
+func (_m *MockGatewayer) ExportChain(start uint64, end uint64, quit <-chan struct{}, f func(*visor.ExportedBlock) error) error {
+	ret := _m.Called(start, end, quit, f)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(uint64, uint64, <-chan struct{}, func(*visor.ExportedBlock) error) error); ok {
+		r0 = rf(start, end, quit, f)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportWallets provides a mock function with given fields: wltIDs, notes, password
+func (_m *MockGatewayer) ExportWallets(wltIDs []string, notes map[string]map[string]string, password []byte) ([]byte, error) {
+	ret := _m.Called(wltIDs, notes, password)
+
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func([]string, map[string]map[string]string, []byte) []byte); ok {
+		r0 = rf(wltIDs, notes, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]string, map[string]map[string]string, []byte) error); ok {
+		r1 = rf(wltIDs, notes, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAddressStats provides a mock function with given fields: addr
+func (_m *MockGatewayer) GetAddressStats(addr cipher.Address) (*historydb.AddressStats, error) {
+	ret := _m.Called(addr)
+
+	var r0 *historydb.AddressStats
+	if rf, ok := ret.Get(0).(func(cipher.Address) *historydb.AddressStats); ok {
+		r0 = rf(addr)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*historydb.AddressStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(cipher.Address) error); ok {
+		r1 = rf(addr)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAllStorageValues provides a mock function with given fields: storageType
 func (_m *MockGatewayer) GetAllStorageValues(storageType kvstorage.Type) (map[string]string, error) {
 	ret := _m.Called(storageType)
@@ -293,6 +374,52 @@ func (_m *MockGatewayer) GetBalanceOfAddresses(addrs []cipher.Address) ([]wallet
 	return r0, r1
 }
 
+// GetBalanceOfAddressesAt provides a mock function with given fields: addrs, at
+func (_m *MockGatewayer) GetBalanceOfAddressesAt(addrs []cipher.Address, at visor.BlockAt) (*visor.HistoricalBalance, error) {
+	ret := _m.Called(addrs, at)
+
+	var r0 *visor.HistoricalBalance
+	if rf, ok := ret.Get(0).(func([]cipher.Address, visor.BlockAt) *visor.HistoricalBalance); ok {
+		r0 = rf(addrs, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.HistoricalBalance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]cipher.Address, visor.BlockAt) error); ok {
+		r1 = rf(addrs, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetBalanceSeriesOfAddresses provides a mock function with given fields: addrs, q
+func (_m *MockGatewayer) GetBalanceSeriesOfAddresses(addrs []cipher.Address, q visor.BalanceSeriesQuery) ([]visor.BalanceSeriesPoint, error) {
+	ret := _m.Called(addrs, q)
+
+	var r0 []visor.BalanceSeriesPoint
+	if rf, ok := ret.Get(0).(func([]cipher.Address, visor.BalanceSeriesQuery) []visor.BalanceSeriesPoint); ok {
+		r0 = rf(addrs, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.BalanceSeriesPoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]cipher.Address, visor.BalanceSeriesQuery) error); ok {
+		r1 = rf(addrs, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlockchainMetadata provides a mock function with given fields:
 func (_m *MockGatewayer) GetBlockchainMetadata() (*visor.BlockchainMetadata, error) {
 	ret := _m.Called()
@@ -575,6 +702,29 @@ func (_m *MockGatewayer) GetLastBlocksVerbose(num uint64) ([]coin.SignedBlock, [
 	return r0, r1, r2
 }
 
+// GetMempoolStats provides a mock function with given fields: now
+func (_m *MockGatewayer) GetMempoolStats(now time.Time) (*visor.MempoolStats, error) {
+	ret := _m.Called(now)
+
+	var r0 *visor.MempoolStats
+	if rf, ok := ret.Get(0).(func(time.Time) *visor.MempoolStats); ok {
+		r0 = rf(now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.MempoolStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRichlist provides a mock function with given fields: includeDistribution
 func (_m *MockGatewayer) GetRichlist(includeDistribution bool) (visor.Richlist, error) {
 	ret := _m.Called(includeDistribution)
@@ -708,6 +858,38 @@ func (_m *MockGatewayer) GetSignedBlockBySeqVerbose(seq uint64) (*coin.SignedBlo
 	return r0, r1, r2
 }
 
+// GetSpendingPolicy provides a mock function with given fields: wltID
+func (_m *MockGatewayer) GetSpendingPolicy(wltID string) (*wallet.SpendingPolicy, []wallet.PolicySpend, error) {
+	ret := _m.Called(wltID)
+
+	var r0 *wallet.SpendingPolicy
+	if rf, ok := ret.Get(0).(func(string) *wallet.SpendingPolicy); ok {
+		r0 = rf(wltID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.SpendingPolicy)
+		}
+	}
+
+	var r1 []wallet.PolicySpend
+	if rf, ok := ret.Get(1).(func(string) []wallet.PolicySpend); ok {
+		r1 = rf(wltID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]wallet.PolicySpend)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(wltID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetSpentOutputsForAddresses provides a mock function with given fields: addr
 func (_m *MockGatewayer) GetSpentOutputsForAddresses(addr []cipher.Address) ([][]historydb.UxOut, uint64, error) {
 	ret := _m.Called(addr)
@@ -996,6 +1178,36 @@ func (_m *MockGatewayer) GetWallet(wltID string) (wallet.Wallet, error) {
 	return r0, r1
 }
 
+// GetWalletAccountBalance provides a mock function with given fields: wltID, account
+func (_m *MockGatewayer) GetWalletAccountBalance(wltID string, account uint32) (wallet.BalancePair, wallet.AddressBalances, error) {
+	ret := _m.Called(wltID, account)
+
+	var r0 wallet.BalancePair
+	if rf, ok := ret.Get(0).(func(string, uint32) wallet.BalancePair); ok {
+		r0 = rf(wltID, account)
+	} else {
+		r0 = ret.Get(0).(wallet.BalancePair)
+	}
+
+	var r1 wallet.AddressBalances
+	if rf, ok := ret.Get(1).(func(string, uint32) wallet.AddressBalances); ok {
+		r1 = rf(wltID, account)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(wallet.AddressBalances)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, uint32) error); ok {
+		r2 = rf(wltID, account)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetWalletBalance provides a mock function with given fields: wltID
 func (_m *MockGatewayer) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error) {
 	ret := _m.Called(wltID)
@@ -1026,6 +1238,82 @@ func (_m *MockGatewayer) GetWalletBalance(wltID string) (wallet.BalancePair, wal
 	return r0, r1, r2
 }
 
+// GetWalletBalanceAt provides a mock function with given fields: wltID, at
+func (_m *MockGatewayer) GetWalletBalanceAt(wltID string, at visor.BlockAt) (*visor.HistoricalBalance, error) {
+	ret := _m.Called(wltID, at)
+
+	var r0 *visor.HistoricalBalance
+	if rf, ok := ret.Get(0).(func(string, visor.BlockAt) *visor.HistoricalBalance); ok {
+		r0 = rf(wltID, at)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*visor.HistoricalBalance)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, visor.BlockAt) error); ok {
+		r1 = rf(wltID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWalletBalanceSeries provides a mock function with given fields: wltID, q
+func (_m *MockGatewayer) GetWalletBalanceSeries(wltID string, q visor.BalanceSeriesQuery) ([]visor.BalanceSeriesPoint, error) {
+	ret := _m.Called(wltID, q)
+
+	var r0 []visor.BalanceSeriesPoint
+	if rf, ok := ret.Get(0).(func(string, visor.BalanceSeriesQuery) []visor.BalanceSeriesPoint); ok {
+		r0 = rf(wltID, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.BalanceSeriesPoint)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, visor.BalanceSeriesQuery) error); ok {
+		r1 = rf(wltID, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetWalletHistory provides a mock function with given fields: wltID, order, page
+func (_m *MockGatewayer) GetWalletHistory(wltID string, order visor.SortOrder, page *visor.PageIndex) ([]visor.WalletTransaction, uint64, error) {
+	ret := _m.Called(wltID, order, page)
+
+	var r0 []visor.WalletTransaction
+	if rf, ok := ret.Get(0).(func(string, visor.SortOrder, *visor.PageIndex) []visor.WalletTransaction); ok {
+		r0 = rf(wltID, order, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.WalletTransaction)
+		}
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(string, visor.SortOrder, *visor.PageIndex) uint64); ok {
+		r1 = rf(wltID, order, page)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string, visor.SortOrder, *visor.PageIndex) error); ok {
+		r2 = rf(wltID, order, page)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetWalletSeed provides a mock function with given fields: wltID, password
 func (_m *MockGatewayer) GetWalletSeed(wltID string, password []byte) (string, string, error) {
 	ret := _m.Called(wltID, password)
@@ -1160,6 +1448,29 @@ func (_m *MockGatewayer) HeadBkSeq() (uint64, bool, error) {
 	return r0, r1, r2
 }
 
+// ImportWallets provides a mock function with given fields: data, password, conflict, walletPasswords
+func (_m *MockGatewayer) ImportWallets(data []byte, password []byte, conflict wallet.ImportConflict, walletPasswords map[string][]byte) (*wallet.ImportResult, error) {
+	ret := _m.Called(data, password, conflict, walletPasswords)
+
+	var r0 *wallet.ImportResult
+	if rf, ok := ret.Get(0).(func([]byte, []byte, wallet.ImportConflict, map[string][]byte) *wallet.ImportResult); ok {
+		r0 = rf(data, password, conflict, walletPasswords)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.ImportResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func([]byte, []byte, wallet.ImportConflict, map[string][]byte) error); ok {
+		r1 = rf(data, password, conflict, walletPasswords)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InjectBroadcastTransaction provides a mock function with given fields: txn
 func (_m *MockGatewayer) InjectBroadcastTransaction(txn coin.Transaction) error {
 	ret := _m.Called(txn)
@@ -1188,6 +1499,20 @@ func (_m *MockGatewayer) InjectTransaction(txn coin.Transaction) error {
 	return r0
 }
 
+// LockWallet provides a mock function with given fields: wltID
+func (_m *MockGatewayer) LockWallet(wltID string) error {
+	ret := _m.Called(wltID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(wltID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewAddresses provides a mock function with given fields: wltID, password, options
 func (_m *MockGatewayer) NewAddresses(wltID string, password []byte, options ...wallet.Option) ([]cipher.Address, error) {
 	_va := make([]interface{}, len(options))
@@ -1324,6 +1649,59 @@ func (_m *MockGatewayer) ScanWalletAddresses(wltID string, password []byte, num
 	return r0, r1
 }
 
+// Search provides a mock function with given fields: q
+func (_m *MockGatewayer) Search(q string) ([]visor.SearchResult, bool, error) {
+	ret := _m.Called(q)
+
+	var r0 []visor.SearchResult
+	if rf, ok := ret.Get(0).(func(string) []visor.SearchResult); ok {
+		r0 = rf(q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]visor.SearchResult)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(q)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(q)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// SetSpendingPolicy provides a mock function with given fields: wltID, password, p
+func (_m *MockGatewayer) SetSpendingPolicy(wltID string, password []byte, p *wallet.SpendingPolicy) (*wallet.SpendingPolicy, error) {
+	ret := _m.Called(wltID, password, p)
+
+	var r0 *wallet.SpendingPolicy
+	if rf, ok := ret.Get(0).(func(string, []byte, *wallet.SpendingPolicy) *wallet.SpendingPolicy); ok {
+		r0 = rf(wltID, password, p)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.SpendingPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, *wallet.SpendingPolicy) error); ok {
+		r1 = rf(wltID, password, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // StartedAt provides a mock function with given fields:
 func (_m *MockGatewayer) StartedAt() time.Time {
 	ret := _m.Called()
@@ -1368,6 +1746,57 @@ func (_m *MockGatewayer) UnloadWallet(wltID string) error {
 	return r0
 }
 
+// UnlockWallet provides a mock function with given fields: wltID, password, timeout
+func (_m *MockGatewayer) UnlockWallet(wltID string, password []byte, timeout time.Duration) (*wallet.UnlockSession, error) {
+	ret := _m.Called(wltID, password, timeout)
+
+	var r0 *wallet.UnlockSession
+	if rf, ok := ret.Get(0).(func(string, []byte, time.Duration) *wallet.UnlockSession); ok {
+		r0 = rf(wltID, password, timeout)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*wallet.UnlockSession)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, []byte, time.Duration) error); ok {
+		r1 = rf(wltID, password, timeout)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateAccountName provides a mock function with given fields: wltID, account, name
+func (_m *MockGatewayer) UpdateAccountName(wltID string, account uint32, name string) error {
+	ret := _m.Called(wltID, account, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, uint32, string) error); ok {
+		r0 = rf(wltID, account, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAddressMeta provides a mock function with given fields: wltID, addr, m
+func (_m *MockGatewayer) UpdateAddressMeta(wltID string, addr cipher.Address, m wallet.EntryMeta) error {
+	ret := _m.Called(wltID, addr, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, cipher.Address, wallet.EntryMeta) error); ok {
+		r0 = rf(wltID, addr, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // UpdateWalletLabel provides a mock function with given fields: wltID, label
 func (_m *MockGatewayer) UpdateWalletLabel(wltID string, label string) error {
 	ret := _m.Called(wltID, label)
@@ -0,0 +1,588 @@
+package api
+
+// OpenAPI specification of the API
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	wh "github.com/skycoin/skycoin/src/util/http"
+)
+
+const (
+	// openAPIVersion is the version of the OpenAPI specification format
+	openAPIVersion = "3.0.3"
+	// openAPIEndpoint is the URI of the OpenAPI specification
+	openAPIEndpoint = "/api/openapi.json"
+
+	openAPITypeString  = "string"
+	openAPITypeInteger = "integer"
+	openAPITypeBoolean = "boolean"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// openAPIRoute is a route registered in newServerMux
+type openAPIRoute struct {
+	apiVersion    string
+	endpoint      string
+	methodAPISets map[string][]string
+}
+
+// openAPIOperation describes a method of a route
+type openAPIOperation struct {
+	Summary string
+	// Params are the path params and the query params of the request, or the form params of an API v1 POST request
+	Params []openAPIParam
+	// Body is a value of the type of the JSON request body
+	Body interface{}
+	// Response is a value of the type of the response. API v2 responses are wrapped in
+	// the data field of an HTTPResponse, unless RawResponse is set.
+	// The response has no content if nil.
+	Response interface{}
+	// RawResponse is set if an API v2 response is not wrapped in an HTTPResponse
+	RawResponse bool
+	// ContentType is the content type of the response, application/json if empty
+	ContentType string
+}
+
+// openAPIParam describes a query, form or path param.
+// A param is a path param if its name appears in braces in the path.
+type openAPIParam struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// openAPIOneOf describes a value that has one of several types
+type openAPIOneOf []interface{}
+
+// param describes an optional param
+func param(name, typ, description string) openAPIParam {
+	return openAPIParam{
+		Name:        name,
+		Type:        typ,
+		Description: description,
+	}
+}
+
+// requiredParam describes a required param
+func requiredParam(name, typ, description string) openAPIParam {
+	p := param(name, typ, description)
+	p.Required = true
+	return p
+}
+
+// openAPIObject is a JSON object of the OpenAPI specification
+type openAPIObject map[string]interface{}
+
+// OpenAPISpec is an OpenAPI 3 specification
+type OpenAPISpec struct {
+	OpenAPI    string                              `json:"openapi"`
+	Info       OpenAPIInfo                         `json:"info"`
+	Paths      map[string]map[string]openAPIObject `json:"paths"`
+	Components openAPIObject                       `json:"components"`
+	Security   []map[string][]string               `json:"security"`
+}
+
+// OpenAPIInfo is the info object of an OpenAPI 3 specification
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+// newOpenAPISpec creates the OpenAPI specification of the routes, described by openAPIRoutes.
+// It returns an error if a route or a method of a route is not described.
+func newOpenAPISpec(version string, routes []openAPIRoute) (*OpenAPISpec, error) {
+	b := &openAPISchemaBuilder{
+		schemas: make(map[string]interface{}),
+	}
+
+	spec := &OpenAPISpec{
+		OpenAPI: openAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       "Skycoin REST API",
+			Description: "Each operation is enabled by one of its x-api-sets on the node, unless x-api-sets is empty.",
+			Version:     version,
+		},
+		Paths: make(map[string]map[string]openAPIObject, len(routes)),
+		// Authentication is optional, it is only required if the node has a username and password
+		Security: []map[string][]string{
+			{},
+			{"basicAuth": {}},
+		},
+	}
+
+	registered := make(map[string]struct{}, len(openAPIRoutes))
+	for _, r := range routes {
+		paths := openAPIRoutePaths(r.endpoint)
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("route %s is not described in openAPIRoutes", r.endpoint)
+		}
+
+		// Routes registered without API sets handle their methods themselves,
+		// other routes only handle the methods of their API sets
+		if r.methodAPISets != nil {
+			for method := range r.methodAPISets {
+				described := false
+				for _, p := range paths {
+					if _, ok := openAPIRoutes[p][method]; ok {
+						described = true
+						break
+					}
+				}
+				if !described {
+					return nil, fmt.Errorf("method %s of route %s is not described in openAPIRoutes", method, r.endpoint)
+				}
+			}
+			for _, p := range paths {
+				for method := range openAPIRoutes[p] {
+					if _, ok := r.methodAPISets[method]; !ok {
+						return nil, fmt.Errorf("method %s of route %s is described but not registered", method, p)
+					}
+				}
+			}
+		}
+
+		for _, p := range paths {
+			registered[p] = struct{}{}
+
+			ops := openAPIRoutes[p]
+			pathItem := make(map[string]openAPIObject, len(ops))
+			for method, op := range ops {
+				if op.Summary == "" {
+					return nil, fmt.Errorf("method %s of route %s has no summary", method, p)
+				}
+
+				pathItem[strings.ToLower(method)] = b.operation(r, p, method, op)
+			}
+
+			spec.Paths[p] = pathItem
+		}
+	}
+
+	for endpoint := range openAPIRoutes {
+		if _, ok := registered[endpoint]; !ok {
+			return nil, fmt.Errorf("route %s is described in openAPIRoutes but not registered", endpoint)
+		}
+	}
+
+	spec.Components = openAPIObject{
+		"schemas": b.schemas,
+		"securitySchemes": openAPIObject{
+			"basicAuth": openAPIObject{
+				"type":        "http",
+				"scheme":      "basic",
+				"description": "The web interface username and password, or the id and secret of an API key",
+			},
+		},
+		"parameters": openAPIObject{
+			"csrfToken": openAPIObject{
+				"name":        CSRFHeaderName,
+				"in":          "header",
+				"description": "CSRF token obtained from /api/v1/csrf, required unless CSRF is disabled",
+				"schema":      openAPIObject{"type": openAPITypeString},
+			},
+		},
+	}
+
+	return spec, nil
+}
+
+// openAPIRoutePaths returns the paths described in openAPIRoutes that are served by a registered endpoint.
+// An endpoint ending with a slash serves the paths with path params below it, e.g. the endpoint
+// /api/v2/address/ serves /api/v2/address/{addr}/stats.
+func openAPIRoutePaths(endpoint string) []string {
+	if _, ok := openAPIRoutes[endpoint]; ok {
+		return []string{endpoint}
+	}
+
+	if !strings.HasSuffix(endpoint, "/") {
+		return nil
+	}
+
+	var paths []string
+	for p := range openAPIRoutes {
+		if strings.HasPrefix(p, endpoint) && strings.Contains(p, "{") {
+			paths = append(paths, p)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// openAPISchemaBuilder builds the schemas of the operations. The schemas of named struct types
+// are added to the components of the specification and referenced by the operations.
+type openAPISchemaBuilder struct {
+	schemas map[string]interface{}
+}
+
+// operation builds the operation object of a method of an endpoint served by a route
+func (b *openAPISchemaBuilder) operation(r openAPIRoute, endpoint, method string, op openAPIOperation) openAPIObject {
+	apiSets := r.methodAPISets[method]
+	if apiSets == nil {
+		apiSets = []string{}
+	}
+
+	o := openAPIObject{
+		"operationId": openAPIOperationID(method, endpoint),
+		"summary":     op.Summary,
+		"x-api-sets":  apiSets,
+	}
+
+	var params []openAPIObject
+	if method != http.MethodGet && r.endpoint != "/api/v1/csrf" {
+		params = append(params, openAPIObject{
+			"$ref": "#/components/parameters/csrfToken",
+		})
+	}
+
+	// Params that appear in the path are path params
+	var otherParams []openAPIParam
+	for _, p := range op.Params {
+		if !strings.Contains(endpoint, "{"+p.Name+"}") {
+			otherParams = append(otherParams, p)
+			continue
+		}
+
+		params = append(params, openAPIObject{
+			"name":        p.Name,
+			"in":          "path",
+			"required":    true,
+			"description": p.Description,
+			"schema":      openAPIObject{"type": p.Type},
+		})
+	}
+
+	// API v1 POST requests send their params as a form
+	formParams := method == http.MethodPost && r.apiVersion == apiVersion1
+	if !formParams {
+		for _, p := range otherParams {
+			params = append(params, openAPIObject{
+				"name":        p.Name,
+				"in":          "query",
+				"required":    p.Required,
+				"description": p.Description,
+				"schema":      openAPIObject{"type": p.Type},
+			})
+		}
+	}
+
+	if len(params) != 0 {
+		o["parameters"] = params
+	}
+
+	switch {
+	case op.Body != nil:
+		o["requestBody"] = openAPIObject{
+			"required": true,
+			"content": openAPIObject{
+				ContentTypeJSON: openAPIObject{
+					"schema": b.value(op.Body),
+				},
+			},
+		}
+	case formParams && len(otherParams) != 0:
+		props := make(openAPIObject, len(otherParams))
+		var required []string
+		for _, p := range otherParams {
+			props[p.Name] = openAPIObject{
+				"type":        p.Type,
+				"description": p.Description,
+			}
+			if p.Required {
+				required = append(required, p.Name)
+			}
+		}
+
+		schema := openAPIObject{
+			"type":       "object",
+			"properties": props,
+		}
+		if len(required) != 0 {
+			schema["required"] = required
+		}
+
+		o["requestBody"] = openAPIObject{
+			"required": len(required) != 0,
+			"content": openAPIObject{
+				ContentTypeForm: openAPIObject{
+					"schema": schema,
+				},
+			},
+		}
+	}
+
+	o["responses"] = openAPIObject{
+		"200":     b.response(r.apiVersion, op),
+		"default": b.errorResponse(r.apiVersion, op),
+	}
+
+	return o
+}
+
+// response builds the response object of a successful request
+func (b *openAPISchemaBuilder) response(apiVersion string, op openAPIOperation) openAPIObject {
+	resp := openAPIObject{
+		"description": "OK",
+	}
+
+	var schema interface{}
+	switch {
+	case apiVersion == apiVersion2 && !op.RawResponse:
+		if op.Response == nil {
+			schema = b.value(HTTPResponse{})
+		} else {
+			schema = openAPIObject{
+				"type": "object",
+				"properties": openAPIObject{
+					"data":  b.value(op.Response),
+					"error": b.value(HTTPError{}),
+				},
+			}
+		}
+	case op.Response != nil:
+		schema = b.value(op.Response)
+	default:
+		return resp
+	}
+
+	contentType := op.ContentType
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+
+	resp["content"] = openAPIObject{
+		contentType: openAPIObject{
+			"schema": schema,
+		},
+	}
+
+	return resp
+}
+
+// errorResponse builds the response object of a failed request. API v1 errors are plain text.
+func (b *openAPISchemaBuilder) errorResponse(apiVersion string, op openAPIOperation) openAPIObject {
+	if apiVersion == apiVersion2 {
+		return openAPIObject{
+			"description": "Error",
+			"content": openAPIObject{
+				ContentTypeJSON: openAPIObject{
+					"schema": b.value(HTTPResponse{}),
+				},
+			},
+		}
+	}
+
+	return openAPIObject{
+		"description": "Error",
+		"content": openAPIObject{
+			"text/plain": openAPIObject{
+				"schema": openAPIObject{"type": openAPITypeString},
+			},
+		},
+	}
+}
+
+// value returns the schema of the type of v
+func (b *openAPISchemaBuilder) value(v interface{}) openAPIObject {
+	if vs, ok := v.(openAPIOneOf); ok {
+		schemas := make([]openAPIObject, len(vs))
+		for i, v := range vs {
+			schemas[i] = b.value(v)
+		}
+		return openAPIObject{"oneOf": schemas}
+	}
+
+	return b.schema(reflect.TypeOf(v))
+}
+
+// schema returns the schema of a type, as encoded by encoding/json
+func (b *openAPISchemaBuilder) schema(t reflect.Type) openAPIObject {
+	switch {
+	case t == timeType:
+		return openAPIObject{"type": openAPITypeString, "format": "date-time"}
+	case t == rawMessageType:
+		return openAPIObject{}
+	case t.Implements(jsonMarshalerType):
+		// The types of the package that implement json.Marshaler are encoded as strings
+		return openAPIObject{"type": openAPITypeString}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return b.schema(t.Elem())
+	case reflect.Bool:
+		return openAPIObject{"type": openAPITypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return openAPIObject{"type": openAPITypeInteger, "format": "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return openAPIObject{"type": openAPITypeInteger, "format": "int64", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return openAPIObject{"type": "number"}
+	case reflect.String:
+		return openAPIObject{"type": openAPITypeString}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return openAPIObject{"type": openAPITypeString, "format": "byte"}
+		}
+		return openAPIObject{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return openAPIObject{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.structSchema(t)
+		}
+
+		name := openAPISchemaName(t)
+		if _, ok := b.schemas[name]; !ok {
+			// Reserve the name before building the schema, for recursive types
+			b.schemas[name] = nil
+			b.schemas[name] = b.structSchema(t)
+		}
+		return openAPIObject{"$ref": "#/components/schemas/" + name}
+	default:
+		// interface{}, any value
+		return openAPIObject{}
+	}
+}
+
+// structSchema returns the schema of a struct type. The fields of embedded structs are promoted.
+func (b *openAPISchemaBuilder) structSchema(t reflect.Type) openAPIObject {
+	props := openAPIObject{}
+	var required []string
+	b.addFields(t, props, &required)
+
+	schema := openAPIObject{
+		"type":       "object",
+		"properties": props,
+	}
+	if len(required) != 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+func (b *openAPISchemaBuilder) addFields(t reflect.Type, props openAPIObject, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			b.addFields(ft, props, required)
+			continue
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		omitEmpty := false
+		asString := false
+		for _, o := range opts[1:] {
+			switch o {
+			case "omitempty":
+				omitEmpty = true
+			case "string":
+				asString = true
+			}
+		}
+
+		if asString {
+			props[name] = openAPIObject{"type": openAPITypeString}
+		} else {
+			props[name] = b.schema(f.Type)
+		}
+
+		if !omitEmpty {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// openAPISchemaName returns the name of the schema of a named type, qualified by its package
+func openAPISchemaName(t reflect.Type) string {
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// openAPIOperationID returns the operation id of a method of a route,
+// for example getV1WalletBalance for GET /api/v1/wallet/balance
+func openAPIOperationID(method, endpoint string) string {
+	id := strings.ToLower(method)
+
+	words := strings.FieldsFunc(strings.TrimPrefix(endpoint, "/api"), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		id += strings.ToUpper(w[:1]) + w[1:]
+	}
+
+	return id
+}
+
+// Returns the OpenAPI 3 specification of the API
+// Method: GET
+// URI: /api/openapi.json
+func openAPIHandler(version string, routes *[]openAPIRoute) http.HandlerFunc {
+	var once sync.Once
+	var spec *OpenAPISpec
+	var err error
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			wh.Error405(w)
+			return
+		}
+
+		// The routes are all registered when the first request is served
+		once.Do(func() {
+			spec, err = newOpenAPISpec(version, *routes)
+			if err != nil {
+				logger.WithError(err).Error("newOpenAPISpec failed")
+			}
+		})
+
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
+		}
+
+		wh.SendJSONOr500(logger, w, spec)
+	}
+}
@@ -0,0 +1,783 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/readable"
+)
+
+// openAPIRoutes describes the methods of the routes registered in newServerMux, by endpoint and method.
+// Every route must be described, newOpenAPISpec fails otherwise.
+var openAPIRoutes = map[string]map[string]openAPIOperation{
+	// Status endpoints
+	"/api/v1/csrf": {
+		http.MethodGet: {
+			Summary: "Get a CSRF token",
+			Response: struct {
+				CSRFToken string `json:"csrf_token"`
+			}{},
+		},
+	},
+	"/api/v1/version": {
+		http.MethodGet: {
+			Summary:  "Get the version of the node",
+			Response: readable.BuildInfo{},
+		},
+	},
+	"/api/v1/health": {
+		http.MethodGet: {
+			Summary:  "Get the health of the node",
+			Response: HealthResponse{},
+		},
+	},
+	"/metrics": {
+		http.MethodGet: {
+			Summary:     "Get the metrics of the node in the Prometheus text format",
+			Response:    "",
+			ContentType: "text/plain",
+		},
+	},
+	"/rpc": {
+		http.MethodPost: {
+			Summary:     "Call a JSON-RPC 2.0 method, or a batch of methods",
+			Body:        openAPIOneOf{RPCRequest{}, []RPCRequest{}},
+			Response:    openAPIOneOf{RPCResponse{}, []RPCResponse{}},
+			RawResponse: true,
+		},
+	},
+	openAPIEndpoint: {
+		http.MethodGet: {
+			Summary:  "Get the OpenAPI specification of the API",
+			Response: map[string]interface{}{},
+		},
+	},
+
+	// API key management
+	"/api/v2/apikeys": {
+		http.MethodGet: {
+			Summary:  "List the API keys",
+			Response: []APIKeyResponse{},
+		},
+		http.MethodPost: {
+			Summary:  "Create an API key",
+			Body:     APIKeyCreateRequest{},
+			Response: APIKeyCreateResponse{},
+		},
+	},
+	"/api/v2/apikeys/revoke": {
+		http.MethodPost: {
+			Summary:  "Revoke an API key",
+			Body:     APIKeyRevokeRequest{},
+			Response: APIKeyResponse{},
+		},
+	},
+
+	// Wallet endpoints
+	"/api/v1/wallet": {
+		http.MethodGet: {
+			Summary: "Get a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+			},
+			Response: WalletResponse{},
+		},
+	},
+	"/api/v1/wallet/create": {
+		http.MethodPost: {
+			Summary: "Create a wallet",
+			Params: []openAPIParam{
+				requiredParam("type", openAPITypeString, "Wallet type: deterministic, bip44, xpub or collection"),
+				param("seed", openAPITypeString, "Wallet seed, required for deterministic and bip44 wallets"),
+				param("seed-passphrase", openAPITypeString, "Seed passphrase of bip44 wallets"),
+				param("bip44-coin", openAPITypeInteger, "Coin type of bip44 wallets"),
+				param("xpub", openAPITypeString, "Extended public key, required for xpub wallets"),
+				param("private-keys", openAPITypeString, "Comma separated private keys of collection wallets"),
+				requiredParam("label", openAPITypeString, "Wallet label"),
+				param("scan", openAPITypeInteger, "Number of addresses to scan for a balance, 1 if zero"),
+				param("encrypt", openAPITypeBoolean, "Encrypt the wallet"),
+				param("password", openAPITypeString, "Password of the encrypted wallet"),
+			},
+			Response: WalletResponse{},
+		},
+	},
+	"/api/v1/wallet/createTemp": {
+		http.MethodPost: {
+			Summary: "Create an unencrypted wallet that is not saved to disk",
+			Params: []openAPIParam{
+				requiredParam("type", openAPITypeString, "Wallet type: deterministic, bip44, xpub or collection"),
+				param("seed", openAPITypeString, "Wallet seed, required for deterministic and bip44 wallets"),
+				param("bip44-coin", openAPITypeInteger, "Coin type of bip44 wallets"),
+				param("xpub", openAPITypeString, "Extended public key, required for xpub wallets"),
+				param("private-keys", openAPITypeString, "Comma separated private keys of collection wallets"),
+				param("label", openAPITypeString, "Wallet label"),
+				param("scan", openAPITypeInteger, "Number of addresses to scan for a balance, 1 if zero"),
+			},
+			Response: WalletResponse{},
+		},
+	},
+	"/api/v1/wallet/newAddress": {
+		http.MethodPost: {
+			Summary: "Generate new addresses in a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				param("num", openAPITypeInteger, "Number of addresses to generate, 1 if empty"),
+				param("password", openAPITypeString, "Password of the encrypted wallet"),
+				param("private-keys", openAPITypeString, "Comma separated private keys to add to a collection wallet"),
+			},
+			Response: struct {
+				Addresses []string `json:"addresses"`
+			}{},
+		},
+	},
+	"/api/v1/wallet/scan": {
+		http.MethodPost: {
+			Summary: "Scan ahead for addresses of a wallet with a balance",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				param("num", openAPITypeInteger, "Number of addresses to scan, 20 if empty"),
+				param("password", openAPITypeString, "Password of the encrypted wallet"),
+			},
+			Response: struct {
+				Addresses []string `json:"addresses"`
+			}{},
+		},
+	},
+	"/api/v1/wallet/balance": {
+		http.MethodGet: {
+			Summary: "Get the balance of a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+			},
+			Response: BalanceResponse{},
+		},
+	},
+	"/api/v1/wallet/transaction": {
+		http.MethodPost: {
+			Summary:  "Create a transaction from a wallet",
+			Body:     WalletCreateTransactionRequest{},
+			Response: CreateTransactionResponse{},
+		},
+	},
+	"/api/v2/wallet/transaction/sign": {
+		http.MethodPost: {
+			Summary:  "Sign the inputs of a transaction that are owned by a wallet",
+			Body:     WalletSignTransactionRequest{},
+			Response: CreateTransactionResponse{},
+		},
+	},
+	"/api/v1/wallet/transactions": {
+		http.MethodGet: {
+			Summary: "Get the unconfirmed transactions of a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				param("verbose", openAPITypeBoolean, "Include the transaction inputs"),
+			},
+			Response: openAPIOneOf{UnconfirmedTxnsResponse{}, UnconfirmedTxnsVerboseResponse{}},
+		},
+	},
+	"/api/v1/wallet/update": {
+		http.MethodPost: {
+			Summary: "Update the label of a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				requiredParam("label", openAPITypeString, "Wallet label"),
+			},
+			Response: "",
+		},
+	},
+	"/api/v1/wallets": {
+		http.MethodGet: {
+			Summary:  "List the wallets",
+			Response: []WalletResponse{},
+		},
+	},
+	"/api/v1/wallets/folderName": {
+		http.MethodGet: {
+			Summary:  "Get the wallet directory",
+			Response: WalletFolder{},
+		},
+	},
+	"/api/v1/wallet/newSeed": {
+		http.MethodGet: {
+			Summary: "Generate a mnemonic seed",
+			Params: []openAPIParam{
+				param("entropy", openAPITypeInteger, "Entropy bits of the seed, 128 or 256, 128 if empty"),
+			},
+			Response: struct {
+				Seed string `json:"seed"`
+			}{},
+		},
+	},
+	"/api/v1/wallet/seed": {
+		http.MethodPost: {
+			Summary: "Get the seed of an encrypted wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				requiredParam("password", openAPITypeString, "Wallet password"),
+			},
+			Response: WalletSeedResponse{},
+		},
+	},
+	"/api/v2/wallet/seed/verify": {
+		http.MethodPost: {
+			Summary:  "Verify a mnemonic seed",
+			Body:     VerifySeedRequest{},
+			Response: struct{}{},
+		},
+	},
+	"/api/v2/wallet/seed/shares": {
+		http.MethodPost: {
+			Summary:  "Split the seed of an encrypted wallet into shares",
+			Body:     WalletSeedSharesRequest{},
+			Response: WalletSeedSharesResponse{},
+		},
+	},
+	"/api/v1/wallet/unload": {
+		http.MethodPost: {
+			Summary: "Unload a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+			},
+		},
+	},
+	"/api/v1/wallet/encrypt": {
+		http.MethodPost: {
+			Summary: "Encrypt a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				requiredParam("password", openAPITypeString, "Wallet password"),
+			},
+			Response: WalletResponse{},
+		},
+	},
+	"/api/v1/wallet/decrypt": {
+		http.MethodPost: {
+			Summary: "Decrypt a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				requiredParam("password", openAPITypeString, "Wallet password"),
+			},
+			Response: WalletResponse{},
+		},
+	},
+	"/api/v2/wallet/recover": {
+		http.MethodPost: {
+			Summary:  "Recover an encrypted wallet from its seed or seed shares",
+			Body:     WalletRecoverRequest{},
+			Response: WalletResponse{},
+		},
+	},
+	"/api/v2/wallet/accounts": {
+		http.MethodGet: {
+			Summary: "List the accounts of a bip44 wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+			},
+			Response: []WalletAccount{},
+		},
+		http.MethodPost: {
+			Summary:  "Create an account in a bip44 wallet",
+			Body:     WalletAccountCreateRequest{},
+			Response: WalletAccount{},
+		},
+	},
+	"/api/v2/wallet/accounts/update": {
+		http.MethodPost: {
+			Summary: "Rename an account of a bip44 wallet",
+			Body:    WalletAccountUpdateRequest{},
+		},
+	},
+	"/api/v2/wallet/accounts/balance": {
+		http.MethodGet: {
+			Summary: "Get the balance of an account of a bip44 wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				requiredParam("account", openAPITypeInteger, "Account index"),
+			},
+			Response: BalanceResponse{},
+		},
+	},
+	"/api/v2/wallet/accounts/addresses": {
+		http.MethodPost: {
+			Summary: "Generate new addresses in an account of a bip44 wallet",
+			Body:    WalletAccountNewAddressesRequest{},
+			Response: struct {
+				Addresses []string `json:"addresses"`
+			}{},
+		},
+	},
+	"/api/v2/wallet/history": {
+		http.MethodGet: {
+			Summary: "Get the transaction history of a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				param("format", openAPITypeString, "Response format: json or csv, json if empty"),
+				param("sort", openAPITypeString, "Sort order: asc or desc"),
+				param("limit", openAPITypeInteger, "Page size"),
+				param("page", openAPITypeInteger, "Page number, starting at 1"),
+			},
+			Response: WalletHistoryResponse{},
+		},
+	},
+	"/api/v2/wallet/balance/at": {
+		http.MethodGet: {
+			Summary: "Get the confirmed balance of a wallet as of a past block",
+			Params: append([]openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+			}, blockAtParams...),
+			Response: BalanceAtResponse{},
+		},
+	},
+	"/api/v2/wallet/balance/series": {
+		http.MethodGet: {
+			Summary: "Get the confirmed balance of a wallet at the end of each bucket of a period",
+			Params: append([]openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+			}, balanceSeriesParams...),
+			Response: BalanceSeriesResponse{},
+		},
+	},
+	"/api/v2/wallet/addresses": {
+		http.MethodGet: {
+			Summary: "List the addresses of a wallet with their labels and tags",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+				param("label", openAPITypeString, "Only return the addresses with this label"),
+				param("tag", openAPITypeString, "Only return the addresses with this tag"),
+			},
+			Response: WalletAddressesResponse{},
+		},
+	},
+	"/api/v2/wallet/address/update": {
+		http.MethodPost: {
+			Summary:  "Update the label and tags of a wallet address",
+			Body:     WalletAddressUpdateRequest{},
+			Response: WalletAddressMeta{},
+		},
+	},
+	"/api/v2/wallet/policy": {
+		http.MethodGet: {
+			Summary: "Get the spending policy of a wallet",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeString, "Wallet id"),
+			},
+			Response: WalletPolicyResponse{},
+		},
+		http.MethodPost: {
+			Summary:  "Set the spending policy of a wallet",
+			Body:     WalletPolicyUpdateRequest{},
+			Response: WalletPolicyResponse{},
+		},
+	},
+	"/api/v2/wallet/unlock": {
+		http.MethodPost: {
+			Summary:  "Unlock an encrypted wallet, returning a session token",
+			Body:     WalletUnlockRequest{},
+			Response: WalletUnlockResponse{},
+		},
+	},
+	"/api/v2/wallet/lock": {
+		http.MethodPost: {
+			Summary: "Lock an unlocked wallet",
+			Body:    WalletLockRequest{},
+		},
+	},
+	"/api/v2/wallet/export": {
+		http.MethodPost: {
+			Summary:  "Export wallets to an encrypted archive",
+			Body:     WalletExportRequest{},
+			Response: json.RawMessage{},
+		},
+	},
+	"/api/v2/wallet/import": {
+		http.MethodPost: {
+			Summary:  "Import wallets from an encrypted archive",
+			Body:     WalletImportRequest{},
+			Response: WalletImportResponse{},
+		},
+	},
+
+	// Blockchain endpoints
+	"/api/v1/blockchain/metadata": {
+		http.MethodGet: {
+			Summary:  "Get the blockchain metadata",
+			Response: readable.BlockchainMetadata{},
+		},
+	},
+	"/api/v1/blockchain/progress": {
+		http.MethodGet: {
+			Summary:  "Get the blockchain sync progress",
+			Response: readable.BlockchainProgress{},
+		},
+	},
+	"/api/v1/block": {
+		http.MethodGet: {
+			Summary: "Get a block by hash or sequence number",
+			Params: []openAPIParam{
+				param("hash", openAPITypeString, "Block hash"),
+				param("seq", openAPITypeInteger, "Block sequence number"),
+				param("verbose", openAPITypeBoolean, "Include the transaction inputs"),
+			},
+			Response: openAPIOneOf{readable.Block{}, readable.BlockVerbose{}},
+		},
+	},
+	"/api/v1/blocks": {
+		http.MethodGet: {
+			Summary:  "Get blocks in a range or by sequence numbers",
+			Params:   blocksParams,
+			Response: openAPIOneOf{readable.Blocks{}, readable.BlocksVerbose{}},
+		},
+		http.MethodPost: {
+			Summary:  "Get blocks in a range or by sequence numbers",
+			Params:   blocksParams,
+			Response: openAPIOneOf{readable.Blocks{}, readable.BlocksVerbose{}},
+		},
+	},
+	"/api/v1/last_blocks": {
+		http.MethodGet: {
+			Summary: "Get the most recent blocks",
+			Params: []openAPIParam{
+				requiredParam("num", openAPITypeInteger, "Number of blocks"),
+				param("verbose", openAPITypeBoolean, "Include the transaction inputs"),
+			},
+			Response: openAPIOneOf{readable.Blocks{}, readable.BlocksVerbose{}},
+		},
+	},
+
+	// Network endpoints
+	"/api/v1/network/connection": {
+		http.MethodGet: {
+			Summary: "Get a connection",
+			Params: []openAPIParam{
+				requiredParam("addr", openAPITypeString, "Connection address, ip:port"),
+			},
+			Response: readable.Connection{},
+		},
+	},
+	"/api/v1/network/connections": {
+		http.MethodGet: {
+			Summary: "List the connections",
+			Params: []openAPIParam{
+				param("states", openAPITypeString, "Comma separated connection states: pending, connected or introduced. Connected and introduced if empty"),
+				param("direction", openAPITypeString, "Connection direction: incoming or outgoing"),
+			},
+			Response: Connections{},
+		},
+	},
+	"/api/v1/network/defaultConnections": {
+		http.MethodGet: {
+			Summary:  "List the default peers",
+			Response: []string{},
+		},
+	},
+	"/api/v1/network/connections/trust": {
+		http.MethodGet: {
+			Summary:  "List the trusted peers",
+			Response: []string{},
+		},
+	},
+	"/api/v1/network/connections/exchange": {
+		http.MethodGet: {
+			Summary:  "List the peers obtained from peer exchange",
+			Response: []string{},
+		},
+	},
+	"/api/v1/network/connection/disconnect": {
+		http.MethodPost: {
+			Summary: "Disconnect a peer",
+			Params: []openAPIParam{
+				requiredParam("id", openAPITypeInteger, "Connection id"),
+			},
+			Response: struct{}{},
+		},
+	},
+
+	// Transaction endpoints
+	"/api/v1/pendingTxs": {
+		http.MethodGet: {
+			Summary: "List the unconfirmed transactions",
+			Params: []openAPIParam{
+				param("verbose", openAPITypeBoolean, "Include the transaction inputs"),
+			},
+			Response: openAPIOneOf{[]readable.UnconfirmedTransactions{}, []readable.UnconfirmedTransactionVerbose{}},
+		},
+	},
+	"/api/v2/mempool/stats": {
+		http.MethodGet: {
+			Summary:  "Get aggregated statistics of the unconfirmed transactions: fee rate histogram, ages, invalid reasons and the projected blocks to clear",
+			Response: MempoolStatsResponse{},
+		},
+	},
+	"/api/v1/transaction": {
+		http.MethodGet: {
+			Summary: "Get a transaction",
+			Params: []openAPIParam{
+				requiredParam("txid", openAPITypeString, "Transaction id"),
+				param("verbose", openAPITypeBoolean, "Include the transaction inputs"),
+				param("encoded", openAPITypeBoolean, "Return the transaction encoded in hex"),
+			},
+			Response: openAPIOneOf{readable.TransactionWithStatus{}, readable.TransactionWithStatusVerbose{}, TransactionEncodedResponse{}},
+		},
+	},
+	"/api/v2/transaction": {
+		http.MethodPost: {
+			Summary:  "Create an unsigned transaction from addresses or unspent outputs",
+			Body:     CreateTransactionRequest{},
+			Response: CreateTransactionResponse{},
+		},
+	},
+	"/api/v2/transaction/verify": {
+		http.MethodPost: {
+			Summary:  "Verify an encoded transaction",
+			Body:     VerifyTransactionRequest{},
+			Response: VerifyTransactionResponse{},
+		},
+	},
+	"/api/v1/transactions": {
+		http.MethodGet: {
+			Summary:  "Get the transactions of addresses",
+			Params:   transactionsParams,
+			Response: openAPIOneOf{[]readable.TransactionWithStatus{}, []readable.TransactionWithStatusVerbose{}},
+		},
+		http.MethodPost: {
+			Summary:  "Get the transactions of addresses",
+			Params:   transactionsParams,
+			Response: openAPIOneOf{[]readable.TransactionWithStatus{}, []readable.TransactionWithStatusVerbose{}},
+		},
+	},
+	"/api/v1/transactions/num": {
+		http.MethodGet: {
+			Summary: "Get the number of transactions in the blockchain",
+			Response: struct {
+				TxnsTotalNum uint64 `json:"txns_num"`
+			}{},
+		},
+	},
+	"/api/v2/transactions": {
+		http.MethodGet: {
+			Summary: "Get a page of the transactions of addresses",
+			Params: append(transactionsParams,
+				param("sort", openAPITypeString, "Sort order: asc or desc"),
+				param("limit", openAPITypeInteger, "Page size"),
+				param("page", openAPITypeInteger, "Page number, starting at 1"),
+			),
+			Response: openAPIOneOf{TransactionsWithStatusV2{}, TransactionsWithStatusVerboseV2{}},
+		},
+	},
+	"/api/v1/injectTransaction": {
+		http.MethodPost: {
+			Summary:  "Broadcast an encoded transaction, returning its id",
+			Body:     InjectTransactionRequest{},
+			Response: "",
+		},
+	},
+	"/api/v1/resendUnconfirmedTxns": {
+		http.MethodPost: {
+			Summary:  "Rebroadcast the unconfirmed transactions",
+			Response: ResendResult{},
+		},
+	},
+	"/api/v1/rawtx": {
+		http.MethodGet: {
+			Summary: "Get a transaction encoded in hex",
+			Params: []openAPIParam{
+				requiredParam("txid", openAPITypeString, "Transaction id"),
+			},
+			Response: "",
+		},
+	},
+
+	// Unspent output endpoints
+	"/api/v1/outputs": {
+		http.MethodGet: {
+			Summary:  "Get the unspent outputs of addresses or by hash",
+			Params:   outputsParams,
+			Response: readable.UnspentOutputsSummary{},
+		},
+		http.MethodPost: {
+			Summary:  "Get the unspent outputs of addresses or by hash",
+			Params:   outputsParams,
+			Response: readable.UnspentOutputsSummary{},
+		},
+	},
+	"/api/v1/balance": {
+		http.MethodGet: {
+			Summary:  "Get the balance of addresses",
+			Params:   balanceParams,
+			Response: BalanceResponse{},
+		},
+		http.MethodPost: {
+			Summary:  "Get the balance of addresses",
+			Params:   balanceParams,
+			Response: BalanceResponse{},
+		},
+	},
+	"/api/v1/uxout": {
+		http.MethodGet: {
+			Summary: "Get an output by hash, spent or unspent",
+			Params: []openAPIParam{
+				requiredParam("uxid", openAPITypeString, "Output hash"),
+			},
+			Response: readable.SpentOutput{},
+		},
+	},
+	"/api/v1/address_uxouts": {
+		http.MethodGet: {
+			Summary: "Get the outputs of an address, spent or unspent",
+			Params: []openAPIParam{
+				requiredParam("address", openAPITypeString, "Address"),
+			},
+			Response: []readable.SpentOutput{},
+		},
+	},
+
+	// Address endpoints
+	"/api/v2/address/verify": {
+		http.MethodPost: {
+			Summary:  "Verify an address",
+			Body:     VerifyAddressRequest{},
+			Response: VerifyAddressResponse{},
+		},
+	},
+	"/api/v2/address/{addr}/stats": {
+		http.MethodGet: {
+			Summary: "Get the stats of an address",
+			Params: []openAPIParam{
+				requiredParam("addr", openAPITypeString, "Address"),
+			},
+			Response: readable.AddressStats{},
+		},
+	},
+
+	// Historical balance endpoints
+	"/api/v2/balance/at": {
+		http.MethodGet: {
+			Summary: "Get the confirmed balance of addresses as of a past block",
+			Params: append([]openAPIParam{
+				requiredParam("addrs", openAPITypeString, "Comma separated addresses"),
+			}, blockAtParams...),
+			Response: BalanceAtResponse{},
+		},
+	},
+	"/api/v2/balance/series": {
+		http.MethodGet: {
+			Summary: "Get the confirmed balance of addresses at the end of each bucket of a period",
+			Params: append([]openAPIParam{
+				requiredParam("addrs", openAPITypeString, "Comma separated addresses"),
+			}, balanceSeriesParams...),
+			Response: BalanceSeriesResponse{},
+		},
+	},
+
+	// Search endpoint
+	"/api/v2/search": {
+		http.MethodGet: {
+			Summary: "Find the blocks, transactions, outputs and addresses that a block seq, hash or hash prefix, or an address refers to",
+			Params: []openAPIParam{
+				requiredParam("q", openAPITypeString, "Block seq, block hash, transaction ID, output ID, address or hex prefix of at least 6 characters of a hash"),
+			},
+			Response: SearchResponse{},
+		},
+	},
+
+	// Export endpoint
+	"/api/v2/export": {
+		http.MethodGet: {
+			Summary: "Stream a table of the blockchain as JSON lines or CSV",
+			Params: []openAPIParam{
+				requiredParam("table", openAPITypeString, "Table: blocks, transactions, inputs or outputs"),
+				param("format", openAPITypeString, "Format: jsonl or csv, jsonl if empty"),
+				param("start", openAPITypeInteger, "Seq of the first block, 0 if empty"),
+				param("end", openAPITypeInteger, "Seq of the last block, the head block if empty. At most 10000 blocks are exported per request."),
+			},
+			Response:    "",
+			RawResponse: true,
+			ContentType: "application/x-ndjson",
+		},
+	},
+
+	// Explorer endpoints
+	"/api/v1/coinSupply": {
+		http.MethodGet: {
+			Summary:  "Get the coin supply",
+			Response: CoinSupply{},
+		},
+	},
+	"/api/v1/richlist": {
+		http.MethodGet: {
+			Summary: "Get the addresses with the largest balances",
+			Params: []openAPIParam{
+				param("n", openAPITypeInteger, "Number of addresses, 20 if empty, all if 0"),
+				param("include-distribution", openAPITypeBoolean, "Include the distribution addresses"),
+			},
+			Response: Richlist{},
+		},
+	},
+	"/api/v1/addresscount": {
+		http.MethodGet: {
+			Summary: "Get the number of addresses with unspent outputs",
+			Response: struct {
+				Count uint64 `json:"count"`
+			}{},
+		},
+	},
+
+	// Storage endpoints
+	"/api/v2/data": {
+		http.MethodGet: {
+			Summary: "Get a value, or all values, of a storage",
+			Params: []openAPIParam{
+				requiredParam("type", openAPITypeString, "Storage type: txid or client"),
+				param("key", openAPITypeString, "Key of the value, all values are returned if empty"),
+			},
+			Response: openAPIOneOf{"", map[string]string{}},
+		},
+		http.MethodPost: {
+			Summary: "Add a value to a storage",
+			Body:    StorageRequest{},
+		},
+		http.MethodDelete: {
+			Summary: "Remove a value from a storage",
+			Params: []openAPIParam{
+				requiredParam("type", openAPITypeString, "Storage type: txid or client"),
+				requiredParam("key", openAPITypeString, "Key of the value"),
+			},
+		},
+	},
+}
+
+var (
+	blocksParams = []openAPIParam{
+		param("start", openAPITypeInteger, "First block of the range"),
+		param("end", openAPITypeInteger, "Last block of the range"),
+		param("seqs", openAPITypeString, "Comma separated block sequence numbers, instead of a range"),
+		param("verbose", openAPITypeBoolean, "Include the transaction inputs"),
+	}
+
+	transactionsParams = []openAPIParam{
+		param("addrs", openAPITypeString, "Comma separated addresses"),
+		param("confirmed", openAPITypeBoolean, "Only return confirmed transactions if true, unconfirmed transactions if false, both if empty"),
+		param("verbose", openAPITypeBoolean, "Include the transaction inputs"),
+	}
+
+	outputsParams = []openAPIParam{
+		param("addrs", openAPITypeString, "Comma separated addresses"),
+		param("hashes", openAPITypeString, "Comma separated output hashes, cannot be combined with addrs"),
+	}
+
+	balanceParams = []openAPIParam{
+		requiredParam("addrs", openAPITypeString, "Comma separated addresses"),
+	}
+
+	blockAtParams = []openAPIParam{
+		param("seq", openAPITypeInteger, "Block seq, the head block if seq and time are empty"),
+		param("time", openAPITypeString, "Unix time or RFC3339 time, selects the last block created at or before the time"),
+	}
+
+	balanceSeriesParams = []openAPIParam{
+		requiredParam("bucket", openAPITypeString, "Bucket of the series: hour, day, week, month, quarter or year"),
+		param("start", openAPITypeString, "Unix time or RFC3339 time of the start of the series, the time of the genesis block if empty"),
+		param("end", openAPITypeString, "Unix time or RFC3339 time of the end of the series, the time of the head block if empty"),
+	}
+)
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	wh "github.com/skycoin/skycoin/src/util/http"
+)
+
+func TestOpenAPIHandler(t *testing.T) {
+	doRequest := func(method string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/openapi.json", nil)
+		req.Host = configuredHost
+
+		cfg := defaultMuxConfig()
+		cfg.health.BuildInfo.Version = "0.27.0"
+
+		rr := httptest.NewRecorder()
+		newServerMux(cfg, &MockGatewayer{}).ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := doRequest(http.MethodPost)
+	require.Equal(t, http.StatusMethodNotAllowed, rr.Code)
+
+	// Fails if a route registered in newServerMux is not described in openAPIRoutes
+	rr = doRequest(http.MethodGet)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+	var spec OpenAPISpec
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&spec))
+	require.Equal(t, "3.0.3", spec.OpenAPI)
+	require.Equal(t, "0.27.0", spec.Info.Version)
+
+	for e, methods := range endpointsMethods {
+		require.Contains(t, spec.Paths, e)
+		require.Len(t, spec.Paths[e], len(methods), e)
+		for _, m := range methods {
+			require.Contains(t, spec.Paths[e], strings.ToLower(m), e)
+		}
+	}
+	require.Contains(t, spec.Paths, "/api/v1/csrf")
+
+	// The operation ids must be unique for the generated clients
+	ids := make(map[string]struct{})
+	for _, ops := range spec.Paths {
+		for _, op := range ops {
+			id := op["operationId"].(string)
+			require.NotContains(t, ids, id)
+			ids[id] = struct{}{}
+		}
+	}
+
+	// Every schema that is referenced is defined
+	var refs []string
+	var findRefs func(v interface{})
+	findRefs = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for k, x := range v {
+				if s, ok := x.(string); ok && k == "$ref" {
+					refs = append(refs, s)
+				}
+				findRefs(x)
+			}
+		case []interface{}:
+			for _, x := range v {
+				findRefs(x)
+			}
+		}
+	}
+
+	var raw map[string]interface{}
+	rr = doRequest(http.MethodGet)
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&raw))
+	findRefs(raw)
+	require.NotEmpty(t, refs)
+
+	components := raw["components"].(map[string]interface{})
+	for _, ref := range refs {
+		parts := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+		require.Len(t, parts, 2)
+		require.Contains(t, components[parts[0]], parts[1], ref)
+	}
+}
+
+func TestNewOpenAPISpec(t *testing.T) {
+	// routes returns a route for each described endpoint, with the described methods
+	routes := func() []openAPIRoute {
+		var routes []openAPIRoute
+		for e, ops := range openAPIRoutes {
+			sets := make(map[string][]string, len(ops))
+			for m := range ops {
+				sets[m] = []string{EndpointsRead}
+			}
+
+			apiVersion := apiVersion1
+			if strings.HasPrefix(e, "/api/v2") {
+				apiVersion = apiVersion2
+			}
+
+			routes = append(routes, openAPIRoute{
+				apiVersion:    apiVersion,
+				endpoint:      e,
+				methodAPISets: sets,
+			})
+		}
+		return routes
+	}
+
+	spec, err := newOpenAPISpec("0.27.0", routes())
+	require.NoError(t, err)
+	require.Len(t, spec.Paths, len(openAPIRoutes))
+
+	op := spec.Paths["/api/v1/wallet/balance"]["get"]
+	require.Equal(t, "getV1WalletBalance", op["operationId"])
+	require.Equal(t, []string{EndpointsRead}, op["x-api-sets"])
+
+	// A registered route must be described
+	rs := append(routes(), openAPIRoute{
+		apiVersion: apiVersion1,
+		endpoint:   "/api/v1/foo",
+	})
+	_, err = newOpenAPISpec("0.27.0", rs)
+	require.EqualError(t, err, "route /api/v1/foo is not described in openAPIRoutes")
+
+	rs = routes()
+	for i, r := range rs {
+		if r.endpoint == "/api/v1/wallet" {
+			rs[i].methodAPISets[http.MethodPost] = []string{EndpointsWallet}
+		}
+	}
+	_, err = newOpenAPISpec("0.27.0", rs)
+	require.EqualError(t, err, "method POST of route /api/v1/wallet is not described in openAPIRoutes")
+
+	rs = routes()
+	for i, r := range rs {
+		if r.endpoint == "/api/v2/data" {
+			delete(rs[i].methodAPISets, http.MethodDelete)
+		}
+	}
+	_, err = newOpenAPISpec("0.27.0", rs)
+	require.EqualError(t, err, "method DELETE of route /api/v2/data is described but not registered")
+
+	rs = routes()
+	for i, r := range rs {
+		if r.endpoint == "/api/v1/version" {
+			rs = append(rs[:i], rs[i+1:]...)
+			break
+		}
+	}
+	_, err = newOpenAPISpec("0.27.0", rs)
+	require.EqualError(t, err, "route /api/v1/version is described in openAPIRoutes but not registered")
+
+	versionOp := openAPIRoutes["/api/v1/version"][http.MethodGet]
+	defer func() {
+		openAPIRoutes["/api/v1/version"][http.MethodGet] = versionOp
+	}()
+	openAPIRoutes["/api/v1/version"][http.MethodGet] = openAPIOperation{}
+	_, err = newOpenAPISpec("0.27.0", routes())
+	require.EqualError(t, err, "method GET of route /api/v1/version has no summary")
+	openAPIRoutes["/api/v1/version"][http.MethodGet] = versionOp
+
+	// A route ending with a slash serves the described paths with path params below it
+	rs = routes()
+	for i, r := range rs {
+		if r.endpoint == "/api/v2/address/{addr}/stats" {
+			rs[i].endpoint = "/api/v2/address/"
+		}
+	}
+	spec, err = newOpenAPISpec("0.27.0", rs)
+	require.NoError(t, err)
+	require.Len(t, spec.Paths, len(openAPIRoutes))
+	require.NotContains(t, spec.Paths, "/api/v2/address/")
+
+	op = spec.Paths["/api/v2/address/{addr}/stats"]["get"]
+	require.Equal(t, "getV2AddressAddrStats", op["operationId"])
+	require.Equal(t, []openAPIObject{
+		{
+			"name":        "addr",
+			"in":          "path",
+			"required":    true,
+			"description": "Address",
+			"schema":      openAPIObject{"type": openAPITypeString},
+		},
+	}, op["parameters"])
+
+	for i, r := range rs {
+		if r.endpoint == "/api/v2/address/" {
+			rs[i].endpoint = "/api/v2/foo/"
+		}
+	}
+	_, err = newOpenAPISpec("0.27.0", rs)
+	require.EqualError(t, err, "route /api/v2/foo/ is not described in openAPIRoutes")
+}
+
+type openAPITestEmbedded struct {
+	Height uint64 `json:"height"`
+}
+
+type openAPITestObject struct {
+	openAPITestEmbedded
+	Name     string                 `json:"name"`
+	Label    *string                `json:"label,omitempty"`
+	Count    int32                  `json:"count,string"`
+	Data     []byte                 `json:"data"`
+	Coins    wh.Coins               `json:"coins"`
+	Time     time.Time              `json:"time"`
+	Tags     map[string]bool        `json:"tags"`
+	Children []openAPITestEmbedded  `json:"children"`
+	Extra    map[string]interface{} `json:"extra,omitempty"`
+	Ignored  string                 `json:"-"`
+	NoTag    float64
+	private  string
+}
+
+func TestOpenAPISchema(t *testing.T) {
+	b := &openAPISchemaBuilder{
+		schemas: make(map[string]interface{}),
+	}
+
+	schema := b.schema(reflect.TypeOf(openAPITestObject{}))
+	require.Equal(t, openAPIObject{"$ref": "#/components/schemas/api.openAPITestObject"}, schema)
+
+	embedded := openAPIObject{"$ref": "#/components/schemas/api.openAPITestEmbedded"}
+	require.Equal(t, map[string]interface{}{
+		"api.openAPITestObject": openAPIObject{
+			"type": "object",
+			"properties": openAPIObject{
+				"height": openAPIObject{"type": "integer", "format": "int64", "minimum": 0},
+				"name":   openAPIObject{"type": "string"},
+				"label":  openAPIObject{"type": "string"},
+				"count":  openAPIObject{"type": "string"},
+				"data":   openAPIObject{"type": "string", "format": "byte"},
+				"coins":  openAPIObject{"type": "string"},
+				"time":   openAPIObject{"type": "string", "format": "date-time"},
+				"tags": openAPIObject{
+					"type":                 "object",
+					"additionalProperties": openAPIObject{"type": "boolean"},
+				},
+				"children": openAPIObject{"type": "array", "items": embedded},
+				"extra": openAPIObject{
+					"type":                 "object",
+					"additionalProperties": openAPIObject{},
+				},
+				"NoTag": openAPIObject{"type": "number"},
+			},
+			"required": []string{"NoTag", "children", "coins", "count", "data", "height", "name", "tags", "time"},
+		},
+		"api.openAPITestEmbedded": openAPIObject{
+			"type": "object",
+			"properties": openAPIObject{
+				"height": openAPIObject{"type": "integer", "format": "int64", "minimum": 0},
+			},
+			"required": []string{"height"},
+		},
+	}, b.schemas)
+
+	require.Equal(t, openAPIObject{
+		"oneOf": []openAPIObject{
+			{"type": "string"},
+			{"type": "array", "items": embedded},
+		},
+	}, b.value(openAPIOneOf{"", []openAPITestEmbedded{}}))
+
+	require.Equal(t, "getV1WalletBalance", openAPIOperationID(http.MethodGet, "/api/v1/wallet/balance"))
+	require.Equal(t, "postV2WalletTransactionSign", openAPIOperationID(http.MethodPost, "/api/v2/wallet/transaction/sign"))
+	require.Equal(t, "getV1LastBlocks", openAPIOperationID(http.MethodGet, "/api/v1/last_blocks"))
+	require.Equal(t, "getOpenapiJson", openAPIOperationID(http.MethodGet, "/api/openapi.json"))
+	require.Equal(t, "getMetrics", openAPIOperationID(http.MethodGet, "/metrics"))
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// rateLimitPeriod is the period of the rate limits, which are in requests per minute
+	rateLimitPeriod = time.Minute
+	// heavyRequestRetryAfter is the Retry-After of a heavy request rejected by the concurrency limit
+	heavyRequestRetryAfter = time.Second
+)
+
+// heavyEndpoints are the endpoints that scan the blockchain, the unspent outputs or the
+// unconfirmed transactions, holding a database read transaction for the duration of the request.
+// The number of requests that they serve concurrently is limited by RateLimitConfig.MaxHeavyRequests.
+var heavyEndpoints = []string{
+	"/api/v1/richlist",
+	"/api/v1/addresscount",
+	"/api/v1/outputs",
+	"/api/v1/blocks",
+	"/api/v1/last_blocks",
+	"/api/v1/transactions",
+	"/api/v2/transactions",
+	"/api/v2/balance/series",
+	"/api/v2/wallet/balance/series",
+	"/api/v2/export",
+	"/api/v2/search",
+	"/api/v2/mempool/stats",
+}
+
+// RateLimitConfig configures the rate limits of the API clients. A client is identified
+// by its API key, by its username or by its remote IP, in that order.
+type RateLimitConfig struct {
+	// Rate is the number of requests per minute that a client can make to each endpoint.
+	// Endpoints are not rate limited if zero.
+	Rate int
+	// EndpointRates overrides Rate for some endpoints, such as "/api/v1/richlist".
+	// An endpoint is not rate limited if its rate is zero.
+	EndpointRates map[string]int
+	// MaxHeavyRequests is the maximum number of requests served concurrently by the heavy endpoints,
+	// which scan the blockchain, the unspent outputs or the unconfirmed transactions. Unlimited if zero.
+	MaxHeavyRequests int
+}
+
+// Enabled returns true if any limit is configured
+func (c RateLimitConfig) Enabled() bool {
+	if c.Rate != 0 || c.MaxHeavyRequests != 0 {
+		return true
+	}
+
+	for _, r := range c.EndpointRates {
+		if r != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate validates RateLimitConfig
+func (c RateLimitConfig) Validate() error {
+	if c.Rate < 0 {
+		return errors.New("rate limit must not be negative")
+	}
+
+	if c.MaxHeavyRequests < 0 {
+		return errors.New("max heavy requests must not be negative")
+	}
+
+	for e, r := range c.EndpointRates {
+		if r < 0 {
+			return fmt.Errorf("rate limit of endpoint %q must not be negative", e)
+		}
+	}
+
+	return nil
+}
+
+// rateLimitKey identifies the token bucket of a client for an endpoint
+type rateLimitKey struct {
+	endpoint string
+	client   string
+}
+
+// tokenBucket holds the requests that a client can still make to an endpoint.
+// It refills continuously, up to the rate of the endpoint.
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// rateLimiter enforces RateLimitConfig. It is shared by the muxes of the server,
+// including the muxes of the API keys.
+type rateLimiter struct {
+	sync.Mutex
+	rate          int
+	endpointRates map[string]int
+	buckets       map[rateLimitKey]*tokenBucket
+	pruned        time.Time
+	heavy         chan struct{}
+	heavyEnds     map[string]struct{}
+	now           func() time.Time
+}
+
+func newRateLimiter(c RateLimitConfig) *rateLimiter {
+	rl := &rateLimiter{
+		rate:          c.Rate,
+		endpointRates: c.EndpointRates,
+		buckets:       make(map[rateLimitKey]*tokenBucket),
+		heavyEnds:     make(map[string]struct{}, len(heavyEndpoints)),
+		now:           time.Now,
+	}
+
+	if c.MaxHeavyRequests != 0 {
+		rl.heavy = make(chan struct{}, c.MaxHeavyRequests)
+	}
+
+	for _, e := range heavyEndpoints {
+		rl.heavyEnds[e] = struct{}{}
+	}
+
+	return rl
+}
+
+// endpointRate returns the rate of an endpoint, in requests per minute
+func (rl *rateLimiter) endpointRate(endpoint string) int {
+	if r, ok := rl.endpointRates[endpoint]; ok {
+		return r
+	}
+	return rl.rate
+}
+
+// allow takes a request from the token bucket of the client for the endpoint.
+// If the bucket is empty, it returns false and the time until the next request is allowed.
+func (rl *rateLimiter) allow(endpoint, client string) (bool, time.Duration) {
+	rate := rl.endpointRate(endpoint)
+	if rate == 0 {
+		return true, 0
+	}
+
+	rl.Lock()
+	defer rl.Unlock()
+
+	now := rl.now()
+	rl.prune(now)
+
+	k := rateLimitKey{
+		endpoint: endpoint,
+		client:   client,
+	}
+
+	b, ok := rl.buckets[k]
+	if !ok {
+		b = &tokenBucket{
+			tokens:  float64(rate),
+			updated: now,
+		}
+		rl.buckets[k] = b
+	}
+
+	perToken := rateLimitPeriod / time.Duration(rate)
+	b.tokens = math.Min(float64(rate), b.tokens+float64(now.Sub(b.updated))/float64(perToken))
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(perToken))
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// prune removes the token buckets that are full again, once per period. The lock must be held.
+func (rl *rateLimiter) prune(now time.Time) {
+	if now.Sub(rl.pruned) < rateLimitPeriod {
+		return
+	}
+
+	for k, b := range rl.buckets {
+		if now.Sub(b.updated) >= rateLimitPeriod {
+			delete(rl.buckets, k)
+		}
+	}
+
+	rl.pruned = now
+}
+
+// acquireHeavy reserves a slot for a request to the endpoint, if the endpoint is heavy.
+// It returns false if all slots are used, otherwise the returned func releases the slot.
+func (rl *rateLimiter) acquireHeavy(endpoint string) (func(), bool) {
+	if _, ok := rl.heavyEnds[endpoint]; !ok || rl.heavy == nil {
+		return func() {}, true
+	}
+
+	select {
+	case rl.heavy <- struct{}{}:
+		return func() { <-rl.heavy }, true
+	default:
+		return nil, false
+	}
+}
+
+// rateLimitClient returns the identity of the client that made the request, used to
+// share the rate limits of a client across its requests.
+// The request must have been authenticated.
+func rateLimitClient(r *http.Request) string {
+	if id, ok := r.Context().Value(apiKeyIDContextKey).(string); ok {
+		return "apikey:" + id
+	}
+
+	if user, _, ok := r.BasicAuth(); ok {
+		return "user:" + user
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// rateLimitCheck rejects the requests of clients that exceeded the rate of the endpoint,
+// and the requests to heavy endpoints that exceed the concurrency limit,
+// with 429 Too Many Requests and a Retry-After header
+func rateLimitCheck(apiVersion, endpoint string, rl *rateLimiter, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client := rateLimitClient(r)
+
+		if ok, retryAfter := rl.allow(endpoint, client); !ok {
+			logger.WithField("client", client).Debugf("Rate limit of %s exceeded", endpoint)
+			writeTooManyRequests(w, apiVersion, retryAfter, "Rate limit exceeded")
+			return
+		}
+
+		release, ok := rl.acquireHeavy(endpoint)
+		if !ok {
+			logger.WithField("client", client).Debugf("Concurrency limit of %s exceeded", endpoint)
+			writeTooManyRequests(w, apiVersion, heavyRequestRetryAfter, "Too many concurrent requests")
+			return
+		}
+		defer release()
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// writeTooManyRequests writes a 429 Too Many Requests error with a Retry-After header,
+// rounding retryAfter up to the second
+func writeTooManyRequests(w http.ResponseWriter, apiVersion string, retryAfter time.Duration, msg string) {
+	secs := int64(math.Ceil(retryAfter.Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.FormatInt(secs, 10))
+	writeError(w, apiVersion, http.StatusTooManyRequests, msg)
+}
@@ -0,0 +1,299 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitConfigValidate(t *testing.T) {
+	require.False(t, RateLimitConfig{}.Enabled())
+	require.False(t, RateLimitConfig{EndpointRates: map[string]int{"/api/v1/richlist": 0}}.Enabled())
+	require.True(t, RateLimitConfig{Rate: 10}.Enabled())
+	require.True(t, RateLimitConfig{EndpointRates: map[string]int{"/api/v1/richlist": 1}}.Enabled())
+	require.True(t, RateLimitConfig{MaxHeavyRequests: 1}.Enabled())
+
+	require.NoError(t, RateLimitConfig{Rate: 10, MaxHeavyRequests: 2}.Validate())
+	require.EqualError(t, RateLimitConfig{Rate: -1}.Validate(), "rate limit must not be negative")
+	require.EqualError(t, RateLimitConfig{MaxHeavyRequests: -1}.Validate(), "max heavy requests must not be negative")
+	require.EqualError(t, RateLimitConfig{
+		EndpointRates: map[string]int{"/api/v1/richlist": -1},
+	}.Validate(), `rate limit of endpoint "/api/v1/richlist" must not be negative`)
+}
+
+func TestHeavyEndpointsAreRoutes(t *testing.T) {
+	// A heavy endpoint that is not a route, e.g. because of a typo, would never be limited
+	for _, e := range heavyEndpoints {
+		_, ok := endpointsMethods[e]
+		require.True(t, ok, "heavy endpoint %s is not a route", e)
+	}
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	rl := newRateLimiter(RateLimitConfig{
+		Rate: 2,
+		EndpointRates: map[string]int{
+			"/api/v1/richlist": 1,
+			"/api/v1/version":  0,
+		},
+	})
+	rl.now = func() time.Time {
+		return now
+	}
+
+	// The default rate is 2 requests per minute per endpoint
+	ok, _ := rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.True(t, ok)
+	ok, _ = rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.True(t, ok)
+	ok, retryAfter := rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.False(t, ok)
+	require.Equal(t, 30*time.Second, retryAfter)
+
+	// Other clients and other endpoints have their own budget
+	ok, _ = rl.allow("/api/v1/outputs", "ip:1.2.3.5")
+	require.True(t, ok)
+	ok, _ = rl.allow("/api/v1/blocks", "ip:1.2.3.4")
+	require.True(t, ok)
+
+	// Endpoint rates override the default rate
+	ok, _ = rl.allow("/api/v1/richlist", "ip:1.2.3.4")
+	require.True(t, ok)
+	ok, retryAfter = rl.allow("/api/v1/richlist", "ip:1.2.3.4")
+	require.False(t, ok)
+	require.Equal(t, time.Minute, retryAfter)
+
+	// An endpoint rate of zero is unlimited
+	for i := 0; i < 10; i++ {
+		ok, _ = rl.allow("/api/v1/version", "ip:1.2.3.4")
+		require.True(t, ok)
+	}
+
+	// The budget refills over time
+	now = now.Add(20 * time.Second)
+	ok, retryAfter = rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.False(t, ok)
+	require.Equal(t, 10*time.Second, retryAfter)
+
+	now = now.Add(10 * time.Second)
+	ok, _ = rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.True(t, ok)
+	ok, _ = rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.False(t, ok)
+
+	// The budget does not exceed the rate
+	now = now.Add(time.Hour)
+	ok, _ = rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.True(t, ok)
+	ok, _ = rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.True(t, ok)
+	ok, _ = rl.allow("/api/v1/outputs", "ip:1.2.3.4")
+	require.False(t, ok)
+
+	// Buckets that are full again are pruned
+	require.Len(t, rl.buckets, 1)
+}
+
+func TestRateLimiterAcquireHeavy(t *testing.T) {
+	rl := newRateLimiter(RateLimitConfig{
+		MaxHeavyRequests: 2,
+	})
+
+	release1, ok := rl.acquireHeavy("/api/v1/richlist")
+	require.True(t, ok)
+	release2, ok := rl.acquireHeavy("/api/v1/addresscount")
+	require.True(t, ok)
+
+	_, ok = rl.acquireHeavy("/api/v1/outputs")
+	require.False(t, ok)
+
+	// Other endpoints are not limited
+	release, ok := rl.acquireHeavy("/api/v1/version")
+	require.True(t, ok)
+	release()
+
+	release1()
+	release3, ok := rl.acquireHeavy("/api/v1/outputs")
+	require.True(t, ok)
+
+	release2()
+	release3()
+	require.Empty(t, rl.heavy)
+
+	// Heavy endpoints are not limited if MaxHeavyRequests is zero
+	rl = newRateLimiter(RateLimitConfig{
+		Rate: 1,
+	})
+	for i := 0; i < 10; i++ {
+		_, ok = rl.acquireHeavy("/api/v1/richlist")
+		require.True(t, ok)
+	}
+}
+
+func TestRateLimitClient(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/version", nil)
+	r.RemoteAddr = "1.2.3.4:5678"
+	require.Equal(t, "ip:1.2.3.4", rateLimitClient(r))
+
+	r.RemoteAddr = "1.2.3.4"
+	require.Equal(t, "ip:1.2.3.4", rateLimitClient(r))
+
+	r.SetBasicAuth("admin", "pwd")
+	require.Equal(t, "user:admin", rateLimitClient(r))
+
+	r = r.WithContext(context.WithValue(r.Context(), apiKeyIDContextKey, "ak_foo"))
+	require.Equal(t, "apikey:ak_foo", rateLimitClient(r))
+}
+
+func TestRateLimitCheck(t *testing.T) {
+	doRequest := func(c muxConfig, endpoint, remoteAddr, username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+		req.Host = configuredHost
+		req.RemoteAddr = remoteAddr
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+
+		rr := httptest.NewRecorder()
+		newServerHandler(c, &MockGatewayer{}).ServeHTTP(rr, req)
+		return rr
+	}
+
+	c := defaultMuxConfig()
+	c.rateLimiter = newRateLimiter(RateLimitConfig{
+		Rate: 1,
+	})
+
+	rr := doRequest(c, "/api/v1/version", "1.2.3.4:5678", "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// API v1 error format
+	rr = doRequest(c, "/api/v1/version", "1.2.3.4:5679", "", "")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "60", rr.Header().Get("Retry-After"))
+	require.Equal(t, "429 Too Many Requests - Rate limit exceeded\n", rr.Body.String())
+
+	// Other IPs are not limited
+	rr = doRequest(c, "/api/v1/version", "1.2.3.5:5678", "", "")
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	// API v2 error format
+	rr = doRequest(c, "/api/v2/apikeys", "1.2.3.4:5678", "", "")
+	require.Equal(t, http.StatusForbidden, rr.Code)
+	rr = doRequest(c, "/api/v2/apikeys", "1.2.3.4:5678", "", "")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "60", rr.Header().Get("Retry-After"))
+
+	var rsp ReceivedHTTPResponse
+	require.NoError(t, json.NewDecoder(rr.Body).Decode(&rsp))
+	require.Equal(t, &HTTPError{
+		Code:    http.StatusTooManyRequests,
+		Message: "Rate limit exceeded",
+	}, rsp.Error)
+
+	// API keys and the web interface username are limited separately from their IP
+	s, cleanup := newTestAPIKeyStore(t)
+	defer cleanup()
+
+	k, secret, err := s.Create(APIKeyParams{
+		APISets: []string{EndpointsRead},
+	})
+	require.NoError(t, err)
+
+	c = apiKeyMuxConfig(s)
+	c.rateLimiter = newRateLimiter(RateLimitConfig{
+		Rate: 1,
+	})
+
+	rr = doRequest(c, "/api/v1/version", "1.2.3.4:5678", k.ID, secret)
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = doRequest(c, "/api/v1/version", "1.2.3.4:5678", k.ID, secret)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	rr = doRequest(c, "/api/v1/version", "1.2.3.4:5678", "admin", "pwd")
+	require.Equal(t, http.StatusOK, rr.Code)
+	rr = doRequest(c, "/api/v1/version", "1.2.3.4:5678", "admin", "pwd")
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	// Requests that fail authentication are rejected before the rate limit
+	rr = doRequest(c, "/api/v1/version", "1.2.3.4:5678", "admin", "bad")
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+}
+
+func TestRateLimitCheckHeavy(t *testing.T) {
+	rl := newRateLimiter(RateLimitConfig{
+		MaxHeavyRequests: 1,
+	})
+
+	started := make(chan struct{})
+	done := make(chan struct{})
+	handler := rateLimitCheck(apiVersion1, "/api/v1/richlist", rl, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-done
+	}))
+
+	go func() {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/richlist", nil))
+	}()
+	<-started
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/richlist", nil))
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+	require.Equal(t, "1", rr.Header().Get("Retry-After"))
+	require.Equal(t, "429 Too Many Requests - Too many concurrent requests\n", rr.Body.String())
+
+	close(done)
+}
+
+func TestRateLimitRPC(t *testing.T) {
+	c := defaultMuxConfig()
+	c.rateLimiter = newRateLimiter(RateLimitConfig{
+		EndpointRates: map[string]int{
+			"/api/v1/version": 2,
+		},
+		MaxHeavyRequests: 1,
+	})
+
+	// Each call of a batch is charged to the REST endpoint of its method
+	status, body := doRPCRequest(t, c, &MockGatewayer{}, http.MethodPost, `[
+		{"jsonrpc":"2.0","id":1,"method":"version"},
+		{"jsonrpc":"2.0","id":2,"method":"version"},
+		{"jsonrpc":"2.0","id":3,"method":"version"}
+	]`)
+	require.Equal(t, http.StatusOK, status)
+
+	var rsps []RPCResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &rsps))
+	require.Len(t, rsps, 3)
+	require.Nil(t, rsps[0].Error)
+	require.Nil(t, rsps[1].Error)
+	require.Equal(t, &RPCError{
+		Code:    RPCErrorTooManyRequests,
+		Message: "Rate limit exceeded",
+	}, rsps[2].Error)
+
+	// The REST endpoint shares the rate limit
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/version", nil)
+	req.Host = configuredHost
+	req.RemoteAddr = "" // the remote addr of the RPC requests of doRPCRequest
+	newServerMux(c, &MockGatewayer{}).ServeHTTP(rr, req)
+	require.Equal(t, http.StatusTooManyRequests, rr.Code)
+
+	// Calls of the methods of heavy endpoints are subject to the concurrency limit
+	release, ok := c.rateLimiter.acquireHeavy("/api/v1/outputs")
+	require.True(t, ok)
+	defer release()
+
+	status, body = doRPCRequest(t, c, &MockGatewayer{}, http.MethodPost, `{"jsonrpc":"2.0","id":1,"method":"outputs","params":{}}`)
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"Too many concurrent requests"}}`, body)
+}
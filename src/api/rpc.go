@@ -0,0 +1,836 @@
+package api
+
+// JSON-RPC 2.0 interface, see https://www.jsonrpc.org/specification
+//
+// The methods are backed by the same Gatewayer as the REST API and are enabled
+// by the same API sets as their REST endpoints. Params are passed by name.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+const (
+	// RPCVersion is the JSON-RPC protocol version
+	RPCVersion = "2.0"
+
+	// MaxRPCBatchSize is the maximum number of requests in a batch
+	MaxRPCBatchSize = 100
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	// RPCErrorParse is returned when the request is not valid JSON
+	RPCErrorParse = -32700
+	// RPCErrorInvalidRequest is returned when the request is not a valid request object
+	RPCErrorInvalidRequest = -32600
+	// RPCErrorMethodNotFound is returned when the method does not exist
+	RPCErrorMethodNotFound = -32601
+	// RPCErrorInvalidParams is returned when the params of the method are invalid
+	RPCErrorInvalidParams = -32602
+	// RPCErrorInternal is returned on internal errors of the node
+	RPCErrorInternal = -32603
+
+	// RPCErrorNotFound is returned when a block, transaction, wallet or account does not exist
+	RPCErrorNotFound = -32000
+	// RPCErrorForbidden is returned when the method or the wallet API is disabled
+	RPCErrorForbidden = -32001
+	// RPCErrorWallet is returned for wallet.Error errors, such as an invalid password
+	RPCErrorWallet = -32002
+	// RPCErrorTransaction is returned when a transaction is invalid or can't be created
+	RPCErrorTransaction = -32003
+	// RPCErrorUnavailable is returned when a transaction can't be broadcast
+	RPCErrorUnavailable = -32004
+	// RPCErrorTooManyRequests is returned when a call exceeds the rate limit or the concurrency limit of the REST endpoint of its method
+	RPCErrorTooManyRequests = -32005
+)
+
+// RPCRequest is a JSON-RPC 2.0 request. A request without ID is a notification, which is not answered.
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// RPCResponse is a JSON-RPC 2.0 response
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError is the error of a JSON-RPC 2.0 response
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewRPCError creates an RPCError
+func NewRPCError(code int, message string) *RPCError {
+	return &RPCError{
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// newRPCErrorFromError maps an error returned by a method to an RPCError
+func newRPCErrorFromError(err error) *RPCError {
+	switch e := err.(type) {
+	case *RPCError:
+		return e
+	case wallet.Error:
+		switch err {
+		case wallet.ErrWalletNotExist,
+			wallet.ErrBip44AccountNotFound:
+			return NewRPCError(RPCErrorNotFound, err.Error())
+		case wallet.ErrWalletAPIDisabled:
+			return NewRPCError(RPCErrorForbidden, err.Error())
+		default:
+			return NewRPCError(RPCErrorWallet, err.Error())
+		}
+	case transaction.Error,
+		transaction.ErrTxnViolatesHardConstraint,
+		transaction.ErrTxnViolatesSoftConstraint,
+		transaction.ErrTxnViolatesUserConstraint,
+		visor.UserError,
+		blockdb.ErrUnspentNotExist:
+		return NewRPCError(RPCErrorTransaction, err.Error())
+	case visor.ErrBlockNotExist:
+		return NewRPCError(RPCErrorNotFound, err.Error())
+	}
+
+	switch err {
+	case fee.ErrTxnNoFee,
+		fee.ErrTxnInsufficientCoinHours:
+		return NewRPCError(RPCErrorTransaction, err.Error())
+	}
+
+	if daemon.IsBroadcastFailure(err) {
+		return NewRPCError(RPCErrorUnavailable, err.Error())
+	}
+
+	return NewRPCError(RPCErrorInternal, err.Error())
+}
+
+// rpcMethod is a JSON-RPC method
+type rpcMethod struct {
+	// endpoint is the REST endpoint of the method, whose rate limits apply to the calls of the method
+	endpoint string
+	// apiSets are the API sets that enable the method, the method is always enabled if there are none
+	apiSets []string
+	call    func(params json.RawMessage) (interface{}, error)
+}
+
+// Serves JSON-RPC 2.0 requests and batches of requests.
+// Method: POST
+// URI: /rpc
+func rpcHandler(c muxConfig, gateway Gatewayer) http.HandlerFunc {
+	methods := newRPCMethods(c, gateway)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		client := rateLimitClient(r)
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if !json.Valid(body) {
+			writeRPCResponse(w, newRPCErrorResponse(nil, NewRPCError(RPCErrorParse, "parse error")))
+			return
+		}
+
+		body = bytes.TrimSpace(body)
+		if len(body) == 0 || body[0] != '[' {
+			if resp := serveRPCRequest(c, methods, client, body); resp != nil {
+				writeRPCResponse(w, resp)
+			} else {
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+
+		var batch []json.RawMessage
+		if err := json.Unmarshal(body, &batch); err != nil {
+			writeRPCResponse(w, newRPCErrorResponse(nil, NewRPCError(RPCErrorParse, "parse error")))
+			return
+		}
+
+		switch {
+		case len(batch) == 0:
+			writeRPCResponse(w, newRPCErrorResponse(nil, NewRPCError(RPCErrorInvalidRequest, "empty batch")))
+			return
+		case len(batch) > MaxRPCBatchSize:
+			msg := fmt.Sprintf("batch exceeds %d requests", MaxRPCBatchSize)
+			writeRPCResponse(w, newRPCErrorResponse(nil, NewRPCError(RPCErrorInvalidRequest, msg)))
+			return
+		}
+
+		resps := make([]*RPCResponse, 0, len(batch))
+		for _, req := range batch {
+			if resp := serveRPCRequest(c, methods, client, req); resp != nil {
+				resps = append(resps, resp)
+			}
+		}
+
+		// A batch of notifications is not answered
+		if len(resps) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		writeRPCResponse(w, resps)
+	}
+}
+
+// serveRPCRequest serves a request of a batch or a single request, made by the rate limit client.
+// Returns nil if the request is a notification.
+func serveRPCRequest(c muxConfig, methods map[string]rpcMethod, client string, data json.RawMessage) *RPCResponse {
+	var req RPCRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return newRPCErrorResponse(nil, NewRPCError(RPCErrorInvalidRequest, "invalid request"))
+	}
+
+	if !validRPCID(req.ID) {
+		return newRPCErrorResponse(nil, NewRPCError(RPCErrorInvalidRequest, "id must be a string, a number or null"))
+	}
+
+	if req.JSONRPC != RPCVersion {
+		return newRPCErrorResponse(req.ID, NewRPCError(RPCErrorInvalidRequest, `jsonrpc must be "2.0"`))
+	}
+
+	if req.Method == "" {
+		return newRPCErrorResponse(req.ID, NewRPCError(RPCErrorInvalidRequest, "method is required"))
+	}
+
+	result, err := callRPCMethod(c, methods, client, req)
+
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	if err != nil {
+		return newRPCErrorResponse(req.ID, newRPCErrorFromError(err))
+	}
+
+	return &RPCResponse{
+		JSONRPC: RPCVersion,
+		ID:      req.ID,
+		Result:  result,
+	}
+}
+
+// callRPCMethod calls the method of a request if it is enabled, and encodes its result.
+// Each call is charged to the rate limits of the REST endpoint of the method, so that a batch
+// can't make more calls than the client could make to the endpoint.
+func callRPCMethod(c muxConfig, methods map[string]rpcMethod, client string, req RPCRequest) (json.RawMessage, error) {
+	m, ok := methods[req.Method]
+	if !ok {
+		return nil, NewRPCError(RPCErrorMethodNotFound, fmt.Sprintf("method %q not found", req.Method))
+	}
+
+	if !rpcMethodEnabled(c, m) {
+		return nil, NewRPCError(RPCErrorForbidden, "Method is disabled")
+	}
+
+	if c.rateLimiter != nil {
+		if ok, _ := c.rateLimiter.allow(m.endpoint, client); !ok {
+			logger.WithField("client", client).Debugf("Rate limit of %s exceeded by RPC method %s", m.endpoint, req.Method)
+			return nil, NewRPCError(RPCErrorTooManyRequests, "Rate limit exceeded")
+		}
+
+		release, ok := c.rateLimiter.acquireHeavy(m.endpoint)
+		if !ok {
+			logger.WithField("client", client).Debugf("Concurrency limit of %s exceeded by RPC method %s", m.endpoint, req.Method)
+			return nil, NewRPCError(RPCErrorTooManyRequests, "Too many concurrent requests")
+		}
+		defer release()
+	}
+
+	result, err := m.call(req.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(result)
+}
+
+// rpcMethodEnabled returns true if one of the API sets of the method is enabled
+func rpcMethodEnabled(c muxConfig, m rpcMethod) bool {
+	if len(m.apiSets) == 0 {
+		return true
+	}
+
+	for _, k := range m.apiSets {
+		if _, ok := c.enabledAPISets[k]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validRPCID returns true if the id of a request is absent, a string, a number or null
+func validRPCID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return true
+	}
+
+	switch id[0] {
+	case '"', '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		return true
+	default:
+		return bytes.Equal(id, []byte("null"))
+	}
+}
+
+func newRPCErrorResponse(id json.RawMessage, err *RPCError) *RPCResponse {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return &RPCResponse{
+		JSONRPC: RPCVersion,
+		ID:      id,
+		Error:   err,
+	}
+}
+
+func writeRPCResponse(w http.ResponseWriter, resp interface{}) {
+	out, err := json.MarshalIndent(resp, "", "    ")
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusInternalServerError, "")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	w.Header().Add("Content-Type", ContentTypeJSON)
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write(out); err != nil {
+		logger.WithError(err).Error("http Write failed")
+	}
+}
+
+// decodeRPCParams decodes the params of a request into v. Params are optional,
+// and must be an object with the fields of v.
+func decodeRPCParams(params json.RawMessage, v interface{}) error {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return nil
+	}
+
+	if params[0] != '{' {
+		return NewRPCError(RPCErrorInvalidParams, "params must be an object")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return NewRPCError(RPCErrorInvalidParams, err.Error())
+	}
+
+	return nil
+}
+
+func newRPCInvalidParams(format string, a ...interface{}) *RPCError {
+	return NewRPCError(RPCErrorInvalidParams, fmt.Sprintf(format, a...))
+}
+
+func decodeRPCAddresses(name string, addrs []string) ([]cipher.Address, error) {
+	decoded := make([]cipher.Address, len(addrs))
+	for i, a := range addrs {
+		addr, err := cipher.DecodeBase58Address(a)
+		if err != nil {
+			return nil, newRPCInvalidParams("invalid %s %q: %v", name, a, err)
+		}
+		decoded[i] = addr
+	}
+	return decoded, nil
+}
+
+// RPCBlockParams are the params of the block method
+type RPCBlockParams struct {
+	Hash    string  `json:"hash"`
+	Seq     *uint64 `json:"seq"`
+	Verbose bool    `json:"verbose"`
+}
+
+// RPCLastBlocksParams are the params of the last_blocks method
+type RPCLastBlocksParams struct {
+	Num     uint64 `json:"num"`
+	Verbose bool   `json:"verbose"`
+}
+
+// RPCTransactionParams are the params of the transaction method
+type RPCTransactionParams struct {
+	TxID    string `json:"txid"`
+	Verbose bool   `json:"verbose"`
+}
+
+// RPCAddressesParams are the params of the balance method
+type RPCAddressesParams struct {
+	Addrs []string `json:"addrs"`
+}
+
+// RPCOutputsParams are the params of the outputs method
+type RPCOutputsParams struct {
+	Addrs  []string `json:"addrs"`
+	Hashes []string `json:"hashes"`
+}
+
+// RPCWalletParams are the params of the wallet_balance method
+type RPCWalletParams struct {
+	ID string `json:"id"`
+}
+
+// RPCWalletNewAddressesParams are the params of the wallet_new_addresses method
+type RPCWalletNewAddressesParams struct {
+	ID       string `json:"id"`
+	Num      uint64 `json:"num"`
+	Password string `json:"password"`
+}
+
+// RPCWalletNewAddressesResult is the result of the wallet_new_addresses method
+type RPCWalletNewAddressesResult struct {
+	Addresses []string `json:"addresses"`
+}
+
+// newRPCMethods creates the JSON-RPC methods. The params of the methods match the args of their REST endpoints:
+//     version: /api/v1/version
+//     blockchain_metadata: /api/v1/blockchain/metadata
+//     blockchain_progress: /api/v1/blockchain/progress
+//     block: /api/v1/block
+//     last_blocks: /api/v1/last_blocks
+//     transaction: /api/v1/transaction
+//     balance: /api/v1/balance
+//     outputs: /api/v1/outputs
+//     inject_transaction: /api/v1/injectTransaction
+//     wallet_balance: /api/v1/wallet/balance
+//     wallet_new_addresses: /api/v1/wallet/newAddress
+//     wallet_create_transaction: /api/v1/wallet/transaction
+//     wallet_sign_transaction: /api/v2/wallet/transaction/sign
+func newRPCMethods(c muxConfig, gateway Gatewayer) map[string]rpcMethod {
+	return map[string]rpcMethod{
+		"version": {
+			endpoint: "/api/v1/version",
+			call: func(json.RawMessage) (interface{}, error) {
+				return c.health.BuildInfo, nil
+			},
+		},
+
+		"blockchain_metadata": {
+			endpoint: "/api/v1/blockchain/metadata",
+			apiSets: []string{EndpointsRead, EndpointsStatus},
+			call: func(json.RawMessage) (interface{}, error) {
+				metadata, err := gateway.GetBlockchainMetadata()
+				if err != nil {
+					return nil, err
+				}
+
+				// This can happen if the node is shut down at the right moment, guard against a panic
+				if metadata == nil {
+					return nil, errors.New("gateway.GetBlockchainMetadata metadata is nil")
+				}
+
+				return readable.NewBlockchainMetadata(*metadata), nil
+			},
+		},
+
+		"blockchain_progress": {
+			endpoint: "/api/v1/blockchain/progress",
+			apiSets: []string{EndpointsRead, EndpointsStatus},
+			call: func(json.RawMessage) (interface{}, error) {
+				headSeq, _, err := gateway.HeadBkSeq()
+				if err != nil {
+					return nil, err
+				}
+
+				progress := gateway.GetBlockchainProgress(headSeq)
+				if progress == nil {
+					return nil, errors.New("gateway.GetBlockchainProgress progress is nil")
+				}
+
+				return readable.NewBlockchainProgress(progress), nil
+			},
+		},
+
+		"block": {
+			endpoint: "/api/v1/block",
+			apiSets: []string{EndpointsRead},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p RPCBlockParams
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				switch {
+				case p.Hash == "" && p.Seq == nil:
+					return nil, newRPCInvalidParams("should specify one filter, hash or seq")
+				case p.Hash != "" && p.Seq != nil:
+					return nil, newRPCInvalidParams("should only specify one filter, hash or seq")
+				}
+
+				var h cipher.SHA256
+				if p.Hash != "" {
+					var err error
+					h, err = cipher.SHA256FromHex(p.Hash)
+					if err != nil {
+						return nil, newRPCInvalidParams("invalid hash: %v", err)
+					}
+				}
+
+				var b *coin.SignedBlock
+				var inputs [][]visor.TransactionInput
+				var err error
+				switch {
+				case p.Verbose && p.Seq != nil:
+					b, inputs, err = gateway.GetSignedBlockBySeqVerbose(*p.Seq)
+				case p.Verbose:
+					b, inputs, err = gateway.GetSignedBlockByHashVerbose(h)
+				case p.Seq != nil:
+					b, err = gateway.GetSignedBlockBySeq(*p.Seq)
+				default:
+					b, err = gateway.GetSignedBlockByHash(h)
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				if b == nil {
+					return nil, NewRPCError(RPCErrorNotFound, "block not found")
+				}
+
+				if p.Verbose {
+					return readable.NewBlockVerbose(b.Block, inputs)
+				}
+				return readable.NewBlock(b.Block)
+			},
+		},
+
+		"last_blocks": {
+			endpoint: "/api/v1/last_blocks",
+			apiSets: []string{EndpointsRead},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p RPCLastBlocksParams
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				maxLBC := gateway.DaemonConfig().MaxLastBlocksCount
+				if p.Num > maxLBC {
+					return nil, newRPCInvalidParams("num: %d must < %d", p.Num, maxLBC)
+				}
+
+				if p.Verbose {
+					blocks, inputs, err := gateway.GetLastBlocksVerbose(p.Num)
+					if err != nil {
+						return nil, err
+					}
+					return readable.NewBlocksVerbose(blocks, inputs)
+				}
+
+				blocks, err := gateway.GetLastBlocks(p.Num)
+				if err != nil {
+					return nil, err
+				}
+				return readable.NewBlocks(blocks)
+			},
+		},
+
+		"transaction": {
+			endpoint: "/api/v1/transaction",
+			apiSets: []string{EndpointsRead},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p RPCTransactionParams
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if p.TxID == "" {
+					return nil, newRPCInvalidParams("txid is required")
+				}
+
+				h, err := cipher.SHA256FromHex(p.TxID)
+				if err != nil {
+					return nil, newRPCInvalidParams("invalid txid: %v", err)
+				}
+
+				if p.Verbose {
+					txn, inputs, err := gateway.GetTransactionWithInputs(h)
+					if err != nil {
+						return nil, err
+					}
+					if txn == nil {
+						return nil, NewRPCError(RPCErrorNotFound, "transaction not found")
+					}
+					return readable.NewTransactionWithStatusVerbose(txn, inputs)
+				}
+
+				txn, err := gateway.GetTransaction(h)
+				if err != nil {
+					return nil, err
+				}
+				if txn == nil {
+					return nil, NewRPCError(RPCErrorNotFound, "transaction not found")
+				}
+				return readable.NewTransactionWithStatus(txn)
+			},
+		},
+
+		"balance": {
+			endpoint: "/api/v1/balance",
+			apiSets: []string{EndpointsRead},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p RPCAddressesParams
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if len(p.Addrs) == 0 {
+					return nil, newRPCInvalidParams("addrs is required")
+				}
+
+				addrs, err := decodeRPCAddresses("address", p.Addrs)
+				if err != nil {
+					return nil, err
+				}
+
+				bals, err := gateway.GetBalanceOfAddresses(addrs)
+				if err != nil {
+					return nil, err
+				}
+
+				return newAddressesBalanceResponse(addrs, bals)
+			},
+		},
+
+		"outputs": {
+			endpoint: "/api/v1/outputs",
+			apiSets: []string{EndpointsRead},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p RPCOutputsParams
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if len(p.Addrs) != 0 && len(p.Hashes) != 0 {
+					return nil, newRPCInvalidParams("addrs and hashes cannot be specified together")
+				}
+
+				var filters []visor.OutputsFilter
+
+				if len(p.Addrs) != 0 {
+					addrs, err := decodeRPCAddresses("address", p.Addrs)
+					if err != nil {
+						return nil, err
+					}
+					filters = append(filters, visor.FbyAddresses(addrs))
+				}
+
+				if len(p.Hashes) != 0 {
+					hashes := make([]cipher.SHA256, len(p.Hashes))
+					for i, s := range p.Hashes {
+						h, err := cipher.SHA256FromHex(s)
+						if err != nil {
+							return nil, newRPCInvalidParams("invalid hash %q: %v", s, err)
+						}
+						hashes[i] = h
+					}
+					filters = append(filters, visor.FbyHashes(hashes))
+				}
+
+				summary, err := gateway.GetUnspentOutputsSummary(filters)
+				if err != nil {
+					return nil, err
+				}
+
+				return readable.NewUnspentOutputsSummary(summary)
+			},
+		},
+
+		"inject_transaction": {
+			endpoint: "/api/v1/injectTransaction",
+			apiSets: []string{EndpointsTransaction, EndpointsWallet},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p InjectTransactionRequest
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if p.RawTxn == "" {
+					return nil, newRPCInvalidParams("rawtx is required")
+				}
+
+				txn, err := coin.DeserializeTransactionHex(p.RawTxn)
+				if err != nil {
+					return nil, newRPCInvalidParams("invalid rawtx: %v", err)
+				}
+
+				if p.NoBroadcast {
+					err = gateway.InjectTransaction(txn)
+				} else {
+					err = gateway.InjectBroadcastTransaction(txn)
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				return txn.Hash().Hex(), nil
+			},
+		},
+
+		"wallet_balance": {
+			endpoint: "/api/v1/wallet/balance",
+			apiSets: []string{EndpointsWallet},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p RPCWalletParams
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if p.ID == "" {
+					return nil, newRPCInvalidParams("id is required")
+				}
+
+				walletBalance, addressBalances, err := gateway.GetWalletBalance(p.ID)
+				if err != nil {
+					return nil, err
+				}
+
+				return BalanceResponse{
+					BalancePair: readable.NewBalancePair(walletBalance),
+					Addresses:   readable.NewAddressBalances(addressBalances),
+				}, nil
+			},
+		},
+
+		"wallet_new_addresses": {
+			endpoint: "/api/v1/wallet/newAddress",
+			apiSets: []string{EndpointsWallet},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p RPCWalletNewAddressesParams
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if p.ID == "" {
+					return nil, newRPCInvalidParams("id is required")
+				}
+
+				var opts []wallet.Option
+				if p.Num != 0 {
+					opts = append(opts, wallet.OptionGenerateN(p.Num))
+				}
+
+				addrs, err := gateway.NewAddresses(p.ID, []byte(p.Password), opts...)
+				if err != nil {
+					return nil, err
+				}
+
+				result := RPCWalletNewAddressesResult{
+					Addresses: make([]string, len(addrs)),
+				}
+				for i, a := range addrs {
+					result.Addresses[i] = a.String()
+				}
+				return result, nil
+			},
+		},
+
+		"wallet_create_transaction": {
+			endpoint: "/api/v1/wallet/transaction",
+			apiSets: []string{EndpointsWallet},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p walletCreateTransactionRequest
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if err := p.Validate(); err != nil {
+					return nil, NewRPCError(RPCErrorInvalidParams, err.Error())
+				}
+
+				wp := p.VisorParams()
+				wp.Account = p.Account
+
+				var txn *coin.Transaction
+				var inputs []visor.TransactionInput
+				var err error
+				if p.Unsigned {
+					txn, inputs, err = gateway.WalletCreateTransaction(p.WalletID, p.TransactionParams(), wp)
+				} else {
+					txn, inputs, err = gateway.WalletCreateTransactionSigned(p.WalletID, []byte(p.Password), p.TransactionParams(), wp)
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				return NewCreateTransactionResponse(txn, inputs)
+			},
+		},
+
+		"wallet_sign_transaction": {
+			endpoint: "/api/v2/wallet/transaction/sign",
+			apiSets: []string{EndpointsWallet},
+			call: func(params json.RawMessage) (interface{}, error) {
+				var p WalletSignTransactionRequest
+				if err := decodeRPCParams(params, &p); err != nil {
+					return nil, err
+				}
+
+				if p.WalletID == "" {
+					return nil, newRPCInvalidParams("wallet_id is required")
+				}
+
+				if p.EncodedTransaction == "" {
+					return nil, newRPCInvalidParams("encoded_transaction is required")
+				}
+
+				txn, err := decodeTxn(p.EncodedTransaction)
+				if err != nil {
+					return nil, newRPCInvalidParams("Decode transaction failed: %v", err)
+				}
+
+				if err := validateSignIndexes(txn, p.SignIndexes); err != nil {
+					return nil, NewRPCError(RPCErrorInvalidParams, err.Error())
+				}
+
+				signedTxn, inputs, err := gateway.WalletSignTransaction(p.WalletID, []byte(p.Password), txn, p.SignIndexes)
+				if err != nil {
+					return nil, err
+				}
+
+				return NewCreateTransactionResponse(signedTxn, inputs)
+			},
+		},
+	}
+}
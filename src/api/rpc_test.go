@@ -0,0 +1,320 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func doRPCRequest(t *testing.T, c muxConfig, gateway *MockGatewayer, method, body string) (int, string) {
+	req, err := http.NewRequest(method, "/rpc", strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", ContentTypeJSON)
+
+	rr := httptest.NewRecorder()
+	handler := newServerMux(c, gateway)
+	handler.ServeHTTP(rr, req)
+
+	return rr.Code, rr.Body.String()
+}
+
+func TestRPCHandler(t *testing.T) {
+	buildInfo := readable.BuildInfo{
+		Version: "0.25.0",
+		Commit:  "8798b5ee43c7ce43b9b75d57a1a6cd2c1295cd1e",
+		Branch:  "develop",
+	}
+	versionResult := `{"version":"0.25.0","commit":"8798b5ee43c7ce43b9b75d57a1a6cd2c1295cd1e","branch":"develop"}`
+
+	tt := []struct {
+		name           string
+		method         string
+		body           string
+		enabledAPISets map[string]struct{}
+		status         int
+		rsp            string
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			rsp:    `{"error":{"code":405,"message":"Method Not Allowed"}}`,
+		},
+		{
+			name:   "parse error",
+			body:   `{"jsonrpc":"2.0","id":1,`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"parse error"}}`,
+		},
+		{
+			name:   "request is not an object",
+			body:   `1`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid request"}}`,
+		},
+		{
+			name:   "invalid id",
+			body:   `{"jsonrpc":"2.0","id":{},"method":"version"}`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"id must be a string, a number or null"}}`,
+		},
+		{
+			name:   "invalid version",
+			body:   `{"jsonrpc":"1.0","id":1,"method":"version"}`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":1,"error":{"code":-32600,"message":"jsonrpc must be \"2.0\""}}`,
+		},
+		{
+			name:   "missing method",
+			body:   `{"jsonrpc":"2.0","id":"a"}`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":"a","error":{"code":-32600,"message":"method is required"}}`,
+		},
+		{
+			name:   "method not found",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"foo"}`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method \"foo\" not found"}}`,
+		},
+		{
+			name:   "params not an object",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"wallet_balance","params":["foo.wlt"]}`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"params must be an object"}}`,
+		},
+		{
+			name:   "unknown param",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"wallet_balance","params":{"wallet":"foo.wlt"}}`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"json: unknown field \"wallet\""}}`,
+		},
+		{
+			name:   "missing param",
+			body:   `{"jsonrpc":"2.0","id":1,"method":"wallet_balance","params":{}}`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"id is required"}}`,
+		},
+		{
+			name:           "method disabled",
+			body:           `{"jsonrpc":"2.0","id":1,"method":"wallet_balance","params":{"id":"foo.wlt"}}`,
+			enabledAPISets: map[string]struct{}{EndpointsRead: {}},
+			status:         http.StatusOK,
+			rsp:            `{"jsonrpc":"2.0","id":1,"error":{"code":-32001,"message":"Method is disabled"}}`,
+		},
+		{
+			name:           "version always enabled",
+			body:           `{"jsonrpc":"2.0","id":1,"method":"version"}`,
+			enabledAPISets: map[string]struct{}{},
+			status:         http.StatusOK,
+			rsp:            `{"jsonrpc":"2.0","id":1,"result":` + versionResult + `}`,
+		},
+		{
+			name:   "notification",
+			body:   `{"jsonrpc":"2.0","method":"version"}`,
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "empty batch",
+			body:   `[]`,
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"empty batch"}}`,
+		},
+		{
+			name:   "batch too large",
+			body:   "[" + strings.TrimSuffix(strings.Repeat(`{"jsonrpc":"2.0","method":"version"},`, MaxRPCBatchSize+1), ",") + "]",
+			status: http.StatusOK,
+			rsp:    `{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch exceeds 100 requests"}}`,
+		},
+		{
+			name:   "batch of notifications",
+			body:   `[{"jsonrpc":"2.0","method":"version"},{"jsonrpc":"2.0","method":"foo"}]`,
+			status: http.StatusNoContent,
+		},
+		{
+			name: "batch",
+			body: `[
+				{"jsonrpc":"2.0","id":1,"method":"version"},
+				{"jsonrpc":"2.0","method":"version"},
+				1,
+				{"jsonrpc":"2.0","id":"b","method":"foo"}
+			]`,
+			status: http.StatusOK,
+			rsp: `[
+				{"jsonrpc":"2.0","id":1,"result":` + versionResult + `},
+				{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"invalid request"}},
+				{"jsonrpc":"2.0","id":"b","error":{"code":-32601,"message":"method \"foo\" not found"}}
+			]`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			c := defaultMuxConfig()
+			c.health.BuildInfo = buildInfo
+			if tc.enabledAPISets != nil {
+				c.enabledAPISets = tc.enabledAPISets
+			}
+
+			method := tc.method
+			if method == "" {
+				method = http.MethodPost
+			}
+
+			status, body := doRPCRequest(t, c, &MockGatewayer{}, method, tc.body)
+			require.Equal(t, tc.status, status)
+			if tc.rsp == "" {
+				require.Empty(t, body)
+				return
+			}
+			require.JSONEq(t, tc.rsp, body)
+		})
+	}
+}
+
+func TestNewRPCErrorFromError(t *testing.T) {
+	tt := []struct {
+		err  error
+		code int
+	}{
+		{NewRPCError(RPCErrorInvalidParams, "bad"), RPCErrorInvalidParams},
+		{wallet.ErrWalletNotExist, RPCErrorNotFound},
+		{wallet.ErrBip44AccountNotFound, RPCErrorNotFound},
+		{wallet.ErrWalletAPIDisabled, RPCErrorForbidden},
+		{wallet.ErrInvalidPassword, RPCErrorWallet},
+		{wallet.ErrPolicyDailyLimit, RPCErrorWallet},
+		{transaction.NewError(errors.New("bad")), RPCErrorTransaction},
+		{transaction.NewErrTxnViolatesHardConstraint(errors.New("bad")), RPCErrorTransaction},
+		{visor.NewUserError(errors.New("bad")), RPCErrorTransaction},
+		{fee.ErrTxnNoFee, RPCErrorTransaction},
+		{visor.NewErrBlockNotExist(10), RPCErrorNotFound},
+		{daemon.ErrNetworkingDisabled, RPCErrorUnavailable},
+		{errors.New("bad"), RPCErrorInternal},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			err := newRPCErrorFromError(tc.err)
+			require.Equal(t, tc.code, err.Code)
+			require.Equal(t, tc.err.Error(), err.Message)
+		})
+	}
+}
+
+func TestRPCMethods(t *testing.T) {
+	txn := makeTransaction(t)
+	addr := cipher.MustDecodeBase58Address("2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U")
+
+	tt := []struct {
+		name  string
+		body  string
+		setup func(gateway *MockGatewayer)
+		rsp   string
+	}{
+		{
+			name: "block not found",
+			body: `{"jsonrpc":"2.0","id":1,"method":"block","params":{"seq":10}}`,
+			setup: func(gateway *MockGatewayer) {
+				gateway.On("GetSignedBlockBySeq", uint64(10)).Return(nil, nil)
+			},
+			rsp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"block not found"}}`,
+		},
+		{
+			name: "block invalid filters",
+			body: `{"jsonrpc":"2.0","id":1,"method":"block","params":{"seq":10,"hash":"ab"}}`,
+			rsp:  `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"should only specify one filter, hash or seq"}}`,
+		},
+		{
+			name: "balance invalid address",
+			body: `{"jsonrpc":"2.0","id":1,"method":"balance","params":{"addrs":["xxx"]}}`,
+			rsp:  `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"invalid address \"xxx\": Invalid address length"}}`,
+		},
+		{
+			name: "wallet balance wallet not found",
+			body: `{"jsonrpc":"2.0","id":1,"method":"wallet_balance","params":{"id":"foo.wlt"}}`,
+			setup: func(gateway *MockGatewayer) {
+				gateway.On("GetWalletBalance", "foo.wlt").Return(wallet.BalancePair{}, nil, wallet.ErrWalletNotExist)
+			},
+			rsp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"wallet doesn't exist"}}`,
+		},
+		{
+			name: "wallet new addresses invalid password",
+			body: `{"jsonrpc":"2.0","id":1,"method":"wallet_new_addresses","params":{"id":"foo.wlt","password":"pwd"}}`,
+			setup: func(gateway *MockGatewayer) {
+				gateway.On("NewAddresses", "foo.wlt", []byte("pwd")).Return(nil, wallet.ErrInvalidPassword)
+			},
+			rsp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32002,"message":"invalid password"}}`,
+		},
+		{
+			name: "wallet new addresses",
+			body: `{"jsonrpc":"2.0","id":1,"method":"wallet_new_addresses","params":{"id":"foo.wlt","num":2}}`,
+			setup: func(gateway *MockGatewayer) {
+				mb := mock.MatchedBy(func(option wallet.Option) bool {
+					return wallet.GetGenerateNFromOptions(option) == 2
+				})
+				gateway.On("NewAddresses", "foo.wlt", []byte(""), mb).Return([]cipher.Address{addr, addr}, nil)
+			},
+			rsp: `{"jsonrpc":"2.0","id":1,"result":{"addresses":["2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U","2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"]}}`,
+		},
+		{
+			name: "inject transaction constraint violation",
+			body: `{"jsonrpc":"2.0","id":1,"method":"inject_transaction","params":{"rawtx":"` + txn.MustSerializeHex() + `"}}`,
+			setup: func(gateway *MockGatewayer) {
+				gateway.On("InjectBroadcastTransaction", txn).Return(transaction.NewErrTxnViolatesHardConstraint(errors.New("bad transaction")))
+			},
+			rsp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32003,"message":"Transaction violates hard constraint: bad transaction"}}`,
+		},
+		{
+			name: "inject transaction broadcast failure",
+			body: `{"jsonrpc":"2.0","id":1,"method":"inject_transaction","params":{"rawtx":"` + txn.MustSerializeHex() + `"}}`,
+			setup: func(gateway *MockGatewayer) {
+				gateway.On("InjectBroadcastTransaction", txn).Return(daemon.ErrNetworkingDisabled)
+			},
+			rsp: `{"jsonrpc":"2.0","id":1,"error":{"code":-32004,"message":"Networking is disabled"}}`,
+		},
+		{
+			name: "inject transaction",
+			body: `{"jsonrpc":"2.0","id":1,"method":"inject_transaction","params":{"rawtx":"` + txn.MustSerializeHex() + `","no_broadcast":true}}`,
+			setup: func(gateway *MockGatewayer) {
+				gateway.On("InjectTransaction", txn).Return(nil)
+			},
+			rsp: `{"jsonrpc":"2.0","id":1,"result":"` + txn.Hash().Hex() + `"}`,
+		},
+		{
+			name: "wallet create transaction invalid params",
+			body: `{"jsonrpc":"2.0","id":1,"method":"wallet_create_transaction","params":{}}`,
+			rsp:  `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"missing wallet_id"}}`,
+		},
+		{
+			name: "wallet sign transaction invalid sign index",
+			body: `{"jsonrpc":"2.0","id":1,"method":"wallet_sign_transaction","params":{"wallet_id":"foo.wlt","encoded_transaction":"` + txn.MustSerializeHex() + `","sign_indexes":[100]}}`,
+			rsp:  `{"jsonrpc":"2.0","id":1,"error":{"code":-32602,"message":"Value in sign_indexes exceeds range of transaction inputs array"}}`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.setup != nil {
+				tc.setup(gateway)
+			}
+
+			status, body := doRPCRequest(t, defaultMuxConfig(), gateway, http.MethodPost, tc.body)
+			require.Equal(t, http.StatusOK, status)
+			require.JSONEq(t, tc.rsp, body)
+			gateway.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+// SearchResult is a block, transaction, output or address found by GET /api/v2/search
+type SearchResult struct {
+	// Type is block, transaction, uxout or address
+	Type string `json:"type"`
+	// ID is the block hash, the transaction ID, the output ID or the address
+	ID string `json:"id"`
+	// BlockSeq is the seq of the block, of the block that executed the transaction
+	// or of the block that created the output
+	BlockSeq *uint64 `json:"block_seq,omitempty"`
+	// Unconfirmed is true for a transaction of the unconfirmed pool
+	Unconfirmed bool `json:"unconfirmed,omitempty"`
+	// Spent is true for an output that was spent
+	Spent bool `json:"spent,omitempty"`
+	// Exact is false for the results matched by a hash prefix
+	Exact bool `json:"exact"`
+	// Redirect is the URI of the API endpoint that returns the result
+	Redirect string `json:"redirect"`
+}
+
+// SearchResponse is returned by GET /api/v2/search
+type SearchResponse struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+	// Truncated is true if more than visor.MaxSearchResults results were found
+	Truncated bool `json:"truncated"`
+	// Redirect is the redirect of the result if the query has exactly one exact result
+	Redirect string `json:"redirect,omitempty"`
+}
+
+// NewSearchResponse creates a SearchResponse from visor.SearchResults
+func NewSearchResponse(q string, results []visor.SearchResult, truncated bool) SearchResponse {
+	rsp := SearchResponse{
+		Query:     q,
+		Results:   make([]SearchResult, len(results)),
+		Truncated: truncated,
+	}
+
+	for i, r := range results {
+		rsp.Results[i] = newSearchResult(r)
+	}
+
+	if len(results) == 1 && results[0].Exact {
+		rsp.Redirect = rsp.Results[0].Redirect
+	}
+
+	return rsp
+}
+
+func newSearchResult(r visor.SearchResult) SearchResult {
+	sr := SearchResult{
+		Type:        string(r.Type),
+		Unconfirmed: r.Unconfirmed,
+		Spent:       r.Spent,
+		Exact:       r.Exact,
+	}
+
+	seq := r.BlockSeq
+	switch r.Type {
+	case visor.SearchResultBlock:
+		sr.ID = r.Hash.Hex()
+		sr.BlockSeq = &seq
+		sr.Redirect = fmt.Sprintf("/api/v1/block?hash=%s", sr.ID)
+	case visor.SearchResultTransaction:
+		sr.ID = r.Hash.Hex()
+		if !r.Unconfirmed {
+			sr.BlockSeq = &seq
+		}
+		sr.Redirect = fmt.Sprintf("/api/v1/transaction?txid=%s", sr.ID)
+	case visor.SearchResultUxOut:
+		sr.ID = r.Hash.Hex()
+		sr.BlockSeq = &seq
+		sr.Redirect = fmt.Sprintf("/api/v1/uxout?uxid=%s", sr.ID)
+	case visor.SearchResultAddress:
+		sr.ID = r.Address.String()
+		sr.Redirect = fmt.Sprintf("/api/v2/transactions?addrs=%s", sr.ID)
+	}
+
+	return sr
+}
+
+// searchHandler classifies a query as a block seq, a block hash, a transaction ID, an output ID or an address,
+// and returns the blocks, transactions (confirmed or unconfirmed), outputs and addresses that it refers to,
+// with the URI of the API endpoint that returns each of them.
+// URI: /api/v2/search
+// Method: GET
+// Args:
+//     q: the query [required]. A hex string of at least 6 characters that is shorter than a hash
+//        matches the block hashes, transaction IDs and output IDs that start with it.
+func searchHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError405Response(w)
+			return
+		}
+
+		q := r.FormValue("q")
+		if q == "" {
+			writeError400Response(w, "q is required")
+			return
+		}
+
+		results, truncated, err := gateway.Search(q)
+		if err != nil {
+			writeError500Response(w, err.Error())
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: NewSearchResponse(q, results, truncated),
+		})
+	}
+}
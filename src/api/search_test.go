@@ -0,0 +1,204 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+func TestSearch(t *testing.T) {
+	hash := "abcdef0123456789abcdef0123456789abcdef0123456789abcdef0123456789"
+	h := cipher.MustSHA256FromHex(hash)
+	addr := "2RxP5N26GhDqHrP6SK45ZzEMSmSpeUeWxsS"
+	seq0 := uint64(0)
+	seq5 := uint64(5)
+
+	cases := []struct {
+		name         string
+		method       string
+		q            string
+		status       int
+		searchRet    []visor.SearchResult
+		searchMore   bool
+		searchErr    error
+		httpResponse HTTPResponse
+	}{
+		{
+			name:         "405",
+			method:       http.MethodPost,
+			q:            hash,
+			status:       http.StatusMethodNotAllowed,
+			httpResponse: NewHTTPErrorResponse(http.StatusMethodNotAllowed, ""),
+		},
+
+		{
+			name:         "400 - missing q",
+			method:       http.MethodGet,
+			status:       http.StatusBadRequest,
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "q is required"),
+		},
+
+		{
+			name:         "500 - gateway error",
+			method:       http.MethodGet,
+			q:            hash,
+			status:       http.StatusInternalServerError,
+			searchErr:    errors.New("Search failed"),
+			httpResponse: NewHTTPErrorResponse(http.StatusInternalServerError, "Search failed"),
+		},
+
+		{
+			name:   "200 - no results",
+			method: http.MethodGet,
+			q:      "foo",
+			status: http.StatusOK,
+			httpResponse: HTTPResponse{
+				Data: SearchResponse{
+					Query:   "foo",
+					Results: []SearchResult{},
+				},
+			},
+		},
+
+		{
+			name:   "200 - exact result",
+			method: http.MethodGet,
+			q:      addr,
+			status: http.StatusOK,
+			searchRet: []visor.SearchResult{
+				{
+					Type:    visor.SearchResultAddress,
+					Address: cipher.MustDecodeBase58Address(addr),
+					Exact:   true,
+				},
+			},
+			httpResponse: HTTPResponse{
+				Data: SearchResponse{
+					Query: addr,
+					Results: []SearchResult{
+						{
+							Type:     "address",
+							ID:       addr,
+							Exact:    true,
+							Redirect: "/api/v2/transactions?addrs=" + addr,
+						},
+					},
+					Redirect: "/api/v2/transactions?addrs=" + addr,
+				},
+			},
+		},
+
+		{
+			name:   "200 - prefix results",
+			method: http.MethodGet,
+			q:      "abcdef",
+			status: http.StatusOK,
+			searchRet: []visor.SearchResult{
+				{
+					Type: visor.SearchResultBlock,
+					Hash: h,
+				},
+				{
+					Type:        visor.SearchResultTransaction,
+					Hash:        h,
+					Unconfirmed: true,
+				},
+				{
+					Type:     visor.SearchResultTransaction,
+					Hash:     h,
+					BlockSeq: 5,
+				},
+				{
+					Type:     visor.SearchResultUxOut,
+					Hash:     h,
+					BlockSeq: 5,
+					Spent:    true,
+				},
+			},
+			searchMore: true,
+			httpResponse: HTTPResponse{
+				Data: SearchResponse{
+					Query: "abcdef",
+					Results: []SearchResult{
+						{
+							Type:     "block",
+							ID:       hash,
+							BlockSeq: &seq0,
+							Redirect: "/api/v1/block?hash=" + hash,
+						},
+						{
+							Type:        "transaction",
+							ID:          hash,
+							Unconfirmed: true,
+							Redirect:    "/api/v1/transaction?txid=" + hash,
+						},
+						{
+							Type:     "transaction",
+							ID:       hash,
+							BlockSeq: &seq5,
+							Redirect: "/api/v1/transaction?txid=" + hash,
+						},
+						{
+							Type:     "uxout",
+							ID:       hash,
+							BlockSeq: &seq5,
+							Spent:    true,
+							Redirect: "/api/v1/uxout?uxid=" + hash,
+						},
+					},
+					Truncated: true,
+				},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("Search", tc.q).Return(tc.searchRet, tc.searchMore, tc.searchErr)
+
+			endpoint := "/api/v2/search"
+			if tc.q != "" {
+				endpoint += "?q=" + url.QueryEscape(tc.q)
+			}
+
+			req, err := http.NewRequest(tc.method, endpoint, nil)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", ContentTypeJSON)
+			setCSRFParameters(t, tokenValid, req)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			status := rr.Code
+			require.Equal(t, tc.status, status, "got `%v` want `%v`", status, tc.status)
+
+			var rsp ReceivedHTTPResponse
+			err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.httpResponse.Error, rsp.Error)
+
+			if rsp.Data == nil {
+				require.Nil(t, tc.httpResponse.Data)
+			} else {
+				require.NotNil(t, tc.httpResponse.Data)
+
+				var searchRsp SearchResponse
+				err := json.Unmarshal(rsp.Data, &searchRsp)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.httpResponse.Data.(SearchResponse), searchRsp)
+			}
+		})
+	}
+}
@@ -542,9 +542,10 @@ func transactionHandlerV2(gateway Gatewayer) http.HandlerFunc {
 
 // walletCreateTransactionRequest is sent to POST /api/v1/wallet/transaction
 type walletCreateTransactionRequest struct {
-	Unsigned bool   `json:"unsigned"`
-	WalletID string `json:"wallet_id"`
-	Password string `json:"password"`
+	Unsigned bool    `json:"unsigned"`
+	WalletID string  `json:"wallet_id"`
+	Password string  `json:"password"`
+	Account  *uint32 `json:"account,omitempty"`
 	createTransactionRequest
 }
 
@@ -591,12 +592,16 @@ func walletCreateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		// Spend from a bip44 account, if selected
+		wp := req.VisorParams()
+		wp.Account = req.Account
+
 		var txn *coin.Transaction
 		var inputs []visor.TransactionInput
 		if req.Unsigned {
-			txn, inputs, err = gateway.WalletCreateTransaction(req.WalletID, req.TransactionParams(), req.VisorParams())
+			txn, inputs, err = gateway.WalletCreateTransaction(req.WalletID, req.TransactionParams(), wp)
 		} else {
-			txn, inputs, err = gateway.WalletCreateTransactionSigned(req.WalletID, []byte(req.Password), req.TransactionParams(), req.VisorParams())
+			txn, inputs, err = gateway.WalletCreateTransactionSigned(req.WalletID, []byte(req.Password), req.TransactionParams(), wp)
 		}
 		if err != nil {
 			switch err.(type) {
@@ -604,7 +609,8 @@ func walletCreateTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 				switch err {
 				case wallet.ErrWalletAPIDisabled:
 					wh.Error403(w, "")
-				case wallet.ErrWalletNotExist:
+				case wallet.ErrWalletNotExist,
+					wallet.ErrBip44AccountNotFound:
 					wh.Error404(w, err.Error())
 				default:
 					wh.Error400(w, err.Error())
@@ -644,6 +650,32 @@ type WalletSignTransactionRequest struct {
 	SignIndexes        []int  `json:"sign_indexes"`
 }
 
+// validateSignIndexes checks that the sign indexes are distinct inputs of the transaction
+func validateSignIndexes(txn *coin.Transaction, signIndexes []int) error {
+	// Check that number of sign_indexes does not exceed number of inputs
+	if len(signIndexes) > len(txn.In) {
+		return errors.New("Too many values in sign_indexes")
+	}
+
+	// Check that values in sign_indexes are in the range of txn inputs
+	for _, i := range signIndexes {
+		if i < 0 || i >= len(txn.In) {
+			return errors.New("Value in sign_indexes exceeds range of transaction inputs array")
+		}
+	}
+
+	// Check for duplicate values in sign_indexes
+	signIndexesMap := make(map[int]struct{}, len(signIndexes))
+	for _, i := range signIndexes {
+		if _, ok := signIndexesMap[i]; ok {
+			return errors.New("Duplicate value in sign_indexes")
+		}
+		signIndexesMap[i] = struct{}{}
+	}
+
+	return nil
+}
+
 // walletSignTransactionHandler signs an unsigned transaction
 // Method: POST
 // URI: /api/v2/wallet/transaction/sign
@@ -682,33 +714,12 @@ func walletSignTransactionHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		// Check that number of sign_indexes does not exceed number of inputs
-		if len(req.SignIndexes) > len(txn.In) {
-			resp := NewHTTPErrorResponse(http.StatusBadRequest, "Too many values in sign_indexes")
+		if err := validateSignIndexes(txn, req.SignIndexes); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
 			writeHTTPResponse(w, resp)
 			return
 		}
 
-		// Check that values in sign_indexes are in the range of txn inputs
-		for _, i := range req.SignIndexes {
-			if i < 0 || i >= len(txn.In) {
-				resp := NewHTTPErrorResponse(http.StatusBadRequest, "Value in sign_indexes exceeds range of transaction inputs array")
-				writeHTTPResponse(w, resp)
-				return
-			}
-		}
-
-		// Check for duplicate values in sign_indexes
-		signIndexesMap := make(map[int]struct{}, len(req.SignIndexes))
-		for _, i := range req.SignIndexes {
-			if _, ok := signIndexesMap[i]; ok {
-				resp := NewHTTPErrorResponse(http.StatusBadRequest, "Duplicate value in sign_indexes")
-				writeHTTPResponse(w, resp)
-				return
-			}
-			signIndexesMap[i] = struct{}{}
-		}
-
 		signedTxn, inputs, err := gateway.WalletSignTransaction(req.WalletID, []byte(req.Password), txn, req.SignIndexes)
 		if err != nil {
 			var resp HTTPResponse
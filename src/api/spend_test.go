@@ -872,9 +872,10 @@ func TestCreateTransaction(t *testing.T) {
 func TestWalletCreateTransaction(t *testing.T) {
 	type rawWalletCreateTxnRequest struct {
 		rawCreateTxnRequest
-		WalletID string `json:"wallet_id"`
-		Password string `json:"password"`
-		Unsigned bool   `json:"unsigned"`
+		WalletID string  `json:"wallet_id"`
+		Password string  `json:"password"`
+		Unsigned bool    `json:"unsigned"`
+		Account  *uint32 `json:"account,omitempty"`
 	}
 
 	changeAddress := testutil.MakeAddress()
@@ -938,6 +939,10 @@ func TestWalletCreateTransaction(t *testing.T) {
 		WalletID: "foo.wlt",
 	}
 
+	account := uint32(1)
+	accountBody := validBody
+	accountBody.Account = &account
+
 	walletInput := testutil.RandSHA256(t)
 
 	type testCase struct {
@@ -1653,6 +1658,25 @@ func TestWalletCreateTransaction(t *testing.T) {
 			csrfDisabled:                   true,
 		},
 
+		{
+			name:                           "200 - bip44 account",
+			method:                         http.MethodPost,
+			body:                           accountBody,
+			status:                         http.StatusOK,
+			gatewayCreateTransactionResult: txn,
+			gatewayCreateTransactionInputs: inputs,
+			createTransactionResponse:      createTxnResponse,
+		},
+
+		{
+			name:                        "404 - bip44 account not found",
+			method:                      http.MethodPost,
+			body:                        accountBody,
+			status:                      http.StatusNotFound,
+			gatewayCreateTransactionErr: wallet.ErrBip44AccountNotFound,
+			err:                         "404 Not Found - bip44 account not found",
+		},
+
 		{
 			name:                        "500 - misc error",
 			method:                      http.MethodPost,
@@ -1769,11 +1793,13 @@ func TestWalletCreateTransaction(t *testing.T) {
 			var body walletCreateTransactionRequest
 			err = json.Unmarshal(serializedBody, &body)
 			if err == nil {
+				wp := body.VisorParams()
+				wp.Account = tc.body.Account
 				if tc.body.Unsigned {
-					x := gateway.On("WalletCreateTransaction", body.WalletID, body.TransactionParams(), body.VisorParams())
+					x := gateway.On("WalletCreateTransaction", body.WalletID, body.TransactionParams(), wp)
 					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
 				} else {
-					x := gateway.On("WalletCreateTransactionSigned", body.WalletID, []byte(body.Password), body.TransactionParams(), body.VisorParams())
+					x := gateway.On("WalletCreateTransactionSigned", body.WalletID, []byte(body.Password), body.TransactionParams(), wp)
 					x.Return(tc.gatewayCreateTransactionResult, tc.gatewayCreateTransactionInputs, tc.gatewayCreateTransactionErr)
 
 				}
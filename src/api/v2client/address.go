@@ -0,0 +1,20 @@
+package v2client
+
+import (
+	"context"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+// VerifyAddress makes a request to POST /api/v2/address/verify.
+// An invalid address is returned as a 422 Unprocessable Entity *Error.
+func (c *Client) VerifyAddress(ctx context.Context, addr string) (*api.VerifyAddressResponse, error) {
+	var rsp api.VerifyAddressResponse
+	if err := c.Post(ctx, "/api/v2/address/verify", api.VerifyAddressRequest{
+		Address: addr,
+	}, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
@@ -0,0 +1,36 @@
+package v2client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func TestVerifyAddress(t *testing.T) {
+	srv := newPostTestServer(t, "/api/v2/address/verify", func(w http.ResponseWriter, r *http.Request) {
+		var req api.VerifyAddressRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if req.Address != "2kvLEyXwAYvHfJuFCkjnYNRTUfHPyWgVwKt" {
+			writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusUnprocessableEntity, "Invalid base58 character"))
+			return
+		}
+
+		writeResponse(t, w, api.HTTPResponse{Data: api.VerifyAddressResponse{Version: 0}})
+	})
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	rsp, err := c.VerifyAddress(context.Background(), "2kvLEyXwAYvHfJuFCkjnYNRTUfHPyWgVwKt")
+	require.NoError(t, err)
+	require.Equal(t, &api.VerifyAddressResponse{Version: 0}, rsp)
+
+	rsp, err = c.VerifyAddress(context.Background(), "0")
+	require.Nil(t, rsp)
+	require.Equal(t, http.StatusUnprocessableEntity, StatusCode(err))
+}
@@ -0,0 +1,416 @@
+/*
+Package v2client implements a client for the v2 REST API of a node.
+
+Every call takes a context.Context, which bounds the call including its retries.
+The client fetches and caches the CSRF token of the node and refreshes it when the node rejects it.
+Requests with an idempotent method are retried with exponential backoff on network errors
+and on 502, 503 and 504 responses, and every request is retried on 429 Too Many Requests responses,
+which are returned before the request is handled. Non-200 responses are returned as *Error.
+*/
+package v2client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+const (
+	dialTimeout         = 30 * time.Second
+	dialKeepAlive       = 30 * time.Second
+	tlsHandshakeTimeout = 10 * time.Second
+	idleConnTimeout     = 90 * time.Second
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 16
+
+	// csrfTokenMaxAge is how long a CSRF token is reused. Tokens expire after api.CSRFMaxAge,
+	// the margin allows for the latency of the requests that use the token
+	csrfTokenMaxAge = api.CSRFMaxAge - 5*time.Second
+
+	// DefaultMaxRetries is the default number of times that a failed request is retried
+	DefaultMaxRetries = 3
+	// DefaultMinBackoff is the default wait before the first retry of a request
+	DefaultMinBackoff = 250 * time.Millisecond
+	// DefaultMaxBackoff is the default maximum wait between the retries of a request,
+	// unless a longer Retry-After is returned by the node
+	DefaultMaxBackoff = 10 * time.Second
+)
+
+// RetryConfig configures the retries of failed requests
+type RetryConfig struct {
+	// MaxRetries is the number of times that a failed request is retried. 0 disables retries
+	MaxRetries int
+	// MinBackoff is the wait before the first retry. The wait doubles for each retry, with jitter
+	MinBackoff time.Duration
+	// MaxBackoff is the maximum wait between retries
+	MaxBackoff time.Duration
+}
+
+// Client provides an interface to the v2 REST API of a remote node.
+// A Client is safe for concurrent use.
+type Client struct {
+	HTTPClient *http.Client
+	Addr       string
+	Username   string
+	Password   string
+	Retry      RetryConfig
+
+	csrfLock      sync.Mutex
+	csrfToken     string
+	csrfFetchedAt time.Time
+}
+
+// NewClient creates a Client. The HTTP client of the Client keeps idle connections to the node
+// open for reuse, and has no timeout; the requests are bounded by their context instead.
+func NewClient(addr string) *Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: dialKeepAlive,
+		}).DialContext,
+		TLSHandshakeTimeout: tlsHandshakeTimeout,
+		IdleConnTimeout:     idleConnTimeout,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: maxIdleConnsPerHost,
+	}
+
+	addr = strings.TrimRight(addr, "/")
+	addr += "/"
+
+	return &Client{
+		HTTPClient: &http.Client{
+			Transport: transport,
+		},
+		Addr: addr,
+		Retry: RetryConfig{
+			MaxRetries: DefaultMaxRetries,
+			MinBackoff: DefaultMinBackoff,
+			MaxBackoff: DefaultMaxBackoff,
+		},
+	}
+}
+
+// SetAuth configures the Client's request authentication, with the web interface username and password
+// or with the id and secret of an API key
+func (c *Client) SetAuth(username, password string) {
+	c.Username = username
+	c.Password = password
+}
+
+// Get makes a GET request to an endpoint and unmarshals the data of the response to respObj
+func (c *Client) Get(ctx context.Context, endpoint string, respObj interface{}) error {
+	return c.Do(ctx, http.MethodGet, endpoint, nil, respObj)
+}
+
+// Post makes a POST request to an endpoint with reqObj as the JSON body,
+// and unmarshals the data of the response to respObj
+func (c *Client) Post(ctx context.Context, endpoint string, reqObj, respObj interface{}) error {
+	return c.Do(ctx, http.MethodPost, endpoint, reqObj, respObj)
+}
+
+// Do makes a request to an endpoint and unmarshals the data of the response to respObj.
+// If reqObj is not nil, it is sent as the JSON body of the request. If respObj is nil, the data is discarded.
+// Non-200 responses are returned as *Error.
+func (c *Client) Do(ctx context.Context, method, endpoint string, reqObj, respObj interface{}) error {
+	var body []byte
+	if reqObj != nil {
+		var err error
+		body, err = json.Marshal(reqObj)
+		if err != nil {
+			return err
+		}
+	}
+
+	csrfRefreshed := false
+	for retries := 0; ; {
+		retry, err := c.do(ctx, method, endpoint, body, respObj)
+		if err == nil {
+			return nil
+		}
+
+		// The node rejected the CSRF token before handling the request, fetch a new token and resend it
+		if isCSRFError(err) && !csrfRefreshed {
+			c.clearCSRF()
+			csrfRefreshed = true
+			continue
+		}
+
+		if !retry || retries >= c.Retry.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		t := time.NewTimer(c.backoff(retries, err))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+
+		retries++
+	}
+}
+
+// do makes a request and returns whether it can be retried if it fails
+func (c *Client) do(ctx context.Context, method, endpoint string, body []byte, respObj interface{}) (bool, error) {
+	var csrf string
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+		var err error
+		csrf, err = c.CSRF(ctx)
+		if err != nil {
+			// The request was not sent, so it can be retried whatever its method
+			return isRetryableCSRFError(err), err
+		}
+	}
+
+	req, err := c.newRequest(ctx, method, endpoint, body)
+	if err != nil {
+		return false, err
+	}
+
+	if csrf != "" {
+		req.Header.Set(api.CSRFHeaderName, csrf)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", api.ContentTypeJSON)
+	}
+	req.Header.Set("Accept", api.ContentTypeJSON)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return isIdempotent(method), err
+	}
+
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return isIdempotent(method), err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		rspErr := newError(resp, respBody)
+		switch rspErr.StatusCode {
+		case http.StatusTooManyRequests:
+			return true, rspErr
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return isIdempotent(method), rspErr
+		default:
+			return false, rspErr
+		}
+	}
+
+	var wrapObj api.ReceivedHTTPResponse
+	if err := json.Unmarshal(respBody, &wrapObj); err != nil {
+		return false, fmt.Errorf("invalid response body: %v", err)
+	}
+
+	if wrapObj.Data == nil || respObj == nil {
+		return false, nil
+	}
+
+	return false, json.Unmarshal(wrapObj.Data, respObj)
+}
+
+func (c *Client) newRequest(ctx context.Context, method, endpoint string, body []byte) (*http.Request, error) {
+	endpoint = c.Addr + strings.TrimLeft(endpoint, "/")
+
+	var req *http.Request
+	var err error
+	if body != nil {
+		req, err = http.NewRequest(method, endpoint, bytes.NewReader(body))
+	} else {
+		req, err = http.NewRequest(method, endpoint, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Username != "" || c.Password != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	return req.WithContext(ctx), nil
+}
+
+// backoff returns the wait before a retry. The wait doubles for each retry up to MaxBackoff,
+// and is randomized so that clients do not retry in lockstep. A longer Retry-After of the response is respected.
+func (c *Client) backoff(retries int, err error) time.Duration {
+	d := c.Retry.MinBackoff
+	for i := 0; i < retries && d < c.Retry.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.Retry.MaxBackoff {
+		d = c.Retry.MaxBackoff
+	}
+	if d > 1 {
+		d = d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+	}
+
+	var rspErr *Error
+	if errors.As(err, &rspErr) && rspErr.RetryAfter > d {
+		d = rspErr.RetryAfter
+	}
+
+	return d
+}
+
+// CSRF returns a CSRF token, reusing the last token until it is about to expire.
+// If CSRF is disabled on the node, returns an empty string and nil error.
+func (c *Client) CSRF(ctx context.Context) (string, error) {
+	c.csrfLock.Lock()
+	defer c.csrfLock.Unlock()
+
+	if !c.csrfFetchedAt.IsZero() && time.Since(c.csrfFetchedAt) < csrfTokenMaxAge {
+		return c.csrfToken, nil
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, "/api/v1/csrf", nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var token string
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var rsp struct {
+			Token string `json:"csrf_token"`
+		}
+		if err := json.Unmarshal(body, &rsp); err != nil {
+			return "", fmt.Errorf("invalid CSRF token response: %v", err)
+		}
+		if rsp.Token == "" {
+			return "", errors.New("csrf_token not found in response")
+		}
+		token = rsp.Token
+	case http.StatusNotFound:
+		// CSRF is disabled on the node
+	default:
+		return "", newError(resp, body)
+	}
+
+	c.csrfToken = token
+	c.csrfFetchedAt = time.Now()
+
+	return token, nil
+}
+
+// clearCSRF discards the cached CSRF token
+func (c *Client) clearCSRF() {
+	c.csrfLock.Lock()
+	defer c.csrfLock.Unlock()
+
+	c.csrfToken = ""
+	c.csrfFetchedAt = time.Time{}
+}
+
+// newError creates an *Error from a non-200 response
+func newError(resp *http.Response, body []byte) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimRight(string(body), "\n"),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+
+	// The v2 API returns errors as JSON, other errors such as the 404 of an unregistered endpoint are plain text
+	var wrapObj api.ReceivedHTTPResponse
+	if err := json.Unmarshal(body, &wrapObj); err == nil && wrapObj.Error != nil {
+		e.Message = wrapObj.Error.Message
+	}
+
+	return e
+}
+
+// parseRetryAfter parses a Retry-After header, in seconds or as an HTTP date
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+
+	if secs, err := strconv.ParseInt(v, 10, 64); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// isIdempotent returns true if a request with the method can be sent more than once
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isCSRFError returns true if the node rejected the CSRF token of a request
+func isCSRFError(err error) bool {
+	var rspErr *Error
+	if !errors.As(err, &rspErr) || rspErr.StatusCode != http.StatusForbidden {
+		return false
+	}
+
+	switch rspErr.Message {
+	case api.ErrCSRFInvalid.Error(), api.ErrCSRFInvalidSignature.Error(), api.ErrCSRFExpired.Error():
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableCSRFError returns true if fetching the CSRF token failed with a network error or a temporary error response
+func isRetryableCSRFError(err error) bool {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+
+	var rspErr *Error
+	if !errors.As(err, &rspErr) {
+		return false
+	}
+
+	switch rspErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
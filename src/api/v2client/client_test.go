@@ -0,0 +1,287 @@
+package v2client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func writeResponse(t *testing.T, w http.ResponseWriter, rsp api.HTTPResponse) {
+	code := http.StatusOK
+	if rsp.Error != nil {
+		code = rsp.Error.Code
+	}
+
+	w.Header().Set("Content-Type", api.ContentTypeJSON)
+	w.WriteHeader(code)
+	require.NoError(t, json.NewEncoder(w).Encode(rsp))
+}
+
+func writeCSRFToken(t *testing.T, w http.ResponseWriter, token string) {
+	w.Header().Set("Content-Type", api.ContentTypeJSON)
+	require.NoError(t, json.NewEncoder(w).Encode(map[string]string{
+		"csrf_token": token,
+	}))
+}
+
+func newTestClient(addr string) *Client {
+	c := NewClient(addr)
+	c.Retry.MinBackoff = time.Millisecond
+	c.Retry.MaxBackoff = 5 * time.Millisecond
+	return c
+}
+
+func TestClientGet(t *testing.T) {
+	type data struct {
+		Foo string `json:"foo"`
+	}
+
+	cases := []struct {
+		name       string
+		maxRetries int
+		responses  []func(w http.ResponseWriter)
+		requests   int
+		data       *data
+		err        error
+	}{
+		{
+			name: "200",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					writeResponse(t, w, api.HTTPResponse{Data: data{Foo: "bar"}})
+				},
+			},
+			requests: 1,
+			data:     &data{Foo: "bar"},
+		},
+
+		{
+			name: "400 is not retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'page' value"))
+				},
+			},
+			requests: 1,
+			err: &Error{
+				StatusCode: http.StatusBadRequest,
+				Message:    "invalid 'page' value",
+			},
+		},
+
+		{
+			name: "404 not in v2 format",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					http.NotFound(w, nil)
+				},
+			},
+			requests: 1,
+			err: &Error{
+				StatusCode: http.StatusNotFound,
+				Message:    "404 page not found",
+			},
+		},
+
+		{
+			name: "503 is retried",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusServiceUnavailable, ""))
+				},
+				func(w http.ResponseWriter) {
+					writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusBadGateway, ""))
+				},
+				func(w http.ResponseWriter) {
+					writeResponse(t, w, api.HTTPResponse{Data: data{Foo: "bar"}})
+				},
+			},
+			maxRetries: 2,
+			requests:   3,
+			data:       &data{Foo: "bar"},
+		},
+
+		{
+			name: "retries exhausted",
+			responses: []func(w http.ResponseWriter){
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "0")
+					writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusTooManyRequests, "Rate limit exceeded"))
+				},
+				func(w http.ResponseWriter) {
+					w.Header().Set("Retry-After", "0")
+					writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusTooManyRequests, "Rate limit exceeded"))
+				},
+			},
+			maxRetries: 1,
+			requests:   2,
+			err: &Error{
+				StatusCode: http.StatusTooManyRequests,
+				Message:    "Rate limit exceeded",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, http.MethodGet, r.Method)
+				require.Equal(t, "/api/v2/foo", r.URL.Path)
+				require.Empty(t, r.Header.Get(api.CSRFHeaderName))
+
+				n := atomic.AddInt32(&requests, 1)
+				require.True(t, int(n) <= len(tc.responses))
+				tc.responses[n-1](w)
+			}))
+			defer srv.Close()
+
+			c := newTestClient(srv.URL)
+			c.Retry.MaxRetries = tc.maxRetries
+
+			var d data
+			err := c.Get(context.Background(), "/api/v2/foo", &d)
+			require.Equal(t, tc.requests, int(atomic.LoadInt32(&requests)))
+
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.data, &d)
+		})
+	}
+}
+
+func TestClientPostCSRF(t *testing.T) {
+	var csrfRequests, postRequests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/csrf":
+			n := atomic.AddInt32(&csrfRequests, 1)
+			if n == 1 {
+				writeCSRFToken(t, w, "expired")
+			} else {
+				writeCSRFToken(t, w, "token")
+			}
+
+		case "/api/v2/foo":
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, api.ContentTypeJSON, r.Header.Get("Content-Type"))
+
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, map[string]string{"foo": "bar"}, req)
+
+			n := atomic.AddInt32(&postRequests, 1)
+			switch r.Header.Get(api.CSRFHeaderName) {
+			case "expired":
+				writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusForbidden, api.ErrCSRFExpired.Error()))
+			case "token":
+				if n == 2 {
+					// Not retried, the POST is not idempotent
+					writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusServiceUnavailable, ""))
+					return
+				}
+				writeResponse(t, w, api.HTTPResponse{Data: "ok"})
+			default:
+				t.Fatalf("unexpected CSRF token %q", r.Header.Get(api.CSRFHeaderName))
+			}
+
+		default:
+			t.Fatalf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+
+	// The expired token is refreshed and the request is sent again, then the 503 is not retried
+	var rsp string
+	err := c.Post(context.Background(), "/api/v2/foo", map[string]string{"foo": "bar"}, &rsp)
+	require.Equal(t, &Error{
+		StatusCode: http.StatusServiceUnavailable,
+		Message:    http.StatusText(http.StatusServiceUnavailable),
+	}, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&csrfRequests))
+	require.Equal(t, int32(2), atomic.LoadInt32(&postRequests))
+
+	// The token is reused
+	err = c.Post(context.Background(), "/api/v2/foo", map[string]string{"foo": "bar"}, &rsp)
+	require.NoError(t, err)
+	require.Equal(t, "ok", rsp)
+	require.Equal(t, int32(2), atomic.LoadInt32(&csrfRequests))
+	require.Equal(t, int32(3), atomic.LoadInt32(&postRequests))
+}
+
+func TestClientCSRFDisabled(t *testing.T) {
+	var csrfRequests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/csrf":
+			atomic.AddInt32(&csrfRequests, 1)
+			http.NotFound(w, r)
+		case "/api/v2/foo":
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Empty(t, r.Header.Get(api.CSRFHeaderName))
+			writeResponse(t, w, api.HTTPResponse{})
+		}
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	require.NoError(t, c.Post(context.Background(), "/api/v2/foo", nil, nil))
+	require.NoError(t, c.Post(context.Background(), "/api/v2/foo", nil, nil))
+	require.Equal(t, int32(1), atomic.LoadInt32(&csrfRequests))
+}
+
+func TestClientContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusTooManyRequests, "Rate limit exceeded"))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+
+	// The wait for the Retry-After is interrupted by the context
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := c.Get(ctx, "/api/v2/foo", nil)
+	require.Equal(t, &Error{
+		StatusCode: http.StatusTooManyRequests,
+		Message:    "Rate limit exceeded",
+		RetryAfter: time.Minute,
+	}, err)
+	require.True(t, IsTooManyRequests(err))
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = c.Get(ctx, "/api/v2/foo", nil)
+	require.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestStatusCode(t *testing.T) {
+	err := &Error{
+		StatusCode: http.StatusNotFound,
+		Message:    "wallet doesn't exist",
+	}
+	require.Equal(t, "404 Not Found - wallet doesn't exist", err.Error())
+	require.Equal(t, http.StatusNotFound, StatusCode(err))
+	require.True(t, IsNotFound(err))
+	require.False(t, IsBadRequest(err))
+	require.Equal(t, 0, StatusCode(errors.New("foo")))
+
+	require.True(t, IsUnauthorized(&Error{StatusCode: http.StatusForbidden}))
+}
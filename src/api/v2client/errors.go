@@ -0,0 +1,57 @@
+package v2client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Error is a non-200 response of the API
+type Error struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+	// Message is the error message of the response. If the response is not a v2 JSON response,
+	// such as a 404 of an unregistered endpoint, Message is the response body
+	Message string
+	// RetryAfter is the Retry-After of a 429 Too Many Requests or 503 Service Unavailable response
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s - %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// StatusCode returns the HTTP status code of an *Error, or 0 if err is not an *Error
+func StatusCode(err error) int {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.StatusCode
+	}
+	return 0
+}
+
+// IsNotFound returns true if err is a 404 Not Found response
+func IsNotFound(err error) bool {
+	return StatusCode(err) == http.StatusNotFound
+}
+
+// IsBadRequest returns true if err is a 400 Bad Request response, which is returned for invalid request params
+func IsBadRequest(err error) bool {
+	return StatusCode(err) == http.StatusBadRequest
+}
+
+// IsUnauthorized returns true if err is a 401 Unauthorized or 403 Forbidden response
+func IsUnauthorized(err error) bool {
+	switch StatusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTooManyRequests returns true if err is a 429 Too Many Requests response
+func IsTooManyRequests(err error) bool {
+	return StatusCode(err) == http.StatusTooManyRequests
+}
@@ -0,0 +1,174 @@
+package v2client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/readable"
+)
+
+// ErrStop can be returned by the callback of ForEachTransaction and ForEachTransactionVerbose
+// to stop the iteration without an error
+var ErrStop = errors.New("stop iteration")
+
+const (
+	// SortAsc sorts transactions by block seq, oldest first
+	SortAsc = "asc"
+	// SortDesc sorts transactions by block seq, newest first
+	SortDesc = "desc"
+)
+
+// TransactionsParams are the filters of GET /api/v2/transactions
+type TransactionsParams struct {
+	// Addrs filters the transactions that have an input or output of the addresses. All transactions if empty
+	Addrs []string
+	// Confirmed filters confirmed or unconfirmed transactions. Both if nil
+	Confirmed *bool
+	// Sort is SortAsc or SortDesc. Defaults to SortAsc
+	Sort string
+	// Limit is the number of transactions per page. Defaults to the node's default page size
+	Limit uint64
+}
+
+// TransactionsPage is a page of GET /api/v2/transactions
+type TransactionsPage struct {
+	PageInfo readable.PageInfo                `json:"page_info"`
+	Txns     []readable.TransactionWithStatus `json:"txns"`
+}
+
+// TransactionsVerbosePage is a page of GET /api/v2/transactions?verbose=1
+type TransactionsVerbosePage struct {
+	PageInfo readable.PageInfo                       `json:"page_info"`
+	Txns     []readable.TransactionWithStatusVerbose `json:"txns"`
+}
+
+// Transactions makes a request to GET /api/v2/transactions for a page of transactions. Pages start at 1.
+func (c *Client) Transactions(ctx context.Context, params TransactionsParams, page uint64) (*TransactionsPage, error) {
+	var rsp TransactionsPage
+	if err := c.Get(ctx, transactionsEndpoint(params, page, false), &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
+// TransactionsVerbose makes a request to GET /api/v2/transactions?verbose=1 for a page of transactions. Pages start at 1.
+func (c *Client) TransactionsVerbose(ctx context.Context, params TransactionsParams, page uint64) (*TransactionsVerbosePage, error) {
+	var rsp TransactionsVerbosePage
+	if err := c.Get(ctx, transactionsEndpoint(params, page, true), &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
+// ForEachTransaction requests the pages of GET /api/v2/transactions in order, and calls f for each transaction.
+// Only one page is held in memory. The iteration stops at the first error of f, which is returned,
+// unless it is ErrStop. Transactions that are added while iterating with SortDesc shift the pages,
+// so a transaction can be passed to f twice.
+func (c *Client) ForEachTransaction(ctx context.Context, params TransactionsParams, f func(*readable.TransactionWithStatus) error) error {
+	return forEachPage(func(page uint64) (uint64, error) {
+		rsp, err := c.Transactions(ctx, params, page)
+		if err != nil {
+			return 0, err
+		}
+
+		for i := range rsp.Txns {
+			if err := f(&rsp.Txns[i]); err != nil {
+				return 0, err
+			}
+		}
+
+		return rsp.PageInfo.TotalPages, nil
+	})
+}
+
+// ForEachTransactionVerbose is ForEachTransaction for GET /api/v2/transactions?verbose=1
+func (c *Client) ForEachTransactionVerbose(ctx context.Context, params TransactionsParams, f func(*readable.TransactionWithStatusVerbose) error) error {
+	return forEachPage(func(page uint64) (uint64, error) {
+		rsp, err := c.TransactionsVerbose(ctx, params, page)
+		if err != nil {
+			return 0, err
+		}
+
+		for i := range rsp.Txns {
+			if err := f(&rsp.Txns[i]); err != nil {
+				return 0, err
+			}
+		}
+
+		return rsp.PageInfo.TotalPages, nil
+	})
+}
+
+// CreateTransaction makes a request to POST /api/v2/transaction to create an unsigned transaction
+func (c *Client) CreateTransaction(ctx context.Context, req api.CreateTransactionRequest) (*api.CreateTransactionResponse, error) {
+	var rsp api.CreateTransactionResponse
+	if err := c.Post(ctx, "/api/v2/transaction", req, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
+// VerifyTransaction makes a request to POST /api/v2/transaction/verify.
+// An invalid transaction is returned as a 422 Unprocessable Entity *Error.
+func (c *Client) VerifyTransaction(ctx context.Context, req api.VerifyTransactionRequest) (*api.VerifyTransactionResponse, error) {
+	var rsp api.VerifyTransactionResponse
+	if err := c.Post(ctx, "/api/v2/transaction/verify", req, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
+// forEachPage calls getPage for each page, until the last page reported by getPage
+func forEachPage(getPage func(page uint64) (uint64, error)) error {
+	for page := uint64(1); ; page++ {
+		totalPages, err := getPage(page)
+		switch err {
+		case nil:
+		case ErrStop:
+			return nil
+		default:
+			return err
+		}
+
+		if page >= totalPages {
+			return nil
+		}
+	}
+}
+
+func transactionsEndpoint(params TransactionsParams, page uint64, verbose bool) string {
+	v := url.Values{}
+	if len(params.Addrs) > 0 {
+		v.Add("addrs", strings.Join(params.Addrs, ","))
+	}
+	if params.Confirmed != nil {
+		v.Add("confirmed", fmt.Sprint(*params.Confirmed))
+	}
+	if params.Sort != "" {
+		v.Add("sort", params.Sort)
+	}
+	if params.Limit != 0 {
+		v.Add("limit", fmt.Sprint(params.Limit))
+	}
+	if page != 0 {
+		v.Add("page", fmt.Sprint(page))
+	}
+	if verbose {
+		v.Add("verbose", "1")
+	}
+
+	endpoint := "/api/v2/transactions"
+	if len(v) > 0 {
+		endpoint += "?" + v.Encode()
+	}
+
+	return endpoint
+}
@@ -0,0 +1,146 @@
+package v2client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/readable"
+)
+
+func TestTransactionsEndpoint(t *testing.T) {
+	confirmed := true
+	require.Equal(t, "/api/v2/transactions", transactionsEndpoint(TransactionsParams{}, 0, false))
+	require.Equal(t, "/api/v2/transactions?addrs=a%2Cb&confirmed=true&limit=50&page=2&sort=desc&verbose=1", transactionsEndpoint(TransactionsParams{
+		Addrs:     []string{"a", "b"},
+		Confirmed: &confirmed,
+		Sort:      SortDesc,
+		Limit:     50,
+	}, 2, true))
+}
+
+func TestForEachTransaction(t *testing.T) {
+	// 5 transactions in pages of 2
+	txids := []string{"a", "b", "c", "d", "e"}
+	pageSize := 2
+	totalPages := uint64(3)
+
+	var pages []uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v2/transactions", r.URL.Path)
+		require.Equal(t, "2kvLEyXwAYvHfJuFCkjnYNRTUfHPyWgVwKt", r.FormValue("addrs"))
+		require.Equal(t, strconv.Itoa(pageSize), r.FormValue("limit"))
+
+		page, err := strconv.ParseUint(r.FormValue("page"), 10, 64)
+		require.NoError(t, err)
+		pages = append(pages, page)
+
+		start := int(page-1) * pageSize
+		end := start + pageSize
+		if end > len(txids) {
+			end = len(txids)
+		}
+
+		rsp := TransactionsPage{
+			PageInfo: readable.PageInfo{
+				TotalPages:  totalPages,
+				PageSize:    uint64(pageSize),
+				CurrentPage: page,
+			},
+			Txns: []readable.TransactionWithStatus{},
+		}
+		for _, txid := range txids[start:end] {
+			rsp.Txns = append(rsp.Txns, readable.TransactionWithStatus{
+				Transaction: readable.Transaction{
+					Hash: txid,
+				},
+			})
+		}
+
+		writeResponse(t, w, api.HTTPResponse{Data: rsp})
+	}))
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	params := TransactionsParams{
+		Addrs: []string{"2kvLEyXwAYvHfJuFCkjnYNRTUfHPyWgVwKt"},
+		Limit: uint64(pageSize),
+	}
+
+	var got []string
+	err := c.ForEachTransaction(context.Background(), params, func(txn *readable.TransactionWithStatus) error {
+		got = append(got, txn.Transaction.Hash)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, txids, got)
+	require.Equal(t, []uint64{1, 2, 3}, pages)
+
+	// ErrStop stops the iteration without an error
+	got = nil
+	pages = nil
+	err = c.ForEachTransaction(context.Background(), params, func(txn *readable.TransactionWithStatus) error {
+		got = append(got, txn.Transaction.Hash)
+		if len(got) == 3 {
+			return ErrStop
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, txids[:3], got)
+	require.Equal(t, []uint64{1, 2}, pages)
+
+	// Other errors are returned
+	fErr := errors.New("f failed")
+	err = c.ForEachTransaction(context.Background(), params, func(txn *readable.TransactionWithStatus) error {
+		return fErr
+	})
+	require.Equal(t, fErr, err)
+
+	// No transactions
+	txids = nil
+	totalPages = 0
+	pages = nil
+	err = c.ForEachTransaction(context.Background(), params, func(txn *readable.TransactionWithStatus) error {
+		t.Fatal("unexpected transaction")
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{1}, pages)
+}
+
+func TestVerifyTransaction(t *testing.T) {
+	srv := newPostTestServer(t, "/api/v2/transaction/verify", func(w http.ResponseWriter, r *http.Request) {
+		var req api.VerifyTransactionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, api.VerifyTransactionRequest{
+			Unsigned:           true,
+			EncodedTransaction: "dc00",
+		}, req)
+
+		writeResponse(t, w, api.HTTPResponse{Data: api.VerifyTransactionResponse{
+			Unsigned: true,
+			Transaction: api.CreatedTransaction{
+				TxID: "a",
+			},
+		}})
+	})
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	rsp, err := c.VerifyTransaction(context.Background(), api.VerifyTransactionRequest{
+		Unsigned:           true,
+		EncodedTransaction: "dc00",
+	})
+	require.NoError(t, err)
+	require.True(t, rsp.Unsigned)
+	require.False(t, rsp.Confirmed)
+	require.Equal(t, "a", rsp.Transaction.TxID)
+}
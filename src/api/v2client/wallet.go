@@ -0,0 +1,66 @@
+package v2client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+// RecoverWallet makes a request to POST /api/v2/wallet/recover to recover a wallet by seed
+func (c *Client) RecoverWallet(ctx context.Context, req api.WalletRecoverRequest) (*api.WalletResponse, error) {
+	var rsp api.WalletResponse
+	if err := c.Post(ctx, "/api/v2/wallet/recover", req, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
+// WalletSignTransaction makes a request to POST /api/v2/wallet/transaction/sign
+func (c *Client) WalletSignTransaction(ctx context.Context, req api.WalletSignTransactionRequest) (*api.CreateTransactionResponse, error) {
+	var rsp api.CreateTransactionResponse
+	if err := c.Post(ctx, "/api/v2/wallet/transaction/sign", req, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
+// VerifySeed makes a request to POST /api/v2/wallet/seed/verify.
+// An invalid seed is returned as a 422 Unprocessable Entity *Error.
+func (c *Client) VerifySeed(ctx context.Context, seed string) error {
+	return c.Post(ctx, "/api/v2/wallet/seed/verify", api.VerifySeedRequest{
+		Seed: seed,
+	}, nil)
+}
+
+// WalletSeedShares makes a request to POST /api/v2/wallet/seed/shares
+func (c *Client) WalletSeedShares(ctx context.Context, req api.WalletSeedSharesRequest) (*api.WalletSeedSharesResponse, error) {
+	var rsp api.WalletSeedSharesResponse
+	if err := c.Post(ctx, "/api/v2/wallet/seed/shares", req, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
+
+// WalletExport makes a request to POST /api/v2/wallet/export and returns the encrypted wallet archive
+func (c *Client) WalletExport(ctx context.Context, req api.WalletExportRequest) ([]byte, error) {
+	var archive json.RawMessage
+	if err := c.Post(ctx, "/api/v2/wallet/export", req, &archive); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
+
+// WalletImport makes a request to POST /api/v2/wallet/import
+func (c *Client) WalletImport(ctx context.Context, req api.WalletImportRequest) (*api.WalletImportResponse, error) {
+	var rsp api.WalletImportResponse
+	if err := c.Post(ctx, "/api/v2/wallet/import", req, &rsp); err != nil {
+		return nil, err
+	}
+
+	return &rsp, nil
+}
@@ -0,0 +1,86 @@
+package v2client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/readable"
+)
+
+// newPostTestServer serves the CSRF token and calls handle for POST requests to the endpoint
+func newPostTestServer(t *testing.T, endpoint string, handle func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/csrf":
+			writeCSRFToken(t, w, "token")
+		case endpoint:
+			require.Equal(t, http.MethodPost, r.Method)
+			require.Equal(t, "token", r.Header.Get(api.CSRFHeaderName))
+			require.Equal(t, api.ContentTypeJSON, r.Header.Get("Content-Type"))
+			handle(w, r)
+		default:
+			t.Fatalf("unexpected request to %s", r.URL.Path)
+		}
+	}))
+}
+
+func TestRecoverWallet(t *testing.T) {
+	srv := newPostTestServer(t, "/api/v2/wallet/recover", func(w http.ResponseWriter, r *http.Request) {
+		var req api.WalletRecoverRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, api.WalletRecoverRequest{
+			ID:       "foo.wlt",
+			Seed:     "seed",
+			Password: "pwd",
+		}, req)
+
+		writeResponse(t, w, api.HTTPResponse{Data: api.WalletResponse{
+			Meta: readable.WalletMeta{
+				Filename:  "foo.wlt",
+				Encrypted: true,
+			},
+			Entries: []readable.WalletEntry{},
+		}})
+	})
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	rsp, err := c.RecoverWallet(context.Background(), api.WalletRecoverRequest{
+		ID:       "foo.wlt",
+		Seed:     "seed",
+		Password: "pwd",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "foo.wlt", rsp.Meta.Filename)
+	require.True(t, rsp.Meta.Encrypted)
+}
+
+func TestVerifySeed(t *testing.T) {
+	srv := newPostTestServer(t, "/api/v2/wallet/seed/verify", func(w http.ResponseWriter, r *http.Request) {
+		var req api.VerifySeedRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		if req.Seed != "valid" {
+			writeResponse(t, w, api.NewHTTPErrorResponse(http.StatusUnprocessableEntity, "Mnemonic must have 12, 15, 18, 21 or 24 words"))
+			return
+		}
+
+		writeResponse(t, w, api.HTTPResponse{Data: struct{}{}})
+	})
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	require.NoError(t, c.VerifySeed(context.Background(), "valid"))
+
+	err := c.VerifySeed(context.Background(), "invalid")
+	require.Equal(t, &Error{
+		StatusCode: http.StatusUnprocessableEntity,
+		Message:    "Mnemonic must have 12, 15, 18, 21 or 24 words",
+	}, err)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/bip39"
 	"github.com/skycoin/skycoin/src/cipher/bip44"
+	"github.com/skycoin/skycoin/src/cipher/shamir"
 	"github.com/skycoin/skycoin/src/readable"
 	wh "github.com/skycoin/skycoin/src/util/http"
 	"github.com/skycoin/skycoin/src/wallet"
@@ -76,30 +77,40 @@ func NewWalletResponse(w wallet.Wallet) (*WalletResponse, error) {
 	wr.Entries = make([]readable.WalletEntry, len(entries))
 
 	for i, e := range entries {
-		wr.Entries[i] = readable.WalletEntry{
-			Address: e.Address.String(),
-			Public:  e.Public.Hex(),
-		}
-
-		switch w.Type() {
-		// Copy these values to another ref to avoid having a pointer
-		// to an element of Entry which could affect GC of the Entry,
-		// which could cause retention/copying of secret data in the Entry.
-		// This is speculative. I don't know if this matters to the go runtime
-		case wallet.WalletTypeBip44:
-			childNumber := e.ChildNumber
-			wr.Entries[i].ChildNumber = &childNumber
-			change := e.Change
-			wr.Entries[i].Change = &change
-		case wallet.WalletTypeXPub:
-			childNumber := e.ChildNumber
-			wr.Entries[i].ChildNumber = &childNumber
-		}
+		wr.Entries[i] = newWalletEntry(w.Type(), e)
 	}
 
 	return &wr, nil
 }
 
+// newWalletEntry creates a readable.WalletEntry from a wallet.Entry of a wallet of given type
+func newWalletEntry(walletType string, e wallet.Entry) readable.WalletEntry {
+	re := readable.WalletEntry{
+		Address: e.Address.String(),
+		Public:  e.Public.Hex(),
+		Label:   e.Label,
+		Tags:    e.Tags,
+		Note:    e.Note,
+	}
+
+	switch walletType {
+	// Copy these values to another ref to avoid having a pointer
+	// to an element of Entry which could affect GC of the Entry,
+	// which could cause retention/copying of secret data in the Entry.
+	// This is speculative. I don't know if this matters to the go runtime
+	case wallet.WalletTypeBip44:
+		childNumber := e.ChildNumber
+		re.ChildNumber = &childNumber
+		change := e.Change
+		re.Change = &change
+	case wallet.WalletTypeXPub:
+		childNumber := e.ChildNumber
+		re.ChildNumber = &childNumber
+	}
+
+	return re
+}
+
 // Returns the wallet's balance, both confirmed and predicted.  The predicted
 // balance is the confirmed balance minus the pending spends.
 // URI: /api/v1/wallet/balance
@@ -171,33 +182,42 @@ func balanceHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
-		// create map of address to balance
-		addressBalances := make(readable.AddressBalances, len(addrs))
-		for idx, addr := range addrs {
-			addressBalances[addr.String()] = readable.NewBalancePair(bals[idx])
+		resp, err := newAddressesBalanceResponse(addrs, bals)
+		if err != nil {
+			wh.Error500(w, err.Error())
+			return
 		}
 
-		var balance wallet.BalancePair
-		for _, bal := range bals {
-			var err error
-			balance.Confirmed, err = balance.Confirmed.Add(bal.Confirmed)
-			if err != nil {
-				wh.Error500(w, err.Error())
-				return
-			}
+		wh.SendJSONOr500(logger, w, resp)
+	}
+}
 
-			balance.Predicted, err = balance.Predicted.Add(bal.Predicted)
-			if err != nil {
-				wh.Error500(w, err.Error())
-				return
-			}
+// newAddressesBalanceResponse creates the BalanceResponse of addresses from their balances
+func newAddressesBalanceResponse(addrs []cipher.Address, bals []wallet.BalancePair) (*BalanceResponse, error) {
+	// create map of address to balance
+	addressBalances := make(readable.AddressBalances, len(addrs))
+	for idx, addr := range addrs {
+		addressBalances[addr.String()] = readable.NewBalancePair(bals[idx])
+	}
+
+	var balance wallet.BalancePair
+	for _, bal := range bals {
+		var err error
+		balance.Confirmed, err = balance.Confirmed.Add(bal.Confirmed)
+		if err != nil {
+			return nil, err
 		}
 
-		wh.SendJSONOr500(logger, w, BalanceResponse{
-			BalancePair: readable.NewBalancePair(balance),
-			Addresses:   addressBalances,
-		})
+		balance.Predicted, err = balance.Predicted.Add(bal.Predicted)
+		if err != nil {
+			return nil, err
+		}
 	}
+
+	return &BalanceResponse{
+		BalancePair: readable.NewBalancePair(balance),
+		Addresses:   addressBalances,
+	}, nil
 }
 
 // Loads wallet from seed, will scan ahead N address and
@@ -448,7 +468,7 @@ func walletCreateTempHandler(gateway Gatewayer) http.HandlerFunc {
 // Args:
 //     id: wallet id [required]
 //     num: number of address need to create [optional, if not set the default value is 1]
-//     password: wallet password [optional, must be provided if the wallet is encrypted]
+//     password: wallet password or unlock session token [optional, must be provided if the wallet is encrypted]
 func walletNewAddressesHandler(gateway Gatewayer) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -915,6 +935,104 @@ func walletSeedHandler(gateway Gatewayer) http.HandlerFunc {
 	}
 }
 
+// WalletSeedSharesRequest is the request data for POST /api/v2/wallet/seed/shares
+type WalletSeedSharesRequest struct {
+	ID        string `json:"id"`
+	Password  string `json:"password"`
+	Shares    int    `json:"shares"`
+	Threshold int    `json:"threshold"`
+}
+
+// WalletSeedSharesResponse is the response data for POST /api/v2/wallet/seed/shares
+type WalletSeedSharesResponse struct {
+	Shares         []string `json:"shares"`
+	Threshold      int      `json:"threshold"`
+	SeedPassphrase string   `json:"seed_passphrase,omitempty"`
+}
+
+// Splits the seed of a wallet into Shamir secret shares, any threshold of which
+// can recover the wallet with /api/v2/wallet/recover.
+// The seed passphrase is not split, it is returned as is.
+// URI: /api/v2/wallet/seed/shares
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     password: wallet password [required]
+//     shares: the number of shares [required, must be <= 255]
+//     threshold: the number of shares required to recover the seed [required, must be >= 2 and <= shares]
+func walletSeedSharesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletSeedSharesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		defer func() {
+			req.Password = ""
+		}()
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Shares < shamir.MinThreshold || req.Shares > shamir.MaxShares {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("shares must be between %d and %d", shamir.MinThreshold, shamir.MaxShares))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Threshold < shamir.MinThreshold || req.Threshold > req.Shares {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("threshold must be between %d and shares", shamir.MinThreshold))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		seed, seedPassphrase, err := gateway.GetWalletSeed(req.ID, []byte(req.Password))
+		if err != nil {
+			var resp HTTPResponse
+			switch err {
+			case wallet.ErrMissingPassword,
+				wallet.ErrWalletNotEncrypted,
+				wallet.ErrInvalidPassword:
+				resp = NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			case wallet.ErrWalletAPIDisabled, wallet.ErrSeedAPIDisabled:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+			case wallet.ErrWalletNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		shares, err := wallet.SplitSeed(seed, req.Shares, req.Threshold)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletSeedSharesResponse{
+				Shares:         shares,
+				Threshold:      req.Threshold,
+				SeedPassphrase: seedPassphrase,
+			},
+		})
+	}
+}
+
 // VerifySeedRequest is the request data for POST /api/v2/wallet/seed/verify
 type VerifySeedRequest struct {
 	Seed string `json:"seed"`
@@ -1085,10 +1203,11 @@ func walletDecryptHandler(gateway Gatewayer) http.HandlerFunc {
 
 // WalletRecoverRequest is the request data for POST /api/v2/wallet/recover
 type WalletRecoverRequest struct {
-	ID             string `json:"id"`
-	Seed           string `json:"seed"`
-	SeedPassphrase string `json:"seed_passphrase"`
-	Password       string `json:"password"`
+	ID             string   `json:"id"`
+	Seed           string   `json:"seed"`
+	SeedShares     []string `json:"seed_shares,omitempty"`
+	SeedPassphrase string   `json:"seed_passphrase"`
+	Password       string   `json:"password"`
 }
 
 // URI: /api/v2/wallet/recover
@@ -1096,8 +1215,10 @@ type WalletRecoverRequest struct {
 // Args:
 //  id: wallet id
 //  seed: wallet seed
+//  seed_shares: [optional] Shamir secret shares of the seed, instead of the seed
 //  password: [optional] new password
-// Recovers an encrypted wallet by providing the seed.
+// Recovers an encrypted wallet by providing the seed, or enough shares of the seed
+// created by /api/v2/wallet/seed/shares.
 // The first address will be generated from seed and compared to the first address
 // of the specified wallet. If they match, the wallet will be regenerated
 // with an optional password.
@@ -1123,6 +1244,22 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 			return
 		}
 
+		if req.Seed != "" && len(req.SeedShares) != 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "seed and seed_shares cannot be combined")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.SeedShares) != 0 {
+			seed, err := wallet.CombineSeedShares(req.SeedShares)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid seed_shares: %v", err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+			req.Seed = seed
+		}
+
 		if req.Seed == "" {
 			resp := NewHTTPErrorResponse(http.StatusBadRequest, "seed is required")
 			writeHTTPResponse(w, resp)
@@ -1136,6 +1273,7 @@ func walletRecoverHandler(gateway Gatewayer) http.HandlerFunc {
 
 		defer func() {
 			req.Seed = ""
+			req.SeedShares = nil
 			req.SeedPassphrase = ""
 			req.Password = ""
 			password = nil
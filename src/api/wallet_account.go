@@ -0,0 +1,397 @@
+package api
+
+// APIs for bip44 wallet accounts
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// WalletAccount is a bip44 account of a wallet, with the addresses of its external and change chains
+type WalletAccount struct {
+	Index           uint32   `json:"index"`
+	Name            string   `json:"name"`
+	Addresses       []string `json:"addresses"`
+	ChangeAddresses []string `json:"change_addresses"`
+}
+
+// newWalletAccount creates a WalletAccount of the account of given index of a bip44 wallet
+func newWalletAccount(w wallet.Wallet, account wallet.Bip44Account) (*WalletAccount, error) {
+	getAddresses := func(options ...wallet.Option) ([]string, error) {
+		addrs, err := w.GetAddresses(append(options, wallet.OptionAccount(account.Index))...)
+		if err != nil {
+			return nil, err
+		}
+
+		ss := make([]string, len(addrs))
+		for i, a := range addrs {
+			ss[i] = a.String()
+		}
+		return ss, nil
+	}
+
+	addrs, err := getAddresses(wallet.OptionExternal())
+	if err != nil {
+		return nil, err
+	}
+
+	changeAddrs, err := getAddresses(wallet.OptionChange())
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletAccount{
+		Index:           account.Index,
+		Name:            account.Name,
+		Addresses:       addrs,
+		ChangeAddresses: changeAddrs,
+	}, nil
+}
+
+// newWalletAccounts creates the WalletAccounts of all accounts of a bip44 wallet
+func newWalletAccounts(w wallet.Wallet) ([]WalletAccount, error) {
+	if w.Type() != wallet.WalletTypeBip44 {
+		return nil, wallet.ErrWalletNotBip44
+	}
+
+	accounts := w.Accounts()
+	was := make([]WalletAccount, len(accounts))
+	for i, a := range accounts {
+		wa, err := newWalletAccount(w, a)
+		if err != nil {
+			return nil, err
+		}
+		was[i] = *wa
+	}
+
+	return was, nil
+}
+
+// walletAccountErrorResponse maps errors of the wallet account endpoints to a HTTPResponse
+func walletAccountErrorResponse(err error) HTTPResponse {
+	switch err {
+	case wallet.ErrWalletNotExist:
+		return NewHTTPErrorResponse(http.StatusNotFound, "")
+	case wallet.ErrBip44AccountNotFound:
+		return NewHTTPErrorResponse(http.StatusNotFound, err.Error())
+	case wallet.ErrWalletAPIDisabled:
+		return NewHTTPErrorResponse(http.StatusForbidden, "")
+	}
+
+	switch err.(type) {
+	case wallet.Error:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// parseAccount parses the bip44 account index
+func parseAccount(s string) (uint32, error) {
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(n), nil
+}
+
+// Dispatches /wallet/accounts endpoint.
+// Method: GET, POST
+// URI: /api/v2/wallet/accounts
+func walletAccountsHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			listWalletAccountsHandler(w, r, gateway)
+		case http.MethodPost:
+			createWalletAccountHandler(w, r, gateway)
+		default:
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+		}
+	}
+}
+
+// Returns the accounts of a bip44 wallet
+// Args:
+//     id: wallet id [required]
+func listWalletAccountsHandler(w http.ResponseWriter, r *http.Request, gateway Gatewayer) {
+	wltID := r.FormValue("id")
+	if wltID == "" {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	wlt, err := gateway.GetWallet(wltID)
+	if err != nil {
+		writeHTTPResponse(w, walletAccountErrorResponse(err))
+		return
+	}
+
+	accounts, err := newWalletAccounts(wlt)
+	if err != nil {
+		writeHTTPResponse(w, walletAccountErrorResponse(err))
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: accounts,
+	})
+}
+
+// WalletAccountCreateRequest is the request data for POST /api/v2/wallet/accounts
+type WalletAccountCreateRequest struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// Creates an account in a bip44 wallet.
+// The first external and change addresses of the account are generated.
+// Args:
+//     id: wallet id [required]
+//     name: account name [required]
+//     password: wallet password [optional, must be provided if the wallet is encrypted]
+func createWalletAccountHandler(w http.ResponseWriter, r *http.Request, gateway Gatewayer) {
+	var req WalletAccountCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	if req.ID == "" {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	if req.Name == "" {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, "name is required")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	var password []byte
+	if req.Password != "" {
+		password = []byte(req.Password)
+	}
+
+	defer func() {
+		req.Password = ""
+		password = nil
+	}()
+
+	account, err := gateway.CreateAccount(req.ID, password, req.Name)
+	if err != nil {
+		writeHTTPResponse(w, walletAccountErrorResponse(err))
+		return
+	}
+
+	wlt, err := gateway.GetWallet(req.ID)
+	if err != nil {
+		writeHTTPResponse(w, walletAccountErrorResponse(err))
+		return
+	}
+
+	wa, err := newWalletAccount(wlt, account)
+	if err != nil {
+		resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: wa,
+	})
+}
+
+// WalletAccountUpdateRequest is the request data for POST /api/v2/wallet/accounts/update
+type WalletAccountUpdateRequest struct {
+	ID      string `json:"id"`
+	Account uint32 `json:"account"`
+	Name    string `json:"name"`
+}
+
+// Renames an account of a bip44 wallet
+// URI: /api/v2/wallet/accounts/update
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     account: account index [required]
+//     name: the name the account will be updated to [required]
+func walletAccountUpdateHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletAccountUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Name == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "name is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := gateway.UpdateAccountName(req.ID, req.Account, req.Name); err != nil {
+			writeHTTPResponse(w, walletAccountErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{})
+	}
+}
+
+// Returns the balance of an account of a bip44 wallet, both confirmed and predicted.
+// The predicted balance is the confirmed balance minus the pending spends.
+// URI: /api/v2/wallet/accounts/balance
+// Method: GET
+// Args:
+//     id: wallet id [required]
+//     account: account index [required]
+func walletAccountBalanceHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		accountStr := r.FormValue("account")
+		if accountStr == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "account is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		account, err := parseAccount(accountStr)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "invalid account value")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		walletBalance, addressBalances, err := gateway.GetWalletAccountBalance(wltID, account)
+		if err != nil {
+			logger.Errorf("Get wallet account balance failed: id: %v, account: %v, err: %v", wltID, account, err)
+			writeHTTPResponse(w, walletAccountErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: BalanceResponse{
+				BalancePair: readable.NewBalancePair(walletBalance),
+				Addresses:   readable.NewAddressBalances(addressBalances),
+			},
+		})
+	}
+}
+
+// WalletAccountNewAddressesRequest is the request data for POST /api/v2/wallet/accounts/addresses
+type WalletAccountNewAddressesRequest struct {
+	ID      string `json:"id"`
+	Account uint32 `json:"account"`
+	Num     uint64 `json:"num"`
+	Change  bool   `json:"change"`
+}
+
+// Generates addresses on the external or change chain of an account of a bip44 wallet.
+// The wallet does not need to be unlocked to generate bip44 addresses.
+// URI: /api/v2/wallet/accounts/addresses
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     account: account index [required]
+//     num: number of addresses to generate [optional, defaults to 1]
+//     change: generate addresses on the change chain instead of the external chain [optional]
+func walletAccountNewAddressesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletAccountNewAddressesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Num == 0 {
+			req.Num = 1
+		}
+
+		wlt, err := gateway.GetWallet(req.ID)
+		if err != nil {
+			writeHTTPResponse(w, walletAccountErrorResponse(err))
+			return
+		}
+
+		// Other wallet types would ignore the account option
+		if err := wallet.ValidateBip44Account(wlt, req.Account); err != nil {
+			writeHTTPResponse(w, walletAccountErrorResponse(err))
+			return
+		}
+
+		opts := []wallet.Option{
+			wallet.OptionAccount(req.Account),
+			wallet.OptionGenerateN(req.Num),
+		}
+		if req.Change {
+			opts = append(opts, wallet.OptionChange())
+		}
+
+		addrs, err := gateway.NewAddresses(req.ID, nil, opts...)
+		if err != nil {
+			writeHTTPResponse(w, walletAccountErrorResponse(err))
+			return
+		}
+
+		rlt := struct {
+			Addresses []string `json:"addresses"`
+		}{
+			Addresses: make([]string, len(addrs)),
+		}
+		for i, a := range addrs {
+			rlt.Addresses[i] = a.String()
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: rlt,
+		})
+	}
+}
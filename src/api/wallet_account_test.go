@@ -0,0 +1,553 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/wallet"
+	_ "github.com/skycoin/skycoin/src/wallet/bip44wallet"
+)
+
+func newTestBip44AccountWallet(t *testing.T) wallet.Wallet {
+	w, err := wallet.NewWallet(
+		"bip44.wlt",
+		"label",
+		"voyage say extend find sheriff surge priority merit ignore maple cash argue",
+		wallet.Options{
+			Type:      wallet.WalletTypeBip44,
+			Coin:      wallet.CoinTypeSkycoin,
+			GenerateN: 2,
+		})
+	require.NoError(t, err)
+
+	am, ok := w.(wallet.Bip44AccountManager)
+	require.True(t, ok)
+	_, err = am.NewAccount("customer")
+	require.NoError(t, err)
+	_, err = w.GenerateAddresses(wallet.OptionGenerateN(1), wallet.OptionAccount(1))
+	require.NoError(t, err)
+
+	return w
+}
+
+func addressStrings(t *testing.T, w wallet.Wallet, options ...wallet.Option) []string {
+	addrs, err := w.GetAddresses(options...)
+	require.NoError(t, err)
+
+	ss := make([]string, len(addrs))
+	for i, a := range addrs {
+		ss[i] = a.String()
+	}
+	return ss
+}
+
+func doWalletAccountRequest(t *testing.T, gateway *MockGatewayer, method, endpoint, body string) (int, ReceivedHTTPResponse) {
+	req, err := http.NewRequest(method, endpoint, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", ContentTypeJSON)
+
+	rr := httptest.NewRecorder()
+	handler := newServerMux(defaultMuxConfig(), gateway)
+	handler.ServeHTTP(rr, req)
+
+	var rsp ReceivedHTTPResponse
+	err = json.Unmarshal(rr.Body.Bytes(), &rsp)
+	require.NoError(t, err)
+
+	return rr.Code, rsp
+}
+
+func TestListWalletAccounts(t *testing.T) {
+	bip44Wallet := newTestBip44AccountWallet(t)
+	deterministicWallet, err := wallet.NewWallet("foo.wlt", "label", "seed", wallet.Options{
+		Type: wallet.WalletTypeDeterministic,
+	})
+	require.NoError(t, err)
+
+	tt := []struct {
+		name             string
+		method           string
+		id               string
+		getWalletResult  wallet.Wallet
+		getWalletErr     error
+		status           int
+		err              *HTTPError
+		expectedAccounts []WalletAccount
+	}{
+		{
+			name:   "405",
+			method: http.MethodDelete,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:         "404 - wallet not found",
+			method:       http.MethodGet,
+			id:           "foo.wlt",
+			getWalletErr: wallet.ErrWalletNotExist,
+			status:       http.StatusNotFound,
+			err:          &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:         "403 - wallet api disabled",
+			method:       http.MethodGet,
+			id:           "foo.wlt",
+			getWalletErr: wallet.ErrWalletAPIDisabled,
+			status:       http.StatusForbidden,
+			err:          &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:            "400 - not a bip44 wallet",
+			method:          http.MethodGet,
+			id:              "foo.wlt",
+			getWalletResult: deterministicWallet,
+			status:          http.StatusBadRequest,
+			err:             &HTTPError{Code: http.StatusBadRequest, Message: "wallet is not a bip44 wallet"},
+		},
+		{
+			name:            "200",
+			method:          http.MethodGet,
+			id:              "bip44.wlt",
+			getWalletResult: bip44Wallet,
+			status:          http.StatusOK,
+			expectedAccounts: []WalletAccount{
+				{
+					Index:           0,
+					Name:            "default",
+					Addresses:       addressStrings(t, bip44Wallet, wallet.OptionExternal()),
+					ChangeAddresses: addressStrings(t, bip44Wallet, wallet.OptionChange()),
+				},
+				{
+					Index:           1,
+					Name:            "customer",
+					Addresses:       addressStrings(t, bip44Wallet, wallet.OptionAccount(1), wallet.OptionExternal()),
+					ChangeAddresses: []string{},
+				},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetWallet", tc.id).Return(tc.getWalletResult, tc.getWalletErr)
+
+			v := url.Values{}
+			if tc.id != "" {
+				v.Add("id", tc.id)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/accounts?"+v.Encode(), "")
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var accounts []WalletAccount
+			require.NoError(t, json.Unmarshal(rsp.Data, &accounts))
+			require.Equal(t, tc.expectedAccounts, accounts)
+			require.Len(t, accounts[0].Addresses, 2)
+			require.Len(t, accounts[1].Addresses, 1)
+		})
+	}
+}
+
+func TestCreateWalletAccount(t *testing.T) {
+	bip44Wallet := newTestBip44AccountWallet(t)
+
+	tt := []struct {
+		name             string
+		body             string
+		req              *WalletAccountCreateRequest
+		createAccountErr error
+		status           int
+		err              *HTTPError
+	}{
+		{
+			name:   "400 - invalid json",
+			body:   "{ca",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid character 'c' looking for beginning of object key string"},
+		},
+		{
+			name:   "400 - missing id",
+			req:    &WalletAccountCreateRequest{Name: "customer"},
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - missing name",
+			req:    &WalletAccountCreateRequest{ID: "bip44.wlt"},
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "name is required"},
+		},
+		{
+			name:             "400 - invalid password",
+			req:              &WalletAccountCreateRequest{ID: "bip44.wlt", Name: "customer", Password: "pwd"},
+			createAccountErr: wallet.ErrInvalidPassword,
+			status:           http.StatusBadRequest,
+			err:              &HTTPError{Code: http.StatusBadRequest, Message: "invalid password"},
+		},
+		{
+			name:             "404 - wallet not found",
+			req:              &WalletAccountCreateRequest{ID: "bip44.wlt", Name: "customer"},
+			createAccountErr: wallet.ErrWalletNotExist,
+			status:           http.StatusNotFound,
+			err:              &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:             "500 - other error",
+			req:              &WalletAccountCreateRequest{ID: "bip44.wlt", Name: "customer"},
+			createAccountErr: errors.New("failed"),
+			status:           http.StatusInternalServerError,
+			err:              &HTTPError{Code: http.StatusInternalServerError, Message: "failed"},
+		},
+		{
+			name:   "200",
+			req:    &WalletAccountCreateRequest{ID: "bip44.wlt", Name: "customer", Password: "pwd"},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			body := tc.body
+			if tc.req != nil {
+				var password []byte
+				if tc.req.Password != "" {
+					password = []byte(tc.req.Password)
+				}
+				gateway.On("CreateAccount", tc.req.ID, password, tc.req.Name).Return(wallet.Bip44Account{
+					Name:  tc.req.Name,
+					Index: 1,
+				}, tc.createAccountErr)
+				gateway.On("GetWallet", tc.req.ID).Return(bip44Wallet, nil)
+				body = toJSON(t, tc.req)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, http.MethodPost, "/api/v2/wallet/accounts", body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var account WalletAccount
+			require.NoError(t, json.Unmarshal(rsp.Data, &account))
+			require.Equal(t, WalletAccount{
+				Index:           1,
+				Name:            "customer",
+				Addresses:       addressStrings(t, bip44Wallet, wallet.OptionAccount(1), wallet.OptionExternal()),
+				ChangeAddresses: []string{},
+			}, account)
+		})
+	}
+}
+
+func TestWalletAccountUpdate(t *testing.T) {
+	tt := []struct {
+		name   string
+		method string
+		body   string
+		req    *WalletAccountUpdateRequest
+		err    error
+		status int
+		rspErr *HTTPError
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			rspErr: &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodPost,
+			req:    &WalletAccountUpdateRequest{Account: 1, Name: "customer"},
+			status: http.StatusBadRequest,
+			rspErr: &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - missing name",
+			method: http.MethodPost,
+			req:    &WalletAccountUpdateRequest{ID: "bip44.wlt", Account: 1},
+			status: http.StatusBadRequest,
+			rspErr: &HTTPError{Code: http.StatusBadRequest, Message: "name is required"},
+		},
+		{
+			name:   "404 - account not found",
+			method: http.MethodPost,
+			req:    &WalletAccountUpdateRequest{ID: "bip44.wlt", Account: 5, Name: "customer"},
+			err:    wallet.ErrBip44AccountNotFound,
+			status: http.StatusNotFound,
+			rspErr: &HTTPError{Code: http.StatusNotFound, Message: "bip44 account not found"},
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			req:    &WalletAccountUpdateRequest{ID: "bip44.wlt", Account: 1, Name: "customer"},
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			body := tc.body
+			if tc.req != nil {
+				gateway.On("UpdateAccountName", tc.req.ID, tc.req.Account, tc.req.Name).Return(tc.err)
+				body = toJSON(t, tc.req)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/accounts/update", body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.rspErr, rsp.Error)
+			if tc.rspErr == nil {
+				gateway.AssertCalled(t, "UpdateAccountName", tc.req.ID, tc.req.Account, tc.req.Name)
+			}
+		})
+	}
+}
+
+func TestWalletAccountBalance(t *testing.T) {
+	addr := "2eZYSbzBKJ7QCL4kd5LSqV478rJQGb4UNkf"
+	balance := wallet.BalancePair{
+		Confirmed: wallet.Balance{Coins: 2e6, Hours: 10},
+		Predicted: wallet.Balance{Coins: 1e6, Hours: 5},
+	}
+	addressBalances := wallet.AddressBalances{
+		addr: balance,
+	}
+
+	tt := []struct {
+		name       string
+		method     string
+		id         string
+		account    string
+		getBalance bool
+		err        error
+		status     int
+		rspErr     *HTTPError
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			rspErr: &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:    "400 - missing id",
+			method:  http.MethodGet,
+			account: "1",
+			status:  http.StatusBadRequest,
+			rspErr:  &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - missing account",
+			method: http.MethodGet,
+			id:     "bip44.wlt",
+			status: http.StatusBadRequest,
+			rspErr: &HTTPError{Code: http.StatusBadRequest, Message: "account is required"},
+		},
+		{
+			name:    "400 - invalid account",
+			method:  http.MethodGet,
+			id:      "bip44.wlt",
+			account: "-1",
+			status:  http.StatusBadRequest,
+			rspErr:  &HTTPError{Code: http.StatusBadRequest, Message: "invalid account value"},
+		},
+		{
+			name:       "400 - not a bip44 wallet",
+			method:     http.MethodGet,
+			id:         "foo.wlt",
+			account:    "1",
+			getBalance: true,
+			err:        wallet.ErrWalletNotBip44,
+			status:     http.StatusBadRequest,
+			rspErr:     &HTTPError{Code: http.StatusBadRequest, Message: "wallet is not a bip44 wallet"},
+		},
+		{
+			name:       "404 - account not found",
+			method:     http.MethodGet,
+			id:         "bip44.wlt",
+			account:    "2",
+			getBalance: true,
+			err:        wallet.ErrBip44AccountNotFound,
+			status:     http.StatusNotFound,
+			rspErr:     &HTTPError{Code: http.StatusNotFound, Message: "bip44 account not found"},
+		},
+		{
+			name:       "200",
+			method:     http.MethodGet,
+			id:         "bip44.wlt",
+			account:    "1",
+			getBalance: true,
+			status:     http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.getBalance {
+				account, err := parseAccount(tc.account)
+				require.NoError(t, err)
+				gateway.On("GetWalletAccountBalance", tc.id, account).Return(balance, addressBalances, tc.err)
+			}
+
+			v := url.Values{}
+			if tc.id != "" {
+				v.Add("id", tc.id)
+			}
+			if tc.account != "" {
+				v.Add("account", tc.account)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/accounts/balance?"+v.Encode(), "")
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.rspErr, rsp.Error)
+			if tc.rspErr != nil {
+				return
+			}
+
+			var balanceRsp BalanceResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &balanceRsp))
+			require.Equal(t, BalanceResponse{
+				BalancePair: readable.NewBalancePair(balance),
+				Addresses:   readable.NewAddressBalances(addressBalances),
+			}, balanceRsp)
+		})
+	}
+}
+
+func TestWalletAccountNewAddresses(t *testing.T) {
+	bip44Wallet := newTestBip44AccountWallet(t)
+	deterministicWallet, err := wallet.NewWallet("foo.wlt", "label", "seed", wallet.Options{
+		Type: wallet.WalletTypeDeterministic,
+	})
+	require.NoError(t, err)
+
+	addrs := []cipher.Address{
+		cipher.MustDecodeBase58Address("2eZYSbzBKJ7QCL4kd5LSqV478rJQGb4UNkf"),
+	}
+
+	tt := []struct {
+		name            string
+		body            string
+		req             *WalletAccountNewAddressesRequest
+		getWalletResult wallet.Wallet
+		newAddresses    bool
+		newAddressesErr error
+		status          int
+		rspErr          *HTTPError
+	}{
+		{
+			name:   "400 - missing id",
+			req:    &WalletAccountNewAddressesRequest{Account: 1},
+			status: http.StatusBadRequest,
+			rspErr: &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:            "400 - not a bip44 wallet",
+			req:             &WalletAccountNewAddressesRequest{ID: "foo.wlt", Account: 1},
+			getWalletResult: deterministicWallet,
+			status:          http.StatusBadRequest,
+			rspErr:          &HTTPError{Code: http.StatusBadRequest, Message: "wallet is not a bip44 wallet"},
+		},
+		{
+			name:            "404 - account not found",
+			req:             &WalletAccountNewAddressesRequest{ID: "bip44.wlt", Account: 2},
+			getWalletResult: bip44Wallet,
+			status:          http.StatusNotFound,
+			rspErr:          &HTTPError{Code: http.StatusNotFound, Message: "bip44 account not found"},
+		},
+		{
+			name:            "403 - wallet api disabled",
+			req:             &WalletAccountNewAddressesRequest{ID: "bip44.wlt", Account: 1},
+			getWalletResult: bip44Wallet,
+			newAddresses:    true,
+			newAddressesErr: wallet.ErrWalletAPIDisabled,
+			status:          http.StatusForbidden,
+			rspErr:          &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:            "200 - external chain",
+			req:             &WalletAccountNewAddressesRequest{ID: "bip44.wlt", Account: 1},
+			getWalletResult: bip44Wallet,
+			newAddresses:    true,
+			status:          http.StatusOK,
+		},
+		{
+			name:            "200 - change chain",
+			req:             &WalletAccountNewAddressesRequest{ID: "bip44.wlt", Account: 1, Num: 1, Change: true},
+			getWalletResult: bip44Wallet,
+			newAddresses:    true,
+			status:          http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			body := tc.body
+			if tc.req != nil {
+				gateway.On("GetWallet", tc.req.ID).Return(tc.getWalletResult, nil)
+				body = toJSON(t, tc.req)
+			}
+
+			if tc.newAddresses {
+				// Options are funcs, so match them by the values they apply
+				applyBip44Option := func(option wallet.Option) wallet.Bip44EntriesOptions {
+					var opts wallet.Bip44EntriesOptions
+					option(&opts)
+					return opts
+				}
+				matchAccount := mock.MatchedBy(func(option wallet.Option) bool {
+					return applyBip44Option(option) == wallet.Bip44EntriesOptions{Account: tc.req.Account}
+				})
+				matchN := mock.MatchedBy(func(option wallet.Option) bool {
+					return wallet.GetGenerateNFromOptions(option) == 1
+				})
+				args := []interface{}{tc.req.ID, []byte(nil), matchAccount, matchN}
+				if tc.req.Change {
+					args = append(args, mock.MatchedBy(func(option wallet.Option) bool {
+						return applyBip44Option(option).ChainMode == wallet.ChangeChain
+					}))
+				}
+				gateway.On("NewAddresses", args...).Return(addrs, tc.newAddressesErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, http.MethodPost, "/api/v2/wallet/accounts/addresses", body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.rspErr, rsp.Error)
+			if tc.rspErr != nil {
+				return
+			}
+
+			var rlt struct {
+				Addresses []string `json:"addresses"`
+			}
+			require.NoError(t, json.Unmarshal(rsp.Data, &rlt))
+			require.Equal(t, []string{addrs[0].String()}, rlt.Addresses)
+		})
+	}
+}
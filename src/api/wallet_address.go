@@ -0,0 +1,174 @@
+package api
+
+// APIs for the labels, tags and notes of wallet addresses
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// walletAddressErrorResponse maps errors of the wallet address endpoints to a HTTPResponse
+func walletAddressErrorResponse(err error) HTTPResponse {
+	switch err {
+	case wallet.ErrWalletNotExist:
+		return NewHTTPErrorResponse(http.StatusNotFound, "")
+	case wallet.ErrAddressNotInWallet:
+		return NewHTTPErrorResponse(http.StatusNotFound, err.Error())
+	case wallet.ErrWalletAPIDisabled:
+		return NewHTTPErrorResponse(http.StatusForbidden, "")
+	}
+
+	switch err.(type) {
+	case wallet.Error:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// WalletAddressUpdateRequest is the request data for POST /api/v2/wallet/address/update
+type WalletAddressUpdateRequest struct {
+	ID      string   `json:"id"`
+	Address string   `json:"address"`
+	Label   string   `json:"label"`
+	Tags    []string `json:"tags"`
+	Note    string   `json:"note"`
+}
+
+// WalletAddressMeta is the label, tags and note of a wallet address
+type WalletAddressMeta struct {
+	Address string   `json:"address"`
+	Label   string   `json:"label"`
+	Tags    []string `json:"tags"`
+	Note    string   `json:"note"`
+}
+
+// Sets the label, tags and note of an address of a wallet, replacing the previous ones.
+// URI: /api/v2/wallet/address/update
+// Method: POST
+// Args:
+//     id: wallet id [required]
+//     address: the wallet address [required]
+//     label: the address label [optional]
+//     tags: the address tags [optional]
+//     note: the address note [optional]
+func walletAddressUpdateHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletAddressUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Address == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "address is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		addr, err := cipher.DecodeBase58Address(req.Address)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid address: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		m := wallet.EntryMeta{
+			Label: req.Label,
+			Tags:  req.Tags,
+			Note:  req.Note,
+		}
+
+		if err := gateway.UpdateAddressMeta(req.ID, addr, m); err != nil {
+			writeHTTPResponse(w, walletAddressErrorResponse(err))
+			return
+		}
+
+		tags := req.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletAddressMeta{
+				Address: addr.String(),
+				Label:   req.Label,
+				Tags:    tags,
+				Note:    req.Note,
+			},
+		})
+	}
+}
+
+// WalletAddressesResponse is the response data for GET /api/v2/wallet/addresses
+type WalletAddressesResponse struct {
+	Entries []readable.WalletEntry `json:"entries"`
+}
+
+// Returns the addresses of a wallet with their labels, tags and notes,
+// including the external and change chains of all accounts of a bip44 wallet.
+// URI: /api/v2/wallet/addresses
+// Method: GET
+// Args:
+//     id: wallet id [required]
+//     label: only returns the addresses whose label contains it, case insensitively [optional]
+//     tag: only returns the addresses that have the tag [optional]
+func walletAddressesHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wlt, err := gateway.GetWallet(wltID)
+		if err != nil {
+			writeHTTPResponse(w, walletAddressErrorResponse(err))
+			return
+		}
+
+		entries, err := wallet.AllEntries(wlt)
+		if err != nil {
+			writeHTTPResponse(w, walletAddressErrorResponse(err))
+			return
+		}
+
+		entries = entries.FilterByMeta(r.FormValue("label"), r.FormValue("tag"))
+
+		resp := WalletAddressesResponse{
+			Entries: make([]readable.WalletEntry, len(entries)),
+		}
+		for i, e := range entries {
+			resp.Entries[i] = newWalletEntry(wlt.Type(), e)
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: resp,
+		})
+	}
+}
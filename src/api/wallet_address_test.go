@@ -0,0 +1,301 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletAddressUpdate(t *testing.T) {
+	addr := cipher.MustDecodeBase58Address("2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U")
+
+	tt := []struct {
+		name      string
+		method    string
+		body      string
+		meta      *wallet.EntryMeta
+		updateErr error
+		status    int
+		err       *HTTPError
+		expect    *WalletAddressMeta
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - invalid json",
+			method: http.MethodPost,
+			body:   "{",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "unexpected EOF"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodPost,
+			body:   `{"address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - missing address",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "address is required"},
+		},
+		{
+			name:   "400 - invalid address",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt","address":"xxx"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid address: Invalid address length"},
+		},
+		{
+			name:      "400 - invalid tag",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U","tags":["a,b"]}`,
+			meta:      &wallet.EntryMeta{Tags: []string{"a,b"}},
+			updateErr: wallet.ErrInvalidEntryTag,
+			status:    http.StatusBadRequest,
+			err:       &HTTPError{Code: http.StatusBadRequest, Message: wallet.ErrInvalidEntryTag.Error()},
+		},
+		{
+			name:      "404 - wallet not found",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"}`,
+			meta:      &wallet.EntryMeta{},
+			updateErr: wallet.ErrWalletNotExist,
+			status:    http.StatusNotFound,
+			err:       &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:      "404 - address not in wallet",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"}`,
+			meta:      &wallet.EntryMeta{},
+			updateErr: wallet.ErrAddressNotInWallet,
+			status:    http.StatusNotFound,
+			err:       &HTTPError{Code: http.StatusNotFound, Message: "address not found in wallet"},
+		},
+		{
+			name:      "403 - wallet api disabled",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"}`,
+			meta:      &wallet.EntryMeta{},
+			updateErr: wallet.ErrWalletAPIDisabled,
+			status:    http.StatusForbidden,
+			err:       &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:      "500 - update failed",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"}`,
+			meta:      &wallet.EntryMeta{},
+			updateErr: errors.New("failed"),
+			status:    http.StatusInternalServerError,
+			err:       &HTTPError{Code: http.StatusInternalServerError, Message: "failed"},
+		},
+		{
+			name:   "200 - clear metadata",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt","address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"}`,
+			meta:   &wallet.EntryMeta{},
+			status: http.StatusOK,
+			expect: &WalletAddressMeta{
+				Address: addr.String(),
+				Tags:    []string{},
+			},
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt","address":"2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U","label":"alice","tags":["customer"],"note":"invoice 42"}`,
+			meta: &wallet.EntryMeta{
+				Label: "alice",
+				Tags:  []string{"customer"},
+				Note:  "invoice 42",
+			},
+			status: http.StatusOK,
+			expect: &WalletAddressMeta{
+				Address: addr.String(),
+				Label:   "alice",
+				Tags:    []string{"customer"},
+				Note:    "invoice 42",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.meta != nil {
+				gateway.On("UpdateAddressMeta", "foo.wlt", addr, *tc.meta).Return(tc.updateErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/address/update", tc.body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var meta WalletAddressMeta
+			require.NoError(t, json.Unmarshal(rsp.Data, &meta))
+			require.Equal(t, *tc.expect, meta)
+		})
+	}
+}
+
+func TestWalletAddresses(t *testing.T) {
+	w := newTestBip44AccountWallet(t)
+
+	externalAddrs, err := w.GetAddresses()
+	require.NoError(t, err)
+	changeAddrs, err := w.GetAddresses(wallet.OptionChange())
+	require.NoError(t, err)
+	customerAddrs, err := w.GetAddresses(wallet.OptionAccount(1))
+	require.NoError(t, err)
+
+	require.NoError(t, w.SetEntryMeta(externalAddrs[1], wallet.EntryMeta{Label: "Alice", Tags: []string{"customer"}}))
+	require.NoError(t, w.SetEntryMeta(changeAddrs[0], wallet.EntryMeta{Label: "change"}))
+	require.NoError(t, w.SetEntryMeta(customerAddrs[0], wallet.EntryMeta{Label: "Bob", Tags: []string{"customer", "vip"}, Note: "invoice 42"}))
+
+	entryAddrs := func(entries []readable.WalletEntry) []string {
+		addrs := make([]string, len(entries))
+		for i, e := range entries {
+			addrs[i] = e.Address
+		}
+		return addrs
+	}
+
+	tt := []struct {
+		name         string
+		method       string
+		query        url.Values
+		getWalletErr error
+		status       int
+		err          *HTTPError
+		expectAddrs  []string
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:         "404 - wallet not found",
+			method:       http.MethodGet,
+			query:        url.Values{"id": {"bip44.wlt"}},
+			getWalletErr: wallet.ErrWalletNotExist,
+			status:       http.StatusNotFound,
+			err:          &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:         "403 - wallet api disabled",
+			method:       http.MethodGet,
+			query:        url.Values{"id": {"bip44.wlt"}},
+			getWalletErr: wallet.ErrWalletAPIDisabled,
+			status:       http.StatusForbidden,
+			err:          &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:   "200 - all addresses",
+			method: http.MethodGet,
+			query:  url.Values{"id": {"bip44.wlt"}},
+			status: http.StatusOK,
+			expectAddrs: []string{
+				externalAddrs[0].String(),
+				externalAddrs[1].String(),
+				changeAddrs[0].String(),
+				customerAddrs[0].String(),
+			},
+		},
+		{
+			name:        "200 - by label",
+			method:      http.MethodGet,
+			query:       url.Values{"id": {"bip44.wlt"}, "label": {"ALI"}},
+			status:      http.StatusOK,
+			expectAddrs: []string{externalAddrs[1].String()},
+		},
+		{
+			name:   "200 - by tag",
+			method: http.MethodGet,
+			query:  url.Values{"id": {"bip44.wlt"}, "tag": {"customer"}},
+			status: http.StatusOK,
+			expectAddrs: []string{
+				externalAddrs[1].String(),
+				customerAddrs[0].String(),
+			},
+		},
+		{
+			name:        "200 - by label and tag",
+			method:      http.MethodGet,
+			query:       url.Values{"id": {"bip44.wlt"}, "label": {"bob"}, "tag": {"vip"}},
+			status:      http.StatusOK,
+			expectAddrs: []string{customerAddrs[0].String()},
+		},
+		{
+			name:        "200 - no match",
+			method:      http.MethodGet,
+			query:       url.Values{"id": {"bip44.wlt"}, "tag": {"carol"}},
+			status:      http.StatusOK,
+			expectAddrs: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.getWalletErr != nil {
+				gateway.On("GetWallet", "bip44.wlt").Return(nil, tc.getWalletErr)
+			} else {
+				gateway.On("GetWallet", "bip44.wlt").Return(w, nil)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/addresses?"+tc.query.Encode(), "")
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var resp WalletAddressesResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &resp))
+			require.Equal(t, tc.expectAddrs, entryAddrs(resp.Entries))
+		})
+	}
+
+	// The metadata of the entries is returned
+	gateway := &MockGatewayer{}
+	gateway.On("GetWallet", "bip44.wlt").Return(w, nil)
+	status, rsp := doWalletAccountRequest(t, gateway, http.MethodGet, "/api/v2/wallet/addresses?id=bip44.wlt&label=bob", "")
+	require.Equal(t, http.StatusOK, status)
+
+	var resp WalletAddressesResponse
+	require.NoError(t, json.Unmarshal(rsp.Data, &resp))
+	require.Len(t, resp.Entries, 1)
+	e := resp.Entries[0]
+	require.Equal(t, "Bob", e.Label)
+	require.Equal(t, []string{"customer", "vip"}, e.Tags)
+	require.Equal(t, "invoice 42", e.Note)
+	require.Equal(t, uint32(0), *e.ChildNumber)
+	require.Equal(t, uint32(0), *e.Change)
+}
@@ -0,0 +1,295 @@
+package api
+
+// APIs for exporting and importing wallet archives
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/kvstorage"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// walletTxIDNotes returns the transaction notes of the transactions of each wallet, keyed by wallet ID.
+// Returns no notes if the txid notes storage is not enabled.
+func walletTxIDNotes(gateway Gatewayer, wltIDs []string) (map[string]map[string]string, error) {
+	allNotes, err := gateway.GetAllStorageValues(kvstorage.TypeTxIDNotes)
+	if err != nil {
+		switch err {
+		case kvstorage.ErrStorageAPIDisabled, kvstorage.ErrNoSuchStorage:
+			return nil, nil
+		default:
+			return nil, err
+		}
+	}
+
+	if len(allNotes) == 0 {
+		return nil, nil
+	}
+
+	notes := make(map[string]map[string]string, len(wltIDs))
+	for _, id := range wltIDs {
+		w, err := gateway.GetWallet(id)
+		if err != nil {
+			return nil, err
+		}
+
+		addrs, err := wallet.AllAddresses(w)
+		if err != nil {
+			return nil, err
+		}
+
+		txns, _, err := gateway.GetTransactions([]visor.TxFilter{visor.NewAddrsFilter(addrs)}, visor.AscOrder, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, txn := range txns {
+			txid := txn.Transaction.Hash().Hex()
+			note, ok := allNotes[txid]
+			if !ok {
+				continue
+			}
+
+			if notes[id] == nil {
+				notes[id] = make(map[string]string)
+			}
+			notes[id][txid] = note
+		}
+	}
+
+	return notes, nil
+}
+
+// walletArchiveErrorResponse maps errors of the wallet archive endpoints to a HTTPResponse
+func walletArchiveErrorResponse(err error) HTTPResponse {
+	switch err {
+	case wallet.ErrWalletNotExist:
+		return NewHTTPErrorResponse(http.StatusNotFound, "")
+	case wallet.ErrWalletAPIDisabled, wallet.ErrSeedAPIDisabled:
+		return NewHTTPErrorResponse(http.StatusForbidden, "")
+	}
+
+	switch err.(type) {
+	case wallet.Error:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// WalletExportRequest is the request data for POST /api/v2/wallet/export
+type WalletExportRequest struct {
+	IDs      []string `json:"ids"`
+	Password string   `json:"password"`
+}
+
+// Exports wallets and their transaction notes as an encrypted wallet archive.
+// Unencrypted wallets, except xpub wallets, can only be exported if the INSECURE_WALLET_SEED API set is enabled.
+// URI: /api/v2/wallet/export
+// Method: POST
+// Args:
+//
+//	ids: wallet ids [required]
+//	password: password to encrypt the archive with [required]
+func walletExportHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletExportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.IDs) == 0 {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "ids is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Password == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		password := []byte(req.Password)
+		defer zeroBytes(password)
+
+		notes, err := walletTxIDNotes(gateway, req.IDs)
+		if err != nil {
+			writeHTTPResponse(w, walletArchiveErrorResponse(err))
+			return
+		}
+
+		archive, err := gateway.ExportWallets(req.IDs, notes, password)
+		if err != nil {
+			writeHTTPResponse(w, walletArchiveErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: json.RawMessage(archive),
+		})
+	}
+}
+
+// WalletImportRequest is the request data for POST /api/v2/wallet/import
+type WalletImportRequest struct {
+	Archive  json.RawMessage `json:"archive"`
+	Password string          `json:"password"`
+	Conflict string          `json:"conflict"`
+	// WalletPasswords are the passwords of the encrypted existing wallets to overwrite, keyed by wallet ID
+	WalletPasswords map[string]string `json:"wallet_passwords,omitempty"`
+}
+
+// WalletImportResponse is the response data for POST /api/v2/wallet/import
+type WalletImportResponse struct {
+	Imported      []string `json:"imported"`
+	Skipped       []string `json:"skipped"`
+	Backups       []string `json:"backups"`
+	NotesImported int      `json:"notes_imported"`
+	// NotesError is set if the wallets were imported but their transaction notes could not be saved
+	NotesError string `json:"notes_error,omitempty"`
+}
+
+// Imports the wallets and transaction notes of a wallet archive created by /api/v2/wallet/export.
+// URI: /api/v2/wallet/import
+// Method: POST
+// Args:
+//
+//	archive: the wallet archive [required]
+//	password: the archive password [required]
+//	conflict: how to handle wallets that already exist, "error", "skip" or "overwrite" [optional, defaults to "error"]
+//	wallet_passwords: the passwords of the encrypted existing wallets to overwrite, keyed by wallet id [optional]
+func walletImportHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletImportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if len(req.Archive) == 0 || string(req.Archive) == "null" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "archive is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Password == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		conflict, err := wallet.ImportConflictFromString(req.Conflict)
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		password := []byte(req.Password)
+		defer zeroBytes(password)
+
+		var walletPasswords map[string][]byte
+		if len(req.WalletPasswords) != 0 {
+			walletPasswords = make(map[string][]byte, len(req.WalletPasswords))
+			for id, p := range req.WalletPasswords {
+				walletPasswords[id] = []byte(p)
+			}
+		}
+		defer func() {
+			for _, p := range walletPasswords {
+				zeroBytes(p)
+			}
+		}()
+
+		result, err := gateway.ImportWallets(req.Archive, password, conflict, walletPasswords)
+		if err != nil {
+			writeHTTPResponse(w, walletArchiveErrorResponse(err))
+			return
+		}
+
+		resp := WalletImportResponse{
+			Imported: make([]string, len(result.Imported)),
+			Skipped:  result.Skipped,
+			Backups:  result.Backups,
+		}
+		for i, wlt := range result.Imported {
+			resp.Imported[i] = wlt.Filename()
+		}
+		if resp.Skipped == nil {
+			resp.Skipped = []string{}
+		}
+		if resp.Backups == nil {
+			resp.Backups = []string{}
+		}
+
+		// The wallets are already imported, a failure to save the notes doesn't fail the request
+		n, err := importTxIDNotes(gateway, result.Notes, conflict == wallet.ImportConflictOverwrite)
+		if err != nil {
+			logger.WithError(err).Error("importTxIDNotes failed")
+			resp.NotesError = err.Error()
+		}
+		resp.NotesImported = n
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: resp,
+		})
+	}
+}
+
+// importTxIDNotes adds the transaction notes of an archive to the txid notes storage.
+// Existing notes are only replaced if overwrite is true.
+// Returns the number of notes added, no notes are added if the txid notes storage is not enabled.
+func importTxIDNotes(gateway Gatewayer, notes map[string]string, overwrite bool) (int, error) {
+	if len(notes) == 0 {
+		return 0, nil
+	}
+
+	existing, err := gateway.GetAllStorageValues(kvstorage.TypeTxIDNotes)
+	if err != nil {
+		switch err {
+		case kvstorage.ErrStorageAPIDisabled, kvstorage.ErrNoSuchStorage:
+			return 0, nil
+		default:
+			return 0, err
+		}
+	}
+
+	var n int
+	for txid, note := range notes {
+		if _, ok := existing[txid]; ok && !overwrite {
+			continue
+		}
+
+		if err := gateway.AddStorageValue(kvstorage.TypeTxIDNotes, txid, note); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+// zeroBytes overwrites the bytes of a password once it is no longer needed
+func zeroBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
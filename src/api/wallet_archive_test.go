@@ -0,0 +1,341 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/kvstorage"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletExport(t *testing.T) {
+	w, err := wallet.NewWallet("foo.wlt", "label", "seed", wallet.Options{
+		Type: wallet.WalletTypeDeterministic,
+	})
+	require.NoError(t, err)
+
+	txn := coin.Transaction{InnerHash: testutil.RandSHA256(t)}
+	txid := txn.Hash().Hex()
+	otherTxID := testutil.RandSHA256(t).Hex()
+
+	archive := []byte(`{"version":1,"crypto_type":"scrypt-chacha20poly1305","checksum":"00","data":"AA=="}`)
+
+	tt := []struct {
+		name          string
+		method        string
+		body          string
+		req           *WalletExportRequest
+		storageErr    error
+		getWalletErr  error
+		exportErr     error
+		expectedNotes map[string]map[string]string
+		status        int
+		err           *HTTPError
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - invalid json",
+			method: http.MethodPost,
+			body:   "{ca",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid character 'c' looking for beginning of object key string"},
+		},
+		{
+			name:   "400 - missing ids",
+			method: http.MethodPost,
+			req:    &WalletExportRequest{Password: "pwd"},
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "ids is required"},
+		},
+		{
+			name:   "400 - missing password",
+			method: http.MethodPost,
+			req:    &WalletExportRequest{IDs: []string{"foo.wlt"}},
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "password is required"},
+		},
+		{
+			name:         "404 - wallet not found",
+			method:       http.MethodPost,
+			req:          &WalletExportRequest{IDs: []string{"foo.wlt"}, Password: "pwd"},
+			getWalletErr: wallet.ErrWalletNotExist,
+			status:       http.StatusNotFound,
+			err:          &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:          "403 - wallet api disabled",
+			method:        http.MethodPost,
+			req:           &WalletExportRequest{IDs: []string{"foo.wlt"}, Password: "pwd"},
+			exportErr:     wallet.ErrWalletAPIDisabled,
+			expectedNotes: map[string]map[string]string{"foo.wlt": {txid: "rent"}},
+			status:        http.StatusForbidden,
+			err:           &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:          "403 - seed api disabled",
+			method:        http.MethodPost,
+			req:           &WalletExportRequest{IDs: []string{"foo.wlt"}, Password: "pwd"},
+			exportErr:     wallet.ErrSeedAPIDisabled,
+			expectedNotes: map[string]map[string]string{"foo.wlt": {txid: "rent"}},
+			status:        http.StatusForbidden,
+			err:           &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:          "500 - export failed",
+			method:        http.MethodPost,
+			req:           &WalletExportRequest{IDs: []string{"foo.wlt"}, Password: "pwd"},
+			exportErr:     errors.New("failed"),
+			expectedNotes: map[string]map[string]string{"foo.wlt": {txid: "rent"}},
+			status:        http.StatusInternalServerError,
+			err:           &HTTPError{Code: http.StatusInternalServerError, Message: "failed"},
+		},
+		{
+			name:          "200",
+			method:        http.MethodPost,
+			req:           &WalletExportRequest{IDs: []string{"foo.wlt"}, Password: "pwd"},
+			expectedNotes: map[string]map[string]string{"foo.wlt": {txid: "rent"}},
+			status:        http.StatusOK,
+		},
+		{
+			name:       "200 - storage disabled",
+			method:     http.MethodPost,
+			req:        &WalletExportRequest{IDs: []string{"foo.wlt"}, Password: "pwd"},
+			storageErr: kvstorage.ErrStorageAPIDisabled,
+			status:     http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			body := tc.body
+			if tc.req != nil {
+				body = toJSON(t, tc.req)
+
+				var allNotes map[string]string
+				if tc.storageErr == nil {
+					allNotes = map[string]string{
+						txid:      "rent",
+						otherTxID: "unrelated",
+					}
+				}
+				gateway.On("GetAllStorageValues", kvstorage.TypeTxIDNotes).Return(allNotes, tc.storageErr)
+				gateway.On("GetWallet", "foo.wlt").Return(w, tc.getWalletErr)
+				gateway.On("GetTransactions", mock.Anything, visor.AscOrder, (*visor.PageIndex)(nil)).Return([]visor.Transaction{
+					{Transaction: txn},
+				}, uint64(0), nil)
+				gateway.On("ExportWallets", tc.req.IDs, tc.expectedNotes, []byte(tc.req.Password)).Return(archive, tc.exportErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/export", body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			require.JSONEq(t, string(archive), string(rsp.Data))
+		})
+	}
+}
+
+func TestWalletImport(t *testing.T) {
+	w, err := wallet.NewWallet("foo.wlt", "label", "seed", wallet.Options{
+		Type: wallet.WalletTypeDeterministic,
+	})
+	require.NoError(t, err)
+
+	archive := json.RawMessage(`{"version":1,"crypto_type":"scrypt-chacha20poly1305","checksum":"00","data":"AA=="}`)
+	notes := map[string]string{
+		"a": "rent",
+		"b": "salary",
+	}
+
+	tt := []struct {
+		name            string
+		body            string
+		req             *WalletImportRequest
+		conflict        wallet.ImportConflict
+		walletPasswords map[string][]byte
+		importErr       error
+		result          *wallet.ImportResult
+		existingNotes   map[string]string
+		storageErr      error
+		expectedNotes   map[string]string
+		status          int
+		err             *HTTPError
+		expectResponse  WalletImportResponse
+	}{
+		{
+			name:   "400 - invalid json",
+			body:   "{ca",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid character 'c' looking for beginning of object key string"},
+		},
+		{
+			name:   "400 - missing archive",
+			req:    &WalletImportRequest{Password: "pwd"},
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "archive is required"},
+		},
+		{
+			name:   "400 - missing password",
+			req:    &WalletImportRequest{Archive: archive},
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "password is required"},
+		},
+		{
+			name:   "400 - invalid conflict",
+			req:    &WalletImportRequest{Archive: archive, Password: "pwd", Conflict: "rename"},
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid import conflict policy"},
+		},
+		{
+			name:      "400 - invalid password",
+			req:       &WalletImportRequest{Archive: archive, Password: "pwd"},
+			conflict:  wallet.ImportConflictError,
+			importErr: wallet.ErrInvalidPassword,
+			status:    http.StatusBadRequest,
+			err:       &HTTPError{Code: http.StatusBadRequest, Message: "invalid password"},
+		},
+		{
+			name:      "400 - checksum mismatch",
+			req:       &WalletImportRequest{Archive: archive, Password: "pwd"},
+			conflict:  wallet.ImportConflictError,
+			importErr: wallet.ErrArchiveChecksumMismatch,
+			status:    http.StatusBadRequest,
+			err:       &HTTPError{Code: http.StatusBadRequest, Message: "wallet archive checksum mismatch"},
+		},
+		{
+			name:     "200 - existing notes are kept",
+			req:      &WalletImportRequest{Archive: archive, Password: "pwd", Conflict: "skip"},
+			conflict: wallet.ImportConflictSkip,
+			result: &wallet.ImportResult{
+				Imported: []wallet.Wallet{w},
+				Skipped:  []string{"bar.wlt"},
+				Notes:    notes,
+			},
+			existingNotes: map[string]string{"a": "existing"},
+			expectedNotes: map[string]string{"b": "salary"},
+			status:        http.StatusOK,
+			expectResponse: WalletImportResponse{
+				Imported:      []string{"foo.wlt"},
+				Skipped:       []string{"bar.wlt"},
+				Backups:       []string{},
+				NotesImported: 1,
+			},
+		},
+		{
+			name: "400 - invalid wallet password",
+			req: &WalletImportRequest{
+				Archive:         archive,
+				Password:        "pwd",
+				Conflict:        "overwrite",
+				WalletPasswords: map[string]string{"foo.wlt": "wrong"},
+			},
+			conflict:        wallet.ImportConflictOverwrite,
+			walletPasswords: map[string][]byte{"foo.wlt": []byte("wrong")},
+			importErr:       wallet.ErrInvalidPassword,
+			status:          http.StatusBadRequest,
+			err:             &HTTPError{Code: http.StatusBadRequest, Message: "invalid password"},
+		},
+		{
+			name: "200 - overwrite notes",
+			req: &WalletImportRequest{
+				Archive:         archive,
+				Password:        "pwd",
+				Conflict:        "overwrite",
+				WalletPasswords: map[string]string{"foo.wlt": "wltpwd"},
+			},
+			conflict:        wallet.ImportConflictOverwrite,
+			walletPasswords: map[string][]byte{"foo.wlt": []byte("wltpwd")},
+			result: &wallet.ImportResult{
+				Imported: []wallet.Wallet{w},
+				Backups:  []string{"foo.wlt.import-1577836800000000000.bak"},
+				Notes:    notes,
+			},
+			existingNotes: map[string]string{"a": "existing"},
+			expectedNotes: notes,
+			status:        http.StatusOK,
+			expectResponse: WalletImportResponse{
+				Imported:      []string{"foo.wlt"},
+				Skipped:       []string{},
+				Backups:       []string{"foo.wlt.import-1577836800000000000.bak"},
+				NotesImported: 2,
+			},
+		},
+		{
+			name:     "200 - notes storage failed",
+			req:      &WalletImportRequest{Archive: archive, Password: "pwd"},
+			conflict: wallet.ImportConflictError,
+			result: &wallet.ImportResult{
+				Imported: []wallet.Wallet{w},
+				Notes:    notes,
+			},
+			storageErr: errors.New("storage failed"),
+			status:     http.StatusOK,
+			expectResponse: WalletImportResponse{
+				Imported:   []string{"foo.wlt"},
+				Skipped:    []string{},
+				Backups:    []string{},
+				NotesError: "storage failed",
+			},
+		},
+		{
+			name:     "200 - storage disabled",
+			req:      &WalletImportRequest{Archive: archive, Password: "pwd"},
+			conflict: wallet.ImportConflictError,
+			result: &wallet.ImportResult{
+				Imported: []wallet.Wallet{w},
+				Notes:    notes,
+			},
+			storageErr: kvstorage.ErrStorageAPIDisabled,
+			status:     http.StatusOK,
+			expectResponse: WalletImportResponse{
+				Imported: []string{"foo.wlt"},
+				Skipped:  []string{},
+				Backups:  []string{},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			body := tc.body
+			if tc.req != nil {
+				body = toJSON(t, tc.req)
+				gateway.On("ImportWallets", []byte(tc.req.Archive), []byte(tc.req.Password), tc.conflict, tc.walletPasswords).Return(tc.result, tc.importErr)
+				gateway.On("GetAllStorageValues", kvstorage.TypeTxIDNotes).Return(tc.existingNotes, tc.storageErr)
+				for txid, note := range tc.expectedNotes {
+					gateway.On("AddStorageValue", kvstorage.TypeTxIDNotes, txid, note).Return(nil)
+				}
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, http.MethodPost, "/api/v2/wallet/import", body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var resp WalletImportResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &resp))
+			require.Equal(t, tc.expectResponse, resp)
+			gateway.AssertNumberOfCalls(t, "AddStorageValue", len(tc.expectedNotes))
+		})
+	}
+}
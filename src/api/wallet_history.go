@@ -0,0 +1,259 @@
+package api
+
+// APIs for the transaction history of wallets
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// WalletHistoryTransaction is a transaction of a wallet's history, with its effect on the wallet's balance
+type WalletHistoryTransaction struct {
+	Txid         string                      `json:"txid"`
+	Type         visor.WalletTransactionType `json:"type"`
+	Height       uint64                      `json:"height"`
+	BlockSeq     uint64                      `json:"block_seq"`
+	Timestamp    uint64                      `json:"timestamp"`
+	CoinsIn      string                      `json:"coins_in"`
+	CoinsOut     string                      `json:"coins_out"`
+	CoinsDelta   string                      `json:"coins_delta"`
+	HoursIn      uint64                      `json:"hours_in"`
+	HoursOut     uint64                      `json:"hours_out"`
+	HoursDelta   int64                       `json:"hours_delta"`
+	BalanceCoins string                      `json:"balance_coins"`
+	BalanceHours uint64                      `json:"balance_hours"`
+}
+
+// NewWalletHistoryTransaction creates a WalletHistoryTransaction from a visor.WalletTransaction
+func NewWalletHistoryTransaction(wt visor.WalletTransaction) (*WalletHistoryTransaction, error) {
+	coinsIn, err := droplet.ToString(wt.CoinsIn)
+	if err != nil {
+		return nil, err
+	}
+
+	coinsOut, err := droplet.ToString(wt.CoinsOut)
+	if err != nil {
+		return nil, err
+	}
+
+	coinsDelta, err := signedDropletString(wt.CoinsDelta())
+	if err != nil {
+		return nil, err
+	}
+
+	balanceCoins, err := droplet.ToString(wt.Balance.Coins)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WalletHistoryTransaction{
+		Txid:         wt.Transaction.Transaction.Hash().Hex(),
+		Type:         wt.Type,
+		Height:       wt.Status.Height,
+		BlockSeq:     wt.Status.BlockSeq,
+		Timestamp:    wt.Time,
+		CoinsIn:      coinsIn,
+		CoinsOut:     coinsOut,
+		CoinsDelta:   coinsDelta,
+		HoursIn:      wt.HoursIn,
+		HoursOut:     wt.HoursOut,
+		HoursDelta:   wt.HoursDelta(),
+		BalanceCoins: balanceCoins,
+		BalanceHours: wt.Balance.Hours,
+	}, nil
+}
+
+// signedDropletString converts a signed droplet amount to a decimal coins string
+func signedDropletString(n int64) (string, error) {
+	if n >= 0 {
+		return droplet.ToString(uint64(n))
+	}
+
+	s, err := droplet.ToString(uint64(-n))
+	if err != nil {
+		return "", err
+	}
+	return "-" + s, nil
+}
+
+// WalletHistoryResponse is the response data for GET /api/v2/wallet/history
+type WalletHistoryResponse struct {
+	PageInfo readable.PageInfo          `json:"page_info"`
+	Txns     []WalletHistoryTransaction `json:"txns"`
+}
+
+// walletHistoryCSVHeader is the header row of the CSV export of a wallet's history
+var walletHistoryCSVHeader = []string{
+	"txid",
+	"type",
+	"block_seq",
+	"time",
+	"coins_in",
+	"coins_out",
+	"coins_delta",
+	"hours_in",
+	"hours_out",
+	"hours_delta",
+	"balance_coins",
+	"balance_hours",
+}
+
+// Returns the confirmed transactions of all addresses of a wallet, with the running balance of the wallet.
+// Transactions are classified as "incoming", "outgoing", "self" (between the wallet's addresses)
+// or "change" (between the wallet's addresses, to change addresses only).
+// Coin hours are the hours stored in the outputs, the hours accrued by the spent outputs are not included.
+// URI: /api/v2/wallet/history
+// Method: GET
+// Args:
+//     id: wallet id [required]
+//     page: page number [optional, defaults to 1]
+//     limit: the number of transactions per page [optional, default to 10, must be <= 100]
+//     sort: sort the transactions by block seq [optional, must be asc or desc, defaults to asc]
+//     format: the response format [optional, must be json or csv, defaults to json].
+//         The csv format returns all transactions unless page or limit is provided.
+func walletHistoryHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		wltID := r.FormValue("id")
+		if wltID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		format := r.FormValue("format")
+		switch format {
+		case "":
+			format = "json"
+		case "json", "csv":
+		default:
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "invalid 'format' value, must be json or csv")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		order, err := parseSortOrderFromStr(r.FormValue("sort"))
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid 'sort' value: %v", err))
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		pageSize := visor.DefaultTxnPageSize
+		pageSizeStr := r.FormValue("limit")
+		if pageSizeStr != "" {
+			pageSize, err = strconv.ParseUint(pageSizeStr, 10, 64)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid 'limit' value: %v", err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		currentPage := uint64(1)
+		pageStr := r.FormValue("page")
+		if pageStr != "" {
+			currentPage, err = strconv.ParseUint(pageStr, 10, 64)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, fmt.Sprintf("invalid 'page' value: %v", err))
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		var pageIndex *visor.PageIndex
+		if format == "json" || pageSizeStr != "" || pageStr != "" {
+			pageIndex, err = visor.NewPageIndex(pageSize, currentPage)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+				writeHTTPResponse(w, resp)
+				return
+			}
+		}
+
+		history, pages, err := gateway.GetWalletHistory(wltID, order, pageIndex)
+		if err != nil {
+			var resp HTTPResponse
+			switch err {
+			case wallet.ErrWalletNotExist:
+				resp = NewHTTPErrorResponse(http.StatusNotFound, "")
+			case wallet.ErrWalletAPIDisabled:
+				resp = NewHTTPErrorResponse(http.StatusForbidden, "")
+			default:
+				resp = NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+			}
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		txns := make([]WalletHistoryTransaction, len(history))
+		for i, wt := range history {
+			txn, err := NewWalletHistoryTransaction(wt)
+			if err != nil {
+				resp := NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+				writeHTTPResponse(w, resp)
+				return
+			}
+			txns[i] = *txn
+		}
+
+		if format == "csv" {
+			writeWalletHistoryCSV(w, wltID, txns)
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletHistoryResponse{
+				PageInfo: readable.PageInfo{
+					TotalPages:  pages,
+					PageSize:    pageSize,
+					CurrentPage: currentPage,
+				},
+				Txns: txns,
+			},
+		})
+	}
+}
+
+// writeWalletHistoryCSV writes the wallet history transactions as a CSV file attachment
+func writeWalletHistoryCSV(w http.ResponseWriter, wltID string, txns []WalletHistoryTransaction) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", wltID+".history.csv"))
+
+	cw := csv.NewWriter(w)
+	records := make([][]string, 0, len(txns)+1)
+	records = append(records, walletHistoryCSVHeader)
+	for _, txn := range txns {
+		records = append(records, []string{
+			txn.Txid,
+			string(txn.Type),
+			strconv.FormatUint(txn.BlockSeq, 10),
+			time.Unix(int64(txn.Timestamp), 0).UTC().Format(time.RFC3339),
+			txn.CoinsIn,
+			txn.CoinsOut,
+			txn.CoinsDelta,
+			strconv.FormatUint(txn.HoursIn, 10),
+			strconv.FormatUint(txn.HoursOut, 10),
+			strconv.FormatInt(txn.HoursDelta, 10),
+			txn.BalanceCoins,
+			strconv.FormatUint(txn.BalanceHours, 10),
+		})
+	}
+
+	if err := cw.WriteAll(records); err != nil {
+		logger.WithError(err).Error("write wallet history csv failed")
+	}
+}
@@ -0,0 +1,324 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/readable"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletHistory(t *testing.T) {
+	txns := []coin.Transaction{
+		{InnerHash: testutil.RandSHA256(t)},
+		{InnerHash: testutil.RandSHA256(t)},
+	}
+
+	history := []visor.WalletTransaction{
+		{
+			Transaction: visor.Transaction{
+				Transaction: txns[0],
+				Status:      visor.NewConfirmedTransactionStatus(2, 1),
+				Time:        1600000000,
+			},
+			Type:    visor.WalletTransactionIncoming,
+			CoinsIn: 10e6,
+			HoursIn: 100,
+			Balance: wallet.Balance{Coins: 10e6, Hours: 100},
+		},
+		{
+			Transaction: visor.Transaction{
+				Transaction: txns[1],
+				Status:      visor.NewConfirmedTransactionStatus(1, 2),
+				Time:        1600000010,
+			},
+			Type:     visor.WalletTransactionOutgoing,
+			CoinsIn:  6e6,
+			HoursIn:  30,
+			CoinsOut: 10e6,
+			HoursOut: 100,
+			Balance:  wallet.Balance{Coins: 6e6, Hours: 30},
+		},
+	}
+
+	expectTxns := []WalletHistoryTransaction{
+		{
+			Txid:         txns[0].Hash().Hex(),
+			Type:         visor.WalletTransactionIncoming,
+			Height:       2,
+			BlockSeq:     1,
+			Timestamp:    1600000000,
+			CoinsIn:      "10.000000",
+			CoinsOut:     "0.000000",
+			CoinsDelta:   "10.000000",
+			HoursIn:      100,
+			HoursDelta:   100,
+			BalanceCoins: "10.000000",
+			BalanceHours: 100,
+		},
+		{
+			Txid:         txns[1].Hash().Hex(),
+			Type:         visor.WalletTransactionOutgoing,
+			Height:       1,
+			BlockSeq:     2,
+			Timestamp:    1600000010,
+			CoinsIn:      "6.000000",
+			CoinsOut:     "10.000000",
+			CoinsDelta:   "-4.000000",
+			HoursIn:      30,
+			HoursOut:     100,
+			HoursDelta:   -70,
+			BalanceCoins: "6.000000",
+			BalanceHours: 30,
+		},
+	}
+
+	page := func(size, n uint64) *visor.PageIndex {
+		p, err := visor.NewPageIndex(size, n)
+		require.NoError(t, err)
+		return p
+	}
+
+	tt := []struct {
+		name       string
+		method     string
+		query      string
+		id         string
+		order      visor.SortOrder
+		page       *visor.PageIndex
+		history    []visor.WalletTransaction
+		pages      uint64
+		historyErr error
+		status     int
+		err        *HTTPError
+		expect     *WalletHistoryResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodPost,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - invalid format",
+			method: http.MethodGet,
+			query:  "id=foo.wlt&format=xml",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid 'format' value, must be json or csv"},
+		},
+		{
+			name:   "400 - invalid sort",
+			method: http.MethodGet,
+			query:  "id=foo.wlt&sort=up",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid 'sort' value: Unknown sort order"},
+		},
+		{
+			name:   "400 - invalid limit",
+			method: http.MethodGet,
+			query:  "id=foo.wlt&limit=a",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid 'limit' value: strconv.ParseUint: parsing \"a\": invalid syntax"},
+		},
+		{
+			name:   "400 - limit too large",
+			method: http.MethodGet,
+			query:  "id=foo.wlt&limit=101",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "transaction page size must be not greater than 100"},
+		},
+		{
+			name:   "400 - zero page",
+			method: http.MethodGet,
+			query:  "id=foo.wlt&page=0",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "page number must be greater than 0"},
+		},
+		{
+			name:       "404 - wallet not found",
+			method:     http.MethodGet,
+			query:      "id=foo.wlt",
+			id:         "foo.wlt",
+			order:      visor.AscOrder,
+			page:       page(visor.DefaultTxnPageSize, 1),
+			historyErr: wallet.ErrWalletNotExist,
+			status:     http.StatusNotFound,
+			err:        &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:       "403 - wallet api disabled",
+			method:     http.MethodGet,
+			query:      "id=foo.wlt",
+			id:         "foo.wlt",
+			order:      visor.AscOrder,
+			page:       page(visor.DefaultTxnPageSize, 1),
+			historyErr: wallet.ErrWalletAPIDisabled,
+			status:     http.StatusForbidden,
+			err:        &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:       "500 - get history failed",
+			method:     http.MethodGet,
+			query:      "id=foo.wlt",
+			id:         "foo.wlt",
+			order:      visor.AscOrder,
+			page:       page(visor.DefaultTxnPageSize, 1),
+			historyErr: errors.New("failed"),
+			status:     http.StatusInternalServerError,
+			err:        &HTTPError{Code: http.StatusInternalServerError, Message: "failed"},
+		},
+		{
+			name:    "200 - no transactions",
+			method:  http.MethodGet,
+			query:   "id=foo.wlt",
+			id:      "foo.wlt",
+			order:   visor.AscOrder,
+			page:    page(visor.DefaultTxnPageSize, 1),
+			history: nil,
+			status:  http.StatusOK,
+			expect: &WalletHistoryResponse{
+				PageInfo: readable.PageInfo{
+					PageSize:    visor.DefaultTxnPageSize,
+					CurrentPage: 1,
+				},
+				Txns: []WalletHistoryTransaction{},
+			},
+		},
+		{
+			name:    "200 - page=2 limit=1 sort=desc",
+			method:  http.MethodGet,
+			query:   "id=foo.wlt&page=2&limit=1&sort=desc",
+			id:      "foo.wlt",
+			order:   visor.DescOrder,
+			page:    page(1, 2),
+			history: history[:1],
+			pages:   2,
+			status:  http.StatusOK,
+			expect: &WalletHistoryResponse{
+				PageInfo: readable.PageInfo{
+					TotalPages:  2,
+					PageSize:    1,
+					CurrentPage: 2,
+				},
+				Txns: expectTxns[:1],
+			},
+		},
+		{
+			name:    "200",
+			method:  http.MethodGet,
+			query:   "id=foo.wlt",
+			id:      "foo.wlt",
+			order:   visor.AscOrder,
+			page:    page(visor.DefaultTxnPageSize, 1),
+			history: history,
+			pages:   1,
+			status:  http.StatusOK,
+			expect: &WalletHistoryResponse{
+				PageInfo: readable.PageInfo{
+					TotalPages:  1,
+					PageSize:    visor.DefaultTxnPageSize,
+					CurrentPage: 1,
+				},
+				Txns: expectTxns,
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.id != "" {
+				gateway.On("GetWalletHistory", tc.id, tc.order, tc.page).Return(tc.history, tc.pages, tc.historyErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/history?"+tc.query, "")
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var resp WalletHistoryResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &resp))
+			require.Equal(t, *tc.expect, resp)
+		})
+	}
+}
+
+func TestWalletHistoryCSV(t *testing.T) {
+	txn := coin.Transaction{InnerHash: testutil.RandSHA256(t)}
+	history := []visor.WalletTransaction{
+		{
+			Transaction: visor.Transaction{
+				Transaction: txn,
+				Status:      visor.NewConfirmedTransactionStatus(1, 3),
+				Time:        1600000000,
+			},
+			Type:     visor.WalletTransactionOutgoing,
+			CoinsIn:  1500000,
+			HoursIn:  5,
+			CoinsOut: 2e6,
+			HoursOut: 10,
+			Balance:  wallet.Balance{Coins: 1500000, Hours: 5},
+		},
+	}
+
+	expectCSV := strings.Join([]string{
+		"txid,type,block_seq,time,coins_in,coins_out,coins_delta,hours_in,hours_out,hours_delta,balance_coins,balance_hours",
+		txn.Hash().Hex() + ",outgoing,3,2020-09-13T12:26:40Z,1.500000,2.000000,-0.500000,5,10,-5,1.500000,5",
+		"",
+	}, "\n")
+
+	page, err := visor.NewPageIndex(50, 1)
+	require.NoError(t, err)
+
+	tt := []struct {
+		name  string
+		query string
+		page  *visor.PageIndex
+	}{
+		{
+			name:  "all transactions",
+			query: "id=foo.wlt&format=csv",
+		},
+		{
+			name:  "paginated",
+			query: "id=foo.wlt&format=csv&limit=50",
+			page:  page,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetWalletHistory", "foo.wlt", visor.AscOrder, tc.page).Return(history, uint64(1), nil)
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v2/wallet/history?"+tc.query, nil)
+			require.NoError(t, err)
+
+			rr := httptest.NewRecorder()
+			handler := newServerMux(defaultMuxConfig(), gateway)
+			handler.ServeHTTP(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, "text/csv", rr.Header().Get("Content-Type"))
+			require.Equal(t, `attachment; filename="foo.wlt.history.csv"`, rr.Header().Get("Content-Disposition"))
+			require.Equal(t, expectCSV, rr.Body.String())
+		})
+	}
+}
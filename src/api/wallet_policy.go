@@ -0,0 +1,274 @@
+package api
+
+// APIs for wallet spending policies
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// WalletPolicyDestination is an address of the allow-list of a spending policy
+type WalletPolicyDestination struct {
+	Address string `json:"address"`
+	// AddedAt is the unix time when the address was added to the allow-list
+	AddedAt int64 `json:"added_at"`
+}
+
+// WalletSpendingPolicy is the spending policy of a wallet. Coins are in decimal coins, 0 means no limit.
+type WalletSpendingPolicy struct {
+	MaxCoinsPerTransaction string                    `json:"max_coins_per_transaction"`
+	MaxCoinsPerDay         string                    `json:"max_coins_per_day"`
+	AllowedDestinations    []WalletPolicyDestination `json:"allowed_destinations"`
+	DestinationCooldown    uint64                    `json:"destination_cooldown"`
+	MinConfirmations       uint64                    `json:"min_confirmations"`
+}
+
+// WalletPolicyResponse is the response data for /api/v2/wallet/policy
+type WalletPolicyResponse struct {
+	// Policy is null if the wallet has no spending policy
+	Policy *WalletSpendingPolicy `json:"policy"`
+	// DailySpent is the number of coins spent under the policy in the last 24 hours
+	DailySpent string `json:"daily_spent"`
+}
+
+func newWalletPolicyResponse(p *wallet.SpendingPolicy, spends []wallet.PolicySpend) (*WalletPolicyResponse, error) {
+	var spent uint64
+	for _, s := range spends {
+		spent += s.Coins
+	}
+
+	dailySpent, err := droplet.ToString(spent)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &WalletPolicyResponse{
+		DailySpent: dailySpent,
+	}
+
+	if p == nil {
+		return resp, nil
+	}
+
+	maxPerTxn, err := droplet.ToString(p.MaxCoinsPerTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPerDay, err := droplet.ToString(p.MaxCoinsPerDay)
+	if err != nil {
+		return nil, err
+	}
+
+	destinations := make([]WalletPolicyDestination, len(p.AllowedDestinations))
+	for i, d := range p.AllowedDestinations {
+		destinations[i] = WalletPolicyDestination{
+			Address: d.Address.String(),
+			AddedAt: d.AddedAt,
+		}
+	}
+
+	resp.Policy = &WalletSpendingPolicy{
+		MaxCoinsPerTransaction: maxPerTxn,
+		MaxCoinsPerDay:         maxPerDay,
+		AllowedDestinations:    destinations,
+		DestinationCooldown:    p.DestinationCooldown,
+		MinConfirmations:       p.MinConfirmations,
+	}
+	return resp, nil
+}
+
+// walletPolicyErrorResponse maps errors of the wallet policy endpoints to a HTTPResponse
+func walletPolicyErrorResponse(err error) HTTPResponse {
+	switch err {
+	case wallet.ErrWalletNotExist:
+		return NewHTTPErrorResponse(http.StatusNotFound, "")
+	case wallet.ErrWalletAPIDisabled:
+		return NewHTTPErrorResponse(http.StatusForbidden, "")
+	}
+
+	switch err.(type) {
+	case wallet.Error:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Dispatches /wallet/policy endpoint.
+// Method: GET, POST
+// URI: /api/v2/wallet/policy
+func walletPolicyHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			getWalletPolicyHandler(w, r, gateway)
+		case http.MethodPost:
+			setWalletPolicyHandler(w, r, gateway)
+		default:
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+		}
+	}
+}
+
+// Returns the spending policy of a wallet and the coins spent under it in the last 24 hours
+// Args:
+//     id: wallet id [required]
+func getWalletPolicyHandler(w http.ResponseWriter, r *http.Request, gateway Gatewayer) {
+	wltID := r.FormValue("id")
+	if wltID == "" {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	p, spends, err := gateway.GetSpendingPolicy(wltID)
+	if err != nil {
+		writeHTTPResponse(w, walletPolicyErrorResponse(err))
+		return
+	}
+
+	resp, err := newWalletPolicyResponse(p, spends)
+	if err != nil {
+		writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: resp,
+	})
+}
+
+// WalletPolicyParams are the spending policy parameters of POST /api/v2/wallet/policy
+type WalletPolicyParams struct {
+	MaxCoinsPerTransaction string   `json:"max_coins_per_transaction"`
+	MaxCoinsPerDay         string   `json:"max_coins_per_day"`
+	AllowedDestinations    []string `json:"allowed_destinations"`
+	DestinationCooldown    uint64   `json:"destination_cooldown"`
+	MinConfirmations       uint64   `json:"min_confirmations"`
+}
+
+// ToSpendingPolicy converts the params to a wallet.SpendingPolicy
+func (p WalletPolicyParams) ToSpendingPolicy() (*wallet.SpendingPolicy, error) {
+	parseCoins := func(name, s string) (uint64, error) {
+		if s == "" {
+			return 0, nil
+		}
+
+		coins, err := droplet.FromString(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %v", name, err)
+		}
+		return coins, nil
+	}
+
+	maxPerTxn, err := parseCoins("max_coins_per_transaction", p.MaxCoinsPerTransaction)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPerDay, err := parseCoins("max_coins_per_day", p.MaxCoinsPerDay)
+	if err != nil {
+		return nil, err
+	}
+
+	destinations := make([]wallet.PolicyDestination, len(p.AllowedDestinations))
+	for i, a := range p.AllowedDestinations {
+		addr, err := cipher.DecodeBase58Address(a)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed destination %q: %v", a, err)
+		}
+		destinations[i] = wallet.PolicyDestination{
+			Address: addr,
+		}
+	}
+
+	return &wallet.SpendingPolicy{
+		MaxCoinsPerTransaction: maxPerTxn,
+		MaxCoinsPerDay:         maxPerDay,
+		AllowedDestinations:    destinations,
+		DestinationCooldown:    p.DestinationCooldown,
+		MinConfirmations:       p.MinConfirmations,
+	}, nil
+}
+
+// WalletPolicyUpdateRequest is the request data for POST /api/v2/wallet/policy
+type WalletPolicyUpdateRequest struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	// Policy replaces the spending policy of the wallet, null removes it
+	Policy *WalletPolicyParams `json:"policy"`
+}
+
+// Sets the spending policy of an encrypted wallet, replacing the previous one.
+// Transactions signed by the wallet must be allowed by the policy.
+// Args:
+//     id: wallet id [required]
+//     password: wallet password [required]
+//     policy: the spending policy, null to remove the policy [optional]
+func setWalletPolicyHandler(w http.ResponseWriter, r *http.Request, gateway Gatewayer) {
+	var req WalletPolicyUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	if req.ID == "" {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	if req.Password == "" {
+		resp := NewHTTPErrorResponse(http.StatusBadRequest, "password is required")
+		writeHTTPResponse(w, resp)
+		return
+	}
+
+	password := []byte(req.Password)
+
+	defer func() {
+		req.Password = ""
+		password = nil
+	}()
+
+	var policy *wallet.SpendingPolicy
+	if req.Policy != nil {
+		var err error
+		policy, err = req.Policy.ToSpendingPolicy()
+		if err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+	}
+
+	policy, err := gateway.SetSpendingPolicy(req.ID, password, policy)
+	if err != nil {
+		writeHTTPResponse(w, walletPolicyErrorResponse(err))
+		return
+	}
+
+	_, spends, err := gateway.GetSpendingPolicy(req.ID)
+	if err != nil {
+		writeHTTPResponse(w, walletPolicyErrorResponse(err))
+		return
+	}
+
+	resp, err := newWalletPolicyResponse(policy, spends)
+	if err != nil {
+		writeHTTPResponse(w, NewHTTPErrorResponse(http.StatusInternalServerError, err.Error()))
+		return
+	}
+
+	writeHTTPResponse(w, HTTPResponse{
+		Data: resp,
+	})
+}
@@ -0,0 +1,255 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestGetWalletPolicy(t *testing.T) {
+	addr := cipher.MustDecodeBase58Address("2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U")
+
+	policy := &wallet.SpendingPolicy{
+		MaxCoinsPerTransaction: 1e6,
+		MaxCoinsPerDay:         10e6,
+		AllowedDestinations:    []wallet.PolicyDestination{{Address: addr, AddedAt: 1600000000}},
+		DestinationCooldown:    3600,
+		MinConfirmations:       6,
+	}
+
+	tt := []struct {
+		name      string
+		method    string
+		id        string
+		policy    *wallet.SpendingPolicy
+		spends    []wallet.PolicySpend
+		getErr    error
+		status    int
+		err       *HTTPError
+		expectRsp *WalletPolicyResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodDelete,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodGet,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "404 - wallet not found",
+			method: http.MethodGet,
+			id:     "foo.wlt",
+			getErr: wallet.ErrWalletNotExist,
+			status: http.StatusNotFound,
+			err:    &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:   "403 - wallet api disabled",
+			method: http.MethodGet,
+			id:     "foo.wlt",
+			getErr: wallet.ErrWalletAPIDisabled,
+			status: http.StatusForbidden,
+			err:    &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:   "500 - get failed",
+			method: http.MethodGet,
+			id:     "foo.wlt",
+			getErr: errors.New("invalid spending policy"),
+			status: http.StatusInternalServerError,
+			err:    &HTTPError{Code: http.StatusInternalServerError, Message: "invalid spending policy"},
+		},
+		{
+			name:   "200 - no policy",
+			method: http.MethodGet,
+			id:     "foo.wlt",
+			status: http.StatusOK,
+			expectRsp: &WalletPolicyResponse{
+				DailySpent: "0.000000",
+			},
+		},
+		{
+			name:   "200",
+			method: http.MethodGet,
+			id:     "foo.wlt",
+			policy: policy,
+			spends: []wallet.PolicySpend{{Time: 1600000000, Coins: 1e6}, {Time: 1600000001, Coins: 500e3}},
+			status: http.StatusOK,
+			expectRsp: &WalletPolicyResponse{
+				Policy: &WalletSpendingPolicy{
+					MaxCoinsPerTransaction: "1.000000",
+					MaxCoinsPerDay:         "10.000000",
+					AllowedDestinations: []WalletPolicyDestination{
+						{Address: addr.String(), AddedAt: 1600000000},
+					},
+					DestinationCooldown: 3600,
+					MinConfirmations:    6,
+				},
+				DailySpent: "1.500000",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			gateway.On("GetSpendingPolicy", tc.id).Return(tc.policy, tc.spends, tc.getErr)
+
+			endpoint := "/api/v2/wallet/policy"
+			if tc.id != "" {
+				endpoint += "?id=" + tc.id
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, endpoint, "")
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var policyRsp WalletPolicyResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &policyRsp))
+			require.Equal(t, *tc.expectRsp, policyRsp)
+		})
+	}
+}
+
+func TestSetWalletPolicy(t *testing.T) {
+	addr := cipher.MustDecodeBase58Address("2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U")
+
+	policy := &wallet.SpendingPolicy{
+		MaxCoinsPerTransaction: 1e6,
+		MaxCoinsPerDay:         10e6,
+		AllowedDestinations:    []wallet.PolicyDestination{{Address: addr}},
+		DestinationCooldown:    3600,
+		MinConfirmations:       6,
+	}
+
+	setPolicy := *policy
+	setPolicy.AllowedDestinations = []wallet.PolicyDestination{{Address: addr, AddedAt: 1600000000}}
+
+	policyBody := `{"id":"foo.wlt","password":"pwd","policy":{"max_coins_per_transaction":"1","max_coins_per_day":"10","allowed_destinations":["2JBfeo6y6FQn2rCiuhdQ8F1E6bj6rpnHo5U"],"destination_cooldown":3600,"min_confirmations":6}}`
+
+	tt := []struct {
+		name      string
+		body      string
+		policy    *wallet.SpendingPolicy
+		setCalled bool
+		setResult *wallet.SpendingPolicy
+		setErr    error
+		status    int
+		err       *HTTPError
+		expectRsp *WalletPolicyResponse
+	}{
+		{
+			name:   "400 - invalid json",
+			body:   "{",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "unexpected EOF"},
+		},
+		{
+			name:   "400 - missing id",
+			body:   `{"password":"pwd"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - missing password",
+			body:   `{"id":"foo.wlt"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "password is required"},
+		},
+		{
+			name:   "400 - invalid coins",
+			body:   `{"id":"foo.wlt","password":"pwd","policy":{"max_coins_per_day":"x"}}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "invalid max_coins_per_day: can't convert x to decimal"},
+		},
+		{
+			name:   "400 - invalid destination",
+			body:   `{"id":"foo.wlt","password":"pwd","policy":{"allowed_destinations":["xxx"]}}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: `invalid allowed destination "xxx": Invalid address length`},
+		},
+		{
+			name:      "400 - invalid password",
+			body:      policyBody,
+			policy:    policy,
+			setCalled: true,
+			setErr:    wallet.ErrInvalidPassword,
+			status:    http.StatusBadRequest,
+			err:       &HTTPError{Code: http.StatusBadRequest, Message: "invalid password"},
+		},
+		{
+			name:      "404 - wallet not found",
+			body:      policyBody,
+			policy:    policy,
+			setCalled: true,
+			setErr:    wallet.ErrWalletNotExist,
+			status:    http.StatusNotFound,
+			err:       &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:      "200 - remove policy",
+			body:      `{"id":"foo.wlt","password":"pwd","policy":null}`,
+			setCalled: true,
+			status:    http.StatusOK,
+			expectRsp: &WalletPolicyResponse{
+				DailySpent: "0.000000",
+			},
+		},
+		{
+			name:      "200",
+			body:      policyBody,
+			policy:    policy,
+			setCalled: true,
+			setResult: &setPolicy,
+			status:    http.StatusOK,
+			expectRsp: &WalletPolicyResponse{
+				Policy: &WalletSpendingPolicy{
+					MaxCoinsPerTransaction: "1.000000",
+					MaxCoinsPerDay:         "10.000000",
+					AllowedDestinations: []WalletPolicyDestination{
+						{Address: addr.String(), AddedAt: 1600000000},
+					},
+					DestinationCooldown: 3600,
+					MinConfirmations:    6,
+				},
+				DailySpent: "0.000000",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.setCalled {
+				gateway.On("SetSpendingPolicy", "foo.wlt", []byte("pwd"), tc.policy).Return(tc.setResult, tc.setErr)
+				gateway.On("GetSpendingPolicy", "foo.wlt").Return(tc.setResult, nil, nil)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, http.MethodPost, "/api/v2/wallet/policy", tc.body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var policyRsp WalletPolicyResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &policyRsp))
+			require.Equal(t, *tc.expectRsp, policyRsp)
+			gateway.AssertExpectations(t)
+		})
+	}
+}
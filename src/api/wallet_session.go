@@ -0,0 +1,154 @@
+package api
+
+// APIs for wallet unlock sessions
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// WalletUnlockRequest is the request data for POST /api/v2/wallet/unlock
+type WalletUnlockRequest struct {
+	ID       string `json:"id"`
+	Password string `json:"password"`
+	// Timeout is the duration of the session in seconds, defaults to 300
+	Timeout uint64 `json:"timeout"`
+}
+
+// WalletUnlockResponse is the response data for POST /api/v2/wallet/unlock
+type WalletUnlockResponse struct {
+	ID string `json:"id"`
+	// Token can be used in place of the wallet password until the session expires
+	Token string `json:"token"`
+	// ExpiresAt is the unix time when the session expires
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+// WalletLockRequest is the request data for POST /api/v2/wallet/lock
+type WalletLockRequest struct {
+	ID string `json:"id"`
+}
+
+// walletSessionErrorResponse maps errors of the wallet session endpoints to a HTTPResponse
+func walletSessionErrorResponse(err error) HTTPResponse {
+	switch err {
+	case wallet.ErrWalletNotExist:
+		return NewHTTPErrorResponse(http.StatusNotFound, "")
+	case wallet.ErrWalletAPIDisabled:
+		return NewHTTPErrorResponse(http.StatusForbidden, "")
+	}
+
+	switch err.(type) {
+	case wallet.Error:
+		return NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+	default:
+		return NewHTTPErrorResponse(http.StatusInternalServerError, err.Error())
+	}
+}
+
+// Unlocks an encrypted wallet for a bounded time. The decrypted secrets are kept in memory
+// and the returned token can be used in place of the wallet password, until the session
+// expires or the wallet is locked. Unlocking a wallet again replaces its previous session.
+// Method: POST
+// URI: /api/v2/wallet/unlock
+// Args:
+//     id: wallet id [required]
+//     password: wallet password [required]
+//     timeout: duration of the session in seconds, at most 3600 [optional, default 300]
+func walletUnlockHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletUnlockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.Password == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "password is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		timeout := wallet.DefaultUnlockTimeout
+		if req.Timeout != 0 {
+			if req.Timeout > uint64(wallet.MaxUnlockTimeout/time.Second) {
+				writeHTTPResponse(w, walletSessionErrorResponse(wallet.ErrInvalidUnlockTimeout))
+				return
+			}
+			timeout = time.Duration(req.Timeout) * time.Second
+		}
+
+		password := []byte(req.Password)
+
+		defer func() {
+			req.Password = ""
+			password = nil
+		}()
+
+		session, err := gateway.UnlockWallet(req.ID, password, timeout)
+		if err != nil {
+			writeHTTPResponse(w, walletSessionErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{
+			Data: WalletUnlockResponse{
+				ID:        session.WalletID,
+				Token:     session.Token,
+				ExpiresAt: session.ExpiresAt.Unix(),
+			},
+		})
+	}
+}
+
+// Locks an unlocked wallet, ending its unlock session and erasing its secrets from memory.
+// Locking a wallet that is not unlocked does nothing.
+// Method: POST
+// URI: /api/v2/wallet/lock
+// Args:
+//     id: wallet id [required]
+func walletLockHandler(gateway Gatewayer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			resp := NewHTTPErrorResponse(http.StatusMethodNotAllowed, "")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		var req WalletLockRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, err.Error())
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if req.ID == "" {
+			resp := NewHTTPErrorResponse(http.StatusBadRequest, "id is required")
+			writeHTTPResponse(w, resp)
+			return
+		}
+
+		if err := gateway.LockWallet(req.ID); err != nil {
+			writeHTTPResponse(w, walletSessionErrorResponse(err))
+			return
+		}
+
+		writeHTTPResponse(w, HTTPResponse{})
+	}
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestWalletUnlock(t *testing.T) {
+	expiresAt := time.Unix(1600000300, 0)
+
+	tt := []struct {
+		name      string
+		method    string
+		body      string
+		timeout   time.Duration
+		unlockErr error
+		status    int
+		err       *HTTPError
+		expectRsp *WalletUnlockResponse
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - invalid json",
+			method: http.MethodPost,
+			body:   "{",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "unexpected EOF"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodPost,
+			body:   `{"password":"pwd"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - missing password",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt"}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "password is required"},
+		},
+		{
+			name:   "400 - timeout too long",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt","password":"pwd","timeout":3601}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "unlock timeout must be positive and at most 1h0m0s"},
+		},
+		{
+			name:      "400 - invalid password",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","password":"pwd"}`,
+			timeout:   wallet.DefaultUnlockTimeout,
+			unlockErr: wallet.ErrInvalidPassword,
+			status:    http.StatusBadRequest,
+			err:       &HTTPError{Code: http.StatusBadRequest, Message: "invalid password"},
+		},
+		{
+			name:      "400 - wallet not encrypted",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","password":"pwd"}`,
+			timeout:   wallet.DefaultUnlockTimeout,
+			unlockErr: wallet.ErrWalletNotEncrypted,
+			status:    http.StatusBadRequest,
+			err:       &HTTPError{Code: http.StatusBadRequest, Message: "wallet is not encrypted"},
+		},
+		{
+			name:      "403 - wallet api disabled",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","password":"pwd"}`,
+			timeout:   wallet.DefaultUnlockTimeout,
+			unlockErr: wallet.ErrWalletAPIDisabled,
+			status:    http.StatusForbidden,
+			err:       &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:      "404 - wallet not found",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","password":"pwd"}`,
+			timeout:   wallet.DefaultUnlockTimeout,
+			unlockErr: wallet.ErrWalletNotExist,
+			status:    http.StatusNotFound,
+			err:       &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:      "500 - unlock failed",
+			method:    http.MethodPost,
+			body:      `{"id":"foo.wlt","password":"pwd"}`,
+			timeout:   wallet.DefaultUnlockTimeout,
+			unlockErr: errors.New("missing crypto type"),
+			status:    http.StatusInternalServerError,
+			err:       &HTTPError{Code: http.StatusInternalServerError, Message: "missing crypto type"},
+		},
+		{
+			name:    "200 - default timeout",
+			method:  http.MethodPost,
+			body:    `{"id":"foo.wlt","password":"pwd"}`,
+			timeout: wallet.DefaultUnlockTimeout,
+			status:  http.StatusOK,
+			expectRsp: &WalletUnlockResponse{
+				ID:        "foo.wlt",
+				Token:     "token",
+				ExpiresAt: expiresAt.Unix(),
+			},
+		},
+		{
+			name:    "200",
+			method:  http.MethodPost,
+			body:    `{"id":"foo.wlt","password":"pwd","timeout":60}`,
+			timeout: time.Minute,
+			status:  http.StatusOK,
+			expectRsp: &WalletUnlockResponse{
+				ID:        "foo.wlt",
+				Token:     "token",
+				ExpiresAt: expiresAt.Unix(),
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.timeout != 0 {
+				var session *wallet.UnlockSession
+				if tc.unlockErr == nil {
+					session = &wallet.UnlockSession{
+						WalletID:  "foo.wlt",
+						Token:     "token",
+						ExpiresAt: expiresAt,
+					}
+				}
+				gateway.On("UnlockWallet", "foo.wlt", []byte("pwd"), tc.timeout).Return(session, tc.unlockErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/unlock", tc.body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			gateway.AssertExpectations(t)
+			if tc.err != nil {
+				return
+			}
+
+			var unlockRsp WalletUnlockResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &unlockRsp))
+			require.Equal(t, *tc.expectRsp, unlockRsp)
+		})
+	}
+}
+
+func TestWalletLock(t *testing.T) {
+	tt := []struct {
+		name    string
+		method  string
+		body    string
+		lockErr error
+		called  bool
+		status  int
+		err     *HTTPError
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - invalid json",
+			method: http.MethodPost,
+			body:   "{",
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "unexpected EOF"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodPost,
+			body:   `{}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:    "404 - wallet not found",
+			method:  http.MethodPost,
+			body:    `{"id":"foo.wlt"}`,
+			called:  true,
+			lockErr: wallet.ErrWalletNotExist,
+			status:  http.StatusNotFound,
+			err:     &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:    "403 - wallet api disabled",
+			method:  http.MethodPost,
+			body:    `{"id":"foo.wlt"}`,
+			called:  true,
+			lockErr: wallet.ErrWalletAPIDisabled,
+			status:  http.StatusForbidden,
+			err:     &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:   "200",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt"}`,
+			called: true,
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.called {
+				gateway.On("LockWallet", "foo.wlt").Return(tc.lockErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/lock", tc.body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			gateway.AssertExpectations(t)
+		})
+	}
+}
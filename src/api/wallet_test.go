@@ -3030,12 +3030,16 @@ func TestWalletRecover(t *testing.T) {
 	okWalletEncryptedResponse, err := NewWalletResponse(okWalletEncrypted)
 	require.NoError(t, err)
 
+	seedShares, err := wallet.SplitSeed("fooseed", 3, 2)
+	require.NoError(t, err)
+
 	cases := []struct {
 		name          string
 		method        string
 		status        int
 		contentType   string
 		req           *WalletRecoverRequest
+		recoverSeed   string
 		httpBody      string
 		httpResponse  HTTPResponse
 		gatewayReturn gatewayReturnPair
@@ -3084,6 +3088,40 @@ func TestWalletRecover(t *testing.T) {
 			},
 			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "seed is required"),
 		},
+		{
+			name:        "seed and seed shares",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:         "foo",
+				Seed:       "fooseed",
+				SeedShares: seedShares[:2],
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "seed and seed_shares cannot be combined"),
+		},
+		{
+			name:        "not enough seed shares",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:         "foo",
+				SeedShares: seedShares[:1],
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid seed_shares: not enough shares to reconstruct the secret"),
+		},
+		{
+			name:        "invalid seed share",
+			method:      http.MethodPost,
+			status:      http.StatusBadRequest,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:         "foo",
+				SeedShares: []string{seedShares[0], "foo"},
+			},
+			httpResponse: NewHTTPErrorResponse(http.StatusBadRequest, "invalid seed_shares: invalid share"),
+		},
 		{
 			name:        "wallet not encrypted",
 			method:      http.MethodPost,
@@ -3187,6 +3225,23 @@ func TestWalletRecover(t *testing.T) {
 				Data: *okWalletUnencryptedResponse,
 			},
 		},
+		{
+			name:        "ok, seed shares",
+			method:      http.MethodPost,
+			status:      http.StatusOK,
+			contentType: ContentTypeJSON,
+			req: &WalletRecoverRequest{
+				ID:         "foo",
+				SeedShares: []string{seedShares[2], seedShares[0]},
+			},
+			recoverSeed: "fooseed",
+			gatewayReturn: gatewayReturnPair{
+				w: okWalletUnencrypted,
+			},
+			httpResponse: HTTPResponse{
+				Data: *okWalletUnencryptedResponse,
+			},
+		},
 		{
 			name:        "ok, password",
 			method:      http.MethodPost,
@@ -3214,7 +3269,11 @@ func TestWalletRecover(t *testing.T) {
 				if tc.req.Password != "" {
 					password = []byte(tc.req.Password)
 				}
-				gateway.On("RecoverWallet", tc.req.ID, tc.req.Seed, tc.req.SeedPassphrase, password).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
+				seed := tc.req.Seed
+				if tc.recoverSeed != "" {
+					seed = tc.recoverSeed
+				}
+				gateway.On("RecoverWallet", tc.req.ID, seed, tc.req.SeedPassphrase, password).Return(tc.gatewayReturn.w, tc.gatewayReturn.err)
 			}
 
 			if tc.httpBody == "" && tc.req != nil {
@@ -3265,3 +3324,124 @@ func TestWalletRecover(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletSeedShares(t *testing.T) {
+	tt := []struct {
+		name           string
+		method         string
+		body           string
+		id             string
+		password       string
+		seed           string
+		seedPassphrase string
+		seedErr        error
+		status         int
+		err            *HTTPError
+		threshold      int
+		shares         int
+	}{
+		{
+			name:   "405",
+			method: http.MethodGet,
+			status: http.StatusMethodNotAllowed,
+			err:    &HTTPError{Code: http.StatusMethodNotAllowed, Message: "Method Not Allowed"},
+		},
+		{
+			name:   "400 - missing id",
+			method: http.MethodPost,
+			body:   `{"password":"pwd","shares":3,"threshold":2}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "id is required"},
+		},
+		{
+			name:   "400 - too many shares",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt","password":"pwd","shares":256,"threshold":2}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "shares must be between 2 and 255"},
+		},
+		{
+			name:   "400 - threshold too low",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt","password":"pwd","shares":3,"threshold":1}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "threshold must be between 2 and shares"},
+		},
+		{
+			name:   "400 - threshold greater than shares",
+			method: http.MethodPost,
+			body:   `{"id":"foo.wlt","password":"pwd","shares":3,"threshold":4}`,
+			status: http.StatusBadRequest,
+			err:    &HTTPError{Code: http.StatusBadRequest, Message: "threshold must be between 2 and shares"},
+		},
+		{
+			name:     "400 - invalid password",
+			method:   http.MethodPost,
+			body:     `{"id":"foo.wlt","password":"pwd","shares":3,"threshold":2}`,
+			id:       "foo.wlt",
+			password: "pwd",
+			seedErr:  wallet.ErrInvalidPassword,
+			status:   http.StatusBadRequest,
+			err:      &HTTPError{Code: http.StatusBadRequest, Message: "invalid password"},
+		},
+		{
+			name:     "403 - seed api disabled",
+			method:   http.MethodPost,
+			body:     `{"id":"foo.wlt","password":"pwd","shares":3,"threshold":2}`,
+			id:       "foo.wlt",
+			password: "pwd",
+			seedErr:  wallet.ErrSeedAPIDisabled,
+			status:   http.StatusForbidden,
+			err:      &HTTPError{Code: http.StatusForbidden, Message: "Forbidden"},
+		},
+		{
+			name:     "404 - wallet not found",
+			method:   http.MethodPost,
+			body:     `{"id":"foo.wlt","password":"pwd","shares":3,"threshold":2}`,
+			id:       "foo.wlt",
+			password: "pwd",
+			seedErr:  wallet.ErrWalletNotExist,
+			status:   http.StatusNotFound,
+			err:      &HTTPError{Code: http.StatusNotFound, Message: "Not Found"},
+		},
+		{
+			name:           "200",
+			method:         http.MethodPost,
+			body:           `{"id":"foo.wlt","password":"pwd","shares":5,"threshold":3}`,
+			id:             "foo.wlt",
+			password:       "pwd",
+			seed:           "voyage say extend find sheriff surge priority merit ignore maple cash argue",
+			seedPassphrase: "passphrase",
+			status:         http.StatusOK,
+			shares:         5,
+			threshold:      3,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway := &MockGatewayer{}
+			if tc.id != "" {
+				gateway.On("GetWalletSeed", tc.id, []byte(tc.password)).Return(tc.seed, tc.seedPassphrase, tc.seedErr)
+			}
+
+			status, rsp := doWalletAccountRequest(t, gateway, tc.method, "/api/v2/wallet/seed/shares", tc.body)
+			require.Equal(t, tc.status, status)
+			require.Equal(t, tc.err, rsp.Error)
+			if tc.err != nil {
+				return
+			}
+
+			var resp WalletSeedSharesResponse
+			require.NoError(t, json.Unmarshal(rsp.Data, &resp))
+			require.Len(t, resp.Shares, tc.shares)
+			require.Equal(t, tc.threshold, resp.Threshold)
+			require.Equal(t, tc.seedPassphrase, resp.SeedPassphrase)
+
+			// Any threshold shares recover the seed
+			seed, err := wallet.CombineSeedShares(resp.Shares[tc.shares-tc.threshold:])
+			require.NoError(t, err)
+			require.Equal(t, tc.seed, seed)
+		})
+	}
+}
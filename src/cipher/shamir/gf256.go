@@ -0,0 +1,71 @@
+package shamir
+
+// Arithmetic in GF(2^8) with the reducing polynomial x^8 + x^4 + x^3 + x + 1 (0x11b),
+// the field used by AES. Addition and subtraction are XOR.
+
+var (
+	expTable [255]byte
+	logTable [256]byte
+)
+
+func init() {
+	// 3 is a generator of the multiplicative group of the field
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		logTable[x] = byte(i)
+
+		// x *= 3, i.e. x = x*2 ^ x
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x = x2 ^ x
+	}
+}
+
+// mul multiplies two field elements
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])+int(logTable[b]))%255]
+}
+
+// div divides a by b, b must not be zero
+func div(a, b byte) byte {
+	if b == 0 {
+		panic("shamir: division by zero")
+	}
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])-int(logTable[b])+255)%255]
+}
+
+// evaluate evaluates the polynomial with coefficients coeffs, lowest degree first, at x
+func evaluate(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = mul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+// interpolate returns the value at x = 0 of the polynomial that passes through the points (xs[i], ys[i]).
+// The xs must be distinct.
+func interpolate(xs, ys []byte) byte {
+	var y byte
+	for i := range xs {
+		// Lagrange basis polynomial of xs[i], evaluated at 0
+		basis := byte(1)
+		for j := range xs {
+			if i == j {
+				continue
+			}
+			basis = mul(basis, div(xs[j], xs[j]^xs[i]))
+		}
+		y ^= mul(ys[i], basis)
+	}
+	return y
+}
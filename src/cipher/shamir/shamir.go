@@ -0,0 +1,216 @@
+/*
+Package shamir implements Shamir's secret sharing over GF(2^8).
+
+A secret is split into n shares, any k of which can reconstruct the secret,
+while fewer than k shares reveal nothing about it. Each byte of the secret is
+the constant term of a random polynomial of degree k-1, and share i holds the
+values of the polynomials at x = i.
+
+Shares are encoded as base58 strings carrying the share set identifier,
+the threshold, the share index and a checksum, so that mistyped shares and
+shares of different secrets are detected.
+*/
+package shamir
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/base58"
+)
+
+const (
+	// MaxShares is the maximum number of shares a secret can be split into
+	MaxShares = 255
+	// MinThreshold is the minimum number of shares required to reconstruct a secret
+	MinThreshold = 2
+
+	// shareVersion is the version of the share encoding
+	shareVersion byte = 1
+	// shareHeaderLen is the length of the version, id, threshold and index of an encoded share
+	shareHeaderLen = 5
+	// shareChecksumLen is the length of the checksum of an encoded share
+	shareChecksumLen = 4
+)
+
+var (
+	// ErrEmptySecret is returned when splitting an empty secret
+	ErrEmptySecret = errors.New("secret is empty")
+	// ErrInvalidThreshold is returned when the threshold is lower than MinThreshold or greater than the number of shares
+	ErrInvalidThreshold = fmt.Errorf("threshold must be at least %d and not greater than the number of shares", MinThreshold)
+	// ErrTooManyShares is returned when splitting a secret into more than MaxShares shares
+	ErrTooManyShares = fmt.Errorf("number of shares must not be greater than %d", MaxShares)
+	// ErrNotEnoughShares is returned when combining fewer shares than the threshold
+	ErrNotEnoughShares = errors.New("not enough shares to reconstruct the secret")
+	// ErrShareSetMismatch is returned when combining shares that were not split from the same secret
+	ErrShareSetMismatch = errors.New("shares do not belong to the same secret")
+	// ErrDuplicateShare is returned when combining the same share twice
+	ErrDuplicateShare = errors.New("duplicate share")
+	// ErrInvalidShare is returned when decoding a malformed share
+	ErrInvalidShare = errors.New("invalid share")
+	// ErrInvalidShareChecksum is returned when decoding a share with a wrong checksum
+	ErrInvalidShareChecksum = errors.New("invalid share checksum")
+	// ErrUnsupportedShareVersion is returned when decoding a share with an unknown version
+	ErrUnsupportedShareVersion = errors.New("unsupported share version")
+)
+
+// Share is a share of a secret
+type Share struct {
+	// ID identifies the shares split from the same secret
+	ID uint16
+	// Threshold is the number of shares required to reconstruct the secret
+	Threshold byte
+	// Index is the x coordinate of the share, from 1 to the number of shares
+	Index byte
+	// Value is the value of the polynomials at Index, it has the length of the secret
+	Value []byte
+}
+
+// Split splits secret into n shares, any k of which can reconstruct the secret
+func Split(secret []byte, n, k int) ([]Share, error) {
+	if len(secret) == 0 {
+		return nil, ErrEmptySecret
+	}
+
+	if n > MaxShares {
+		return nil, ErrTooManyShares
+	}
+
+	if k < MinThreshold || k > n {
+		return nil, ErrInvalidThreshold
+	}
+
+	id := binary.BigEndian.Uint16(cipher.RandByte(2))
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{
+			ID:        id,
+			Threshold: byte(k),
+			Index:     byte(i + 1),
+			Value:     make([]byte, len(secret)),
+		}
+	}
+
+	coeffs := make([]byte, k)
+	for i, b := range secret {
+		coeffs[0] = b
+		copy(coeffs[1:], cipher.RandByte(k-1))
+
+		for j := range shares {
+			shares[j].Value[i] = evaluate(coeffs, shares[j].Index)
+		}
+	}
+
+	// Wipe the coefficients, the first one is a byte of the secret
+	for i := range coeffs {
+		coeffs[i] = 0
+	}
+
+	return shares, nil
+}
+
+// Combine reconstructs a secret from its shares.
+// At least the threshold number of shares of the same secret are required.
+func Combine(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+
+	first := shares[0]
+	indexes := make(map[byte]struct{}, len(shares))
+	for _, s := range shares {
+		if s.ID != first.ID || s.Threshold != first.Threshold || len(s.Value) != len(first.Value) {
+			return nil, ErrShareSetMismatch
+		}
+
+		if s.Index == 0 || len(s.Value) == 0 {
+			return nil, ErrInvalidShare
+		}
+
+		if _, ok := indexes[s.Index]; ok {
+			return nil, ErrDuplicateShare
+		}
+		indexes[s.Index] = struct{}{}
+	}
+
+	if len(shares) < int(first.Threshold) {
+		return nil, ErrNotEnoughShares
+	}
+
+	// Exactly threshold shares determine the polynomials
+	shares = shares[:first.Threshold]
+
+	xs := make([]byte, len(shares))
+	ys := make([]byte, len(shares))
+	for i, s := range shares {
+		xs[i] = s.Index
+	}
+
+	secret := make([]byte, len(first.Value))
+	for i := range secret {
+		for j, s := range shares {
+			ys[j] = s.Value[i]
+		}
+		secret[i] = interpolate(xs, ys)
+	}
+
+	return secret, nil
+}
+
+// String encodes the share as a base58 string
+func (s Share) String() string {
+	b := make([]byte, shareHeaderLen, shareHeaderLen+len(s.Value)+shareChecksumLen)
+	b[0] = shareVersion
+	binary.BigEndian.PutUint16(b[1:3], s.ID)
+	b[3] = s.Threshold
+	b[4] = s.Index
+	b = append(b, s.Value...)
+	b = append(b, shareChecksum(b)...)
+	return base58.Encode(b)
+}
+
+// ParseShare decodes a share encoded by Share.String
+func ParseShare(s string) (Share, error) {
+	b, err := base58.Decode(s)
+	if err != nil {
+		return Share{}, ErrInvalidShare
+	}
+
+	if len(b) <= shareHeaderLen+shareChecksumLen {
+		return Share{}, ErrInvalidShare
+	}
+
+	data := b[:len(b)-shareChecksumLen]
+	if !bytes.Equal(shareChecksum(data), b[len(data):]) {
+		return Share{}, ErrInvalidShareChecksum
+	}
+
+	if data[0] != shareVersion {
+		return Share{}, ErrUnsupportedShareVersion
+	}
+
+	share := Share{
+		ID:        binary.BigEndian.Uint16(data[1:3]),
+		Threshold: data[3],
+		Index:     data[4],
+		Value:     append([]byte{}, data[shareHeaderLen:]...),
+	}
+
+	if share.Index == 0 || share.Threshold < MinThreshold {
+		return Share{}, ErrInvalidShare
+	}
+
+	return share, nil
+}
+
+// shareChecksum returns the checksum of an encoded share, the first bytes of its double SHA256 hash
+func shareChecksum(b []byte) []byte {
+	h := sha256.Sum256(b)
+	h = sha256.Sum256(h[:])
+	return h[:shareChecksumLen]
+}
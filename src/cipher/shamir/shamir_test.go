@@ -0,0 +1,154 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/base58"
+)
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			p := mul(byte(a), byte(b))
+			require.NotEqual(t, byte(0), p)
+			require.Equal(t, byte(a), div(p, byte(b)))
+		}
+	}
+
+	// Known product in the AES field
+	require.Equal(t, byte(0xc1), mul(0x57, 0x83))
+	require.Equal(t, byte(0), mul(0, 0x83))
+}
+
+// combinations returns all subsets of size k of shares
+func combinations(shares []Share, k int) [][]Share {
+	if k == 0 {
+		return [][]Share{{}}
+	}
+	if len(shares) < k {
+		return nil
+	}
+
+	var cs [][]Share
+	for _, c := range combinations(shares[1:], k-1) {
+		cs = append(cs, append([]Share{shares[0]}, c...))
+	}
+	return append(cs, combinations(shares[1:], k)...)
+}
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("voyage say extend find sheriff surge priority merit ignore maple cash argue")
+
+	for _, p := range []struct{ n, k int }{
+		{2, 2},
+		{3, 2},
+		{5, 3},
+		{6, 6},
+	} {
+		shares, err := Split(secret, p.n, p.k)
+		require.NoError(t, err)
+		require.Len(t, shares, p.n)
+
+		for i, s := range shares {
+			require.Equal(t, shares[0].ID, s.ID)
+			require.Equal(t, byte(p.k), s.Threshold)
+			require.Equal(t, byte(i+1), s.Index)
+			require.Len(t, s.Value, len(secret))
+		}
+
+		// Any k or more shares reconstruct the secret
+		for k := p.k; k <= p.n; k++ {
+			for _, c := range combinations(shares, k) {
+				s, err := Combine(c)
+				require.NoError(t, err)
+				require.Equal(t, secret, s)
+			}
+		}
+
+		// Fewer than k shares are refused
+		_, err = Combine(shares[:p.k-1])
+		require.Equal(t, ErrNotEnoughShares, err)
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	_, err := Split(nil, 3, 2)
+	require.Equal(t, ErrEmptySecret, err)
+
+	_, err = Split([]byte("secret"), 3, 1)
+	require.Equal(t, ErrInvalidThreshold, err)
+
+	_, err = Split([]byte("secret"), 3, 4)
+	require.Equal(t, ErrInvalidThreshold, err)
+
+	_, err = Split([]byte("secret"), 256, 2)
+	require.Equal(t, ErrTooManyShares, err)
+}
+
+func TestCombineErrors(t *testing.T) {
+	shares, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+
+	other, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+	other[1].ID = shares[0].ID + 1
+
+	_, err = Combine(nil)
+	require.Equal(t, ErrNotEnoughShares, err)
+
+	_, err = Combine([]Share{shares[0], shares[0]})
+	require.Equal(t, ErrDuplicateShare, err)
+
+	_, err = Combine([]Share{shares[0], other[1]})
+	require.Equal(t, ErrShareSetMismatch, err)
+
+	short := shares[1]
+	short.Value = short.Value[1:]
+	_, err = Combine([]Share{shares[0], short})
+	require.Equal(t, ErrShareSetMismatch, err)
+}
+
+func TestShareEncoding(t *testing.T) {
+	secret := cipher.RandByte(16)
+	shares, err := Split(secret, 3, 2)
+	require.NoError(t, err)
+
+	encoded := make([]string, len(shares))
+	for i, s := range shares {
+		encoded[i] = s.String()
+
+		ps, err := ParseShare(encoded[i])
+		require.NoError(t, err)
+		require.Equal(t, s, ps)
+	}
+
+	require.NotEqual(t, encoded[0], encoded[1])
+
+	// A typo is detected by the checksum
+	b := []byte(encoded[0])
+	if b[10] == '2' {
+		b[10] = '3'
+	} else {
+		b[10] = '2'
+	}
+	_, err = ParseShare(string(b))
+	require.Equal(t, ErrInvalidShareChecksum, err)
+
+	_, err = ParseShare("")
+	require.Equal(t, ErrInvalidShare, err)
+
+	_, err = ParseShare("0OIl")
+	require.Equal(t, ErrInvalidShare, err)
+
+	_, err = ParseShare("2g")
+	require.Equal(t, ErrInvalidShare, err)
+
+	// Unknown versions are refused
+	b = append([]byte{2, 0, 1, 2, 1, 0xff}, 0, 0, 0, 0)
+	copy(b[6:], shareChecksum(b[:6]))
+	_, err = ParseShare(base58.Encode(b))
+	require.Equal(t, ErrUnsupportedShareVersion, err)
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func addressStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Short:                 "Get the stats of an address",
+		Long:                  "Returns the first and last blocks with transactions of an address, its number of transactions and the coins and hours it received and sent.",
+		Use:                   "addressStats [address]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if _, err := cipher.DecodeBase58Address(args[0]); err != nil {
+				return err
+			}
+
+			stats, err := apiClient.AddressStats(args[0])
+			if err != nil {
+				return err
+			}
+
+			return printJSON(stats)
+		},
+	}
+}
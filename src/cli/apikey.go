@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func apiKeysCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "apiKeys",
+		Short: "List the API keys of the node",
+		Long: `List the API keys of the node. API keys are enabled with the
+    -web-interface-api-keys option of the node, and can only be managed
+    with the web interface username and password, set with RPC_USER and
+    RPC_PASS.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			keys, err := apiClient.APIKeys()
+			if err != nil {
+				return err
+			}
+
+			return printJSON(keys)
+		},
+	}
+}
+
+func apiKeyCreateCmd() *cobra.Command {
+	apiKeyCreateCmd := &cobra.Command{
+		Args:  cobra.NoArgs,
+		Use:   "apiKeyCreate",
+		Short: "Create an API key scoped to API sets and wallets",
+		Long: `Create an API key scoped to API sets and, optionally, to wallets.
+    The key can only access the API sets that are also enabled on the node.
+    The secret of the key is only printed once and can't be recovered.
+
+    Requests are made with the key by sending its id and secret as the
+    username and password of the "Authorization: Basic" header, e.g. with
+    RPC_USER and RPC_PASS.`,
+		Example: `skycoin-cli apiKeyCreate --label payouts --api-sets WALLET,READ --wallets payouts.wlt --expires 720h`,
+		RunE: func(c *cobra.Command, args []string) error {
+			label, err := c.Flags().GetString("label")
+			if err != nil {
+				return err
+			}
+
+			apiSets, err := c.Flags().GetStringSlice("api-sets")
+			if err != nil {
+				return err
+			}
+			for i, s := range apiSets {
+				apiSets[i] = strings.ToUpper(s)
+			}
+
+			wallets, err := c.Flags().GetStringSlice("wallets")
+			if err != nil {
+				return err
+			}
+
+			expires, err := c.Flags().GetDuration("expires")
+			if err != nil {
+				return err
+			}
+			if expires < 0 {
+				return fmt.Errorf("invalid expiry %s", expires)
+			}
+
+			rsp, err := apiClient.CreateAPIKey(api.APIKeyCreateRequest{
+				Label:     label,
+				APISets:   apiSets,
+				Wallets:   wallets,
+				ExpiresIn: uint64(expires / time.Second),
+			})
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+
+	apiKeyCreateCmd.Flags().String("label", "", "label of the key")
+	apiKeyCreateCmd.Flags().StringSlice("api-sets", nil, "comma separated API sets that the key can access, e.g. READ,STATUS")
+	apiKeyCreateCmd.Flags().StringSlice("wallets", nil, "comma separated wallets that the key can access. All wallets if empty")
+	apiKeyCreateCmd.Flags().Duration("expires", 0, "lifetime of the key, e.g. 720h. The key does not expire if zero")
+
+	return apiKeyCreateCmd
+}
+
+func apiKeyRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "apiKeyRevoke [id]",
+		Short: "Revoke an API key",
+		Long:  "Revoke an API key. Requests made with the key are rejected from then on.",
+		RunE: func(c *cobra.Command, args []string) error {
+			rsp, err := apiClient.RevokeAPIKey(args[0])
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+}
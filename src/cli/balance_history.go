@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func balanceAtCmd() *cobra.Command {
+	balanceAtCmd := &cobra.Command{
+		Short: "Show the confirmed balance of addresses or of a wallet as of a past block",
+		Use:   "balanceAt [addresses]",
+		Long: `Show the confirmed balance of addresses or of a wallet after a block was executed,
+    with the coin hours at the time of the block. The block is selected with --seq, or with
+    --time as the last block created at or before the time. The head block is selected by default.
+    example: balanceAt --time 2020-03-31T23:59:59Z "$addr1 $addr2"`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			wlt, err := c.Flags().GetString("wallet")
+			if err != nil {
+				return err
+			}
+
+			var reqArgs []api.RequestArg
+			seq, err := c.Flags().GetString("seq")
+			if err != nil {
+				return err
+			}
+			if seq != "" {
+				if _, err := strconv.ParseUint(seq, 10, 64); err != nil {
+					return errors.New("invalid seq")
+				}
+				reqArgs = append(reqArgs, api.RequestArg{Key: "seq", Value: seq})
+			}
+
+			t, err := c.Flags().GetString("time")
+			if err != nil {
+				return err
+			}
+			if t != "" {
+				reqArgs = append(reqArgs, api.RequestArg{Key: "time", Value: t})
+			}
+
+			var rsp *api.BalanceAtResponse
+			if wlt != "" {
+				if len(args) != 0 {
+					return errors.New("addresses and --wallet cannot be combined")
+				}
+				rsp, err = apiClient.WalletBalanceAt(wlt, reqArgs...)
+			} else {
+				if len(args) == 0 {
+					return errors.New("addresses or --wallet is required")
+				}
+				rsp, err = apiClient.BalanceAt(args, reqArgs...)
+			}
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+
+	balanceAtCmd.Flags().StringP("wallet", "w", "", "Show the balance of all addresses of this wallet")
+	balanceAtCmd.Flags().String("seq", "", "Seq of the block")
+	balanceAtCmd.Flags().String("time", "", "Unix time or RFC3339 time, selects the last block created at or before the time")
+
+	return balanceAtCmd
+}
+
+func balanceSeriesCmd() *cobra.Command {
+	balanceSeriesCmd := &cobra.Command{
+		Short: "Show the confirmed balance of addresses or of a wallet at the end of each bucket of a period",
+		Use:   "balanceSeries [addresses]",
+		Long: `Show the confirmed balance of addresses or of a wallet at the end of each hour, day, week,
+    month, quarter or year of a period, in UTC. The period starts at the genesis block and ends
+    at the head block by default.
+    example: balanceSeries --bucket quarter --start 2019-01-01T00:00:00Z "$addr1 $addr2"`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			wlt, err := c.Flags().GetString("wallet")
+			if err != nil {
+				return err
+			}
+
+			bucket, err := c.Flags().GetString("bucket")
+			if err != nil {
+				return err
+			}
+
+			var reqArgs []api.RequestArg
+			for _, name := range []string{"start", "end"} {
+				v, err := c.Flags().GetString(name)
+				if err != nil {
+					return err
+				}
+				if v != "" {
+					reqArgs = append(reqArgs, api.RequestArg{Key: name, Value: v})
+				}
+			}
+
+			var rsp *api.BalanceSeriesResponse
+			if wlt != "" {
+				if len(args) != 0 {
+					return errors.New("addresses and --wallet cannot be combined")
+				}
+				rsp, err = apiClient.WalletBalanceSeries(wlt, bucket, reqArgs...)
+			} else {
+				if len(args) == 0 {
+					return errors.New("addresses or --wallet is required")
+				}
+				rsp, err = apiClient.BalanceSeries(args, bucket, reqArgs...)
+			}
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+
+	balanceSeriesCmd.Flags().StringP("wallet", "w", "", "Show the balance of all addresses of this wallet")
+	balanceSeriesCmd.Flags().StringP("bucket", "b", "day", "Bucket of the series: hour, day, week, month, quarter or year")
+	balanceSeriesCmd.Flags().String("start", "", "Unix time or RFC3339 time of the start of the series")
+	balanceSeriesCmd.Flags().String("end", "", "Unix time or RFC3339 time of the end of the series")
+
+	return balanceSeriesCmd
+}
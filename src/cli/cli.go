@@ -41,8 +41,8 @@ const (
 var (
 	envVarsHelp = fmt.Sprintf(`ENVIRONMENT VARIABLES:
     RPC_ADDR: Address of RPC node. Must be in scheme://host format. Default "%s"
-    RPC_USER: Username for RPC API, if enabled in the RPC.
-    RPC_PASS: Password for RPC API, if enabled in the RPC.
+    RPC_USER: Username for RPC API, if enabled in the RPC, or the id of an API key.
+    RPC_PASS: Password for RPC API, if enabled in the RPC, or the secret of an API key.
     COIN: Name of the coin. Default "%s"
     DATA_DIR: Directory where everything is stored. Default "%s"`, defaultRPCAddress, defaultCoin, defaultDataDir)
 
@@ -198,6 +198,7 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		sendCmd(),
 		showConfigCmd(),
 		showSeedCmd(),
+		showSeedSharesCmd(),
 		statusCmd(),
 		transactionCmd(),
 		verifyTransactionCmd(),
@@ -207,15 +208,39 @@ func NewCLI(cfg Config) (*cobra.Command, error) {
 		walletCreateTempCmd(),
 		walletAddAddressesCmd(),
 		walletScanAddressesCmd(),
+		walletRecoverCmd(),
 		walletKeyExportCmd(),
 		walletBalanceCmd(),
 		walletHisCmd(),
 		walletOutputsCmd(),
+		walletAccountsCmd(),
+		walletAccountCreateCmd(),
+		walletAccountRenameCmd(),
+		walletAccountBalanceCmd(),
+		walletAccountAddAddressesCmd(),
+		walletAddressLabelCmd(),
+		walletPolicyCmd(),
+		walletPolicySetCmd(),
+		walletUnlockCmd(),
+		walletLockCmd(),
+		walletExportCmd(),
+		walletImportCmd(),
+		walletMigrateCmd(),
 		richlistCmd(),
 		addressTransactionsCmd(),
+		addressStatsCmd(),
+		balanceAtCmd(),
+		balanceSeriesCmd(),
+		searchCmd(),
+		exportChainCmd(),
 		pendingTransactionsCmd(),
+		mempoolStatsCmd(),
 		addresscountCmd(),
 		distributeGenesisCmd(),
+		rpcCmd(),
+		apiKeysCmd(),
+		apiKeyCreateCmd(),
+		apiKeyRevokeCmd(),
 	}
 
 	skyCLI.Version = Version
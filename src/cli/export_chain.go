@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/util/apputil"
+	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+const exportCheckpointFilename = "checkpoint.json"
+
+// exportCheckpoint records the progress of a chain export, so that it can be resumed.
+// Offsets are the sizes of the table files after the last exported block; data written
+// after them is discarded when the export is resumed.
+type exportCheckpoint struct {
+	NextSeq uint64           `json:"next_seq"`
+	Format  api.ExportFormat `json:"format"`
+	Tables  []string         `json:"tables"`
+	Offsets map[string]int64 `json:"offsets"`
+}
+
+// exportTableFile is a table file of a chain export
+type exportTableFile struct {
+	table api.ExportTable
+	f     *os.File
+	buf   *bufio.Writer
+	ew    *api.ExportWriter
+}
+
+func exportChainCmd() *cobra.Command {
+	exportChainCmd := &cobra.Command{
+		Short: "Export the blockchain to JSONL or CSV files",
+		Use:   "exportChain [db path]",
+		Long: `Exports the blocks, transactions, inputs and outputs of a database to one file per table
+    in the export directory, for loading into analytics databases. Input coin hours are resolved.
+    The progress is saved to checkpoint.json in the export directory, and running the command again
+    with the same directory resumes the export, appending the blocks added since.
+    The database must not be opened by a running node.
+    If no argument is specificed, the default data.db in $HOME/.$COIN/ will be exported.`,
+		Args:         cobra.MaximumNArgs(1),
+		SilenceUsage: true,
+		RunE:         exportChain,
+	}
+
+	exportChainCmd.Flags().StringP("dir", "d", ".", "directory to write the table files to")
+	exportChainCmd.Flags().StringP("format", "f", string(api.ExportFormatJSONL), "output format, jsonl or csv")
+	exportChainCmd.Flags().StringSlice("tables", []string{"blocks", "transactions", "inputs", "outputs"}, "comma separated tables to export")
+	exportChainCmd.Flags().Uint64("start", 0, "seq of the first block to export. Cannot be used when resuming an export")
+	exportChainCmd.Flags().Uint64("end", 0, "seq of the last block to export [default: the head block]")
+
+	return exportChainCmd
+}
+
+func exportChain(c *cobra.Command, args []string) error {
+	dir, err := c.Flags().GetString("dir")
+	if err != nil {
+		return err
+	}
+
+	format, err := c.Flags().GetString("format")
+	if err != nil {
+		return err
+	}
+
+	tables, err := c.Flags().GetStringSlice("tables")
+	if err != nil {
+		return err
+	}
+	if len(tables) == 0 {
+		return fmt.Errorf("no tables to export")
+	}
+	for _, t := range tables {
+		if !isExportTable(t) {
+			return fmt.Errorf("invalid table %q, must be blocks, transactions, inputs or outputs", t)
+		}
+	}
+
+	switch api.ExportFormat(format) {
+	case api.ExportFormatJSONL, api.ExportFormatCSV:
+	default:
+		return fmt.Errorf("invalid format %q, must be jsonl or csv", format)
+	}
+
+	start, err := c.Flags().GetUint64("start")
+	if err != nil {
+		return err
+	}
+
+	end := ^uint64(0)
+	if c.Flags().Changed("end") {
+		end, err = c.Flags().GetUint64("end")
+		if err != nil {
+			return err
+		}
+	}
+
+	dbPath := ""
+	if len(args) > 0 {
+		dbPath = args[0]
+	}
+	dbPath, err = resolveDBPath(cliConfig, dbPath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		return fmt.Errorf("db file: %v does not exist", dbPath)
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	checkpointPath := filepath.Join(dir, exportCheckpointFilename)
+	checkpoint := exportCheckpoint{
+		NextSeq: start,
+		Format:  api.ExportFormat(format),
+		Tables:  tables,
+	}
+	resume, err := file.Exists(checkpointPath)
+	if err != nil {
+		return err
+	}
+	if resume {
+		if c.Flags().Changed("start") {
+			return fmt.Errorf("%s has a checkpoint, --start cannot be used when resuming an export", dir)
+		}
+
+		if err := file.LoadJSON(checkpointPath, &checkpoint); err != nil {
+			return fmt.Errorf("load checkpoint failed: %v", err)
+		}
+
+		if string(checkpoint.Format) != format || strings.Join(checkpoint.Tables, ",") != strings.Join(tables, ",") {
+			return fmt.Errorf("%s was exported with --format %s --tables %s", dir, checkpoint.Format, strings.Join(checkpoint.Tables, ","))
+		}
+	}
+
+	files, err := openExportTableFiles(dir, &checkpoint, resume)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, tf := range files {
+			tf.f.Close()
+		}
+	}()
+
+	db, err := bolt.Open(dbPath, 0600, &bolt.Options{
+		Timeout:  5 * time.Second,
+		ReadOnly: true,
+	})
+	if err != nil {
+		return fmt.Errorf("open db failed: %v", err)
+	}
+	defer db.Close()
+
+	go func() {
+		apputil.CatchInterrupt(quitChan)
+	}()
+
+	firstSeq := checkpoint.NextSeq
+	err = visor.ExportDatabase(wrapDB(db), checkpoint.NextSeq, end, quitChan, func(b *visor.ExportedBlock) error {
+		rows, err := api.NewExportRows(b)
+		if err != nil {
+			return err
+		}
+
+		for _, tf := range files {
+			if err := tf.ew.Write(rows); err != nil {
+				return err
+			}
+		}
+
+		checkpoint.NextSeq = rows.Block.Seq + 1
+		if checkpoint.NextSeq%visor.ExportChunkSize != 0 {
+			return nil
+		}
+
+		return saveExportCheckpoint(checkpointPath, &checkpoint, files)
+	})
+
+	switch err {
+	case nil, visor.ErrExportStopped:
+	case visor.ErrExportStartNotFound:
+		if resume {
+			fmt.Printf("%s is up to date at block %d\n", dir, checkpoint.NextSeq)
+			return nil
+		}
+		return err
+	default:
+		return fmt.Errorf("export failed: %v", err)
+	}
+
+	if err := saveExportCheckpoint(checkpointPath, &checkpoint, files); err != nil {
+		return err
+	}
+
+	if checkpoint.NextSeq == firstSeq {
+		fmt.Println("no blocks exported")
+		return nil
+	}
+
+	fmt.Printf("exported blocks %d to %d to %s\n", firstSeq, checkpoint.NextSeq-1, dir)
+	return nil
+}
+
+// openExportTableFiles opens the table files of an export. When resuming, the files are
+// truncated to the checkpoint offsets. Otherwise the files must not exist.
+func openExportTableFiles(dir string, checkpoint *exportCheckpoint, resume bool) ([]exportTableFile, error) {
+	files := make([]exportTableFile, 0, len(checkpoint.Tables))
+	closeFiles := func() {
+		for _, tf := range files {
+			tf.f.Close()
+		}
+	}
+
+	for _, t := range checkpoint.Tables {
+		table := api.ExportTable(t)
+		path := filepath.Join(dir, fmt.Sprintf("%s.%s", table, checkpoint.Format))
+
+		flags := os.O_RDWR | os.O_CREATE
+		if !resume {
+			flags |= os.O_EXCL
+		}
+
+		f, err := os.OpenFile(path, flags, 0600)
+		if err != nil {
+			closeFiles()
+			return nil, err
+		}
+
+		offset := checkpoint.Offsets[t]
+		if err := f.Truncate(offset); err != nil {
+			f.Close()
+			closeFiles()
+			return nil, err
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			closeFiles()
+			return nil, err
+		}
+
+		buf := bufio.NewWriter(f)
+		ew, err := api.NewExportWriter(buf, table, checkpoint.Format, offset == 0)
+		if err != nil {
+			f.Close()
+			closeFiles()
+			return nil, err
+		}
+
+		files = append(files, exportTableFile{
+			table: table,
+			f:     f,
+			buf:   buf,
+			ew:    ew,
+		})
+	}
+
+	return files, nil
+}
+
+// saveExportCheckpoint flushes the table files to disk and saves their offsets to the checkpoint
+func saveExportCheckpoint(path string, checkpoint *exportCheckpoint, files []exportTableFile) error {
+	offsets := make(map[string]int64, len(files))
+	for _, tf := range files {
+		if err := tf.ew.Flush(); err != nil {
+			return err
+		}
+		if err := tf.buf.Flush(); err != nil {
+			return err
+		}
+		if err := tf.f.Sync(); err != nil {
+			return err
+		}
+
+		offset, err := tf.f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		offsets[string(tf.table)] = offset
+	}
+
+	checkpoint.Offsets = offsets
+	return file.SaveJSON(path, checkpoint, 0600)
+}
+
+func isExportTable(t string) bool {
+	for _, table := range api.ExportTables {
+		if string(table) == t {
+			return true
+		}
+	}
+	return false
+}
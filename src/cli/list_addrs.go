@@ -7,22 +7,72 @@ import (
 )
 
 func listAddressesCmd() *cobra.Command {
-	return &cobra.Command{
-		Short:                 "Lists all addresses in a given wallet",
-		Use:                   "listAddresses [wallet]",
-		Args:                  cobra.ExactArgs(1),
-		DisableFlagsInUseLine: true,
-		SilenceUsage:          true,
-		RunE:                  listAddresses,
+	listAddressesCmd := &cobra.Command{
+		Short: "Lists all addresses in a given wallet",
+		Use:   "listAddresses [wallet]",
+		Long: `Lists all addresses in a given wallet.
+
+    Use the --label and --tag options to search the addresses by label
+    or by tag, and the --verbose option to show the labels, tags and notes
+    of the addresses.`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         listAddresses,
 	}
+
+	listAddressesCmd.Flags().StringP("label", "l", "", "only list the addresses whose label contains this text, case insensitively")
+	listAddressesCmd.Flags().StringP("tag", "t", "", "only list the addresses that have this tag")
+	listAddressesCmd.Flags().BoolP("verbose", "v", false, "show the labels, tags and notes of the addresses")
+
+	return listAddressesCmd
 }
 
-func listAddresses(_ *cobra.Command, args []string) error {
-	addrs, err := getWalletAddresses(args[0])
+func listAddresses(c *cobra.Command, args []string) error {
+	label, err := c.Flags().GetString("label")
+	if err != nil {
+		return err
+	}
+
+	tag, err := c.Flags().GetString("tag")
+	if err != nil {
+		return err
+	}
+
+	verbose, err := c.Flags().GetBool("verbose")
+	if err != nil {
+		return err
+	}
+
+	if label == "" && tag == "" && !verbose {
+		addrs, err := getWalletAddresses(args[0])
+		if err != nil {
+			return err
+		}
+
+		s, err := FormatAddressesAsJSON(addrs)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(s)
+
+		return nil
+	}
+
+	rsp, err := apiClient.WalletAddresses(args[0], label, tag)
 	if err != nil {
 		return err
 	}
 
+	if verbose {
+		return printJSON(rsp)
+	}
+
+	addrs := make([]string, len(rsp.Entries))
+	for i, e := range rsp.Entries {
+		addrs[i] = e.Address
+	}
+
 	s, err := FormatAddressesAsJSON(addrs)
 	if err != nil {
 		return err
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func rpcCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.RangeArgs(1, 2),
+		Use:   "rpc [method] [params]",
+		Short: "Call a method of the JSON-RPC 2.0 interface of the node",
+		Long: `Call a method of the JSON-RPC 2.0 interface of the node and print its
+    result. The params are a JSON object of the named params of the method.
+    Methods are enabled by the same API sets as their REST endpoints.`,
+		Example: `skycoin-cli rpc block '{"seq":10}'`,
+		RunE: func(c *cobra.Command, args []string) error {
+			var params interface{}
+			if len(args) == 2 {
+				if !json.Valid([]byte(args[1])) {
+					return fmt.Errorf("invalid params: %s", args[1])
+				}
+				params = json.RawMessage(args[1])
+			}
+
+			var result json.RawMessage
+			if err := apiClient.RPC(args[0], params, &result); err != nil {
+				return err
+			}
+
+			return printJSON(result)
+		},
+	}
+}
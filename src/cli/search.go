@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func searchCmd() *cobra.Command {
+	return &cobra.Command{
+		Short: "Find the blocks, transactions, outputs and addresses that a query refers to",
+		Long: `Classifies the query as a block seq, a block hash, a transaction ID, an output ID or an address,
+    and returns the blocks, transactions (confirmed or unconfirmed), outputs and addresses that it refers to.
+    A hex string of at least 6 characters that is shorter than a hash matches the hashes that start with it.`,
+		Use:                   "search [query]",
+		Args:                  cobra.ExactArgs(1),
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			rsp, err := apiClient.Search(args[0])
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+}
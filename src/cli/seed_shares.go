@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func showSeedSharesCmd() *cobra.Command {
+	showSeedSharesCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "showSeedShares [wallet]",
+		Short: "Split the wallet seed into Shamir secret shares",
+		Long: `Split the seed of a wallet into n Shamir secret shares, any k of
+    which can recover the wallet with walletRecover, while fewer than k shares
+    reveal nothing about the seed. Give each share to a different custodian.
+
+    The seed passphrase is not split, it is printed after the shares.
+
+    Use caution when using the "-p" command. If you have command history enabled
+    your wallet encryption password can be recovered from the history log. If you
+    do not include the "-p" option you will be prompted to enter your password
+    after you enter your command.`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			id := args[0]
+
+			password, err := c.Flags().GetString("password")
+			if err != nil {
+				return err
+			}
+
+			n, err := c.Flags().GetInt("shares")
+			if err != nil {
+				return err
+			}
+
+			k, err := c.Flags().GetInt("threshold")
+			if err != nil {
+				return err
+			}
+
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			wlt, err := apiClient.Wallet(id)
+			if err != nil {
+				return err
+			}
+
+			var pwd []byte
+			if wlt.Meta.Encrypted {
+				pwd, err = NewPasswordReader([]byte(password)).Password()
+				if err != nil {
+					return err
+				}
+			}
+
+			rsp, err := apiClient.WalletSeedShares(api.WalletSeedSharesRequest{
+				ID:        id,
+				Password:  string(pwd),
+				Shares:    n,
+				Threshold: k,
+			})
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				return printJSON(rsp)
+			}
+
+			for _, s := range rsp.Shares {
+				fmt.Println(s)
+			}
+			if rsp.SeedPassphrase != "" {
+				fmt.Println(rsp.SeedPassphrase)
+			}
+			return nil
+		},
+	}
+
+	showSeedSharesCmd.Flags().StringP("password", "p", "", "Wallet password")
+	showSeedSharesCmd.Flags().IntP("shares", "n", 0, "Number of shares")
+	showSeedSharesCmd.Flags().IntP("threshold", "k", 0, "Number of shares required to recover the seed")
+	showSeedSharesCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format.")
+
+	return showSeedSharesCmd
+}
+
+func walletRecoverCmd() *cobra.Command {
+	walletRecoverCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "walletRecover [wallet]",
+		Short: "Recover an encrypted wallet from its seed or its seed shares",
+		Long: `Recover an encrypted wallet, whose password was lost, from its seed
+    or from enough Shamir secret shares of its seed, created by showSeedShares.
+    The wallet is encrypted with the new password if one is given.
+
+    Use caution when using the "-s" and "-p" options. If you have command history
+    enabled the seed and the wallet encryption password can be recovered from the
+    history log.`,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			seed, err := c.Flags().GetString("seed")
+			if err != nil {
+				return err
+			}
+
+			shares, err := c.Flags().GetStringSlice("shares")
+			if err != nil {
+				return err
+			}
+
+			if seed == "" && len(shares) == 0 {
+				return errors.New("seed or shares is required")
+			}
+
+			seedPassphrase, err := c.Flags().GetString("seed-passphrase")
+			if err != nil {
+				return err
+			}
+
+			password, err := c.Flags().GetString("password")
+			if err != nil {
+				return err
+			}
+
+			wlt, err := apiClient.RecoverWallet(api.WalletRecoverRequest{
+				ID:             args[0],
+				Seed:           seed,
+				SeedShares:     shares,
+				SeedPassphrase: seedPassphrase,
+				Password:       password,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printJSON(wlt)
+		},
+	}
+
+	walletRecoverCmd.Flags().StringP("seed", "s", "", "Wallet seed")
+	walletRecoverCmd.Flags().StringSlice("shares", nil, "Comma separated Shamir secret shares of the wallet seed")
+	walletRecoverCmd.Flags().String("seed-passphrase", "", "Wallet seed passphrase")
+	walletRecoverCmd.Flags().StringP("password", "p", "", "New wallet password")
+
+	return walletRecoverCmd
+}
@@ -343,6 +343,27 @@ func pendingTransactionsCmd() *cobra.Command {
 	return pendingTxnsCmd
 }
 
+func mempoolStatsCmd() *cobra.Command {
+	return &cobra.Command{
+		Short: "Get aggregated statistics of the unconfirmed transactions",
+		Long: `Shows the count and size of the unconfirmed transactions, a histogram of their fee rates,
+    the distribution of their ages, the number of invalid transactions by reason, the oldest transaction
+    and the projected number of blocks to confirm the valid transactions.`,
+		Use:                   "mempoolStats",
+		DisableFlagsInUseLine: true,
+		SilenceUsage:          true,
+		Args:                  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			stats, err := apiClient.MempoolStats()
+			if err != nil {
+				return err
+			}
+
+			return printJSON(stats)
+		},
+	}
+}
+
 func signTxnCmd() *cobra.Command {
 	signTxnCmd := &cobra.Command{
 		Short:                 "Sign an unsigned transaction with specific wallet",
@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func walletAccountsCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "walletAccounts [wallet]",
+		Short: "List the accounts of a bip44 wallet",
+		Long: `List the accounts of a bip44 wallet, with the addresses of
+    their external and change chains.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			accounts, err := apiClient.WalletAccounts(args[0])
+			if err != nil {
+				return err
+			}
+
+			return printJSON(accounts)
+		},
+	}
+}
+
+func walletAccountCreateCmd() *cobra.Command {
+	walletAccountCreateCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(2),
+		Use:   "walletAccountCreate [wallet] [name]",
+		Short: "Create an account in a bip44 wallet",
+		Long: `Create an account in a bip44 wallet. The first external and
+    change addresses of the new account are generated.
+
+    Use caution when using the "-p" command. If you have command
+    history enabled your wallet encryption password can be recovered from the
+    history log. If you do not include the "-p" option you will be prompted to
+    enter your password after you enter your command.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			wltID := args[0]
+
+			wlt, err := apiClient.Wallet(wltID)
+			if err != nil {
+				return err
+			}
+
+			if wlt.Meta.Type != wallet.WalletTypeBip44 {
+				return wallet.ErrWalletNotBip44
+			}
+
+			var password []byte
+			if wlt.Meta.Encrypted {
+				pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+				password, err = pr.Password()
+				if err != nil {
+					return err
+				}
+			}
+
+			account, err := apiClient.CreateWalletAccount(api.WalletAccountCreateRequest{
+				ID:       wltID,
+				Name:     args[1],
+				Password: string(password),
+			})
+			if err != nil {
+				return err
+			}
+
+			return printJSON(account)
+		},
+	}
+
+	walletAccountCreateCmd.Flags().StringP("password", "p", "", "wallet password")
+
+	return walletAccountCreateCmd
+}
+
+func walletAccountRenameCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.ExactArgs(3),
+		Use:   "walletAccountRename [wallet] [account] [name]",
+		Short: "Rename an account of a bip44 wallet",
+		RunE: func(c *cobra.Command, args []string) error {
+			account, err := parseAccountArg(args[1])
+			if err != nil {
+				return err
+			}
+
+			return apiClient.UpdateWalletAccount(api.WalletAccountUpdateRequest{
+				ID:      args[0],
+				Account: account,
+				Name:    args[2],
+			})
+		},
+	}
+}
+
+func walletAccountBalanceCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.ExactArgs(2),
+		Use:   "walletAccountBalance [wallet] [account]",
+		Short: "Check the balance of an account of a bip44 wallet",
+		RunE: func(c *cobra.Command, args []string) error {
+			account, err := parseAccountArg(args[1])
+			if err != nil {
+				return err
+			}
+
+			balance, err := apiClient.WalletAccountBalance(args[0], account)
+			if err != nil {
+				return err
+			}
+
+			return printJSON(balance)
+		},
+	}
+}
+
+func walletAccountAddAddressesCmd() *cobra.Command {
+	walletAccountAddAddressesCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(2),
+		Use:   "walletAccountAddAddresses [wallet] [account]",
+		Short: "Generate addresses for an account of a bip44 wallet",
+		Long: `Generate addresses for an account of a bip44 wallet.
+    Addresses are generated on the external chain of the account, unless
+    the --change option is used.
+
+    BIP44 wallets can generate addresses without being unlocked.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			account, err := parseAccountArg(args[1])
+			if err != nil {
+				return err
+			}
+
+			num, err := c.Flags().GetUint64("num")
+			if err != nil {
+				return err
+			}
+
+			if num == 0 {
+				return errors.New("-n must > 0")
+			}
+
+			change, err := c.Flags().GetBool("change")
+			if err != nil {
+				return err
+			}
+
+			jsonFmt, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			addrs, err := apiClient.NewWalletAccountAddresses(api.WalletAccountNewAddressesRequest{
+				ID:      args[0],
+				Account: account,
+				Num:     num,
+				Change:  change,
+			})
+			if err != nil {
+				return err
+			}
+
+			if jsonFmt {
+				s, err := FormatAddressesAsJSON(addrs)
+				if err != nil {
+					return err
+				}
+				fmt.Println(s)
+			} else {
+				fmt.Println(FormatAddressesAsJoinedArray(addrs))
+			}
+
+			return nil
+		},
+	}
+
+	walletAccountAddAddressesCmd.Flags().Uint64P("num", "n", 1, "Number of addresses to generate")
+	walletAccountAddAddressesCmd.Flags().Bool("change", false, "Generate addresses on the change chain")
+	walletAccountAddAddressesCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format")
+
+	return walletAccountAddAddressesCmd
+}
+
+func parseAccountArg(s string) (uint32, error) {
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid account index %q", s)
+	}
+	return uint32(n), nil
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func walletAddressLabelCmd() *cobra.Command {
+	walletAddressLabelCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(2),
+		Use:   "walletAddressLabel [wallet] [address]",
+		Short: "Set the label, tags and note of a wallet address",
+		Long: `Set the label, tags and note of a wallet address, replacing
+    the previous ones. Run the command without options to clear them.
+
+    Tags are separated by commas, e.g. --tags customer,invoice.
+
+    Encrypted wallets don't need to be unlocked, the labels, tags and notes
+    are not encrypted.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			label, err := c.Flags().GetString("label")
+			if err != nil {
+				return err
+			}
+
+			tags, err := c.Flags().GetStringSlice("tags")
+			if err != nil {
+				return err
+			}
+
+			note, err := c.Flags().GetString("note")
+			if err != nil {
+				return err
+			}
+
+			meta, err := apiClient.UpdateWalletAddress(api.WalletAddressUpdateRequest{
+				ID:      args[0],
+				Address: args[1],
+				Label:   label,
+				Tags:    tags,
+				Note:    note,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printJSON(meta)
+		},
+	}
+
+	walletAddressLabelCmd.Flags().StringP("label", "l", "", "address label")
+	walletAddressLabelCmd.Flags().StringSliceP("tags", "t", nil, "comma separated address tags")
+	walletAddressLabelCmd.Flags().StringP("note", "n", "", "address note")
+
+	return walletAddressLabelCmd
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func walletExportCmd() *cobra.Command {
+	walletExportCmd := &cobra.Command{
+		Args:  cobra.MinimumNArgs(1),
+		Use:   "walletExport [wallet]...",
+		Short: "Export wallets to an encrypted archive",
+		Long: `Export one or more wallets, with their labels and transaction notes,
+    to a single archive encrypted with a password. The archive can be imported
+    on another node with walletImport.
+
+    Encrypted wallets stay encrypted with their own password in the archive.
+    The node must have the WALLET API set enabled, and also the
+    INSECURE_WALLET_SEED API set to export unencrypted wallets.
+
+    Use caution when using the "-p" command. If you have command
+    history enabled your archive password can be recovered from the
+    history log. If you do not include the "-p" option you will be prompted to
+    enter your password after you enter your command.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			output, err := c.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				return errors.New("--output or -o is required")
+			}
+
+			pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+			password, err := pr.Password()
+			if err != nil {
+				return err
+			}
+
+			archive, err := apiClient.WalletExport(api.WalletExportRequest{
+				IDs:      args,
+				Password: string(password),
+			})
+			if err != nil {
+				return err
+			}
+
+			if err := ioutil.WriteFile(output, archive, 0600); err != nil {
+				return err
+			}
+
+			fmt.Printf("Exported %d wallets to %s\n", len(args), output)
+			return nil
+		},
+	}
+
+	walletExportCmd.Flags().StringP("output", "o", "", "archive file to write")
+	walletExportCmd.Flags().StringP("password", "p", "", "archive password")
+
+	return walletExportCmd
+}
+
+func walletImportCmd() *cobra.Command {
+	walletImportCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "walletImport [archive]",
+		Short: "Import wallets from an encrypted archive",
+		Long: `Import the wallets and transaction notes of an archive created by walletExport.
+
+    The --conflict option controls how wallets that already exist on the node,
+    loaded or unloaded, are handled: "error" aborts the import, "skip" imports
+    the other wallets only, and "overwrite" replaces the existing wallets of the
+    same name and seed, after making a backup of their files. The password of an encrypted wallet
+    is required to overwrite it, pass it with --wallet-password [wallet id]=[password].
+
+    Use caution when using the "-p" command. If you have command
+    history enabled your archive password can be recovered from the
+    history log. If you do not include the "-p" option you will be prompted to
+    enter your password after you enter your command.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			conflict, err := c.Flags().GetString("conflict")
+			if err != nil {
+				return err
+			}
+
+			archive, err := ioutil.ReadFile(args[0])
+			if err != nil {
+				return err
+			}
+
+			pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+			password, err := pr.Password()
+			if err != nil {
+				return err
+			}
+
+			walletPasswords, err := c.Flags().GetStringToString("wallet-password")
+			if err != nil {
+				return err
+			}
+
+			rsp, err := apiClient.WalletImport(api.WalletImportRequest{
+				Archive:         archive,
+				Password:        string(password),
+				Conflict:        conflict,
+				WalletPasswords: walletPasswords,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+
+	walletImportCmd.Flags().StringP("password", "p", "", "archive password")
+	walletImportCmd.Flags().String("conflict", "error", "how to handle wallets that already exist: error, skip or overwrite")
+	walletImportCmd.Flags().StringToString("wallet-password", nil, "password of an encrypted existing wallet to overwrite, as [wallet id]=[password]")
+
+	return walletImportCmd
+}
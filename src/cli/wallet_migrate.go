@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func walletMigrateCmd() *cobra.Command {
+	walletMigrateCmd := &cobra.Command{
+		Use:   "walletMigrate [wallet file]...",
+		Short: "Upgrade wallet files to the current wallet version",
+		Long: fmt.Sprintf(`Upgrade wallet files created by older releases to the current
+    wallet version %s, and report the changes made to each file. If no file is
+    given, all wallet files in $DATA_DIR/wallets are upgraded.
+
+    The original file is kept next to the upgraded file, with the extension
+    .<version>.bak. Use --rollback with the original version to restore it.
+
+    The node only upgrades its wallets in memory, unless it is started with
+    -migrate-wallets. Stop the node before using this command.`, wallet.Version),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			dryRun, err := c.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+
+			rollback, err := c.Flags().GetString("rollback")
+			if err != nil {
+				return err
+			}
+
+			jsonOutput, err := c.Flags().GetBool("json")
+			if err != nil {
+				return err
+			}
+
+			filenames := args
+			if len(filenames) == 0 {
+				filenames, err = walletFilenames(filepath.Join(cliConfig.DataDir, "wallets"))
+				if err != nil {
+					return err
+				}
+			}
+
+			if rollback != "" {
+				if dryRun {
+					return fmt.Errorf("--dry-run and --rollback cannot be combined")
+				}
+
+				for _, f := range filenames {
+					if err := wallet.RollbackMigration(f, rollback); err != nil {
+						return fmt.Errorf("rollback %s failed: %v", f, err)
+					}
+					fmt.Printf("Restored %s from %s\n", f, wallet.MigrationBackupFilename(filepath.Base(f), rollback))
+				}
+				return nil
+			}
+
+			reports := make([]*wallet.MigrationReport, 0, len(filenames))
+			for _, f := range filenames {
+				r, err := wallet.MigrateFile(f, dryRun)
+				if err != nil {
+					return fmt.Errorf("migrate %s failed: %v", f, err)
+				}
+				reports = append(reports, r)
+			}
+
+			if jsonOutput {
+				return printJSON(reports)
+			}
+
+			for _, r := range reports {
+				printMigrationReport(r)
+			}
+			return nil
+		},
+	}
+
+	walletMigrateCmd.Flags().Bool("dry-run", false, "Report the changes without modifying the wallet files")
+	walletMigrateCmd.Flags().String("rollback", "", "Restore the wallet files from the backups made when they were upgraded from this version")
+	walletMigrateCmd.Flags().BoolP("json", "j", false, "Returns the results in JSON format")
+
+	return walletMigrateCmd
+}
+
+// walletFilenames returns the paths of the wallet files in a directory
+func walletFilenames(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	for _, e := range entries {
+		if e.Mode().IsRegular() && strings.HasSuffix(e.Name(), wallet.WalletExt) {
+			filenames = append(filenames, filepath.Join(dir, e.Name()))
+		}
+	}
+
+	return filenames, nil
+}
+
+func printMigrationReport(r *wallet.MigrationReport) {
+	if !r.Migrated() {
+		fmt.Printf("%s: version %s is up to date\n", r.Filename, r.FromVersion)
+		return
+	}
+
+	if r.DryRun {
+		fmt.Printf("%s: would upgrade from version %s to %s\n", r.Filename, r.FromVersion, r.ToVersion)
+	} else {
+		fmt.Printf("%s: upgraded from version %s to %s, backup saved as %s\n", r.Filename, r.FromVersion, r.ToVersion, r.Backup)
+	}
+
+	for _, s := range r.Steps {
+		fmt.Printf("  %s -> %s: %s\n", s.From, s.To, s.Description)
+		for _, c := range s.Changes {
+			fmt.Printf("    - %s\n", c)
+		}
+	}
+}
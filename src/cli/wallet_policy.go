@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/api"
+)
+
+func walletPolicyCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "walletPolicy [wallet]",
+		Short: "Show the spending policy of a wallet",
+		Long: `Show the spending policy of a wallet, and the coins spent under
+    the policy in the last 24 hours. The policy is null if the wallet has no
+    spending policy.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			policy, err := apiClient.WalletPolicy(args[0])
+			if err != nil {
+				return err
+			}
+
+			return printJSON(policy)
+		},
+	}
+}
+
+func walletPolicySetCmd() *cobra.Command {
+	walletPolicySetCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "walletPolicySet [wallet]",
+		Short: "Set the spending policy of an encrypted wallet",
+		Long: `Set the spending policy of an encrypted wallet, replacing the
+    previous one. Transactions signed by the wallet must be allowed by the
+    policy. Options that are not set are not restricted by the new policy.
+
+    Coins sent to the addresses of the wallet, such as change, are not limited
+    by the policy. Addresses new to the allow-list can't receive coins until
+    the cooldown has passed. The spends in the last 24 hours are kept when the
+    policy is replaced.
+
+    Use caution when using the "-p" command. If you have command
+    history enabled your wallet encryption password can be recovered from the
+    history log. If you do not include the "-p" option you will be prompted to
+    enter your password after you enter your command.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			remove, err := c.Flags().GetBool("remove")
+			if err != nil {
+				return err
+			}
+
+			var policy *api.WalletPolicyParams
+			if !remove {
+				policy, err = walletPolicyParamsFromFlags(c)
+				if err != nil {
+					return err
+				}
+			}
+
+			pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+			password, err := pr.Password()
+			if err != nil {
+				return err
+			}
+
+			rsp, err := apiClient.UpdateWalletPolicy(api.WalletPolicyUpdateRequest{
+				ID:       args[0],
+				Password: string(password),
+				Policy:   policy,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+
+	walletPolicySetCmd.Flags().String("max-per-transaction", "", "maximum coins sent to other wallets by a transaction")
+	walletPolicySetCmd.Flags().String("max-per-day", "", "maximum coins sent to other wallets in 24 hours")
+	walletPolicySetCmd.Flags().StringSlice("allow", nil, "comma separated addresses that the wallet can send coins to")
+	walletPolicySetCmd.Flags().Duration("cooldown", 0, "time before an address added to the allow-list can receive coins, e.g. 24h")
+	walletPolicySetCmd.Flags().Uint64("min-confirmations", 0, "minimum confirmations of the outputs spent")
+	walletPolicySetCmd.Flags().Bool("remove", false, "remove the spending policy")
+	walletPolicySetCmd.Flags().StringP("password", "p", "", "wallet password")
+
+	return walletPolicySetCmd
+}
+
+func walletPolicyParamsFromFlags(c *cobra.Command) (*api.WalletPolicyParams, error) {
+	maxPerTxn, err := c.Flags().GetString("max-per-transaction")
+	if err != nil {
+		return nil, err
+	}
+
+	maxPerDay, err := c.Flags().GetString("max-per-day")
+	if err != nil {
+		return nil, err
+	}
+
+	allow, err := c.Flags().GetStringSlice("allow")
+	if err != nil {
+		return nil, err
+	}
+
+	cooldown, err := c.Flags().GetDuration("cooldown")
+	if err != nil {
+		return nil, err
+	}
+	if cooldown < 0 {
+		return nil, fmt.Errorf("invalid cooldown %s", cooldown)
+	}
+
+	minConfirmations, err := c.Flags().GetUint64("min-confirmations")
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.WalletPolicyParams{
+		MaxCoinsPerTransaction: maxPerTxn,
+		MaxCoinsPerDay:         maxPerDay,
+		AllowedDestinations:    allow,
+		DestinationCooldown:    uint64(cooldown.Seconds()),
+		MinConfirmations:       minConfirmations,
+	}, nil
+}
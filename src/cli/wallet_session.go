@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func walletUnlockCmd() *cobra.Command {
+	walletUnlockCmd := &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "walletUnlock [wallet]",
+		Short: "Unlock an encrypted wallet for a limited time",
+		Long: fmt.Sprintf(`Unlock an encrypted wallet for a limited time. The node keeps the
+    decrypted secrets of the wallet in memory, and the token that is printed
+    can be used in place of the wallet password, with the "-p" option of
+    other commands or when prompted for the password, until the session
+    expires or the wallet is locked.
+    The timeout is at most %s.
+
+    Use caution when using the "-p" command. If you have command
+    history enabled your wallet encryption password can be recovered from the
+    history log. If you do not include the "-p" option you will be prompted to
+    enter your password after you enter your command.`, wallet.MaxUnlockTimeout),
+		RunE: func(c *cobra.Command, args []string) error {
+			timeout, err := c.Flags().GetDuration("timeout")
+			if err != nil {
+				return err
+			}
+			if timeout < 0 {
+				return fmt.Errorf("invalid timeout %s", timeout)
+			}
+
+			pr := NewPasswordReader([]byte(c.Flag("password").Value.String()))
+			password, err := pr.Password()
+			if err != nil {
+				return err
+			}
+
+			rsp, err := apiClient.UnlockWallet(args[0], string(password), timeout)
+			if err != nil {
+				return err
+			}
+
+			return printJSON(rsp)
+		},
+	}
+
+	walletUnlockCmd.Flags().Duration("timeout", wallet.DefaultUnlockTimeout, "duration of the unlock session, e.g. 10m")
+	walletUnlockCmd.Flags().StringP("password", "p", "", "wallet password")
+
+	return walletUnlockCmd
+}
+
+func walletLockCmd() *cobra.Command {
+	return &cobra.Command{
+		Args:  cobra.ExactArgs(1),
+		Use:   "walletLock [wallet]",
+		Short: "Lock an unlocked wallet",
+		Long: `Lock an unlocked wallet, ending its unlock session. The node erases
+    the decrypted secrets of the wallet from memory and the session token
+    can no longer be used.`,
+		RunE: func(c *cobra.Command, args []string) error {
+			return apiClient.LockWallet(args[0])
+		},
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Package capture records and reads captures of the messages exchanged by the daemon.
+
+A capture file begins with a header, followed by a sequence of length prefixed records.
+Each record holds a single gnet message, as it was read from or written to the connection,
+without its length prefix.
+*/
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+	"github.com/skycoin/skycoin/src/util/logging"
+)
+
+const (
+	// Version is the capture file format version
+	Version uint32 = 1
+
+	// maxRecordLength is the maximum length of a record in a capture file.
+	// Records hold a single gnet message, so this is much larger than any message length limit.
+	maxRecordLength = 64 * 1024 * 1024
+)
+
+var (
+	logger = logging.MustGetLogger("capture")
+
+	// magic identifies a capture file
+	magic = []byte("SKYCAP")
+
+	// ErrInvalidMagic is returned when reading a file that is not a capture file
+	ErrInvalidMagic = errors.New("not a capture file")
+	// ErrRecordTooLong is returned when a record exceeds the maximum record length
+	ErrRecordTooLong = errors.New("capture record exceeds maximum length")
+)
+
+// Record is a single captured message
+type Record struct {
+	// Time the message was read or written, in unix nanoseconds
+	Time int64
+	// Sent is true if the message was sent to the peer, false if it was received from the peer
+	Sent bool
+	// Addr is the address of the peer
+	Addr string
+	// ConnID is the gnet connection ID
+	ConnID uint64
+	// Data is the message prefix followed by the encoded message
+	Data []byte
+}
+
+// Direction returns "out" for sent messages and "in" for received messages
+func (r Record) Direction() string {
+	if r.Sent {
+		return "out"
+	}
+	return "in"
+}
+
+// Writer writes records to a capture file. It is safe for concurrent use.
+type Writer struct {
+	sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	err    error
+}
+
+// NewWriter creates a Writer and writes the capture header to w
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+
+	header := append([]byte{}, magic...)
+	header = append(header, encoder.SerializeAtomic(Version)...)
+	if _, err := bw.Write(header); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		w: bw,
+	}, nil
+}
+
+// Create creates or truncates a capture file
+func Create(filename string) (*Writer, error) {
+	f, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := NewWriter(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	w.closer = f
+	return w, nil
+}
+
+// Write writes a record. Each record is flushed as it is written,
+// so that a capture remains readable if the process stops unexpectedly.
+func (w *Writer) Write(r Record) error {
+	b := encoder.Serialize(r)
+	if len(b) > maxRecordLength {
+		return ErrRecordTooLong
+	}
+
+	w.Lock()
+	defer w.Unlock()
+
+	if w.err != nil {
+		return w.err
+	}
+
+	if _, err := w.w.Write(encoder.SerializeUint32(uint32(len(b)))); err != nil {
+		w.err = err
+		return err
+	}
+	if _, err := w.w.Write(b); err != nil {
+		w.err = err
+		return err
+	}
+	if err := w.w.Flush(); err != nil {
+		w.err = err
+		return err
+	}
+
+	return nil
+}
+
+// MessageCallback records a message. It matches gnet.MessageCallback.
+// Errors are logged rather than returned, and only the first error is logged.
+func (w *Writer) MessageCallback(addr string, id uint64, sent bool, msg []byte) {
+	w.Lock()
+	failed := w.err != nil
+	w.Unlock()
+	if failed {
+		return
+	}
+
+	if err := w.Write(Record{
+		Time:   time.Now().UTC().UnixNano(),
+		Sent:   sent,
+		Addr:   addr,
+		ConnID: id,
+		Data:   msg,
+	}); err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("Failed to write capture record, capture stopped")
+	}
+}
+
+// Close flushes the writer and closes the underlying file, if it was opened by Create
+func (w *Writer) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	err := w.w.Flush()
+	if w.err == nil {
+		w.err = errors.New("capture writer is closed")
+	}
+
+	if w.closer != nil {
+		if cerr := w.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// Reader reads records from a capture file
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader creates a Reader and reads the capture header from r
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, len(magic)+4)
+	if _, err := io.ReadFull(br, header); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrInvalidMagic
+		}
+		return nil, err
+	}
+
+	if !bytes.Equal(header[:len(magic)], magic) {
+		return nil, ErrInvalidMagic
+	}
+
+	var version uint32
+	if _, err := encoder.DeserializeAtomic(header[len(magic):], &version); err != nil {
+		return nil, err
+	}
+	if version != Version {
+		return nil, fmt.Errorf("unsupported capture file version %d", version)
+	}
+
+	return &Reader{
+		r: br,
+	}, nil
+}
+
+// Next reads the next record. Returns io.EOF when there are no more records.
+// Returns io.ErrUnexpectedEOF if the last record is truncated.
+func (r *Reader) Next() (Record, error) {
+	var n [4]byte
+	if _, err := io.ReadFull(r.r, n[:]); err != nil {
+		return Record{}, err
+	}
+
+	length, _, err := encoder.DeserializeUint32(n[:])
+	if err != nil {
+		return Record{}, err
+	}
+	if length > maxRecordLength {
+		return Record{}, ErrRecordTooLong
+	}
+
+	b := make([]byte, length)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return Record{}, err
+	}
+
+	var rec Record
+	if err := encoder.DeserializeRawExact(b, &rec); err != nil {
+		return Record{}, err
+	}
+
+	return rec, nil
+}
+
+// ReadFile reads all of the records in a capture file
+func ReadFile(filename string) ([]Record, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for {
+		rec, err := r.Next()
+		switch err {
+		case nil:
+			records = append(records, rec)
+		case io.EOF:
+			return records, nil
+		default:
+			return records, err
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+)
+
+func init() {
+	gnet.EraseMessages()
+	c := daemon.NewMessagesConfig()
+	c.Register()
+}
+
+func encodeMessage(t *testing.T, m gnet.Message) []byte {
+	b, err := gnet.EncodeMessage(m)
+	require.NoError(t, err)
+	// Strip the length prefix
+	return b[4:]
+}
+
+func testRecords(t *testing.T) []Record {
+	now := time.Now().UTC().UnixNano()
+	return []Record{
+		{
+			Time:   now,
+			Sent:   true,
+			Addr:   "127.0.0.1:6000",
+			ConnID: 1,
+			Data:   encodeMessage(t, daemon.NewGetBlocksMessage(10, 20)),
+		},
+		{
+			Time:   now + int64(time.Millisecond),
+			Sent:   false,
+			Addr:   "127.0.0.1:6000",
+			ConnID: 1,
+			Data:   encodeMessage(t, daemon.NewAnnounceBlocksMessage(30)),
+		},
+		{
+			Time:   now + int64(2*time.Millisecond),
+			Sent:   false,
+			Addr:   "127.0.0.2:6000",
+			ConnID: 2,
+			Data:   encodeMessage(t, daemon.NewGetTxnsMessage([]cipher.SHA256{cipher.SumSHA256([]byte("a"))}, 1024)),
+		},
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	records := testRecords(t)
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	require.NoError(t, err)
+
+	for _, r := range records {
+		require.NoError(t, w.Write(r))
+	}
+	require.NoError(t, w.Close())
+
+	// Writes fail after Close
+	require.Error(t, w.Write(records[0]))
+
+	b := buf.Bytes()
+
+	r, err := NewReader(bytes.NewReader(b))
+	require.NoError(t, err)
+	for _, rec := range records {
+		x, err := r.Next()
+		require.NoError(t, err)
+		require.Equal(t, rec, x)
+	}
+	_, err = r.Next()
+	require.Equal(t, io.EOF, err)
+
+	// A truncated record is reported
+	r, err = NewReader(bytes.NewReader(b[:len(b)-1]))
+	require.NoError(t, err)
+	for range records[:len(records)-1] {
+		_, err := r.Next()
+		require.NoError(t, err)
+	}
+	_, err = r.Next()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+
+	// Invalid headers are rejected
+	_, err = NewReader(bytes.NewReader([]byte("SKY")))
+	require.Equal(t, ErrInvalidMagic, err)
+	_, err = NewReader(bytes.NewReader([]byte("NOTCAP\x01\x00\x00\x00")))
+	require.Equal(t, ErrInvalidMagic, err)
+	_, err = NewReader(bytes.NewReader([]byte("SKYCAP\x02\x00\x00\x00")))
+	require.Error(t, err)
+}
+
+func TestCreateReadFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "capture")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "test.skycap")
+	w, err := Create(fn)
+	require.NoError(t, err)
+
+	records := testRecords(t)
+	for _, r := range records {
+		w.MessageCallback(r.Addr, r.ConnID, r.Sent, r.Data)
+	}
+	require.NoError(t, w.Close())
+
+	rs, err := ReadFile(fn)
+	require.NoError(t, err)
+	require.Len(t, rs, len(records))
+	for i, r := range rs {
+		require.NotZero(t, r.Time)
+		r.Time = records[i].Time
+		require.Equal(t, records[i], r)
+	}
+}
+
+func TestRecordDecode(t *testing.T) {
+	records := testRecords(t)
+
+	m, err := records[0].Decode()
+	require.NoError(t, err)
+	require.Equal(t, daemon.NewGetBlocksMessage(10, 20), m)
+	require.Equal(t, "GETB", records[0].Prefix())
+
+	r := Record{Data: []byte("GET")}
+	_, err = r.Decode()
+	require.Equal(t, gnet.ErrDisconnectTruncatedMessageID, err)
+	require.Equal(t, "", r.Prefix())
+
+	r = Record{Data: []byte("XXXX")}
+	_, err = r.Decode()
+	require.Equal(t, gnet.ErrDisconnectUnknownMessage, err)
+
+	r = Record{Data: append(records[0].Data, 0)}
+	_, err = r.Decode()
+	require.Equal(t, gnet.ErrDisconnectMessageDecodeUnderflow, err)
+}
+
+func TestNewRecordJSON(t *testing.T) {
+	records := testRecords(t)
+
+	rj := NewRecordJSON(records[0])
+	require.Equal(t, "out", rj.Direction)
+	require.Equal(t, "GETB", rj.Type)
+	require.Equal(t, len(records[0].Data), rj.Size)
+	require.Empty(t, rj.Error)
+
+	b, err := json.Marshal(rj)
+	require.NoError(t, err)
+	var x map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &x))
+	require.Equal(t, map[string]interface{}{
+		"LastBlock":       float64(10),
+		"RequestedBlocks": float64(20),
+	}, x["message"])
+
+	// Hashes are shown as hex
+	rj = NewRecordJSON(records[2])
+	require.Equal(t, "in", rj.Direction)
+	b, err = json.Marshal(rj.Message)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"transactions":["`+cipher.SumSHA256([]byte("a")).Hex()+`"]}`, string(b))
+
+	// Disconnect reasons are shown
+	rj = NewRecordJSON(Record{Data: encodeMessage(t, daemon.NewDisconnectMessage(daemon.ErrDisconnectIdle))})
+	b, err = json.Marshal(rj.Message)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"reason_code":6,"reason":"`+daemon.ErrDisconnectIdle.Error()+`"}`, string(b))
+
+	// Undecodable messages include the error
+	rj = NewRecordJSON(Record{Data: []byte("XXXX")})
+	require.Equal(t, gnet.ErrDisconnectUnknownMessage.Error(), rj.Error)
+	require.Nil(t, rj.Message)
+}
+
+func TestReplay(t *testing.T) {
+	records := testRecords(t)
+
+	require.Equal(t, []uint64{1, 2}, Connections(records))
+	received := Received(records, 1)
+	require.Equal(t, []Record{records[1]}, received)
+
+	var buf bytes.Buffer
+	require.NoError(t, Replay(&buf, records, 0, nil))
+
+	// The replayed stream is a sequence of length prefixed messages
+	var expected []byte
+	for _, r := range records {
+		b, err := gnet.EncodeMessage(mustDecode(t, r))
+		require.NoError(t, err)
+		expected = append(expected, b...)
+	}
+	require.Equal(t, expected, buf.Bytes())
+
+	// Delays are scaled by speed
+	buf.Reset()
+	start := time.Now()
+	require.NoError(t, Replay(&buf, records, 0.1, nil))
+	require.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	// Replay can be aborted
+	quit := make(chan struct{})
+	close(quit)
+	buf.Reset()
+	require.NoError(t, Replay(&buf, records, 1, quit))
+	require.Empty(t, buf.Bytes())
+}
+
+func mustDecode(t *testing.T, r Record) gnet.Message {
+	m, err := r.Decode()
+	require.NoError(t, err)
+	return m
+}
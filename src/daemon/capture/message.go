@@ -0,0 +1,189 @@
+package capture
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+	"github.com/skycoin/skycoin/src/readable"
+)
+
+// Prefix returns the message prefix of the record's message as a string
+func (r Record) Prefix() string {
+	if len(r.Data) < len(gnet.MessagePrefix{}) {
+		return ""
+	}
+	return string(r.Data[:len(gnet.MessagePrefix{})])
+}
+
+// Decode decodes the record's message. The message types must be registered with gnet,
+// e.g. with daemon.NewMessagesConfig().Register()
+func (r Record) Decode() (gnet.Message, error) {
+	var prefix gnet.MessagePrefix
+	if len(r.Data) < len(prefix) {
+		return nil, gnet.ErrDisconnectTruncatedMessageID
+	}
+	copy(prefix[:], r.Data)
+
+	t, ok := gnet.MessageIDReverseMap[prefix]
+	if !ok {
+		return nil, gnet.ErrDisconnectUnknownMessage
+	}
+
+	v := reflect.New(t)
+	m, ok := (v.Interface()).(gnet.Message)
+	if !ok {
+		return nil, errors.New("MessageIdMaps contain non-Message")
+	}
+
+	n, err := m.Decode(r.Data[len(prefix):])
+	if err != nil {
+		return nil, err
+	}
+	if n != uint64(len(r.Data)-len(prefix)) {
+		return nil, gnet.ErrDisconnectMessageDecodeUnderflow
+	}
+
+	return m, nil
+}
+
+// RecordJSON is a human readable representation of a Record
+type RecordJSON struct {
+	Time      string      `json:"time"`
+	Direction string      `json:"direction"`
+	Addr      string      `json:"addr"`
+	ConnID    uint64      `json:"conn_id"`
+	Type      string      `json:"type"`
+	Size      int         `json:"size"`
+	Message   interface{} `json:"message,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// NewRecordJSON decodes a Record into a RecordJSON. If the message can't be decoded,
+// the error is included in the RecordJSON
+func NewRecordJSON(r Record) RecordJSON {
+	rj := RecordJSON{
+		Time:      time.Unix(0, r.Time).UTC().Format(time.RFC3339Nano),
+		Direction: r.Direction(),
+		Addr:      r.Addr,
+		ConnID:    r.ConnID,
+		Type:      r.Prefix(),
+		Size:      len(r.Data),
+	}
+
+	m, err := r.Decode()
+	if err != nil {
+		rj.Error = err.Error()
+		return rj
+	}
+
+	rj.Message, err = readableMessage(m)
+	if err != nil {
+		rj.Error = err.Error()
+	}
+
+	return rj
+}
+
+// readableMessage converts the messages that contain binary data to a readable format.
+// Other messages are returned as is.
+func readableMessage(m gnet.Message) (interface{}, error) {
+	switch msg := m.(type) {
+	case *daemon.IntroductionMessage:
+		return struct {
+			Mirror          uint32 `json:"mirror"`
+			ListenPort      uint16 `json:"listen_port"`
+			ProtocolVersion int32  `json:"protocol_version"`
+			Extra           string `json:"extra,omitempty"`
+		}{
+			Mirror:          msg.Mirror,
+			ListenPort:      msg.ListenPort,
+			ProtocolVersion: msg.ProtocolVersion,
+			Extra:           hex.EncodeToString(msg.Extra),
+		}, nil
+
+	case *daemon.DisconnectMessage:
+		return struct {
+			ReasonCode uint16 `json:"reason_code"`
+			Reason     string `json:"reason"`
+		}{
+			ReasonCode: msg.ReasonCode,
+			Reason:     daemon.DisconnectCodeToReason(msg.ReasonCode).Error(),
+		}, nil
+
+	case *daemon.GivePeersMessage:
+		peers := make([]string, len(msg.Peers))
+		for i, p := range msg.Peers {
+			peers[i] = p.String()
+		}
+		return struct {
+			Peers []string `json:"peers"`
+		}{
+			Peers: peers,
+		}, nil
+
+	case *daemon.GiveBlocksMessage:
+		type signedBlock struct {
+			readable.Block
+			Signature string `json:"signature"`
+		}
+
+		blocks := make([]signedBlock, len(msg.Blocks))
+		for i, b := range msg.Blocks {
+			rb, err := readable.NewBlock(b.Block)
+			if err != nil {
+				return nil, err
+			}
+			blocks[i] = signedBlock{
+				Block:     *rb,
+				Signature: b.Sig.Hex(),
+			}
+		}
+		return struct {
+			Blocks []signedBlock `json:"blocks"`
+		}{
+			Blocks: blocks,
+		}, nil
+
+	case *daemon.AnnounceTxnsMessage:
+		return txnHashes(msg.Transactions), nil
+
+	case *daemon.GetTxnsMessage:
+		return txnHashes(msg.Transactions), nil
+
+	case *daemon.GiveTxnsMessage:
+		txns := make([]readable.Transaction, len(msg.Transactions))
+		for i, txn := range msg.Transactions {
+			rt, err := readable.NewTransaction(txn, false)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %d: %v", i, err)
+			}
+			txns[i] = *rt
+		}
+		return struct {
+			Transactions []readable.Transaction `json:"transactions"`
+		}{
+			Transactions: txns,
+		}, nil
+
+	default:
+		return m, nil
+	}
+}
+
+func txnHashes(hashes []cipher.SHA256) interface{} {
+	txids := make([]string, len(hashes))
+	for i, h := range hashes {
+		txids[i] = h.Hex()
+	}
+	return struct {
+		Transactions []string `json:"transactions"`
+	}{
+		Transactions: txids,
+	}
+}
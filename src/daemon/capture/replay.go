@@ -0,0 +1,66 @@
+package capture
+
+import (
+	"io"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher/encoder"
+)
+
+// Connections returns the connection IDs in the records, in order of first appearance
+func Connections(records []Record) []uint64 {
+	seen := make(map[uint64]struct{})
+	var ids []uint64
+	for _, r := range records {
+		if _, ok := seen[r.ConnID]; ok {
+			continue
+		}
+		seen[r.ConnID] = struct{}{}
+		ids = append(ids, r.ConnID)
+	}
+	return ids
+}
+
+// Received returns the messages received on connection connID.
+// These are the messages that a replay sends to reproduce that peer's side of the connection.
+func Received(records []Record, connID uint64) []Record {
+	var rs []Record
+	for _, r := range records {
+		if r.ConnID == connID && !r.Sent {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// Replay writes the records' messages to w, with a length prefix, as gnet would send them.
+// The delay between messages is the delay between the records' timestamps divided by speed.
+// If speed is 0, the messages are written without delay.
+// The quit channel aborts the replay between messages.
+func Replay(w io.Writer, records []Record, speed float64, quit <-chan struct{}) error {
+	for i, r := range records {
+		if i > 0 && speed > 0 {
+			delay := time.Duration(float64(r.Time-records[i-1].Time) / speed)
+			if delay > 0 {
+				select {
+				case <-quit:
+					return nil
+				case <-time.After(delay):
+				}
+			}
+		}
+
+		select {
+		case <-quit:
+			return nil
+		default:
+		}
+
+		b := append(encoder.SerializeUint32(uint32(len(r.Data))), r.Data...)
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -65,6 +65,7 @@ type ConnectionDetails struct {
 	UserAgent            useragent.Data
 	UnconfirmedVerifyTxn params.VerifyTxn
 	GenesisHash          cipher.SHA256
+	Features             Feature
 }
 
 // HasIntroduced returns true if the connection has introduced
@@ -300,6 +301,7 @@ func (c *Connections) introduced(addr string, gnetID uint64, m *IntroductionMess
 	conn.UserAgent = m.UserAgent
 	conn.UnconfirmedVerifyTxn = m.UnconfirmedVerifyTxn
 	conn.GenesisHash = m.GenesisHash
+	conn.Features = m.Features
 
 	if !conn.Outgoing {
 		listenAddr := conn.ListenAddr()
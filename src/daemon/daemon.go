@@ -35,6 +35,8 @@ var (
 	ErrNetworkingDisabled = errors.New("Networking is disabled")
 	// ErrNoPeerAcceptsTxn is returned if no peer will propagate a transaction broadcasted with BroadcastUserTransaction
 	ErrNoPeerAcceptsTxn = errors.New("No peer will propagate this transaction")
+	// ErrPeerMissingFeatures is returned when sending a message to a peer that did not advertise the features it requires
+	ErrPeerMissingFeatures = errors.New("Peer does not support the features required by this message")
 
 	logger = logging.MustGetLogger("daemon")
 )
@@ -153,6 +155,8 @@ type DaemonConfig struct { //nolint:golint
 	ProtocolVersion int32
 	// Minimum accepted protocol version
 	MinProtocolVersion int32
+	// Optional protocol features advertised to peers in the introduction message
+	Features Feature
 	// IP Address to serve on. Leave empty for automatic assignment
 	Address string
 	// BlockchainPubkey blockchain pubkey string
@@ -231,6 +235,7 @@ func NewDaemonConfig() DaemonConfig {
 	return DaemonConfig{
 		ProtocolVersion:              2,
 		MinProtocolVersion:           2,
+		Features:                     SupportedFeatures,
 		Address:                      "",
 		Port:                         6677,
 		OutgoingRate:                 time.Second * 5,
@@ -272,7 +277,7 @@ type daemoner interface {
 	sendMessage(addr string, msg gnet.Message) error
 	broadcastMessage(msg gnet.Message) ([]uint64, error)
 	disconnectNow(addr string, r gnet.DisconnectReason) error
-	addPeers(addrs []string) int
+	addPeers(source string, addrs []string) int
 	recordPeerHeight(addr string, gnetID, height uint64)
 	getSignedBlocksSince(seq, count uint64) ([]coin.SignedBlock, error)
 	headBkSeq() (uint64, bool, error)
@@ -805,8 +810,20 @@ func (dm *Daemon) connectToRandomPeer() {
 		return
 	}
 
-	// Make a connection to a random (public) peer
-	peers := dm.pex.Random(dm.config.MaxOutgoingConnections - dm.connections.OutgoingLen())
+	// Make connections to random (public) peers, spread across network groups.
+	// The connections to trusted peers are made separately and do not use up a network group.
+	var outgoing []string
+	for _, c := range dm.connections.all() {
+		if !c.Outgoing {
+			continue
+		}
+		if p, ok := dm.pex.GetPeer(c.Addr); ok && p.Trusted {
+			continue
+		}
+		outgoing = append(outgoing, c.Addr)
+	}
+
+	peers := dm.pex.RandomDiverse(dm.config.MaxOutgoingConnections-dm.connections.OutgoingLen(), outgoing)
 	for _, p := range peers {
 		if err := dm.connectToPeer(p); err != nil {
 			logger.WithError(err).WithField("addr", p.Addr).Warning("connectToPeer failed")
@@ -957,6 +974,7 @@ func (dm *Daemon) onConnectEvent(e ConnectEvent) {
 		dm.config.userAgent,
 		dm.config.UnconfirmedVerifyTxn,
 		dm.config.GenesisHash,
+		dm.config.Features,
 	)); err != nil {
 		logger.WithFields(fields).WithError(err).Error("Send IntroductionMessage failed")
 		return
@@ -1380,6 +1398,10 @@ func (dm *Daemon) connectionIntroduced(addr string, gnetID uint64, m *Introducti
 			logger.Critical().WithError(err).WithFields(fields).Error("pex.SetHasIncomingPort failed")
 			return nil, err
 		}
+
+		if err := dm.pex.MarkTried(listenAddr); err != nil {
+			logger.WithError(err).WithFields(fields).Warning("pex.MarkTried failed")
+		}
 	} else {
 		// For successful incoming connections, add the peer to the peer list, with their self-reported listen port
 		if err := dm.pex.AddPeer(listenAddr); err != nil {
@@ -1475,7 +1497,15 @@ func divideHashes(hashes []cipher.SHA256, n int) [][]cipher.SHA256 {
 }
 
 // sendMessage sends a Message to a Connection and pushes the result onto the SendResults channel.
+// Returns ErrPeerMissingFeatures if the message is gated on features that the peer did not advertise.
 func (dm *Daemon) sendMessage(addr string, msg gnet.Message) error {
+	if required := dm.Messages.features.required(msg); required != 0 {
+		c := dm.connections.get(addr)
+		if c == nil || !c.Features.Has(required) {
+			return ErrPeerMissingFeatures
+		}
+	}
+
 	return dm.pool.Pool.SendMessage(addr, msg)
 }
 
@@ -1491,7 +1521,7 @@ func (dm *Daemon) broadcastMessage(msg gnet.Message) ([]uint64, error) {
 	conns := dm.connections.all()
 	var addrs []string
 	for _, c := range conns {
-		if c.HasIntroduced() {
+		if c.HasIntroduced() && dm.Messages.features.canSend(msg, c.Features) {
 			addrs = append(addrs, c.Addr)
 		}
 	}
@@ -1510,9 +1540,9 @@ func (dm *Daemon) pexConfig() pex.Config {
 	return dm.pex.Config
 }
 
-// addPeers adds peers to the pex
-func (dm *Daemon) addPeers(addrs []string) int {
-	return dm.pex.AddPeers(addrs)
+// addPeers adds peers that were advertised by the peer at source to the pex
+func (dm *Daemon) addPeers(source string, addrs []string) int {
+	return dm.pex.AddPeersFrom(source, addrs)
 }
 
 // recordPeerHeight records the height of specific peer
@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/skycoin/skycoin/src/daemon/gnet"
+)
+
+// Feature is a bitfield of optional protocol capabilities, advertised by peers in the IntroductionMessage.
+// Features allow protocol extensions to be rolled out without requiring all peers to upgrade at once:
+// a message type that is gated on a feature is only sent to peers that advertise that feature.
+type Feature uint64
+
+const (
+	// FeatureIgnoreUnknownMessages indicates that the peer ignores messages with an unknown prefix,
+	// instead of disconnecting
+	FeatureIgnoreUnknownMessages Feature = 1 << iota
+)
+
+// SupportedFeatures are the features implemented by this version of the daemon
+const SupportedFeatures = FeatureIgnoreUnknownMessages
+
+var featureNames = map[Feature]string{
+	FeatureIgnoreUnknownMessages: "ignore-unknown-messages",
+}
+
+// Has returns true if all of the bits in g are set in f
+func (f Feature) Has(g Feature) bool {
+	return f&g == g
+}
+
+// Names returns the names of the known features that are set, sorted.
+// Unknown feature bits are omitted.
+func (f Feature) Names() []string {
+	var names []string
+	for k, v := range featureNames {
+		if f.Has(k) {
+			names = append(names, v)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// messageFeatures maps message types to the features a peer must advertise in order to be sent that message.
+// Message types which are not present are part of the base protocol and can be sent to any peer.
+type messageFeatures map[reflect.Type]Feature
+
+// newMessageFeatures creates messageFeatures from a set of MessageConfigs
+func newMessageFeatures(mcs []MessageConfig) messageFeatures {
+	mf := make(messageFeatures)
+	for _, mc := range mcs {
+		if mc.Features != 0 {
+			mf[reflect.TypeOf(mc.Message)] = mc.Features
+		}
+	}
+	return mf
+}
+
+// required returns the features that a peer must advertise to be sent msg
+func (mf messageFeatures) required(msg gnet.Message) Feature {
+	t := reflect.TypeOf(msg)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return mf[t]
+}
+
+// canSend returns true if a peer that advertised features can be sent msg
+func (mf messageFeatures) canSend(msg gnet.Message, features Feature) bool {
+	return features.Has(mf.required(msg))
+}
@@ -0,0 +1,95 @@
+package daemon
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestFeatureHas(t *testing.T) {
+	var f Feature
+	require.True(t, f.Has(0))
+	require.False(t, f.Has(FeatureIgnoreUnknownMessages))
+
+	f = FeatureIgnoreUnknownMessages | 1<<40
+	require.True(t, f.Has(FeatureIgnoreUnknownMessages))
+	require.True(t, f.Has(1<<40))
+	require.False(t, f.Has(1<<41|FeatureIgnoreUnknownMessages))
+}
+
+func TestFeatureNames(t *testing.T) {
+	require.Empty(t, Feature(0).Names())
+	require.Equal(t, []string{"ignore-unknown-messages"}, SupportedFeatures.Names())
+	// Unknown bits are omitted
+	require.Equal(t, []string{"ignore-unknown-messages"}, (SupportedFeatures | 1<<63).Names())
+}
+
+func TestMessageFeatures(t *testing.T) {
+	const featureTest Feature = 1 << 32
+
+	mf := newMessageFeatures([]MessageConfig{
+		NewMessageConfig("PING", PingMessage{}),
+		NewFeatureMessageConfig("PONG", PongMessage{}, featureTest),
+	})
+
+	require.Equal(t, Feature(0), mf.required(&PingMessage{}))
+	require.Equal(t, featureTest, mf.required(&PongMessage{}))
+	require.Equal(t, Feature(0), mf.required(&GetPeersMessage{}))
+
+	require.True(t, mf.canSend(&PingMessage{}, 0))
+	require.False(t, mf.canSend(&PongMessage{}, 0))
+	require.False(t, mf.canSend(&PongMessage{}, SupportedFeatures))
+	require.True(t, mf.canSend(&PongMessage{}, SupportedFeatures|featureTest))
+}
+
+func TestIntroductionMessageVerifyFeatures(t *testing.T) {
+	pubkey, _ := cipher.GenerateKeyPair()
+	genesisHash := testutil.RandSHA256(t)
+	verifyTxn := params.VerifyTxn{
+		BurnFactor:          2,
+		MaxTransactionSize:  32768,
+		MaxDropletPrecision: 3,
+	}
+
+	dc := DaemonConfig{
+		Mirror:           10000,
+		BlockchainPubkey: pubkey,
+	}
+
+	// Features are parsed from the extra data
+	intro := NewIntroductionMessage(10001, 2, 6000, pubkey, "skycoin:0.28.0", verifyTxn, genesisHash, SupportedFeatures|1<<40)
+	require.NoError(t, intro.Verify(dc, logrus.Fields{}))
+	require.Equal(t, SupportedFeatures|1<<40, intro.Features)
+	require.Equal(t, genesisHash, intro.GenesisHash)
+
+	// Older peers do not send features
+	intro = NewIntroductionMessage(10001, 2, 6000, pubkey, "skycoin:0.27.0", verifyTxn, genesisHash, 0)
+	intro.Extra = intro.Extra[:len(intro.Extra)-8]
+	require.NoError(t, intro.Verify(dc, logrus.Fields{}))
+	require.Equal(t, Feature(0), intro.Features)
+	require.Equal(t, genesisHash, intro.GenesisHash)
+
+	// Data after the features is ignored
+	intro = NewIntroductionMessage(10001, 2, 6000, pubkey, "skycoin:0.28.0", verifyTxn, genesisHash, SupportedFeatures)
+	intro.Extra = append(intro.Extra, []byte("future extension")...)
+	require.NoError(t, intro.Verify(dc, logrus.Fields{}))
+	require.Equal(t, SupportedFeatures, intro.Features)
+
+	// Data after the genesis hash that is too short for the features is ignored, as it was before v28
+	intro = NewIntroductionMessage(10001, 2, 6000, pubkey, "skycoin:0.28.0", verifyTxn, genesisHash, SupportedFeatures)
+	intro.Extra = intro.Extra[:len(intro.Extra)-3]
+	require.NoError(t, intro.Verify(dc, logrus.Fields{}))
+	require.Equal(t, Feature(0), intro.Features)
+
+	// A 3 byte trailer after the genesis hash
+	intro = NewIntroductionMessage(10001, 2, 6000, pubkey, "skycoin:0.27.0", verifyTxn, genesisHash, 0)
+	intro.Extra = append(intro.Extra[:len(intro.Extra)-8], 1, 2, 3)
+	require.NoError(t, intro.Verify(dc, logrus.Fields{}))
+	require.Equal(t, Feature(0), intro.Features)
+	require.Equal(t, genesisHash, intro.GenesisHash)
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/skycoin/skycoin/src/daemon/strand"
 	"github.com/skycoin/skycoin/src/util/elapse"
 	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/metrics"
 )
 
 // DisconnectReason is passed to ConnectionPool's DisconnectCallback
@@ -72,6 +73,9 @@ var (
 
 	// Logger
 	logger = logging.MustGetLogger("gnet")
+
+	messagesCount = metrics.MustRegisterCounter(metrics.NewCounterVec("skycoin_gnet_messages_total",
+		"Number of messages received from and sent to peers, by direction and message type", "direction", "type"))
 )
 
 // ReadError connection read error
@@ -130,6 +134,12 @@ type Config struct {
 	ConnectCallback ConnectCallback
 	// Triggered on client connect failure
 	ConnectFailureCallback ConnectFailureCallback
+	// Triggered for every message that is decoded or sent successfully
+	MessageCallback MessageCallback
+	// Creates the listener for incoming connections. Defaults to net.Listen
+	Listen ListenFunc
+	// Makes outgoing connections. Defaults to net.DialTimeout
+	Dial DialFunc
 	// Print debug logs
 	DebugPrint bool
 	// Default "trusted" peers
@@ -226,6 +236,17 @@ type ConnectCallback func(addr string, id uint64, solicited bool)
 // ConnectFailureCallback trigger on client connect failure
 type ConnectFailureCallback func(addr string, solicited bool, err error)
 
+// ListenFunc creates a listener, like net.Listen
+type ListenFunc func(network, address string) (net.Listener, error)
+
+// DialFunc makes a connection, like net.DialTimeout
+type DialFunc func(network, address string, timeout time.Duration) (net.Conn, error)
+
+// MessageCallback triggered when a message is received or sent.
+// msg is the message ID prefix followed by the encoded message body, without the length prefix.
+// It is called from the connection's read and write goroutines, so it must be safe for concurrent use.
+type MessageCallback func(addr string, id uint64, sent bool, msg []byte)
+
 // ConnectionPool connection pool
 type ConnectionPool struct {
 	// Configuration parameters
@@ -302,7 +323,12 @@ func (pool *ConnectionPool) Run() error {
 	addr := fmt.Sprintf("%s:%v", pool.Config.Address, pool.Config.Port)
 	logger.Infof("Listening for connections on %s...", addr)
 
-	ln, err := net.Listen("tcp", addr)
+	listen := pool.Config.Listen
+	if listen == nil {
+		listen = net.Listen
+	}
+
+	ln, err := listen("tcp", addr)
 	if err != nil {
 		return err
 	}
@@ -682,6 +708,17 @@ func (pool *ConnectionPool) sendLoop(conn *Connection, timeout time.Duration, ma
 				if err := pool.updateLastSent(conn.Addr(), Now()); err != nil {
 					logger.WithField("addr", conn.Addr()).WithError(err).Warning("updateLastSent failed")
 				}
+
+				if prefix, ok := MessageIDMap[reflect.ValueOf(m).Elem().Type()]; ok {
+					messagesCount.WithLabelValues("out", string(prefix[:])).Inc()
+				}
+
+				if pool.Config.MessageCallback != nil {
+					// The message was encoded successfully by sendMessage, so it can't fail here
+					if b, err := EncodeMessage(m); err == nil {
+						pool.Config.MessageCallback(conn.Addr(), conn.ID, true, b[messageLengthPrefixSize:])
+					}
+				}
 			}
 
 			sr := newSendResult(conn.Addr(), m, err)
@@ -827,7 +864,12 @@ func (pool *ConnectionPool) Connect(address string) error {
 	}
 
 	logger.WithField("addr", address).Debugf("Making TCP connection")
-	conn, err := net.DialTimeout("tcp", address, pool.Config.DialTimeout)
+	dial := pool.Config.Dial
+	if dial == nil {
+		dial = net.DialTimeout
+	}
+
+	conn, err := dial("tcp", address, pool.Config.DialTimeout)
 	if err != nil {
 		return err
 	}
@@ -1019,9 +1061,19 @@ func (pool *ConnectionPool) BroadcastMessage(msg Message, addrs []string) ([]uin
 // be the value returned from the message handler.
 func (pool *ConnectionPool) receiveMessage(c *Connection, msg []byte) error {
 	m, err := convertToMessage(c.ID, msg, pool.Config.DebugPrint)
-	if err != nil {
+	switch err {
+	case nil:
+	case ErrDisconnectUnknownMessage:
+		// Ignore unknown messages so that new message types can be
+		// introduced without disconnecting peers that do not understand them
+		return nil
+	default:
 		return err
 	}
+	messagesCount.WithLabelValues("in", string(msg[:messagePrefixLength])).Inc()
+	if pool.Config.MessageCallback != nil {
+		pool.Config.MessageCallback(c.Addr(), c.ID, false, msg)
+	}
 	if err := pool.updateLastRecv(c.Addr(), Now()); err != nil {
 		return err
 	}
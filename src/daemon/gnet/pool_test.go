@@ -1180,6 +1180,12 @@ func TestPoolReceiveMessage(t *testing.T) {
 	err = p.receiveMessage(c, b)
 	require.Error(t, err)
 
+	// Unknown message received, which is ignored
+	b = []byte("UNKN")
+	b = append(b, byte(7))
+	err = p.receiveMessage(c, b)
+	require.NoError(t, err)
+
 	// Valid message, but handler returns a DisconnectReason
 	b = make([]byte, 0)
 	b = append(b, ErrorPrefix[:]...)
@@ -1190,6 +1196,87 @@ func TestPoolReceiveMessage(t *testing.T) {
 	<-q
 }
 
+func TestPoolMessageCallback(t *testing.T) {
+	wait()
+	resetHandler()
+	EraseMessages()
+	RegisterMessage(BytePrefix, ByteMessage{})
+	VerifyMessages()
+
+	type captured struct {
+		addr string
+		id   uint64
+		sent bool
+		msg  []byte
+	}
+
+	cfg := newTestConfig()
+	cfg.WriteTimeout = time.Second
+	cfg.ConnectionWriteQueueSize = 8
+	p, err := NewConnectionPool(cfg, nil)
+	require.NoError(t, err)
+
+	prefix := string(BytePrefix[:])
+	inCount := messagesCount.WithLabelValues("in", prefix).Value()
+	outCount := messagesCount.WithLabelValues("out", prefix).Value()
+
+	msgs := make(chan captured, 4)
+	p.Config.MessageCallback = func(addr string, id uint64, sent bool, msg []byte) {
+		msgs <- captured{addr, id, sent, msg}
+	}
+
+	cc := make(chan *Connection, 1)
+	p.Config.ConnectCallback = func(addr string, id uint64, solicited bool) {
+		cc <- p.pool[1]
+	}
+
+	q := make(chan struct{})
+	go func() {
+		defer close(q)
+		err := p.Run()
+		require.NoError(t, err)
+	}()
+	wait()
+
+	// Received messages are captured
+	c := NewConnection(p, 1, NewDummyConn(addr), 10, true)
+	b := append([]byte{}, BytePrefix[:]...)
+	b = append(b, byte(7))
+	err = p.receiveMessage(c, b)
+	require.NoError(t, err)
+
+	m := <-msgs
+	require.Equal(t, captured{addr, 1, false, b}, m)
+	require.Equal(t, inCount+1, messagesCount.WithLabelValues("in", prefix).Value())
+
+	// Unknown messages are not captured
+	err = p.receiveMessage(c, append([]byte("UNKN"), byte(7)))
+	require.NoError(t, err)
+	require.Empty(t, msgs)
+
+	// Sent messages are captured
+	_, err = net.Dial("tcp", addr)
+	require.NoError(t, err)
+	c = <-cc
+
+	err = p.SendMessage(c.Addr(), NewByteMessage(88))
+	require.NoError(t, err)
+
+	select {
+	case m = <-msgs:
+	case <-time.After(time.Second * 2):
+		t.Fatal("MessageCallback was not triggered for a sent message")
+	}
+	require.True(t, m.sent)
+	require.Equal(t, c.Addr(), m.addr)
+	require.Equal(t, append(BytePrefix[:], byte(88)), m.msg)
+	require.Equal(t, outCount+1, messagesCount.WithLabelValues("out", prefix).Value())
+	require.Equal(t, inCount+1, messagesCount.WithLabelValues("in", prefix).Value())
+
+	p.Shutdown()
+	<-q
+}
+
 // Helpers
 
 func wait() {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"reflect"
 	"strings"
 
 	"github.com/sirupsen/logrus"
@@ -34,6 +35,8 @@ import (
 type MessageConfig struct {
 	Prefix  gnet.MessagePrefix
 	Message interface{}
+	// Features that a peer must advertise to be sent this message. Zero for base protocol messages.
+	Features Feature
 }
 
 // NewMessageConfig creates message config
@@ -44,6 +47,13 @@ func NewMessageConfig(prefix string, m interface{}) MessageConfig {
 	}
 }
 
+// NewFeatureMessageConfig creates message config for a message that is only sent to peers advertising features
+func NewFeatureMessageConfig(prefix string, m interface{}, features Feature) MessageConfig {
+	mc := NewMessageConfig(prefix, m)
+	mc.Features = features
+	return mc
+}
+
 //go:generate skyencoder -unexported -struct IntroductionMessage
 //go:generate skyencoder -unexported -struct GivePeersMessage
 //go:generate skyencoder -unexported -struct GetBlocksMessage
@@ -88,9 +98,14 @@ func NewMessagesConfig() MessagesConfig {
 	}
 }
 
-// Register registers our Messages with gnet
+// Register registers our Messages with gnet.
+// Messages that are already registered with the same prefix are skipped,
+// so that multiple daemons can be created in one process.
 func (msc *MessagesConfig) Register() {
 	for _, mc := range msc.Messages {
+		if t, ok := gnet.MessageIDReverseMap[mc.Prefix]; ok && t == reflect.TypeOf(mc.Message) {
+			continue
+		}
 		gnet.RegisterMessage(mc.Prefix, mc.Message)
 	}
 	gnet.VerifyMessages()
@@ -98,13 +113,15 @@ func (msc *MessagesConfig) Register() {
 
 // Messages messages struct
 type Messages struct {
-	Config MessagesConfig
+	Config   MessagesConfig
+	features messageFeatures
 }
 
 // NewMessages creates Messages
 func NewMessages(c MessagesConfig) *Messages {
 	return &Messages{
-		Config: c,
+		Config:   c,
+		features: newMessageFeatures(c.Messages),
 	}
 }
 
@@ -320,7 +337,7 @@ func (gpm *GivePeersMessage) process(d daemoner) {
 		"count":  len(peers),
 	}).Debug("Received peers via PEX")
 
-	d.addPeers(peers)
+	d.addPeers(gpm.c.Addr, peers)
 }
 
 // IntroductionMessage is sent on first connect by both parties
@@ -329,6 +346,7 @@ type IntroductionMessage struct {
 	UserAgent            useragent.Data       `enc:"-"`
 	UnconfirmedVerifyTxn params.VerifyTxn     `enc:"-"`
 	GenesisHash          cipher.SHA256        `enc:"-"`
+	Features             Feature              `enc:"-"`
 
 	// Mirror is a random value generated on client startup that is used to identify self-connections
 	Mirror uint32
@@ -348,20 +366,22 @@ type IntroductionMessage struct {
 	// MaxDropletPrecision uint8 // maximum number of decimal places for announced txns
 	// UserAgent           string `enc:",maxlen=256"`
 	// GenesisHash         cipher.SHA256 // genesis block hash
+	// Features            uint64 // bitfield of optional protocol features supported by the peer
+	// Any data following these fields is ignored, to allow for future extensions.
 	Extra []byte `enc:",omitempty"`
 }
 
 // NewIntroductionMessage creates introduction message
-func NewIntroductionMessage(mirror uint32, version int32, port uint16, pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, genesisHash cipher.SHA256) *IntroductionMessage {
+func NewIntroductionMessage(mirror uint32, version int32, port uint16, pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, genesisHash cipher.SHA256, features Feature) *IntroductionMessage {
 	return &IntroductionMessage{
 		Mirror:          mirror,
 		ProtocolVersion: version,
 		ListenPort:      port,
-		Extra:           newIntroductionMessageExtra(pubkey, userAgent, verifyParams, genesisHash),
+		Extra:           newIntroductionMessageExtra(pubkey, userAgent, verifyParams, genesisHash, features),
 	}
 }
 
-func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, genesisHash cipher.SHA256) []byte {
+func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyParams params.VerifyTxn, genesisHash cipher.SHA256, features Feature) []byte {
 	if len(userAgent) > useragent.MaxLen {
 		logger.WithFields(logrus.Fields{
 			"userAgent": userAgent,
@@ -379,8 +399,9 @@ func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyP
 
 	userAgentSerialized := encoder.SerializeString(userAgent)
 	verifyParamsSerialized := encoder.Serialize(verifyParams)
+	featuresSerialized := encoder.SerializeAtomic(uint64(features))
 
-	extra := make([]byte, len(pubkey)+len(userAgentSerialized)+len(verifyParamsSerialized)+len(genesisHash))
+	extra := make([]byte, len(pubkey)+len(userAgentSerialized)+len(verifyParamsSerialized)+len(genesisHash)+len(featuresSerialized))
 
 	copy(extra[:len(pubkey)], pubkey[:])
 	i := len(pubkey)
@@ -389,6 +410,8 @@ func newIntroductionMessageExtra(pubkey cipher.PubKey, userAgent string, verifyP
 	copy(extra[i:], userAgentSerialized)
 	i += len(userAgentSerialized)
 	copy(extra[i:i+len(genesisHash)], genesisHash[:])
+	i += len(genesisHash)
+	copy(extra[i:], featuresSerialized)
 
 	return extra
 }
@@ -580,6 +603,22 @@ func (intro *IntroductionMessage) Verify(dc DaemonConfig, logFields logrus.Field
 	}
 	copy(intro.GenesisHash[:], intro.Extra[i:])
 
+	// v28 adds the features bitfield
+	if remainingLen <= len(intro.GenesisHash) {
+		return nil
+	}
+	i += len(intro.GenesisHash)
+
+	// Older peers may send other data after the genesis hash, which was ignored before v28,
+	// so data too short to be the features bitfield is ignored too
+	var features uint64
+	if _, err := encoder.DeserializeAtomic(intro.Extra[i:], &features); err != nil {
+		logger.WithError(err).WithFields(logFields).Info("Extra data features could not be deserialized: not enough data, assuming no features")
+		intro.Features = 0
+		return nil
+	}
+	intro.Features = Feature(features)
+
 	return nil
 }
 
@@ -31,8 +31,9 @@ func TestIntroductionMessage(t *testing.T) {
 		BurnFactor:          4,
 		MaxTransactionSize:  32768,
 		MaxDropletPrecision: 3,
-	}, genesisHash)
-	invalidGenesisHashExtra = invalidGenesisHashExtra[:len(invalidGenesisHashExtra)-2]
+	}, genesisHash, 0)
+	// Remove the features and the end of the genesis hash
+	invalidGenesisHashExtra = invalidGenesisHashExtra[:len(invalidGenesisHashExtra)-10]
 
 	type daemonMockValue struct {
 		protocolVersion          uint32
@@ -144,7 +145,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}, genesisHash),
+				}, genesisHash, 0),
 			},
 		},
 		{
@@ -187,7 +188,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}, genesisHash), []byte("additional data")...),
+				}, genesisHash, 0), []byte("additional data")...),
 			},
 		},
 		{
@@ -232,7 +233,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}, genesisHash),
+				}, genesisHash, 0),
 			},
 		},
 		{
@@ -392,7 +393,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}, genesisHash),
+				}, genesisHash, 0),
 			},
 		},
 		{
@@ -435,7 +436,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}, genesisHash),
+				}, genesisHash, 0),
 			},
 		},
 		{
@@ -477,7 +478,7 @@ func TestIntroductionMessage(t *testing.T) {
 					BurnFactor:          4,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}, genesisHash),
+				}, genesisHash, 0),
 			},
 		},
 	}
@@ -568,7 +569,7 @@ func TestMessageEncodeDecode(t *testing.T) {
 					BurnFactor:          2,
 					MaxTransactionSize:  32768,
 					MaxDropletPrecision: 3,
-				}, introGenesisHash),
+				}, introGenesisHash, SupportedFeatures),
 			},
 		},
 		{
@@ -1086,6 +1087,23 @@ func TestGetBlocksMessageProcess(t *testing.T) {
 	d.AssertExpectations(t)
 }
 
+func TestMessagesConfigRegisterTwice(t *testing.T) {
+	defer gnet.EraseMessages()
+	setupMsgEncoding()
+
+	n := len(gnet.MessageIDMap)
+	require.NotZero(t, n)
+
+	// Registering the same messages again is a no-op
+	c := NewMessagesConfig()
+	require.NotPanics(t, c.Register)
+	require.Len(t, gnet.MessageIDMap, n)
+
+	// A different message under a registered prefix still panics
+	c.Messages = []MessageConfig{NewMessageConfig("INTR", PingMessage{})}
+	require.Panics(t, c.Register)
+}
+
 func setupMsgEncoding() {
 	gnet.EraseMessages()
 	var messagesConfig = NewMessagesConfig()
@@ -48,13 +48,13 @@ func (_m *mockDaemoner) Disconnect(addr string, r gnet.DisconnectReason) error {
 	return r0
 }
 
-// addPeers provides a mock function with given fields: addrs
-func (_m *mockDaemoner) addPeers(addrs []string) int {
-	ret := _m.Called(addrs)
+// addPeers provides a mock function with given fields: source, addrs
+func (_m *mockDaemoner) addPeers(source string, addrs []string) int {
+	ret := _m.Called(source, addrs)
 
 	var r0 int
-	if rf, ok := ret.Get(0).(func([]string) int); ok {
-		r0 = rf(addrs)
+	if rf, ok := ret.Get(0).(func(string, []string) int); ok {
+		r0 = rf(source, addrs)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
@@ -0,0 +1,232 @@
+package pex
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+/*
+Peers are placed into "new" and "tried" tables, each of which is divided into buckets.
+The bucket a peer lands in is derived from a secret key, the peer's network group and,
+for the new table, the network group of the peer that told us about it. A single source
+network group can only reach a small number of new buckets, and a single destination
+network group can only reach a small number of tried buckets, so flooding GivePeersMessages
+from one subnet can only displace a small fraction of the peerlist.
+
+Peers are moved from the new table to the tried table once an outgoing connection
+to them completes an introduction.
+*/
+
+const (
+	// newBucketCount is the number of buckets in the new table
+	newBucketCount = 1024
+	// triedBucketCount is the number of buckets in the tried table
+	triedBucketCount = 256
+	// bucketSize is the maximum number of peers in a bucket
+	bucketSize = 64
+	// newBucketsPerSourceGroup is the number of new buckets a single source network group can reach
+	newBucketsPerSourceGroup = 64
+	// triedBucketsPerGroup is the number of tried buckets a single destination network group can reach
+	triedBucketsPerGroup = 8
+	// bucketKeyLen is the length of the secret key used to place peers into buckets
+	bucketKeyLen = 32
+	// localNetgroup is the network group of loopback addresses and of locally added peers
+	localNetgroup = "local"
+	// terribleAge is the age after which a peer can be evicted from a full bucket
+	terribleAge = time.Hour * 24
+)
+
+// netgroup returns the network group of an ip:port or ip address.
+// IPv4 addresses are grouped by /16, IPv6 addresses by /32.
+// Loopback addresses, unparseable addresses and the empty string are in the local group.
+func netgroup(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() {
+		return localNetgroup
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("ipv4:%d.%d", ip4[0], ip4[1])
+	}
+
+	return fmt.Sprintf("ipv6:%x", []byte(ip[:4]))
+}
+
+// newBucketKey creates a random key for bucket placement
+func newBucketKey() []byte {
+	key := make([]byte, bucketKeyLen)
+	if _, err := rand.Read(key); err != nil {
+		logger.Panic(err)
+	}
+	return key
+}
+
+// bucketID identifies a bucket in the new or tried table
+type bucketID struct {
+	tried bool
+	index uint64
+}
+
+// bucketHash hashes the key with the parts, returning a uint64
+func bucketHash(key []byte, parts ...string) uint64 {
+	h := sha256.New()
+	h.Write(key) // nolint: errcheck
+	for _, p := range parts {
+		// Length prefix each part so that different splits can't collide
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(p)))
+		h.Write(n[:])      // nolint: errcheck
+		h.Write([]byte(p)) // nolint: errcheck
+	}
+	return binary.BigEndian.Uint64(h.Sum(nil)[:8])
+}
+
+// newBucket returns the new table bucket for a peer learned from a source network group
+func newBucket(key []byte, srcGroup, addr string) bucketID {
+	dstGroup := netgroup(addr)
+	n := bucketHash(key, "new", srcGroup, dstGroup) % newBucketsPerSourceGroup
+	return bucketID{
+		index: bucketHash(key, "new", srcGroup, fmt.Sprint(n)) % newBucketCount,
+	}
+}
+
+// triedBucket returns the tried table bucket for a peer
+func triedBucket(key []byte, addr string) bucketID {
+	n := bucketHash(key, "tried", addr) % triedBucketsPerGroup
+	return bucketID{
+		tried: true,
+		index: bucketHash(key, "tried", netgroup(addr), fmt.Sprint(n)) % triedBucketCount,
+	}
+}
+
+// isTerrible returns true if the peer can be evicted from a full bucket
+func isTerrible(p *Peer, now int64) bool {
+	if p.Trusted {
+		return false
+	}
+	return p.RetryTimes >= MaxPeerRetryTimes || now-p.LastSeen > int64(terribleAge/time.Second)
+}
+
+// bucketOf returns the bucket that a peer belongs to
+func (pl *peerlist) bucketOf(p *Peer) bucketID {
+	if p.Tried {
+		return triedBucket(pl.key, p.Addr)
+	}
+
+	src := p.Source
+	if src == "" {
+		src = localNetgroup
+	}
+	return newBucket(pl.key, src, p.Addr)
+}
+
+// index adds a peer to the bucket and network group indexes
+func (pl *peerlist) index(p *Peer) {
+	b := pl.bucketOf(p)
+	if pl.buckets[b] == nil {
+		pl.buckets[b] = make(map[string]struct{})
+	}
+	pl.buckets[b][p.Addr] = struct{}{}
+	pl.groups[netgroup(p.Addr)]++
+}
+
+// unindex removes a peer from the bucket and network group indexes
+func (pl *peerlist) unindex(p *Peer) {
+	b := pl.bucketOf(p)
+	delete(pl.buckets[b], p.Addr)
+	if len(pl.buckets[b]) == 0 {
+		delete(pl.buckets, b)
+	}
+
+	g := netgroup(p.Addr)
+	pl.groups[g]--
+	if pl.groups[g] <= 0 {
+		delete(pl.groups, g)
+	}
+}
+
+// makeRoom tries to make room in a bucket, by evicting its oldest evictable peer.
+// Returns false if the bucket is full and no peer could be evicted.
+func (pl *peerlist) makeRoom(b bucketID) bool {
+	if len(pl.buckets[b]) < bucketSize {
+		return true
+	}
+
+	now := time.Now().UTC().Unix()
+	var worst *Peer
+	for addr := range pl.buckets[b] {
+		p := pl.peers[addr]
+		if !isTerrible(p, now) {
+			continue
+		}
+		if worst == nil || p.LastSeen < worst.LastSeen {
+			worst = p
+		}
+	}
+
+	if worst == nil {
+		return false
+	}
+
+	pl.removePeer(worst.Addr)
+	return true
+}
+
+// markTried moves a peer from the new table to the tried table.
+// If the tried bucket is full, the oldest non-trusted peer in it is moved back to the new table.
+func (pl *peerlist) markTried(addr string) error {
+	p, ok := pl.peers[addr]
+	if !ok {
+		return fmt.Errorf("mark peer tried failed: %v does not exist in peer list", addr)
+	}
+
+	if p.Tried {
+		return nil
+	}
+
+	b := triedBucket(pl.key, addr)
+	if len(pl.buckets[b]) >= bucketSize {
+		var oldest *Peer
+		for a := range pl.buckets[b] {
+			q := pl.peers[a]
+			if q.Trusted {
+				continue
+			}
+			if oldest == nil || q.LastSeen < oldest.LastSeen {
+				oldest = q
+			}
+		}
+
+		if oldest == nil {
+			return fmt.Errorf("mark peer tried failed: tried bucket for %v is full of trusted peers", addr)
+		}
+
+		pl.unindex(oldest)
+		oldest.Tried = false
+		if pl.makeRoom(pl.bucketOf(oldest)) {
+			pl.index(oldest)
+		} else {
+			delete(pl.peers, oldest.Addr)
+		}
+	}
+
+	pl.unindex(p)
+	p.Tried = true
+	pl.index(p)
+
+	return nil
+}
+
+// netgroupLen returns the number of peers in the network group of addr
+func (pl *peerlist) netgroupLen(addr string) int {
+	return pl.groups[netgroup(addr)]
+}
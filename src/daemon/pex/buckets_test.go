@@ -0,0 +1,268 @@
+package pex
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+func TestNetgroup(t *testing.T) {
+	tt := []struct {
+		addr  string
+		group string
+	}{
+		{"112.32.32.14:7200", "ipv4:112.32"},
+		{"112.32.99.1:6000", "ipv4:112.32"},
+		{"112.33.32.14:7200", "ipv4:112.33"},
+		{"112.32.32.14", "ipv4:112.32"},
+		{"127.0.0.1:6000", localNetgroup},
+		{"", localNetgroup},
+		{"not an address", localNetgroup},
+		{"[2001:db8:1:2::1]:6000", "ipv6:20010db8"},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.addr, func(t *testing.T) {
+			require.Equal(t, tc.group, netgroup(tc.addr))
+		})
+	}
+}
+
+func TestPeerlistAddPeerFromNetgroupLimit(t *testing.T) {
+	pl := newPeerlist()
+
+	for i := 0; i < 5; i++ {
+		added := pl.addPeerFrom("44.1.1.1:6000", fmt.Sprintf("112.32.%d.1:6000", i), 3)
+		require.Equal(t, i < 3, added)
+	}
+
+	require.Equal(t, 3, pl.len())
+	require.Equal(t, 3, pl.netgroupLen("112.32.0.0"))
+
+	// A peer from a different network group is accepted
+	require.True(t, pl.addPeerFrom("44.1.1.1:6000", "113.32.0.1:6000", 3))
+
+	// A known peer is accepted, even if its network group is full
+	require.True(t, pl.addPeerFrom("44.1.1.1:6000", "112.32.0.1:6000", 3))
+	require.Equal(t, 4, pl.len())
+
+	p, ok := pl.getPeer("113.32.0.1:6000")
+	require.True(t, ok)
+	require.Equal(t, "ipv4:44.1", p.Source)
+	require.False(t, p.Tried)
+}
+
+func TestPeerlistAddPeerFromSourceFlood(t *testing.T) {
+	pl := newPeerlist()
+
+	// A single source network group advertising peers from many network groups
+	// can only fill a limited number of new buckets
+	n := 0
+	for i := 0; i < 200; i++ {
+		for j := 0; j < 100; j++ {
+			if pl.addPeerFrom("44.1.1.1:6000", fmt.Sprintf("%d.%d.1.1:6000", i+1, j), 0) {
+				n++
+			}
+		}
+	}
+
+	require.Equal(t, n, pl.len())
+	require.True(t, n <= newBucketsPerSourceGroup*bucketSize)
+	require.True(t, len(pl.buckets) <= newBucketsPerSourceGroup)
+
+	// A peer advertised by a different source network group can still be added
+	require.True(t, pl.addPeerFrom("45.1.1.1:6000", "201.1.1.1:6000", 0))
+}
+
+func TestPeerlistAddPeerFromEvictsTerrible(t *testing.T) {
+	pl := newPeerlist()
+
+	source := "44.1.1.1:6000"
+	addr := "112.32.0.1:6000"
+	b := newBucket(pl.key, netgroup(source), addr)
+
+	// Fill the bucket with old peers
+	old := time.Now().UTC().Unix() - int64(terribleAge/time.Second) - 60
+	var peers []Peer
+	for i := 0; len(peers) < bucketSize; i++ {
+		a := fmt.Sprintf("112.32.%d.%d:6000", i/250, i%250+2)
+		peers = append(peers, Peer{
+			Addr:     a,
+			LastSeen: old,
+			Source:   netgroup(source),
+		})
+	}
+	pl.setPeers(peers)
+	require.Len(t, pl.buckets[b], bucketSize)
+
+	require.True(t, pl.addPeerFrom(source, addr, 0))
+	require.Len(t, pl.buckets[b], bucketSize)
+	require.True(t, pl.hasPeer(addr))
+	require.Equal(t, bucketSize, pl.len())
+
+	// Once all peers in the bucket are fresh, no more peers can be added to it
+	for a := range pl.buckets[b] {
+		pl.peers[a].Seen()
+	}
+
+	// Peers from the same source and destination network groups share a bucket
+	a := "112.32.255.1:6000"
+	require.Equal(t, b, newBucket(pl.key, netgroup(source), a))
+	require.False(t, pl.addPeerFrom(source, a, 0))
+	require.False(t, pl.hasPeer(a))
+}
+
+func TestPeerlistMarkTried(t *testing.T) {
+	pl := newPeerlist()
+	require.True(t, pl.addPeerFrom("44.1.1.1:6000", testPeers[0], 0))
+
+	b := pl.bucketOf(pl.peers[testPeers[0]])
+	require.False(t, b.tried)
+
+	require.NoError(t, pl.markTried(testPeers[0]))
+	p, ok := pl.getPeer(testPeers[0])
+	require.True(t, ok)
+	require.True(t, p.Tried)
+
+	_, ok = pl.buckets[b]
+	require.False(t, ok)
+	require.Contains(t, pl.buckets[triedBucket(pl.key, testPeers[0])], testPeers[0])
+
+	// Marking twice is a no-op
+	require.NoError(t, pl.markTried(testPeers[0]))
+	require.Equal(t, 1, pl.groups[netgroup(testPeers[0])])
+
+	require.Error(t, pl.markTried(testPeers[1]))
+
+	pl.removePeer(testPeers[0])
+	require.Empty(t, pl.buckets)
+	require.Empty(t, pl.groups)
+}
+
+func TestPeerlistRandomDiverse(t *testing.T) {
+	pl := newPeerlist()
+	pl.addPeers([]string{
+		"112.32.0.1:6000",
+		"112.32.0.2:6000",
+		"112.32.0.3:6000",
+		"113.32.0.1:6000",
+		"113.32.0.2:6000",
+		"114.32.0.1:6000",
+	})
+	require.NoError(t, pl.markTried("113.32.0.1:6000"))
+
+	for i := 0; i < 10; i++ {
+		ps := pl.randomDiverse(0, nil, nil)
+		require.Len(t, ps, 3)
+
+		groups := make(map[string]struct{})
+		for _, p := range ps {
+			groups[netgroup(p.Addr)] = struct{}{}
+		}
+		require.Len(t, groups, 3)
+
+		ps = pl.randomDiverse(0, []string{"112.32.5.5:6000"}, nil)
+		require.Len(t, ps, 2)
+		for _, p := range ps {
+			require.NotEqual(t, "ipv4:112.32", netgroup(p.Addr))
+		}
+
+		ps = pl.randomDiverse(1, nil, nil)
+		require.Len(t, ps, 1)
+		// The tried table is picked from first
+		require.Equal(t, "113.32.0.1:6000", ps[0].Addr)
+
+		// Once every network group is used, the remaining peers are picked from any group
+		ps = pl.randomDiverse(5, []string{"112.32.0.1:6000"}, nil)
+		require.Len(t, ps, 5)
+		addrs := make(map[string]struct{})
+		for _, p := range ps {
+			require.NotEqual(t, "112.32.0.1:6000", p.Addr)
+			addrs[p.Addr] = struct{}{}
+		}
+		require.Len(t, addrs, 5)
+	}
+
+	// All of the local peers are in the same network group
+	pl = newPeerlist()
+	pl.addPeers([]string{
+		"127.0.0.1:6000",
+		"127.0.0.1:6001",
+		"127.0.0.1:6002",
+	})
+	ps := pl.randomDiverse(3, []string{"127.0.0.1:6003"}, nil)
+	require.Len(t, ps, 3)
+}
+
+func TestPeerlistSaveVersioned(t *testing.T) {
+	pl := newPeerlist()
+	pl.addPeers(testPeers[:2])
+	require.True(t, pl.addPeerFrom("44.1.1.1:6000", testPeers[2], 0))
+	require.NoError(t, pl.markTried(testPeers[0]))
+
+	f, removeFile := preparePeerlistFile(t)
+	defer removeFile()
+	require.NoError(t, pl.save(f))
+
+	var pf PeersFileJSON
+	require.NoError(t, file.LoadJSON(f, &pf))
+	require.Equal(t, peersFileVersion, pf.Version)
+	require.Len(t, pf.Peers, 3)
+
+	peers, key, err := loadCachedPeersFileWithKey(f)
+	require.NoError(t, err)
+	require.Equal(t, pl.key, key)
+	require.Len(t, peers, 3)
+	require.True(t, peers[testPeers[0]].Tried)
+	require.False(t, peers[testPeers[1]].Tried)
+	require.Equal(t, "ipv4:44.1", peers[testPeers[2]].Source)
+
+	// Unsupported future versions are rejected
+	pf.Version = peersFileVersion + 1
+	require.NoError(t, file.SaveJSON(f, pf, 0600))
+	_, _, err = loadCachedPeersFileWithKey(f)
+	require.Error(t, err)
+
+	// An invalid key is replaced, but the peers are loaded
+	pf.Version = peersFileVersion
+	pf.Key = "abcd"
+	require.NoError(t, file.SaveJSON(f, pf, 0600))
+	peers, key, err = loadCachedPeersFileWithKey(f)
+	require.NoError(t, err)
+	require.Nil(t, key)
+	require.Len(t, peers, 3)
+}
+
+func TestPexAddPeersFrom(t *testing.T) {
+	dir, removeDir := preparePeerlistDir(t)
+	defer removeDir()
+
+	cfg := NewConfig()
+	cfg.DataDirectory = dir
+	cfg.MaxPeersPerNetgroup = 2
+	cfg.Max = 4
+
+	px, err := New(cfg)
+	require.NoError(t, err)
+
+	n := px.AddPeersFrom("44.1.1.1:6000", []string{
+		testPeers[0],
+		testPeers[1],
+		testPeers[2],
+		wrongPortPeer,
+		"113.32.0.1:6000",
+		"114.32.0.1:6000",
+		"115.32.0.1:6000",
+	})
+	require.Equal(t, 4, n)
+	require.True(t, px.IsFull())
+
+	require.NoError(t, px.MarkTried(testPeers[0]))
+	p, ok := px.GetPeer(testPeers[0])
+	require.True(t, ok)
+	require.True(t, p.Tried)
+}
@@ -1,6 +1,7 @@
 package pex
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -26,36 +27,99 @@ func (ps Peers) ToAddrs() []string {
 	return addrs
 }
 
-// peerlist is a map of addresses to *PeerStates
+// peersFileVersion is the version of the peers.json format written by save.
+// Unversioned files are a JSON object mapping addresses to PeerJSON.
+const peersFileVersion = 2
+
+// peerlist is a map of addresses to *PeerStates, indexed into new and tried buckets
 type peerlist struct {
 	peers map[string]*Peer
+	// key randomizes which bucket a peer is placed into
+	key []byte
+	// buckets holds the addresses in each bucket of the new and tried tables
+	buckets map[bucketID]map[string]struct{}
+	// groups counts the peers in each network group
+	groups map[string]int
 }
 
 func newPeerlist() peerlist {
+	return newPeerlistWithKey(newBucketKey())
+}
+
+func newPeerlistWithKey(key []byte) peerlist {
 	return peerlist{
-		peers: make(map[string]*Peer),
+		peers:   make(map[string]*Peer),
+		key:     key,
+		buckets: make(map[bucketID]map[string]struct{}),
+		groups:  make(map[string]int),
 	}
 }
 
 // Filter peers filter
 type Filter func(peer Peer) bool
 
+// PeersFileJSON is the versioned format of the cached peers.json file
+type PeersFileJSON struct {
+	Version int                 `json:"version"`
+	Key     string              `json:"key"` // hex-encoded bucket placement key
+	Peers   map[string]PeerJSON `json:"peers"`
+}
+
 // loadCachedPeersFile loads peers from the cached peers.json file
 func loadCachedPeersFile(path string) (map[string]*Peer, error) {
-	peersJSON := make(map[string]PeerJSON)
-	err := file.LoadJSON(path, &peersJSON)
+	peers, _, err := loadCachedPeersFileWithKey(path)
+	return peers, err
+}
+
+// loadCachedPeersFileWithKey loads peers and the bucket placement key from the cached peers.json file.
+// The key is nil if the file is unversioned or has no valid key.
+func loadCachedPeersFileWithKey(path string) (map[string]*Peer, []byte, error) {
+	raw := make(map[string]json.RawMessage)
+	err := file.LoadJSON(path, &raw)
 
 	if os.IsNotExist(err) {
 		logger.WithField("path", path).Info("File does not exist")
-		return nil, nil
+		return nil, nil, nil
 	} else if err == io.EOF {
 		logger.WithField("path", path).Error("Corrupt or empty file")
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	if err != nil {
 		logger.WithField("path", path).WithError(err).Error("Failed to load peers file")
-		return nil, err
+		return nil, nil, err
+	}
+
+	var key []byte
+	peersJSON := make(map[string]PeerJSON)
+	if _, ok := raw["version"]; ok {
+		var pf PeersFileJSON
+		if err := file.LoadJSON(path, &pf); err != nil {
+			logger.WithField("path", path).WithError(err).Error("Failed to load versioned peers file")
+			return nil, nil, err
+		}
+
+		if pf.Version > peersFileVersion {
+			err := fmt.Errorf("peers file version %d is newer than the supported version %d", pf.Version, peersFileVersion)
+			logger.WithField("path", path).WithError(err).Error()
+			return nil, nil, err
+		}
+
+		if k, err := hex.DecodeString(pf.Key); err != nil || len(k) != bucketKeyLen {
+			logger.WithField("path", path).Error("Invalid bucket key in peers file, a new key will be generated")
+		} else {
+			key = k
+		}
+
+		if pf.Peers != nil {
+			peersJSON = pf.Peers
+		}
+	} else {
+		// Unversioned file, a map of addresses to PeerJSON
+		if err := file.LoadJSON(path, &peersJSON); err != nil {
+			logger.WithField("path", path).WithError(err).Error("Failed to load peers file")
+			return nil, nil, err
+		}
 	}
 
 	peers := make(map[string]*Peer, len(peersJSON))
@@ -87,13 +151,17 @@ func loadCachedPeersFile(path string) (map[string]*Peer, error) {
 		peers[a] = peer
 	}
 
-	return peers, nil
+	return peers, key, nil
 }
 
 func (pl *peerlist) setPeers(peers []Peer) {
 	for _, p := range peers {
 		np := p
+		if old, ok := pl.peers[p.Addr]; ok {
+			pl.unindex(old)
+		}
 		pl.peers[p.Addr] = &np
+		pl.index(&np)
 	}
 }
 
@@ -102,6 +170,7 @@ func (pl *peerlist) hasPeer(addr string) bool {
 	return ok && p != nil
 }
 
+// addPeer adds a locally discovered peer. Locally discovered peers are not subject to bucket limits.
 func (pl *peerlist) addPeer(addr string) {
 	if p, ok := pl.peers[addr]; ok && p != nil {
 		p.Seen()
@@ -110,6 +179,7 @@ func (pl *peerlist) addPeer(addr string) {
 
 	peer := NewPeer(addr)
 	pl.peers[addr] = peer
+	pl.index(peer)
 }
 
 func (pl *peerlist) addPeers(addrs []string) {
@@ -118,6 +188,32 @@ func (pl *peerlist) addPeers(addrs []string) {
 	}
 }
 
+// addPeerFrom adds a peer that was advertised by the peer at source.
+// The peer is rejected if its network group already has maxPerNetgroup peers,
+// or if its new bucket is full and has no peer that can be evicted.
+// Returns true if the peer is in the peerlist afterwards.
+func (pl *peerlist) addPeerFrom(source, addr string, maxPerNetgroup int) bool {
+	if p, ok := pl.peers[addr]; ok && p != nil {
+		p.Seen()
+		return true
+	}
+
+	if maxPerNetgroup > 0 && pl.netgroupLen(addr) >= maxPerNetgroup {
+		return false
+	}
+
+	peer := NewPeer(addr)
+	peer.Source = netgroup(source)
+
+	if !pl.makeRoom(pl.bucketOf(peer)) {
+		return false
+	}
+
+	pl.peers[addr] = peer
+	pl.index(peer)
+	return true
+}
+
 func (pl *peerlist) seen(addr string) {
 	if p, ok := pl.peers[addr]; ok && p != nil {
 		p.Seen()
@@ -177,7 +273,10 @@ var isExchangeable = []Filter{hasIncomingPort}
 
 // removePeer removes peer
 func (pl *peerlist) removePeer(addr string) {
-	delete(pl.peers, addr)
+	if p, ok := pl.peers[addr]; ok {
+		pl.unindex(p)
+		delete(pl.peers, addr)
+	}
 }
 
 // setTrusted sets peer as trusted peer
@@ -239,11 +338,78 @@ func (pl *peerlist) clearOld(timeAgo time.Duration) {
 	for addr, peer := range pl.peers {
 		lastSeen := time.Unix(peer.LastSeen, 0)
 		if !peer.Trusted && t.Sub(lastSeen) > timeAgo {
-			delete(pl.peers, addr)
+			pl.removePeer(addr)
 		}
 	}
 }
 
+// randomDiverse returns up to count random peers that pass the filters, with at most one peer
+// from each network group, excluding the network groups of the addresses in exclude.
+// Tried and new peers are interleaved so that neither table can monopolize the selection.
+// If count is not 0 and there are not enough network groups, such as on a local or private network,
+// the remaining peers are picked regardless of their network group, excluding the addresses in exclude.
+// If count is 0, one peer of each network group is returned.
+func (pl *peerlist) randomDiverse(count int, exclude []string, flts []Filter) Peers {
+	usedGroups := make(map[string]struct{}, len(exclude))
+	picked := make(map[string]struct{}, len(exclude))
+	for _, a := range exclude {
+		usedGroups[netgroup(a)] = struct{}{}
+		picked[a] = struct{}{}
+	}
+
+	var tried, untried Peers
+	for _, p := range pl.getCanTryPeers(flts) {
+		if p.Tried {
+			tried = append(tried, p)
+		} else {
+			untried = append(untried, p)
+		}
+	}
+
+	rand.Shuffle(len(tried), func(i, j int) {
+		tried[i], tried[j] = tried[j], tried[i]
+	})
+	rand.Shuffle(len(untried), func(i, j int) {
+		untried[i], untried[j] = untried[j], untried[i]
+	})
+
+	ps := Peers{}
+	pick := func(candidates Peers, diverse bool) Peers {
+		for i, p := range candidates {
+			if _, ok := picked[p.Addr]; ok {
+				continue
+			}
+
+			g := netgroup(p.Addr)
+			if _, ok := usedGroups[g]; ok && diverse {
+				continue
+			}
+			usedGroups[g] = struct{}{}
+			picked[p.Addr] = struct{}{}
+			ps = append(ps, p)
+			return candidates[i+1:]
+		}
+		return nil
+	}
+
+	fill := func(tried, untried Peers, diverse bool) {
+		for (count == 0 || len(ps) < count) && (len(tried) > 0 || len(untried) > 0) {
+			tried = pick(tried, diverse)
+			if count != 0 && len(ps) >= count {
+				break
+			}
+			untried = pick(untried, diverse)
+		}
+	}
+
+	fill(tried, untried, true)
+	if count != 0 && len(ps) < count {
+		fill(tried, untried, false)
+	}
+
+	return ps
+}
+
 // Returns n random peers, or all of the peers, whichever is lower.
 // If count is 0, all of the peers are returned, shuffled.
 func (pl *peerlist) random(count int, flts []Filter) Peers {
@@ -276,7 +442,13 @@ func (pl *peerlist) save(fn string) error {
 		}
 	}
 
-	if err := file.SaveJSON(fn, peers, 0600); err != nil {
+	pf := PeersFileJSON{
+		Version: peersFileVersion,
+		Key:     hex.EncodeToString(pl.key),
+		Peers:   peers,
+	}
+
+	if err := file.SaveJSON(fn, pf, 0600); err != nil {
 		return fmt.Errorf("save peer list failed: %s", err)
 	}
 	return nil
@@ -339,6 +511,8 @@ type PeerJSON struct {
 	HasIncomePort   *bool `json:"HasIncomePort,omitempty"` // Whether this peer has incoming port [DEPRECATED]
 	HasIncomingPort *bool // Whether this peer has incoming port
 	UserAgent       useragent.Data
+	Source          string `json:",omitempty"` // Network group of the peer that advertised this peer
+	Tried           bool   `json:",omitempty"` // Whether this peer is in the tried table
 }
 
 // newPeerJSON returns a PeerJSON from a Peer
@@ -349,6 +523,8 @@ func newPeerJSON(p Peer) PeerJSON {
 		Trusted:         p.Trusted,
 		HasIncomingPort: &p.HasIncomingPort,
 		UserAgent:       p.UserAgent,
+		Source:          p.Source,
+		Tried:           p.Tried,
 	}
 }
 
@@ -392,5 +568,7 @@ func newPeerFromJSON(p PeerJSON) (*Peer, error) {
 		Trusted:         p.Trusted,
 		HasIncomingPort: hasIncomingPort,
 		UserAgent:       p.UserAgent,
+		Source:          p.Source,
+		Tried:           p.Tried,
 	}, nil
 }
@@ -103,6 +103,8 @@ type Peer struct {
 	Trusted         bool           // Whether this peer is trusted
 	HasIncomingPort bool           // Whether this peer has accessible public port
 	UserAgent       useragent.Data // Peer's last reported user agent
+	Source          string         // Network group of the peer that advertised this peer, empty if discovered locally
+	Tried           bool           // Whether an outgoing connection to this peer has succeeded
 	RetryTimes      int            `json:"-"` // records the retry times
 }
 
@@ -160,6 +162,9 @@ type Config struct {
 	DataDirectory string
 	// Maximum number of peers to keep account of in the PeerList
 	Max int
+	// Maximum number of peers from a single /16 (IPv4) or /32 (IPv6) network group
+	// that can be added through peer exchange
+	MaxPeersPerNetgroup int
 	// Cull peers after they havent been seen in this much time
 	Expiration time.Duration
 	// Cull expired peers on this interval
@@ -195,6 +200,7 @@ func NewConfig() Config {
 	return Config{
 		DataDirectory:       "./",
 		Max:                 65535,
+		MaxPeersPerNetgroup: 256,
 		Expiration:          time.Hour * 24 * 7,
 		CullRate:            time.Minute * 10,
 		ClearOldRate:        time.Minute * 10,
@@ -345,12 +351,17 @@ func (px *Pex) loadCache() error {
 	defer px.Unlock()
 
 	fp := filepath.Join(px.Config.DataDirectory, PeerCacheFilename)
-	peers, err := loadCachedPeersFile(fp)
+	peers, key, err := loadCachedPeersFileWithKey(fp)
 
 	if err != nil {
 		return err
 	}
 
+	// Reuse the saved bucket key so that peers stay in the same buckets across restarts
+	if key != nil {
+		px.peerlist = newPeerlistWithKey(key)
+	}
+
 	// If the PeerCacheFilename peers.json file does not exist, try to load the old peers.txt file
 	if peers == nil {
 		logger.Infof("Peer cache %s not found, falling back on %s", PeerCacheFilename, oldPeerCacheFilename)
@@ -500,6 +511,49 @@ func (px *Pex) AddPeers(addrs []string) int {
 	return len(addrs)
 }
 
+// AddPeersFrom adds peers that were advertised by the peer at source, through peer exchange.
+// Unlike AddPeers, peers are subject to the per-netgroup limit and bucket limits,
+// so that a single network cannot flood the peerlist.
+// Returns the number of peers that are in the peerlist afterwards.
+func (px *Pex) AddPeersFrom(source string, addrs []string) int {
+	px.Lock()
+	defer px.Unlock()
+
+	n := 0
+	for _, addr := range addrs {
+		if px.isFull() {
+			logger.Warning("Add peers failed, peer list is full")
+			break
+		}
+
+		a, err := validateAddress(addr, px.Config.AllowLocalhost)
+		if err != nil {
+			logger.WithField("addr", addr).WithError(err).Info("Add peers sees an invalid address")
+			continue
+		}
+
+		if px.peerlist.addPeerFrom(source, a, px.Config.MaxPeersPerNetgroup) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// MarkTried moves a peer to the tried table, after a successful outgoing connection
+func (px *Pex) MarkTried(addr string) error {
+	px.Lock()
+	defer px.Unlock()
+
+	cleanAddr, err := validateAddress(addr, px.Config.AllowLocalhost)
+	if err != nil {
+		logger.WithError(err).WithField("addr", addr).Error("Invalid address")
+		return ErrInvalidAddress
+	}
+
+	return px.peerlist.markTried(cleanAddr)
+}
+
 // setTrusted marks a peer as a default peer by setting its trusted flag to true
 func (px *Pex) setTrusted(addr string) error {
 	px.Lock()
@@ -593,6 +647,18 @@ func (px *Pex) Random(n int) Peers {
 	}})
 }
 
+// RandomDiverse returns up to N random untrusted peers for making outgoing connections.
+// Peers are picked from the network groups that have no peer in connected first, one per group,
+// so that outgoing connections are spread across networks. If there are not enough network groups,
+// the remaining peers are picked from any group. The peers in connected are never returned.
+func (px *Pex) RandomDiverse(n int, connected []string) Peers {
+	px.RLock()
+	defer px.RUnlock()
+	return px.peerlist.randomDiverse(n, connected, []Filter{func(p Peer) bool {
+		return !p.Trusted
+	}})
+}
+
 // RandomExchangeable returns N random exchangeable peers
 func (px *Pex) RandomExchangeable(n int) Peers {
 	px.RLock()
@@ -36,6 +36,12 @@ type PoolConfig struct {
 	MaxIncomingMessageLength int
 	// Maximum length of outgoing messages in bytes
 	MaxOutgoingMessageLength int
+	// Triggered for every message that is received or sent, e.g. to capture traffic
+	MessageCallback gnet.MessageCallback
+	// Creates the listener for incoming connections. Defaults to net.Listen
+	Listen gnet.ListenFunc
+	// Makes outgoing connections. Defaults to net.DialTimeout
+	Dial gnet.DialFunc
 	// These should be assigned by the controlling daemon
 	address string
 	port    int
@@ -84,6 +90,9 @@ func NewPool(cfg PoolConfig, d *Daemon) (*Pool, error) {
 	gnetCfg.DefaultConnections = cfg.DefaultConnections
 	gnetCfg.MaxIncomingMessageLength = cfg.MaxIncomingMessageLength
 	gnetCfg.MaxOutgoingMessageLength = cfg.MaxOutgoingMessageLength
+	gnetCfg.MessageCallback = cfg.MessageCallback
+	gnetCfg.Listen = cfg.Listen
+	gnetCfg.Dial = cfg.Dial
 
 	pool, err := gnet.NewConnectionPool(gnetCfg, d)
 	if err != nil {
@@ -0,0 +1,43 @@
+package readable
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/droplet"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+// AddressStats are the stats of an address
+type AddressStats struct {
+	Address       string `json:"address"`
+	FirstBlockSeq uint64 `json:"first_block_seq"`
+	LastBlockSeq  uint64 `json:"last_block_seq"`
+	TxnCount      uint64 `json:"txn_count"`
+	CoinsReceived string `json:"coins_received"`
+	HoursReceived uint64 `json:"hours_received"`
+	CoinsSent     string `json:"coins_sent"`
+	HoursSent     uint64 `json:"hours_sent"`
+}
+
+// NewAddressStats creates AddressStats from historydb.AddressStats
+func NewAddressStats(addr cipher.Address, s historydb.AddressStats) (*AddressStats, error) {
+	coinsReceived, err := droplet.ToString(s.CoinsReceived)
+	if err != nil {
+		return nil, err
+	}
+
+	coinsSent, err := droplet.ToString(s.CoinsSent)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AddressStats{
+		Address:       addr.String(),
+		FirstBlockSeq: s.FirstBlockSeq,
+		LastBlockSeq:  s.LastBlockSeq,
+		TxnCount:      s.TxnCount,
+		CoinsReceived: coinsReceived,
+		HoursReceived: s.HoursReceived,
+		CoinsSent:     coinsSent,
+		HoursSent:     s.HoursSent,
+	}, nil
+}
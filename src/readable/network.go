@@ -21,6 +21,7 @@ type Connection struct {
 	UserAgent            useragent.Data         `json:"user_agent"`
 	IsTrustedPeer        bool                   `json:"is_trusted_peer"`
 	UnconfirmedVerifyTxn VerifyTxn              `json:"unconfirmed_verify_transaction"`
+	Features             []string               `json:"features"`
 }
 
 // NewConnection copies daemon.Connection to a struct with json tags
@@ -53,6 +54,7 @@ func NewConnection(c *daemon.Connection) Connection {
 		UserAgent:            c.UserAgent,
 		IsTrustedPeer:        c.Pex.Trusted,
 		UnconfirmedVerifyTxn: NewVerifyTxn(c.UnconfirmedVerifyTxn),
+		Features:             c.Features.Names(),
 	}
 }
 
@@ -48,10 +48,13 @@ func NewAddressBalances(wab wallet.AddressBalances) AddressBalances {
 
 // WalletEntry the wallet entry struct
 type WalletEntry struct {
-	Address     string  `json:"address"`
-	Public      string  `json:"public_key"`
-	ChildNumber *uint32 `json:"child_number,omitempty"` // For bip32/44
-	Change      *uint32 `json:"change,omitempty"`       // For bip44
+	Address     string   `json:"address"`
+	Public      string   `json:"public_key"`
+	ChildNumber *uint32  `json:"child_number,omitempty"` // For bip32/44
+	Change      *uint32  `json:"change,omitempty"`       // For bip44
+	Label       string   `json:"label,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Note        string   `json:"note,omitempty"`
 }
 
 // WalletMeta the wallet meta struct
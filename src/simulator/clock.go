@@ -0,0 +1,103 @@
+package simulator
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the time source of the simulated network.
+// It schedules the delivery of delayed messages and timestamps created blocks.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// AfterTime returns a channel that receives the current time once the clock reaches t
+	AfterTime(t time.Time) <-chan time.Time
+}
+
+// RealClock is a Clock that follows the system clock
+type RealClock struct{}
+
+// Now returns the current time
+func (RealClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// AfterTime returns a channel that receives the current time once the clock reaches t
+func (RealClock) AfterTime(t time.Time) <-chan time.Time {
+	return time.After(time.Until(t))
+}
+
+// ManualClock is a Clock that only moves when advanced.
+// It makes the delivery of delayed messages reproducible.
+type ManualClock struct {
+	sync.Mutex
+	now    time.Time
+	timers []manualTimer
+}
+
+type manualTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewManualClock creates a ManualClock set to now
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{
+		now: now,
+	}
+}
+
+// Now returns the current time
+func (c *ManualClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.now
+}
+
+// AfterTime returns a channel that receives the current time once the clock reaches t
+func (c *ManualClock) AfterTime(t time.Time) <-chan time.Time {
+	c.Lock()
+	defer c.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if !t.After(c.now) {
+		ch <- c.now
+		return ch
+	}
+
+	c.timers = append(c.timers, manualTimer{
+		at: t,
+		c:  ch,
+	})
+	return ch
+}
+
+// Advance moves the clock forward by d, firing the timers that expire, in order
+func (c *ManualClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.now = c.now.Add(d)
+
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].at.Before(c.timers[j].at)
+	})
+
+	n := 0
+	for _, t := range c.timers {
+		if t.at.After(c.now) {
+			break
+		}
+		t.c <- c.now
+		n++
+	}
+	c.timers = c.timers[n:]
+}
+
+// Pending returns the number of timers that have not fired
+func (c *ManualClock) Pending() int {
+	c.Lock()
+	defer c.Unlock()
+	return len(c.timers)
+}
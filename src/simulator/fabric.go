@@ -0,0 +1,657 @@
+package simulator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrConnectionRefused is returned when dialing an address that no host is listening on
+	ErrConnectionRefused = errors.New("connection refused")
+	// ErrNetworkUnreachable is returned when dialing a host on the other side of a partition
+	ErrNetworkUnreachable = errors.New("network is unreachable")
+	// ErrAddressInUse is returned when listening on an address that is already in use
+	ErrAddressInUse = errors.New("address already in use")
+	// ErrClosed is returned when using a closed connection or listener
+	ErrClosed = errors.New("use of closed network connection")
+	// ErrFabricClosed is returned when using a closed Fabric
+	ErrFabricClosed = errors.New("fabric is closed")
+)
+
+const (
+	// firstEphemeralPort is the first local port assigned to outgoing connections
+	firstEphemeralPort = 40000
+	// listenerBacklog is the number of connections that can wait to be accepted
+	listenerBacklog = 64
+)
+
+// Link describes the conditions of the network between two hosts
+type Link struct {
+	// Latency is the delay before written data is delivered
+	Latency time.Duration
+	// Jitter is the maximum random delay added to Latency.
+	// Data is always delivered in order, as with TCP.
+	Jitter time.Duration
+	// Loss is the probability, between 0 and 1, that a write is dropped.
+	// gnet writes one message per write, so whole messages are dropped.
+	Loss float64
+}
+
+// FabricStats are counters of the writes handled by a Fabric
+type FabricStats struct {
+	Delivered uint64
+	Dropped   uint64
+}
+
+type hostPair struct {
+	a, b string
+}
+
+func newHostPair(a, b string) hostPair {
+	if b < a {
+		a, b = b, a
+	}
+	return hostPair{a, b}
+}
+
+/*
+Fabric is an in-memory network of hosts, each identified by an IP address.
+Hosts listen and dial like TCP, and the connections between them are subject
+to the Link rules and partitions configured on the Fabric.
+
+Given the same seed, the connections between two hosts see the same sequence of
+jitter and loss, regardless of the other traffic on the Fabric.
+*/
+type Fabric struct {
+	sync.Mutex
+	clock       Clock
+	seed        int64
+	defaultLink Link
+	links       map[hostPair]Link
+	// partition maps a host to its partition group. Hosts without a group can reach all hosts.
+	partition map[string]int
+	listeners map[string]*listener
+	nextPort  map[string]int
+	linkConns map[hostPair]int
+	delivered uint64
+	dropped   uint64
+	quit      chan struct{}
+	closed    bool
+}
+
+// NewFabric creates a Fabric
+func NewFabric(clock Clock, seed int64) *Fabric {
+	if clock == nil {
+		clock = RealClock{}
+	}
+
+	return &Fabric{
+		clock:     clock,
+		seed:      seed,
+		links:     make(map[hostPair]Link),
+		listeners: make(map[string]*listener),
+		nextPort:  make(map[string]int),
+		linkConns: make(map[hostPair]int),
+		quit:      make(chan struct{}),
+	}
+}
+
+// Host returns a handle for the host with the given IP address
+func (f *Fabric) Host(ip string) *Host {
+	return &Host{
+		fabric: f,
+		ip:     ip,
+	}
+}
+
+// SetDefaultLink sets the conditions of links that have no specific rule
+func (f *Fabric) SetDefaultLink(l Link) {
+	f.Lock()
+	defer f.Unlock()
+	f.defaultLink = l
+}
+
+// SetLink sets the conditions of the link between hosts a and b, in both directions
+func (f *Fabric) SetLink(a, b string, l Link) {
+	f.Lock()
+	defer f.Unlock()
+	f.links[newHostPair(a, b)] = l
+}
+
+// Partition splits the hosts into groups that cannot reach each other.
+// Hosts that are not in any group can reach all hosts.
+// Writes across the partition are dropped and dials across the partition fail.
+// Existing connections are not closed, but they will stall until the partition is healed.
+func (f *Fabric) Partition(groups ...[]string) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.partition = make(map[string]int)
+	for i, g := range groups {
+		for _, h := range g {
+			f.partition[h] = i
+		}
+	}
+}
+
+// Heal removes the partition
+func (f *Fabric) Heal() {
+	f.Lock()
+	defer f.Unlock()
+	f.partition = nil
+}
+
+// Stats returns the counters of the writes handled by the Fabric
+func (f *Fabric) Stats() FabricStats {
+	return FabricStats{
+		Delivered: atomic.LoadUint64(&f.delivered),
+		Dropped:   atomic.LoadUint64(&f.dropped),
+	}
+}
+
+// Close stops the delivery of all pending data. Connections and listeners can not be created after Close.
+func (f *Fabric) Close() {
+	f.Lock()
+	defer f.Unlock()
+	if !f.closed {
+		f.closed = true
+		close(f.quit)
+	}
+}
+
+// reachable returns true if host a can reach host b. Must be called with the lock held.
+func (f *Fabric) reachable(a, b string) bool {
+	ga, okA := f.partition[a]
+	gb, okB := f.partition[b]
+	return !okA || !okB || ga == gb
+}
+
+// link returns the conditions of the link between hosts a and b. Must be called with the lock held.
+func (f *Fabric) link(a, b string) Link {
+	if l, ok := f.links[newHostPair(a, b)]; ok {
+		return l
+	}
+	return f.defaultLink
+}
+
+// linkRand creates the random source for the nth connection between two hosts, in one direction
+func (f *Fabric) linkRand(from, to string, n int) *rand.Rand {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d/%s/%s/%d", f.seed, from, to, n)
+	return rand.New(rand.NewSource(int64(binary.BigEndian.Uint64(h.Sum(nil)[:8])))) // nolint: gosec
+}
+
+// Host is a host on a Fabric. Its Listen and Dial methods match gnet.ListenFunc and gnet.DialFunc.
+type Host struct {
+	fabric *Fabric
+	ip     string
+}
+
+// IP returns the host's IP address
+func (h *Host) IP() string {
+	return h.ip
+}
+
+// Listen listens for connections on address, which must be on the host's IP address or have an empty host
+func (h *Host) Listen(network, address string) (net.Listener, error) {
+	addr, err := h.resolve(address)
+	if err != nil {
+		return nil, err
+	}
+	if addr.IP.String() != h.ip {
+		return nil, fmt.Errorf("listen %s: address is not on host %s", address, h.ip)
+	}
+
+	f := h.fabric
+	f.Lock()
+	defer f.Unlock()
+
+	if f.closed {
+		return nil, ErrFabricClosed
+	}
+
+	key := addr.String()
+	if _, ok := f.listeners[key]; ok {
+		return nil, ErrAddressInUse
+	}
+
+	ln := &listener{
+		fabric: f,
+		addr:   addr,
+		accept: make(chan net.Conn, listenerBacklog),
+		closed: make(chan struct{}),
+	}
+	f.listeners[key] = ln
+
+	return ln, nil
+}
+
+// Dial connects to a listener on the fabric. timeout is ignored, dials complete or fail immediately.
+func (h *Host) Dial(network, address string, timeout time.Duration) (net.Conn, error) {
+	raddr, err := h.resolve(address)
+	if err != nil {
+		return nil, err
+	}
+
+	f := h.fabric
+	f.Lock()
+	defer f.Unlock()
+
+	if f.closed {
+		return nil, ErrFabricClosed
+	}
+
+	remoteIP := raddr.IP.String()
+	if !f.reachable(h.ip, remoteIP) {
+		return nil, &net.OpError{Op: "dial", Net: network, Addr: raddr, Err: ErrNetworkUnreachable}
+	}
+
+	ln, ok := f.listeners[raddr.String()]
+	if !ok {
+		return nil, &net.OpError{Op: "dial", Net: network, Addr: raddr, Err: ErrConnectionRefused}
+	}
+
+	port := f.nextPort[h.ip]
+	if port == 0 {
+		port = firstEphemeralPort
+	}
+	f.nextPort[h.ip] = port + 1
+	laddr := &net.TCPAddr{
+		IP:   net.ParseIP(h.ip),
+		Port: port,
+	}
+
+	pair := newHostPair(h.ip, remoteIP)
+	n := f.linkConns[pair]
+	f.linkConns[pair] = n + 1
+
+	client := newConn(laddr, raddr)
+	server := newConn(raddr, laddr)
+	client.out = newStream(f, h.ip, remoteIP, f.linkRand(h.ip, remoteIP, n), server.in)
+	server.out = newStream(f, remoteIP, h.ip, f.linkRand(remoteIP, h.ip, n), client.in)
+
+	select {
+	case ln.accept <- server:
+	default:
+		return nil, &net.OpError{Op: "dial", Net: network, Addr: raddr, Err: ErrConnectionRefused}
+	}
+
+	go client.out.run()
+	go server.out.run()
+
+	return client, nil
+}
+
+// resolve parses an ip:port address. An empty host resolves to the host's IP address.
+func (h *Host) resolve(address string) (*net.TCPAddr, error) {
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if host == "" {
+		host = h.ip
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid IP address %q", host)
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port %q", portStr)
+	}
+
+	return &net.TCPAddr{
+		IP:   ip,
+		Port: int(port),
+	}, nil
+}
+
+// listener implements net.Listener on a Fabric
+type listener struct {
+	fabric    *Fabric
+	addr      *net.TCPAddr
+	accept    chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// Accept waits for and returns the next connection to the listener
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case c := <-l.accept:
+		return c, nil
+	case <-l.closed:
+		return nil, ErrClosed
+	}
+}
+
+// Close closes the listener
+func (l *listener) Close() error {
+	l.closeOnce.Do(func() {
+		l.fabric.Lock()
+		delete(l.fabric.listeners, l.addr.String())
+		l.fabric.Unlock()
+		close(l.closed)
+	})
+	return nil
+}
+
+// Addr returns the listener's network address
+func (l *listener) Addr() net.Addr {
+	return l.addr
+}
+
+// timeoutError is returned by reads that exceed their deadline
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// pipe buffers the data delivered to one end of a connection
+type pipe struct {
+	sync.Mutex
+	buf bytes.Buffer
+	eof bool
+	// wake is closed and replaced when data is written or the pipe is closed
+	wake chan struct{}
+}
+
+func newPipe() *pipe {
+	return &pipe{
+		wake: make(chan struct{}),
+	}
+}
+
+func (p *pipe) write(b []byte) {
+	p.Lock()
+	defer p.Unlock()
+	if p.eof {
+		return
+	}
+	p.buf.Write(b)
+	close(p.wake)
+	p.wake = make(chan struct{})
+}
+
+func (p *pipe) closeWrite() {
+	p.Lock()
+	defer p.Unlock()
+	if p.eof {
+		return
+	}
+	p.eof = true
+	close(p.wake)
+	p.wake = make(chan struct{})
+}
+
+func (p *pipe) read(b []byte, deadline time.Time, closed <-chan struct{}) (int, error) {
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		t := time.NewTimer(time.Until(deadline))
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	for {
+		p.Lock()
+		if p.buf.Len() > 0 {
+			n, err := p.buf.Read(b)
+			p.Unlock()
+			return n, err
+		}
+		if p.eof {
+			p.Unlock()
+			return 0, io.EOF
+		}
+		wake := p.wake
+		p.Unlock()
+
+		select {
+		case <-wake:
+		case <-closed:
+			return 0, ErrClosed
+		case <-timeout:
+			return 0, timeoutError{}
+		}
+	}
+}
+
+// packet is data in flight on a stream
+type packet struct {
+	at   time.Time
+	data []byte
+	fin  bool
+}
+
+// stream delivers the data written to one end of a connection to the other end,
+// applying the fabric's link conditions
+type stream struct {
+	sync.Mutex
+	fabric   *Fabric
+	from, to string
+	rng      *rand.Rand
+	dst      *pipe
+	queue    []packet
+	lastAt   time.Time
+	finished bool
+	notify   chan struct{}
+}
+
+func newStream(f *Fabric, from, to string, rng *rand.Rand, dst *pipe) *stream {
+	return &stream{
+		fabric: f,
+		from:   from,
+		to:     to,
+		rng:    rng,
+		dst:    dst,
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// send queues data for delivery, or drops it
+func (s *stream) send(b []byte) {
+	f := s.fabric
+	f.Lock()
+	reachable := f.reachable(s.from, s.to)
+	l := f.link(s.from, s.to)
+	now := f.clock.Now()
+	f.Unlock()
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.finished {
+		return
+	}
+
+	// Always consume the same number of random values, so that changes to the
+	// partition do not change the sequence of jitter and loss that follows
+	loss := s.rng.Float64()
+	var jitter time.Duration
+	if l.Jitter > 0 {
+		jitter = time.Duration(s.rng.Int63n(int64(l.Jitter)))
+	}
+
+	if !reachable || loss < l.Loss {
+		atomic.AddUint64(&f.dropped, 1)
+		return
+	}
+
+	s.push(packet{
+		at:   now.Add(l.Latency + jitter),
+		data: append([]byte(nil), b...),
+	})
+}
+
+// finish queues the end of the stream. It is delivered after the queued data,
+// regardless of the link conditions.
+func (s *stream) finish() {
+	f := s.fabric
+	f.Lock()
+	l := f.link(s.from, s.to)
+	now := f.clock.Now()
+	f.Unlock()
+
+	s.Lock()
+	defer s.Unlock()
+
+	if s.finished {
+		return
+	}
+	s.finished = true
+
+	s.push(packet{
+		at:  now.Add(l.Latency),
+		fin: true,
+	})
+}
+
+// push queues a packet, keeping delivery in order. Must be called with the lock held.
+func (s *stream) push(p packet) {
+	if p.at.Before(s.lastAt) {
+		p.at = s.lastAt
+	}
+	s.lastAt = p.at
+	s.queue = append(s.queue, p)
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued packets until the end of the stream is delivered or the fabric is closed
+func (s *stream) run() {
+	f := s.fabric
+	for {
+		s.Lock()
+		if len(s.queue) == 0 {
+			s.Unlock()
+			select {
+			case <-s.notify:
+				continue
+			case <-f.quit:
+				return
+			}
+		}
+		p := s.queue[0]
+		s.Unlock()
+
+		select {
+		case <-f.clock.AfterTime(p.at):
+		case <-f.quit:
+			return
+		}
+
+		s.Lock()
+		s.queue = s.queue[1:]
+		s.Unlock()
+
+		if p.fin {
+			s.dst.closeWrite()
+			return
+		}
+
+		s.dst.write(p.data)
+		atomic.AddUint64(&f.delivered, 1)
+	}
+}
+
+// conn implements net.Conn on a Fabric
+type conn struct {
+	local, remote *net.TCPAddr
+	in            *pipe
+	out           *stream
+
+	deadlineLock sync.Mutex
+	readDeadline time.Time
+
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newConn(local, remote *net.TCPAddr) *conn {
+	return &conn{
+		local:  local,
+		remote: remote,
+		in:     newPipe(),
+		closed: make(chan struct{}),
+	}
+}
+
+// Read reads data delivered to the connection. The read deadline is applied when Read is called.
+func (c *conn) Read(b []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, ErrClosed
+	default:
+	}
+
+	c.deadlineLock.Lock()
+	deadline := c.readDeadline
+	c.deadlineLock.Unlock()
+
+	return c.in.read(b, deadline, c.closed)
+}
+
+// Write sends data to the other end of the connection. It never blocks.
+func (c *conn) Write(b []byte) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, ErrClosed
+	default:
+	}
+
+	c.out.send(b)
+	return len(b), nil
+}
+
+// Close closes the connection. The other end reads EOF after the data that was already sent.
+func (c *conn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.out.finish()
+	})
+	return nil
+}
+
+// LocalAddr returns the local network address
+func (c *conn) LocalAddr() net.Addr {
+	return c.local
+}
+
+// RemoteAddr returns the remote network address
+func (c *conn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+// SetDeadline sets the read deadline. Writes never block, so the write deadline is ignored.
+func (c *conn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline
+func (c *conn) SetReadDeadline(t time.Time) error {
+	c.deadlineLock.Lock()
+	defer c.deadlineLock.Unlock()
+	c.readDeadline = t
+	return nil
+}
+
+// SetWriteDeadline is a no-op, writes never block
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	return nil
+}
@@ -0,0 +1,229 @@
+package simulator
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// connPair creates a connection from host a to a listener on host b, returning both ends
+func connPair(t *testing.T, f *Fabric, a, b string) (net.Conn, net.Conn) {
+	l, err := f.Host(b).Listen("tcp", b+":6000")
+	require.NoError(t, err)
+	defer l.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, err := l.Accept()
+		require.NoError(t, err)
+		accepted <- c
+	}()
+
+	dialed, err := f.Host(a).Dial("tcp", b+":6000", time.Second)
+	require.NoError(t, err)
+
+	return dialed, <-accepted
+}
+
+func requireOpError(t *testing.T, expected, err error) {
+	opErr, ok := err.(*net.OpError)
+	require.True(t, ok, "%T is not a *net.OpError", err)
+	require.Equal(t, expected, opErr.Err)
+}
+
+func readN(t *testing.T, c net.Conn, n int) []byte {
+	b := make([]byte, n)
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(time.Second)))
+	_, err := io.ReadFull(c, b)
+	require.NoError(t, err)
+	return b
+}
+
+func TestFabricDelivery(t *testing.T) {
+	f := NewFabric(nil, 1)
+	defer f.Close()
+
+	a, b := connPair(t, f, "10.0.0.1", "10.0.0.2")
+	defer a.Close()
+	defer b.Close()
+
+	require.Equal(t, "10.0.0.1:40000", a.LocalAddr().String())
+	require.Equal(t, "10.0.0.2:6000", a.RemoteAddr().String())
+	require.Equal(t, "10.0.0.1:40000", b.RemoteAddr().String())
+
+	_, err := a.Write([]byte("hello"))
+	require.NoError(t, err)
+	_, err = b.Write([]byte("world"))
+	require.NoError(t, err)
+
+	require.Equal(t, []byte("hello"), readN(t, b, 5))
+	require.Equal(t, []byte("world"), readN(t, a, 5))
+}
+
+func TestFabricListenDial(t *testing.T) {
+	f := NewFabric(nil, 1)
+	defer f.Close()
+
+	h := f.Host("10.0.0.1")
+
+	_, err := h.Dial("tcp", "10.0.0.2:6000", time.Second)
+	requireOpError(t, ErrConnectionRefused, err)
+
+	l, err := f.Host("10.0.0.2").Listen("tcp", "10.0.0.2:6000")
+	require.NoError(t, err)
+
+	_, err = f.Host("10.0.0.2").Listen("tcp", "10.0.0.2:6000")
+	require.Equal(t, ErrAddressInUse, err)
+
+	require.NoError(t, l.Close())
+	_, err = l.Accept()
+	require.Equal(t, ErrClosed, err)
+
+	_, err = h.Dial("tcp", "10.0.0.2:6000", time.Second)
+	requireOpError(t, ErrConnectionRefused, err)
+
+	f.Close()
+	_, err = h.Dial("tcp", "10.0.0.2:6000", time.Second)
+	require.Equal(t, ErrFabricClosed, err)
+}
+
+func TestFabricLatency(t *testing.T) {
+	start := time.Unix(1000000, 0)
+	clock := NewManualClock(start)
+	f := NewFabric(clock, 1)
+	defer f.Close()
+	f.SetLink("10.0.0.1", "10.0.0.2", Link{
+		Latency: time.Second,
+	})
+
+	a, b := connPair(t, f, "10.0.0.1", "10.0.0.2")
+	defer a.Close()
+	defer b.Close()
+
+	_, err := a.Write([]byte("x"))
+	require.NoError(t, err)
+
+	// Nothing is delivered before the latency has passed
+	waitPending(t, clock, 1)
+	require.NoError(t, b.SetReadDeadline(time.Now().Add(time.Millisecond*50)))
+	_, err = b.Read(make([]byte, 1))
+	require.Error(t, err)
+	netErr, ok := err.(net.Error)
+	require.True(t, ok)
+	require.True(t, netErr.Timeout())
+
+	clock.Advance(time.Millisecond * 999)
+	require.Equal(t, 1, clock.Pending())
+
+	clock.Advance(time.Millisecond)
+	require.Equal(t, []byte("x"), readN(t, b, 1))
+}
+
+// waitPending waits for the fabric to schedule n timers on the clock
+func waitPending(t *testing.T, c *ManualClock, n int) {
+	deadline := time.Now().Add(time.Second)
+	for c.Pending() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("clock has %d pending timers, expected %d", c.Pending(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestFabricLossIsReproducible(t *testing.T) {
+	received := func(seed int64) []byte {
+		f := NewFabric(nil, seed)
+		defer f.Close()
+		f.SetDefaultLink(Link{
+			Loss: 0.5,
+		})
+
+		a, b := connPair(t, f, "10.0.0.1", "10.0.0.2")
+		defer b.Close()
+
+		for i := 0; i < 100; i++ {
+			_, err := a.Write([]byte{byte(i)})
+			require.NoError(t, err)
+		}
+		require.NoError(t, a.Close())
+
+		require.NoError(t, b.SetReadDeadline(time.Now().Add(time.Second)))
+		data, err := readAll(b)
+		require.NoError(t, err)
+
+		stats := f.Stats()
+		require.NotZero(t, stats.Dropped)
+		require.NotZero(t, stats.Delivered)
+		require.Equal(t, uint64(100), stats.Dropped+stats.Delivered)
+		require.Len(t, data, int(stats.Delivered))
+		return data
+	}
+
+	first := received(7)
+	require.Equal(t, first, received(7))
+	require.NotEqual(t, first, received(8))
+}
+
+func readAll(c net.Conn) ([]byte, error) {
+	var data []byte
+	b := make([]byte, 64)
+	for {
+		n, err := c.Read(b)
+		data = append(data, b[:n]...)
+		if err == io.EOF {
+			return data, nil
+		} else if err != nil {
+			return data, err
+		}
+	}
+}
+
+func TestFabricPartition(t *testing.T) {
+	f := NewFabric(nil, 1)
+	defer f.Close()
+
+	a, b := connPair(t, f, "10.0.0.1", "10.0.0.2")
+	defer a.Close()
+	defer b.Close()
+
+	f.Partition([]string{"10.0.0.1"}, []string{"10.0.0.2", "10.0.0.3"})
+
+	// Dials across the partition fail
+	_, err := f.Host("10.0.0.1").Dial("tcp", "10.0.0.2:6000", time.Second)
+	requireOpError(t, ErrNetworkUnreachable, err)
+
+	// Writes across the partition are dropped
+	_, err = a.Write([]byte("lost"))
+	require.NoError(t, err)
+	require.Equal(t, FabricStats{Dropped: 1}, f.Stats())
+
+	f.Heal()
+
+	_, err = a.Write([]byte("sent"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("sent"), readN(t, b, 4))
+}
+
+func TestFabricClose(t *testing.T) {
+	f := NewFabric(nil, 1)
+	defer f.Close()
+
+	a, b := connPair(t, f, "10.0.0.1", "10.0.0.2")
+	defer b.Close()
+
+	_, err := a.Write([]byte("bye"))
+	require.NoError(t, err)
+	require.NoError(t, a.Close())
+
+	_, err = a.Write([]byte("x"))
+	require.Equal(t, ErrClosed, err)
+
+	// Data written before closing is delivered, followed by EOF
+	require.NoError(t, b.SetReadDeadline(time.Now().Add(time.Second)))
+	data, err := readAll(b)
+	require.NoError(t, err)
+	require.Equal(t, []byte("bye"), data)
+}
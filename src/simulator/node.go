@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/visor"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+// Node is a daemon and visor running on a simulated host
+type Node struct {
+	// Index is the node's position in Simulator.Nodes
+	Index int
+	// Addr is the node's listening ip:port
+	Addr string
+	// Host is the node's host on the Fabric
+	Host   *Host
+	Visor  *visor.Visor
+	Daemon *daemon.Daemon
+
+	db      *dbutil.DB
+	dir     string
+	done    chan struct{}
+	running bool
+}
+
+func newNode(index int, host *Host, port int, dir string, vc visor.Config, dc daemon.Config) (*Node, error) {
+	db, err := visor.OpenDB(filepath.Join(dir, "data.db"), false)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := visor.New(vc, db, nil)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	d, err := daemon.New(dc, v)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Node{
+		Index:  index,
+		Addr:   fmt.Sprintf("%s:%d", host.IP(), port),
+		Host:   host,
+		Visor:  v,
+		Daemon: d,
+		db:     db,
+		dir:    dir,
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// start initializes the visor and runs the daemon in a goroutine
+func (n *Node) start() error {
+	if err := n.Visor.Init(); err != nil {
+		return err
+	}
+
+	n.running = true
+	go func() {
+		defer close(n.done)
+		if err := n.Daemon.Run(); err != nil {
+			logger.WithError(err).WithField("addr", n.Addr).Error("daemon.Run failed")
+		}
+	}()
+
+	return nil
+}
+
+// stop shuts down the daemon and closes the database
+func (n *Node) stop() error {
+	if n.running {
+		n.Daemon.Shutdown()
+		<-n.done
+		n.running = false
+	}
+
+	return n.db.Close()
+}
+
+// HeadSeq returns the sequence of the node's head block
+func (n *Node) HeadSeq() (uint64, error) {
+	seq, _, err := n.Visor.HeadBkSeq()
+	return seq, err
+}
+
+// HeadHash returns the hash of the node's head block
+func (n *Node) HeadHash() (cipher.SHA256, error) {
+	seq, ok, err := n.Visor.HeadBkSeq()
+	if err != nil {
+		return cipher.SHA256{}, err
+	}
+	if !ok {
+		return cipher.SHA256{}, nil
+	}
+
+	b, err := n.Visor.GetSignedBlockBySeq(seq)
+	if err != nil {
+		return cipher.SHA256{}, err
+	}
+	if b == nil {
+		return cipher.SHA256{}, fmt.Errorf("node %d has no block %d", n.Index, seq)
+	}
+
+	return b.HashHeader(), nil
+}
@@ -0,0 +1,76 @@
+package simulator
+
+import (
+	"fmt"
+	"time"
+)
+
+// Step is one step of a scenario
+type Step func(s *Simulator) error
+
+// Run runs the steps of a scenario in order, stopping at the first step that fails
+func (s *Simulator) Run(steps ...Step) error {
+	for i, step := range steps {
+		if err := step(s); err != nil {
+			return fmt.Errorf("step %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// CreateBlocks is a Step that creates n blocks on the publisher
+func CreateBlocks(n int) Step {
+	return func(s *Simulator) error {
+		return s.CreateBlocks(n)
+	}
+}
+
+// Partition is a Step that splits the nodes into groups that cannot reach each other
+func Partition(groups ...[]int) Step {
+	return func(s *Simulator) error {
+		s.Partition(groups...)
+		return nil
+	}
+}
+
+// Heal is a Step that removes the partition
+func Heal() Step {
+	return func(s *Simulator) error {
+		s.Heal()
+		return nil
+	}
+}
+
+// Sleep is a Step that waits for d of real time
+func Sleep(d time.Duration) Step {
+	return func(s *Simulator) error {
+		time.Sleep(d)
+		return nil
+	}
+}
+
+// AdvanceClock is a Step that advances the simulator's ManualClock by d
+func AdvanceClock(d time.Duration) Step {
+	return func(s *Simulator) error {
+		c, ok := s.clock.(*ManualClock)
+		if !ok {
+			return fmt.Errorf("the simulator clock is a %T, not a *ManualClock", s.clock)
+		}
+		c.Advance(d)
+		return nil
+	}
+}
+
+// Converge is a Step that waits until all nodes have the same head block
+func Converge(timeout time.Duration) Step {
+	return func(s *Simulator) error {
+		return s.WaitForConvergence(timeout)
+	}
+}
+
+// Connect is a Step that waits until every node has at least n introduced connections
+func Connect(n int, timeout time.Duration) Step {
+	return func(s *Simulator) error {
+		return s.WaitForConnections(n, timeout)
+	}
+}
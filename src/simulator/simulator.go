@@ -0,0 +1,436 @@
+/*
+Package simulator runs a network of skycoin nodes in one process, for reproducible
+tests of block propagation and synchronization.
+
+Each node is a daemon.Daemon and visor.Visor with its own database. The nodes are
+connected through an in-memory Fabric, which applies latency, packet loss and
+partition rules to their connections. Node 0 is the block publisher.
+
+The Fabric delivers delayed data and the publisher timestamps blocks according to
+the simulator's Clock. The daemons' own timers run on the system clock, so their
+rates are shortened by the simulator's default daemon configuration.
+*/
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/useragent"
+	"github.com/skycoin/skycoin/src/visor"
+)
+
+const (
+	// genesisCoins is the number of droplets created in the genesis block
+	genesisCoins uint64 = 100e12
+	// nodePort is the port that every node listens on
+	nodePort = 6000
+	// pollRate is how often Wait* methods check the nodes
+	pollRate = time.Millisecond * 50
+)
+
+var (
+	logger = logging.MustGetLogger("simulator")
+
+	// ErrTimeout is returned when a Wait* method times out
+	ErrTimeout = errors.New("timed out")
+)
+
+// Config configures a Simulator
+type Config struct {
+	// Nodes is the number of nodes. Node 0 is the block publisher.
+	Nodes int
+	// Seed seeds the Fabric's loss and jitter and the nodes' keys and mirror values
+	Seed int64
+	// Clock is the simulated clock. Defaults to RealClock.
+	Clock Clock
+	// Link is the default link between nodes
+	Link Link
+	// ConfigureDaemon can modify a node's daemon configuration before the node is created
+	ConfigureDaemon func(n int, c *daemon.Config)
+	// ConfigureVisor can modify a node's visor configuration before the node is created
+	ConfigureVisor func(n int, c *visor.Config)
+}
+
+// NewConfig returns a Config with defaults set
+func NewConfig() Config {
+	return Config{
+		Nodes: 3,
+		Seed:  1,
+	}
+}
+
+// Simulator is a network of nodes connected through a Fabric
+type Simulator struct {
+	Config Config
+	Fabric *Fabric
+	Nodes  []*Node
+
+	dir              string
+	clock            Clock
+	blockchainSecKey cipher.SecKey
+	genesisSecKey    cipher.SecKey
+	// outputs are the publisher's spendable outputs, oldest first
+	outputs coin.UxArray
+}
+
+// New creates a Simulator and its nodes. The nodes are not started.
+func New(c Config) (*Simulator, error) {
+	if c.Nodes < 1 {
+		return nil, errors.New("a simulation needs at least one node")
+	}
+	if c.Nodes > 250 {
+		return nil, errors.New("a simulation can have at most 250 nodes")
+	}
+	if c.Clock == nil {
+		c.Clock = RealClock{}
+	}
+
+	dir, err := ioutil.TempDir("", "simulator")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Simulator{
+		Config: c,
+		Fabric: NewFabric(c.Clock, c.Seed),
+		dir:    dir,
+		clock:  c.Clock,
+	}
+	s.Fabric.SetDefaultLink(c.Link)
+
+	if err := s.createNodes(); err != nil {
+		s.Stop() // nolint: errcheck
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Simulator) createNodes() error {
+	rnd := rand.New(rand.NewSource(s.Config.Seed)) // nolint: gosec
+
+	blockchainPubKey, blockchainSecKey := cipher.MustGenerateDeterministicKeyPair([]byte(fmt.Sprintf("blockchain %d", s.Config.Seed)))
+	genesisPubKey, genesisSecKey := cipher.MustGenerateDeterministicKeyPair([]byte(fmt.Sprintf("genesis %d", s.Config.Seed)))
+	genesisAddr := cipher.AddressFromPubKey(genesisPubKey)
+	s.blockchainSecKey = blockchainSecKey
+	s.genesisSecKey = genesisSecKey
+
+	genesisTimestamp := uint64(s.clock.Now().Unix())
+	gb, err := coin.NewGenesisBlock(genesisAddr, genesisCoins, genesisTimestamp)
+	if err != nil {
+		return err
+	}
+	genesisSig := cipher.MustSignHash(gb.HashHeader(), blockchainSecKey)
+	s.outputs = coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+
+	addrs := make([]string, s.Config.Nodes)
+	for i := range addrs {
+		addrs[i] = fmt.Sprintf("%s:%d", nodeIP(i), nodePort)
+	}
+
+	for i := 0; i < s.Config.Nodes; i++ {
+		vc := visor.NewConfig()
+		vc.IsBlockPublisher = i == 0
+		vc.Arbitrating = i == 0
+		vc.BlockchainPubkey = blockchainPubKey
+		if i == 0 {
+			vc.BlockchainSeckey = blockchainSecKey
+		}
+		vc.GenesisAddress = genesisAddr
+		vc.GenesisSignature = genesisSig
+		vc.GenesisTimestamp = genesisTimestamp
+		vc.GenesisCoinVolume = genesisCoins
+		vc.Distribution = params.Distribution{
+			MaxCoinSupply:        genesisCoins / 1e6,
+			InitialUnlockedCount: 1,
+			UnlockAddressRate:    1,
+			UnlockTimeInterval:   60 * 60 * 24 * 365,
+			Addresses:            []string{genesisAddr.String()},
+		}
+
+		var peers []string
+		for j, a := range addrs {
+			if j != i {
+				peers = append(peers, a)
+			}
+		}
+
+		host := s.Fabric.Host(nodeIP(i))
+		dc := defaultDaemonConfig(host, peers)
+		dc.Daemon.BlockchainPubkey = blockchainPubKey
+		dc.Daemon.GenesisHash = gb.HashHeader()
+		dc.Daemon.Mirror = rnd.Uint32()
+
+		dir := filepath.Join(s.dir, fmt.Sprint(i))
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return err
+		}
+		dc.Daemon.DataDirectory = dir
+		dc.Pex.DataDirectory = dir
+
+		if s.Config.ConfigureVisor != nil {
+			s.Config.ConfigureVisor(i, &vc)
+		}
+		if s.Config.ConfigureDaemon != nil {
+			s.Config.ConfigureDaemon(i, &dc)
+		}
+
+		n, err := newNode(i, host, nodePort, dir, vc, dc)
+		if err != nil {
+			return fmt.Errorf("create node %d: %v", i, err)
+		}
+		s.Nodes = append(s.Nodes, n)
+	}
+
+	return nil
+}
+
+// nodeIP returns the IP address of the nth node
+func nodeIP(n int) string {
+	return fmt.Sprintf("10.0.0.%d", n+1)
+}
+
+// defaultDaemonConfig returns a daemon configuration for a node on host that connects to peers.
+// Timers are shortened so that the network converges quickly.
+func defaultDaemonConfig(host *Host, peers []string) daemon.Config {
+	maxOutgoing := len(peers)
+	if maxOutgoing == 0 {
+		maxOutgoing = 1
+	} else if maxOutgoing > 8 {
+		maxOutgoing = 8
+	}
+
+	dc := daemon.NewConfig()
+
+	dc.Daemon.Address = host.IP()
+	dc.Daemon.Port = nodePort
+	dc.Daemon.DefaultConnections = peers
+	dc.Daemon.MaxOutgoingConnections = maxOutgoing
+	dc.Daemon.MaxPendingConnections = maxOutgoing
+	dc.Daemon.OutgoingRate = time.Millisecond * 100
+	dc.Daemon.OutgoingTrustedRate = time.Millisecond * 100
+	dc.Daemon.BlocksRequestRate = time.Millisecond * 250
+	dc.Daemon.BlocksAnnounceRate = time.Millisecond * 250
+	dc.Daemon.BlockCreationInterval = 60 * 60
+	dc.Daemon.LogPings = false
+	dc.Daemon.UserAgent = useragent.Data{
+		Coin:    "skycoin",
+		Version: "0.0.0",
+		Remark:  "simulator",
+	}
+
+	dc.Pool.DefaultConnections = peers
+	dc.Pool.MaxOutgoingConnections = maxOutgoing
+	dc.Pool.MaxDefaultPeerOutgoingConnections = maxOutgoing
+	dc.Pool.PingRate = time.Second
+	dc.Pool.IdleLimit = time.Second * 5
+	dc.Pool.Listen = host.Listen
+	dc.Pool.Dial = host.Dial
+
+	dc.Pex.DefaultConnections = peers
+	dc.Pex.RequestRate = time.Second
+
+	return dc
+}
+
+// Start starts all of the nodes
+func (s *Simulator) Start() error {
+	for _, n := range s.Nodes {
+		if err := n.start(); err != nil {
+			return fmt.Errorf("start node %d: %v", n.Index, err)
+		}
+	}
+	return nil
+}
+
+// Stop stops all of the nodes and removes their data
+func (s *Simulator) Stop() error {
+	var err error
+	for _, n := range s.Nodes {
+		if stopErr := n.stop(); stopErr != nil && err == nil {
+			err = stopErr
+		}
+	}
+
+	s.Fabric.Close()
+
+	if rmErr := os.RemoveAll(s.dir); rmErr != nil && err == nil {
+		err = rmErr
+	}
+
+	return err
+}
+
+// Publisher returns the block publishing node
+func (s *Simulator) Publisher() *Node {
+	return s.Nodes[0]
+}
+
+// CreateBlocks creates n blocks on the publisher. Each block has one transaction.
+// The blocks reach the other nodes through the network.
+func (s *Simulator) CreateBlocks(n int) error {
+	for i := 0; i < n; i++ {
+		if err := s.createBlock(); err != nil {
+			return fmt.Errorf("create block %d of %d: %v", i+1, n, err)
+		}
+	}
+	return nil
+}
+
+// createBlock creates a block with a transaction that spends the publisher's oldest output.
+// The first block splits the genesis output into two outputs, and following blocks alternate
+// between them, so that the spent output has always accumulated coin hours to pay the fee.
+func (s *Simulator) createBlock() error {
+	v := s.Publisher().Visor
+
+	headSeq, _, err := v.HeadBkSeq()
+	if err != nil {
+		return err
+	}
+	head, err := v.GetSignedBlockBySeq(headSeq)
+	if err != nil {
+		return err
+	}
+
+	ux := s.outputs[0]
+	hours, err := ux.CoinHours(head.Time())
+	if err != nil {
+		return err
+	}
+
+	var txn coin.Transaction
+	if err := txn.PushInput(ux.Hash()); err != nil {
+		return err
+	}
+
+	if len(s.outputs) == 1 {
+		// The outputs must differ, a transaction can not have duplicate outputs
+		coins := ux.Body.Coins / 2
+		if err := txn.PushOutput(ux.Body.Address, coins, hours/4); err != nil {
+			return err
+		}
+		if err := txn.PushOutput(ux.Body.Address, ux.Body.Coins-coins, hours/8); err != nil {
+			return err
+		}
+	} else {
+		if err := txn.PushOutput(ux.Body.Address, ux.Body.Coins, hours/2); err != nil {
+			return err
+		}
+	}
+
+	txn.SignInputs([]cipher.SecKey{s.genesisSecKey})
+	if err := txn.UpdateHeader(); err != nil {
+		return err
+	}
+
+	when := uint64(s.clock.Now().Unix())
+	if when <= head.Time() {
+		when = head.Time() + 1
+	}
+
+	b, err := v.CreateBlockFromTxns(coin.Transactions{txn}, when)
+	if err != nil {
+		return err
+	}
+
+	sb := coin.SignedBlock{
+		Block: b,
+		Sig:   cipher.MustSignHash(b.HashHeader(), s.blockchainSecKey),
+	}
+	if err := v.ExecuteSignedBlock(sb); err != nil {
+		return err
+	}
+
+	s.outputs = append(s.outputs[1:], coin.CreateUnspents(b.Head, txn)...)
+
+	return nil
+}
+
+// Partition splits the nodes into groups that cannot reach each other
+func (s *Simulator) Partition(groups ...[]int) {
+	hosts := make([][]string, len(groups))
+	for i, g := range groups {
+		for _, n := range g {
+			hosts[i] = append(hosts[i], s.Nodes[n].Host.IP())
+		}
+	}
+	s.Fabric.Partition(hosts...)
+}
+
+// Heal removes the partition
+func (s *Simulator) Heal() {
+	s.Fabric.Heal()
+}
+
+// Converged returns nil if all nodes have the same head block, otherwise an error describing the difference
+func (s *Simulator) Converged() error {
+	first, err := s.Nodes[0].HeadHash()
+	if err != nil {
+		return err
+	}
+
+	for _, n := range s.Nodes[1:] {
+		h, err := n.HeadHash()
+		if err != nil {
+			return err
+		}
+		if h != first {
+			seq0, _ := s.Nodes[0].HeadSeq() // nolint: errcheck
+			seq, _ := n.HeadSeq()           // nolint: errcheck
+			return fmt.Errorf("node %d head is block %d, node 0 head is block %d", n.Index, seq, seq0)
+		}
+	}
+
+	return nil
+}
+
+// WaitForConvergence waits until all nodes have the same head block
+func (s *Simulator) WaitForConvergence(timeout time.Duration) error {
+	return s.waitFor(timeout, s.Converged)
+}
+
+// WaitForConnections waits until every node has at least n introduced connections
+func (s *Simulator) WaitForConnections(n int, timeout time.Duration) error {
+	return s.waitFor(timeout, func() error {
+		for _, node := range s.Nodes {
+			conns, err := node.Daemon.GetConnections(func(c daemon.Connection) bool {
+				return c.State == daemon.ConnectionStateIntroduced
+			})
+			if err != nil {
+				return err
+			}
+			if len(conns) < n {
+				return fmt.Errorf("node %d has %d introduced connections", node.Index, len(conns))
+			}
+		}
+		return nil
+	})
+}
+
+// waitFor polls f until it returns nil or the timeout expires. On timeout, the last error from f is returned.
+func (s *Simulator) waitFor(timeout time.Duration, f func() error) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := f()
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%v: %v", ErrTimeout, err)
+		}
+
+		time.Sleep(pollRate)
+	}
+}
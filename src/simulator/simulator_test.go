@@ -0,0 +1,118 @@
+package simulator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const convergeTimeout = time.Second * 30
+
+func newTestSimulator(t *testing.T, c Config) *Simulator {
+	if testing.Short() {
+		t.Skip("skipping simulation in short mode")
+	}
+
+	s, err := New(c)
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+
+	return s
+}
+
+func TestSimulatorConverges(t *testing.T) {
+	c := NewConfig()
+	c.Link = Link{
+		Latency: time.Millisecond * 5,
+		Jitter:  time.Millisecond * 5,
+	}
+
+	s := newTestSimulator(t, c)
+	defer s.Stop() // nolint: errcheck
+
+	err := s.Run(
+		Connect(2, convergeTimeout),
+		CreateBlocks(20),
+		Converge(convergeTimeout),
+	)
+	require.NoError(t, err)
+
+	for _, n := range s.Nodes {
+		seq, err := n.HeadSeq()
+		require.NoError(t, err)
+		require.Equal(t, uint64(20), seq)
+	}
+}
+
+func TestSimulatorPartition(t *testing.T) {
+	c := NewConfig()
+	c.Nodes = 4
+
+	s := newTestSimulator(t, c)
+	defer s.Stop() // nolint: errcheck
+
+	err := s.Run(
+		Connect(3, convergeTimeout),
+		CreateBlocks(5),
+		Converge(convergeTimeout),
+		Partition([]int{0, 1}, []int{2, 3}),
+		CreateBlocks(10),
+	)
+	require.NoError(t, err)
+
+	// Nodes on the other side of the partition do not receive the new blocks
+	time.Sleep(time.Second)
+	for _, n := range s.Nodes[2:] {
+		seq, err := n.HeadSeq()
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), seq)
+	}
+	require.Error(t, s.Converged())
+
+	err = s.Run(
+		Heal(),
+		Converge(convergeTimeout),
+	)
+	require.NoError(t, err)
+
+	seq, err := s.Nodes[3].HeadSeq()
+	require.NoError(t, err)
+	require.Equal(t, uint64(15), seq)
+}
+
+func TestSimulatorManualClock(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Second)
+	clock := NewManualClock(start)
+
+	c := NewConfig()
+	c.Nodes = 2
+	c.Clock = clock
+
+	s := newTestSimulator(t, c)
+	defer s.Stop() // nolint: errcheck
+
+	err := s.Run(
+		CreateBlocks(1),
+		AdvanceClock(time.Hour),
+		CreateBlocks(1),
+	)
+	require.NoError(t, err)
+
+	b, err := s.Publisher().Visor.GetSignedBlockBySeq(2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(start.Add(time.Hour).Unix()), b.Time())
+
+	// AdvanceClock needs a ManualClock
+	s2, err := New(NewConfig())
+	require.NoError(t, err)
+	defer s2.Stop() // nolint: errcheck
+	require.Error(t, s2.Run(AdvanceClock(time.Second)))
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	c := NewConfig()
+	c.Nodes = 0
+	_, err := New(c)
+	require.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -99,6 +100,8 @@ type NodeConfig struct {
 	MaxLastBlocksCount uint64
 	// PeerlistSize represents the maximum number of peers that the pex would maintain
 	PeerlistSize int
+	// PeerlistNetgroupSize is the maximum number of peers from a single /16 network that peer exchange can add to the peerlist
+	PeerlistNetgroupSize int
 	// Wallet Address Version
 	// AddressVersion string
 	// Remote web interface
@@ -118,6 +121,17 @@ type NodeConfig struct {
 	WebInterfacePassword string
 	// Allow web interface auth without HTTPS
 	WebInterfacePlaintextAuth bool
+	// Enable API keys, managed with the web interface username and password
+	WebInterfaceAPIKeys bool
+	// File storing the API keys, defaults to $DATA_DIR/apikeys.json
+	WebInterfaceAPIKeysFile string
+	// Requests per minute that a client can make to each web interface endpoint, unlimited if zero
+	WebInterfaceRateLimit int
+	// Comma separated list of endpoint=requests per minute, overriding WebInterfaceRateLimit
+	WebInterfaceEndpointRateLimits string
+	endpointRateLimits             map[string]int
+	// Maximum number of requests served concurrently by the heavy web interface endpoints, unlimited if zero
+	WebInterfaceMaxHeavyRequests int
 
 	// Launch System Default Browser after client startup
 	LaunchBrowser bool
@@ -168,6 +182,8 @@ type NodeConfig struct {
 	WalletDirectory string
 	// Wallet crypto type
 	WalletCryptoType string
+	// Upgrade wallet files of older versions when loading them, keeping a backup of the original files
+	MigrateWallets bool
 
 	// Key-value storage
 	// Default to ${DataDirectory}/data
@@ -180,6 +196,14 @@ type NodeConfig struct {
 	CustomPeersFile string
 
 	RunBlockPublisher bool
+	// Encrypted key file holding the blockchain secret key of a block publisher, created with cmd/block-signer.
+	// Defaults to ${DataDirectory}/block-signer.key for block publishers
+	BlockSignerKeyFile string
+	// File containing the password of BlockSignerKeyFile.
+	// Defaults to ${DataDirectory}/block-signer.password for block publishers
+	BlockSignerPasswordFile string
+	// Unix socket of an external block signer process holding the blockchain secret key of a block publisher
+	BlockSignerAddr string
 
 	/* Developer options */
 
@@ -191,6 +215,8 @@ type NodeConfig struct {
 	HTTPProf bool
 	// Expose HTTP profiling on this interface
 	HTTPProfHost string
+	// Record all messages exchanged with peers to this file, for debugging. Disabled if empty.
+	CaptureFile string
 
 	DBPath     string
 	DBReadOnly bool
@@ -265,6 +291,7 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		MaxIncomingMessageLength: 1024 * 1024,
 		MaxLastBlocksCount:       256,
 		PeerlistSize:             65535,
+		PeerlistNetgroupSize:     256,
 		// Wallet Address Version
 		// AddressVersion: "test",
 		// Remote web interface
@@ -311,6 +338,7 @@ func NewNodeConfig(mode string, node fiber.NodeConfig) NodeConfig {
 		// Wallets
 		WalletDirectory:  "",
 		WalletCryptoType: string(crypto.DefaultCryptoType),
+		MigrateWallets:   false,
 
 		// Key-value storage
 		KVStorageDirectory: "",
@@ -407,6 +435,28 @@ func (c *Config) postProcess() error {
 		c.Node.WebInterfaceKey = replaceHome(c.Node.WebInterfaceKey, home)
 	}
 
+	if c.Node.WebInterfaceAPIKeysFile == "" {
+		c.Node.WebInterfaceAPIKeysFile = filepath.Join(c.Node.DataDirectory, "apikeys.json")
+	} else {
+		c.Node.WebInterfaceAPIKeysFile = replaceHome(c.Node.WebInterfaceAPIKeysFile, home)
+	}
+
+	// Block publishers sign the blocks with the encrypted key file in the data directory,
+	// unless an external signer process or the deprecated -blockchain-secret-key is set
+	if c.Node.RunBlockPublisher && c.Node.BlockSignerAddr == "" && c.Node.blockchainSeckey == (cipher.SecKey{}) {
+		if c.Node.BlockSignerKeyFile == "" {
+			c.Node.BlockSignerKeyFile = filepath.Join(c.Node.DataDirectory, "block-signer.key")
+		} else {
+			c.Node.BlockSignerKeyFile = replaceHome(c.Node.BlockSignerKeyFile, home)
+		}
+
+		if c.Node.BlockSignerPasswordFile == "" {
+			c.Node.BlockSignerPasswordFile = filepath.Join(c.Node.DataDirectory, "block-signer.password")
+		} else {
+			c.Node.BlockSignerPasswordFile = replaceHome(c.Node.BlockSignerPasswordFile, home)
+		}
+	}
+
 	if c.Node.WalletDirectory == "" {
 		c.Node.WalletDirectory = filepath.Join(c.Node.DataDirectory, "wallets")
 	} else {
@@ -463,6 +513,20 @@ func (c *Config) postProcess() error {
 		c.Node.DefaultConnections = nil
 	}
 
+	endpointRateLimits, err := parseEndpointRateLimits(c.Node.WebInterfaceEndpointRateLimits)
+	if err != nil {
+		return err
+	}
+	c.Node.endpointRateLimits = endpointRateLimits
+
+	if c.Node.WebInterfaceRateLimit < 0 {
+		return errors.New("-web-interface-rate-limit must not be negative")
+	}
+
+	if c.Node.WebInterfaceMaxHeavyRequests < 0 {
+		return errors.New("-web-interface-max-heavy-requests must not be negative")
+	}
+
 	if c.Node.HostWhitelist != "" {
 		if c.Node.DisableHeaderCheck {
 			return errors.New("host whitelist should be empty when header check is disabled")
@@ -475,6 +539,10 @@ func (c *Config) postProcess() error {
 		return errors.New("Web interface auth enabled but HTTPS is not enabled. Use -web-interface-plaintext-auth=true if this is desired")
 	}
 
+	if c.Node.WebInterfaceAPIKeys && (c.Node.WebInterfaceUsername == "" || c.Node.WebInterfacePassword == "") {
+		return errors.New("-web-interface-api-keys requires -web-interface-username and -web-interface-password, which are used to manage the API keys")
+	}
+
 	if c.Node.MaxConnections < c.Node.MaxOutgoingConnections+c.Node.MaxIncomingConnections {
 		return errors.New("-max-connections must be >= -max-outgoing-connections + -max-incoming-connections")
 	}
@@ -532,6 +600,16 @@ func (c *Config) postProcess() error {
 		return fmt.Errorf("-max-txn-size-create-block must be >= params.UserVerifyTxn.MaxTransactionSize (%d)", params.UserVerifyTxn.MaxTransactionSize)
 	}
 
+	if c.Node.BlockSignerKeyFile != "" && c.Node.BlockSignerAddr != "" {
+		return errors.New("-block-signer-key-file and -block-signer-addr cannot be combined")
+	}
+	if (c.Node.BlockSignerKeyFile != "" || c.Node.BlockSignerAddr != "") && c.Node.blockchainSeckey != (cipher.SecKey{}) {
+		return errors.New("-blockchain-secret-key cannot be combined with -block-signer-key-file or -block-signer-addr")
+	}
+	if c.Node.BlockSignerKeyFile != "" && c.Node.BlockSignerPasswordFile == "" {
+		return errors.New("-block-signer-password-file is required by -block-signer-key-file")
+	}
+
 	if c.Node.MaxBlockTransactionsSize < params.MinTransactionSize {
 		return fmt.Errorf("-max-block-size must be >= params.MinTransactionSize (%d)", params.MinTransactionSize)
 	}
@@ -600,6 +678,7 @@ func buildAPISets(c NodeConfig) (map[string]struct{}, error) {
 		api.EndpointsTransaction,
 		api.EndpointsNetCtrl,
 		api.EndpointsStorage,
+		api.EndpointsPrometheus,
 		// Do not include insecure or deprecated API sets, they must always
 		// be explicitly enabled through -enable-api-sets
 	}
@@ -623,6 +702,31 @@ func buildAPISets(c NodeConfig) (map[string]struct{}, error) {
 	return apiSets, nil
 }
 
+// parseEndpointRateLimits parses a comma separated list of endpoint=requests per minute,
+// for example "/api/v1/richlist=6,/api/v1/addresscount=6"
+func parseEndpointRateLimits(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	rates := make(map[string]int)
+	for _, v := range strings.Split(s, ",") {
+		pts := strings.Split(strings.TrimSpace(v), "=")
+		if len(pts) != 2 || !strings.HasPrefix(pts[0], "/") {
+			return nil, fmt.Errorf("Invalid value in -web-interface-endpoint-rate-limits: %q", v)
+		}
+
+		rate, err := strconv.Atoi(pts[1])
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("Invalid rate in -web-interface-endpoint-rate-limits: %q", v)
+		}
+
+		rates[pts[0]] = rate
+	}
+
+	return rates, nil
+}
+
 func validateAPISets(opt string, apiSets []string) error {
 	for _, k := range apiSets {
 		k = strings.ToUpper(strings.TrimSpace(k))
@@ -633,7 +737,8 @@ func validateAPISets(opt string, apiSets []string) error {
 			api.EndpointsWallet,
 			api.EndpointsInsecureWalletSeed,
 			api.EndpointsNetCtrl,
-			api.EndpointsStorage:
+			api.EndpointsStorage,
+			api.EndpointsPrometheus:
 		case "":
 			continue
 		default:
@@ -675,6 +780,7 @@ func (c *NodeConfig) RegisterFlags() {
 		api.EndpointsNetCtrl,
 		api.EndpointsInsecureWalletSeed,
 		api.EndpointsStorage,
+		api.EndpointsPrometheus,
 	}
 	flag.StringVar(&c.EnabledAPISets, "enable-api-sets", c.EnabledAPISets, fmt.Sprintf("enable API set. Options are %s. Multiple values should be separated by comma", strings.Join(allAPISets, ", ")))
 	flag.StringVar(&c.DisabledAPISets, "disable-api-sets", c.DisabledAPISets, fmt.Sprintf("disable API set. Options are %s. Multiple values should be separated by comma", strings.Join(allAPISets, ", ")))
@@ -683,6 +789,11 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.StringVar(&c.WebInterfaceUsername, "web-interface-username", c.WebInterfaceUsername, "username for the web interface")
 	flag.StringVar(&c.WebInterfacePassword, "web-interface-password", c.WebInterfacePassword, "password for the web interface")
 	flag.BoolVar(&c.WebInterfacePlaintextAuth, "web-interface-plaintext-auth", c.WebInterfacePlaintextAuth, "allow web interface auth without https")
+	flag.BoolVar(&c.WebInterfaceAPIKeys, "web-interface-api-keys", c.WebInterfaceAPIKeys, "enable API keys scoped to API sets and wallets, managed with the web interface username and password")
+	flag.StringVar(&c.WebInterfaceAPIKeysFile, "web-interface-api-keys-file", c.WebInterfaceAPIKeysFile, "file storing the API keys. Defaults to apikeys.json in --data-dir")
+	flag.IntVar(&c.WebInterfaceRateLimit, "web-interface-rate-limit", c.WebInterfaceRateLimit, "requests per minute that a client can make to each web interface endpoint. Clients are identified by API key, username or remote IP. 0 is unlimited")
+	flag.StringVar(&c.WebInterfaceEndpointRateLimits, "web-interface-endpoint-rate-limits", c.WebInterfaceEndpointRateLimits, "comma separated list of endpoint=requests per minute, overriding -web-interface-rate-limit for these endpoints, e.g. /api/v1/richlist=6,/api/v1/addresscount=6")
+	flag.IntVar(&c.WebInterfaceMaxHeavyRequests, "web-interface-max-heavy-requests", c.WebInterfaceMaxHeavyRequests, "maximum number of requests served concurrently by the web interface endpoints that scan the blockchain or the unspent outputs, such as /api/v1/richlist. 0 is unlimited")
 
 	flag.BoolVar(&c.LaunchBrowser, "launch-browser", c.LaunchBrowser, "launch system default webbrowser at client startup")
 	flag.StringVar(&c.DataDirectory, "data-dir", c.DataDirectory, "directory to store app data (defaults to ~/.skycoin)")
@@ -692,6 +803,7 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.StringVar(&c.ProfileCPUFile, "profile-cpu-file", c.ProfileCPUFile, "where to write the cpu profile file")
 	flag.BoolVar(&c.HTTPProf, "http-prof", c.HTTPProf, "run the HTTP profiling interface")
 	flag.StringVar(&c.HTTPProfHost, "http-prof-host", c.HTTPProfHost, "hostname to bind the HTTP profiling interface to")
+	flag.StringVar(&c.CaptureFile, "capture-file", c.CaptureFile, "record all messages exchanged with peers to this file. Read it with cmd/netcapture")
 	flag.StringVar(&c.LogLevel, "log-level", c.LogLevel, "Choices are: debug, info, warn, error, fatal, panic")
 	flag.BoolVar(&c.ColorLog, "color-log", c.ColorLog, "Add terminal colors to log output")
 	flag.BoolVar(&c.DisablePingPong, "no-ping-log", c.DisablePingPong, `disable "reply to ping" and "received pong" debug log messages`)
@@ -717,7 +829,10 @@ func (c *NodeConfig) RegisterFlags() {
 
 	flag.BoolVar(&c.RunBlockPublisher, "block-publisher", c.RunBlockPublisher, "run the daemon as a block publisher")
 	flag.StringVar(&c.BlockchainPubkeyStr, "blockchain-public-key", c.BlockchainPubkeyStr, "public key of the blockchain")
-	flag.StringVar(&c.BlockchainSeckeyStr, "blockchain-secret-key", c.BlockchainSeckeyStr, "secret key of the blockchain")
+	flag.StringVar(&c.BlockchainSeckeyStr, "blockchain-secret-key", c.BlockchainSeckeyStr, "secret key of the blockchain. Deprecated, use -block-signer-key-file or -block-signer-addr to keep the secret key out of the command line")
+	flag.StringVar(&c.BlockSignerKeyFile, "block-signer-key-file", c.BlockSignerKeyFile, "encrypted key file of the blockchain secret key, created with cmd/block-signer. Defaults to block-signer.key in the data directory for block publishers")
+	flag.StringVar(&c.BlockSignerPasswordFile, "block-signer-password-file", c.BlockSignerPasswordFile, "file containing the password of -block-signer-key-file. Defaults to block-signer.password in the data directory for block publishers")
+	flag.StringVar(&c.BlockSignerAddr, "block-signer-addr", c.BlockSignerAddr, "unix socket of an external block signer process holding the blockchain secret key")
 
 	flag.StringVar(&c.GenesisAddressStr, "genesis-address", c.GenesisAddressStr, "genesis address")
 	flag.StringVar(&c.GenesisSignatureStr, "genesis-signature", c.GenesisSignatureStr, "genesis block signature")
@@ -730,11 +845,13 @@ func (c *NodeConfig) RegisterFlags() {
 	flag.IntVar(&c.MaxIncomingConnections, "max-incoming-connections", c.MaxIncomingConnections, "Maximum number of incoming connections allowd")
 	flag.IntVar(&c.MaxDefaultPeerOutgoingConnections, "max-default-peer-outgoing-connections", c.MaxDefaultPeerOutgoingConnections, "The maximum default peer outgoing connections allowed")
 	flag.IntVar(&c.PeerlistSize, "peerlist-size", c.PeerlistSize, "Max number of peers to track in peerlist")
+	flag.IntVar(&c.PeerlistNetgroupSize, "peerlist-netgroup-size", c.PeerlistNetgroupSize, "Max number of peers from a single /16 network that peer exchange can add to the peerlist")
 	flag.DurationVar(&c.OutgoingConnectionsRate, "connection-rate", c.OutgoingConnectionsRate, "How often to make an outgoing connection")
 	flag.IntVar(&c.MaxOutgoingMessageLength, "max-out-msg-len", c.MaxOutgoingMessageLength, "Maximum length of outgoing wire messages")
 	flag.IntVar(&c.MaxIncomingMessageLength, "max-in-msg-len", c.MaxIncomingMessageLength, "Maximum length of incoming wire messages")
 	flag.BoolVar(&c.LocalhostOnly, "localhost-only", c.LocalhostOnly, "Run on localhost and only connect to localhost peers")
 	flag.StringVar(&c.WalletCryptoType, "wallet-crypto-type", c.WalletCryptoType, "wallet crypto type. Can be sha256-xor or scrypt-chacha20poly1305")
+	flag.BoolVar(&c.MigrateWallets, "migrate-wallets", c.MigrateWallets, "Upgrade wallet files of older versions on startup, the original files are kept as .bak files. Wallets are upgraded in memory regardless")
 	flag.BoolVar(&c.Version, "version", false, "show node version")
 }
 
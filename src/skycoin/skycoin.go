@@ -4,8 +4,10 @@ Package skycoin implements the main daemon cmd's configuration and setup
 package skycoin
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -24,6 +26,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher/crypto"
 	"github.com/skycoin/skycoin/src/coin"
 	"github.com/skycoin/skycoin/src/daemon"
+	"github.com/skycoin/skycoin/src/daemon/capture"
 	"github.com/skycoin/skycoin/src/kvstorage"
 	"github.com/skycoin/skycoin/src/params"
 	"github.com/skycoin/skycoin/src/readable"
@@ -147,6 +150,42 @@ func (c *Coin) Run() error {
 	vconf := c.ConfigureVisor()
 	sconf := c.ConfigureStorage()
 
+	if vconf.IsBlockPublisher {
+		signer, err := c.ConfigureBlockSigner()
+		if err != nil {
+			c.logger.WithError(err).Error("ConfigureBlockSigner failed")
+			return err
+		}
+
+		if signer != nil {
+			vconf.BlockSigner = signer
+			if closer, ok := signer.(io.Closer); ok {
+				defer func() {
+					if err := closer.Close(); err != nil {
+						c.logger.WithError(err).Error("Failed to close block signer")
+					}
+				}()
+			}
+		}
+	}
+
+	if c.config.Node.CaptureFile != "" {
+		c.logger.Infof("Capturing peer messages to %s", c.config.Node.CaptureFile)
+		cw, err := capture.Create(c.config.Node.CaptureFile)
+		if err != nil {
+			c.logger.WithError(err).Error("capture.Create failed")
+			return err
+		}
+
+		defer func() {
+			if err := cw.Close(); err != nil {
+				c.logger.WithError(err).Error("Failed to close capture file")
+			}
+		}()
+
+		dconf.Pool.MessageCallback = cw.MessageCallback
+	}
+
 	// Open the database
 	c.logger.Infof("Opening database %s", c.config.Node.DBPath)
 	db, err = visor.OpenDB(c.config.Node.DBPath, c.config.Node.DBReadOnly)
@@ -362,6 +401,44 @@ func (c *Coin) ConfigureVisor() visor.Config {
 	return vc
 }
 
+// ConfigureBlockSigner creates the signer of the blocks of a block publisher node,
+// which holds the blockchain secret key in an encrypted key file or in an external signer process.
+// Returns nil if the blocks are signed with the deprecated blockchain secret key of the config.
+func (c *Coin) ConfigureBlockSigner() (visor.BlockSigner, error) {
+	switch {
+	case c.config.Node.BlockSignerAddr != "":
+		c.logger.Infof("Using block signer at %s", c.config.Node.BlockSignerAddr)
+		s, err := visor.DialBlockSigner(c.config.Node.BlockSignerAddr, 0)
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+
+	case c.config.Node.BlockSignerKeyFile != "":
+		if _, err := os.Stat(c.config.Node.BlockSignerKeyFile); os.IsNotExist(err) {
+			return nil, fmt.Errorf("block signer key file %s doesn't exist, create it with cmd/block-signer keygen", c.config.Node.BlockSignerKeyFile)
+		}
+
+		password, err := ioutil.ReadFile(c.config.Node.BlockSignerPasswordFile)
+		if err != nil {
+			return nil, err
+		}
+
+		c.logger.Infof("Using block signer key file %s", c.config.Node.BlockSignerKeyFile)
+		s, err := visor.LoadBlockSignerKeyFile(c.config.Node.BlockSignerKeyFile, bytes.TrimRight(password, "\r\n"))
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+
+	default:
+		if c.config.Node.blockchainSeckey != (cipher.SecKey{}) {
+			c.logger.Warning("-blockchain-secret-key is deprecated, the secret key is exposed in the command line or the config. Use -block-signer-key-file or -block-signer-addr instead")
+		}
+		return nil, nil
+	}
+}
+
 // ConfigureWallet sets the wallet config values
 func (c *Coin) ConfigureWallet() wallet.Config {
 	wc := wallet.NewConfig()
@@ -377,6 +454,7 @@ func (c *Coin) ConfigureWallet() wallet.Config {
 	}
 
 	wc.CryptoType = cryptoType
+	wc.MigrateWallets = c.config.Node.MigrateWallets
 
 	bc := c.config.Node.Fiber.Bip44Coin
 	wc.Bip44Coin = &bc
@@ -411,6 +489,7 @@ func (c *Coin) ConfigureDaemon() daemon.Config {
 	dc.Pex.Disabled = c.config.Node.DisablePEX
 	dc.Pex.NetworkDisabled = c.config.Node.DisableNetworking
 	dc.Pex.Max = c.config.Node.PeerlistSize
+	dc.Pex.MaxPeersPerNetgroup = c.config.Node.PeerlistNetgroupSize
 	dc.Pex.DownloadPeerList = c.config.Node.DownloadPeerList
 	dc.Pex.PeerListURL = c.config.Node.PeerListURL
 	dc.Pex.DisableTrustedPeers = c.config.Node.DisableDefaultPeers
@@ -469,6 +548,15 @@ func (c *Coin) createGUI(gw *api.Gateway, host string) (*api.Server, error) {
 		},
 		Username: c.config.Node.WebInterfaceUsername,
 		Password: c.config.Node.WebInterfacePassword,
+		RateLimit: api.RateLimitConfig{
+			Rate:             c.config.Node.WebInterfaceRateLimit,
+			EndpointRates:    c.config.Node.endpointRateLimits,
+			MaxHeavyRequests: c.config.Node.WebInterfaceMaxHeavyRequests,
+		},
+	}
+
+	if c.config.Node.WebInterfaceAPIKeys {
+		config.APIKeysFile = c.config.Node.WebInterfaceAPIKeysFile
 	}
 
 	var s *api.Server
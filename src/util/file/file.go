@@ -311,9 +311,10 @@ func Exists(fn string) (bool, error) {
 	return true, nil
 }
 
-// IsWritable checks if the file is writable
+// IsWritable checks if the file is writable. The file is not truncated, the caller
+// may still read it before writing it.
 func IsWritable(name string) bool {
-	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE, 0600)
 	if err != nil && os.IsPermission(err) {
 		return false
 	}
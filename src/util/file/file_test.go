@@ -264,4 +264,9 @@ func TestIsWritable(t *testing.T) {
 	err = os.Chmod(fn, 0600)
 	require.NoError(t, err)
 	require.True(t, IsWritable(fn))
+
+	// The file content is kept
+	data, err := ioutil.ReadFile(fn)
+	require.NoError(t, err)
+	require.Equal(t, b, data)
 }
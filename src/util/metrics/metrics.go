@@ -0,0 +1,612 @@
+/*
+Package metrics implements counters, gauges and histograms that are exported
+in the Prometheus text exposition format.
+
+Metrics are created by the packages that update them, usually as package variables,
+and registered with DefaultRegistry:
+
+	var blocksExecuted = metrics.MustRegisterCounter(metrics.NewCounter("skycoin_blocks_executed_total", "Number of blocks executed"))
+
+A metric may have labels. Each distinct set of label values is a separate time series:
+
+	var messages = metrics.NewCounterVec("skycoin_messages_total", "Number of messages", "type")
+	messages.WithLabelValues("GIVB").Inc()
+*/
+package metrics
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// ContentType is the content type of the text exposition format
+	ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+var (
+	// DefaultRegistry is the registry that package level metrics are registered with
+	DefaultRegistry = NewRegistry()
+
+	// DefBuckets are the default histogram buckets, in seconds, suited to the duration of
+	// database transactions and other in-process operations
+	DefBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+	// ErrDuplicateMetric is returned when registering a metric with a name that is already registered
+	ErrDuplicateMetric = errors.New("a metric with this name is already registered")
+	// ErrInvalidName is returned for a metric or label name that is not valid
+	ErrInvalidName = errors.New("invalid metric or label name")
+
+	nameRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+)
+
+// Metric is a named time series, or a family of time series distinguished by labels
+type Metric interface {
+	// Name returns the metric name
+	Name() string
+	// Help returns the metric description
+	Help() string
+	// Type returns the metric type, "counter", "gauge" or "histogram"
+	Type() string
+	// Samples returns the current values of the metric's time series
+	Samples() []Sample
+}
+
+// Sample is a single value of a time series
+type Sample struct {
+	// Suffix is appended to the metric name, e.g. "_bucket" for histogram buckets
+	Suffix string
+	Labels []Label
+	Value  float64
+}
+
+// Label is a label name and value
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Registry is a set of metrics with unique names
+type Registry struct {
+	sync.Mutex
+	metrics map[string]Metric
+}
+
+// NewRegistry creates a Registry
+func NewRegistry() *Registry {
+	return &Registry{
+		metrics: make(map[string]Metric),
+	}
+}
+
+// Register adds a metric to the registry
+func (r *Registry) Register(m Metric) error {
+	if !nameRegexp.MatchString(m.Name()) {
+		return ErrInvalidName
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.metrics[m.Name()]; ok {
+		return ErrDuplicateMetric
+	}
+	r.metrics[m.Name()] = m
+	return nil
+}
+
+// MustRegister adds metrics to the registry, panicking on error
+func (r *Registry) MustRegister(ms ...Metric) {
+	for _, m := range ms {
+		if err := r.Register(m); err != nil {
+			panic(fmt.Sprintf("metrics: register %q: %v", m.Name(), err))
+		}
+	}
+}
+
+// Unregister removes the metric with the given name. Returns false if no metric was registered with that name.
+func (r *Registry) Unregister(name string) bool {
+	r.Lock()
+	defer r.Unlock()
+
+	_, ok := r.metrics[name]
+	delete(r.metrics, name)
+	return ok
+}
+
+// Metrics returns the registered metrics, sorted by name
+func (r *Registry) Metrics() []Metric {
+	r.Lock()
+	defer r.Unlock()
+
+	ms := make([]Metric, 0, len(r.metrics))
+	for _, m := range r.metrics {
+		ms = append(ms, m)
+	}
+
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].Name() < ms[j].Name()
+	})
+
+	return ms
+}
+
+// WriteText writes the registered metrics in the text exposition format
+func (r *Registry) WriteText(w io.Writer) error {
+	return WriteText(w, r.Metrics()...)
+}
+
+// WriteText writes metrics in the text exposition format
+func WriteText(w io.Writer, ms ...Metric) error {
+	bw := bufio.NewWriter(w)
+
+	for _, m := range ms {
+		samples := m.Samples()
+		if len(samples) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.Name(), escapeHelp(m.Help()))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", m.Name(), m.Type())
+
+		for _, s := range samples {
+			bw.WriteString(m.Name())
+			bw.WriteString(s.Suffix)
+			if len(s.Labels) != 0 {
+				bw.WriteByte('{')
+				for i, l := range s.Labels {
+					if i != 0 {
+						bw.WriteByte(',')
+					}
+					fmt.Fprintf(bw, "%s=\"%s\"", l.Name, escapeLabelValue(l.Value))
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteByte(' ')
+			bw.WriteString(formatFloat(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+
+	return bw.Flush()
+}
+
+// MustRegisterCounter registers a CounterVec with DefaultRegistry and returns it
+func MustRegisterCounter(c *CounterVec) *CounterVec {
+	DefaultRegistry.MustRegister(c)
+	return c
+}
+
+// MustRegisterGauge registers a GaugeVec with DefaultRegistry and returns it
+func MustRegisterGauge(g *GaugeVec) *GaugeVec {
+	DefaultRegistry.MustRegister(g)
+	return g
+}
+
+// MustRegisterHistogram registers a HistogramVec with DefaultRegistry and returns it
+func MustRegisterHistogram(h *HistogramVec) *HistogramVec {
+	DefaultRegistry.MustRegister(h)
+	return h
+}
+
+// vec holds the time series of a metric, keyed by their label values
+type vec struct {
+	sync.Mutex
+	name       string
+	help       string
+	labelNames []string
+	series     map[string]*series
+	newValue   func() value
+}
+
+type series struct {
+	labels []Label
+	value  value
+}
+
+type value interface {
+	samples(labels []Label) []Sample
+}
+
+func newVec(name, help string, labelNames []string, newValue func() value) vec {
+	for _, l := range labelNames {
+		if !nameRegexp.MatchString(l) || strings.HasPrefix(l, "__") || l == "le" {
+			panic(fmt.Sprintf("metrics: %s: invalid label name %q", name, l))
+		}
+	}
+
+	return vec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		series:     make(map[string]*series),
+		newValue:   newValue,
+	}
+}
+
+// Name returns the metric name
+func (v *vec) Name() string {
+	return v.name
+}
+
+// Help returns the metric description
+func (v *vec) Help() string {
+	return v.help
+}
+
+// get returns the value for the label values, creating it if necessary
+func (v *vec) get(labelValues []string) value {
+	if len(labelValues) != len(v.labelNames) {
+		panic(fmt.Sprintf("metrics: %s: expected %d label values, got %d", v.name, len(v.labelNames), len(labelValues)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+
+	v.Lock()
+	defer v.Unlock()
+
+	s, ok := v.series[key]
+	if !ok {
+		labels := make([]Label, len(labelValues))
+		for i, lv := range labelValues {
+			labels[i] = Label{
+				Name:  v.labelNames[i],
+				Value: lv,
+			}
+		}
+
+		s = &series{
+			labels: labels,
+			value:  v.newValue(),
+		}
+		v.series[key] = s
+	}
+
+	return s.value
+}
+
+// Reset removes all of the time series
+func (v *vec) Reset() {
+	v.Lock()
+	defer v.Unlock()
+	v.series = make(map[string]*series)
+}
+
+// Samples returns the current values of the metric's time series, sorted by label values
+func (v *vec) Samples() []Sample {
+	v.Lock()
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	all := make([]*series, len(keys))
+	for i, k := range keys {
+		all[i] = v.series[k]
+	}
+	v.Unlock()
+
+	var samples []Sample
+	for _, s := range all {
+		samples = append(samples, s.value.samples(s.labels)...)
+	}
+	return samples
+}
+
+// CounterVec is a counter, partitioned by labels
+type CounterVec struct {
+	vec
+}
+
+// NewCounterVec creates a CounterVec. A counter with no label names has a single time series.
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	return &CounterVec{
+		vec: newVec(name, help, labelNames, func() value {
+			return &Counter{}
+		}),
+	}
+}
+
+// NewCounter creates a counter without labels
+func NewCounter(name, help string) *CounterVec {
+	return NewCounterVec(name, help)
+}
+
+// Type returns "counter"
+func (c *CounterVec) Type() string {
+	return typeCounter
+}
+
+// WithLabelValues returns the counter for the label values, which are given in the order of the label names
+func (c *CounterVec) WithLabelValues(labelValues ...string) *Counter {
+	return c.get(labelValues).(*Counter)
+}
+
+// Inc increments the counter of a CounterVec without labels
+func (c *CounterVec) Inc() {
+	c.WithLabelValues().Inc()
+}
+
+// Add adds to the counter of a CounterVec without labels
+func (c *CounterVec) Add(v float64) {
+	c.WithLabelValues().Add(v)
+}
+
+// Counter is a value that only increases
+type Counter struct {
+	sync.Mutex
+	v float64
+}
+
+// Inc increments the counter by 1
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+// Add adds v to the counter. Panics if v is negative.
+func (c *Counter) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.v += v
+}
+
+// Value returns the counter's value
+func (c *Counter) Value() float64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.v
+}
+
+func (c *Counter) samples(labels []Label) []Sample {
+	return []Sample{
+		{
+			Labels: labels,
+			Value:  c.Value(),
+		},
+	}
+}
+
+// GaugeVec is a gauge, partitioned by labels
+type GaugeVec struct {
+	vec
+}
+
+// NewGaugeVec creates a GaugeVec. A gauge with no label names has a single time series.
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	return &GaugeVec{
+		vec: newVec(name, help, labelNames, func() value {
+			return &Gauge{}
+		}),
+	}
+}
+
+// NewGauge creates a gauge without labels
+func NewGauge(name, help string) *GaugeVec {
+	return NewGaugeVec(name, help)
+}
+
+// Type returns "gauge"
+func (g *GaugeVec) Type() string {
+	return typeGauge
+}
+
+// WithLabelValues returns the gauge for the label values, which are given in the order of the label names
+func (g *GaugeVec) WithLabelValues(labelValues ...string) *Gauge {
+	return g.get(labelValues).(*Gauge)
+}
+
+// Set sets the gauge of a GaugeVec without labels
+func (g *GaugeVec) Set(v float64) {
+	g.WithLabelValues().Set(v)
+}
+
+// Gauge is a value that can go up and down
+type Gauge struct {
+	sync.Mutex
+	v float64
+}
+
+// Set sets the gauge's value
+func (g *Gauge) Set(v float64) {
+	g.Lock()
+	defer g.Unlock()
+	g.v = v
+}
+
+// Add adds v, which may be negative, to the gauge
+func (g *Gauge) Add(v float64) {
+	g.Lock()
+	defer g.Unlock()
+	g.v += v
+}
+
+// Value returns the gauge's value
+func (g *Gauge) Value() float64 {
+	g.Lock()
+	defer g.Unlock()
+	return g.v
+}
+
+func (g *Gauge) samples(labels []Label) []Sample {
+	return []Sample{
+		{
+			Labels: labels,
+			Value:  g.Value(),
+		},
+	}
+}
+
+// HistogramVec is a histogram, partitioned by labels
+type HistogramVec struct {
+	vec
+}
+
+// NewHistogramVec creates a HistogramVec. Buckets are the upper bounds of the histogram buckets, in increasing order.
+// If buckets is nil, DefBuckets is used.
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: %s: histogram buckets are not sorted", name))
+	}
+	buckets = append([]float64(nil), buckets...)
+
+	return &HistogramVec{
+		vec: newVec(name, help, labelNames, func() value {
+			return &Histogram{
+				buckets: buckets,
+				counts:  make([]uint64, len(buckets)),
+			}
+		}),
+	}
+}
+
+// NewHistogram creates a histogram without labels
+func NewHistogram(name, help string, buckets []float64) *HistogramVec {
+	return NewHistogramVec(name, help, buckets)
+}
+
+// Type returns "histogram"
+func (h *HistogramVec) Type() string {
+	return typeHistogram
+}
+
+// WithLabelValues returns the histogram for the label values, which are given in the order of the label names
+func (h *HistogramVec) WithLabelValues(labelValues ...string) *Histogram {
+	return h.get(labelValues).(*Histogram)
+}
+
+// Observe adds an observation to the histogram of a HistogramVec without labels
+func (h *HistogramVec) Observe(v float64) {
+	h.WithLabelValues().Observe(v)
+}
+
+// Histogram counts observations in buckets
+type Histogram struct {
+	sync.Mutex
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+// Observe adds an observation
+func (h *Histogram) Observe(v float64) {
+	h.Lock()
+	defer h.Unlock()
+
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+			break
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveDuration adds the time elapsed since start, in seconds, as an observation
+func (h *Histogram) ObserveDuration(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	h.Lock()
+	defer h.Unlock()
+	return h.count
+}
+
+// Sum returns the sum of the observations
+func (h *Histogram) Sum() float64 {
+	h.Lock()
+	defer h.Unlock()
+	return h.sum
+}
+
+func (h *Histogram) samples(labels []Label) []Sample {
+	h.Lock()
+	defer h.Unlock()
+
+	samples := make([]Sample, 0, len(h.buckets)+3)
+
+	var cumulative uint64
+	for i, b := range h.buckets {
+		cumulative += h.counts[i]
+		samples = append(samples, Sample{
+			Suffix: "_bucket",
+			Labels: withLabel(labels, "le", formatFloat(b)),
+			Value:  float64(cumulative),
+		})
+	}
+
+	samples = append(samples, Sample{
+		Suffix: "_bucket",
+		Labels: withLabel(labels, "le", "+Inf"),
+		Value:  float64(h.count),
+	}, Sample{
+		Suffix: "_sum",
+		Labels: labels,
+		Value:  h.sum,
+	}, Sample{
+		Suffix: "_count",
+		Labels: labels,
+		Value:  float64(h.count),
+	})
+
+	return samples
+}
+
+func withLabel(labels []Label, name, value string) []Label {
+	l := make([]Label, len(labels), len(labels)+1)
+	copy(l, labels)
+	return append(l, Label{
+		Name:  name,
+		Value: value,
+	})
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+func escapeLabelValue(s string) string {
+	return labelReplacer.Replace(s)
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+
+	c := NewCounterVec("test_messages_total", "Number of messages\nreceived or sent", "direction", "type")
+	g := NewGauge("test_head_seq", "Head block sequence")
+	h := NewHistogramVec("test_duration_seconds", "Duration", []float64{0.1, 1}, "op")
+	empty := NewCounterVec("test_empty_total", "Has no series", "type")
+	r.MustRegister(c, g, h, empty)
+
+	c.WithLabelValues("out", "GIVB").Inc()
+	c.WithLabelValues("in", "GIVB").Add(2)
+	c.WithLabelValues("in", `a"b\`).Inc()
+	g.Set(12)
+	h.WithLabelValues("view").Observe(0.05)
+	h.WithLabelValues("view").Observe(0.5)
+	h.WithLabelValues("view").Observe(5)
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteText(&buf))
+
+	expected := `# HELP test_duration_seconds Duration
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{op="view",le="0.1"} 1
+test_duration_seconds_bucket{op="view",le="1"} 2
+test_duration_seconds_bucket{op="view",le="+Inf"} 3
+test_duration_seconds_sum{op="view"} 5.55
+test_duration_seconds_count{op="view"} 3
+# HELP test_head_seq Head block sequence
+# TYPE test_head_seq gauge
+test_head_seq 12
+# HELP test_messages_total Number of messages\nreceived or sent
+# TYPE test_messages_total counter
+test_messages_total{direction="in",type="GIVB"} 2
+test_messages_total{direction="in",type="a\"b\\"} 1
+test_messages_total{direction="out",type="GIVB"} 1
+`
+	require.Equal(t, expected, buf.String())
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+
+	require.NoError(t, r.Register(NewCounter("test_total", "")))
+	require.Equal(t, ErrDuplicateMetric, r.Register(NewGauge("test_total", "")))
+	require.Equal(t, ErrInvalidName, r.Register(NewGauge("test-gauge", "")))
+	require.Panics(t, func() {
+		r.MustRegister(NewCounter("test_total", ""))
+	})
+
+	require.True(t, r.Unregister("test_total"))
+	require.False(t, r.Unregister("test_total"))
+	require.NoError(t, r.Register(NewGauge("test_total", "")))
+	require.Len(t, r.Metrics(), 1)
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounterVec("test_total", "", "type")
+
+	c.WithLabelValues("a").Inc()
+	c.WithLabelValues("a").Add(1.5)
+	require.Equal(t, 2.5, c.WithLabelValues("a").Value())
+	require.Equal(t, 0.0, c.WithLabelValues("b").Value())
+
+	require.Panics(t, func() {
+		c.WithLabelValues("a").Add(-1)
+	})
+
+	// Wrong number of label values
+	require.Panics(t, func() {
+		c.WithLabelValues()
+	})
+	require.Panics(t, func() {
+		c.Inc()
+	})
+
+	c.Reset()
+	require.Empty(t, c.Samples())
+}
+
+func TestGauge(t *testing.T) {
+	g := NewGaugeVec("test_gauge", "", "state")
+
+	g.WithLabelValues("a").Set(3)
+	g.WithLabelValues("a").Add(-5)
+	require.Equal(t, -2.0, g.WithLabelValues("a").Value())
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("test_seconds", "", nil)
+	require.Len(t, h.WithLabelValues().buckets, len(DefBuckets))
+
+	h.Observe(0.0001)
+	h.Observe(100)
+	require.Equal(t, uint64(2), h.WithLabelValues().Count())
+	require.Equal(t, 100.0001, h.WithLabelValues().Sum())
+
+	samples := h.Samples()
+	require.Len(t, samples, len(DefBuckets)+3)
+	// The first bucket counts the small observation, and the +Inf bucket counts both
+	require.Equal(t, 1.0, samples[0].Value)
+	require.Equal(t, 1.0, samples[len(DefBuckets)-1].Value)
+	require.Equal(t, 2.0, samples[len(DefBuckets)].Value)
+
+	require.Panics(t, func() {
+		NewHistogram("test_unsorted", "", []float64{1, 0.5})
+	})
+	require.Panics(t, func() {
+		NewHistogramVec("test_le", "", nil, "le")
+	})
+}
@@ -0,0 +1,475 @@
+package visor
+
+// This file contains the Visor methods for the balances of addresses and wallets in the past
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// MaxBalanceSeriesPoints is the maximum number of points of a balance series
+const MaxBalanceSeriesPoints = 1000
+
+// BalanceBucket is the period covered by each point of a balance series
+type BalanceBucket string
+
+const (
+	// BalanceBucketHour buckets the series by hour
+	BalanceBucketHour BalanceBucket = "hour"
+	// BalanceBucketDay buckets the series by day
+	BalanceBucketDay BalanceBucket = "day"
+	// BalanceBucketWeek buckets the series by week, weeks start on Monday
+	BalanceBucketWeek BalanceBucket = "week"
+	// BalanceBucketMonth buckets the series by calendar month
+	BalanceBucketMonth BalanceBucket = "month"
+	// BalanceBucketQuarter buckets the series by calendar quarter
+	BalanceBucketQuarter BalanceBucket = "quarter"
+	// BalanceBucketYear buckets the series by calendar year
+	BalanceBucketYear BalanceBucket = "year"
+)
+
+var (
+	// ErrBlockAtSeqAndTime seq and time cannot be combined to select a block
+	ErrBlockAtSeqAndTime = NewUserError(errors.New("seq and time cannot be combined"))
+	// ErrBlockAtSeqNotFound the selected block seq is above the head block
+	ErrBlockAtSeqNotFound = NewUserError(errors.New("block seq is above the head block"))
+	// ErrBlockAtTimeNotFound the selected time is before the genesis block
+	ErrBlockAtTimeNotFound = NewUserError(errors.New("no block was created at or before the time"))
+	// ErrInvalidBalanceBucket the bucket of a balance series is not valid
+	ErrInvalidBalanceBucket = NewUserError(errors.New("invalid bucket, must be hour, day, week, month, quarter or year"))
+	// ErrBalanceSeriesEndBeforeStart the end of a balance series is before its start
+	ErrBalanceSeriesEndBeforeStart = NewUserError(errors.New("end is before start"))
+	// ErrBalanceSeriesTooLong the balance series has too many points
+	ErrBalanceSeriesTooLong = NewUserError(fmt.Errorf("balance series has more than %d points", MaxBalanceSeriesPoints))
+)
+
+// BlockAt selects a block of the blockchain, by seq or as the last block created at or before
+// a unix time. The head block is selected if neither is set.
+type BlockAt struct {
+	Seq  *uint64
+	Time *uint64
+}
+
+// HistoricalBalance is the confirmed balance of addresses after a block was executed.
+// The coin hours are computed at the time of the block.
+type HistoricalBalance struct {
+	BlockSeq  uint64
+	BlockTime uint64
+	Balance   wallet.Balance
+	Addresses map[cipher.Address]wallet.Balance
+}
+
+// BalanceSeriesQuery selects the points of a balance series
+type BalanceSeriesQuery struct {
+	// Start is the unix time of the start of the series, the time of the genesis block if 0
+	Start uint64
+	// End is the unix time of the end of the series, the time of the head block if 0
+	End    uint64
+	Bucket BalanceBucket
+}
+
+// BalanceSeriesPoint is the confirmed balance of addresses at the end of a bucket of a balance series
+type BalanceSeriesPoint struct {
+	// BucketStart and BucketEnd are the unix times of the start and the end of the bucket, the end is excluded
+	BucketStart uint64
+	BucketEnd   uint64
+	// BlockSeq and BlockTime are the seq and time of the last block created before the end of the bucket
+	BlockSeq  uint64
+	BlockTime uint64
+	Balance   wallet.Balance
+}
+
+// GetBalanceOfAddressesAt returns the confirmed balance of addresses as of a block
+func (vs *Visor) GetBalanceOfAddressesAt(addrs []cipher.Address, at BlockAt) (*HistoricalBalance, error) {
+	var b *HistoricalBalance
+	if err := vs.db.View("GetBalanceOfAddressesAt", func(tx *dbutil.Tx) error {
+		var err error
+		b, err = vs.getBalanceOfAddressesAt(tx, addrs, at)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// GetWalletBalanceAt returns the confirmed balance of all addresses of a wallet as of a block
+func (vs *Visor) GetWalletBalanceAt(wltID string, at BlockAt) (*HistoricalBalance, error) {
+	addrs, err := vs.walletAddresses(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	return vs.GetBalanceOfAddressesAt(addrs, at)
+}
+
+// GetBalanceSeriesOfAddresses returns the confirmed balance of addresses at the end of each bucket of a period.
+// Buckets that end before the genesis block have no point.
+func (vs *Visor) GetBalanceSeriesOfAddresses(addrs []cipher.Address, q BalanceSeriesQuery) ([]BalanceSeriesPoint, error) {
+	var points []BalanceSeriesPoint
+	if err := vs.db.View("GetBalanceSeriesOfAddresses", func(tx *dbutil.Tx) error {
+		var err error
+		points, err = vs.getBalanceSeriesOfAddresses(tx, addrs, q)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// GetWalletBalanceSeries returns the confirmed balance of all addresses of a wallet at the end of each bucket of a period
+func (vs *Visor) GetWalletBalanceSeries(wltID string, q BalanceSeriesQuery) ([]BalanceSeriesPoint, error) {
+	addrs, err := vs.walletAddresses(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	return vs.GetBalanceSeriesOfAddresses(addrs, q)
+}
+
+// walletAddresses returns the addresses of all accounts of a wallet
+func (vs *Visor) walletAddresses(wltID string) ([]cipher.Address, error) {
+	var addrs []cipher.Address
+	if err := vs.wallets.View(wltID, func(w wallet.Wallet) error {
+		var err error
+		addrs, err = wallet.AllAddresses(w)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return addrs, nil
+}
+
+func (vs *Visor) getBalanceOfAddressesAt(tx *dbutil.Tx, addrs []cipher.Address, at BlockAt) (*HistoricalBalance, error) {
+	b, err := vs.blockAt(tx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	outs, err := vs.addressesHistoryOutputs(tx, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	hb := &HistoricalBalance{
+		BlockSeq:  b.Seq(),
+		BlockTime: b.Time(),
+		Addresses: make(map[cipher.Address]wallet.Balance, len(addrs)),
+	}
+
+	for _, addr := range addrs {
+		bal, err := balanceAtBlock(outs[addr], b.Seq(), b.Time())
+		if err != nil {
+			return nil, err
+		}
+
+		hb.Addresses[addr] = bal
+
+		hb.Balance, err = addBalance(hb.Balance, bal)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return hb, nil
+}
+
+func (vs *Visor) getBalanceSeriesOfAddresses(tx *dbutil.Tx, addrs []cipher.Address, q BalanceSeriesQuery) ([]BalanceSeriesPoint, error) {
+	if !q.Bucket.valid() {
+		return nil, ErrInvalidBalanceBucket
+	}
+
+	genesis, err := vs.blockchain.GetGenesisBlock(tx)
+	if err != nil {
+		return nil, err
+	}
+	if genesis == nil {
+		return nil, errors.New("genesis block not found")
+	}
+
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	start := q.Start
+	if start == 0 {
+		start = genesis.Time()
+	}
+
+	end := q.End
+	if end == 0 {
+		end = head.Time()
+	}
+
+	if end < start {
+		return nil, ErrBalanceSeriesEndBeforeStart
+	}
+
+	// Buckets that end before the genesis block have no balance
+	if start < genesis.Time() {
+		start = genesis.Time()
+	}
+
+	var buckets [][2]uint64
+	for t := q.Bucket.start(start); t <= end; t = q.Bucket.next(t) {
+		if len(buckets) == MaxBalanceSeriesPoints {
+			return nil, ErrBalanceSeriesTooLong
+		}
+		buckets = append(buckets, [2]uint64{t, q.Bucket.next(t)})
+	}
+
+	outs, err := vs.addressesHistoryOutputs(tx, addrs)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]BalanceSeriesPoint, 0, len(buckets))
+	for _, bkt := range buckets {
+		// The balance at the end of the bucket is the balance after the last block created before its end
+		t := bkt[1] - 1
+		b, err := vs.blockAt(tx, BlockAt{Time: &t})
+		if err != nil {
+			return nil, err
+		}
+
+		p := BalanceSeriesPoint{
+			BucketStart: bkt[0],
+			BucketEnd:   bkt[1],
+			BlockSeq:    b.Seq(),
+			BlockTime:   b.Time(),
+		}
+
+		for _, addr := range addrs {
+			bal, err := balanceAtBlock(outs[addr], b.Seq(), b.Time())
+			if err != nil {
+				return nil, err
+			}
+
+			p.Balance, err = addBalance(p.Balance, bal)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+// addressesHistoryOutputs returns all outputs that were ever created for the addresses
+func (vs *Visor) addressesHistoryOutputs(tx *dbutil.Tx, addrs []cipher.Address) (map[cipher.Address][]historydb.UxOut, error) {
+	outs := make(map[cipher.Address][]historydb.UxOut, len(addrs))
+	for _, addr := range addrs {
+		if _, ok := outs[addr]; ok {
+			return nil, ErrDuplicateAddresses
+		}
+
+		uxs, err := vs.history.GetOutputsForAddress(tx, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		outs[addr] = uxs
+	}
+
+	return outs, nil
+}
+
+// blockAt returns the block selected by at
+func (vs *Visor) blockAt(tx *dbutil.Tx, at BlockAt) (*coin.SignedBlock, error) {
+	switch {
+	case at.Seq != nil && at.Time != nil:
+		return nil, ErrBlockAtSeqAndTime
+	case at.Seq != nil:
+		b, err := vs.blockchain.GetSignedBlockBySeq(tx, *at.Seq)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, ErrBlockAtSeqNotFound
+		}
+		return b, nil
+	case at.Time != nil:
+		return vs.blockAtTime(tx, *at.Time)
+	default:
+		return vs.blockchain.Head(tx)
+	}
+}
+
+// blockAtTime returns the last block created at or before a unix time.
+// Block times increase with the block seq, so the block is found with a binary search.
+func (vs *Visor) blockAtTime(tx *dbutil.Tx, t uint64) (*coin.SignedBlock, error) {
+	head, err := vs.blockchain.Head(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if head.Time() <= t {
+		return head, nil
+	}
+
+	getBlock := func(seq uint64) (*coin.SignedBlock, error) {
+		b, err := vs.blockchain.GetSignedBlockBySeq(tx, seq)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			return nil, fmt.Errorf("block %d not found", seq)
+		}
+		return b, nil
+	}
+
+	lo, err := getBlock(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if lo.Time() > t {
+		return nil, ErrBlockAtTimeNotFound
+	}
+
+	// lo is created at or before t, head is created after t
+	hiSeq := head.Seq()
+	for hiSeq-lo.Seq() > 1 {
+		b, err := getBlock(lo.Seq() + (hiSeq-lo.Seq())/2)
+		if err != nil {
+			return nil, err
+		}
+
+		if b.Time() <= t {
+			lo = b
+		} else {
+			hiSeq = b.Seq()
+		}
+	}
+
+	return lo, nil
+}
+
+// balanceAtBlock returns the balance of the outputs that were created and not spent
+// as of the block seq, with the coin hours at the block time
+func balanceAtBlock(outs []historydb.UxOut, seq, blockTime uint64) (wallet.Balance, error) {
+	var uxa coin.UxArray
+	for _, o := range outs {
+		if o.Out.Head.BkSeq > seq {
+			continue
+		}
+
+		if !o.SpentTxnID.Null() && o.SpentBlockSeq <= seq {
+			continue
+		}
+
+		uxa = append(uxa, o.Out)
+	}
+
+	coins, err := uxa.Coins()
+	if err != nil {
+		return wallet.Balance{}, fmt.Errorf("uxa.Coins failed: %v", err)
+	}
+
+	hours, err := uxa.CoinHours(blockTime)
+	if err != nil {
+		switch err {
+		case coin.ErrAddEarnedCoinHoursAdditionOverflow:
+			hours = 0
+		default:
+			return wallet.Balance{}, fmt.Errorf("uxa.CoinHours failed: %v", err)
+		}
+	}
+
+	return wallet.NewBalance(coins, hours), nil
+}
+
+// addBalance returns the sum of two balances
+func addBalance(a, b wallet.Balance) (wallet.Balance, error) {
+	coins, err := mathutil.AddUint64(a.Coins, b.Coins)
+	if err != nil {
+		return wallet.Balance{}, err
+	}
+
+	hours, err := mathutil.AddUint64(a.Hours, b.Hours)
+	if err != nil {
+		return wallet.Balance{}, err
+	}
+
+	return wallet.NewBalance(coins, hours), nil
+}
+
+func (b BalanceBucket) valid() bool {
+	switch b {
+	case BalanceBucketHour,
+		BalanceBucketDay,
+		BalanceBucketWeek,
+		BalanceBucketMonth,
+		BalanceBucketQuarter,
+		BalanceBucketYear:
+		return true
+	default:
+		return false
+	}
+}
+
+// start returns the unix time of the start of the bucket that contains a unix time, in UTC
+func (b BalanceBucket) start(t uint64) uint64 {
+	tm := time.Unix(int64(t), 0).UTC()
+	year, month, day := tm.Date()
+
+	var s time.Time
+	switch b {
+	case BalanceBucketHour:
+		s = tm.Truncate(time.Hour)
+	case BalanceBucketDay:
+		s = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	case BalanceBucketWeek:
+		// time.Weekday starts on Sunday
+		offset := (int(tm.Weekday()) + 6) % 7
+		s = time.Date(year, month, day-offset, 0, 0, 0, 0, time.UTC)
+	case BalanceBucketMonth:
+		s = time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	case BalanceBucketQuarter:
+		s = time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	case BalanceBucketYear:
+		s = time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		panic("invalid BalanceBucket")
+	}
+
+	return uint64(s.Unix())
+}
+
+// next returns the unix time of the start of the next bucket, t must be the start of a bucket
+func (b BalanceBucket) next(t uint64) uint64 {
+	tm := time.Unix(int64(t), 0).UTC()
+
+	var n time.Time
+	switch b {
+	case BalanceBucketHour:
+		n = tm.Add(time.Hour)
+	case BalanceBucketDay:
+		n = tm.AddDate(0, 0, 1)
+	case BalanceBucketWeek:
+		n = tm.AddDate(0, 0, 7)
+	case BalanceBucketMonth:
+		n = tm.AddDate(0, 1, 0)
+	case BalanceBucketQuarter:
+		n = tm.AddDate(0, 3, 0)
+	case BalanceBucketYear:
+		n = tm.AddDate(1, 0, 0)
+	default:
+		panic("invalid BalanceBucket")
+	}
+
+	return uint64(n.Unix())
+}
@@ -0,0 +1,306 @@
+package visor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestBalanceHistory(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	// 2020-01-01T00:00:00Z, a block is created every 30 minutes
+	t0 := uint64(1577836800)
+	blocks := make([]coin.SignedBlock, 5)
+	for i := range blocks {
+		blocks[i] = coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: uint64(i),
+					Time:  t0 + uint64(i)*1800,
+				},
+			},
+		}
+	}
+
+	addr1 := testutil.MakeAddress()
+	addr2 := testutil.MakeAddress()
+
+	uxOut := func(addr cipher.Address, seq, coins, hours uint64) historydb.UxOut {
+		return historydb.UxOut{
+			Out: coin.UxOut{
+				Head: coin.UxHead{
+					BkSeq: seq,
+					Time:  blocks[seq].Time(),
+				},
+				Body: coin.UxBody{
+					SrcTransaction: testutil.RandSHA256(t),
+					Address:        addr,
+					Coins:          coins,
+					Hours:          hours,
+				},
+			},
+		}
+	}
+
+	spent := uxOut(addr1, 0, 10e6, 100)
+	spent.SpentTxnID = testutil.RandSHA256(t)
+	spent.SpentBlockSeq = 2
+
+	bc := &MockBlockchainer{}
+	for i := range blocks {
+		bc.On("GetSignedBlockBySeq", matchDBTx, uint64(i)).Return(&blocks[i], nil)
+	}
+	bc.On("GetSignedBlockBySeq", matchDBTx, uint64(len(blocks))).Return(nil, nil)
+	bc.On("GetGenesisBlock", matchDBTx).Return(&blocks[0], nil)
+	bc.On("Head", matchDBTx).Return(&blocks[len(blocks)-1], nil)
+
+	his := &MockHistoryer{}
+	his.On("GetOutputsForAddress", matchDBTx, addr1).Return([]historydb.UxOut{
+		spent,
+		uxOut(addr1, 2, 4e6, 10),
+	}, nil)
+	his.On("GetOutputsForAddress", matchDBTx, addr2).Return([]historydb.UxOut{
+		uxOut(addr2, 3, 1e6, 0),
+	}, nil)
+
+	v := &Visor{
+		db:         db,
+		blockchain: bc,
+		history:    his,
+	}
+
+	addrs := []cipher.Address{addr1, addr2}
+	seq := func(s uint64) BlockAt {
+		return BlockAt{Seq: &s}
+	}
+	at := func(t uint64) BlockAt {
+		return BlockAt{Time: &t}
+	}
+
+	cases := []struct {
+		name      string
+		at        BlockAt
+		blockSeq  uint64
+		balance   wallet.Balance
+		addresses map[cipher.Address]wallet.Balance
+		err       error
+	}{
+		{
+			name:     "genesis block",
+			at:       seq(0),
+			blockSeq: 0,
+			balance:  wallet.Balance{Coins: 10e6, Hours: 100},
+			addresses: map[cipher.Address]wallet.Balance{
+				addr1: {Coins: 10e6, Hours: 100},
+				addr2: {},
+			},
+		},
+		{
+			name:     "hours accumulated",
+			at:       seq(1),
+			blockSeq: 1,
+			balance:  wallet.Balance{Coins: 10e6, Hours: 105},
+			addresses: map[cipher.Address]wallet.Balance{
+				addr1: {Coins: 10e6, Hours: 105},
+				addr2: {},
+			},
+		},
+		{
+			name:     "output spent and created in the block",
+			at:       seq(2),
+			blockSeq: 2,
+			balance:  wallet.Balance{Coins: 4e6, Hours: 10},
+			addresses: map[cipher.Address]wallet.Balance{
+				addr1: {Coins: 4e6, Hours: 10},
+				addr2: {},
+			},
+		},
+		{
+			name:     "head block",
+			at:       BlockAt{},
+			blockSeq: 4,
+			balance:  wallet.Balance{Coins: 5e6, Hours: 14},
+			addresses: map[cipher.Address]wallet.Balance{
+				addr1: {Coins: 4e6, Hours: 14},
+				addr2: {Coins: 1e6, Hours: 0},
+			},
+		},
+		{
+			name:     "time of a block",
+			at:       at(t0 + 5400),
+			blockSeq: 3,
+			balance:  wallet.Balance{Coins: 5e6, Hours: 12},
+			addresses: map[cipher.Address]wallet.Balance{
+				addr1: {Coins: 4e6, Hours: 12},
+				addr2: {Coins: 1e6, Hours: 0},
+			},
+		},
+		{
+			name:     "time between blocks",
+			at:       at(t0 + 1799),
+			blockSeq: 0,
+			balance:  wallet.Balance{Coins: 10e6, Hours: 100},
+			addresses: map[cipher.Address]wallet.Balance{
+				addr1: {Coins: 10e6, Hours: 100},
+				addr2: {},
+			},
+		},
+		{
+			name:     "time after the head block",
+			at:       at(t0 + 100000),
+			blockSeq: 4,
+			balance:  wallet.Balance{Coins: 5e6, Hours: 14},
+			addresses: map[cipher.Address]wallet.Balance{
+				addr1: {Coins: 4e6, Hours: 14},
+				addr2: {Coins: 1e6, Hours: 0},
+			},
+		},
+		{
+			name: "time before the genesis block",
+			at:   at(t0 - 1),
+			err:  ErrBlockAtTimeNotFound,
+		},
+		{
+			name: "seq above the head block",
+			at:   seq(5),
+			err:  ErrBlockAtSeqNotFound,
+		},
+		{
+			name: "seq and time",
+			at:   BlockAt{Seq: seq(1).Seq, Time: at(t0).Time},
+			err:  ErrBlockAtSeqAndTime,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := v.GetBalanceOfAddressesAt(addrs, tc.at)
+			if tc.err != nil {
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, &HistoricalBalance{
+				BlockSeq:  tc.blockSeq,
+				BlockTime: blocks[tc.blockSeq].Time(),
+				Balance:   tc.balance,
+				Addresses: tc.addresses,
+			}, b)
+		})
+	}
+
+	_, err := v.GetBalanceOfAddressesAt([]cipher.Address{addr1, addr1}, BlockAt{})
+	require.Equal(t, ErrDuplicateAddresses, err)
+
+	points, err := v.GetBalanceSeriesOfAddresses(addrs, BalanceSeriesQuery{
+		Bucket: BalanceBucketHour,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []BalanceSeriesPoint{
+		{
+			BucketStart: t0,
+			BucketEnd:   t0 + 3600,
+			BlockSeq:    1,
+			BlockTime:   t0 + 1800,
+			Balance:     wallet.Balance{Coins: 10e6, Hours: 105},
+		},
+		{
+			BucketStart: t0 + 3600,
+			BucketEnd:   t0 + 7200,
+			BlockSeq:    3,
+			BlockTime:   t0 + 5400,
+			Balance:     wallet.Balance{Coins: 5e6, Hours: 12},
+		},
+		{
+			BucketStart: t0 + 7200,
+			BucketEnd:   t0 + 10800,
+			BlockSeq:    4,
+			BlockTime:   t0 + 7200,
+			Balance:     wallet.Balance{Coins: 5e6, Hours: 14},
+		},
+	}, points)
+
+	// The series starts at the genesis block
+	points, err = v.GetBalanceSeriesOfAddresses(addrs, BalanceSeriesQuery{
+		Start:  t0 - 10*24*3600,
+		End:    t0 + 1,
+		Bucket: BalanceBucketYear,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []BalanceSeriesPoint{
+		{
+			BucketStart: t0,
+			BucketEnd:   uint64(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).Unix()),
+			BlockSeq:    4,
+			BlockTime:   t0 + 7200,
+			Balance:     wallet.Balance{Coins: 5e6, Hours: 14},
+		},
+	}, points)
+
+	_, err = v.GetBalanceSeriesOfAddresses(addrs, BalanceSeriesQuery{
+		Bucket: "minute",
+	})
+	require.Equal(t, ErrInvalidBalanceBucket, err)
+
+	_, err = v.GetBalanceSeriesOfAddresses(addrs, BalanceSeriesQuery{
+		Start:  t0 + 10,
+		End:    t0,
+		Bucket: BalanceBucketDay,
+	})
+	require.Equal(t, ErrBalanceSeriesEndBeforeStart, err)
+
+	_, err = v.GetBalanceSeriesOfAddresses(addrs, BalanceSeriesQuery{
+		End:    t0 + MaxBalanceSeriesPoints*3600,
+		Bucket: BalanceBucketHour,
+	})
+	require.Equal(t, ErrBalanceSeriesTooLong, err)
+}
+
+func TestBalanceBucket(t *testing.T) {
+	date := func(year int, month time.Month, day, hour, min int) uint64 {
+		return uint64(time.Date(year, month, day, hour, min, 0, 0, time.UTC).Unix())
+	}
+
+	// Wednesday
+	wed := date(2020, 5, 20, 13, 45)
+	// Sunday
+	sun := date(2020, 5, 24, 23, 59)
+
+	cases := []struct {
+		bucket BalanceBucket
+		t      uint64
+		start  uint64
+		next   uint64
+	}{
+		{BalanceBucketHour, wed, date(2020, 5, 20, 13, 0), date(2020, 5, 20, 14, 0)},
+		{BalanceBucketDay, wed, date(2020, 5, 20, 0, 0), date(2020, 5, 21, 0, 0)},
+		{BalanceBucketWeek, wed, date(2020, 5, 18, 0, 0), date(2020, 5, 25, 0, 0)},
+		{BalanceBucketWeek, sun, date(2020, 5, 18, 0, 0), date(2020, 5, 25, 0, 0)},
+		{BalanceBucketMonth, wed, date(2020, 5, 1, 0, 0), date(2020, 6, 1, 0, 0)},
+		{BalanceBucketMonth, date(2020, 12, 31, 23, 59), date(2020, 12, 1, 0, 0), date(2021, 1, 1, 0, 0)},
+		{BalanceBucketQuarter, wed, date(2020, 4, 1, 0, 0), date(2020, 7, 1, 0, 0)},
+		{BalanceBucketQuarter, date(2020, 12, 31, 23, 59), date(2020, 10, 1, 0, 0), date(2021, 1, 1, 0, 0)},
+		{BalanceBucketYear, wed, date(2020, 1, 1, 0, 0), date(2021, 1, 1, 0, 0)},
+	}
+
+	for _, tc := range cases {
+		t.Run(string(tc.bucket), func(t *testing.T) {
+			require.True(t, tc.bucket.valid())
+			start := tc.bucket.start(tc.t)
+			require.Equal(t, tc.start, start)
+			require.Equal(t, tc.next, tc.bucket.next(start))
+		})
+	}
+
+	require.False(t, BalanceBucket("minute").valid())
+}
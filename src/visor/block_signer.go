@@ -0,0 +1,169 @@
+package visor
+
+// This file contains the signers of the blocks created by a block publisher node
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/crypto"
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+// BlockSignerKeyFileVersion is the current version of the block signer key file format
+const BlockSignerKeyFileVersion = 1
+
+var (
+	// ErrBlockSignerKeyFileExists is returned when saving a block signer key file that already exists
+	ErrBlockSignerKeyFileExists = errors.New("block signer key file already exists")
+	// ErrBlockSignerPubKeyMismatch is returned when a block signer does not sign with the blockchain secret key
+	ErrBlockSignerPubKeyMismatch = errors.New("block signer public key does not match the blockchain public key")
+)
+
+//go:generate mockery -name BlockSigner -case underscore -inpkg -testonly
+
+// BlockSigner signs the blocks created by a block publisher node.
+// The secret key can be held in memory, in an encrypted key file, or by an external signer process.
+type BlockSigner interface {
+	// PubKey returns the public key of the secret key that signs the blocks
+	PubKey() (cipher.PubKey, error)
+	// SignHash signs the header hash of a block
+	SignHash(hash cipher.SHA256) (cipher.Sig, error)
+}
+
+// SecKeyBlockSigner is a BlockSigner that holds the secret key in memory
+type SecKeyBlockSigner struct {
+	pubkey cipher.PubKey
+	seckey cipher.SecKey
+}
+
+// NewSecKeyBlockSigner creates a SecKeyBlockSigner
+func NewSecKeyBlockSigner(seckey cipher.SecKey) (*SecKeyBlockSigner, error) {
+	pubkey, err := cipher.PubKeyFromSecKey(seckey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecKeyBlockSigner{
+		pubkey: pubkey,
+		seckey: seckey,
+	}, nil
+}
+
+// PubKey returns the public key of the secret key
+func (s *SecKeyBlockSigner) PubKey() (cipher.PubKey, error) {
+	return s.pubkey, nil
+}
+
+// SignHash signs the hash with the secret key
+func (s *SecKeyBlockSigner) SignHash(hash cipher.SHA256) (cipher.Sig, error) {
+	return cipher.SignHash(hash, s.seckey)
+}
+
+// blockSignerKeyFile is the JSON format of a block signer key file
+type blockSignerKeyFile struct {
+	Version    int    `json:"version"`
+	CryptoType string `json:"crypto_type"`
+	PubKey     string `json:"pubkey"`
+	// SecKey is the base64 encoded encrypted secret key
+	SecKey string `json:"seckey"`
+}
+
+// SaveBlockSignerKeyFile encrypts a secret key with the password and saves it to a new key file,
+// which can be loaded with LoadBlockSignerKeyFile
+func SaveBlockSignerKeyFile(filename string, seckey cipher.SecKey, password []byte, cryptoType crypto.CryptoType) error {
+	if len(password) == 0 {
+		return errors.New("missing password")
+	}
+
+	pubkey, err := cipher.PubKeyFromSecKey(seckey)
+	if err != nil {
+		return err
+	}
+
+	c, err := crypto.GetCrypto(cryptoType)
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := c.Encrypt(seckey[:], password)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(filename); err == nil {
+		return ErrBlockSignerKeyFileExists
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	data, err := json.MarshalIndent(blockSignerKeyFile{
+		Version:    BlockSignerKeyFileVersion,
+		CryptoType: string(cryptoType),
+		PubKey:     pubkey.Hex(),
+		SecKey:     base64.StdEncoding.EncodeToString(encrypted),
+	}, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return file.SaveBinary(filename, data, 0600)
+}
+
+// LoadBlockSignerKeyFile decrypts the secret key of a key file created by SaveBlockSignerKeyFile
+func LoadBlockSignerKeyFile(filename string, password []byte) (*SecKeyBlockSigner, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var kf blockSignerKeyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, fmt.Errorf("invalid block signer key file: %v", err)
+	}
+
+	if kf.Version != BlockSignerKeyFileVersion {
+		return nil, fmt.Errorf("unsupported block signer key file version %d", kf.Version)
+	}
+
+	pubkey, err := cipher.PubKeyFromHex(kf.PubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block signer key file pubkey: %v", err)
+	}
+
+	encrypted, err := base64.StdEncoding.DecodeString(kf.SecKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block signer key file seckey: %v", err)
+	}
+
+	c, err := crypto.GetCrypto(crypto.CryptoType(kf.CryptoType))
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := c.Decrypt(encrypted, password)
+	if err != nil {
+		return nil, err
+	}
+
+	seckey, err := cipher.NewSecKey(b)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := NewSecKeyBlockSigner(seckey)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.pubkey != pubkey {
+		return nil, errors.New("block signer key file pubkey does not match its seckey")
+	}
+
+	return s, nil
+}
@@ -0,0 +1,305 @@
+package visor
+
+// This file contains the protocol spoken with an external block signer process.
+//
+// The protocol is JSON-RPC 1.0, as implemented by net/rpc/jsonrpc, over a unix socket.
+// The protocol has no authentication, the socket is only accessible to the user running
+// the signer process. The signer process registers two methods:
+//
+//     BlockSigner.PubKey(BlockSignerPubKeyArgs) BlockSignerPubKeyReply
+//     BlockSigner.SignHash(BlockSignerSignHashArgs) BlockSignerSignHashReply
+//
+// Keys, hashes and signatures are hex encoded. A signer process that keeps its key in
+// a hardware security module implements the two methods and serves them on a socket,
+// ServeBlockSigner serves any BlockSigner with this protocol.
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+const (
+	// blockSignerServiceName is the name of the RPC service of a block signer process
+	blockSignerServiceName = "BlockSigner"
+	// DefaultBlockSignerTimeout is the default timeout of the calls to a block signer process
+	DefaultBlockSignerTimeout = time.Second * 10
+)
+
+var (
+	// ErrBlockSignerTimeout is returned when a block signer process does not reply in time
+	ErrBlockSignerTimeout = errors.New("block signer request timed out")
+	// ErrBlockSignerNetwork is returned when a block signer is served on another network than a unix socket
+	ErrBlockSignerNetwork = errors.New("block signer is only served on unix sockets")
+)
+
+// BlockSignerPubKeyArgs is the request of the BlockSigner.PubKey method
+type BlockSignerPubKeyArgs struct{}
+
+// BlockSignerPubKeyReply is the reply of the BlockSigner.PubKey method
+type BlockSignerPubKeyReply struct {
+	PubKey string `json:"pubkey"`
+}
+
+// BlockSignerSignHashArgs is the request of the BlockSigner.SignHash method
+type BlockSignerSignHashArgs struct {
+	Hash string `json:"hash"`
+}
+
+// BlockSignerSignHashReply is the reply of the BlockSigner.SignHash method
+type BlockSignerSignHashReply struct {
+	Sig string `json:"sig"`
+}
+
+// blockSignerService exposes a BlockSigner as RPC methods
+type blockSignerService struct {
+	signer BlockSigner
+}
+
+// PubKey returns the public key of the signer
+func (s *blockSignerService) PubKey(_ BlockSignerPubKeyArgs, reply *BlockSignerPubKeyReply) error {
+	pubkey, err := s.signer.PubKey()
+	if err != nil {
+		return err
+	}
+
+	reply.PubKey = pubkey.Hex()
+	return nil
+}
+
+// SignHash signs a block header hash
+func (s *blockSignerService) SignHash(args BlockSignerSignHashArgs, reply *BlockSignerSignHashReply) error {
+	hash, err := cipher.SHA256FromHex(args.Hash)
+	if err != nil {
+		return fmt.Errorf("invalid hash: %v", err)
+	}
+
+	sig, err := s.signer.SignHash(hash)
+	if err != nil {
+		return err
+	}
+
+	reply.Sig = sig.Hex()
+	return nil
+}
+
+// blockSignerListener is a unix socket listener that removes its socket file when it is closed
+type blockSignerListener struct {
+	*net.UnixListener
+	path string
+}
+
+// Close closes the listener and removes the socket file
+func (l *blockSignerListener) Close() error {
+	err := l.UnixListener.Close()
+	if rmErr := os.Remove(l.path); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// ListenBlockSigner listens on a unix socket at the path for ServeBlockSigner.
+// The socket is created in a new directory that only the user can access and is moved
+// to the path once its permissions are restricted, so other users can never connect to it.
+func ListenBlockSigner(path string) (net.Listener, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("block signer socket %q already exists", path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// ioutil.TempDir creates the directory with mode 0700
+	dir, err := ioutil.TempDir(filepath.Dir(path), ".block-signer")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "signer.sock")
+	l, err := net.ListenUnix("unix", &net.UnixAddr{
+		Name: tmp,
+		Net:  "unix",
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The socket is removed from its final path by blockSignerListener.Close
+	l.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, 0600); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return &blockSignerListener{
+		UnixListener: l,
+		path:         path,
+	}, nil
+}
+
+// ServeBlockSigner serves the BlockSigner to the connections accepted by the listener,
+// until the listener is closed. The listener must be a unix socket listener, as created by
+// ListenBlockSigner, since the protocol has no authentication.
+func ServeBlockSigner(l net.Listener, signer BlockSigner) error {
+	if l.Addr().Network() != "unix" {
+		return ErrBlockSignerNetwork
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName(blockSignerServiceName, &blockSignerService{
+		signer: signer,
+	}); err != nil {
+		return err
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// RemoteBlockSigner is a BlockSigner whose secret key is held by an external signer process.
+// The connection is reopened if the signer process is restarted.
+type RemoteBlockSigner struct {
+	address string
+	timeout time.Duration
+
+	sync.Mutex
+	client *rpc.Client
+}
+
+// DialBlockSigner connects to a block signer process listening on the unix socket at the address,
+// e.g. "/run/skycoin/signer.sock". If timeout is 0, DefaultBlockSignerTimeout is used.
+func DialBlockSigner(address string, timeout time.Duration) (*RemoteBlockSigner, error) {
+	if timeout == 0 {
+		timeout = DefaultBlockSignerTimeout
+	}
+
+	s := &RemoteBlockSigner{
+		address: address,
+		timeout: timeout,
+	}
+
+	if _, err := s.getClient(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *RemoteBlockSigner) getClient() (*rpc.Client, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	conn, err := net.DialTimeout("unix", s.address, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	s.client = jsonrpc.NewClient(conn)
+	return s.client, nil
+}
+
+// resetClient closes the connection if it is still the connection of the client
+func (s *RemoteBlockSigner) resetClient(c *rpc.Client) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.client != c {
+		return
+	}
+
+	if err := s.client.Close(); err != nil && err != rpc.ErrShutdown {
+		logger.WithError(err).Warning("Close block signer connection failed")
+	}
+	s.client = nil
+}
+
+// call calls a method of the signer process, reconnecting once if the connection was lost
+func (s *RemoteBlockSigner) call(method string, args, reply interface{}) error {
+	var err error
+	for i := 0; i < 2; i++ {
+		var c *rpc.Client
+		c, err = s.getClient()
+		if err != nil {
+			return err
+		}
+
+		call := c.Go(blockSignerServiceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+		select {
+		case <-call.Done:
+			err = call.Error
+		case <-time.After(s.timeout):
+			s.resetClient(c)
+			return ErrBlockSignerTimeout
+		}
+
+		// The connection was lost, e.g. the signer process was restarted
+		if err != rpc.ErrShutdown && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return err
+		}
+
+		s.resetClient(c)
+	}
+
+	return err
+}
+
+// PubKey returns the public key of the signer process
+func (s *RemoteBlockSigner) PubKey() (cipher.PubKey, error) {
+	var reply BlockSignerPubKeyReply
+	if err := s.call("PubKey", BlockSignerPubKeyArgs{}, &reply); err != nil {
+		return cipher.PubKey{}, err
+	}
+
+	return cipher.PubKeyFromHex(reply.PubKey)
+}
+
+// SignHash asks the signer process to sign the hash
+func (s *RemoteBlockSigner) SignHash(hash cipher.SHA256) (cipher.Sig, error) {
+	var reply BlockSignerSignHashReply
+	if err := s.call("SignHash", BlockSignerSignHashArgs{
+		Hash: hash.Hex(),
+	}, &reply); err != nil {
+		return cipher.Sig{}, err
+	}
+
+	return cipher.SigFromHex(reply.Sig)
+}
+
+// Close closes the connection to the signer process
+func (s *RemoteBlockSigner) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.client == nil {
+		return nil
+	}
+
+	err := s.client.Close()
+	s.client = nil
+	return err
+}
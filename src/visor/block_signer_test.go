@@ -0,0 +1,305 @@
+package visor
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/crypto"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+func TestBlockSignerKeyFile(t *testing.T) {
+	for _, ct := range crypto.TypesInsecure() {
+		t.Run(fmt.Sprintf("crypto=%v", ct), func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "block-signer")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			filename := filepath.Join(dir, "signer.key")
+			pubkey, seckey := cipher.GenerateKeyPair()
+
+			err = SaveBlockSignerKeyFile(filename, seckey, nil, ct)
+			require.EqualError(t, err, "missing password")
+
+			err = SaveBlockSignerKeyFile(filename, seckey, []byte("pwd"), ct)
+			require.NoError(t, err)
+
+			// An existing key file is not overwritten
+			err = SaveBlockSignerKeyFile(filename, seckey, []byte("pwd"), ct)
+			require.Equal(t, ErrBlockSignerKeyFileExists, err)
+
+			s, err := LoadBlockSignerKeyFile(filename, []byte("pwd"))
+			require.NoError(t, err)
+
+			pk, err := s.PubKey()
+			require.NoError(t, err)
+			require.Equal(t, pubkey, pk)
+
+			hash := testutil.RandSHA256(t)
+			sig, err := s.SignHash(hash)
+			require.NoError(t, err)
+			require.NoError(t, cipher.VerifyPubKeySignedHash(pubkey, sig, hash))
+
+			_, err = LoadBlockSignerKeyFile(filename, []byte("wrong"))
+			require.Error(t, err)
+
+			_, err = LoadBlockSignerKeyFile(filepath.Join(dir, "missing.key"), []byte("pwd"))
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+// unlockedDBBlockSigner is a BlockSigner that fails if the database write lock is held while signing
+type unlockedDBBlockSigner struct {
+	BlockSigner
+	db *dbutil.DB
+}
+
+func (s unlockedDBBlockSigner) SignHash(hash cipher.SHA256) (cipher.Sig, error) {
+	done := make(chan error, 1)
+	go func() {
+		done <- s.db.Update("unlockedDBBlockSigner", func(*dbutil.Tx) error {
+			return nil
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return cipher.Sig{}, err
+		}
+	case <-time.After(time.Second * 5):
+		return cipher.Sig{}, errors.New("the database write lock is held while signing")
+	}
+
+	return s.BlockSigner.SignHash(hash)
+}
+
+// serveBlockSigner serves the signer on a unix socket and returns its path
+func serveBlockSigner(t *testing.T, signer BlockSigner) (string, func()) {
+	dir, err := ioutil.TempDir("", "block-signer")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "signer.sock")
+	l, err := ListenBlockSigner(path)
+	require.NoError(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err := ServeBlockSigner(l, signer)
+		require.Error(t, err)
+	}()
+
+	return path, func() {
+		require.NoError(t, l.Close())
+		<-done
+		require.NoError(t, os.RemoveAll(dir))
+	}
+}
+
+func TestListenBlockSigner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "block-signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "signer.sock")
+	l, err := ListenBlockSigner(path)
+	require.NoError(t, err)
+
+	// The socket is only accessible to the user
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.ModeSocket, info.Mode()&os.ModeSocket)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// No temporary directory is left
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// An existing socket is not replaced
+	_, err = ListenBlockSigner(path)
+	require.Error(t, err)
+
+	// The socket is removed when the listener is closed
+	require.NoError(t, l.Close())
+	_, err = os.Stat(path)
+	require.True(t, os.IsNotExist(err))
+
+	// The signer is not served on tcp, the protocol has no authentication
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer tcp.Close()
+	require.Equal(t, ErrBlockSignerNetwork, ServeBlockSigner(tcp, &MockBlockSigner{}))
+}
+
+func TestRemoteBlockSigner(t *testing.T) {
+	pubkey, seckey := cipher.GenerateKeyPair()
+	signer, err := NewSecKeyBlockSigner(seckey)
+	require.NoError(t, err)
+
+	addr, stop := serveBlockSigner(t, signer)
+	defer stop()
+
+	s, err := DialBlockSigner(addr, 0)
+	require.NoError(t, err)
+	defer s.Close()
+
+	pk, err := s.PubKey()
+	require.NoError(t, err)
+	require.Equal(t, pubkey, pk)
+
+	hash := testutil.RandSHA256(t)
+	sig, err := s.SignHash(hash)
+	require.NoError(t, err)
+	require.NoError(t, cipher.VerifyPubKeySignedHash(pubkey, sig, hash))
+
+	// The signer reconnects after the connection is closed
+	require.NoError(t, s.Close())
+	sig, err = s.SignHash(hash)
+	require.NoError(t, err)
+	require.NoError(t, cipher.VerifyPubKeySignedHash(pubkey, sig, hash))
+
+	// Errors of the signer process are returned
+	m := &MockBlockSigner{}
+	m.On("PubKey").Return(cipher.PubKey{}, errors.New("device locked"))
+	m.On("SignHash", hash).Return(cipher.Sig{}, errors.New("device locked"))
+
+	addr2, stop2 := serveBlockSigner(t, m)
+	defer stop2()
+
+	s2, err := DialBlockSigner(addr2, 0)
+	require.NoError(t, err)
+	defer s2.Close()
+
+	_, err = s2.PubKey()
+	require.EqualError(t, err, "device locked")
+	_, err = s2.SignHash(hash)
+	require.EqualError(t, err, "device locked")
+
+	_, err = DialBlockSigner(filepath.Join(os.TempDir(), "missing-block-signer.sock"), 0)
+	require.Error(t, err)
+}
+
+func TestVisorSignBlock(t *testing.T) {
+	b, err := coin.NewGenesisBlock(genAddress, genCoins, genTime)
+	require.NoError(t, err)
+	hash := b.HashHeader()
+
+	_, otherSecret := cipher.GenerateKeyPair()
+
+	tt := []struct {
+		name    string
+		sig     cipher.Sig
+		signErr error
+		err     error
+	}{
+		{
+			name: "ok",
+			sig:  cipher.MustSignHash(hash, genSecret),
+		},
+		{
+			name:    "signer failed",
+			signErr: errors.New("device locked"),
+			err:     errors.New("device locked"),
+		},
+		{
+			name: "signed with another key",
+			sig:  cipher.MustSignHash(hash, otherSecret),
+			err:  ErrBlockSignerPubKeyMismatch,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			signer := &MockBlockSigner{}
+			signer.On("SignHash", hash).Return(tc.sig, tc.signErr)
+
+			cfg := NewConfig()
+			cfg.IsBlockPublisher = true
+			cfg.BlockchainPubkey = genPublic
+			cfg.BlockSigner = signer
+			v := &Visor{Config: cfg}
+
+			sb, err := v.signBlock(*b)
+			require.Equal(t, tc.err, err)
+			if tc.err != nil {
+				return
+			}
+
+			require.Equal(t, *b, sb.Block)
+			require.Equal(t, tc.sig, sb.Sig)
+			signer.AssertExpectations(t)
+		})
+	}
+
+	// Without a BlockSigner, the block is signed with BlockchainSeckey
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainPubkey = genPublic
+	cfg.BlockchainSeckey = genSecret
+	v := &Visor{Config: cfg}
+
+	sb, err := v.signBlock(*b)
+	require.NoError(t, err)
+	require.NoError(t, cipher.VerifyPubKeySignedHash(genPublic, sb.Sig, hash))
+}
+
+func TestConfigVerifyBlockSigner(t *testing.T) {
+	otherPublic, _ := cipher.GenerateKeyPair()
+
+	tt := []struct {
+		name      string
+		pubkey    cipher.PubKey
+		pubkeyErr error
+		err       string
+	}{
+		{
+			name:   "ok",
+			pubkey: genPublic,
+		},
+		{
+			name:      "signer failed",
+			pubkeyErr: errors.New("connection refused"),
+			err:       "Cannot run as block publisher: block signer failed: connection refused",
+		},
+		{
+			name:   "pubkey mismatch",
+			pubkey: otherPublic,
+			err:    "Cannot run as block publisher: block signer pubkey does not match pubkey",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			signer := &MockBlockSigner{}
+			signer.On("PubKey").Return(tc.pubkey, tc.pubkeyErr)
+
+			cfg := NewConfig()
+			cfg.Distribution = params.MainNetDistribution
+			cfg.IsBlockPublisher = true
+			cfg.BlockchainPubkey = genPublic
+			cfg.BlockSigner = signer
+
+			err := cfg.Verify()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
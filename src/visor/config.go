@@ -16,9 +16,13 @@ type Config struct {
 	// Public key of the blockchain
 	BlockchainPubkey cipher.PubKey
 
-	// Secret key of the blockchain (required if block publisher)
+	// Secret key of the blockchain (required if block publisher and BlockSigner is not set)
 	BlockchainSeckey cipher.SecKey
 
+	// Signer of the blocks of a block publisher, which holds the secret key of the blockchain.
+	// If not set, the blocks are signed with BlockchainSeckey.
+	BlockSigner BlockSigner
+
 	// Transaction verification parameters used for unconfirmed transactions
 	UnconfirmedVerifyTxn params.VerifyTxn
 	// Transaction verification parameters used when creating a block
@@ -70,7 +74,15 @@ func NewConfig() Config {
 // Verify verifies the configuration
 func (c Config) Verify() error {
 	if c.IsBlockPublisher {
-		if c.BlockchainPubkey != cipher.MustPubKeyFromSecKey(c.BlockchainSeckey) {
+		if c.BlockSigner != nil {
+			pubkey, err := c.BlockSigner.PubKey()
+			if err != nil {
+				return fmt.Errorf("Cannot run as block publisher: block signer failed: %v", err)
+			}
+			if c.BlockchainPubkey != pubkey {
+				return errors.New("Cannot run as block publisher: block signer pubkey does not match pubkey")
+			}
+		} else if c.BlockchainPubkey != cipher.MustPubKeyFromSecKey(c.BlockchainSeckey) {
 			return errors.New("Cannot run as block publisher: invalid seckey for pubkey")
 		}
 	}
@@ -4,6 +4,7 @@ Package dbutil provides boltdb utility methods
 package dbutil
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher/encoder"
 	"github.com/skycoin/skycoin/src/util/logging"
+	"github.com/skycoin/skycoin/src/util/metrics"
 )
 
 var (
@@ -26,6 +28,9 @@ var (
 	txUpdateTrace                = false
 	txDurationLog                = true
 	txDurationReportingThreshold = time.Millisecond * 100
+
+	txDuration = metrics.MustRegisterHistogram(metrics.NewHistogramVec("skycoin_db_transaction_duration_seconds",
+		"Duration of bolt database transactions, by transaction type and name", nil, "type", "name"))
 )
 
 // Tx wraps a Tx
@@ -97,6 +102,7 @@ func (db *DB) View(name string, f func(*Tx) error) error {
 	if db.DurationLog && delta > db.DurationReportingThreshold {
 		logger.Debugf("db.View [%s] elapsed %s", name, delta)
 	}
+	txDuration.WithLabelValues("view", name).Observe(delta.Seconds())
 
 	return err
 }
@@ -125,6 +131,7 @@ func (db *DB) Update(name string, f func(*Tx) error) error {
 	if db.DurationLog && delta > db.DurationReportingThreshold {
 		logger.Debugf("db.Update [%s] elapsed %s", name, delta)
 	}
+	txDuration.WithLabelValues("update", name).Observe(delta.Seconds())
 
 	return err
 }
@@ -297,6 +304,28 @@ func ForEach(tx *Tx, bktName []byte, f func(k, v []byte) error) error {
 	return bkt.ForEach(f)
 }
 
+// ForEachPrefix calls f for each key of the bucket that starts with prefix, in key order.
+// The iteration stops when f returns false or an error.
+func ForEachPrefix(tx *Tx, bktName, prefix []byte, f func(k, v []byte) (bool, error)) error {
+	bkt := tx.Bucket(bktName)
+	if bkt == nil {
+		return NewErrBucketNotExist(bktName)
+	}
+
+	c := bkt.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		ok, err := f(k, v)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+	}
+
+	return nil
+}
+
 // Delete deletes from a bucket
 func Delete(tx *Tx, bktName, key []byte) error {
 	bkt := tx.Bucket(bktName)
@@ -0,0 +1,162 @@
+package visor
+
+// This file contains the streaming export of the blockchain, for loading it into analytics databases
+
+import (
+	"errors"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+// ExportChunkSize is the number of blocks read in each database transaction of a chain export.
+// The blocks are passed to the export callback after the database transaction is closed,
+// so that a slow consumer does not hold the database.
+const ExportChunkSize = 100
+
+var (
+	// ErrExportStopped is returned when a chain export is interrupted
+	ErrExportStopped = errors.New("chain export stopped")
+	// ErrExportEndBeforeStart the end of a chain export is before its start
+	ErrExportEndBeforeStart = NewUserError(errors.New("end is before start"))
+	// ErrExportStartNotFound the start of a chain export is above the last exportable block
+	ErrExportStartNotFound = NewUserError(errors.New("start is above the head block"))
+)
+
+// ExportedBlock is a block of a chain export, with the outputs spent by its transactions
+type ExportedBlock struct {
+	Block coin.SignedBlock
+	// Inputs are the outputs spent by each transaction of the block, with their coin hours
+	// calculated at the time of the previous block, which is the time that the fee of the transaction
+	// was calculated against
+	Inputs [][]TransactionInput
+}
+
+// ExportChain calls f for each block from start to end, in order. If end is above the head block,
+// the export ends at the head block when the export started.
+// The export stops with ErrExportStopped when quit is closed.
+func (vs *Visor) ExportChain(start, end uint64, quit <-chan struct{}, f func(*ExportedBlock) error) error {
+	if end < start {
+		return ErrExportEndBeforeStart
+	}
+
+	var err error
+	end, err = vs.exportEnd(end)
+	if err != nil {
+		return err
+	}
+
+	if start > end {
+		return ErrExportStartNotFound
+	}
+
+	for seq := start; ; seq += ExportChunkSize {
+		chunkEnd := end
+		if end-seq >= ExportChunkSize {
+			chunkEnd = seq + ExportChunkSize - 1
+		}
+
+		blocks, err := vs.getExportedBlocks(seq, chunkEnd)
+		if err != nil {
+			return err
+		}
+
+		for i := range blocks {
+			select {
+			case <-quit:
+				return ErrExportStopped
+			default:
+			}
+
+			if err := f(&blocks[i]); err != nil {
+				return err
+			}
+		}
+
+		if chunkEnd == end {
+			return nil
+		}
+	}
+}
+
+// ExportDatabase exports the blockchain of a database that is not opened by a Visor,
+// such as a copy of the data.db of a node. See Visor.ExportChain.
+func ExportDatabase(db *dbutil.DB, start, end uint64, quit <-chan struct{}, f func(*ExportedBlock) error) error {
+	bc, err := NewBlockchain(db, BlockchainConfig{})
+	if err != nil {
+		return err
+	}
+
+	vs := &Visor{
+		db:         db,
+		blockchain: bc,
+		history:    historydb.New(),
+	}
+
+	return vs.ExportChain(start, end, quit, f)
+}
+
+// exportEnd returns the last block of an export that ends at end, which is the lowest of end,
+// the head block and the last block parsed by the history database
+func (vs *Visor) exportEnd(end uint64) (uint64, error) {
+	var headSeq, parsedSeq uint64
+	var ok bool
+	if err := vs.db.View("exportEnd", func(tx *dbutil.Tx) error {
+		var err error
+		headSeq, ok, err = vs.blockchain.HeadSeq(tx)
+		if err != nil || !ok {
+			return err
+		}
+
+		parsedSeq, ok, err = vs.history.ParsedBlockSeq(tx)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return 0, ErrExportStartNotFound
+	}
+
+	if headSeq < end {
+		end = headSeq
+	}
+	if parsedSeq < end {
+		end = parsedSeq
+	}
+
+	return end, nil
+}
+
+// getExportedBlocks returns the blocks from start to end with their inputs
+func (vs *Visor) getExportedBlocks(start, end uint64) ([]ExportedBlock, error) {
+	blocks := make([]ExportedBlock, 0, end-start+1)
+
+	if err := vs.db.View("getExportedBlocks", func(tx *dbutil.Tx) error {
+		for seq := start; seq <= end; seq++ {
+			b, err := vs.blockchain.GetSignedBlockBySeq(tx, seq)
+			if err != nil {
+				return err
+			}
+			if b == nil {
+				return errors.New("getExportedBlocks: block not found")
+			}
+
+			inputs, err := vs.getBlockInputs(tx, b)
+			if err != nil {
+				return err
+			}
+
+			blocks = append(blocks, ExportedBlock{
+				Block:  *b,
+				Inputs: inputs,
+			})
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
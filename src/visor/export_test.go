@@ -0,0 +1,160 @@
+package visor
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func TestExportChain(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	t0 := uint64(1577836800)
+	addr := testutil.MakeAddress()
+
+	// Each block spends the output created by the previous block
+	blocks := make([]coin.SignedBlock, 3)
+	uxOuts := make([]historydb.UxOut, len(blocks))
+	for i := range blocks {
+		txn := coin.Transaction{
+			Out: []coin.TransactionOutput{
+				{
+					Address: addr,
+					Coins:   100e6,
+					Hours:   1000 - uint64(i)*100,
+				},
+			},
+		}
+		if i > 0 {
+			txn.In = []cipher.SHA256{uxOuts[i-1].Hash()}
+		}
+
+		blocks[i] = coin.SignedBlock{
+			Block: coin.Block{
+				Head: coin.BlockHeader{
+					BkSeq: uint64(i),
+					Time:  t0 + uint64(i)*3600,
+				},
+				Body: coin.BlockBody{
+					Transactions: coin.Transactions{txn},
+				},
+			},
+		}
+
+		uxOuts[i] = historydb.UxOut{
+			Out: coin.UxOut{
+				Head: coin.UxHead{
+					Time:  blocks[i].Time(),
+					BkSeq: uint64(i),
+				},
+				Body: coin.UxBody{
+					SrcTransaction: txn.Hash(),
+					Address:        addr,
+					Coins:          100e6,
+					Hours:          txn.Out[0].Hours,
+				},
+			},
+		}
+	}
+
+	bc := &MockBlockchainer{}
+	bc.On("HeadSeq", matchDBTx).Return(uint64(2), true, nil)
+	for i := range blocks {
+		bc.On("GetSignedBlockBySeq", matchDBTx, uint64(i)).Return(&blocks[i], nil)
+	}
+
+	his := &MockHistoryer{}
+	his.On("ParsedBlockSeq", matchDBTx).Return(uint64(2), true, nil)
+	for i := 0; i < len(blocks)-1; i++ {
+		his.On("GetUxOuts", matchDBTx, []cipher.SHA256{uxOuts[i].Hash()}).Return([]historydb.UxOut{uxOuts[i]}, nil)
+	}
+
+	v := &Visor{
+		db:         db,
+		blockchain: bc,
+		history:    his,
+	}
+
+	// The hours of the inputs are calculated at the time of the previous block,
+	// which is the time that the outputs were created
+	expected := []ExportedBlock{
+		{
+			Block:  blocks[0],
+			Inputs: make([][]TransactionInput, 1),
+		},
+		{
+			Block: blocks[1],
+			Inputs: [][]TransactionInput{
+				{
+					{
+						UxOut:           uxOuts[0].Out,
+						CalculatedHours: 1000,
+					},
+				},
+			},
+		},
+		{
+			Block: blocks[2],
+			Inputs: [][]TransactionInput{
+				{
+					{
+						UxOut:           uxOuts[1].Out,
+						CalculatedHours: 900,
+					},
+				},
+			},
+		},
+	}
+
+	export := func(start, end uint64, quit chan struct{}) ([]ExportedBlock, error) {
+		var exported []ExportedBlock
+		err := v.ExportChain(start, end, quit, func(b *ExportedBlock) error {
+			exported = append(exported, *b)
+			return nil
+		})
+		return exported, err
+	}
+
+	exported, err := export(0, math.MaxUint64, nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, exported)
+
+	exported, err = export(1, 1, nil)
+	require.NoError(t, err)
+	require.Equal(t, expected[1:2], exported)
+
+	_, err = export(2, 1, nil)
+	require.Equal(t, ErrExportEndBeforeStart, err)
+
+	_, err = export(3, 10, nil)
+	require.Equal(t, ErrExportStartNotFound, err)
+
+	quit := make(chan struct{})
+	close(quit)
+	_, err = export(0, 2, quit)
+	require.Equal(t, ErrExportStopped, err)
+
+	fErr := errors.New("write failed")
+	err = v.ExportChain(0, 2, nil, func(b *ExportedBlock) error {
+		return fErr
+	})
+	require.Equal(t, fErr, err)
+
+	// The export ends at the last block parsed by the history database
+	his = &MockHistoryer{}
+	his.On("ParsedBlockSeq", matchDBTx).Return(uint64(1), true, nil)
+	his.On("GetUxOuts", matchDBTx, []cipher.SHA256{uxOuts[0].Hash()}).Return([]historydb.UxOut{uxOuts[0]}, nil)
+	v.history = his
+
+	exported, err = export(0, math.MaxUint64, nil)
+	require.NoError(t, err)
+	require.Equal(t, expected[:2], exported)
+}
@@ -0,0 +1,132 @@
+package historydb
+
+import (
+	"fmt"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+//go:generate skyencoder -unexported -struct AddressStats
+
+// AddressStatsBkt maps addresses to their stats
+var AddressStatsBkt = []byte("address_stats")
+
+// AddressStats are the stats of an address, maintained as blocks are parsed
+type AddressStats struct {
+	FirstBlockSeq uint64 // seq of the first block with a transaction of the address
+	LastBlockSeq  uint64 // seq of the last block with a transaction of the address
+	TxnCount      uint64 // number of transactions that created or spent outputs of the address
+	CoinsReceived uint64 // coins of the outputs created for the address
+	HoursReceived uint64 // hours of the outputs created for the address
+	CoinsSent     uint64 // coins of the spent outputs of the address
+	HoursSent     uint64 // hours of the spent outputs of the address, not including the hours they accumulated
+}
+
+// addReceived adds the coins and hours of an output created for the address
+func (s *AddressStats) addReceived(coins, hours uint64) error {
+	var err error
+	s.CoinsReceived, err = mathutil.AddUint64(s.CoinsReceived, coins)
+	if err != nil {
+		return fmt.Errorf("coins received overflow: %v", err)
+	}
+
+	s.HoursReceived, err = mathutil.AddUint64(s.HoursReceived, hours)
+	if err != nil {
+		return fmt.Errorf("hours received overflow: %v", err)
+	}
+
+	return nil
+}
+
+// addSent adds the coins and hours of a spent output of the address
+func (s *AddressStats) addSent(coins, hours uint64) error {
+	var err error
+	s.CoinsSent, err = mathutil.AddUint64(s.CoinsSent, coins)
+	if err != nil {
+		return fmt.Errorf("coins sent overflow: %v", err)
+	}
+
+	s.HoursSent, err = mathutil.AddUint64(s.HoursSent, hours)
+	if err != nil {
+		return fmt.Errorf("hours sent overflow: %v", err)
+	}
+
+	return nil
+}
+
+// addressStats bucket stores the stats of addresses, address as key and AddressStats as value
+type addressStats struct{}
+
+// get returns the stats of an address, nil if the address has no transactions
+func (as *addressStats) get(tx *dbutil.Tx, addr cipher.Address) (*AddressStats, error) {
+	var s AddressStats
+
+	v, err := dbutil.GetBucketValueNoCopy(tx, AddressStatsBkt, addr.Bytes())
+	if err != nil {
+		return nil, err
+	} else if v == nil {
+		return nil, nil
+	}
+
+	if err := decodeAddressStatsExact(v, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// put sets the stats of an address
+func (as *addressStats) put(tx *dbutil.Tx, addr cipher.Address, s AddressStats) error {
+	buf, err := encodeAddressStats(&s)
+	if err != nil {
+		return err
+	}
+
+	return dbutil.PutBucketValue(tx, AddressStatsBkt, addr.Bytes(), buf)
+}
+
+// addTxn adds a transaction of block seq to the stats of its addresses.
+// txnStats holds the coins and hours received and sent by each address in the transaction.
+func (as *addressStats) addTxn(tx *dbutil.Tx, seq uint64, txnStats map[cipher.Address]*AddressStats) error {
+	for addr, ts := range txnStats {
+		s, err := as.get(tx, addr)
+		if err != nil {
+			return err
+		}
+
+		if s == nil {
+			s = &AddressStats{
+				FirstBlockSeq: seq,
+			}
+		}
+
+		s.LastBlockSeq = seq
+		s.TxnCount++
+
+		if err := s.addReceived(ts.CoinsReceived, ts.HoursReceived); err != nil {
+			return fmt.Errorf("address %s: %v", addr, err)
+		}
+
+		if err := s.addSent(ts.CoinsSent, ts.HoursSent); err != nil {
+			return fmt.Errorf("address %s: %v", addr, err)
+		}
+
+		if err := as.put(tx, addr, *s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isEmpty checks if the address stats bucket is empty
+func (as *addressStats) isEmpty(tx *dbutil.Tx) (bool, error) {
+	return dbutil.IsEmpty(tx, AddressStatsBkt)
+}
+
+// reset resets the bucket
+func (as *addressStats) reset(tx *dbutil.Tx) error {
+	return dbutil.Reset(tx, AddressStatsBkt)
+}
@@ -21,6 +21,7 @@ func CreateBuckets(tx *dbutil.Tx) error {
 	return dbutil.CreateBuckets(tx, [][]byte{
 		AddressTxnsBkt,
 		AddressUxBkt,
+		AddressStatsBkt,
 		HistoryMetaBkt,
 		UxOutsBkt,
 		TransactionsBkt,
@@ -29,21 +30,23 @@ func CreateBuckets(tx *dbutil.Tx) error {
 
 // HistoryDB provides APIs for blockchain explorer
 type HistoryDB struct {
-	outputs  *uxOuts       // outputs bucket
-	txns     *transactions // transactions bucket
-	addrUx   *addressUx    // bucket which stores all UxOuts that address received
-	addrTxns *addressTxns  // address related transaction bucket
-	meta     *historyMeta  // stores history meta info
+	outputs   *uxOuts       // outputs bucket
+	txns      *transactions // transactions bucket
+	addrUx    *addressUx    // bucket which stores all UxOuts that address received
+	addrTxns  *addressTxns  // address related transaction bucket
+	addrStats *addressStats // stats of each address
+	meta      *historyMeta  // stores history meta info
 }
 
 // New create HistoryDB instance
 func New() *HistoryDB {
 	return &HistoryDB{
-		outputs:  &uxOuts{},
-		txns:     &transactions{},
-		addrUx:   &addressUx{},
-		addrTxns: &addressTxns{},
-		meta:     &historyMeta{},
+		outputs:   &uxOuts{},
+		txns:      &transactions{},
+		addrUx:    &addressUx{},
+		addrTxns:  &addressTxns{},
+		addrStats: &addressStats{},
+		meta:      &historyMeta{},
 	}
 }
 
@@ -69,6 +72,11 @@ func (hd *HistoryDB) NeedsReset(tx *dbutil.Tx) (bool, error) {
 		return false, err
 	}
 
+	addrStatsEmpty, err := hd.addrStats.isEmpty(tx)
+	if err != nil {
+		return false, err
+	}
+
 	txnsEmpty, err := hd.txns.isEmpty(tx)
 	if err != nil {
 		return false, err
@@ -79,7 +87,7 @@ func (hd *HistoryDB) NeedsReset(tx *dbutil.Tx) (bool, error) {
 		return false, err
 	}
 
-	if addrTxnsEmpty || addrUxEmpty || txnsEmpty || outputsEmpty {
+	if addrTxnsEmpty || addrUxEmpty || addrStatsEmpty || txnsEmpty || outputsEmpty {
 		return true, nil
 	}
 
@@ -97,6 +105,10 @@ func (hd *HistoryDB) Erase(tx *dbutil.Tx) error {
 		return err
 	}
 
+	if err := hd.addrStats.reset(tx); err != nil {
+		return err
+	}
+
 	if err := hd.outputs.reset(tx); err != nil {
 		return err
 	}
@@ -133,6 +145,17 @@ func (hd *HistoryDB) ParseBlock(tx *dbutil.Tx, b coin.Block) error {
 
 		spentTxnID := t.Hash()
 
+		// coins and hours received and sent by each address of the transaction
+		txnStats := make(map[cipher.Address]*AddressStats)
+		addrTxnStats := func(addr cipher.Address) *AddressStats {
+			s, ok := txnStats[addr]
+			if !ok {
+				s = &AddressStats{}
+				txnStats[addr] = s
+			}
+			return s
+		}
+
 		if err := hd.txns.put(tx, &txn); err != nil {
 			return err
 		}
@@ -158,6 +181,10 @@ func (hd *HistoryDB) ParseBlock(tx *dbutil.Tx, b coin.Block) error {
 			if err := hd.addrTxns.add(tx, o.Out.Body.Address, spentTxnID); err != nil {
 				return err
 			}
+
+			if err := addrTxnStats(o.Out.Body.Address).addSent(o.Out.Body.Coins, o.Out.Body.Hours); err != nil {
+				return err
+			}
 		}
 
 		// handle the tx out
@@ -176,6 +203,14 @@ func (hd *HistoryDB) ParseBlock(tx *dbutil.Tx, b coin.Block) error {
 			if err := hd.addrTxns.add(tx, ux.Body.Address, spentTxnID); err != nil {
 				return err
 			}
+
+			if err := addrTxnStats(ux.Body.Address).addReceived(ux.Body.Coins, ux.Body.Hours); err != nil {
+				return err
+			}
+		}
+
+		if err := hd.addrStats.addTxn(tx, b.Seq(), txnStats); err != nil {
+			return err
 		}
 	}
 
@@ -224,6 +259,11 @@ func (hd HistoryDB) AddressSeen(tx *dbutil.Tx, addr cipher.Address) (bool, error
 	return hd.addrTxns.contains(tx, addr)
 }
 
+// GetAddressStats returns the stats of an address, nil if the address has no transactions
+func (hd HistoryDB) GetAddressStats(tx *dbutil.Tx, addr cipher.Address) (*AddressStats, error) {
+	return hd.addrStats.get(tx, addr)
+}
+
 // ForEachTxn traverses the transactions bucket
 func (hd HistoryDB) ForEachTxn(tx *dbutil.Tx, f func(cipher.SHA256, *Transaction) error) error {
 	return hd.txns.forEach(tx, f)
@@ -351,6 +351,54 @@ func TestProcessBlock(t *testing.T) {
 	}
 
 	testEngine(t, testData, bc, hisDB, db)
+
+	addrA := cipher.MustDecodeBase58Address("2RxP5N26GhDqHrP6SK45ZzEMSmSpeUeWxsS")
+	addrB := cipher.MustDecodeBase58Address("222uMeCeL1PbkJGZJDgAz5sib2uisv9hYUm")
+	genHours := gb.Body.Transactions[0].Out[0].Hours
+
+	err = db.View("", func(tx *dbutil.Tx) error {
+		s, err := hisDB.GetAddressStats(tx, genAddress)
+		require.NoError(t, err)
+		require.Equal(t, &AddressStats{
+			FirstBlockSeq: 0,
+			LastBlockSeq:  1,
+			TxnCount:      2,
+			CoinsReceived: genCoins,
+			HoursReceived: genHours,
+			CoinsSent:     genCoins,
+			HoursSent:     genHours,
+		}, s)
+
+		s, err = hisDB.GetAddressStats(tx, addrA)
+		require.NoError(t, err)
+		require.Equal(t, &AddressStats{
+			FirstBlockSeq: 1,
+			LastBlockSeq:  2,
+			TxnCount:      2,
+			CoinsReceived: 20e6,
+			HoursReceived: 200,
+		}, s)
+
+		// The change of the second transaction is received by the address that sent it
+		s, err = hisDB.GetAddressStats(tx, addrB)
+		require.NoError(t, err)
+		require.Equal(t, &AddressStats{
+			FirstBlockSeq: 1,
+			LastBlockSeq:  2,
+			TxnCount:      2,
+			CoinsReceived: genCoins - 10e6 + 1000e6 - 20e6,
+			HoursReceived: 500,
+			CoinsSent:     genCoins - 10e6,
+			HoursSent:     400,
+		}, s)
+
+		s, err = hisDB.GetAddressStats(tx, testutil.MakeAddress())
+		require.NoError(t, err)
+		require.Nil(t, s)
+
+		return nil
+	})
+	require.NoError(t, err)
 }
 
 func testEngine(t *testing.T, tds []testData, bc *fakeBlockchain, hdb *HistoryDB, db *dbutil.DB) {
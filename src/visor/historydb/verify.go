@@ -73,6 +73,12 @@ func VerifyDBSkyencoderSafe(tx *dbutil.Tx, quit <-chan struct{}) error {
 		return err
 	}
 
+	// The address stats bucket does not exist in databases created before it was added,
+	// until the history is parsed again
+	if err := verifyAddressStatsSkyencoderSafe(tx, quit); err != nil {
+		return err
+	}
+
 	if err := dbutil.ForEach(tx, UxOutsBkt, func(_, v []byte) error {
 		select {
 		case <-quit:
@@ -127,3 +133,33 @@ func VerifyDBSkyencoderSafe(tx *dbutil.Tx, quit <-chan struct{}) error {
 
 	return nil
 }
+
+func verifyAddressStatsSkyencoderSafe(tx *dbutil.Tx, quit <-chan struct{}) error {
+	if !dbutil.Exists(tx, AddressStatsBkt) {
+		return nil
+	}
+
+	return dbutil.ForEach(tx, AddressStatsBkt, func(_, v []byte) error {
+		select {
+		case <-quit:
+			return ErrVerifyStopped
+		default:
+		}
+
+		var b1 AddressStats
+		if err := decodeAddressStatsExact(v, &b1); err != nil {
+			return err
+		}
+
+		var b2 AddressStats
+		if err := encoder.DeserializeRawExact(v, &b2); err != nil {
+			return err
+		}
+
+		if !reflect.DeepEqual(b1, b2) {
+			return errors.New("AddressStatsBkt address stats mismatch")
+		}
+
+		return nil
+	})
+}
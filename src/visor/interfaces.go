@@ -23,6 +23,7 @@ type Historyer interface {
 	GetOutputsForAddress(tx *dbutil.Tx, address cipher.Address) ([]historydb.UxOut, error)
 	GetTransactionHashesForAddresses(tx *dbutil.Tx, addresses []cipher.Address) ([]cipher.SHA256, error)
 	AddressSeen(tx *dbutil.Tx, address cipher.Address) (bool, error)
+	GetAddressStats(tx *dbutil.Tx, address cipher.Address) (*historydb.AddressStats, error)
 	NeedsReset(tx *dbutil.Tx) (bool, error)
 	Erase(tx *dbutil.Tx) error
 	ParsedBlockSeq(tx *dbutil.Tx) (uint64, bool, error)
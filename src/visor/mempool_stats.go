@@ -0,0 +1,239 @@
+package visor
+
+// This file contains the aggregated statistics of the unconfirmed transaction pool
+
+import (
+	"math"
+	"time"
+
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/transaction"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+)
+
+var (
+	// MempoolFeeRateBounds are the upper bounds of the fee rate buckets of MempoolStats, in coin hours per kB,
+	// the fee rate that blocks are filled by. The last bucket has no upper bound.
+	MempoolFeeRateBounds = []uint64{1, 10, 100, 1000, 10000, 100000, 1000000}
+	// MempoolAgeBounds are the upper bounds of the age buckets of MempoolStats. The last bucket has no upper bound.
+	MempoolAgeBounds = []time.Duration{time.Minute, 10 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour}
+)
+
+// Reasons that an unconfirmed transaction is invalid, see MempoolStats.InvalidReasons
+const (
+	// InvalidReasonMissingInput an input is spent or is created by another unconfirmed transaction
+	InvalidReasonMissingInput = "missing_input"
+	// InvalidReasonInsufficientFee the transaction burns no coin hours or less than the burn factor requires
+	InvalidReasonInsufficientFee = "insufficient_fee"
+	// InvalidReasonInsufficientHours the outputs have more coin hours than the inputs
+	InvalidReasonInsufficientHours = "insufficient_hours"
+	// InvalidReasonTooLarge the transaction is larger than the max transaction size
+	InvalidReasonTooLarge = "too_large"
+	// InvalidReasonLocked the transaction spends from a locked distribution address
+	InvalidReasonLocked = "locked"
+	// InvalidReasonDecimals an output has more decimal places than allowed
+	InvalidReasonDecimals = "invalid_decimals"
+	// InvalidReasonHardConstraint the transaction violates another hard constraint, such as an invalid signature
+	InvalidReasonHardConstraint = "hard_constraint"
+	// InvalidReasonSoftConstraint the transaction violates another soft constraint
+	InvalidReasonSoftConstraint = "soft_constraint"
+	// InvalidReasonRecheckedValid the transaction is valid against the current blockchain,
+	// and will be marked valid when the pool is next refreshed
+	InvalidReasonRecheckedValid = "rechecked_valid"
+)
+
+// MempoolStats are the aggregated statistics of the unconfirmed transaction pool
+type MempoolStats struct {
+	// Count is the number of unconfirmed transactions
+	Count uint64
+	// Bytes is the total size of the unconfirmed transactions
+	Bytes uint64
+	// ValidCount is the number of valid unconfirmed transactions
+	ValidCount uint64
+	// ValidBytes is the total size of the valid unconfirmed transactions
+	ValidBytes uint64
+	// FeeRateCounts are the number of valid transactions in each bucket of MempoolFeeRateBounds.
+	// Transactions whose fee cannot be calculated, because an input was spent since the pool was refreshed,
+	// are not counted.
+	FeeRateCounts []uint64
+	// FeeRateBytes are the total size of the transactions in each bucket of MempoolFeeRateBounds
+	FeeRateBytes []uint64
+	// AgeCounts are the number of transactions in each bucket of MempoolAgeBounds, by the time they were last received
+	AgeCounts []uint64
+	// InvalidReasons are the number of invalid transactions by reason, see the InvalidReason constants
+	InvalidReasons map[string]uint64
+	// Oldest is the transaction that was received the longest ago, nil if the pool is empty
+	Oldest *UnconfirmedTransaction
+	// BlocksToClear is the number of blocks needed to confirm the valid transactions, if no other transactions are received.
+	// The blocks are filled by fee rate up to MaxBlockTransactionsSize, as they are created.
+	BlocksToClear uint64
+	// MaxBlockTransactionsSize is the max size of the transactions of a block
+	MaxBlockTransactionsSize uint32
+}
+
+// GetMempoolStats returns the aggregated statistics of the unconfirmed transaction pool.
+// Transaction ages are calculated at now.
+func (vs *Visor) GetMempoolStats(now time.Time) (*MempoolStats, error) {
+	stats := &MempoolStats{
+		FeeRateCounts:            make([]uint64, len(MempoolFeeRateBounds)+1),
+		FeeRateBytes:             make([]uint64, len(MempoolFeeRateBounds)+1),
+		AgeCounts:                make([]uint64, len(MempoolAgeBounds)+1),
+		InvalidReasons:           make(map[string]uint64),
+		MaxBlockTransactionsSize: vs.Config.MaxBlockTransactionsSize,
+	}
+
+	if err := vs.db.View("GetMempoolStats", func(tx *dbutil.Tx) error {
+		txns, err := vs.unconfirmed.GetFiltered(tx, All)
+		if err != nil {
+			return err
+		}
+
+		headTime, err := vs.blockchain.Time(tx)
+		if err != nil {
+			return err
+		}
+		feeCalc := vs.blockchain.TransactionFee(tx, headTime)
+
+		var valid coin.Transactions
+		for i := range txns {
+			txn := &txns[i]
+
+			size, err := txn.Transaction.Size()
+			if err != nil {
+				return err
+			}
+
+			stats.Count++
+			stats.Bytes += uint64(size)
+
+			stats.AgeCounts[ageBucket(now.Sub(time.Unix(0, txn.Received)))]++
+
+			if stats.Oldest == nil || txn.Received < stats.Oldest.Received {
+				stats.Oldest = txn
+			}
+
+			if !IsValid(*txn) {
+				reason, err := vs.invalidReason(tx, txn.Transaction)
+				if err != nil {
+					return err
+				}
+				stats.InvalidReasons[reason]++
+				continue
+			}
+
+			stats.ValidCount++
+			stats.ValidBytes += uint64(size)
+			valid = append(valid, txn.Transaction)
+
+			f, err := feeCalc(&txn.Transaction)
+			if err != nil {
+				continue
+			}
+
+			b := feeRateBucket(feeRate(f, size))
+			stats.FeeRateCounts[b]++
+			stats.FeeRateBytes[b] += uint64(size)
+		}
+
+		stats.BlocksToClear, err = vs.blocksToClear(valid, feeCalc)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// invalidReason returns the reason that an unconfirmed transaction is invalid, by checking it against the blockchain
+func (vs *Visor) invalidReason(tx *dbutil.Tx, txn coin.Transaction) (string, error) {
+	_, _, err := vs.blockchain.VerifySingleTxnSoftHardConstraints(tx, txn, vs.Config.Distribution, vs.Config.UnconfirmedVerifyTxn, transaction.TxnSigned)
+	switch e := err.(type) {
+	case nil:
+		return InvalidReasonRecheckedValid, nil
+	case transaction.ErrTxnViolatesHardConstraint:
+		switch e.Err.(type) {
+		case blockdb.ErrUnspentNotExist:
+			return InvalidReasonMissingInput, nil
+		default:
+			return InvalidReasonHardConstraint, nil
+		}
+	case transaction.ErrTxnViolatesSoftConstraint:
+		switch e.Err {
+		case fee.ErrTxnNoFee, fee.ErrTxnInsufficientFee:
+			return InvalidReasonInsufficientFee, nil
+		case fee.ErrTxnInsufficientCoinHours:
+			return InvalidReasonInsufficientHours, nil
+		case transaction.ErrTxnExceedsMaxBlockSize:
+			return InvalidReasonTooLarge, nil
+		case transaction.ErrTxnIsLocked:
+			return InvalidReasonLocked, nil
+		case params.ErrInvalidDecimals:
+			return InvalidReasonDecimals, nil
+		default:
+			return InvalidReasonSoftConstraint, nil
+		}
+	default:
+		return "", err
+	}
+}
+
+// blocksToClear returns the number of blocks needed to confirm txns, filling each block
+// by fee rate up to MaxBlockTransactionsSize and coin.MaxBlockTransactions as CreateBlock does
+func (vs *Visor) blocksToClear(txns coin.Transactions, feeCalc coin.FeeCalculator) (uint64, error) {
+	txns, err := coin.SortTransactions(txns, feeCalc)
+	if err != nil {
+		return 0, err
+	}
+
+	var blocks uint64
+	for len(txns) > 0 {
+		block, err := txns.TruncateBytesTo(vs.Config.MaxBlockTransactionsSize)
+		if err != nil {
+			return 0, err
+		}
+
+		n := len(block)
+		if n > coin.MaxBlockTransactions {
+			n = coin.MaxBlockTransactions
+		}
+
+		// A transaction larger than a block can never be confirmed
+		if n == 0 {
+			break
+		}
+
+		txns = txns[n:]
+		blocks++
+	}
+
+	return blocks, nil
+}
+
+// feeRate returns the fee per kB of a transaction, as calculated by coin.SortTransactions
+func feeRate(f uint64, size uint32) uint64 {
+	if f > math.MaxUint64/1024 {
+		return math.MaxUint64 / uint64(size)
+	}
+	return f * 1024 / uint64(size)
+}
+
+func feeRateBucket(rate uint64) int {
+	for i, b := range MempoolFeeRateBounds {
+		if rate < b {
+			return i
+		}
+	}
+	return len(MempoolFeeRateBounds)
+}
+
+func ageBucket(age time.Duration) int {
+	for i, b := range MempoolAgeBounds {
+		if age < b {
+			return i
+		}
+	}
+	return len(MempoolAgeBounds)
+}
@@ -0,0 +1,209 @@
+package visor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/params"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/util/fee"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func TestGetMempoolStats(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	bc, err := NewBlockchain(db, BlockchainConfig{
+		Pubkey: genPublic,
+	})
+	require.NoError(t, err)
+
+	unconfirmed, err := NewUnconfirmedTransactionPool(db)
+	require.NoError(t, err)
+
+	cfg := NewConfig()
+	cfg.IsBlockPublisher = true
+	cfg.BlockchainSeckey = genSecret
+	cfg.BlockchainPubkey = genPublic
+	cfg.GenesisAddress = genAddress
+
+	v := &Visor{
+		Config:      cfg,
+		unconfirmed: unconfirmed,
+		blockchain:  bc,
+		db:          db,
+		history:     historydb.New(),
+	}
+
+	// Empty pool
+	now := time.Now()
+	gb := addGenesisBlockToVisor(t, v)
+	stats, err := v.GetMempoolStats(now)
+	require.NoError(t, err)
+	require.Equal(t, &MempoolStats{
+		FeeRateCounts:            make([]uint64, len(MempoolFeeRateBounds)+1),
+		FeeRateBytes:             make([]uint64, len(MempoolFeeRateBounds)+1),
+		AgeCounts:                make([]uint64, len(MempoolAgeBounds)+1),
+		InvalidReasons:           map[string]uint64{},
+		MaxBlockTransactionsSize: cfg.MaxBlockTransactionsSize,
+	}, stats)
+
+	uxs := coin.CreateUnspents(gb.Head, gb.Body.Transactions[0])
+	toAddr := testutil.MakeAddress()
+	var coins uint64 = 10e6
+
+	validTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, genAddress, coins)
+	_, softErr, err := v.InjectForeignTransaction(validTxn)
+	require.NoError(t, err)
+	require.Nil(t, softErr)
+
+	// Invalid decimal places is a soft constraint, the transaction is injected as invalid
+	invalidCoins := coins + (params.UserVerifyTxn.MaxDropletDivisor() / 10)
+	decimalsTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, toAddr, invalidCoins)
+	_, softErr, err = v.InjectForeignTransaction(decimalsTxn)
+	require.NoError(t, err)
+	require.NotNil(t, softErr)
+
+	// A transaction that exceeded the max transaction size when it was injected, but is valid now
+	v.Config.UnconfirmedVerifyTxn.MaxTransactionSize = 1
+	recheckedTxn := makeSpendTxn(t, uxs, []cipher.SecKey{genSecret}, toAddr, coins)
+	_, softErr, err = v.InjectForeignTransaction(recheckedTxn)
+	require.NoError(t, err)
+	require.NotNil(t, softErr)
+	v.Config.UnconfirmedVerifyTxn.MaxTransactionSize = cfg.UnconfirmedVerifyTxn.MaxTransactionSize
+
+	// A transaction that spends an output that does not exist, received a day ago
+	missingTxn := makeSpendTxn(t, coin.UxArray{{
+		Body: coin.UxBody{
+			SrcTransaction: testutil.RandSHA256(t),
+			Address:        genAddress,
+			Coins:          coins,
+			Hours:          1000,
+		},
+	}}, []cipher.SecKey{genSecret}, toAddr, coins)
+	missingUtxn := NewUnconfirmedTransaction(missingTxn)
+	missingUtxn.Received = now.Add(-30 * time.Hour).UnixNano()
+	err = db.Update("", func(tx *dbutil.Tx) error {
+		return unconfirmed.txns.put(tx, &missingUtxn)
+	})
+	require.NoError(t, err)
+
+	size := func(txn coin.Transaction) uint64 {
+		s, err := txn.Size()
+		require.NoError(t, err)
+		return uint64(s)
+	}
+
+	f, err := fee.TransactionFee(&validTxn, gb.Time(), uxs)
+	require.NoError(t, err)
+	validSize := size(validTxn)
+	feeRateCounts := make([]uint64, len(MempoolFeeRateBounds)+1)
+	feeRateBytes := make([]uint64, len(MempoolFeeRateBounds)+1)
+	feeRateCounts[feeRateBucket(feeRate(f, uint32(validSize)))] = 1
+	feeRateBytes[feeRateBucket(feeRate(f, uint32(validSize)))] = validSize
+
+	stats, err = v.GetMempoolStats(now.Add(2 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, &MempoolStats{
+		Count:         4,
+		Bytes:         validSize + size(decimalsTxn) + size(recheckedTxn) + size(missingTxn),
+		ValidCount:    1,
+		ValidBytes:    validSize,
+		FeeRateCounts: feeRateCounts,
+		FeeRateBytes:  feeRateBytes,
+		AgeCounts:     []uint64{0, 0, 0, 3, 0, 1},
+		InvalidReasons: map[string]uint64{
+			InvalidReasonDecimals:       1,
+			InvalidReasonRecheckedValid: 1,
+			InvalidReasonMissingInput:   1,
+		},
+		Oldest:                   &missingUtxn,
+		BlocksToClear:            1,
+		MaxBlockTransactionsSize: cfg.MaxBlockTransactionsSize,
+	}, stats)
+}
+
+func TestBlocksToClear(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	var txns coin.Transactions
+	fees := make(map[cipher.SHA256]uint64)
+	for i := 0; i < 5; i++ {
+		txn := coin.Transaction{
+			In: []cipher.SHA256{testutil.RandSHA256(t)},
+			Out: []coin.TransactionOutput{
+				{
+					Address: testutil.MakeAddress(),
+					Coins:   1e6,
+				},
+			},
+		}
+		require.NoError(t, txn.UpdateHeader())
+		txns = append(txns, txn)
+		fees[txn.Hash()] = uint64(i + 1)
+	}
+	feeCalc := func(txn *coin.Transaction) (uint64, error) {
+		return fees[txn.Hash()], nil
+	}
+
+	txnSize, err := txns[0].Size()
+	require.NoError(t, err)
+
+	cases := []struct {
+		name          string
+		maxBlockSize  uint32
+		blocksToClear uint64
+	}{
+		{
+			name:          "one block",
+			maxBlockSize:  txnSize * 5,
+			blocksToClear: 1,
+		},
+		{
+			name:          "two transactions per block",
+			maxBlockSize:  txnSize*2 + 1,
+			blocksToClear: 3,
+		},
+		{
+			name:          "transactions larger than a block",
+			maxBlockSize:  txnSize - 1,
+			blocksToClear: 0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &Visor{
+				Config: Config{
+					MaxBlockTransactionsSize: tc.maxBlockSize,
+				},
+				db: db,
+			}
+
+			n, err := v.blocksToClear(txns, feeCalc)
+			require.NoError(t, err)
+			require.Equal(t, tc.blocksToClear, n)
+		})
+	}
+}
+
+func TestMempoolStatsBuckets(t *testing.T) {
+	require.Equal(t, 0, feeRateBucket(0))
+	require.Equal(t, 1, feeRateBucket(1))
+	require.Equal(t, 1, feeRateBucket(9))
+	require.Equal(t, len(MempoolFeeRateBounds), feeRateBucket(1e9))
+
+	require.Equal(t, uint64(1024), feeRate(100, 100))
+	require.Equal(t, uint64(0), feeRate(0, 100))
+
+	require.Equal(t, 0, ageBucket(time.Second))
+	require.Equal(t, 1, ageBucket(time.Minute))
+	require.Equal(t, len(MempoolAgeBounds), ageBucket(48*time.Hour))
+}
@@ -67,6 +67,29 @@ func (_m *MockHistoryer) ForEachTxn(tx *dbutil.Tx, f func(cipher.SHA256, *histor
 	return r0
 }
 
+// GetAddressStats provides a mock function with given fields: tx, address
+func (_m *MockHistoryer) GetAddressStats(tx *dbutil.Tx, address cipher.Address) (*historydb.AddressStats, error) {
+	ret := _m.Called(tx, address)
+
+	var r0 *historydb.AddressStats
+	if rf, ok := ret.Get(0).(func(*dbutil.Tx, cipher.Address) *historydb.AddressStats); ok {
+		r0 = rf(tx, address)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*historydb.AddressStats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(*dbutil.Tx, cipher.Address) error); ok {
+		r1 = rf(tx, address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetOutputsForAddress provides a mock function with given fields: tx, address
 func (_m *MockHistoryer) GetOutputsForAddress(tx *dbutil.Tx, address cipher.Address) ([]historydb.UxOut, error) {
 	ret := _m.Called(tx, address)
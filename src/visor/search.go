@@ -0,0 +1,263 @@
+package visor
+
+// This file contains the Visor methods for searching the blockchain by a user query
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+const (
+	// MinSearchPrefixLen is the minimum number of hex characters of a hash prefix search
+	MinSearchPrefixLen = 6
+	// MaxSearchResults is the maximum number of results of a search
+	MaxSearchResults = 20
+)
+
+// SearchResultType is the type of a search result
+type SearchResultType string
+
+const (
+	// SearchResultBlock the result is a block, matched by hash or seq
+	SearchResultBlock SearchResultType = "block"
+	// SearchResultTransaction the result is a confirmed or unconfirmed transaction, matched by ID
+	SearchResultTransaction SearchResultType = "transaction"
+	// SearchResultUxOut the result is an output, matched by ID
+	SearchResultUxOut SearchResultType = "uxout"
+	// SearchResultAddress the result is an address
+	SearchResultAddress SearchResultType = "address"
+)
+
+// SearchResult is a block, transaction, output or address found by Search
+type SearchResult struct {
+	Type SearchResultType
+	// Hash is the block hash, the transaction ID or the output ID
+	Hash    cipher.SHA256
+	Address cipher.Address
+	// BlockSeq is the seq of the block, of the block that executed the transaction
+	// or of the block that created the output. It is not set for unconfirmed transactions and addresses.
+	BlockSeq uint64
+	// Unconfirmed is true for a transaction of the unconfirmed pool
+	Unconfirmed bool
+	// Spent is true for an output that was spent
+	Spent bool
+	// Exact is false for the results matched by a hash prefix
+	Exact bool
+}
+
+// Search classifies a user query as a block seq, a block hash, a transaction ID, an output ID or an address,
+// and returns the blocks, transactions, outputs and addresses that it refers to.
+// A query of at least MinSearchPrefixLen hex characters that is shorter than a hash matches the hashes
+// that start with it. At most MaxSearchResults results are returned, the second return value is true if
+// more results were found.
+func (vs *Visor) Search(q string) ([]SearchResult, bool, error) {
+	q = strings.TrimSpace(q)
+
+	var results []SearchResult
+	if err := vs.db.View("Search", func(tx *dbutil.Tx) error {
+		var err error
+		results, err = vs.search(tx, q)
+		return err
+	}); err != nil {
+		return nil, false, err
+	}
+
+	if len(results) > MaxSearchResults {
+		return results[:MaxSearchResults], true, nil
+	}
+
+	return results, false, nil
+}
+
+// search returns up to MaxSearchResults+1 results of the query, so that the caller can tell if results were left out
+func (vs *Visor) search(tx *dbutil.Tx, q string) ([]SearchResult, error) {
+	var results []SearchResult
+
+	if seq, err := strconv.ParseUint(q, 10, 64); err == nil {
+		b, err := vs.blockchain.GetSignedBlockBySeq(tx, seq)
+		if err != nil {
+			return nil, err
+		}
+		if b != nil {
+			results = append(results, SearchResult{
+				Type:     SearchResultBlock,
+				Hash:     b.HashHeader(),
+				BlockSeq: b.Seq(),
+				Exact:    true,
+			})
+		}
+	}
+
+	if addr, err := cipher.DecodeBase58Address(q); err == nil {
+		results = append(results, SearchResult{
+			Type:    SearchResultAddress,
+			Address: addr,
+			Exact:   true,
+		})
+	}
+
+	prefix := strings.ToLower(q)
+	if len(prefix) < MinSearchPrefixLen || len(prefix) > len(cipher.SHA256{})*2 || !isHex(prefix) {
+		return results, nil
+	}
+
+	_, err := cipher.SHA256FromHex(prefix)
+	exact := err == nil
+
+	blockHashes, err := hashesWithHexPrefix(tx, blockdb.BlocksBkt, prefix, MaxSearchResults+1-len(results))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range blockHashes {
+		b, err := vs.blockchain.GetSignedBlockByHash(tx, h)
+		if err != nil {
+			return nil, err
+		}
+		if b == nil {
+			continue
+		}
+
+		results = append(results, SearchResult{
+			Type:     SearchResultBlock,
+			Hash:     h,
+			BlockSeq: b.Seq(),
+			Exact:    exact,
+		})
+	}
+
+	unconfirmedTxnIDs, err := unconfirmedTxnIDsWithPrefix(tx, prefix, MaxSearchResults+1-len(results))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range unconfirmedTxnIDs {
+		results = append(results, SearchResult{
+			Type:        SearchResultTransaction,
+			Hash:        h,
+			Unconfirmed: true,
+			Exact:       exact,
+		})
+	}
+
+	txnIDs, err := hashesWithHexPrefix(tx, historydb.TransactionsBkt, prefix, MaxSearchResults+1-len(results))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, h := range txnIDs {
+		txn, err := vs.history.GetTransaction(tx, h)
+		if err != nil {
+			return nil, err
+		}
+		if txn == nil {
+			continue
+		}
+
+		results = append(results, SearchResult{
+			Type:     SearchResultTransaction,
+			Hash:     h,
+			BlockSeq: txn.BlockSeq,
+			Exact:    exact,
+		})
+	}
+
+	uxIDs, err := hashesWithHexPrefix(tx, historydb.UxOutsBkt, prefix, MaxSearchResults+1-len(results))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(uxIDs) != 0 {
+		uxOuts, err := vs.history.GetUxOuts(tx, uxIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, ux := range uxOuts {
+			results = append(results, SearchResult{
+				Type:     SearchResultUxOut,
+				Hash:     uxIDs[i],
+				BlockSeq: ux.Out.Head.BkSeq,
+				Spent:    !ux.SpentTxnID.Null(),
+				Exact:    exact,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// hashesWithHexPrefix returns up to max keys of a bucket keyed by hashes, whose hex encoding starts with prefix
+func hashesWithHexPrefix(tx *dbutil.Tx, bktName []byte, prefix string, max int) ([]cipher.SHA256, error) {
+	if max <= 0 {
+		return nil, nil
+	}
+
+	// An odd number of hex characters is matched by seeking to the whole bytes
+	// and comparing the last character
+	b, err := hex.DecodeString(prefix[:len(prefix)&^1])
+	if err != nil {
+		return nil, err
+	}
+
+	var hashes []cipher.SHA256
+	if err := dbutil.ForEachPrefix(tx, bktName, b, func(k, _ []byte) (bool, error) {
+		switch p := hex.EncodeToString(k); {
+		case p < prefix:
+			return true, nil
+		case !strings.HasPrefix(p, prefix):
+			// The keys are sorted, no later key can match
+			return false, nil
+		}
+
+		h, err := cipher.SHA256FromBytes(k)
+		if err != nil {
+			return false, err
+		}
+
+		hashes = append(hashes, h)
+		return len(hashes) < max, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// unconfirmedTxnIDsWithPrefix returns up to max IDs of unconfirmed transactions whose hex encoding starts with prefix.
+// The unconfirmed pool is keyed by the hex encoding of the transaction IDs.
+func unconfirmedTxnIDsWithPrefix(tx *dbutil.Tx, prefix string, max int) ([]cipher.SHA256, error) {
+	if max <= 0 {
+		return nil, nil
+	}
+
+	var hashes []cipher.SHA256
+	if err := dbutil.ForEachPrefix(tx, UnconfirmedTxnsBkt, []byte(prefix), func(k, _ []byte) (bool, error) {
+		h, err := cipher.SHA256FromHex(string(k))
+		if err != nil {
+			return false, err
+		}
+
+		hashes = append(hashes, h)
+		return len(hashes) < max, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,246 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/visor/blockdb"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/visor/historydb"
+)
+
+func TestSearch(t *testing.T) {
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	hash := func(prefix ...byte) cipher.SHA256 {
+		h := testutil.RandSHA256(t)
+		copy(h[:], prefix)
+		return h
+	}
+
+	blockHash := hash(0xab, 0xcd, 0xef, 0x01)
+	txnID := hash(0xab, 0xcd, 0xe0)
+	unconfirmedTxnID := hash(0xab, 0xcd, 0xe1)
+	uxID := hash(0xab, 0xcd, 0xef, 0x02)
+	var manyBlockHashes []cipher.SHA256
+	for i := 0; i < MaxSearchResults+5; i++ {
+		manyBlockHashes = append(manyBlockHashes, hash(0xff, 0xff, 0xff))
+	}
+
+	err := db.Update("", func(tx *dbutil.Tx) error {
+		for _, h := range append(manyBlockHashes, blockHash) {
+			if err := dbutil.PutBucketValue(tx, blockdb.BlocksBkt, h[:], []byte{1}); err != nil {
+				return err
+			}
+		}
+		if err := dbutil.PutBucketValue(tx, historydb.TransactionsBkt, txnID[:], []byte{1}); err != nil {
+			return err
+		}
+		if err := dbutil.PutBucketValue(tx, historydb.UxOutsBkt, uxID[:], []byte{1}); err != nil {
+			return err
+		}
+		return dbutil.PutBucketValue(tx, UnconfirmedTxnsBkt, []byte(unconfirmedTxnID.Hex()), []byte{1})
+	})
+	require.NoError(t, err)
+
+	block := &coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 7,
+			},
+		},
+	}
+
+	bc := &MockBlockchainer{}
+	bc.On("GetSignedBlockBySeq", matchDBTx, uint64(7)).Return(block, nil)
+	bc.On("GetSignedBlockBySeq", matchDBTx, uint64(8)).Return(nil, nil)
+	bc.On("GetSignedBlockByHash", matchDBTx, mock.Anything).Return(block, nil)
+
+	his := &MockHistoryer{}
+	his.On("GetTransaction", matchDBTx, txnID).Return(&historydb.Transaction{
+		BlockSeq: 3,
+	}, nil)
+	his.On("GetUxOuts", matchDBTx, []cipher.SHA256{uxID}).Return([]historydb.UxOut{
+		{
+			Out: coin.UxOut{
+				Head: coin.UxHead{
+					BkSeq: 5,
+				},
+			},
+			SpentTxnID:    txnID,
+			SpentBlockSeq: 6,
+		},
+	}, nil)
+
+	v := &Visor{
+		db:         db,
+		blockchain: bc,
+		history:    his,
+	}
+
+	addr := testutil.MakeAddress()
+
+	cases := []struct {
+		name    string
+		q       string
+		results []SearchResult
+	}{
+		{
+			name: "block seq",
+			q:    "7",
+			results: []SearchResult{
+				{
+					Type:     SearchResultBlock,
+					Hash:     block.HashHeader(),
+					BlockSeq: 7,
+					Exact:    true,
+				},
+			},
+		},
+		{
+			name: "block seq above the head block",
+			q:    "8",
+		},
+		{
+			name: "address",
+			q:    " " + addr.String() + " ",
+			results: []SearchResult{
+				{
+					Type:    SearchResultAddress,
+					Address: addr,
+					Exact:   true,
+				},
+			},
+		},
+		{
+			name: "block hash",
+			q:    blockHash.Hex(),
+			results: []SearchResult{
+				{
+					Type:     SearchResultBlock,
+					Hash:     blockHash,
+					BlockSeq: 7,
+					Exact:    true,
+				},
+			},
+		},
+		{
+			name: "transaction ID",
+			q:    txnID.Hex(),
+			results: []SearchResult{
+				{
+					Type:     SearchResultTransaction,
+					Hash:     txnID,
+					BlockSeq: 3,
+					Exact:    true,
+				},
+			},
+		},
+		{
+			name: "unconfirmed transaction ID",
+			q:    unconfirmedTxnID.Hex(),
+			results: []SearchResult{
+				{
+					Type:        SearchResultTransaction,
+					Hash:        unconfirmedTxnID,
+					Unconfirmed: true,
+					Exact:       true,
+				},
+			},
+		},
+		{
+			name: "output ID",
+			q:    uxID.Hex(),
+			results: []SearchResult{
+				{
+					Type:     SearchResultUxOut,
+					Hash:     uxID,
+					BlockSeq: 5,
+					Spent:    true,
+					Exact:    true,
+				},
+			},
+		},
+		{
+			name: "prefix",
+			q:    "ABCDEF",
+			results: []SearchResult{
+				{
+					Type:     SearchResultBlock,
+					Hash:     blockHash,
+					BlockSeq: 7,
+				},
+				{
+					Type:     SearchResultUxOut,
+					Hash:     uxID,
+					BlockSeq: 5,
+					Spent:    true,
+				},
+			},
+		},
+		{
+			name: "odd length prefix",
+			q:    "abcde1",
+			results: []SearchResult{
+				{
+					Type:        SearchResultTransaction,
+					Hash:        unconfirmedTxnID,
+					Unconfirmed: true,
+				},
+			},
+		},
+		{
+			name: "odd length prefix of whole bytes",
+			q:    "abcdef0",
+			results: []SearchResult{
+				{
+					Type:     SearchResultBlock,
+					Hash:     blockHash,
+					BlockSeq: 7,
+				},
+				{
+					Type:     SearchResultUxOut,
+					Hash:     uxID,
+					BlockSeq: 5,
+					Spent:    true,
+				},
+			},
+		},
+		{
+			name: "prefix too short",
+			q:    "abcde",
+		},
+		{
+			name: "not hex",
+			q:    "abcdefg",
+		},
+		{
+			name: "empty",
+			q:    "",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, truncated, err := v.Search(tc.q)
+			require.NoError(t, err)
+			require.Equal(t, tc.results, results)
+			require.False(t, truncated)
+		})
+	}
+
+	results, truncated, err := v.Search("ffffff")
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Len(t, results, MaxSearchResults)
+	for _, r := range results {
+		require.Equal(t, SearchResultBlock, r.Type)
+		require.Equal(t, byte(0xff), r.Hash[2])
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/skycoin/skycoin/src/transaction"
 	"github.com/skycoin/skycoin/src/util/logging"
 	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/util/metrics"
 	"github.com/skycoin/skycoin/src/util/timeutil"
 	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
@@ -29,7 +30,12 @@ import (
 	"github.com/skycoin/skycoin/src/wallet"
 )
 
-var logger = logging.MustGetLogger("visor")
+var (
+	logger = logging.MustGetLogger("visor")
+
+	blockExecutionDuration = metrics.MustRegisterHistogram(metrics.NewHistogram("skycoin_block_execution_duration_seconds",
+		"Time taken to execute a block, including the unconfirmed pool and history updates", nil))
+)
 
 // Visor manages the blockchain
 type Visor struct {
@@ -232,7 +238,10 @@ func (vs *Visor) maybeCreateGenesisBlock(tx *dbutil.Tx) error {
 	var sb coin.SignedBlock
 	// record the signature of genesis block
 	if vs.Config.IsBlockPublisher {
-		sb = vs.signBlock(*b)
+		sb, err = vs.signBlock(*b)
+		if err != nil {
+			return err
+		}
 		logger.Infof("Genesis block signature=%s", sb.Sig.Hex())
 	} else {
 		sb = coin.SignedBlock{
@@ -291,6 +300,16 @@ func (vs *Visor) RemoveInvalidUnconfirmed() ([]cipher.SHA256, error) {
 
 // createBlock creates a SignedBlock from pending transactions
 func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, error) {
+	b, err := vs.createUnsignedBlock(tx, when)
+	if err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	return vs.signBlock(b)
+}
+
+// createUnsignedBlock creates a Block from pending transactions, to be signed by signBlock
+func (vs *Visor) createUnsignedBlock(tx *dbutil.Tx, when uint64) (coin.Block, error) {
 	if !vs.Config.IsBlockPublisher {
 		logger.Panic("Only a block publisher node can create blocks")
 	}
@@ -298,15 +317,10 @@ func (vs *Visor) createBlock(tx *dbutil.Tx, when uint64) (coin.SignedBlock, erro
 	// Gather all unconfirmed transactions
 	txns, err := vs.unconfirmed.AllRawTransactions(tx)
 	if err != nil {
-		return coin.SignedBlock{}, err
-	}
-
-	b, err := vs.createBlockFromTxns(tx, txns, when)
-	if err != nil {
-		return coin.SignedBlock{}, err
+		return coin.Block{}, err
 	}
 
-	return vs.signBlock(b), nil
+	return vs.createBlockFromTxns(tx, txns, when)
 }
 
 // createBlockFromTxns creates a Block from specified set of transactions according to set of determinstic rules.
@@ -382,21 +396,33 @@ func (vs *Visor) createBlockFromTxns(tx *dbutil.Tx, txns coin.Transactions, when
 	return *b, nil
 }
 
-// CreateAndExecuteBlock creates a SignedBlock from pending transactions and executes it
+// CreateAndExecuteBlock creates a SignedBlock from pending transactions and executes it.
+// The block is signed outside of the database transactions, so that a slow external
+// block signer doesn't hold the database write lock.
 func (vs *Visor) CreateAndExecuteBlock() (coin.SignedBlock, error) {
-	var sb coin.SignedBlock
-
-	err := vs.db.Update("CreateAndExecuteBlock", func(tx *dbutil.Tx) error {
+	var b coin.Block
+	if err := vs.db.View("CreateAndExecuteBlock", func(tx *dbutil.Tx) error {
 		var err error
-		sb, err = vs.createBlock(tx, uint64(time.Now().UTC().Unix()))
-		if err != nil {
-			return err
-		}
+		b, err = vs.createUnsignedBlock(tx, uint64(time.Now().UTC().Unix()))
+		return err
+	}); err != nil {
+		return coin.SignedBlock{}, err
+	}
+
+	sb, err := vs.signBlock(b)
+	if err != nil {
+		return coin.SignedBlock{}, err
+	}
 
+	// The block is verified again against the blockchain when it is executed,
+	// in case the blockchain or the unconfirmed pool changed while it was signed
+	if err := vs.db.Update("CreateAndExecuteBlock", func(tx *dbutil.Tx) error {
 		return vs.executeSignedBlock(tx, sb)
-	})
+	}); err != nil {
+		return coin.SignedBlock{}, err
+	}
 
-	return sb, err
+	return sb, nil
 }
 
 // CreateBlockFromTxns creates a Block from specified set of transactions according to set of determinstic rules.
@@ -452,6 +478,8 @@ func (vs *Visor) executeSignedBlock(tx *dbutil.Tx, b coin.SignedBlock) error {
 // executeSignedBlockUnsafe add a block to the blockchain, or returns error.
 // Blocks must be executed in sequence. Block signature is not verified.
 func (vs *Visor) executeSignedBlockUnsafe(tx *dbutil.Tx, b coin.SignedBlock) error {
+	start := time.Now()
+
 	if err := vs.blockchain.ExecuteBlock(tx, &b); err != nil {
 		return err
 	}
@@ -467,21 +495,47 @@ func (vs *Visor) executeSignedBlockUnsafe(tx *dbutil.Tx, b coin.SignedBlock) err
 	}
 
 	// Update the HistoryDB
-	return vs.history.ParseBlock(tx, b.Block)
+	if err := vs.history.ParseBlock(tx, b.Block); err != nil {
+		return err
+	}
+
+	blockExecutionDuration.WithLabelValues().ObserveDuration(start)
+
+	return nil
 }
 
-// signBlock signs a block for a block publisher node. Will panic if anything is invalid
-func (vs *Visor) signBlock(b coin.Block) coin.SignedBlock {
+// signBlock signs a block for a block publisher node. Will panic if the node is not a block publisher
+func (vs *Visor) signBlock(b coin.Block) (coin.SignedBlock, error) {
 	if !vs.Config.IsBlockPublisher {
 		logger.Panic("Only a block publisher node can sign blocks")
 	}
 
-	sig := cipher.MustSignHash(b.HashHeader(), vs.Config.BlockchainSeckey)
+	signer := vs.Config.BlockSigner
+	if signer == nil {
+		var err error
+		signer, err = NewSecKeyBlockSigner(vs.Config.BlockchainSeckey)
+		if err != nil {
+			return coin.SignedBlock{}, err
+		}
+	}
+
+	hash := b.HashHeader()
+	sig, err := signer.SignHash(hash)
+	if err != nil {
+		logger.WithError(err).Error("BlockSigner.SignHash failed")
+		return coin.SignedBlock{}, err
+	}
+
+	// Don't create a block that the network would reject, if an external signer uses the wrong key
+	if err := cipher.VerifyPubKeySignedHash(vs.Config.BlockchainPubkey, sig, hash); err != nil {
+		logger.WithError(err).Error("Block signature does not match the blockchain pubkey")
+		return coin.SignedBlock{}, ErrBlockSignerPubKeyMismatch
+	}
 
 	return coin.SignedBlock{
 		Block: b,
 		Sig:   sig,
-	}
+	}, nil
 }
 
 /*
@@ -1305,6 +1359,21 @@ func (vs *Visor) GetTransactionsNum() (uint64, error) {
 	return num, nil
 }
 
+// GetAddressStats returns the stats of an address, nil if the address has no transactions
+func (vs *Visor) GetAddressStats(addr cipher.Address) (*historydb.AddressStats, error) {
+	var stats *historydb.AddressStats
+
+	if err := vs.db.View("GetAddressStats", func(tx *dbutil.Tx) error {
+		var err error
+		stats, err = vs.history.GetAddressStats(tx, addr)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
 // GetSignedBlockByHash get block of specific hash header, return nil on not found.
 func (vs *Visor) GetSignedBlockByHash(hash cipher.SHA256) (*coin.SignedBlock, error) {
 	var sb *coin.SignedBlock
@@ -339,9 +339,18 @@ func TestVisorCreateBlock(t *testing.T) {
 
 	v.Config.MaxBlockTransactionsSize, err = txn.Size()
 	require.NoError(t, err)
+
+	// The block is signed without holding the database write lock
+	signer, err := NewSecKeyBlockSigner(genSecret)
+	require.NoError(t, err)
+	v.Config.BlockSigner = unlockedDBBlockSigner{
+		BlockSigner: signer,
+		db:          db,
+	}
 	sb, err := v.CreateAndExecuteBlock()
 	require.NoError(t, err)
 	require.Equal(t, 1, len(sb.Body.Transactions))
+	v.Config.BlockSigner = nil
 
 	var length uint64
 	err = db.View("", func(tx *dbutil.Tx) error {
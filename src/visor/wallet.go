@@ -12,7 +12,6 @@ import (
 	"github.com/skycoin/skycoin/src/util/mathutil"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 	"github.com/skycoin/skycoin/src/wallet"
-	"github.com/skycoin/skycoin/src/wallet/bip44wallet"
 )
 
 // UserError wraps user input-related errors.
@@ -51,20 +50,36 @@ var (
 
 // GetWalletBalance returns balance pairs of specific wallet
 func (vs *Visor) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.AddressBalances, error) {
+	return vs.getWalletBalance(wltID, nil)
+}
+
+// GetWalletAccountBalance returns balance pairs of an account of specific bip44 wallet
+func (vs *Visor) GetWalletAccountBalance(wltID string, account uint32) (wallet.BalancePair, wallet.AddressBalances, error) {
+	return vs.getWalletBalance(wltID, &account)
+}
+
+func (vs *Visor) getWalletBalance(wltID string, account *uint32) (wallet.BalancePair, wallet.AddressBalances, error) {
 	var addressBalances wallet.AddressBalances
 	var walletBalance wallet.BalancePair
 	var addrsBalanceList []wallet.BalancePair
 	var addrs []cipher.Address
 
 	if err := vs.wallets.View(wltID, func(w wallet.Wallet) error {
-		var err error
+		options, err := walletAccountOptions(w, account)
+		if err != nil {
+			return err
+		}
+
 		addrs, err = func() ([]cipher.Address, error) {
-			addrs, err := w.GetAddresses()
+			addrs, err := w.GetAddresses(options...)
 			if err != nil {
 				return nil, err
 			}
 			return wallet.SkycoinAddresses(addrs), nil
 		}()
+		if err != nil {
+			return err
+		}
 
 		addrsBalanceList, err = vs.GetBalanceOfAddresses(addrs)
 		return err
@@ -105,6 +120,20 @@ func (vs *Visor) GetWalletBalance(wltID string) (wallet.BalancePair, wallet.Addr
 	return walletBalance, addressBalances, nil
 }
 
+// walletAccountOptions returns the options that select the bip44 account in the wallet,
+// or no options if the account is nil
+func walletAccountOptions(w wallet.Wallet, account *uint32) ([]wallet.Option, error) {
+	if account == nil {
+		return nil, nil
+	}
+
+	if err := wallet.ValidateBip44Account(w, *account); err != nil {
+		return nil, err
+	}
+
+	return []wallet.Option{wallet.OptionAccount(*account)}, nil
+}
+
 // GetWalletUnconfirmedTransactions returns all unconfirmed transactions in given wallet
 func (vs *Visor) GetWalletUnconfirmedTransactions(wltID string) ([]UnconfirmedTransaction, error) {
 	var txns []UnconfirmedTransaction
@@ -160,7 +189,7 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 		return nil, nil, ErrTransactionAlreadySigned
 	}
 
-	if err := vs.wallets.ViewSecrets(wltID, password, func(w wallet.Wallet) error {
+	if err := vs.wallets.ViewSecretsToSpend(wltID, password, func(w wallet.Wallet, authorize func(wallet.Spend) error) error {
 		return vs.db.View("WalletSignTransaction", func(tx *dbutil.Tx) error {
 			// Verify the transaction before signing
 			if err := transaction.VerifySingleTxnUserConstraints(*txn); err != nil {
@@ -186,6 +215,22 @@ func (vs *Visor) WalletSignTransaction(wltID string, password []byte, txn *coin.
 				uxOuts[i] = in.UxOut
 			}
 
+			// Check the transaction against the wallet's spending policy
+			head, err := vs.blockchain.Head(tx)
+			if err != nil {
+				logger.WithError(err).Error("blockchain.Head failed")
+				return err
+			}
+
+			spend, err := wallet.NewSpend(w, txn, minConfirmations(head.Head.BkSeq, uxOuts))
+			if err != nil {
+				return err
+			}
+
+			if err := authorize(spend); err != nil {
+				return err
+			}
+
 			signedTxn, err = wallet.SignTransaction(w, txn, signIndexes, uxOuts)
 			if err != nil {
 				logger.WithError(err).Error("wallet.SignTransaction failed")
@@ -225,6 +270,9 @@ type CreateTransactionParams struct {
 	// IgnoreUnconfirmed if true, outputs matching Addresses or UxOuts spent by
 	// an unconfirmed transactions will be ignored, otherwise an error will be returned
 	IgnoreUnconfirmed bool
+	// Account if set, restricts the spendable outputs to the addresses of this bip44 account,
+	// and the change is sent to the account's change chain. Only used when creating a transaction from a wallet.
+	Account *uint32
 }
 
 // Validate validates params
@@ -276,29 +324,25 @@ func (vs *Visor) WalletCreateTransactionSigned(wltID string, password []byte, p
 		return nil, nil, err
 	}
 
-	if p.ChangeAddress == nil && w.Type() == wallet.WalletTypeBip44 {
-		// TODO: Maybe add the `PeekChangeAddress` to wallet.Wallet interface, and
-		// only bip44 wallet will implement it, all others do nothing. In this way
-		// we don't have to explicitly check the wallet type here.
-		//
-		// For bip44 wallet, peek a change address if p.ChangeAddress is nill
-		if err := vs.wallets.Update(wltID, func(w wallet.Wallet) error {
-			addr, err := w.(*bip44wallet.Wallet).PeekChangeAddress(vs.tf)
-			if err != nil {
-				logger.Critical().WithError(err).Error("PeekChangeAddress failed")
-				return err
-			}
-			skyAddr := addr.(cipher.Address)
-			p.ChangeAddress = &skyAddr
-			return nil
-		}); err != nil {
+	options, err := walletAccountOptions(w, wp.Account)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Wallets with a change chain send change to an unused change address,
+	// instead of reusing one of the addresses being spent
+	if _, ok := w.(wallet.ChangeAddressPeeker); ok && p.ChangeAddress == nil {
+		addr, err := vs.wallets.PeekChangeAddress(wltID, vs.tf, options...)
+		if err != nil {
+			logger.Critical().WithError(err).Error("PeekChangeAddress failed")
 			return nil, nil, err
 		}
+		p.ChangeAddress = &addr
 	}
 
-	if err := vs.wallets.ViewSecrets(wltID, password, func(w wallet.Wallet) error {
+	if err := vs.wallets.ViewSecretsToSpend(wltID, password, func(w wallet.Wallet, authorize func(wallet.Spend) error) error {
 		var err error
-		txn, inputs, err = vs.walletCreateTransaction("WalletCreateTransactionSigned", w, p, wp, transaction.TxnSigned)
+		txn, inputs, err = vs.walletCreateTransaction("WalletCreateTransactionSigned", w, p, wp, transaction.TxnSigned, authorize)
 		return err
 	}); err != nil {
 		return nil, nil, err
@@ -322,13 +366,13 @@ func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp
 	var inputs []TransactionInput
 
 	if err := vs.wallets.Update(wltID, func(w wallet.Wallet) error {
-		if p.ChangeAddress == nil && w.Type() == wallet.WalletTypeBip44 {
-			// TODO: Maybe add the `PeekChangeAddress` to wallet.Wallet interface, and
-			// only bip44 wallet will implement it, all others do nothing. In this way
-			// we don't have to explicitly check the wallet type here.
-			//
-			// For bip44 wallet, peek a change address if p.ChangeAddress is nill
-			addr, err := w.(*bip44wallet.Wallet).PeekChangeAddress(vs.tf)
+		options, err := walletAccountOptions(w, wp.Account)
+		if err != nil {
+			return err
+		}
+
+		if peeker, ok := w.(wallet.ChangeAddressPeeker); ok && p.ChangeAddress == nil {
+			addr, err := peeker.PeekChangeAddress(vs.tf, options...)
 			if err != nil {
 				logger.Critical().WithError(err).Error("PeekChangeAddress failed")
 				return err
@@ -337,8 +381,7 @@ func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp
 			p.ChangeAddress = &skyAddr
 		}
 
-		var err error
-		txn, inputs, err = vs.walletCreateTransaction("WalletCreateTransaction", w, p, wp, transaction.TxnUnsigned)
+		txn, inputs, err = vs.walletCreateTransaction("WalletCreateTransaction", w, p, wp, transaction.TxnUnsigned, nil)
 		return err
 	}); err != nil {
 		return nil, nil, err
@@ -347,7 +390,9 @@ func (vs *Visor) WalletCreateTransaction(wltID string, p transaction.Params, wp
 	return txn, inputs, nil
 }
 
-func (vs *Visor) walletCreateTransaction(methodName string, w wallet.Wallet, p transaction.Params, wp CreateTransactionParams, signed transaction.TxnSignedFlag) (*coin.Transaction, []TransactionInput, error) {
+// walletCreateTransaction creates a transaction from the wallet.
+// Signed transactions are signed once authorize allows the spend of the transaction.
+func (vs *Visor) walletCreateTransaction(methodName string, w wallet.Wallet, p transaction.Params, wp CreateTransactionParams, signed transaction.TxnSignedFlag, authorize func(wallet.Spend) error) (*coin.Transaction, []TransactionInput, error) {
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
 	}
@@ -355,9 +400,14 @@ func (vs *Visor) walletCreateTransaction(methodName string, w wallet.Wallet, p t
 		return nil, nil, err
 	}
 
-	// Get all addresses from the wallet for checking params against
+	options, err := walletAccountOptions(w, wp.Account)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get all addresses from the wallet, or the selected account, for checking params against
 	walletAddresses, err := func() ([]cipher.Address, error) {
-		addrs, err := w.GetAddresses()
+		addrs, err := w.GetAddresses(options...)
 		if err != nil {
 			return nil, err
 		}
@@ -390,7 +440,7 @@ func (vs *Visor) walletCreateTransaction(methodName string, w wallet.Wallet, p t
 
 	if err := vs.db.View(methodName, func(tx *dbutil.Tx) error {
 		var err error
-		txn, uxb, err = vs.walletCreateTransactionTx(tx, methodName, w, p, wp, signed, addrs, walletAddressesMap)
+		txn, uxb, err = vs.walletCreateTransactionTx(tx, methodName, w, p, wp, signed, authorize, addrs, walletAddressesMap, options)
 		return err
 	}); err != nil {
 		return nil, nil, err
@@ -402,8 +452,8 @@ func (vs *Visor) walletCreateTransaction(methodName string, w wallet.Wallet, p t
 }
 
 func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
-	w wallet.Wallet, p transaction.Params, wp CreateTransactionParams, signed transaction.TxnSignedFlag,
-	addrs []cipher.Address, walletAddressesMap map[cipher.Address]struct{}) (*coin.Transaction, []transaction.UxBalance, error) {
+	w wallet.Wallet, p transaction.Params, wp CreateTransactionParams, signed transaction.TxnSignedFlag, authorize func(wallet.Spend) error,
+	addrs []cipher.Address, walletAddressesMap map[cipher.Address]struct{}, options []wallet.Option) (*coin.Transaction, []transaction.UxBalance, error) {
 	// Note: assumes inputs have already been validated by walletCreateTransaction
 
 	head, err := vs.blockchain.Head(tx)
@@ -441,9 +491,27 @@ func (vs *Visor) walletCreateTransactionTx(tx *dbutil.Tx, methodName string,
 
 	switch signed {
 	case transaction.TxnSigned:
-		txn, uxb, err = wallet.CreateTransactionSigned(w, p, auxs, head.Time())
+		var policy *wallet.SpendingPolicy
+		policy, err = w.SpendingPolicy()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Only choose outputs with the confirmations required by the spending policy.
+		// Outputs requested by hash are checked by the policy instead.
+		if policy != nil && len(wp.UxOuts) == 0 {
+			auxs = confirmedAddressUxOuts(auxs, head.Head.BkSeq, policy.MinConfirmations)
+		}
+
+		txn, uxb, err = wallet.CreateTransactionAuthorized(w, p, auxs, head.Time(), func(txn *coin.Transaction, uxb []transaction.UxBalance) error {
+			spend, err := wallet.NewSpend(w, txn, minConfirmationsUxBalance(head.Head.BkSeq, uxb))
+			if err != nil {
+				return err
+			}
+			return authorize(spend)
+		}, options...)
 	case transaction.TxnUnsigned:
-		txn, uxb, err = wallet.CreateTransaction(w, p, auxs, head.Time())
+		txn, uxb, err = wallet.CreateTransaction(w, p, auxs, head.Time(), options...)
 	default:
 		logger.Panic("Invalid TxnSignedFlag")
 	}
@@ -613,3 +681,44 @@ func (vs *Visor) getCreateTransactionAuxsAddress(tx *dbutil.Tx, addrs []cipher.A
 
 	return vs.getCreateTransactionAuxsUxOut(tx, hashes, ignoreUnconfirmed)
 }
+
+// minConfirmations returns the lowest number of confirmations of the outputs, given the head block seq
+func minConfirmations(headSeq uint64, uxOuts []coin.UxOut) uint64 {
+	var n uint64
+	for i, ux := range uxOuts {
+		c := headSeq - ux.Head.BkSeq + 1
+		if i == 0 || c < n {
+			n = c
+		}
+	}
+	return n
+}
+
+// minConfirmationsUxBalance returns the lowest number of confirmations of the outputs, given the head block seq
+func minConfirmationsUxBalance(headSeq uint64, uxb []transaction.UxBalance) uint64 {
+	var n uint64
+	for i, ux := range uxb {
+		c := headSeq - ux.BkSeq + 1
+		if i == 0 || c < n {
+			n = c
+		}
+	}
+	return n
+}
+
+// confirmedAddressUxOuts returns the outputs with at least minConfirmations confirmations, given the head block seq
+func confirmedAddressUxOuts(auxs coin.AddressUxOuts, headSeq, minConfirmations uint64) coin.AddressUxOuts {
+	if minConfirmations == 0 {
+		return auxs
+	}
+
+	confirmed := make(coin.AddressUxOuts, len(auxs))
+	for a, uxa := range auxs {
+		for _, ux := range uxa {
+			if headSeq-ux.Head.BkSeq+1 >= minConfirmations {
+				confirmed[a] = append(confirmed[a], ux)
+			}
+		}
+	}
+	return confirmed
+}
@@ -0,0 +1,212 @@
+package visor
+
+// This file contains the Visor methods for the transaction history of wallets
+
+import (
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/util/mathutil"
+	"github.com/skycoin/skycoin/src/visor/dbutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// WalletTransactionType is the classification of a transaction from the point of view of a wallet
+type WalletTransactionType string
+
+const (
+	// WalletTransactionIncoming is a transaction that spends no outputs of the wallet
+	WalletTransactionIncoming WalletTransactionType = "incoming"
+	// WalletTransactionOutgoing is a transaction that spends outputs of the wallet
+	// and sends coins to an address that is not in the wallet
+	WalletTransactionOutgoing WalletTransactionType = "outgoing"
+	// WalletTransactionSelf is a transaction that spends outputs of the wallet
+	// and sends all coins to addresses of the wallet
+	WalletTransactionSelf WalletTransactionType = "self"
+	// WalletTransactionChange is a transaction that spends outputs of the wallet
+	// and sends all coins to change addresses of the wallet
+	WalletTransactionChange WalletTransactionType = "change"
+)
+
+// WalletTransaction is a confirmed transaction of a wallet, with its effect on the wallet's balance.
+// Coin hours are the hours stored in the outputs, the hours accrued by the spent outputs are not included.
+type WalletTransaction struct {
+	Transaction
+	Type WalletTransactionType
+	// CoinsIn and HoursIn are the totals of the outputs sent to the wallet
+	CoinsIn uint64
+	HoursIn uint64
+	// CoinsOut and HoursOut are the totals of the spent outputs of the wallet
+	CoinsOut uint64
+	HoursOut uint64
+	// Balance is the balance of the wallet after the transaction
+	Balance wallet.Balance
+}
+
+// CoinsDelta returns the net change of the wallet's coins
+func (wt WalletTransaction) CoinsDelta() int64 {
+	return int64(wt.CoinsIn) - int64(wt.CoinsOut)
+}
+
+// HoursDelta returns the net change of the wallet's coin hours
+func (wt WalletTransaction) HoursDelta() int64 {
+	return int64(wt.HoursIn) - int64(wt.HoursOut)
+}
+
+// GetWalletHistory returns the confirmed transactions of all addresses of a wallet,
+// with the running balance of the wallet after each transaction.
+// If page is nil, all transactions are returned.
+func (vs *Visor) GetWalletHistory(wltID string, order SortOrder, page *PageIndex) ([]WalletTransaction, uint64, error) {
+	var addrs, changeAddrs []cipher.Address
+	if err := vs.wallets.View(wltID, func(w wallet.Wallet) error {
+		var err error
+		addrs, err = wallet.AllAddresses(w)
+		if err != nil {
+			return err
+		}
+
+		changeAddrs, err = wallet.AllChangeAddresses(w)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	var history []WalletTransaction
+	if err := vs.db.View("GetWalletHistory", func(tx *dbutil.Tx) error {
+		var err error
+		history, err = vs.getWalletHistory(tx, addrs, changeAddrs)
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	if order == DescOrder {
+		for i, j := 0, len(history)-1; i < j; i, j = i+1, j-1 {
+			history[i], history[j] = history[j], history[i]
+		}
+	}
+
+	if page == nil {
+		return history, 0, nil
+	}
+
+	start, end, pages, err := page.Cal(uint64(len(history)))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return history[start:end], pages, nil
+}
+
+// getWalletHistory returns the wallet transactions of the addresses, in ascending order
+func (vs *Visor) getWalletHistory(tx *dbutil.Tx, addrs, changeAddrs []cipher.Address) ([]WalletTransaction, error) {
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+
+	flts := []TxFilter{
+		NewAddrsFilter(addrs),
+		NewConfirmedTxFilter(true),
+	}
+
+	txns, _, err := vs.txns.GetTransactions(tx, flts, AscOrder, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([][]coin.UxOut, len(txns))
+	for i, txn := range txns {
+		// The genesis block transaction has no inputs
+		if len(txn.Transaction.In) == 0 {
+			continue
+		}
+
+		uxOuts, err := vs.history.GetUxOuts(tx, txn.Transaction.In)
+		if err != nil {
+			return nil, err
+		}
+
+		inputs[i] = make([]coin.UxOut, len(uxOuts))
+		for j, o := range uxOuts {
+			inputs[i][j] = o.Out
+		}
+	}
+
+	return newWalletHistory(txns, inputs, addrs, changeAddrs)
+}
+
+// newWalletHistory classifies transactions in ascending order and computes the running balance
+// of the wallet of the addresses. inputs are the outputs spent by each transaction.
+func newWalletHistory(txns []Transaction, inputs [][]coin.UxOut, addrs, changeAddrs []cipher.Address) ([]WalletTransaction, error) {
+	addrsMap := make(map[cipher.Address]struct{}, len(addrs))
+	for _, a := range addrs {
+		addrsMap[a] = struct{}{}
+	}
+
+	changeAddrsMap := make(map[cipher.Address]struct{}, len(changeAddrs))
+	for _, a := range changeAddrs {
+		changeAddrsMap[a] = struct{}{}
+	}
+
+	var balance wallet.Balance
+	history := make([]WalletTransaction, len(txns))
+	for i, txn := range txns {
+		wt := WalletTransaction{
+			Transaction: txn,
+			Type:        WalletTransactionIncoming,
+		}
+
+		var err error
+		for _, o := range inputs[i] {
+			if _, ok := addrsMap[o.Body.Address]; !ok {
+				continue
+			}
+
+			if wt.CoinsOut, err = mathutil.AddUint64(wt.CoinsOut, o.Body.Coins); err != nil {
+				return nil, err
+			}
+			if wt.HoursOut, err = mathutil.AddUint64(wt.HoursOut, o.Body.Hours); err != nil {
+				return nil, err
+			}
+		}
+
+		toOthers := false
+		toChangeOnly := true
+		for _, o := range txn.Transaction.Out {
+			if _, ok := addrsMap[o.Address]; !ok {
+				toOthers = true
+				continue
+			}
+
+			if _, ok := changeAddrsMap[o.Address]; !ok {
+				toChangeOnly = false
+			}
+
+			if wt.CoinsIn, err = mathutil.AddUint64(wt.CoinsIn, o.Coins); err != nil {
+				return nil, err
+			}
+			if wt.HoursIn, err = mathutil.AddUint64(wt.HoursIn, o.Hours); err != nil {
+				return nil, err
+			}
+		}
+
+		if wt.CoinsOut > 0 {
+			switch {
+			case toOthers:
+				wt.Type = WalletTransactionOutgoing
+			case toChangeOnly:
+				wt.Type = WalletTransactionChange
+			default:
+				wt.Type = WalletTransactionSelf
+			}
+		}
+
+		// The spent outputs are part of the balance, so the balance can't underflow
+		balance.Coins = balance.Coins + wt.CoinsIn - wt.CoinsOut
+		balance.Hours = balance.Hours + wt.HoursIn - wt.HoursOut
+		wt.Balance = balance
+
+		history[i] = wt
+	}
+
+	return history, nil
+}
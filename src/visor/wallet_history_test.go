@@ -0,0 +1,130 @@
+package visor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+func TestNewWalletHistory(t *testing.T) {
+	addr := testutil.MakeAddress()
+	changeAddr := testutil.MakeAddress()
+	otherAddr := testutil.MakeAddress()
+
+	uxOut := func(a cipher.Address, coins, hours uint64) coin.UxOut {
+		return coin.UxOut{
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        a,
+				Coins:          coins,
+				Hours:          hours,
+			},
+		}
+	}
+
+	txn := func(seq uint64, outs ...coin.TransactionOutput) Transaction {
+		return Transaction{
+			Transaction: coin.Transaction{
+				InnerHash: testutil.RandSHA256(t),
+				Out:       outs,
+			},
+			Status: NewConfirmedTransactionStatus(10-seq, seq),
+		}
+	}
+
+	out := func(a cipher.Address, coins, hours uint64) coin.TransactionOutput {
+		return coin.TransactionOutput{
+			Address: a,
+			Coins:   coins,
+			Hours:   hours,
+		}
+	}
+
+	txns := []Transaction{
+		// Receives 10 coins
+		txn(1, out(addr, 10e6, 100)),
+		// Sends 3 coins, 6 coins are sent back as change
+		txn(2, out(otherAddr, 3e6, 20), out(changeAddr, 6e6, 30)),
+		// Moves the change to the external address
+		txn(3, out(addr, 6e6, 10)),
+		// Consolidates the external address to the change address
+		txn(4, out(changeAddr, 6e6, 5)),
+		// Another wallet sends to the wallet and to itself
+		txn(5, out(changeAddr, 1e6, 1), out(otherAddr, 2e6, 2)),
+	}
+
+	inputs := [][]coin.UxOut{
+		{uxOut(otherAddr, 10e6, 50)},
+		{uxOut(addr, 10e6, 100)},
+		{uxOut(changeAddr, 6e6, 30)},
+		{uxOut(addr, 6e6, 10)},
+		{uxOut(otherAddr, 3e6, 20)},
+	}
+
+	history, err := newWalletHistory(txns, inputs, []cipher.Address{addr, changeAddr}, []cipher.Address{changeAddr})
+	require.NoError(t, err)
+
+	require.Equal(t, []WalletTransaction{
+		{
+			Transaction: txns[0],
+			Type:        WalletTransactionIncoming,
+			CoinsIn:     10e6,
+			HoursIn:     100,
+			Balance:     wallet.Balance{Coins: 10e6, Hours: 100},
+		},
+		{
+			Transaction: txns[1],
+			Type:        WalletTransactionOutgoing,
+			CoinsIn:     6e6,
+			HoursIn:     30,
+			CoinsOut:    10e6,
+			HoursOut:    100,
+			Balance:     wallet.Balance{Coins: 6e6, Hours: 30},
+		},
+		{
+			Transaction: txns[2],
+			Type:        WalletTransactionSelf,
+			CoinsIn:     6e6,
+			HoursIn:     10,
+			CoinsOut:    6e6,
+			HoursOut:    30,
+			Balance:     wallet.Balance{Coins: 6e6, Hours: 10},
+		},
+		{
+			Transaction: txns[3],
+			Type:        WalletTransactionChange,
+			CoinsIn:     6e6,
+			HoursIn:     5,
+			CoinsOut:    6e6,
+			HoursOut:    10,
+			Balance:     wallet.Balance{Coins: 6e6, Hours: 5},
+		},
+		{
+			Transaction: txns[4],
+			Type:        WalletTransactionIncoming,
+			CoinsIn:     1e6,
+			HoursIn:     1,
+			Balance:     wallet.Balance{Coins: 7e6, Hours: 6},
+		},
+	}, history)
+
+	require.Equal(t, int64(-4e6), history[1].CoinsDelta())
+	require.Equal(t, int64(-70), history[1].HoursDelta())
+	require.Equal(t, int64(1e6), history[4].CoinsDelta())
+	require.Equal(t, int64(1), history[4].HoursDelta())
+
+	// Without a change chain, moving coins between the wallet's addresses is a self transfer
+	history, err = newWalletHistory(txns[3:4], inputs[3:4], []cipher.Address{addr, changeAddr}, nil)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.Equal(t, WalletTransactionSelf, history[0].Type)
+
+	history, err = newWalletHistory(nil, nil, []cipher.Address{addr}, nil)
+	require.NoError(t, err)
+	require.Empty(t, history)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/skycoin/skycoin/src/visor/blockdb"
 	"github.com/skycoin/skycoin/src/visor/dbutil"
 	"github.com/skycoin/skycoin/src/wallet"
+	_ "github.com/skycoin/skycoin/src/wallet/bip44wallet"
 	"github.com/skycoin/skycoin/src/wallet/collection"
 )
 
@@ -1014,6 +1015,306 @@ func TestWalletCreateTransaction(t *testing.T) {
 	}
 }
 
+func TestWalletCreateTransactionAccount(t *testing.T) {
+	account := uint32(1)
+	missingAccount := uint32(2)
+
+	validParams := transaction.Params{
+		HoursSelection: transaction.HoursSelection{
+			Type: transaction.HoursSelectionTypeManual,
+		},
+		To: []coin.TransactionOutput{
+			{
+				Address: testutil.MakeAddress(),
+				Coins:   1e6,
+				Hours:   7,
+			},
+		},
+	}
+
+	for _, signed := range []transaction.TxnSignedFlag{transaction.TxnUnsigned, transaction.TxnSigned} {
+		t.Run(fmt.Sprintf("signed-flag=%d", signed), func(t *testing.T) {
+			var password []byte
+			if signed == transaction.TxnSigned {
+				password = []byte("foo")
+			}
+
+			ws, err := wallet.NewService(wallet.Config{
+				EnableWalletAPI: true,
+				CryptoType:      crypto.CryptoTypeScryptChacha20poly1305Insecure,
+				WalletDir:       prepareWltDir(),
+			})
+			require.NoError(t, err)
+
+			_, err = ws.CreateWallet("bip44.wlt", wallet.Options{
+				Label:      "test",
+				Coin:       wallet.CoinTypeSkycoin,
+				Encrypt:    len(password) != 0,
+				Password:   password,
+				CryptoType: crypto.CryptoTypeScryptChacha20poly1305Insecure,
+				Type:       wallet.WalletTypeBip44,
+				Seed:       "voyage say extend find sheriff surge priority merit ignore maple cash argue",
+			})
+			require.NoError(t, err)
+
+			_, err = ws.CreateWallet("collection.wlt", wallet.Options{
+				Label: "test",
+				Type:  wallet.WalletTypeCollection,
+			})
+			require.NoError(t, err)
+
+			_, err = ws.CreateAccount("bip44.wlt", password, "customer")
+			require.NoError(t, err)
+
+			// Only the addresses of the selected account are spendable
+			accountAddrs, err := ws.GetAddresses("bip44.wlt", wallet.OptionAccount(account))
+			require.NoError(t, err)
+			require.Len(t, accountAddrs, 2)
+			accountChangeAddrs, err := ws.GetAddresses("bip44.wlt", wallet.OptionAccount(account), wallet.OptionChange())
+			require.NoError(t, err)
+			require.Len(t, accountChangeAddrs, 1)
+
+			uxOut := coin.UxOut{
+				Head: coin.UxHead{
+					Time:  uint64(time.Now().Unix()) - 3700,
+					BkSeq: 100,
+				},
+				Body: coin.UxBody{
+					SrcTransaction: testutil.RandSHA256(t),
+					Address:        accountAddrs[0],
+					Coins:          2e6,
+					Hours:          100,
+				},
+			}
+
+			b := &MockBlockchainer{}
+			ut := &MockUnconfirmedTransactionPooler{}
+			up := &MockUnspentPooler{}
+
+			b.On("Head", matchDBTx).Return(&coin.SignedBlock{
+				Block: coin.Block{
+					Head: coin.BlockHeader{
+						Time: uint64(time.Now().UTC().Unix()),
+					},
+				},
+			}, nil)
+			up.On("GetUnspentHashesOfAddrs", matchDBTx, accountAddrs).Return(blockdb.AddressHashes{
+				accountAddrs[0]: []cipher.SHA256{uxOut.Hash()},
+			}, nil)
+			ut.On("ForEach", matchDBTx, mock.Anything).Return(nil)
+			up.On("GetArray", matchDBTx, []cipher.SHA256{uxOut.Hash()}).Return(coin.UxArray{uxOut}, nil)
+			b.On("Unspent").Return(up)
+			b.On("VerifySingleTxnSoftHardConstraints", matchDBTx, mock.Anything, params.MainNetDistribution, params.UserVerifyTxn, signed).Return(nil, nil, nil)
+
+			db, shutdown := prepareDB(t)
+			defer shutdown()
+
+			v := &Visor{
+				db:          db,
+				blockchain:  b,
+				unconfirmed: ut,
+				wallets:     ws,
+				Config: Config{
+					Distribution: params.MainNetDistribution,
+				},
+				tf: mockTxnsFinder{},
+			}
+
+			create := func(wltID string, wp CreateTransactionParams) (*coin.Transaction, []TransactionInput, error) {
+				if signed == transaction.TxnSigned {
+					return v.WalletCreateTransactionSigned(wltID, password, validParams, wp)
+				}
+				return v.WalletCreateTransaction(wltID, validParams, wp)
+			}
+
+			txn, inputs, err := create("bip44.wlt", CreateTransactionParams{
+				Account: &account,
+			})
+			require.NoError(t, err)
+			require.Len(t, inputs, 1)
+			require.Equal(t, uxOut, inputs[0].UxOut)
+			require.Equal(t, signed == transaction.TxnSigned, txn.IsFullySigned())
+
+			// The change is sent to the account's change chain
+			require.Len(t, txn.Out, 2)
+			require.Equal(t, accountChangeAddrs[0], txn.Out[1].Address)
+
+			// Addresses of the default account are not spendable from the account
+			defaultAddrs, err := ws.GetAddresses("bip44.wlt")
+			require.NoError(t, err)
+			_, _, err = create("bip44.wlt", CreateTransactionParams{
+				Account:   &account,
+				Addresses: defaultAddrs[:1],
+			})
+			require.Equal(t, wallet.ErrUnknownAddress, err)
+
+			_, _, err = create("bip44.wlt", CreateTransactionParams{
+				Account: &missingAccount,
+			})
+			require.Equal(t, wallet.ErrBip44AccountNotFound, err)
+
+			_, _, err = v.WalletCreateTransaction("collection.wlt", validParams, CreateTransactionParams{
+				Account: &account,
+			})
+			require.Equal(t, wallet.ErrWalletNotBip44, err)
+		})
+	}
+}
+
+func TestWalletCreateTransactionSpendingPolicy(t *testing.T) {
+	password := []byte("foo")
+
+	ws, err := wallet.NewService(wallet.Config{
+		EnableWalletAPI: true,
+		CryptoType:      crypto.CryptoTypeSha256Xor,
+		WalletDir:       prepareWltDir(),
+	})
+	require.NoError(t, err)
+
+	_, err = ws.CreateWallet("t.wlt", wallet.Options{
+		Label:      "test",
+		Coin:       wallet.CoinTypeSkycoin,
+		Encrypt:    true,
+		Password:   password,
+		CryptoType: crypto.CryptoTypeSha256Xor,
+		Type:       wallet.WalletTypeBip44,
+		Seed:       "voyage say extend find sheriff surge priority merit ignore maple cash argue",
+	})
+	require.NoError(t, err)
+
+	addrs, err := ws.GetAddresses("t.wlt")
+	require.NoError(t, err)
+	changeAddrs, err := ws.GetAddresses("t.wlt", wallet.OptionChange())
+	require.NoError(t, err)
+
+	dest := testutil.MakeAddress()
+	_, err = ws.SetSpendingPolicy("t.wlt", password, &wallet.SpendingPolicy{
+		MaxCoinsPerTransaction: 2e6,
+		MaxCoinsPerDay:         3e6,
+		AllowedDestinations:    []wallet.PolicyDestination{{Address: dest}},
+		MinConfirmations:       10,
+	})
+	require.NoError(t, err)
+
+	newUxOut := func(bkSeq, coins uint64) coin.UxOut {
+		return coin.UxOut{
+			Head: coin.UxHead{
+				Time:  uint64(time.Now().Unix()) - 3700,
+				BkSeq: bkSeq,
+			},
+			Body: coin.UxBody{
+				SrcTransaction: testutil.RandSHA256(t),
+				Address:        addrs[0],
+				Coins:          coins,
+				Hours:          100,
+			},
+		}
+	}
+
+	// Only the confirmed output can be spent, the recent output has more coins and would be chosen otherwise
+	confirmedUxOut := newUxOut(91, 3e6)
+	recentUxOut := newUxOut(95, 5e6)
+
+	b := &MockBlockchainer{}
+	ut := &MockUnconfirmedTransactionPooler{}
+	up := &MockUnspentPooler{}
+
+	b.On("Head", matchDBTx).Return(&coin.SignedBlock{
+		Block: coin.Block{
+			Head: coin.BlockHeader{
+				BkSeq: 100,
+				Time:  uint64(time.Now().UTC().Unix()),
+			},
+		},
+	}, nil)
+	up.On("GetUnspentHashesOfAddrs", matchDBTx, addrs).Return(blockdb.AddressHashes{
+		addrs[0]: []cipher.SHA256{confirmedUxOut.Hash(), recentUxOut.Hash()},
+	}, nil)
+	ut.On("ForEach", matchDBTx, mock.Anything).Return(nil)
+	up.On("GetArray", matchDBTx, []cipher.SHA256{confirmedUxOut.Hash(), recentUxOut.Hash()}).Return(coin.UxArray{confirmedUxOut, recentUxOut}, nil)
+	b.On("Unspent").Return(up)
+	b.On("VerifySingleTxnSoftHardConstraints", matchDBTx, mock.Anything, params.MainNetDistribution, params.UserVerifyTxn, transaction.TxnSigned).Return(nil, nil, nil)
+
+	db, shutdown := prepareDB(t)
+	defer shutdown()
+
+	v := &Visor{
+		db:          db,
+		blockchain:  b,
+		unconfirmed: ut,
+		wallets:     ws,
+		Config: Config{
+			Distribution: params.MainNetDistribution,
+		},
+		tf: mockTxnsFinder{},
+	}
+
+	send := func(to cipher.Address, coins uint64) (*coin.Transaction, []TransactionInput, error) {
+		return v.WalletCreateTransactionSigned("t.wlt", password, transaction.Params{
+			HoursSelection: transaction.HoursSelection{
+				Type: transaction.HoursSelectionTypeManual,
+			},
+			To: []coin.TransactionOutput{
+				{
+					Address: to,
+					Coins:   coins,
+					Hours:   7,
+				},
+			},
+		}, CreateTransactionParams{})
+	}
+
+	_, _, err = send(testutil.MakeAddress(), 1e6)
+	require.Equal(t, wallet.ErrPolicyDestinationNotAllowed, err)
+
+	_, _, err = send(dest, 2e6+1)
+	require.Equal(t, wallet.ErrPolicyTransactionLimit, err)
+
+	txn, inputs, err := send(dest, 2e6)
+	require.NoError(t, err)
+	require.True(t, txn.IsFullySigned())
+	require.Len(t, inputs, 1)
+	require.Equal(t, confirmedUxOut, inputs[0].UxOut)
+
+	// The change sent back to the wallet is not part of the spend
+	require.Len(t, txn.Out, 2)
+	require.Equal(t, changeAddrs[0], txn.Out[1].Address)
+
+	_, spends, err := ws.GetSpendingPolicy("t.wlt")
+	require.NoError(t, err)
+	require.Len(t, spends, 1)
+	require.Equal(t, uint64(2e6), spends[0].Coins)
+
+	_, _, err = send(dest, 1e6+1)
+	require.Equal(t, wallet.ErrPolicyDailyLimit, err)
+}
+
+func TestMinConfirmations(t *testing.T) {
+	uxOuts := []coin.UxOut{
+		{Head: coin.UxHead{BkSeq: 5}},
+		{Head: coin.UxHead{BkSeq: 10}},
+		{Head: coin.UxHead{BkSeq: 1}},
+	}
+	require.Equal(t, uint64(1), minConfirmations(10, uxOuts))
+	require.Equal(t, uint64(6), minConfirmations(15, uxOuts))
+	require.Equal(t, uint64(0), minConfirmations(15, nil))
+
+	uxb := []transaction.UxBalance{{BkSeq: 5}, {BkSeq: 1}}
+	require.Equal(t, uint64(6), minConfirmationsUxBalance(10, uxb))
+	require.Equal(t, uint64(0), minConfirmationsUxBalance(10, nil))
+
+	a1 := testutil.MakeAddress()
+	a2 := testutil.MakeAddress()
+	auxs := coin.AddressUxOuts{
+		a1: uxOuts,
+		a2: uxOuts[1:2],
+	}
+	require.Equal(t, auxs, confirmedAddressUxOuts(auxs, 10, 0))
+	require.Equal(t, coin.AddressUxOuts{
+		a1: coin.UxArray{uxOuts[0], uxOuts[2]},
+	}, confirmedAddressUxOuts(auxs, 10, 6))
+}
+
 func TestCreateTransactionParamsValidate(t *testing.T) {
 	var nullAddress cipher.Address
 	addr := testutil.MakeAddress()
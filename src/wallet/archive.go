@@ -0,0 +1,211 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/cipher/crypto"
+)
+
+// ArchiveVersion is the current version of the wallet archive format
+const ArchiveVersion = 1
+
+var (
+	// ErrInvalidArchive is returned when a wallet archive can't be parsed
+	ErrInvalidArchive = NewError(errors.New("invalid wallet archive"))
+	// ErrUnsupportedArchiveVersion is returned when a wallet archive was created by a newer, unknown format version
+	ErrUnsupportedArchiveVersion = NewError(errors.New("unsupported wallet archive version"))
+	// ErrArchiveChecksumMismatch is returned when the decrypted content of a wallet archive does not match its checksum
+	ErrArchiveChecksumMismatch = NewError(errors.New("wallet archive checksum mismatch"))
+	// ErrMissingArchivePassword is returned when exporting or importing a wallet archive without a password
+	ErrMissingArchivePassword = NewError(errors.New("missing archive password"))
+	// ErrInvalidImportConflict is returned when the import conflict policy is unknown
+	ErrInvalidImportConflict = NewError(errors.New("invalid import conflict policy"))
+)
+
+// ImportConflict is the policy applied to wallets of an archive that conflict with existing wallets.
+// A wallet conflicts if it has the filename of a loaded wallet or of a wallet file in the wallet
+// directory, or the fingerprint of a loaded wallet.
+type ImportConflict string
+
+const (
+	// ImportConflictError aborts the import if any wallet conflicts, no wallet is imported
+	ImportConflictError ImportConflict = "error"
+	// ImportConflictSkip skips the conflicting wallets and imports the others
+	ImportConflictSkip ImportConflict = "skip"
+	// ImportConflictOverwrite replaces the existing wallets of the same filename and the same fingerprint,
+	// whether they are loaded or only their file is in the wallet directory.
+	// The password of an encrypted existing wallet is required to replace it, and a backup of its file is made.
+	// An existing wallet that has a spending policy is never replaced.
+	// A wallet that has the filename of an existing wallet with another fingerprint, or the fingerprint
+	// of a loaded wallet of another filename, still aborts the import.
+	ImportConflictOverwrite ImportConflict = "overwrite"
+)
+
+// ImportConflictFromString converts a string to an ImportConflict, an empty string is ImportConflictError
+func ImportConflictFromString(s string) (ImportConflict, error) {
+	switch ImportConflict(s) {
+	case "", ImportConflictError:
+		return ImportConflictError, nil
+	case ImportConflictSkip, ImportConflictOverwrite:
+		return ImportConflict(s), nil
+	default:
+		return "", ErrInvalidImportConflict
+	}
+}
+
+// ImportBackupFilename returns the name of the file that holds the original data
+// of a wallet file that was overwritten by an import at the time
+func ImportBackupFilename(filename string, t time.Time) string {
+	return fmt.Sprintf("%s.import-%d.bak", filename, t.UnixNano())
+}
+
+// Archive is the content of a wallet archive
+type Archive struct {
+	Version int             `json:"version"`
+	Created int64           `json:"created"`
+	Wallets []ArchiveWallet `json:"wallets"`
+}
+
+// ArchiveWallet is a wallet bundled in an Archive
+type ArchiveWallet struct {
+	Filename string `json:"filename"`
+	Label    string `json:"label"`
+	// Data is the serialized wallet file
+	Data []byte `json:"data"`
+	// Notes are the transaction notes of the wallet's transactions, keyed by transaction ID
+	Notes map[string]string `json:"notes,omitempty"`
+}
+
+// archiveFile is the encrypted form of an Archive
+type archiveFile struct {
+	Version    int               `json:"version"`
+	CryptoType crypto.CryptoType `json:"crypto_type"`
+	// Checksum is the hex encoded SHA256 of the serialized Archive
+	Checksum string `json:"checksum"`
+	Data     []byte `json:"data"`
+}
+
+// EncryptArchive serializes an Archive and encrypts it with the password,
+// using the crypto of the given type
+func EncryptArchive(a Archive, password []byte, cryptoType crypto.CryptoType) ([]byte, error) {
+	if len(password) == 0 {
+		return nil, ErrMissingArchivePassword
+	}
+
+	cryptor, err := crypto.GetCrypto(cryptoType)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := cryptor.Encrypt(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(archiveFile{
+		Version:    a.Version,
+		CryptoType: cryptoType,
+		Checksum:   cipher.SumSHA256(data).Hex(),
+		Data:       encrypted,
+	}, "", "    ")
+}
+
+// DecryptArchive decrypts an archive created by EncryptArchive and verifies its checksum
+func DecryptArchive(data, password []byte) (*Archive, error) {
+	if len(password) == 0 {
+		return nil, ErrMissingArchivePassword
+	}
+
+	var af archiveFile
+	if err := json.Unmarshal(data, &af); err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	if af.Version == 0 || len(af.Data) == 0 {
+		return nil, ErrInvalidArchive
+	}
+
+	if af.Version > ArchiveVersion {
+		return nil, ErrUnsupportedArchiveVersion
+	}
+
+	cryptor, err := crypto.GetCrypto(af.CryptoType)
+	if err != nil {
+		return nil, NewError(err)
+	}
+
+	decrypted, err := cryptor.Decrypt(af.Data, password)
+	if err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	if cipher.SumSHA256(decrypted).Hex() != af.Checksum {
+		return nil, ErrArchiveChecksumMismatch
+	}
+
+	var a Archive
+	if err := json.Unmarshal(decrypted, &a); err != nil {
+		return nil, ErrInvalidArchive
+	}
+
+	if a.Version != af.Version {
+		return nil, ErrInvalidArchive
+	}
+
+	return &a, nil
+}
+
+// newArchiveWallet serializes a wallet for an Archive
+func newArchiveWallet(w Wallet, notes map[string]string) (*ArchiveWallet, error) {
+	data, err := w.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ArchiveWallet{
+		Filename: w.Filename(),
+		Label:    w.Label(),
+		Data:     data,
+		Notes:    notes,
+	}, nil
+}
+
+// loadArchiveWallet loads a wallet of an Archive with the loader of its wallet type
+func loadArchiveWallet(aw ArchiveWallet) (Wallet, error) {
+	if aw.Filename == "" || filepath.Base(aw.Filename) != aw.Filename || !strings.HasSuffix(aw.Filename, WalletExt) {
+		return nil, NewError(fmt.Errorf("archive wallet has invalid filename %q", aw.Filename))
+	}
+
+	var m walletLoadMeta
+	if err := json.Unmarshal(aw.Data, &m); err != nil {
+		return nil, NewError(fmt.Errorf("archive wallet %q is invalid: %v", aw.Filename, err))
+	}
+
+	l, ok := getLoader(m.Meta.Type)
+	if !ok {
+		return nil, NewError(fmt.Errorf("archive wallet %q has unsupported wallet type %q", aw.Filename, m.Meta.Type))
+	}
+
+	w, err := l.Load(aw.Data)
+	if err != nil {
+		return nil, NewError(fmt.Errorf("archive wallet %q is invalid: %v", aw.Filename, err))
+	}
+
+	if w.Coin() != CoinTypeSkycoin {
+		return nil, NewError(fmt.Errorf("archive wallet %q is a %s wallet, only skycoin wallets are supported", aw.Filename, w.Coin()))
+	}
+
+	w.SetFilename(aw.Filename)
+	return w, nil
+}
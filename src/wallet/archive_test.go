@@ -0,0 +1,91 @@
+package wallet
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher/crypto"
+)
+
+func TestEncryptDecryptArchive(t *testing.T) {
+	a := Archive{
+		Version: ArchiveVersion,
+		Created: 1600000000,
+		Wallets: []ArchiveWallet{
+			{
+				Filename: "test.wlt",
+				Label:    "test",
+				Data:     []byte(`{"meta":{"type":"deterministic"}}`),
+				Notes: map[string]string{
+					"52d5e3b2a5bbcd9df5ab5ea5f9d6ff36ee3b53a4d39b8a8b9ac5e5ccdde3a4bb": "rent",
+				},
+			},
+		},
+	}
+
+	password := []byte("pwd")
+
+	for _, ct := range crypto.TypesInsecure() {
+		t.Run(string(ct), func(t *testing.T) {
+			data, err := EncryptArchive(a, password, ct)
+			require.NoError(t, err)
+
+			// The archive content is not stored in plain text
+			require.NotContains(t, string(data), "rent")
+
+			a2, err := DecryptArchive(data, password)
+			require.NoError(t, err)
+			require.Equal(t, a, *a2)
+
+			_, err = DecryptArchive(data, []byte("wrong"))
+			require.Equal(t, ErrInvalidPassword, err)
+
+			_, err = DecryptArchive(data, nil)
+			require.Equal(t, ErrMissingArchivePassword, err)
+
+			var af archiveFile
+			require.NoError(t, json.Unmarshal(data, &af))
+			require.Equal(t, ct, af.CryptoType)
+
+			// A tampered checksum is detected
+			badChecksum := af
+			badChecksum.Checksum = "00"
+			badData, err := json.Marshal(badChecksum)
+			require.NoError(t, err)
+			_, err = DecryptArchive(badData, password)
+			require.Equal(t, ErrArchiveChecksumMismatch, err)
+
+			// Archives of a newer format version are rejected
+			newer := af
+			newer.Version = ArchiveVersion + 1
+			newerData, err := json.Marshal(newer)
+			require.NoError(t, err)
+			_, err = DecryptArchive(newerData, password)
+			require.Equal(t, ErrUnsupportedArchiveVersion, err)
+		})
+	}
+
+	_, err := EncryptArchive(a, nil, crypto.CryptoTypeScryptChacha20poly1305Insecure)
+	require.Equal(t, ErrMissingArchivePassword, err)
+
+	_, err = DecryptArchive([]byte("not an archive"), password)
+	require.Equal(t, ErrInvalidArchive, err)
+}
+
+func TestImportConflictFromString(t *testing.T) {
+	for s, expect := range map[string]ImportConflict{
+		"":          ImportConflictError,
+		"error":     ImportConflictError,
+		"skip":      ImportConflictSkip,
+		"overwrite": ImportConflictOverwrite,
+	} {
+		c, err := ImportConflictFromString(s)
+		require.NoError(t, err)
+		require.Equal(t, expect, c)
+	}
+
+	_, err := ImportConflictFromString("rename")
+	require.Equal(t, ErrInvalidImportConflict, err)
+}
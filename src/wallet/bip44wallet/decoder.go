@@ -199,15 +199,23 @@ func newBip44EntryFromReadable(re readableBip44Entry, d wallet.AddressSecKeyDeco
 		Public:      p,
 		Secret:      secKey,
 		ChildNumber: re.ChildNumber,
+		EntryMeta: wallet.EntryMeta{
+			Label: re.Label,
+			Tags:  re.Tags,
+			Note:  re.Note,
+		},
 	}, nil
 }
 
 // ReadableBip44Entry bip44 entry with JSON tags
 type readableBip44Entry struct {
-	Address     string `json:"address"`
-	Public      string `json:"public"`
-	Secret      string `json:"secret"`
-	ChildNumber uint32 `json:"child_number"` // For bip32/bip44
+	Address     string   `json:"address"`
+	Public      string   `json:"public"`
+	Secret      string   `json:"secret"`
+	ChildNumber uint32   `json:"child_number"` // For bip32/bip44
+	Label       string   `json:"label,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Note        string   `json:"note,omitempty"`
 }
 
 // newReadableBip44Accounts converts bip44Accounts to ReadableBip44Accounts
@@ -259,6 +267,9 @@ func newReadableBip44Chains(cs []bip44Chain, d wallet.SecKeyDecoder) ([]readable
 				Public:      e.Public.Hex(),
 				ChildNumber: e.ChildNumber,
 				Secret:      secret,
+				Label:       e.Label,
+				Tags:        e.Tags,
+				Note:        e.Note,
 			})
 		}
 		rcs = append(rcs, rc)
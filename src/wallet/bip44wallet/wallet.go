@@ -253,6 +253,17 @@ func (w *Wallet) NewAccount(name string) (uint32, error) {
 	})
 }
 
+// SetAccountName renames the account of given index
+func (w *Wallet) SetAccountName(index uint32, name string) error {
+	a, err := w.accountManager.account(index)
+	if err != nil {
+		return err
+	}
+
+	a.Name = name
+	return nil
+}
+
 // newExternalAddresses generates addresses on external chain of selected account
 func (w *Wallet) newExternalAddresses(account, n uint32) ([]cipher.Addresser, error) {
 	return w.newAddresses(account, bip44.ExternalChainIndex, n)
@@ -597,7 +608,8 @@ func getBip44Options(options ...wallet.Option) *wallet.Bip44EntriesOptions {
 }
 
 // ScanAddresses scans both the external and change addresses to find addresses with
-// transactions.
+// transactions. scanN is the gap limit: scanning of a chain stops once scanN consecutive
+// addresses after the last active one have no activity.
 // Only external addresses will be returned.
 func (w *Wallet) ScanAddresses(scanN uint64, tf wallet.TransactionsFinder) ([]cipher.Addresser, error) {
 	if scanN == 0 {
@@ -613,28 +625,36 @@ func (w *Wallet) ScanAddresses(scanN uint64, tf wallet.TransactionsFinder) ([]ci
 			return nil, 0, 0, err
 		}
 
-		// generates the addresses  to scan
-		addrs, err := w2.accountManager.newAddresses(account, chain, uint32(scanN))
-		if err != nil {
-			return nil, 0, 0, err
-		}
+		// keeps scanning until scanN addresses past the last active one have no activity
+		var scannedAddrs []cipher.Addresser
+		var keepNum uint64
+		for uint64(len(scannedAddrs))-keepNum < scanN {
+			n := scanN - (uint64(len(scannedAddrs)) - keepNum)
 
-		// finds if these addresses had any activity
-		active, err := tf.AddressesActivity(addrs)
-		if err != nil {
-			return nil, 0, 0, err
-		}
+			// generates the addresses to scan
+			addrs, err := w2.accountManager.newAddresses(account, chain, uint32(n))
+			if err != nil {
+				return nil, 0, 0, err
+			}
 
-		// checks activity from the last one until we find the address that has activity
-		var keepNum uint64
-		for i := len(active) - 1; i >= 0; i-- {
-			if active[i] {
-				keepNum = uint64(i + 1)
-				break
+			// finds if these addresses had any activity
+			active, err := tf.AddressesActivity(addrs)
+			if err != nil {
+				return nil, 0, 0, err
+			}
+
+			// checks activity from the last one until we find the address that has activity
+			for i := len(active) - 1; i >= 0; i-- {
+				if active[i] {
+					keepNum = uint64(len(scannedAddrs) + i + 1)
+					break
+				}
 			}
+
+			scannedAddrs = append(scannedAddrs, addrs...)
 		}
 
-		return addrs[:keepNum], int(nExistingAddrs), int(keepNum), nil
+		return scannedAddrs[:keepNum], int(nExistingAddrs), int(keepNum), nil
 	}
 
 	// [accounts][chains] array
@@ -661,6 +681,12 @@ func (w *Wallet) ScanAddresses(scanN uint64, tf wallet.TransactionsFinder) ([]ci
 		generateAddresses[i] = append(generateAddresses[i], uint32(initLen+keepNum))
 	}
 
+	// Keep the metadata of the existing entries
+	metas, err := w2.entryMetas()
+	if err != nil {
+		return nil, err
+	}
+
 	w2.reset()
 	for i, a := range accounts {
 		// generate addresses on external chains
@@ -672,6 +698,10 @@ func (w *Wallet) ScanAddresses(scanN uint64, tf wallet.TransactionsFinder) ([]ci
 		}
 	}
 
+	if err := w2.setEntryMetas(metas); err != nil {
+		return nil, err
+	}
+
 	*w = *w2
 
 	return retAddrs, nil
@@ -741,6 +771,60 @@ func (w *Wallet) GetEntry(addr cipher.Addresser, options ...wallet.Option) (wall
 	return e, nil
 }
 
+// SetEntryMeta sets the label, tags and note of the entry of given address,
+// the entries of all accounts and chains are searched.
+func (w *Wallet) SetEntryMeta(addr cipher.Addresser, m wallet.EntryMeta) error {
+	for _, a := range w.accountManager.all() {
+		act, err := w.accountManager.account(a.Index)
+		if err != nil {
+			return err
+		}
+
+		for i := range act.Chains {
+			if act.Chains[i].Entries.SetMeta(addr, m) {
+				return nil
+			}
+		}
+	}
+
+	return wallet.ErrEntryNotFound
+}
+
+// entryMetas returns the metadata of the entries of all accounts and chains that have any, keyed by address
+func (w *Wallet) entryMetas() (map[cipher.Addresser]wallet.EntryMeta, error) {
+	metas := make(map[cipher.Addresser]wallet.EntryMeta)
+	for _, a := range w.accountManager.all() {
+		act, err := w.accountManager.account(a.Index)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, c := range act.Chains {
+			for addr, m := range c.Entries.Metas() {
+				metas[addr] = m
+			}
+		}
+	}
+
+	return metas, nil
+}
+
+// setEntryMetas sets the metadata of the entries of all accounts and chains of the addresses in metas
+func (w *Wallet) setEntryMetas(metas map[cipher.Addresser]wallet.EntryMeta) error {
+	for _, a := range w.accountManager.all() {
+		act, err := w.accountManager.account(a.Index)
+		if err != nil {
+			return err
+		}
+
+		for i := range act.Chains {
+			act.Chains[i].Entries.SetMetas(metas)
+		}
+	}
+
+	return nil
+}
+
 // HasEntry checks whether the entry of given address exists on selected account and chain,
 // if no options are provided, check the external chain of account 0.
 func (w *Wallet) HasEntry(addr cipher.Addresser, options ...wallet.Option) (bool, error) {
@@ -787,16 +871,19 @@ func (w *Wallet) reset() {
 
 // PeekChangeAddress returns the last entry address on change chain if
 // no transactions are found, otherwise, returns with a new address.
-func (w *Wallet) PeekChangeAddress(tf wallet.TransactionsFinder) (cipher.Addresser, error) {
+// The account can be selected with wallet.OptionAccount, defaults to account 0.
+func (w *Wallet) PeekChangeAddress(tf wallet.TransactionsFinder, options ...wallet.Option) (cipher.Addresser, error) {
+	opts := getBip44Options(options...)
+	onAccount := wallet.OptionAccount(opts.Account)
 	onChangeChain := wallet.OptionChange()
-	entries, err := w.GetEntries(onChangeChain)
+	entries, err := w.GetEntries(onAccount, onChangeChain)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(entries) == 0 {
 		// generate a new address and return
-		addrs, err := w.GenerateAddresses(wallet.OptionGenerateN(1), onChangeChain)
+		addrs, err := w.GenerateAddresses(wallet.OptionGenerateN(1), onAccount, onChangeChain)
 		if err != nil {
 			return nil, err
 		}
@@ -816,7 +903,7 @@ func (w *Wallet) PeekChangeAddress(tf wallet.TransactionsFinder) (cipher.Address
 	}
 
 	// generate a new address and return it
-	addrs, err := w.GenerateAddresses(wallet.OptionGenerateN(1), onChangeChain)
+	addrs, err := w.GenerateAddresses(wallet.OptionGenerateN(1), onAccount, onChangeChain)
 	if err != nil {
 		return nil, err
 	}
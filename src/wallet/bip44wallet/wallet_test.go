@@ -686,6 +686,35 @@ func TestWalletCreateAccount(t *testing.T) {
 	require.Equal(t, uint32(3), w.accountManager.len())
 }
 
+func TestWalletSetAccountName(t *testing.T) {
+	w, err := NewWallet(
+		"test.wlt",
+		"test",
+		testSeed,
+		testSeedPassphrase,
+		wallet.OptionCoinType(wallet.CoinTypeSkycoin))
+	require.NoError(t, err)
+
+	_, err = w.NewAccount("account1")
+	require.NoError(t, err)
+
+	require.NoError(t, w.SetAccountName(1, "customer1"))
+	require.Equal(t, []wallet.Bip44Account{
+		{Name: DefaultAccountName, Index: 0},
+		{Name: "customer1", Index: 1},
+	}, w.Accounts())
+
+	err = w.SetAccountName(2, "customer2")
+	require.EqualError(t, err, "account index 2 out of range")
+
+	// The account name survives serialization
+	b, err := w.Serialize()
+	require.NoError(t, err)
+	w2 := &Wallet{}
+	require.NoError(t, w2.Deserialize(b))
+	require.Equal(t, w.Accounts(), w2.Accounts())
+}
+
 func TestWalletAccountCreateAddresses(t *testing.T) {
 	w, err := NewWallet(
 		"test.wlt",
@@ -888,6 +917,31 @@ func TestPeekChangeAddress(t *testing.T) {
 	require.Equal(t, skycoinChangeAddrs[2], addr)
 }
 
+func TestPeekChangeAddressAccount(t *testing.T) {
+	w, err := NewWallet("test.wlt", "test", testSeed, testSeedPassphrase)
+	require.NoError(t, err)
+
+	_, err = w.NewAccount("account1")
+	require.NoError(t, err)
+
+	// The new account has no change address yet, so one is generated on it
+	addr, err := w.PeekChangeAddress(mockTxnsFinder{}, wallet.OptionAccount(1))
+	require.NoError(t, err)
+	require.NotEqual(t, skycoinChangeAddrs[0], addr)
+
+	changeAddrs, err := w.GetAddresses(wallet.OptionAccount(1), wallet.OptionChange())
+	require.NoError(t, err)
+	require.Equal(t, []cipher.Addresser{addr}, changeAddrs)
+
+	// Account 0 is unaffected
+	changeAddrs, err = w.GetAddresses(wallet.OptionChange())
+	require.NoError(t, err)
+	require.Equal(t, []cipher.Addresser{skycoinChangeAddrs[0]}, changeAddrs)
+
+	_, err = w.PeekChangeAddress(mockTxnsFinder{}, wallet.OptionAccount(2))
+	require.Error(t, err)
+}
+
 func TestScanAddresses(t *testing.T) {
 	eAddrs := skycoinExternalAddrs
 	cAddrs := skycoinChangeAddrs
@@ -972,6 +1026,29 @@ func TestScanAddresses(t *testing.T) {
 			expectAddrs:          eAddrs[1:5],
 			expectAllChangeAddrs: cAddrs[:5],
 		},
+		{
+			name:  "gap limit extends the scan past active addresses",
+			scanN: 2,
+			txnFinder: mockTxnsFinder{
+				eAddrs[2]: true,
+				eAddrs[4]: true,
+				cAddrs[1]: true,
+				cAddrs[3]: true,
+			},
+			expectAddrs:          eAddrs[1:5],
+			expectAllChangeAddrs: cAddrs[:4],
+		},
+		{
+			name:  "gap limit reached",
+			scanN: 1,
+			txnFinder: mockTxnsFinder{
+				eAddrs[1]: true,
+				eAddrs[3]: true,
+				cAddrs[2]: true,
+			},
+			expectAddrs:          eAddrs[1:2],
+			expectAllChangeAddrs: cAddrs[:1],
+		},
 	}
 
 	for _, tc := range tt {
@@ -1002,3 +1079,58 @@ func getExternalAddrs(t *testing.T) []cipher.Addresser {
 func getChangeAddrs(t *testing.T) []cipher.Addresser {
 	return skycoinAddressStringsToAddress(testSkycoinChangeAddresses)
 }
+
+func TestWalletSetEntryMeta(t *testing.T) {
+	w, err := NewWallet("test.wlt", "test", testSeed, testSeedPassphrase,
+		wallet.OptionCryptoType(crypto.CryptoTypeSha256Xor))
+	require.NoError(t, err)
+
+	eAddr := skycoinExternalAddrs[0]
+	cAddr := skycoinChangeAddrs[0]
+
+	m := wallet.EntryMeta{
+		Label: "alice",
+		Tags:  []string{"customer", "invoice"},
+		Note:  "issued for invoice 42",
+	}
+	cm := wallet.EntryMeta{
+		Label: "change",
+	}
+	require.NoError(t, w.SetEntryMeta(eAddr, m))
+	require.NoError(t, w.SetEntryMeta(cAddr, cm))
+	require.Equal(t, wallet.ErrEntryNotFound, w.SetEntryMeta(skycoinExternalAddrs[1], m))
+
+	check := func(w wallet.Wallet) {
+		e, err := w.GetEntry(eAddr)
+		require.NoError(t, err)
+		require.Equal(t, m, e.EntryMeta)
+
+		e, err = w.GetEntry(cAddr, wallet.OptionChange())
+		require.NoError(t, err)
+		require.Equal(t, cm, e.EntryMeta)
+	}
+	check(w)
+
+	// The metadata stays readable when the wallet is encrypted
+	require.NoError(t, w.Lock([]byte("pwd")))
+	check(w)
+
+	uw, err := w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+	check(uw)
+
+	// The metadata survives serialization
+	b, err := w.Serialize()
+	require.NoError(t, err)
+	w2 := &Wallet{}
+	require.NoError(t, w2.Deserialize(b))
+	check(w2)
+
+	// The metadata survives scanning for new addresses
+	_, err = uw.ScanAddresses(5, mockTxnsFinder{skycoinExternalAddrs[2]: true})
+	require.NoError(t, err)
+	ok, err := uw.HasEntry(skycoinExternalAddrs[2])
+	require.NoError(t, err)
+	require.True(t, ok)
+	check(uw)
+}
@@ -396,6 +396,14 @@ func (w *Wallet) HasEntry(a cipher.Addresser, _ ...wallet.Option) (bool, error)
 	return w.entries.Has(a), nil
 }
 
+// SetEntryMeta sets the label, tags and note of the entry of given address
+func (w *Wallet) SetEntryMeta(a cipher.Addresser, m wallet.EntryMeta) error {
+	if !w.entries.SetMeta(a, m) {
+		return wallet.ErrEntryNotFound
+	}
+	return nil
+}
+
 // EntriesLen returns the number of entries in the wallet
 func (w *Wallet) EntriesLen(_ ...wallet.Option) (int, error) {
 	return len(w.entries), nil
@@ -655,3 +655,45 @@ func TestWalletDeserialize(t *testing.T) {
 		require.Equal(t, testSkycoinEntries[i], e)
 	}
 }
+
+func TestWalletSetEntryMeta(t *testing.T) {
+	w, err := NewWallet("test.wlt", "test", wallet.OptionCryptoType(crypto.CryptoTypeSha256Xor))
+	require.NoError(t, err)
+	for _, e := range testSkycoinEntries[:2] {
+		require.NoError(t, w.AddEntry(e))
+	}
+
+	addr := testSkycoinEntries[1].Address
+	m := wallet.EntryMeta{
+		Label: "alice",
+		Tags:  []string{"customer"},
+		Note:  "imported from the paper wallet",
+	}
+	require.NoError(t, w.SetEntryMeta(addr, m))
+	require.Equal(t, wallet.ErrEntryNotFound, w.SetEntryMeta(testSkycoinEntries[2].Address, m))
+
+	e, err := w.GetEntry(addr)
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	// The metadata stays readable when the wallet is encrypted
+	require.NoError(t, w.Lock([]byte("pwd")))
+	e, err = w.GetEntry(addr)
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	uw, err := w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+	e, err = uw.GetEntry(addr)
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	// The metadata survives serialization
+	b, err := w.Serialize()
+	require.NoError(t, err)
+	w2 := &Wallet{}
+	require.NoError(t, w2.Deserialize(b))
+	e, err = w2.GetEntry(addr)
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+}
@@ -29,14 +29,21 @@ func (d JSONDecoder) Decode(b []byte) (wallet.Wallet, error) {
 
 // readableEntry wallet entry with json tags
 type readableEntry struct {
-	Address string `json:"address"`
-	Public  string `json:"public_key"`
-	Secret  string `json:"secret_key"`
+	Address string   `json:"address"`
+	Public  string   `json:"public_key"`
+	Secret  string   `json:"secret_key"`
+	Label   string   `json:"label,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	Note    string   `json:"note,omitempty"`
 }
 
 // newReadableEntry creates readable wallet entry
 func newReadableEntry(coinType wallet.CoinType, e wallet.Entry) readableEntry {
-	re := readableEntry{}
+	re := readableEntry{
+		Label: e.Label,
+		Tags:  e.Tags,
+		Note:  e.Note,
+	}
 	if !e.Address.Null() {
 		re.Address = e.Address.String()
 	}
@@ -129,6 +136,11 @@ func newEntryFromReadable(coinType wallet.CoinType, re *readableEntry) (*wallet.
 		Address: a,
 		Public:  p,
 		Secret:  secret,
+		EntryMeta: wallet.EntryMeta{
+			Label: re.Label,
+			Tags:  re.Tags,
+			Note:  re.Note,
+		},
 	}, nil
 }
 
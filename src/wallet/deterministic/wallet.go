@@ -411,6 +411,9 @@ func (w *Wallet) ScanAddresses(scanN uint64, tf wallet.TransactionsFinder) ([]ci
 		}
 	}
 
+	// Keep the metadata of the existing entries
+	metas := w2.entries.Metas()
+
 	// Regenerate addresses up to nExistingAddrs + nAddAddrs.
 	// This is necessary to keep the lastSeed updated.
 	w2.reset()
@@ -422,6 +425,8 @@ func (w *Wallet) ScanAddresses(scanN uint64, tf wallet.TransactionsFinder) ([]ci
 		return nil, err
 	}
 
+	w2.entries.SetMetas(metas)
+
 	*w = *w2
 
 	return addrs[:keepNum], nil
@@ -500,6 +505,14 @@ func (w *Wallet) HasEntry(a cipher.Addresser, _ ...wallet.Option) (bool, error)
 	return w.entries.Has(a), nil
 }
 
+// SetEntryMeta sets the label, tags and note of the entry of given address
+func (w *Wallet) SetEntryMeta(a cipher.Addresser, m wallet.EntryMeta) error {
+	if !w.entries.SetMeta(a, m) {
+		return wallet.ErrEntryNotFound
+	}
+	return nil
+}
+
 // EntriesLen returns the number of entries in the wallet
 func (w *Wallet) EntriesLen(_ ...wallet.Option) (int, error) {
 	return len(w.entries), nil
@@ -11,6 +11,7 @@ import (
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/bip39"
 	"github.com/skycoin/skycoin/src/cipher/crypto"
+	"github.com/skycoin/skycoin/src/testutil"
 	"github.com/skycoin/skycoin/src/wallet"
 	"github.com/stretchr/testify/require"
 )
@@ -772,3 +773,62 @@ func TestWalletDeserialize(t *testing.T) {
 		require.Equal(t, testSkycoinEntries[i], e)
 	}
 }
+
+func TestWalletSetEntryMeta(t *testing.T) {
+	w, err := NewWallet("test.wlt", "test", testSeed, wallet.OptionCryptoType(crypto.CryptoTypeSha256Xor))
+	require.NoError(t, err)
+
+	addrs, err := w.GenerateAddresses(wallet.OptionGenerateN(2))
+	require.NoError(t, err)
+
+	m := wallet.EntryMeta{
+		Label: "alice",
+		Tags:  []string{"customer", "invoice"},
+		Note:  "issued for invoice 42",
+	}
+	require.NoError(t, w.SetEntryMeta(addrs[1], m))
+	require.Equal(t, wallet.ErrEntryNotFound, w.SetEntryMeta(testutil.MakeAddress(), m))
+
+	e, err := w.GetEntry(addrs[1])
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	// The entry owns a copy of the tags
+	e.Tags[0] = "changed"
+	e, err = w.GetEntry(addrs[1])
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	e, err = w.GetEntry(addrs[0])
+	require.NoError(t, err)
+	require.True(t, e.EntryMeta.IsEmpty())
+
+	// The metadata stays readable when the wallet is encrypted
+	require.NoError(t, w.Lock([]byte("pwd")))
+	e, err = w.GetEntry(addrs[1])
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	uw, err := w.Unlock([]byte("pwd"))
+	require.NoError(t, err)
+	e, err = uw.GetEntry(addrs[1])
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	// The metadata survives serialization
+	b, err := w.Serialize()
+	require.NoError(t, err)
+	w2 := &Wallet{}
+	require.NoError(t, w2.Deserialize(b))
+	e, err = w2.GetEntry(addrs[1])
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	// The metadata survives copying
+	w3, err := NewWallet("test2.wlt", "test", testSeed, wallet.OptionCryptoType(crypto.CryptoTypeSha256Xor))
+	require.NoError(t, err)
+	w3.CopyFromRef(uw)
+	e, err = w3.GetEntry(addrs[1])
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/skycoin/skycoin/src/cipher"
 )
@@ -15,6 +16,56 @@ type Entry struct {
 	Secret      cipher.SecKey
 	ChildNumber uint32 // For bip32/bip44
 	Change      uint32 // For bip44
+	EntryMeta
+}
+
+// EntryMeta is the user metadata of a wallet entry, it is not secret and stays readable when the wallet is encrypted
+type EntryMeta struct {
+	Label string   // Label of the address, e.g. the name of the customer it was issued to
+	Tags  []string // Tags of the address
+	Note  string   // Note about the address, e.g. why it was created
+}
+
+// Clone returns a copy of the entry metadata
+func (m EntryMeta) Clone() EntryMeta {
+	if len(m.Tags) > 0 {
+		m.Tags = append([]string{}, m.Tags...)
+	} else {
+		m.Tags = nil
+	}
+	return m
+}
+
+// Validate checks that the tags are not empty, do not contain a comma and are not duplicated
+func (m EntryMeta) Validate() error {
+	tags := make(map[string]struct{}, len(m.Tags))
+	for _, t := range m.Tags {
+		if strings.TrimSpace(t) == "" || strings.Contains(t, ",") {
+			return ErrInvalidEntryTag
+		}
+
+		if _, ok := tags[t]; ok {
+			return ErrDuplicateEntryTag
+		}
+		tags[t] = struct{}{}
+	}
+
+	return nil
+}
+
+// IsEmpty returns whether the entry metadata has no label, tags or note
+func (m EntryMeta) IsEmpty() bool {
+	return m.Label == "" && len(m.Tags) == 0 && m.Note == ""
+}
+
+// HasTag returns whether the entry metadata has the tag
+func (m EntryMeta) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // SkycoinAddress returns the Skycoin address of an entry. Panics if Address is not a Skycoin address
@@ -58,7 +109,12 @@ func (entries Entries) Clone() Entries {
 	if len(entries) == 0 {
 		return nil
 	}
-	return append(Entries{}, entries...)
+
+	es := append(Entries{}, entries...)
+	for i := range es {
+		es[i].EntryMeta = es[i].EntryMeta.Clone()
+	}
+	return es
 }
 
 // Has checks if entries contains the entry with specified address
@@ -77,12 +133,65 @@ func (entries Entries) Has(a cipher.Addresser) bool {
 func (entries Entries) Get(a cipher.Addresser) (Entry, bool) {
 	for _, e := range entries {
 		if e.Address == a {
+			e.EntryMeta = e.EntryMeta.Clone()
 			return e, true
 		}
 	}
 	return Entry{}, false
 }
 
+// SetMeta sets the metadata of the entry of specific address, returns false if the entry is not found
+func (entries Entries) SetMeta(a cipher.Addresser, m EntryMeta) bool {
+	for i, e := range entries {
+		if e.Address == a {
+			entries[i].EntryMeta = m.Clone()
+			return true
+		}
+	}
+	return false
+}
+
+// Metas returns the metadata of the entries that have any, keyed by address
+func (entries Entries) Metas() map[cipher.Addresser]EntryMeta {
+	metas := make(map[cipher.Addresser]EntryMeta)
+	for _, e := range entries {
+		if !e.EntryMeta.IsEmpty() {
+			metas[e.Address] = e.EntryMeta.Clone()
+		}
+	}
+	return metas
+}
+
+// SetMetas sets the metadata of the entries of the addresses in metas
+func (entries Entries) SetMetas(metas map[cipher.Addresser]EntryMeta) {
+	for i, e := range entries {
+		if m, ok := metas[e.Address]; ok {
+			entries[i].EntryMeta = m.Clone()
+		}
+	}
+}
+
+// FilterByMeta returns the entries whose label contains label, case insensitively, and that have the tag.
+// An empty label or tag matches all entries.
+func (entries Entries) FilterByMeta(label, tag string) Entries {
+	label = strings.ToLower(label)
+
+	var es Entries
+	for _, e := range entries {
+		if label != "" && !strings.Contains(strings.ToLower(e.Label), label) {
+			continue
+		}
+
+		if tag != "" && !e.HasTag(tag) {
+			continue
+		}
+
+		es = append(es, e)
+	}
+
+	return es
+}
+
 // GetAddresses returns all addresses
 func (entries Entries) GetAddresses() []cipher.Addresser {
 	addrs := make([]cipher.Addresser, len(entries))
@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+func TestEntryMetaValidate(t *testing.T) {
+	tt := []struct {
+		name string
+		meta EntryMeta
+		err  error
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			meta: EntryMeta{
+				Label: "alice",
+				Tags:  []string{"customer", "invoice 42"},
+				Note:  "note, with a comma",
+			},
+		},
+		{
+			name: "empty tag",
+			meta: EntryMeta{Tags: []string{"customer", " "}},
+			err:  ErrInvalidEntryTag,
+		},
+		{
+			name: "tag with comma",
+			meta: EntryMeta{Tags: []string{"a,b"}},
+			err:  ErrInvalidEntryTag,
+		},
+		{
+			name: "duplicate tag",
+			meta: EntryMeta{Tags: []string{"customer", "customer"}},
+			err:  ErrDuplicateEntryTag,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.err, tc.meta.Validate())
+		})
+	}
+}
+
+func TestEntryMetaClone(t *testing.T) {
+	m := EntryMeta{
+		Label: "alice",
+		Tags:  []string{"customer"},
+	}
+
+	cm := m.Clone()
+	require.Equal(t, m, cm)
+	cm.Tags[0] = "changed"
+	require.Equal(t, "customer", m.Tags[0])
+
+	// Empty tags are normalized to nil
+	require.Nil(t, EntryMeta{Tags: []string{}}.Clone().Tags)
+	require.True(t, EntryMeta{Tags: []string{}}.IsEmpty())
+	require.False(t, m.IsEmpty())
+	require.True(t, m.HasTag("customer"))
+	require.False(t, m.HasTag("cust"))
+}
+
+func TestEntriesMeta(t *testing.T) {
+	addrs := make([]cipher.Address, 3)
+	entries := make(Entries, 3)
+	for i := range entries {
+		pk, _ := cipher.GenerateKeyPair()
+		addrs[i] = cipher.AddressFromPubKey(pk)
+		entries[i] = Entry{
+			Address: addrs[i],
+			Public:  pk,
+		}
+	}
+
+	require.True(t, entries.SetMeta(addrs[0], EntryMeta{Label: "Alice", Tags: []string{"customer"}}))
+	require.True(t, entries.SetMeta(addrs[1], EntryMeta{Label: "Bob", Tags: []string{"customer", "vip"}}))
+	require.False(t, entries.SetMeta(cipher.Address{}, EntryMeta{Label: "nobody"}))
+
+	// Clone copies the tags
+	ce := entries.Clone()
+	ce[0].Tags[0] = "changed"
+	require.Equal(t, "customer", entries[0].Tags[0])
+
+	require.Equal(t, entries, entries.FilterByMeta("", ""))
+	require.Equal(t, Entries{entries[0]}, entries.FilterByMeta("ali", ""))
+	require.Equal(t, Entries{entries[1]}, entries.FilterByMeta("", "vip"))
+	require.Equal(t, Entries{entries[0], entries[1]}, entries.FilterByMeta("", "customer"))
+	require.Equal(t, Entries{entries[1]}, entries.FilterByMeta("BOB", "customer"))
+	require.Empty(t, entries.FilterByMeta("carol", ""))
+
+	metas := entries.Metas()
+	require.Len(t, metas, 2)
+
+	// The metadata can be restored after the entries are regenerated
+	regenerated := make(Entries, len(entries))
+	for i, e := range entries {
+		regenerated[i] = Entry{
+			Address: e.Address,
+			Public:  e.Public,
+		}
+	}
+	regenerated.SetMetas(metas)
+	require.Equal(t, entries, regenerated)
+}
@@ -1,7 +1,9 @@
 package wallet
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 
@@ -27,6 +29,8 @@ const (
 	MetaSeedPassphrase = "seedPassphrase" // seed passphrase [bip44 wallets]
 	MetaXPub           = "xpub"           // xpub key [xpub wallets]
 	MetaTemp           = "temp"           // whether the wallet is a temporary wallet
+	MetaSpendingPolicy = "spendingPolicy" // JSON encoded spending policy
+	MetaPolicySpends   = "policySpends"   // JSON encoded spends recorded by the spending policy
 )
 
 //const (
@@ -346,3 +350,62 @@ func (m Meta) IsTemp() bool {
 
 	return false
 }
+
+// SpendingPolicy returns the spending policy, nil if the wallet has no spending policy
+func (m Meta) SpendingPolicy() (*SpendingPolicy, error) {
+	s := m[MetaSpendingPolicy]
+	if s == "" {
+		return nil, nil
+	}
+
+	var p SpendingPolicy
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return nil, fmt.Errorf("invalid spending policy: %v", err)
+	}
+	return &p, nil
+}
+
+// SetSpendingPolicy sets the spending policy, a nil policy removes it
+func (m Meta) SetSpendingPolicy(p *SpendingPolicy) error {
+	if p == nil {
+		delete(m, MetaSpendingPolicy)
+		delete(m, MetaPolicySpends)
+		return nil
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	m[MetaSpendingPolicy] = string(b)
+	return nil
+}
+
+// PolicySpends returns the spends recorded by the spending policy
+func (m Meta) PolicySpends() ([]PolicySpend, error) {
+	s := m[MetaPolicySpends]
+	if s == "" {
+		return nil, nil
+	}
+
+	var spends []PolicySpend
+	if err := json.Unmarshal([]byte(s), &spends); err != nil {
+		return nil, fmt.Errorf("invalid policy spends: %v", err)
+	}
+	return spends, nil
+}
+
+// SetPolicySpends sets the spends recorded by the spending policy
+func (m Meta) SetPolicySpends(spends []PolicySpend) error {
+	if len(spends) == 0 {
+		delete(m, MetaPolicySpends)
+		return nil
+	}
+
+	b, err := json.Marshal(spends)
+	if err != nil {
+		return err
+	}
+	m[MetaPolicySpends] = string(b)
+	return nil
+}
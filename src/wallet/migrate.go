@@ -0,0 +1,328 @@
+package wallet
+
+// Migrations of wallet files created by older releases to the current wallet Version
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/skycoin/skycoin/src/util/file"
+)
+
+var (
+	// ErrMigrationBackupNotExist is returned when rolling back a migration whose backup file doesn't exist
+	ErrMigrationBackupNotExist = NewError(errors.New("wallet migration backup does not exist"))
+)
+
+var walletMigrations migrations
+
+func init() {
+	for _, m := range []Migration{
+		{
+			From:        "0.1",
+			To:          "0.2",
+			Description: "normalize the coin type and add the encryption fields",
+			Migrate:     migrateV01ToV02,
+		},
+		{
+			From:        "0.2",
+			To:          "0.3",
+			Description: "no changes to the wallet data, version 0.3 introduced bip44 wallets",
+			Migrate:     migrateVersionOnly,
+		},
+		{
+			From:        "0.3",
+			To:          "0.4",
+			Description: "no changes to the wallet data, version 0.4 introduced xpub wallets",
+			Migrate:     migrateVersionOnly,
+		},
+	} {
+		if err := RegisterMigration(m); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// MigrateFunc upgrades the decoded JSON of a wallet file in place
+// and returns a description of each change that was made
+type MigrateFunc func(w map[string]interface{}) ([]string, error)
+
+// Migration upgrades a wallet file from one version to the next one
+type Migration struct {
+	From        string
+	To          string
+	Description string
+	Migrate     MigrateFunc
+}
+
+// RegisterMigration registers a wallet Migration. Only one migration can start from a version.
+func RegisterMigration(m Migration) error {
+	return walletMigrations.add(m)
+}
+
+type migrations struct {
+	sync.Mutex
+	ms map[string]Migration
+}
+
+// add adds a new Migration
+func (ms *migrations) add(m Migration) error {
+	if m.From == "" || m.To == "" || m.From == m.To {
+		return fmt.Errorf("invalid wallet migration from %q to %q", m.From, m.To)
+	}
+
+	if m.Migrate == nil {
+		return fmt.Errorf("wallet migration from %s has no Migrate function", m.From)
+	}
+
+	ms.Lock()
+	defer ms.Unlock()
+	if ms.ms == nil {
+		ms.ms = map[string]Migration{}
+	}
+
+	if _, ok := ms.ms[m.From]; ok {
+		return fmt.Errorf("wallet migration from %s already exists", m.From)
+	}
+
+	ms.ms[m.From] = m
+	return nil
+}
+
+// path returns the migrations that upgrade a wallet from the version to the target version
+func (ms *migrations) path(from, to string) ([]Migration, error) {
+	ms.Lock()
+	defer ms.Unlock()
+
+	var path []Migration
+	seen := map[string]struct{}{}
+	for v := from; v != to; {
+		if _, ok := seen[v]; ok {
+			return nil, fmt.Errorf("wallet migrations from %s contain a cycle", from)
+		}
+		seen[v] = struct{}{}
+
+		m, ok := ms.ms[v]
+		if !ok {
+			return nil, NewError(fmt.Errorf("no wallet migration from version %q to %q", v, to))
+		}
+
+		path = append(path, m)
+		v = m.To
+	}
+
+	return path, nil
+}
+
+// MigrationStep is a migration that was applied to a wallet file
+type MigrationStep struct {
+	From        string   `json:"from"`
+	To          string   `json:"to"`
+	Description string   `json:"description"`
+	Changes     []string `json:"changes"`
+}
+
+// MigrationReport describes the migration of a wallet file
+type MigrationReport struct {
+	Filename    string          `json:"filename"`
+	FromVersion string          `json:"from_version"`
+	ToVersion   string          `json:"to_version"`
+	Steps       []MigrationStep `json:"steps"`
+	Backup      string          `json:"backup,omitempty"`
+	DryRun      bool            `json:"dry_run"`
+}
+
+// Migrated returns true if the wallet file was, or would be in a dry run, upgraded
+func (r MigrationReport) Migrated() bool {
+	return len(r.Steps) > 0
+}
+
+// MigrationBackupFilename returns the name of the file that holds the original data
+// of a wallet file that was migrated from the version
+func MigrationBackupFilename(filename, version string) string {
+	return fmt.Sprintf("%s.%s.bak", filename, version)
+}
+
+// migrateData applies the migrations from the version of the wallet data to the current Version.
+// The returned data is the original data if the wallet is already at the current Version.
+func migrateData(data []byte) ([]byte, *MigrationReport, error) {
+	var w map[string]interface{}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return nil, nil, err
+	}
+
+	meta, err := migrationMeta(w)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from, _ := meta[MetaVersion].(string)
+	if from == "" {
+		return nil, nil, NewError(errors.New("missing meta.version field"))
+	}
+
+	report := &MigrationReport{
+		FromVersion: from,
+		ToVersion:   Version,
+		Steps:       []MigrationStep{},
+	}
+
+	if from == Version {
+		return data, report, nil
+	}
+
+	path, err := walletMigrations.path(from, Version)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, m := range path {
+		changes, err := m.Migrate(w)
+		if err != nil {
+			return nil, nil, fmt.Errorf("wallet migration from %s to %s failed: %v", m.From, m.To, err)
+		}
+
+		meta, err := migrationMeta(w)
+		if err != nil {
+			return nil, nil, err
+		}
+		meta[MetaVersion] = m.To
+
+		report.Steps = append(report.Steps, MigrationStep{
+			From:        m.From,
+			To:          m.To,
+			Description: m.Description,
+			Changes:     append([]string{fmt.Sprintf("set version to %s", m.To)}, changes...),
+		})
+	}
+
+	migrated, err := json.Marshal(w)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return migrated, report, nil
+}
+
+// migrationMeta returns the meta object of the decoded JSON of a wallet file
+func migrationMeta(w map[string]interface{}) (map[string]interface{}, error) {
+	meta, ok := w["meta"].(map[string]interface{})
+	if !ok {
+		return nil, NewError(errors.New("missing meta field"))
+	}
+	return meta, nil
+}
+
+// MigrateFile upgrades a wallet file to the current Version. The original file is kept in
+// a backup file named by MigrationBackupFilename. If dryRun is true, the file is only
+// validated and the report describes the changes that would be made.
+func MigrateFile(filename string, dryRun bool) (*MigrationReport, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	migrated, report, err := migrateData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	report.Filename = filepath.Base(filename)
+	report.DryRun = dryRun
+
+	if !report.Migrated() {
+		return report, nil
+	}
+
+	// Make sure the migrated wallet can be loaded before touching the file
+	w, err := loadData(migrated)
+	if err != nil {
+		return nil, fmt.Errorf("migrated wallet is invalid: %v", err)
+	}
+	if w == nil {
+		return nil, fmt.Errorf("wallet type of %q is not supported", filename)
+	}
+
+	if dryRun {
+		return report, nil
+	}
+
+	backup := MigrationBackupFilename(filename, report.FromVersion)
+	if _, err := os.Stat(backup); err == nil {
+		return nil, fmt.Errorf("wallet migration backup %q already exists", backup)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := file.SaveBinary(backup, data, 0600); err != nil {
+		return nil, err
+	}
+
+	w.SetFilename(filepath.Base(filename))
+	if err := Save(w, filepath.Dir(filename)); err != nil {
+		return nil, err
+	}
+
+	report.Backup = filepath.Base(backup)
+
+	return report, nil
+}
+
+// RollbackMigration restores a wallet file from the backup that was made
+// when it was migrated from the version
+func RollbackMigration(filename, version string) error {
+	backup := MigrationBackupFilename(filename, version)
+	if _, err := os.Stat(backup); err != nil {
+		if os.IsNotExist(err) {
+			return ErrMigrationBackupNotExist
+		}
+		return err
+	}
+
+	return os.Rename(backup, filename)
+}
+
+// migrateVersionOnly is the migration between versions that have the same data layout
+func migrateVersionOnly(w map[string]interface{}) ([]string, error) {
+	return nil, nil
+}
+
+// migrateV01ToV02 normalizes the coin type and adds the encryption fields that were introduced in version 0.2
+func migrateV01ToV02(w map[string]interface{}) ([]string, error) {
+	meta, err := migrationMeta(w)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+
+	coin, _ := meta[MetaCoin].(string)
+	ct, err := ResolveCoinType(coin)
+	if err != nil {
+		return nil, err
+	}
+	if string(ct) != coin {
+		meta[MetaCoin] = string(ct)
+		changes = append(changes, fmt.Sprintf("set coin from %q to %q", coin, ct))
+	}
+
+	for _, k := range []string{MetaEncrypted, MetaCryptoType, MetaSecrets} {
+		if _, ok := meta[k]; ok {
+			continue
+		}
+
+		v := ""
+		if k == MetaEncrypted {
+			v = "false"
+		}
+		meta[k] = v
+		changes = append(changes, fmt.Sprintf("add %s %q", k, v))
+	}
+
+	return changes, nil
+}
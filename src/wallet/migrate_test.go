@@ -0,0 +1,329 @@
+package wallet_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher/crypto"
+	"github.com/skycoin/skycoin/src/wallet"
+)
+
+// copyWalletFile copies a wallet file of the testdata to a temporary directory
+func copyWalletFile(t *testing.T, filename string) string {
+	data, err := ioutil.ReadFile(filepath.Join("./testdata", filename))
+	require.NoError(t, err)
+
+	dir := prepareWltDir()
+	path := filepath.Join(dir, filename)
+	require.NoError(t, ioutil.WriteFile(path, data, 0600))
+	return path
+}
+
+func TestMigrateFile(t *testing.T) {
+	tt := []struct {
+		name        string
+		filename    string
+		fromVersion string
+		steps       []wallet.MigrationStep
+	}{
+		{
+			name:        "version 0.1",
+			filename:    "test2.wlt",
+			fromVersion: "0.1",
+			steps: []wallet.MigrationStep{
+				{
+					From:        "0.1",
+					To:          "0.2",
+					Description: "normalize the coin type and add the encryption fields",
+					Changes: []string{
+						"set version to 0.2",
+						`set coin from "sky" to "skycoin"`,
+						`add encrypted "false"`,
+						`add cryptoType ""`,
+						`add secrets ""`,
+					},
+				},
+				{
+					From:        "0.2",
+					To:          "0.3",
+					Description: "no changes to the wallet data, version 0.3 introduced bip44 wallets",
+					Changes:     []string{"set version to 0.3"},
+				},
+				{
+					From:        "0.3",
+					To:          "0.4",
+					Description: "no changes to the wallet data, version 0.4 introduced xpub wallets",
+					Changes:     []string{"set version to 0.4"},
+				},
+			},
+		},
+		{
+			name:        "version 0.2 encrypted",
+			filename:    "scrypt-chacha20poly1305-encrypted.wlt",
+			fromVersion: "0.2",
+			steps: []wallet.MigrationStep{
+				{
+					From:        "0.2",
+					To:          "0.3",
+					Description: "no changes to the wallet data, version 0.3 introduced bip44 wallets",
+					Changes:     []string{"set version to 0.3"},
+				},
+				{
+					From:        "0.3",
+					To:          "0.4",
+					Description: "no changes to the wallet data, version 0.4 introduced xpub wallets",
+					Changes:     []string{"set version to 0.4"},
+				},
+			},
+		},
+		{
+			name:        "current version",
+			filename:    "test5-bip44.wlt",
+			fromVersion: "0.4",
+			steps:       []wallet.MigrationStep{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			path := copyWalletFile(t, tc.filename)
+			defer os.RemoveAll(filepath.Dir(path))
+
+			original, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+
+			w, err := wallet.Load(path)
+			require.NoError(t, err)
+			require.Equal(t, wallet.Version, w.Version())
+			addrs, err := w.GetAddresses()
+			require.NoError(t, err)
+
+			// A dry run does not change the file
+			report, err := wallet.MigrateFile(path, true)
+			require.NoError(t, err)
+			require.Equal(t, &wallet.MigrationReport{
+				Filename:    tc.filename,
+				FromVersion: tc.fromVersion,
+				ToVersion:   wallet.Version,
+				Steps:       tc.steps,
+				DryRun:      true,
+			}, report)
+
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, original, data)
+
+			backup := wallet.MigrationBackupFilename(path, tc.fromVersion)
+			_, err = os.Stat(backup)
+			require.True(t, os.IsNotExist(err))
+
+			report, err = wallet.MigrateFile(path, false)
+			require.NoError(t, err)
+			require.Equal(t, len(tc.steps) > 0, report.Migrated())
+			require.False(t, report.DryRun)
+			require.Equal(t, tc.steps, report.Steps)
+
+			if !report.Migrated() {
+				require.Empty(t, report.Backup)
+				data, err := ioutil.ReadFile(path)
+				require.NoError(t, err)
+				require.Equal(t, original, data)
+
+				err = wallet.RollbackMigration(path, tc.fromVersion)
+				require.Equal(t, wallet.ErrMigrationBackupNotExist, err)
+				return
+			}
+
+			// The original file is kept as a backup
+			require.Equal(t, filepath.Base(backup), report.Backup)
+			data, err = ioutil.ReadFile(backup)
+			require.NoError(t, err)
+			require.Equal(t, original, data)
+
+			// The upgraded file holds the same wallet at the current version
+			w2, err := wallet.Load(path)
+			require.NoError(t, err)
+			require.Equal(t, wallet.Version, w2.Version())
+			require.Equal(t, wallet.CoinTypeSkycoin, w2.Coin())
+			require.Equal(t, w.IsEncrypted(), w2.IsEncrypted())
+			addrs2, err := w2.GetAddresses()
+			require.NoError(t, err)
+			require.Equal(t, addrs, addrs2)
+
+			// Migrating again does nothing
+			report, err = wallet.MigrateFile(path, false)
+			require.NoError(t, err)
+			require.False(t, report.Migrated())
+			require.Equal(t, wallet.Version, report.FromVersion)
+
+			// Rolling back restores the original file
+			require.NoError(t, wallet.RollbackMigration(path, tc.fromVersion))
+			data, err = ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, original, data)
+			_, err = os.Stat(backup)
+			require.True(t, os.IsNotExist(err))
+
+			err = wallet.RollbackMigration(path, tc.fromVersion)
+			require.Equal(t, wallet.ErrMigrationBackupNotExist, err)
+		})
+	}
+}
+
+func TestMigrateFileErrors(t *testing.T) {
+	tt := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "unknown version",
+			data: `{"meta":{"type":"deterministic","coin":"skycoin","version":"9.9"},"entries":[]}`,
+			err:  `no wallet migration from version "9.9" to "0.4"`,
+		},
+		{
+			name: "missing version",
+			data: `{"meta":{"type":"deterministic","coin":"skycoin"},"entries":[]}`,
+			err:  "missing meta.version field",
+		},
+		{
+			name: "missing meta",
+			data: `{"entries":[]}`,
+			err:  "missing meta field",
+		},
+		{
+			name: "invalid coin",
+			data: `{"meta":{"type":"deterministic","coin":"foo","version":"0.1"},"entries":[]}`,
+			err:  "wallet migration from 0.1 to 0.2 failed: invalid coin type",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := prepareWltDir()
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "foo.wlt")
+			require.NoError(t, ioutil.WriteFile(path, []byte(tc.data), 0600))
+
+			_, err := wallet.MigrateFile(path, true)
+			require.Error(t, err)
+			require.Equal(t, tc.err, err.Error())
+
+			_, err = wallet.MigrateFile(path, false)
+			require.Error(t, err)
+			require.Equal(t, tc.err, err.Error())
+
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Equal(t, tc.data, string(data))
+		})
+	}
+}
+
+func TestRegisterMigration(t *testing.T) {
+	noop := func(map[string]interface{}) ([]string, error) {
+		return nil, nil
+	}
+
+	err := wallet.RegisterMigration(wallet.Migration{From: "0.1", To: "0.2", Migrate: noop})
+	require.EqualError(t, err, "wallet migration from 0.1 already exists")
+
+	err = wallet.RegisterMigration(wallet.Migration{From: "0.5", To: "0.5", Migrate: noop})
+	require.EqualError(t, err, `invalid wallet migration from "0.5" to "0.5"`)
+
+	err = wallet.RegisterMigration(wallet.Migration{From: "0.5", To: "0.6"})
+	require.EqualError(t, err, "wallet migration from 0.5 has no Migrate function")
+}
+
+func TestServiceMigrateWallets(t *testing.T) {
+	for _, migrate := range []bool{true, false} {
+		t.Run(fmt.Sprintf("migrate=%v", migrate), func(t *testing.T) {
+			path := copyWalletFile(t, "test2.wlt")
+			dir := filepath.Dir(path)
+			defer os.RemoveAll(dir)
+
+			original, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+
+			s, err := wallet.NewService(wallet.Config{
+				WalletDir:       dir,
+				CryptoType:      crypto.DefaultCryptoType,
+				EnableWalletAPI: true,
+				MigrateWallets:  migrate,
+			})
+			require.NoError(t, err)
+
+			// Wallets are migrated in memory either way
+			w, err := s.GetWallet("test2.wlt")
+			require.NoError(t, err)
+			require.Equal(t, wallet.Version, w.Version())
+
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			_, err = os.Stat(wallet.MigrationBackupFilename(path, "0.1"))
+
+			if !migrate {
+				require.Equal(t, original, data)
+				require.True(t, os.IsNotExist(err))
+
+				// The first write of the wallet keeps the file of the older version in the migration backup
+				require.NoError(t, s.UpdateWalletLabel("test2.wlt", "changed"))
+				backup, err := ioutil.ReadFile(wallet.MigrationBackupFilename(path, "0.1"))
+				require.NoError(t, err)
+				require.Equal(t, original, backup)
+
+				w, err := wallet.Load(path)
+				require.NoError(t, err)
+				require.Equal(t, "changed", w.Label())
+				return
+			}
+
+			require.NotEqual(t, original, data)
+			require.NoError(t, err)
+
+			var m struct {
+				Meta map[string]string `json:"meta"`
+			}
+			require.NoError(t, json.Unmarshal(data, &m))
+			require.Equal(t, wallet.Version, m.Meta["version"])
+			require.Equal(t, "skycoin", m.Meta["coin"])
+		})
+	}
+
+	t.Run("backup exists", func(t *testing.T) {
+		path := copyWalletFile(t, "test2.wlt")
+		dir := filepath.Dir(path)
+		defer os.RemoveAll(dir)
+
+		original, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+
+		// The backup of an interrupted migration
+		backup := wallet.MigrationBackupFilename(path, "0.1")
+		require.NoError(t, ioutil.WriteFile(backup, original, 0600))
+
+		// The file is not migrated, but the service still loads the wallet
+		s, err := wallet.NewService(wallet.Config{
+			WalletDir:       dir,
+			CryptoType:      crypto.DefaultCryptoType,
+			EnableWalletAPI: true,
+			MigrateWallets:  true,
+		})
+		require.NoError(t, err)
+
+		w, err := s.GetWallet("test2.wlt")
+		require.NoError(t, err)
+		require.Equal(t, wallet.Version, w.Version())
+
+		data, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, original, data)
+	})
+}
@@ -412,6 +412,29 @@ func (_m *MockWallet) Lock(password []byte) error {
 	return r0
 }
 
+// PolicySpends provides a mock function with given fields:
+func (_m *MockWallet) PolicySpends() ([]PolicySpend, error) {
+	ret := _m.Called()
+
+	var r0 []PolicySpend
+	if rf, ok := ret.Get(0).(func() []PolicySpend); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]PolicySpend)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ScanAddresses provides a mock function with given fields: scanN, tf
 func (_m *MockWallet) ScanAddresses(scanN uint64, tf TransactionsFinder) ([]cipher.Addresser, error) {
 	ret := _m.Called(scanN, tf)
@@ -520,6 +543,20 @@ func (_m *MockWallet) SetDecoder(d Decoder) {
 	_m.Called(d)
 }
 
+// SetEntryMeta provides a mock function with given fields: addr, m
+func (_m *MockWallet) SetEntryMeta(addr cipher.Addresser, m EntryMeta) error {
+	ret := _m.Called(addr, m)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(cipher.Addresser, EntryMeta) error); ok {
+		r0 = rf(addr, m)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetFilename provides a mock function with given fields: _a0
 func (_m *MockWallet) SetFilename(_a0 string) {
 	_m.Called(_a0)
@@ -530,6 +567,34 @@ func (_m *MockWallet) SetLabel(_a0 string) {
 	_m.Called(_a0)
 }
 
+// SetPolicySpends provides a mock function with given fields: spends
+func (_m *MockWallet) SetPolicySpends(spends []PolicySpend) error {
+	ret := _m.Called(spends)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]PolicySpend) error); ok {
+		r0 = rf(spends)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetSpendingPolicy provides a mock function with given fields: p
+func (_m *MockWallet) SetSpendingPolicy(p *SpendingPolicy) error {
+	ret := _m.Called(p)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*SpendingPolicy) error); ok {
+		r0 = rf(p)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SetTemp provides a mock function with given fields: temp
 func (_m *MockWallet) SetTemp(temp bool) {
 	_m.Called(temp)
@@ -540,6 +605,29 @@ func (_m *MockWallet) SetTimestamp(_a0 int64) {
 	_m.Called(_a0)
 }
 
+// SpendingPolicy provides a mock function with given fields:
+func (_m *MockWallet) SpendingPolicy() (*SpendingPolicy, error) {
+	ret := _m.Called()
+
+	var r0 *SpendingPolicy
+	if rf, ok := ret.Get(0).(func() *SpendingPolicy); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*SpendingPolicy)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Timestamp provides a mock function with given fields:
 func (_m *MockWallet) Timestamp() int64 {
 	ret := _m.Called()
@@ -0,0 +1,238 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+)
+
+// PolicySpendWindow is the period over which MaxCoinsPerDay is enforced
+const PolicySpendWindow = time.Hour * 24
+
+var (
+	// ErrPolicyTransactionLimit is returned when a transaction sends more coins than the policy allows per transaction
+	ErrPolicyTransactionLimit = NewError(errors.New("transaction exceeds the spending policy's per-transaction limit"))
+	// ErrPolicyDailyLimit is returned when a transaction would exceed the policy's daily limit
+	ErrPolicyDailyLimit = NewError(errors.New("transaction exceeds the spending policy's daily limit"))
+	// ErrPolicyDestinationNotAllowed is returned when a transaction sends coins to an address that is not in the policy's allow-list
+	ErrPolicyDestinationNotAllowed = NewError(errors.New("destination address is not allowed by the spending policy"))
+	// ErrPolicyDestinationCoolingDown is returned when a transaction sends coins to an allowed address that was added too recently
+	ErrPolicyDestinationCoolingDown = NewError(errors.New("destination address was added to the spending policy too recently"))
+	// ErrPolicyMinConfirmations is returned when a transaction spends outputs with fewer confirmations than the policy requires
+	ErrPolicyMinConfirmations = NewError(errors.New("transaction spends outputs with fewer confirmations than the spending policy requires"))
+	// ErrWalletHasSpendingPolicy is returned when decrypting a wallet that has a spending policy
+	ErrWalletHasSpendingPolicy = NewError(errors.New("wallet has a spending policy, remove it before decrypting the wallet"))
+	// ErrSpendNotAuthorized is returned when a transaction of a wallet with a spending policy was signed without checking the policy
+	ErrSpendNotAuthorized = errors.New("spend was not authorized by the spending policy")
+)
+
+// SpendingPolicy restricts the transactions signed by a wallet.
+// The zero value of each field disables the restriction.
+type SpendingPolicy struct {
+	// MaxCoinsPerTransaction is the maximum number of droplets sent to external addresses by a transaction
+	MaxCoinsPerTransaction uint64 `json:"max_coins_per_transaction,omitempty"`
+	// MaxCoinsPerDay is the maximum number of droplets sent to external addresses by the transactions signed in PolicySpendWindow
+	MaxCoinsPerDay uint64 `json:"max_coins_per_day,omitempty"`
+	// AllowedDestinations is the allow-list of external addresses. If empty, any address is allowed
+	AllowedDestinations []PolicyDestination `json:"allowed_destinations,omitempty"`
+	// DestinationCooldown is the number of seconds before an address added to AllowedDestinations can receive coins
+	DestinationCooldown uint64 `json:"destination_cooldown,omitempty"`
+	// MinConfirmations is the minimum number of confirmations of the outputs spent by a transaction
+	MinConfirmations uint64 `json:"min_confirmations,omitempty"`
+}
+
+// PolicyDestination is an address of the allow-list of a spending policy
+type PolicyDestination struct {
+	Address cipher.Address
+	// AddedAt is the unix time when the address was added to the allow-list
+	AddedAt int64
+}
+
+type policyDestinationJSON struct {
+	Address string `json:"address"`
+	AddedAt int64  `json:"added_at"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (d PolicyDestination) MarshalJSON() ([]byte, error) {
+	return json.Marshal(policyDestinationJSON{
+		Address: d.Address.String(),
+		AddedAt: d.AddedAt,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (d *PolicyDestination) UnmarshalJSON(b []byte) error {
+	var dj policyDestinationJSON
+	if err := json.Unmarshal(b, &dj); err != nil {
+		return err
+	}
+
+	addr, err := cipher.DecodeBase58Address(dj.Address)
+	if err != nil {
+		return err
+	}
+
+	d.Address = addr
+	d.AddedAt = dj.AddedAt
+	return nil
+}
+
+// PolicySpend records the coins sent to external addresses by a transaction signed under a spending policy
+type PolicySpend struct {
+	// Time is the unix time when the transaction was signed
+	Time  int64  `json:"time"`
+	Coins uint64 `json:"coins"`
+}
+
+// Spend describes a transaction that a wallet is about to sign, for checking it against a spending policy
+type Spend struct {
+	// Coins is the number of droplets sent to addresses outside of the wallet
+	Coins uint64
+	// Destinations are the addresses outside of the wallet that receive coins
+	Destinations []cipher.Address
+	// MinConfirmations is the lowest number of confirmations of the outputs spent
+	MinConfirmations uint64
+}
+
+// Validate validates the spending policy
+func (p SpendingPolicy) Validate() error {
+	if p.MaxCoinsPerTransaction != 0 && p.MaxCoinsPerDay != 0 && p.MaxCoinsPerTransaction > p.MaxCoinsPerDay {
+		return NewError(errors.New("max coins per transaction exceeds max coins per day"))
+	}
+
+	if p.DestinationCooldown != 0 && len(p.AllowedDestinations) == 0 {
+		return NewError(errors.New("destination cooldown requires allowed destinations"))
+	}
+
+	addrs := make(map[cipher.Address]struct{}, len(p.AllowedDestinations))
+	for _, d := range p.AllowedDestinations {
+		if d.Address.Null() {
+			return NewError(errors.New("allowed destination is a null address"))
+		}
+
+		if _, ok := addrs[d.Address]; ok {
+			return NewError(fmt.Errorf("duplicate allowed destination %s", d.Address))
+		}
+		addrs[d.Address] = struct{}{}
+	}
+
+	return nil
+}
+
+// Check returns an error if the spend is not allowed by the policy at time now,
+// given the spends recorded by the policy in the last PolicySpendWindow
+func (p SpendingPolicy) Check(spends []PolicySpend, s Spend, now time.Time) error {
+	if s.MinConfirmations < p.MinConfirmations {
+		return ErrPolicyMinConfirmations
+	}
+
+	if len(p.AllowedDestinations) != 0 {
+		allowed := make(map[cipher.Address]int64, len(p.AllowedDestinations))
+		for _, d := range p.AllowedDestinations {
+			allowed[d.Address] = d.AddedAt
+		}
+
+		for _, a := range s.Destinations {
+			addedAt, ok := allowed[a]
+			if !ok {
+				return ErrPolicyDestinationNotAllowed
+			}
+
+			if now.Unix()-addedAt < int64(p.DestinationCooldown) {
+				return ErrPolicyDestinationCoolingDown
+			}
+		}
+	}
+
+	if p.MaxCoinsPerTransaction != 0 && s.Coins > p.MaxCoinsPerTransaction {
+		return ErrPolicyTransactionLimit
+	}
+
+	if p.MaxCoinsPerDay != 0 {
+		total := s.Coins
+		for _, ps := range prunePolicySpends(spends, now) {
+			total += ps.Coins
+			if total < ps.Coins {
+				return ErrPolicyDailyLimit
+			}
+		}
+
+		if total > p.MaxCoinsPerDay {
+			return ErrPolicyDailyLimit
+		}
+	}
+
+	return nil
+}
+
+// prunePolicySpends returns the spends made within PolicySpendWindow before now
+func prunePolicySpends(spends []PolicySpend, now time.Time) []PolicySpend {
+	since := now.Add(-PolicySpendWindow).Unix()
+	var pruned []PolicySpend
+	for _, s := range spends {
+		if s.Time > since {
+			pruned = append(pruned, s)
+		}
+	}
+	return pruned
+}
+
+// updatePolicyDestinations sets the AddedAt time of the allowed destinations of p.
+// Destinations already allowed by the previous policy keep their AddedAt time, new destinations are added at now.
+func updatePolicyDestinations(prev *SpendingPolicy, p *SpendingPolicy, now time.Time) {
+	addedAt := make(map[cipher.Address]int64)
+	if prev != nil {
+		for _, d := range prev.AllowedDestinations {
+			addedAt[d.Address] = d.AddedAt
+		}
+	}
+
+	for i, d := range p.AllowedDestinations {
+		if t, ok := addedAt[d.Address]; ok {
+			p.AllowedDestinations[i].AddedAt = t
+		} else {
+			p.AllowedDestinations[i].AddedAt = now.Unix()
+		}
+	}
+}
+
+// NewSpend creates the Spend of a transaction to be signed by the wallet.
+// Outputs sent to the addresses of the wallet, such as change, are not part of the spend.
+func NewSpend(w Wallet, txn *coin.Transaction, minConfirmations uint64) (Spend, error) {
+	entries, err := AllEntries(w)
+	if err != nil {
+		return Spend{}, err
+	}
+
+	own := make(map[cipher.Address]struct{}, len(entries))
+	for _, e := range entries {
+		own[e.SkycoinAddress()] = struct{}{}
+	}
+
+	s := Spend{
+		MinConfirmations: minConfirmations,
+	}
+	destinations := make(map[cipher.Address]struct{})
+	for _, o := range txn.Out {
+		if _, ok := own[o.Address]; ok {
+			continue
+		}
+
+		if _, ok := destinations[o.Address]; !ok {
+			destinations[o.Address] = struct{}{}
+			s.Destinations = append(s.Destinations, o.Address)
+		}
+
+		s.Coins += o.Coins
+		if s.Coins < o.Coins {
+			return Spend{}, NewError(errors.New("spend coins overflow"))
+		}
+	}
+
+	return s, nil
+}
@@ -0,0 +1,293 @@
+package wallet
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher"
+	"github.com/skycoin/skycoin/src/coin"
+	"github.com/skycoin/skycoin/src/testutil"
+)
+
+func TestSpendingPolicyValidate(t *testing.T) {
+	addr := testutil.MakeAddress()
+
+	tt := []struct {
+		name   string
+		policy SpendingPolicy
+		err    string
+	}{
+		{
+			name: "ok",
+			policy: SpendingPolicy{
+				MaxCoinsPerTransaction: 1e6,
+				MaxCoinsPerDay:         10e6,
+				AllowedDestinations:    []PolicyDestination{{Address: addr}},
+				DestinationCooldown:    3600,
+				MinConfirmations:       6,
+			},
+		},
+		{
+			name: "empty policy",
+		},
+		{
+			name: "per transaction limit exceeds daily limit",
+			policy: SpendingPolicy{
+				MaxCoinsPerTransaction: 10e6,
+				MaxCoinsPerDay:         1e6,
+			},
+			err: "max coins per transaction exceeds max coins per day",
+		},
+		{
+			name: "cooldown without allowed destinations",
+			policy: SpendingPolicy{
+				DestinationCooldown: 3600,
+			},
+			err: "destination cooldown requires allowed destinations",
+		},
+		{
+			name: "null destination",
+			policy: SpendingPolicy{
+				AllowedDestinations: []PolicyDestination{{}},
+			},
+			err: "allowed destination is a null address",
+		},
+		{
+			name: "duplicate destination",
+			policy: SpendingPolicy{
+				AllowedDestinations: []PolicyDestination{{Address: addr}, {Address: addr}},
+			},
+			err: "duplicate allowed destination " + addr.String(),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, NewError(errors.New(tc.err)), err)
+		})
+	}
+}
+
+func TestSpendingPolicyCheck(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	addr := testutil.MakeAddress()
+	newAddr := testutil.MakeAddress()
+
+	policy := SpendingPolicy{
+		MaxCoinsPerTransaction: 5e6,
+		MaxCoinsPerDay:         10e6,
+		AllowedDestinations: []PolicyDestination{
+			{Address: addr, AddedAt: now.Unix() - 7200},
+			{Address: newAddr, AddedAt: now.Unix() - 60},
+		},
+		DestinationCooldown: 3600,
+		MinConfirmations:    3,
+	}
+
+	spends := []PolicySpend{
+		// Outside of the window
+		{Time: now.Add(-PolicySpendWindow).Unix(), Coins: 5e6},
+		{Time: now.Add(-time.Hour).Unix(), Coins: 4e6},
+	}
+
+	tt := []struct {
+		name   string
+		policy SpendingPolicy
+		spend  Spend
+		err    error
+	}{
+		{
+			name:   "ok",
+			policy: policy,
+			spend: Spend{
+				Coins:            5e6,
+				Destinations:     []cipher.Address{addr},
+				MinConfirmations: 3,
+			},
+		},
+		{
+			name:   "no destinations",
+			policy: policy,
+			spend: Spend{
+				MinConfirmations: 3,
+			},
+		},
+		{
+			name: "empty policy",
+			spend: Spend{
+				Coins:        100e6,
+				Destinations: []cipher.Address{testutil.MakeAddress()},
+			},
+		},
+		{
+			name:   "not enough confirmations",
+			policy: policy,
+			spend: Spend{
+				Coins:            1e6,
+				Destinations:     []cipher.Address{addr},
+				MinConfirmations: 2,
+			},
+			err: ErrPolicyMinConfirmations,
+		},
+		{
+			name:   "destination not allowed",
+			policy: policy,
+			spend: Spend{
+				Coins:            1e6,
+				Destinations:     []cipher.Address{addr, testutil.MakeAddress()},
+				MinConfirmations: 3,
+			},
+			err: ErrPolicyDestinationNotAllowed,
+		},
+		{
+			name:   "destination cooling down",
+			policy: policy,
+			spend: Spend{
+				Coins:            1e6,
+				Destinations:     []cipher.Address{newAddr},
+				MinConfirmations: 3,
+			},
+			err: ErrPolicyDestinationCoolingDown,
+		},
+		{
+			name:   "transaction limit",
+			policy: policy,
+			spend: Spend{
+				Coins:            5e6 + 1,
+				Destinations:     []cipher.Address{addr},
+				MinConfirmations: 3,
+			},
+			err: ErrPolicyTransactionLimit,
+		},
+		{
+			name: "daily limit",
+			policy: SpendingPolicy{
+				MaxCoinsPerDay: 10e6,
+			},
+			spend: Spend{
+				Coins:        6e6 + 1,
+				Destinations: []cipher.Address{addr},
+			},
+			err: ErrPolicyDailyLimit,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Check(spends, tc.spend, now)
+			require.Equal(t, tc.err, err)
+		})
+	}
+}
+
+func TestPrunePolicySpends(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	spends := []PolicySpend{
+		{Time: now.Add(-PolicySpendWindow - time.Second).Unix(), Coins: 1},
+		{Time: now.Add(-PolicySpendWindow).Unix(), Coins: 2},
+		{Time: now.Add(-time.Second).Unix(), Coins: 3},
+		{Time: now.Unix(), Coins: 4},
+	}
+
+	require.Equal(t, spends[2:], prunePolicySpends(spends, now))
+	require.Empty(t, prunePolicySpends(nil, now))
+}
+
+func TestUpdatePolicyDestinations(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	a1 := testutil.MakeAddress()
+	a2 := testutil.MakeAddress()
+
+	prev := &SpendingPolicy{
+		AllowedDestinations: []PolicyDestination{{Address: a1, AddedAt: 100}},
+	}
+
+	p := &SpendingPolicy{
+		AllowedDestinations: []PolicyDestination{{Address: a2, AddedAt: 1}, {Address: a1}},
+	}
+	updatePolicyDestinations(prev, p, now)
+	require.Equal(t, []PolicyDestination{
+		{Address: a2, AddedAt: now.Unix()},
+		{Address: a1, AddedAt: 100},
+	}, p.AllowedDestinations)
+
+	p = &SpendingPolicy{
+		AllowedDestinations: []PolicyDestination{{Address: a1}},
+	}
+	updatePolicyDestinations(nil, p, now)
+	require.Equal(t, now.Unix(), p.AllowedDestinations[0].AddedAt)
+}
+
+func TestMetaSpendingPolicy(t *testing.T) {
+	m := Meta{}
+
+	p, err := m.SpendingPolicy()
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	spends, err := m.PolicySpends()
+	require.NoError(t, err)
+	require.Nil(t, spends)
+
+	policy := &SpendingPolicy{
+		MaxCoinsPerTransaction: 1e6,
+		MaxCoinsPerDay:         2e6,
+		AllowedDestinations:    []PolicyDestination{{Address: testutil.MakeAddress(), AddedAt: 100}},
+		DestinationCooldown:    60,
+		MinConfirmations:       2,
+	}
+	require.NoError(t, m.SetSpendingPolicy(policy))
+
+	p, err = m.SpendingPolicy()
+	require.NoError(t, err)
+	require.Equal(t, policy, p)
+
+	require.NoError(t, m.SetPolicySpends([]PolicySpend{{Time: 100, Coins: 1e6}}))
+	spends, err = m.PolicySpends()
+	require.NoError(t, err)
+	require.Equal(t, []PolicySpend{{Time: 100, Coins: 1e6}}, spends)
+
+	// Removing the policy removes its spends
+	require.NoError(t, m.SetSpendingPolicy(nil))
+	require.Empty(t, m)
+
+	m[MetaSpendingPolicy] = "{"
+	_, err = m.SpendingPolicy()
+	require.Error(t, err)
+}
+
+func TestNewSpend(t *testing.T) {
+	own := testutil.MakeAddress()
+	change := testutil.MakeAddress()
+	a1 := testutil.MakeAddress()
+	a2 := testutil.MakeAddress()
+
+	w := &MockWallet{}
+	w.On("Type").Return(WalletTypeDeterministic)
+	w.On("GetEntries").Return(Entries{{Address: own}, {Address: change}}, nil)
+
+	txn := &coin.Transaction{
+		Out: []coin.TransactionOutput{
+			{Address: a1, Coins: 1e6},
+			{Address: change, Coins: 5e6},
+			{Address: a2, Coins: 2e6},
+			{Address: a1, Coins: 3e6},
+		},
+	}
+
+	s, err := NewSpend(w, txn, 4)
+	require.NoError(t, err)
+	require.Equal(t, Spend{
+		Coins:            6e6,
+		Destinations:     []cipher.Address{a1, a2},
+		MinConfirmations: 4,
+	}, s)
+}
@@ -0,0 +1,46 @@
+package wallet
+
+import (
+	"strings"
+
+	"github.com/skycoin/skycoin/src/cipher/shamir"
+)
+
+// SplitSeed splits a wallet seed into n Shamir secret shares, any k of which
+// can recover the seed with CombineSeedShares. The seed passphrase is not included.
+func SplitSeed(seed string, n, k int) ([]string, error) {
+	if seed == "" {
+		return nil, ErrMissingSeed
+	}
+
+	shares, err := shamir.Split([]byte(seed), n, k)
+	if err != nil {
+		return nil, NewError(err)
+	}
+
+	ss := make([]string, len(shares))
+	for i, s := range shares {
+		ss[i] = s.String()
+	}
+
+	return ss, nil
+}
+
+// CombineSeedShares recovers a wallet seed from the Shamir secret shares created by SplitSeed
+func CombineSeedShares(shares []string) (string, error) {
+	ss := make([]shamir.Share, len(shares))
+	for i, s := range shares {
+		share, err := shamir.ParseShare(strings.TrimSpace(s))
+		if err != nil {
+			return "", NewError(err)
+		}
+		ss[i] = share
+	}
+
+	seed, err := shamir.Combine(ss)
+	if err != nil {
+		return "", NewError(err)
+	}
+
+	return string(seed), nil
+}
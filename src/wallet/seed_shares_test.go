@@ -0,0 +1,34 @@
+package wallet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skycoin/skycoin/src/cipher/bip39"
+	"github.com/skycoin/skycoin/src/cipher/shamir"
+)
+
+func TestSplitCombineSeed(t *testing.T) {
+	seed := bip39.MustNewDefaultMnemonic()
+
+	shares, err := SplitSeed(seed, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	s, err := CombineSeedShares([]string{shares[4], " " + shares[0] + "\n", shares[2]})
+	require.NoError(t, err)
+	require.Equal(t, seed, s)
+
+	_, err = CombineSeedShares(shares[:2])
+	require.Equal(t, NewError(shamir.ErrNotEnoughShares), err)
+
+	_, err = CombineSeedShares([]string{shares[0], shares[1], "foo"})
+	require.Equal(t, NewError(shamir.ErrInvalidShare), err)
+
+	_, err = SplitSeed("", 5, 3)
+	require.Equal(t, ErrMissingSeed, err)
+
+	_, err = SplitSeed(seed, 2, 3)
+	require.Equal(t, NewError(shamir.ErrInvalidThreshold), err)
+}
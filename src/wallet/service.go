@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
@@ -15,8 +16,17 @@ import (
 	"github.com/skycoin/skycoin/src/cipher/bip44"
 	"github.com/skycoin/skycoin/src/cipher/crypto"
 	"github.com/skycoin/skycoin/src/util/file"
+	"github.com/skycoin/skycoin/src/util/metrics"
 )
 
+var operationDuration = metrics.MustRegisterHistogram(metrics.NewHistogramVec("skycoin_wallet_operation_duration_seconds",
+	"Duration of wallet service operations, including the time spent waiting for the service lock", nil, "operation"))
+
+// observeOperation records the duration of a wallet service operation that started at start
+func observeOperation(op string, start time.Time) {
+	operationDuration.WithLabelValues(op).ObserveDuration(start)
+}
+
 // TransactionsFinder interface for finding address related transaction hashes
 type TransactionsFinder interface {
 	AddressesActivity(addrs []cipher.Addresser) ([]bool, error)
@@ -29,6 +39,10 @@ type Service struct {
 	config  Config
 	// fingerprints is used to check for duplicate deterministic wallets
 	fingerprints map[string]string
+
+	// sessionsMu guards sessions, the unlock sessions of wallets by wallet id
+	sessionsMu sync.Mutex
+	sessions   map[string]*unlockSession
 }
 
 // Config wallet service config
@@ -38,6 +52,10 @@ type Config struct {
 	EnableWalletAPI bool
 	EnableSeedAPI   bool
 	Bip44Coin       *bip44.CoinType
+	// MigrateWallets upgrades the wallet files of older versions when the service loads them.
+	// Wallets are migrated in memory regardless, this only controls whether the files are rewritten on load.
+	// A file that isn't migrated is kept in its migration backup when the wallet is first saved.
+	MigrateWallets bool
 }
 
 // NewConfig creates a default Config
@@ -49,6 +67,7 @@ func NewConfig() Config {
 		EnableWalletAPI: false,
 		EnableSeedAPI:   false,
 		Bip44Coin:       &bc,
+		MigrateWallets:  false,
 	}
 }
 
@@ -57,6 +76,7 @@ func NewService(c Config) (*Service, error) {
 	serv := &Service{
 		config:       c,
 		fingerprints: make(map[string]string),
+		sessions:     make(map[string]*unlockSession),
 	}
 
 	if !serv.config.EnableWalletAPI {
@@ -114,6 +134,9 @@ func (serv *Service) WalletDir() (string, error) {
 // SetEnableWalletAPI sets whether or not enables the wallet related APIs
 func (serv *Service) SetEnableWalletAPI(enable bool) {
 	serv.config.EnableWalletAPI = enable
+	if !enable {
+		serv.endAllSessions()
+	}
 }
 
 func (serv *Service) loadWallets() (Wallets, error) {
@@ -134,6 +157,22 @@ func (serv *Service) loadWallets() (Wallets, error) {
 			}
 
 			fullPath := filepath.Join(serv.config.WalletDir, name)
+			if serv.config.MigrateWallets {
+				// A failed migration, e.g. because the backup of an interrupted migration already exists,
+				// must not prevent the node from starting, the wallet is still migrated in memory by Load
+				report, err := MigrateFile(fullPath, false)
+				if err != nil {
+					logger.WithError(err).WithField("filename", fullPath).Warning("loadWallets: MigrateFile failed, the wallet file is not upgraded")
+				} else if report.Migrated() {
+					logger.WithFields(logrus.Fields{
+						"filename": fullPath,
+						"from":     report.FromVersion,
+						"to":       report.ToVersion,
+						"backup":   report.Backup,
+					}).Info("loadWallets: migrated wallet")
+				}
+			}
+
 			w, err := serv.Load(fullPath)
 			if err != nil {
 				logger.WithError(err).WithField("filename", fullPath).Error("loadWallets: loadWallet failed")
@@ -189,6 +228,8 @@ func (serv *Service) updateOptions(opts Options) Options {
 // CreateWallet creates a wallet with the given wallet file name and options.
 // A address will be automatically generated by default.
 func (serv *Service) CreateWallet(wltName string, options Options) (Wallet, error) {
+	defer observeOperation("CreateWallet", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -266,6 +307,8 @@ func (serv *Service) generateUniqueWalletFilename() string {
 
 // EncryptWallet encrypts wallet with password
 func (serv *Service) EncryptWallet(wltID string, password []byte) (Wallet, error) {
+	defer observeOperation("EncryptWallet", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -298,6 +341,8 @@ func (serv *Service) EncryptWallet(wltID string, password []byte) (Wallet, error
 // DecryptWallet decrypts wallet with password
 // TODO: this function will be deprecated in future.
 func (serv *Service) DecryptWallet(wltID string, password []byte) (Wallet, error) {
+	defer observeOperation("DecryptWallet", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -314,6 +359,15 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (Wallet, error
 		return nil, ErrWalletNotEncrypted
 	}
 
+	// The spending policy of a wallet can't be changed without a password
+	policy, err := w.SpendingPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		return nil, ErrWalletHasSpendingPolicy
+	}
+
 	// Unlocks the wallet
 	unlockWlt, err := w.Unlock(password)
 	if err != nil {
@@ -325,6 +379,11 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (Wallet, error
 		return nil, err
 	}
 
+	// The secrets are no longer encrypted, ends the unlock session
+	serv.sessionsMu.Lock()
+	serv.endSession(wltID, serv.sessions[wltID])
+	serv.sessionsMu.Unlock()
+
 	// Sets the decrypted wallet in memory
 	serv.wallets.set(unlockWlt)
 	return unlockWlt, nil
@@ -383,8 +442,11 @@ func (serv *Service) DecryptWallet(wltID string, password []byte) (Wallet, error
 // 	return addrs, nil
 // }
 
-// NewAddresses generate addresses
+// NewAddresses generate addresses.
+// The password of an encrypted wallet can be the token of its unlock session.
 func (serv *Service) NewAddresses(wltID string, password []byte, options ...Option) ([]cipher.Address, error) {
+	defer observeOperation("NewAddresses", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 
@@ -413,7 +475,7 @@ func (serv *Service) NewAddresses(wltID string, password []byte, options ...Opti
 				return nil, err
 			}
 		} else {
-			if err := GuardUpdate(w, password, f); err != nil {
+			if err := serv.guardUpdate(w, password, f); err != nil {
 				return nil, err
 			}
 		}
@@ -448,6 +510,8 @@ func (serv *Service) NewAddresses(wltID string, password []byte, options ...Opti
 
 // ScanAddresses scan ahead addresses to see if contains balance.
 func (serv *Service) ScanAddresses(wltID string, password []byte, num uint64, tf TransactionsFinder) ([]cipher.Address, error) {
+	defer observeOperation("ScanAddresses", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -477,7 +541,7 @@ func (serv *Service) ScanAddresses(wltID string, password []byte, num uint64, tf
 		}
 	} else {
 		if w.IsEncrypted() {
-			if err := GuardUpdate(w, password, f); err != nil {
+			if err := serv.guardUpdate(w, password, f); err != nil {
 				return nil, err
 			}
 		} else {
@@ -527,8 +591,62 @@ func (serv *Service) ScanAddresses(wltID string, password []byte, num uint64, tf
 // 	return w.GetSkycoinAddresses()
 // }
 
+// PeekChangeAddress returns an unused address on the change chain of the wallet.
+// A new change address is generated and saved if the last one has been used.
+// For bip44 wallets, the account can be selected with OptionAccount.
+// Returns ErrWalletNoChangeChain if the wallet does not implement ChangeAddressPeeker.
+func (serv *Service) PeekChangeAddress(wltID string, tf TransactionsFinder, options ...Option) (cipher.Address, error) {
+	defer observeOperation("PeekChangeAddress", time.Now())
+
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return cipher.Address{}, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return cipher.Address{}, err
+	}
+
+	p, ok := w.(ChangeAddressPeeker)
+	if !ok {
+		return cipher.Address{}, ErrWalletNoChangeChain
+	}
+
+	addr, err := p.PeekChangeAddress(tf, options...)
+	if err != nil {
+		return cipher.Address{}, err
+	}
+
+	skyAddr, ok := addr.(cipher.Address)
+	if !ok {
+		return cipher.Address{}, fmt.Errorf("change address %s is not a skycoin address", addr)
+	}
+
+	// Checks if the wallet file is writable
+	if !w.IsTemp() {
+		wf := filepath.Join(serv.config.WalletDir, w.Filename())
+		if !file.IsWritable(wf) {
+			return cipher.Address{}, ErrWalletPermission
+		}
+
+		// Saves the wallet to disk
+		if err := Save(w, serv.config.WalletDir); err != nil {
+			return cipher.Address{}, err
+		}
+	}
+
+	// Updates wallet in memory
+	serv.wallets.set(w)
+
+	return skyAddr, nil
+}
+
 // GetAddresses returns all addresses of the selected wallet
 func (serv *Service) GetAddresses(wltID string, options ...Option) ([]cipher.Address, error) {
+	defer observeOperation("GetAddresses", time.Now())
+
 	serv.RLock()
 	defer serv.RUnlock()
 	if !serv.config.EnableWalletAPI {
@@ -549,6 +667,8 @@ func (serv *Service) GetAddresses(wltID string, options ...Option) ([]cipher.Add
 
 // GetWallet returns wallet by id
 func (serv *Service) GetWallet(wltID string) (Wallet, error) {
+	defer observeOperation("GetWallet", time.Now())
+
 	serv.RLock()
 	defer serv.RUnlock()
 	if !serv.config.EnableWalletAPI {
@@ -569,6 +689,8 @@ func (serv *Service) getWallet(wltID string) (Wallet, error) {
 
 // GetWallets returns all wallet clones
 func (serv *Service) GetWallets() (Wallets, error) {
+	defer observeOperation("GetWallets", time.Now())
+
 	serv.RLock()
 	defer serv.RUnlock()
 	if !serv.config.EnableWalletAPI {
@@ -584,6 +706,8 @@ func (serv *Service) GetWallets() (Wallets, error) {
 
 // UpdateWalletLabel updates the wallet label
 func (serv *Service) UpdateWalletLabel(wltID, label string) error {
+	defer observeOperation("UpdateWalletLabel", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -605,8 +729,274 @@ func (serv *Service) UpdateWalletLabel(wltID, label string) error {
 	return nil
 }
 
+// CreateAccount creates a bip44 account in the wallet of given id.
+// The wallet seed is needed to derive the account, so the password must be provided if the wallet is encrypted.
+func (serv *Service) CreateAccount(wltID string, password []byte, name string) (Bip44Account, error) {
+	defer observeOperation("CreateAccount", time.Now())
+
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return Bip44Account{}, ErrWalletAPIDisabled
+	}
+
+	if name == "" {
+		return Bip44Account{}, ErrMissingAccountName
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return Bip44Account{}, err
+	}
+
+	if w.Type() != WalletTypeBip44 {
+		return Bip44Account{}, ErrWalletNotBip44
+	}
+
+	var index uint32
+	f := func(w Wallet) error {
+		am, ok := w.(Bip44AccountManager)
+		if !ok {
+			return ErrWalletNotBip44
+		}
+
+		var err error
+		index, err = am.NewAccount(name)
+		if err != nil {
+			return err
+		}
+
+		// Generates the first external and change addresses, the same as the default account of a new wallet
+		if _, err := w.GenerateAddresses(OptionGenerateN(1), OptionAccount(index)); err != nil {
+			return err
+		}
+
+		_, err = w.GenerateAddresses(OptionGenerateN(1), OptionAccount(index), OptionChange())
+		return err
+	}
+
+	if w.IsEncrypted() {
+		if err := serv.guardUpdate(w, password, f); err != nil {
+			return Bip44Account{}, err
+		}
+	} else {
+		if len(password) != 0 {
+			return Bip44Account{}, ErrWalletNotEncrypted
+		}
+
+		if err := f(w); err != nil {
+			return Bip44Account{}, err
+		}
+	}
+
+	if !w.IsTemp() {
+		wf := filepath.Join(serv.config.WalletDir, w.Filename())
+		if !file.IsWritable(wf) {
+			return Bip44Account{}, ErrWalletPermission
+		}
+
+		if err := Save(w, serv.config.WalletDir); err != nil {
+			return Bip44Account{}, err
+		}
+	}
+
+	serv.wallets.set(w)
+
+	return Bip44Account{
+		Name:  name,
+		Index: index,
+	}, nil
+}
+
+// GetAccounts returns the bip44 accounts of the wallet of given id
+func (serv *Service) GetAccounts(wltID string) ([]Bip44Account, error) {
+	defer observeOperation("GetAccounts", time.Now())
+
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.Type() != WalletTypeBip44 {
+		return nil, ErrWalletNotBip44
+	}
+
+	return w.Accounts(), nil
+}
+
+// UpdateAccountName renames a bip44 account of the wallet of given id
+func (serv *Service) UpdateAccountName(wltID string, account uint32, name string) error {
+	defer observeOperation("UpdateAccountName", time.Now())
+
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	if name == "" {
+		return ErrMissingAccountName
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return err
+	}
+
+	if err := ValidateBip44Account(w, account); err != nil {
+		return err
+	}
+
+	am, ok := w.(Bip44AccountManager)
+	if !ok {
+		return ErrWalletNotBip44
+	}
+
+	if err := am.SetAccountName(account, name); err != nil {
+		return err
+	}
+
+	if err := Save(w, serv.config.WalletDir); err != nil {
+		return err
+	}
+
+	serv.wallets.set(w)
+	return nil
+}
+
+// UpdateAddressMeta sets the label, tags and note of an address of the wallet of given id.
+// The metadata is not secret, so encrypted wallets don't need to be unlocked.
+func (serv *Service) UpdateAddressMeta(wltID string, addr cipher.Address, m EntryMeta) error {
+	defer observeOperation("UpdateAddressMeta", time.Now())
+
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return err
+	}
+
+	if err := w.SetEntryMeta(addr, m); err != nil {
+		if err == ErrEntryNotFound {
+			return ErrAddressNotInWallet
+		}
+		return err
+	}
+
+	if !w.IsTemp() {
+		wf := filepath.Join(serv.config.WalletDir, w.Filename())
+		if !file.IsWritable(wf) {
+			return ErrWalletPermission
+		}
+
+		if err := Save(w, serv.config.WalletDir); err != nil {
+			return err
+		}
+	}
+
+	serv.wallets.set(w)
+	return nil
+}
+
+// SetSpendingPolicy sets the spending policy of an encrypted wallet, a nil policy removes it.
+// The wallet password is required, so that a caller who can only sign transactions can't loosen the policy.
+// Allowed destinations that are new to the policy are added at the current time, for the destination cooldown.
+func (serv *Service) SetSpendingPolicy(wltID string, password []byte, p *SpendingPolicy) (*SpendingPolicy, error) {
+	defer observeOperation("SetSpendingPolicy", time.Now())
+
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if p != nil {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the password
+	if err := GuardView(w, password, func(Wallet) error { return nil }); err != nil {
+		return nil, err
+	}
+
+	prev, err := w.SpendingPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	if p != nil {
+		np := *p
+		np.AllowedDestinations = append([]PolicyDestination(nil), p.AllowedDestinations...)
+		updatePolicyDestinations(prev, &np, time.Now())
+		p = &np
+	}
+
+	if err := w.SetSpendingPolicy(p); err != nil {
+		return nil, err
+	}
+
+	if err := Save(w, serv.config.WalletDir); err != nil {
+		return nil, err
+	}
+
+	serv.wallets.set(w)
+	return p, nil
+}
+
+// GetSpendingPolicy returns the spending policy of a wallet and the spends recorded by it in the last PolicySpendWindow.
+// The policy is nil if the wallet has no spending policy.
+func (serv *Service) GetSpendingPolicy(wltID string) (*SpendingPolicy, []PolicySpend, error) {
+	defer observeOperation("GetSpendingPolicy", time.Now())
+
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, nil, ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p, err := w.SpendingPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	spends, err := w.PolicySpends()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return p, prunePolicySpends(spends, time.Now()), nil
+}
+
 // UnloadWallet removes wallet of given wallet id from the service
 func (serv *Service) UnloadWallet(wltID string) error {
+	defer observeOperation("UnloadWallet", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -620,10 +1010,286 @@ func (serv *Service) UnloadWallet(wltID string) error {
 		}
 	}
 
+	serv.sessionsMu.Lock()
+	serv.endSession(wltID, serv.sessions[wltID])
+	serv.sessionsMu.Unlock()
+
 	serv.wallets.remove(wltID)
 	return nil
 }
 
+// ExportWallets bundles the wallets of the given IDs and their transaction notes, keyed by
+// wallet ID, into a wallet archive, encrypted with the password. The wallets are archived as they are
+// stored, encrypted wallets remain encrypted with their own password.
+// Exporting an unencrypted wallet exposes its secrets, so it requires EnableSeedAPI.
+func (serv *Service) ExportWallets(wltIDs []string, notes map[string]map[string]string, password []byte) ([]byte, error) {
+	defer observeOperation("ExportWallets", time.Now())
+
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if len(wltIDs) == 0 {
+		return nil, NewError(errors.New("no wallets to export"))
+	}
+
+	if len(password) == 0 {
+		return nil, ErrMissingArchivePassword
+	}
+
+	a := Archive{
+		Version: ArchiveVersion,
+		Created: time.Now().UTC().Unix(),
+		Wallets: make([]ArchiveWallet, 0, len(wltIDs)),
+	}
+
+	exported := make(map[string]struct{}, len(wltIDs))
+	for _, id := range wltIDs {
+		if _, ok := exported[id]; ok {
+			continue
+		}
+		exported[id] = struct{}{}
+
+		w := serv.wallets.get(id)
+		if w == nil {
+			return nil, ErrWalletNotExist
+		}
+
+		// xpub wallets have no secrets to expose
+		if !w.IsEncrypted() && w.Type() != WalletTypeXPub && !serv.config.EnableSeedAPI {
+			return nil, ErrSeedAPIDisabled
+		}
+
+		aw, err := newArchiveWallet(w, notes[id])
+		if err != nil {
+			return nil, err
+		}
+		a.Wallets = append(a.Wallets, *aw)
+	}
+
+	return EncryptArchive(a, password, serv.config.CryptoType)
+}
+
+// loadWalletFile loads the file of a wallet that isn't loaded from the wallet directory.
+// Returns nil if the file doesn't exist.
+func (serv *Service) loadWalletFile(filename string) (Wallet, error) {
+	wf := filepath.Join(serv.config.WalletDir, filename)
+	if _, err := os.Stat(wf); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return Load(wf)
+}
+
+// ImportResult is the result of importing a wallet archive
+type ImportResult struct {
+	// Imported are the wallets that were imported
+	Imported []Wallet
+	// Skipped are the filenames of the wallets that conflicted with existing wallets and were skipped
+	Skipped []string
+	// Backups are the filenames of the backups of the overwritten wallet files
+	Backups []string
+	// Notes are the transaction notes of the imported wallets, to be merged by the caller
+	Notes map[string]string
+}
+
+// ImportWallets decrypts a wallet archive created by ExportWallets and loads its wallets.
+// Wallets that conflict with loaded wallets are handled according to the conflict policy.
+// walletPasswords are the passwords of the encrypted loaded wallets to overwrite, keyed by wallet ID.
+// Conflicts are checked before any wallet is imported.
+func (serv *Service) ImportWallets(data, password []byte, conflict ImportConflict, walletPasswords map[string][]byte) (*ImportResult, error) {
+	defer observeOperation("ImportWallets", time.Now())
+
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if _, err := ImportConflictFromString(string(conflict)); err != nil {
+		return nil, err
+	}
+
+	a, err := DecryptArchive(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+
+	var wlts []Wallet
+	notes := make(map[string]map[string]string, len(a.Wallets))
+	overwritten := make(map[string]struct{}, len(a.Wallets))
+	filenames := make(map[string]struct{}, len(a.Wallets))
+	fingerprints := make(map[string]struct{}, len(a.Wallets))
+	for _, aw := range a.Wallets {
+		w, err := loadArchiveWallet(aw)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := filenames[w.Filename()]; ok {
+			return nil, NewError(fmt.Errorf("archive has duplicate wallet %q", w.Filename()))
+		}
+		filenames[w.Filename()] = struct{}{}
+		notes[w.Filename()] = aw.Notes
+
+		if fp := w.Fingerprint(); fp != "" {
+			if _, ok := fingerprints[fp]; ok {
+				return nil, NewError(fmt.Errorf("archive has duplicate wallet %q", w.Filename()))
+			}
+			fingerprints[fp] = struct{}{}
+		}
+
+		// A wallet file that was unloaded is still on disk and is overwritten by the import
+		// the same way as a loaded wallet
+		existing := serv.wallets.get(w.Filename())
+		if existing == nil {
+			existing, err = serv.loadWalletFile(w.Filename())
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		fp := w.Fingerprint()
+		fpOwner, fpConflict := serv.fingerprints[fp]
+		fpConflict = fpConflict && fp != ""
+		sameWallet := existing != nil && fp != "" && existing.Fingerprint() == fp
+
+		switch {
+		case existing == nil && !fpConflict:
+			wlts = append(wlts, w)
+		case conflict == ImportConflictSkip:
+			result.Skipped = append(result.Skipped, w.Filename())
+		case fpConflict && fpOwner != w.Filename():
+			return nil, NewError(fmt.Errorf("archive wallet %q has the same fingerprint as loaded wallet %q", w.Filename(), fpOwner))
+		case conflict == ImportConflictOverwrite && sameWallet:
+			// The spending policy can only be changed with the wallet password,
+			// replacing the wallet would drop it
+			policy, err := existing.SpendingPolicy()
+			if err != nil {
+				return nil, err
+			}
+			if policy != nil {
+				return nil, NewError(fmt.Errorf("wallet %q has a spending policy and can't be overwritten", w.Filename()))
+			}
+
+			if existing.IsEncrypted() {
+				if err := GuardView(existing, walletPasswords[w.Filename()], func(Wallet) error {
+					return nil
+				}); err != nil {
+					return nil, err
+				}
+			}
+			wlts = append(wlts, w)
+			overwritten[w.Filename()] = struct{}{}
+		case conflict == ImportConflictOverwrite:
+			return nil, NewError(fmt.Errorf("archive wallet %q has a different fingerprint than the existing wallet of the same filename", w.Filename()))
+		default:
+			return nil, NewError(fmt.Errorf("archive wallet %q conflicts with an existing wallet", w.Filename()))
+		}
+	}
+
+	// The permission of the overwritten files is checked without opening them for truncation,
+	// an import that fails leaves every wallet file as it was
+	for name := range overwritten {
+		f, err := os.OpenFile(filepath.Join(serv.config.WalletDir, name), os.O_WRONLY, 0)
+		if err != nil {
+			if os.IsPermission(err) {
+				return nil, ErrWalletPermission
+			}
+			return nil, err
+		}
+		f.Close()
+	}
+
+	// Back up the files of the wallets that are overwritten before saving any wallet
+	now := time.Now()
+	for _, w := range wlts {
+		if _, ok := overwritten[w.Filename()]; !ok {
+			continue
+		}
+
+		wf := filepath.Join(serv.config.WalletDir, w.Filename())
+		backup := ImportBackupFilename(wf, now)
+		if _, err := os.Stat(backup); err == nil {
+			return nil, fmt.Errorf("wallet import backup %q already exists", backup)
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+
+		orig, err := ioutil.ReadFile(wf)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := file.SaveBinary(backup, orig, 0600); err != nil {
+			return nil, err
+		}
+
+		result.Backups = append(result.Backups, filepath.Base(backup))
+	}
+
+	// Write every wallet to a temporary file first, the wallet files are only replaced
+	// once all of them are written
+	tmpFiles := make([]string, 0, len(wlts))
+	removeTmpFiles := func() {
+		for _, tmp := range tmpFiles {
+			if err := os.Remove(tmp); err != nil && !os.IsNotExist(err) {
+				logger.WithError(err).WithField("file", tmp).Error("Remove temporary wallet import file failed")
+			}
+		}
+	}
+	for _, w := range wlts {
+		data, err := w.Serialize()
+		if err != nil {
+			removeTmpFiles()
+			return nil, err
+		}
+
+		tmp := filepath.Join(serv.config.WalletDir, w.Filename()+".import.tmp")
+		tmpFiles = append(tmpFiles, tmp)
+		if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+			removeTmpFiles()
+			return nil, err
+		}
+	}
+
+	for i, w := range wlts {
+		if err := os.Rename(tmpFiles[i], filepath.Join(serv.config.WalletDir, w.Filename())); err != nil {
+			removeTmpFiles()
+			return nil, err
+		}
+
+		if old := serv.wallets.get(w.Filename()); old != nil {
+			if fp := old.Fingerprint(); fp != "" {
+				delete(serv.fingerprints, fp)
+			}
+		}
+
+		serv.wallets.set(w)
+		if fp := w.Fingerprint(); fp != "" {
+			serv.fingerprints[fp] = w.Filename()
+		}
+
+		result.Imported = append(result.Imported, w.Clone())
+
+		for txid, note := range notes[w.Filename()] {
+			if result.Notes == nil {
+				result.Notes = make(map[string]string)
+			}
+			result.Notes[txid] = note
+		}
+	}
+
+	return result, nil
+}
+
 func (serv *Service) setWallets(wlts Wallets) {
 	serv.wallets = wlts
 
@@ -637,6 +1303,8 @@ func (serv *Service) setWallets(wlts Wallets) {
 // GetWalletSeed returns seed and seed passphrase of encrypted wallet of given wallet id
 // Returns ErrWalletNotEncrypted if it's not encrypted
 func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, string, error) {
+	defer observeOperation("GetWalletSeed", time.Now())
+
 	serv.RLock()
 	defer serv.RUnlock()
 	if !serv.config.EnableWalletAPI {
@@ -668,8 +1336,11 @@ func (serv *Service) GetWalletSeed(wltID string, password []byte) (string, strin
 	return seed, seedPassphrase, nil
 }
 
-// UpdateSecrets opens a wallet for modification of secret data and saves it safely
+// UpdateSecrets opens a wallet for modification of secret data and saves it safely.
+// The password of an encrypted wallet can be the token of its unlock session.
 func (serv *Service) UpdateSecrets(wltID string, password []byte, f func(Wallet) error) error {
+	defer observeOperation("UpdateSecrets", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -682,7 +1353,7 @@ func (serv *Service) UpdateSecrets(wltID string, password []byte, f func(Wallet)
 	}
 
 	if w.IsEncrypted() {
-		if err := GuardUpdate(w, password, f); err != nil {
+		if err := serv.guardUpdate(w, password, f); err != nil {
 			return err
 		}
 	} else if len(password) != 0 {
@@ -705,6 +1376,8 @@ func (serv *Service) UpdateSecrets(wltID string, password []byte, f func(Wallet)
 
 // Update opens a wallet for modification of non-secret data and saves it safely
 func (serv *Service) Update(wltID string, f func(Wallet) error) error {
+	defer observeOperation("Update", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -730,8 +1403,11 @@ func (serv *Service) Update(wltID string, f func(Wallet) error) error {
 	return nil
 }
 
-// ViewSecrets opens a wallet for reading secret data
+// ViewSecrets opens a wallet for reading secret data.
+// The password of an encrypted wallet can be the token of its unlock session.
 func (serv *Service) ViewSecrets(wltID string, password []byte, f func(Wallet) error) error {
+	defer observeOperation("ViewSecrets", time.Now())
+
 	serv.RLock()
 	defer serv.RUnlock()
 	if !serv.config.EnableWalletAPI {
@@ -744,7 +1420,7 @@ func (serv *Service) ViewSecrets(wltID string, password []byte, f func(Wallet) e
 	}
 
 	if w.IsEncrypted() {
-		return GuardView(w, password, f)
+		return serv.guardView(w, password, f)
 	} else if len(password) != 0 {
 		return ErrWalletNotEncrypted
 	} else {
@@ -752,8 +1428,98 @@ func (serv *Service) ViewSecrets(wltID string, password []byte, f func(Wallet) e
 	}
 }
 
+// ViewSecretsToSpend opens a wallet for reading secret data to sign a transaction.
+// f must call authorize with the spend of the transaction before signing it.
+// If the wallet has a spending policy, authorize returns an error if the policy does not allow the spend,
+// and the spend is recorded by the policy if f returns without error.
+// The password of an encrypted wallet can be the token of its unlock session.
+func (serv *Service) ViewSecretsToSpend(wltID string, password []byte, f func(w Wallet, authorize func(Spend) error) error) error {
+	defer observeOperation("ViewSecretsToSpend", time.Now())
+
+	// The spends recorded by the policy are updated, so the wallet is locked for writing
+	serv.Lock()
+	defer serv.Unlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return err
+	}
+
+	policy, err := w.SpendingPolicy()
+	if err != nil {
+		return err
+	}
+
+	authorized := false
+	authorize := func(s Spend) error {
+		if authorized {
+			return errors.New("spend is already authorized")
+		}
+
+		if policy != nil {
+			now := time.Now()
+			spends, err := w.PolicySpends()
+			if err != nil {
+				return err
+			}
+			spends = prunePolicySpends(spends, now)
+
+			if err := policy.Check(spends, s, now); err != nil {
+				return err
+			}
+
+			if err := w.SetPolicySpends(append(spends, PolicySpend{
+				Time:  now.Unix(),
+				Coins: s.Coins,
+			})); err != nil {
+				return err
+			}
+		}
+
+		authorized = true
+		return nil
+	}
+
+	view := func(uw Wallet) error {
+		return f(uw, authorize)
+	}
+
+	if w.IsEncrypted() {
+		err = serv.guardView(w, password, view)
+	} else if len(password) != 0 {
+		err = ErrWalletNotEncrypted
+	} else {
+		err = view(w)
+	}
+	if err != nil {
+		return err
+	}
+
+	if policy == nil {
+		return nil
+	}
+
+	if !authorized {
+		logger.Critical().WithField("wallet", wltID).Error("Transaction signed without checking the spending policy")
+		return ErrSpendNotAuthorized
+	}
+
+	// Save the spend recorded by the policy
+	if err := Save(w, serv.config.WalletDir); err != nil {
+		return err
+	}
+
+	serv.wallets.set(w)
+	return nil
+}
+
 // View opens a wallet for reading non-secret data
 func (serv *Service) View(wltID string, f func(Wallet) error) error {
+	defer observeOperation("View", time.Now())
+
 	serv.RLock()
 	defer serv.RUnlock()
 	if !serv.config.EnableWalletAPI {
@@ -772,6 +1538,8 @@ func (serv *Service) View(wltID string, f func(Wallet) error) error {
 // The recovered wallet will be encrypted with the new password, if provided.
 func (serv *Service) RecoverWallet(wltName, seed, seedPassphrase string,
 	password []byte) (Wallet, error) {
+	defer observeOperation("RecoverWallet", time.Now())
+
 	serv.Lock()
 	defer serv.Unlock()
 	if !serv.config.EnableWalletAPI {
@@ -854,6 +1622,11 @@ func (serv *Service) RecoverWallet(wltName, seed, seedPassphrase string,
 				return nil, err
 			}
 		}
+
+		// regenerate the accounts created after the default account
+		if err := recoverBip44Accounts(w, w3, password); err != nil {
+			return nil, err
+		}
 	}
 
 	// Preserve the timestamp of the old wallet
@@ -868,3 +1641,56 @@ func (serv *Service) RecoverWallet(wltName, seed, seedPassphrase string,
 
 	return w3.Clone(), nil
 }
+
+// recoverBip44Accounts recreates the accounts of the bip44 wallet w in the recovered wallet w3,
+// with the same names and the same number of addresses on each chain.
+// The default account is expected to be regenerated already.
+func recoverBip44Accounts(w, w3 Wallet, password []byte) error {
+	accounts := w.Accounts()
+	if len(accounts) == 1 && accounts[0] == w3.Accounts()[0] {
+		return nil
+	}
+
+	f := func(w3 Wallet) error {
+		am, ok := w3.(Bip44AccountManager)
+		if !ok {
+			return ErrWalletNotBip44
+		}
+
+		for _, a := range accounts {
+			if a.Index == 0 {
+				if err := am.SetAccountName(0, a.Name); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if _, err := am.NewAccount(a.Name); err != nil {
+				return err
+			}
+
+			for _, chain := range []Option{OptionExternal(), OptionChange()} {
+				n, err := w.EntriesLen(OptionAccount(a.Index), chain)
+				if err != nil {
+					return err
+				}
+
+				if n == 0 {
+					continue
+				}
+
+				if _, err := w3.GenerateAddresses(OptionGenerateN(uint64(n)), OptionAccount(a.Index), chain); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	}
+
+	if w3.IsEncrypted() {
+		return GuardUpdate(w3, password, f)
+	}
+
+	return f(w3)
+}
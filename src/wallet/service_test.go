@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/skycoin/skycoin/src/cipher/bip39"
 	"github.com/skycoin/skycoin/src/testutil"
@@ -21,6 +22,7 @@ import (
 
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/crypto"
+	"github.com/skycoin/skycoin/src/util/metrics"
 	"github.com/skycoin/skycoin/src/wallet"
 )
 
@@ -1085,6 +1087,29 @@ func TestServiceGetWallets(t *testing.T) {
 	}
 }
 
+func TestServiceOperationMetrics(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.GetWallets()
+	require.NoError(t, err)
+
+	// Failed operations are timed too
+	_, err = s.GetWallet("missing.wlt")
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	var buf strings.Builder
+	require.NoError(t, metrics.DefaultRegistry.WriteText(&buf))
+	require.Contains(t, buf.String(), "# TYPE skycoin_wallet_operation_duration_seconds histogram\n")
+	require.Contains(t, buf.String(), `skycoin_wallet_operation_duration_seconds_count{operation="GetWallets"}`)
+	require.Contains(t, buf.String(), `skycoin_wallet_operation_duration_seconds_count{operation="GetWallet"}`)
+}
+
 func TestServiceUpdateWalletLabel(t *testing.T) {
 	tt := []struct {
 		name             string
@@ -1175,6 +1200,641 @@ func TestServiceUpdateWalletLabel(t *testing.T) {
 	}
 }
 
+func TestServiceCreateAccount(t *testing.T) {
+	tt := []struct {
+		name             string
+		opts             wallet.Options
+		wltID            string
+		accountName      string
+		password         []byte
+		disableWalletAPI bool
+		err              error
+	}{
+		{
+			name: "ok",
+			opts: wallet.Options{
+				Type:  wallet.WalletTypeBip44,
+				Seed:  bip39.MustNewDefaultMnemonic(),
+				Label: "label",
+			},
+			wltID:       "t.wlt",
+			accountName: "customer",
+		},
+		{
+			name: "ok encrypted",
+			opts: wallet.Options{
+				Type:     wallet.WalletTypeBip44,
+				Seed:     bip39.MustNewDefaultMnemonic(),
+				Label:    "label",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			wltID:       "t.wlt",
+			accountName: "customer",
+			password:    []byte("pwd"),
+		},
+		{
+			name: "encrypted missing password",
+			opts: wallet.Options{
+				Type:     wallet.WalletTypeBip44,
+				Seed:     bip39.MustNewDefaultMnemonic(),
+				Label:    "label",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			wltID:       "t.wlt",
+			accountName: "customer",
+			err:         wallet.ErrMissingPassword,
+		},
+		{
+			name: "encrypted wrong password",
+			opts: wallet.Options{
+				Type:     wallet.WalletTypeBip44,
+				Seed:     bip39.MustNewDefaultMnemonic(),
+				Label:    "label",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			},
+			wltID:       "t.wlt",
+			accountName: "customer",
+			password:    []byte("wrong"),
+			err:         wallet.ErrInvalidPassword,
+		},
+		{
+			name: "password for unencrypted wallet",
+			opts: wallet.Options{
+				Type:  wallet.WalletTypeBip44,
+				Seed:  bip39.MustNewDefaultMnemonic(),
+				Label: "label",
+			},
+			wltID:       "t.wlt",
+			accountName: "customer",
+			password:    []byte("pwd"),
+			err:         wallet.ErrWalletNotEncrypted,
+		},
+		{
+			name: "not bip44 wallet",
+			opts: wallet.Options{
+				Type:  wallet.WalletTypeDeterministic,
+				Seed:  bip39.MustNewDefaultMnemonic(),
+				Label: "label",
+			},
+			wltID:       "t.wlt",
+			accountName: "customer",
+			err:         wallet.ErrWalletNotBip44,
+		},
+		{
+			name: "missing account name",
+			opts: wallet.Options{
+				Type:  wallet.WalletTypeBip44,
+				Seed:  bip39.MustNewDefaultMnemonic(),
+				Label: "label",
+			},
+			wltID: "t.wlt",
+			err:   wallet.ErrMissingAccountName,
+		},
+		{
+			name: "wallet doesn't exist",
+			opts: wallet.Options{
+				Type:  wallet.WalletTypeBip44,
+				Seed:  bip39.MustNewDefaultMnemonic(),
+				Label: "label",
+			},
+			wltID:       "t1.wlt",
+			accountName: "customer",
+			err:         wallet.ErrWalletNotExist,
+		},
+		{
+			name:             "wallet api disabled",
+			disableWalletAPI: true,
+			accountName:      "customer",
+			err:              wallet.ErrWalletAPIDisabled,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := prepareWltDir()
+			s, err := wallet.NewService(wallet.Config{
+				WalletDir:       dir,
+				CryptoType:      crypto.CryptoTypeSha256Xor,
+				EnableWalletAPI: !tc.disableWalletAPI,
+			})
+			require.NoError(t, err)
+
+			if tc.disableWalletAPI {
+				_, err := s.CreateAccount(tc.wltID, tc.password, tc.accountName)
+				require.Equal(t, tc.err, err)
+				return
+			}
+
+			_, err = s.CreateWallet("t.wlt", tc.opts)
+			require.NoError(t, err)
+
+			a, err := s.CreateAccount(tc.wltID, tc.password, tc.accountName)
+			require.Equal(t, tc.err, err)
+			if err != nil {
+				return
+			}
+
+			require.Equal(t, wallet.Bip44Account{
+				Name:  tc.accountName,
+				Index: 1,
+			}, a)
+
+			accounts, err := s.GetAccounts(tc.wltID)
+			require.NoError(t, err)
+			require.Equal(t, []wallet.Bip44Account{
+				{Name: bip44wallet.DefaultAccountName, Index: 0},
+				{Name: tc.accountName, Index: 1},
+			}, accounts)
+
+			// The first external and change addresses of the account are generated
+			addrs, err := s.GetAddresses(tc.wltID, wallet.OptionAccount(1))
+			require.NoError(t, err)
+			require.Len(t, addrs, 2)
+
+			// The account is saved to disk
+			w, err := wallet.Load(filepath.Join(dir, tc.wltID))
+			require.NoError(t, err)
+			require.Equal(t, accounts, w.Accounts())
+
+			// The account addresses can be signed for once the wallet is unlocked
+			if w.IsEncrypted() {
+				checkNoSensitiveData(t, w)
+				err = s.ViewSecrets(tc.wltID, tc.password, func(w wallet.Wallet) error {
+					for _, a := range addrs {
+						e, err := w.GetEntry(a, wallet.OptionAccount(1))
+						require.NoError(t, err)
+						require.False(t, e.Secret == cipher.SecKey{})
+					}
+					return nil
+				})
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestServiceGetAccounts(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", wallet.Options{
+		Type:  wallet.WalletTypeDeterministic,
+		Seed:  bip39.MustNewDefaultMnemonic(),
+		Label: "label",
+	})
+	require.NoError(t, err)
+
+	_, err = s.GetAccounts("t.wlt")
+	require.Equal(t, wallet.ErrWalletNotBip44, err)
+
+	_, err = s.GetAccounts("t1.wlt")
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+}
+
+func TestServiceUpdateAccountName(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", wallet.Options{
+		Type:  wallet.WalletTypeBip44,
+		Seed:  bip39.MustNewDefaultMnemonic(),
+		Label: "label",
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateAccount("t.wlt", nil, "customer")
+	require.NoError(t, err)
+
+	require.NoError(t, s.UpdateAccountName("t.wlt", 1, "customer-renamed"))
+	require.Equal(t, wallet.ErrBip44AccountNotFound, s.UpdateAccountName("t.wlt", 2, "customer"))
+	require.Equal(t, wallet.ErrMissingAccountName, s.UpdateAccountName("t.wlt", 1, ""))
+	require.Equal(t, wallet.ErrWalletNotExist, s.UpdateAccountName("t1.wlt", 1, "customer"))
+
+	w, err := wallet.Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	require.Equal(t, []wallet.Bip44Account{
+		{Name: bip44wallet.DefaultAccountName, Index: 0},
+		{Name: "customer-renamed", Index: 1},
+	}, w.Accounts())
+}
+
+func TestServiceUpdateAddressMeta(t *testing.T) {
+	for _, walletType := range []string{
+		wallet.WalletTypeDeterministic,
+		wallet.WalletTypeBip44,
+	} {
+		t.Run(walletType, func(t *testing.T) {
+			dir := prepareWltDir()
+			s, err := wallet.NewService(wallet.Config{
+				WalletDir:       dir,
+				CryptoType:      crypto.CryptoTypeSha256Xor,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+
+			w, err := s.CreateWallet("t.wlt", wallet.Options{
+				Type:     walletType,
+				Seed:     bip39.MustNewDefaultMnemonic(),
+				Label:    "label",
+				Encrypt:  true,
+				Password: []byte("pwd"),
+			})
+			require.NoError(t, err)
+
+			addrs, err := w.GetAddresses()
+			require.NoError(t, err)
+			addr := addrs[0].(cipher.Address)
+
+			m := wallet.EntryMeta{
+				Label: "alice",
+				Tags:  []string{"customer"},
+				Note:  "issued for invoice 42",
+			}
+
+			// Encrypted wallets don't need to be unlocked
+			require.NoError(t, s.UpdateAddressMeta("t.wlt", addr, m))
+			require.Equal(t, wallet.ErrAddressNotInWallet, s.UpdateAddressMeta("t.wlt", testutil.MakeAddress(), m))
+			require.Equal(t, wallet.ErrWalletNotExist, s.UpdateAddressMeta("t1.wlt", addr, m))
+			require.Equal(t, wallet.ErrInvalidEntryTag, s.UpdateAddressMeta("t.wlt", addr, wallet.EntryMeta{Tags: []string{"a,b"}}))
+			require.Equal(t, wallet.ErrDuplicateEntryTag, s.UpdateAddressMeta("t.wlt", addr, wallet.EntryMeta{Tags: []string{"a", "a"}}))
+
+			w, err = s.GetWallet("t.wlt")
+			require.NoError(t, err)
+			e, err := w.GetEntry(addr)
+			require.NoError(t, err)
+			require.Equal(t, m, e.EntryMeta)
+
+			// The metadata is saved to the wallet file
+			w, err = wallet.Load(filepath.Join(dir, "t.wlt"))
+			require.NoError(t, err)
+			e, err = w.GetEntry(addr)
+			require.NoError(t, err)
+			require.Equal(t, m, e.EntryMeta)
+		})
+	}
+
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:  prepareWltDir(),
+		CryptoType: crypto.CryptoTypeSha256Xor,
+	})
+	require.NoError(t, err)
+	require.Equal(t, wallet.ErrWalletAPIDisabled, s.UpdateAddressMeta("t.wlt", testutil.MakeAddress(), wallet.EntryMeta{}))
+}
+
+func TestServiceRecoverWalletAccounts(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	seed := bip39.MustNewDefaultMnemonic()
+	_, err = s.CreateWallet("t.wlt", wallet.Options{
+		Type:     wallet.WalletTypeBip44,
+		Seed:     seed,
+		Label:    "label",
+		Encrypt:  true,
+		Password: []byte("pwd"),
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateAccount("t.wlt", []byte("pwd"), "customer")
+	require.NoError(t, err)
+	require.NoError(t, s.UpdateAccountName("t.wlt", 0, "main"))
+
+	_, err = s.NewAddresses("t.wlt", nil, wallet.OptionAccount(1), wallet.OptionGenerateN(2))
+	require.NoError(t, err)
+
+	addrs, err := s.GetAddresses("t.wlt", wallet.OptionAccount(1))
+	require.NoError(t, err)
+	require.Len(t, addrs, 4)
+
+	w, err := s.RecoverWallet("t.wlt", seed, "", []byte("pwd2"))
+	require.NoError(t, err)
+	require.True(t, w.IsEncrypted())
+	require.Equal(t, []wallet.Bip44Account{
+		{Name: "main", Index: 0},
+		{Name: "customer", Index: 1},
+	}, w.Accounts())
+
+	recoveredAddrs, err := s.GetAddresses("t.wlt", wallet.OptionAccount(1))
+	require.NoError(t, err)
+	require.Equal(t, addrs, recoveredAddrs)
+	checkNoSensitiveData(t, w)
+}
+
+func TestServicePeekChangeAddress(t *testing.T) {
+	dir := prepareWltDir()
+	s, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeSha256Xor,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+
+	_, err = s.CreateWallet("t.wlt", wallet.Options{
+		Type:  wallet.WalletTypeBip44,
+		Seed:  bip39.MustNewDefaultMnemonic(),
+		Label: "label",
+	})
+	require.NoError(t, err)
+
+	changeAddrs, err := s.GetAddresses("t.wlt", wallet.OptionChange())
+	require.NoError(t, err)
+	require.Len(t, changeAddrs, 1)
+
+	// The unused change address is returned
+	addr, err := s.PeekChangeAddress("t.wlt", mockTxnsFinder{})
+	require.NoError(t, err)
+	require.Equal(t, changeAddrs[0], addr)
+
+	// A new change address is generated and saved once the last one is used
+	addr, err = s.PeekChangeAddress("t.wlt", mockTxnsFinder{changeAddrs[0]: true})
+	require.NoError(t, err)
+	require.NotEqual(t, changeAddrs[0], addr)
+
+	w, err := wallet.Load(filepath.Join(dir, "t.wlt"))
+	require.NoError(t, err)
+	savedChangeAddrs, err := w.GetAddresses(wallet.OptionChange())
+	require.NoError(t, err)
+	require.Equal(t, []cipher.Addresser{changeAddrs[0], addr}, savedChangeAddrs)
+
+	// The change chain of another account is used
+	_, err = s.CreateAccount("t.wlt", nil, "customer")
+	require.NoError(t, err)
+	accountChangeAddrs, err := s.GetAddresses("t.wlt", wallet.OptionAccount(1), wallet.OptionChange())
+	require.NoError(t, err)
+	addr, err = s.PeekChangeAddress("t.wlt", mockTxnsFinder{}, wallet.OptionAccount(1))
+	require.NoError(t, err)
+	require.Equal(t, accountChangeAddrs[0], addr)
+
+	// Wallets without a change chain are rejected
+	_, err = s.CreateWallet("d.wlt", wallet.Options{
+		Type:  wallet.WalletTypeDeterministic,
+		Seed:  "seed",
+		Label: "label",
+	})
+	require.NoError(t, err)
+	_, err = s.PeekChangeAddress("d.wlt", mockTxnsFinder{})
+	require.Equal(t, wallet.ErrWalletNoChangeChain, err)
+
+	_, err = s.PeekChangeAddress("t1.wlt", mockTxnsFinder{})
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+}
+
+func TestServiceExportImportWallets(t *testing.T) {
+	newService := func() (*wallet.Service, string) {
+		dir := prepareWltDir()
+		s, err := wallet.NewService(wallet.Config{
+			WalletDir:       dir,
+			CryptoType:      crypto.CryptoTypeScryptChacha20poly1305Insecure,
+			EnableWalletAPI: true,
+			EnableSeedAPI:   true,
+		})
+		require.NoError(t, err)
+		return s, dir
+	}
+
+	s, dir := newService()
+	_, err := s.CreateWallet("t1.wlt", wallet.Options{
+		Type:  wallet.WalletTypeDeterministic,
+		Seed:  "seed1",
+		Label: "label1",
+	})
+	require.NoError(t, err)
+	_, err = s.CreateWallet("t2.wlt", wallet.Options{
+		Type:       wallet.WalletTypeDeterministic,
+		Seed:       "seed2",
+		Label:      "label2",
+		Encrypt:    true,
+		Password:   []byte("wltpwd"),
+		CryptoType: crypto.CryptoTypeScryptChacha20poly1305Insecure,
+	})
+	require.NoError(t, err)
+
+	notes := map[string]map[string]string{
+		"t1.wlt": {
+			"52d5e3b2a5bbcd9df5ab5ea5f9d6ff36ee3b53a4d39b8a8b9ac5e5ccdde3a4bb": "rent",
+		},
+		"t2.wlt": {
+			"a4d39b8a8b9ac5e5ccdde3a4bb52d5e3b2a5bbcd9df5ab5ea5f9d6ff36ee3b53": "salary",
+		},
+	}
+
+	_, err = s.ExportWallets(nil, notes, []byte("pwd"))
+	require.Error(t, err)
+	_, err = s.ExportWallets([]string{"t1.wlt"}, notes, nil)
+	require.Equal(t, wallet.ErrMissingArchivePassword, err)
+	_, err = s.ExportWallets([]string{"t3.wlt"}, notes, []byte("pwd"))
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	data, err := s.ExportWallets([]string{"t1.wlt", "t2.wlt"}, notes, []byte("pwd"))
+	require.NoError(t, err)
+
+	// Exporting an unencrypted wallet exposes its seed, it requires the seed api
+	noSeedAPI, err := wallet.NewService(wallet.Config{
+		WalletDir:       dir,
+		CryptoType:      crypto.CryptoTypeScryptChacha20poly1305Insecure,
+		EnableWalletAPI: true,
+	})
+	require.NoError(t, err)
+	_, err = noSeedAPI.ExportWallets([]string{"t1.wlt"}, notes, []byte("pwd"))
+	require.Equal(t, wallet.ErrSeedAPIDisabled, err)
+	_, err = noSeedAPI.ExportWallets([]string{"t2.wlt"}, notes, []byte("pwd"))
+	require.NoError(t, err)
+
+	// Import into an empty wallet directory
+	s2, dir2 := newService()
+	_, err = s2.ImportWallets(data, []byte("wrong"), wallet.ImportConflictError, nil)
+	require.Equal(t, wallet.ErrInvalidPassword, err)
+
+	result, err := s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictError, nil)
+	require.NoError(t, err)
+	require.Len(t, result.Imported, 2)
+	require.Empty(t, result.Skipped)
+	require.Equal(t, map[string]string{
+		"52d5e3b2a5bbcd9df5ab5ea5f9d6ff36ee3b53a4d39b8a8b9ac5e5ccdde3a4bb": "rent",
+		"a4d39b8a8b9ac5e5ccdde3a4bb52d5e3b2a5bbcd9df5ab5ea5f9d6ff36ee3b53": "salary",
+	}, result.Notes)
+
+	for _, id := range []string{"t1.wlt", "t2.wlt"} {
+		w1, err := s.GetWallet(id)
+		require.NoError(t, err)
+		w2, err := s2.GetWallet(id)
+		require.NoError(t, err)
+		require.Equal(t, w1.Label(), w2.Label())
+		require.Equal(t, w1.IsEncrypted(), w2.IsEncrypted())
+		require.Equal(t, w1.Fingerprint(), w2.Fingerprint())
+
+		// The imported wallets are saved
+		w3, err := wallet.Load(filepath.Join(dir2, id))
+		require.NoError(t, err)
+		require.Equal(t, w1.Fingerprint(), w3.Fingerprint())
+	}
+
+	// The encrypted wallet keeps its own password
+	require.NoError(t, s2.ViewSecrets("t2.wlt", []byte("wltpwd"), func(w wallet.Wallet) error {
+		require.Equal(t, "seed2", w.Seed())
+		return nil
+	}))
+
+	// Importing again conflicts with the loaded wallets
+	_, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictError, nil)
+	require.Error(t, err)
+	require.IsType(t, wallet.Error{}, err)
+
+	result, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictSkip, nil)
+	require.NoError(t, err)
+	require.Empty(t, result.Imported)
+	require.Equal(t, []string{"t1.wlt", "t2.wlt"}, result.Skipped)
+	require.Empty(t, result.Notes)
+
+	// The password of the encrypted loaded wallet is required to overwrite it
+	require.NoError(t, s2.UpdateWalletLabel("t1.wlt", "changed"))
+	_, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, nil)
+	require.Equal(t, wallet.ErrMissingPassword, err)
+	_, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, map[string][]byte{
+		"t2.wlt": []byte("wrong"),
+	})
+	require.Equal(t, wallet.ErrInvalidPassword, err)
+	w, err := s2.GetWallet("t1.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "changed", w.Label())
+
+	result, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, map[string][]byte{
+		"t2.wlt": []byte("wltpwd"),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Imported, 2)
+	w, err = s2.GetWallet("t1.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "label1", w.Label())
+
+	// The overwritten wallet files are backed up
+	require.Len(t, result.Backups, 2)
+	backup, err := wallet.Load(filepath.Join(dir2, result.Backups[0]))
+	require.NoError(t, err)
+	require.Equal(t, "changed", backup.Label())
+	require.Equal(t, w.Fingerprint(), backup.Fingerprint())
+
+	// The file of an unloaded wallet is still on disk and conflicts the same way as a loaded wallet
+	require.NoError(t, s2.UpdateWalletLabel("t1.wlt", "unloaded"))
+	require.NoError(t, s2.UnloadWallet("t1.wlt"))
+	_, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictError, nil)
+	require.Error(t, err)
+	require.IsType(t, wallet.Error{}, err)
+	result, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictSkip, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"t1.wlt", "t2.wlt"}, result.Skipped)
+	_, err = s2.GetWallet("t1.wlt")
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	result, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, map[string][]byte{
+		"t2.wlt": []byte("wltpwd"),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Imported, 2)
+	require.Len(t, result.Backups, 2)
+	backup, err = wallet.Load(filepath.Join(dir2, result.Backups[0]))
+	require.NoError(t, err)
+	require.Equal(t, "unloaded", backup.Label())
+	w, err = s2.GetWallet("t1.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "label1", w.Label())
+
+	// A wallet with a spending policy can't be overwritten, the policy can only be removed with the wallet password
+	_, err = s2.SetSpendingPolicy("t2.wlt", []byte("wltpwd"), &wallet.SpendingPolicy{
+		MaxCoinsPerTransaction: 1e6,
+	})
+	require.NoError(t, err)
+	_, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, map[string][]byte{
+		"t2.wlt": []byte("wltpwd"),
+	})
+	require.Equal(t, wallet.NewError(errors.New(`wallet "t2.wlt" has a spending policy and can't be overwritten`)), err)
+	policy, _, err := s2.GetSpendingPolicy("t2.wlt")
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+
+	// Unloading the wallet doesn't allow to drop its policy by overwriting its file
+	require.NoError(t, s2.UnloadWallet("t2.wlt"))
+	_, err = s2.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, map[string][]byte{
+		"t2.wlt": []byte("wltpwd"),
+	})
+	require.Equal(t, wallet.NewError(errors.New(`wallet "t2.wlt" has a spending policy and can't be overwritten`)), err)
+	w, err = wallet.Load(filepath.Join(dir2, "t2.wlt"))
+	require.NoError(t, err)
+	policy, err = w.SpendingPolicy()
+	require.NoError(t, err)
+	require.NotNil(t, policy)
+
+	// A wallet with another seed under the same filename can't be overwritten
+	s4, dir4 := newService()
+	_, err = s4.CreateWallet("t1.wlt", wallet.Options{
+		Type:  wallet.WalletTypeDeterministic,
+		Seed:  "seed3",
+		Label: "label3",
+	})
+	require.NoError(t, err)
+	_, err = s4.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, nil)
+	require.Error(t, err)
+	require.IsType(t, wallet.Error{}, err)
+	w, err = s4.GetWallet("t1.wlt")
+	require.NoError(t, err)
+	require.Equal(t, "label3", w.Label())
+
+	require.NoError(t, s4.UnloadWallet("t1.wlt"))
+	_, err = s4.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, nil)
+	require.Error(t, err)
+	require.IsType(t, wallet.Error{}, err)
+	w, err = wallet.Load(filepath.Join(dir4, "t1.wlt"))
+	require.NoError(t, err)
+	require.Equal(t, "label3", w.Label())
+
+	// A wallet with the same seed under another filename can't be overwritten
+	s3, dir3 := newService()
+	_, err = s3.CreateWallet("other.wlt", wallet.Options{
+		Type:  wallet.WalletTypeDeterministic,
+		Seed:  "seed1",
+		Label: "label1",
+	})
+	require.NoError(t, err)
+	_, err = s3.ImportWallets(data, []byte("pwd"), wallet.ImportConflictOverwrite, nil)
+	require.Error(t, err)
+	_, err = s3.GetWallet("t2.wlt")
+	require.Equal(t, wallet.ErrWalletNotExist, err)
+
+	result, err = s3.ImportWallets(data, []byte("pwd"), wallet.ImportConflictSkip, nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"t1.wlt"}, result.Skipped)
+	require.Len(t, result.Imported, 1)
+	require.Equal(t, "t2.wlt", result.Imported[0].Filename())
+
+	// Only the notes of the imported wallets are returned
+	require.Equal(t, map[string]string{
+		"a4d39b8a8b9ac5e5ccdde3a4bb52d5e3b2a5bbcd9df5ab5ea5f9d6ff36ee3b53": "salary",
+	}, result.Notes)
+
+	// No temporary files are left in the wallet directory
+	tmpFiles, err := filepath.Glob(filepath.Join(dir3, "*.tmp"))
+	require.NoError(t, err)
+	require.Empty(t, tmpFiles)
+}
+
 func TestServiceEncryptWallet(t *testing.T) {
 	tt := []struct {
 		name             string
@@ -2867,6 +3527,12 @@ func TestServiceScanAddresses(t *testing.T) {
 		password := []byte("pwd")
 		var encrypt bool
 		var tt testCases
+		// Bip44 wallets keep scanning until scanN addresses after the last active one are unused
+		haveTwoScanOneAddrs := addrs[1:2]
+		if walletType == wallet.WalletTypeBip44 {
+			haveTwoScanOneAddrs = addrs[1:3]
+		}
+
 		switch walletType {
 		case wallet.WalletTypeXPub, wallet.WalletTypeBip44:
 			password = []byte("")
@@ -2982,7 +3648,7 @@ func TestServiceScanAddresses(t *testing.T) {
 				expectErr:   nil,
 			},
 			{
-				name: "have 2 scan 1 get 1 unencrypted",
+				name: "have 2 scan 1 unencrypted",
 				opts: wallet.Options{
 					Type:  walletType,
 					Label: "label",
@@ -2994,11 +3660,11 @@ func TestServiceScanAddresses(t *testing.T) {
 					},
 				},
 				scanN:       1,
-				expectAddrs: addrs[1:2],
+				expectAddrs: haveTwoScanOneAddrs,
 				expectErr:   nil,
 			},
 			{
-				name: "have 2 scan 1 get 1 encrypted",
+				name: "have 2 scan 1 encrypted",
 				opts: wallet.Options{
 					Type:     walletType,
 					Label:    "label",
@@ -3013,7 +3679,7 @@ func TestServiceScanAddresses(t *testing.T) {
 				},
 				scanN:       1,
 				password:    password,
-				expectAddrs: addrs[1:2],
+				expectAddrs: haveTwoScanOneAddrs,
 				expectErr:   nil,
 			},
 			{
@@ -4439,3 +5105,289 @@ func combineAddrs(a []cipher.Address, b []cipher.Address) []cipher.Address {
 	copy(addrs[len(a):], b[:])
 	return addrs
 }
+
+func TestServiceSpendingPolicy(t *testing.T) {
+	for _, ct := range crypto.TypesInsecure() {
+		t.Run(fmt.Sprintf("crypto=%v", ct), func(t *testing.T) {
+			dir := prepareWltDir()
+			defer os.RemoveAll(dir)
+
+			cfg := wallet.Config{
+				WalletDir:       dir,
+				CryptoType:      ct,
+				EnableWalletAPI: true,
+			}
+			s, err := wallet.NewService(cfg)
+			require.NoError(t, err)
+
+			_, err = s.CreateWallet("t.wlt", wallet.Options{
+				Seed:       bip39.MustNewDefaultMnemonic(),
+				Label:      "t",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: ct,
+				Type:       wallet.WalletTypeDeterministic,
+			})
+			require.NoError(t, err)
+
+			_, err = s.CreateWallet("unencrypted.wlt", wallet.Options{
+				Seed:  bip39.MustNewDefaultMnemonic(),
+				Label: "unencrypted",
+				Type:  wallet.WalletTypeDeterministic,
+			})
+			require.NoError(t, err)
+
+			dest := testutil.MakeAddress()
+			policy := &wallet.SpendingPolicy{
+				MaxCoinsPerTransaction: 2e6,
+				MaxCoinsPerDay:         3e6,
+				AllowedDestinations:    []wallet.PolicyDestination{{Address: dest}},
+			}
+
+			_, err = s.SetSpendingPolicy("unencrypted.wlt", nil, policy)
+			require.Equal(t, wallet.ErrWalletNotEncrypted, err)
+
+			_, err = s.SetSpendingPolicy("t.wlt", []byte("wrong"), policy)
+			require.Equal(t, wallet.ErrInvalidPassword, err)
+
+			_, err = s.SetSpendingPolicy("t.wlt", []byte("pwd"), &wallet.SpendingPolicy{
+				DestinationCooldown: 60,
+			})
+			require.Equal(t, wallet.NewError(errors.New("destination cooldown requires allowed destinations")), err)
+
+			_, err = s.SetSpendingPolicy("missing.wlt", []byte("pwd"), policy)
+			require.Equal(t, wallet.ErrWalletNotExist, err)
+
+			p, err := s.SetSpendingPolicy("t.wlt", []byte("pwd"), policy)
+			require.NoError(t, err)
+			require.NotZero(t, p.AllowedDestinations[0].AddedAt)
+			require.Zero(t, policy.AllowedDestinations[0].AddedAt)
+
+			p2, spends, err := s.GetSpendingPolicy("t.wlt")
+			require.NoError(t, err)
+			require.Equal(t, p, p2)
+			require.Empty(t, spends)
+
+			spend := func(coins uint64, authorize bool, fErr error) error {
+				return s.ViewSecretsToSpend("t.wlt", []byte("pwd"), func(w wallet.Wallet, authorizeSpend func(wallet.Spend) error) error {
+					require.False(t, w.IsEncrypted())
+					if authorize {
+						if err := authorizeSpend(wallet.Spend{
+							Coins:        coins,
+							Destinations: []cipher.Address{dest},
+						}); err != nil {
+							return err
+						}
+					}
+					return fErr
+				})
+			}
+
+			require.NoError(t, spend(2e6, true, nil))
+			require.Equal(t, wallet.ErrPolicyTransactionLimit, spend(2e6+1, true, nil))
+			require.Equal(t, wallet.ErrPolicyDailyLimit, spend(1e6+1, true, nil))
+
+			// The spend is not recorded if signing failed
+			require.EqualError(t, spend(1e6, true, errors.New("sign failed")), "sign failed")
+
+			// The policy must be checked
+			require.Equal(t, wallet.ErrSpendNotAuthorized, spend(1e6, false, nil))
+
+			err = s.ViewSecretsToSpend("t.wlt", []byte("pwd"), func(w wallet.Wallet, authorize func(wallet.Spend) error) error {
+				return authorize(wallet.Spend{
+					Coins:        1,
+					Destinations: []cipher.Address{testutil.MakeAddress()},
+				})
+			})
+			require.Equal(t, wallet.ErrPolicyDestinationNotAllowed, err)
+
+			// The policy and the spends are saved in the wallet file
+			s2, err := wallet.NewService(cfg)
+			require.NoError(t, err)
+			p2, spends, err = s2.GetSpendingPolicy("t.wlt")
+			require.NoError(t, err)
+			require.Equal(t, p, p2)
+			require.Len(t, spends, 1)
+			require.Equal(t, uint64(2e6), spends[0].Coins)
+
+			// Replacing the policy keeps the spends and the time the destinations were added
+			p3, err := s.SetSpendingPolicy("t.wlt", []byte("pwd"), &wallet.SpendingPolicy{
+				MaxCoinsPerDay:      3e6,
+				AllowedDestinations: []wallet.PolicyDestination{{Address: dest}},
+			})
+			require.NoError(t, err)
+			require.Equal(t, p.AllowedDestinations, p3.AllowedDestinations)
+			require.Equal(t, wallet.ErrPolicyDailyLimit, spend(1e6+1, true, nil))
+			require.NoError(t, spend(1e6, true, nil))
+
+			// A wallet with a spending policy can't be decrypted
+			_, err = s.DecryptWallet("t.wlt", []byte("pwd"))
+			require.Equal(t, wallet.ErrWalletHasSpendingPolicy, err)
+
+			// Remove the policy
+			p, err = s.SetSpendingPolicy("t.wlt", []byte("pwd"), nil)
+			require.NoError(t, err)
+			require.Nil(t, p)
+
+			p, spends, err = s.GetSpendingPolicy("t.wlt")
+			require.NoError(t, err)
+			require.Nil(t, p)
+			require.Empty(t, spends)
+
+			require.NoError(t, spend(10e6, true, nil))
+			require.NoError(t, spend(10e6, false, nil))
+
+			// Wallets without a policy are opened like ViewSecrets
+			err = s.ViewSecretsToSpend("unencrypted.wlt", []byte("pwd"), func(wallet.Wallet, func(wallet.Spend) error) error {
+				return nil
+			})
+			require.Equal(t, wallet.ErrWalletNotEncrypted, err)
+
+			s.SetEnableWalletAPI(false)
+			_, err = s.SetSpendingPolicy("t.wlt", []byte("pwd"), policy)
+			require.Equal(t, wallet.ErrWalletAPIDisabled, err)
+			_, _, err = s.GetSpendingPolicy("t.wlt")
+			require.Equal(t, wallet.ErrWalletAPIDisabled, err)
+			require.Equal(t, wallet.ErrWalletAPIDisabled, spend(1, true, nil))
+		})
+	}
+}
+
+func TestServiceUnlockWallet(t *testing.T) {
+	for _, wltType := range []string{wallet.WalletTypeDeterministic, wallet.WalletTypeBip44} {
+		t.Run(wltType, func(t *testing.T) {
+			dir := prepareWltDir()
+			defer os.RemoveAll(dir)
+
+			s, err := wallet.NewService(wallet.Config{
+				WalletDir:       dir,
+				CryptoType:      crypto.CryptoTypeSha256Xor,
+				EnableWalletAPI: true,
+			})
+			require.NoError(t, err)
+
+			_, err = s.CreateWallet("t.wlt", wallet.Options{
+				Seed:       bip39.MustNewDefaultMnemonic(),
+				Label:      "t",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: crypto.CryptoTypeSha256Xor,
+				Type:       wltType,
+			})
+			require.NoError(t, err)
+
+			_, err = s.CreateWallet("unencrypted.wlt", wallet.Options{
+				Seed:  bip39.MustNewDefaultMnemonic(),
+				Label: "unencrypted",
+				Type:  wltType,
+			})
+			require.NoError(t, err)
+
+			_, err = s.UnlockWallet("t.wlt", []byte("pwd"), 0)
+			require.Equal(t, wallet.ErrInvalidUnlockTimeout, err)
+			_, err = s.UnlockWallet("t.wlt", []byte("pwd"), wallet.MaxUnlockTimeout+time.Second)
+			require.Equal(t, wallet.ErrInvalidUnlockTimeout, err)
+			_, err = s.UnlockWallet("unencrypted.wlt", []byte("pwd"), time.Minute)
+			require.Equal(t, wallet.ErrWalletNotEncrypted, err)
+			_, err = s.UnlockWallet("missing.wlt", []byte("pwd"), time.Minute)
+			require.Equal(t, wallet.ErrWalletNotExist, err)
+			_, err = s.UnlockWallet("t.wlt", nil, time.Minute)
+			require.Equal(t, wallet.ErrMissingPassword, err)
+			_, err = s.UnlockWallet("t.wlt", []byte("wrong"), time.Minute)
+			require.Equal(t, wallet.ErrInvalidPassword, err)
+
+			start := time.Now()
+			session, err := s.UnlockWallet("t.wlt", []byte("pwd"), time.Minute)
+			require.NoError(t, err)
+			require.Equal(t, "t.wlt", session.WalletID)
+			require.Len(t, session.Token, 64)
+			require.False(t, session.ExpiresAt.Before(start.Add(time.Minute)))
+			token := []byte(session.Token)
+
+			// The token generates addresses and opens the secrets of new addresses
+			addrs, err := s.NewAddresses("t.wlt", token, wallet.OptionGenerateN(2))
+			require.NoError(t, err)
+			require.Len(t, addrs, 2)
+
+			w, err := s.GetWallet("t.wlt")
+			require.NoError(t, err)
+			require.True(t, w.IsEncrypted())
+			entries, err := wallet.AllEntries(w)
+			require.NoError(t, err)
+			for _, e := range entries {
+				require.True(t, e.Secret.Null())
+			}
+
+			viewSecrets := func(token []byte) error {
+				return s.ViewSecrets("t.wlt", token, func(w wallet.Wallet) error {
+					require.False(t, w.IsEncrypted())
+					entries, err := wallet.AllEntries(w)
+					require.NoError(t, err)
+					has := make(map[cipher.Address]bool, len(entries))
+					for _, e := range entries {
+						require.False(t, e.Secret.Null())
+						has[e.SkycoinAddress()] = true
+					}
+					for _, a := range addrs {
+						require.True(t, has[a])
+					}
+					return nil
+				})
+			}
+			require.NoError(t, viewSecrets(token))
+
+			// The password still works
+			require.NoError(t, viewSecrets([]byte("pwd")))
+
+			// Addresses generated with the password are visible to the session
+			newAddrs, err := s.NewAddresses("t.wlt", []byte("pwd"))
+			require.NoError(t, err)
+			addrs = append(addrs, newAddrs...)
+			require.NoError(t, viewSecrets(token))
+
+			// The token is bound to the wallet
+			_, err = s.UnlockWallet("unencrypted.wlt", nil, time.Minute)
+			require.Equal(t, wallet.ErrWalletNotEncrypted, err)
+			_, err = s.CreateWallet("t2.wlt", wallet.Options{
+				Seed:       bip39.MustNewDefaultMnemonic(),
+				Label:      "t2",
+				Encrypt:    true,
+				Password:   []byte("pwd"),
+				CryptoType: crypto.CryptoTypeSha256Xor,
+				Type:       wltType,
+			})
+			require.NoError(t, err)
+			require.Equal(t, wallet.ErrInvalidPassword, s.ViewSecrets("t2.wlt", token, func(wallet.Wallet) error {
+				return nil
+			}))
+
+			// Unlocking again replaces the session
+			session2, err := s.UnlockWallet("t.wlt", []byte("pwd"), time.Minute)
+			require.NoError(t, err)
+			require.NotEqual(t, session.Token, session2.Token)
+			require.Equal(t, wallet.ErrInvalidPassword, viewSecrets(token))
+			token = []byte(session2.Token)
+			require.NoError(t, viewSecrets(token))
+
+			require.Equal(t, wallet.ErrWalletNotExist, s.LockWallet("missing.wlt"))
+			require.NoError(t, s.LockWallet("t.wlt"))
+			require.Equal(t, wallet.ErrInvalidPassword, viewSecrets(token))
+			_, err = s.NewAddresses("t.wlt", token)
+			if wltType == wallet.WalletTypeBip44 {
+				// Bip44 wallets generate external addresses without the password
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, wallet.ErrInvalidPassword, err)
+			}
+
+			// Locking a wallet that is not unlocked does nothing
+			require.NoError(t, s.LockWallet("t.wlt"))
+
+			// The session expires
+			session, err = s.UnlockWallet("t.wlt", []byte("pwd"), 10*time.Millisecond)
+			require.NoError(t, err)
+			time.Sleep(50 * time.Millisecond)
+			require.Equal(t, wallet.ErrInvalidPassword, viewSecrets([]byte(session.Token)))
+		})
+	}
+}
@@ -0,0 +1,301 @@
+package wallet
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/skycoin/skycoin/src/cipher"
+)
+
+const (
+	// DefaultUnlockTimeout is the default duration of an unlock session
+	DefaultUnlockTimeout = 5 * time.Minute
+	// MaxUnlockTimeout is the maximum duration of an unlock session
+	MaxUnlockTimeout = time.Hour
+
+	// unlockTokenSize is the number of random bytes of an unlock session token
+	unlockTokenSize = 32
+)
+
+var (
+	// ErrInvalidUnlockTimeout is returned when unlocking a wallet with a timeout out of range
+	ErrInvalidUnlockTimeout = NewError(fmt.Errorf("unlock timeout must be positive and at most %s", MaxUnlockTimeout))
+)
+
+// UnlockSession is an unlock session of an encrypted wallet.
+// The token can be used in place of the wallet password until the session expires or is locked.
+type UnlockSession struct {
+	WalletID  string
+	Token     string
+	ExpiresAt time.Time
+}
+
+// unlockSession keeps the decrypted secrets of a wallet in memory
+type unlockSession struct {
+	token []byte
+	// password is kept to encrypt the secrets created within the session
+	password []byte
+	// wallet is an unlocked copy of the wallet
+	wallet Wallet
+	// secrets and entries are the encrypted secrets and the entry count of
+	// the locked wallet that the unlocked copy was made from
+	secrets string
+	entries int
+	expires time.Time
+	timer   *time.Timer
+}
+
+// erase wipes the secrets of the session
+func (s *unlockSession) erase() {
+	if s.wallet != nil {
+		s.wallet.Erase()
+	}
+	for i := range s.password {
+		s.password[i] = 0
+	}
+	for i := range s.token {
+		s.token[i] = 0
+	}
+}
+
+// matches returns true if the session is a session of w that was unlocked from its current secrets
+func (s *unlockSession) matches(w Wallet) (bool, error) {
+	if s.secrets != w.Secrets() {
+		return false, nil
+	}
+
+	entries, err := AllEntries(w)
+	if err != nil {
+		return false, err
+	}
+	return s.entries == len(entries), nil
+}
+
+// reset unlocks w with the password of the session and replaces the unlocked copy of the session
+func (s *unlockSession) reset(w Wallet) error {
+	// Unlocking a bip44 wallet may encrypt the secrets of its new addresses
+	secrets := w.Secrets()
+
+	uw, err := w.Unlock(s.password)
+	if err != nil {
+		return err
+	}
+
+	s.setWallet(secrets, uw)
+	return nil
+}
+
+// setWallet replaces the unlocked copy of the session with uw, unlocked from the encrypted secrets
+func (s *unlockSession) setWallet(secrets string, uw Wallet) {
+	if s.wallet != nil {
+		s.wallet.Erase()
+	}
+
+	s.wallet = uw
+	s.secrets = secrets
+	s.entries = 0
+	if entries, err := AllEntries(uw); err == nil {
+		s.entries = len(entries)
+	}
+}
+
+// UnlockWallet decrypts the secrets of an encrypted wallet and keeps them in memory until the
+// session expires after timeout, or is ended by LockWallet. The token of the returned session can
+// be used in place of the wallet password to generate addresses and sign transactions.
+// Unlocking a wallet again replaces its previous session.
+func (serv *Service) UnlockWallet(wltID string, password []byte, timeout time.Duration) (*UnlockSession, error) {
+	defer observeOperation("UnlockWallet", time.Now())
+
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return nil, ErrWalletAPIDisabled
+	}
+
+	if timeout <= 0 || timeout > MaxUnlockTimeout {
+		return nil, ErrInvalidUnlockTimeout
+	}
+
+	w, err := serv.getWallet(wltID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !w.IsEncrypted() {
+		return nil, ErrWalletNotEncrypted
+	}
+
+	if len(password) == 0 {
+		return nil, ErrMissingPassword
+	}
+
+	s := &unlockSession{
+		token:    cipher.RandByte(unlockTokenSize),
+		password: append([]byte(nil), password...),
+		expires:  time.Now().Add(timeout),
+	}
+	if err := s.reset(w); err != nil {
+		s.erase()
+		return nil, err
+	}
+
+	token := hex.EncodeToString(s.token)
+
+	serv.sessionsMu.Lock()
+	defer serv.sessionsMu.Unlock()
+	serv.endSession(wltID, serv.sessions[wltID])
+	serv.sessions[wltID] = s
+
+	// Erases the secrets when the session expires
+	s.timer = time.AfterFunc(timeout, func() {
+		serv.sessionsMu.Lock()
+		defer serv.sessionsMu.Unlock()
+		serv.endSession(wltID, s)
+	})
+
+	logger.WithField("wallet", wltID).Infof("Wallet unlocked for %s", timeout)
+
+	return &UnlockSession{
+		WalletID:  wltID,
+		Token:     token,
+		ExpiresAt: s.expires,
+	}, nil
+}
+
+// LockWallet ends the unlock session of a wallet and erases its secrets from memory.
+// It does nothing if the wallet is not unlocked.
+func (serv *Service) LockWallet(wltID string) error {
+	defer observeOperation("LockWallet", time.Now())
+
+	serv.RLock()
+	defer serv.RUnlock()
+	if !serv.config.EnableWalletAPI {
+		return ErrWalletAPIDisabled
+	}
+
+	if _, err := serv.getWallet(wltID); err != nil {
+		return err
+	}
+
+	serv.sessionsMu.Lock()
+	defer serv.sessionsMu.Unlock()
+	serv.endSession(wltID, serv.sessions[wltID])
+	return nil
+}
+
+// endSession erases the session s of a wallet if it is the current session of the wallet.
+// sessionsMu must be held.
+func (serv *Service) endSession(wltID string, s *unlockSession) {
+	if s == nil || serv.sessions[wltID] != s {
+		return
+	}
+
+	s.timer.Stop()
+	s.erase()
+	delete(serv.sessions, wltID)
+}
+
+// endAllSessions erases all unlock sessions
+func (serv *Service) endAllSessions() {
+	serv.sessionsMu.Lock()
+	defer serv.sessionsMu.Unlock()
+	for wltID, s := range serv.sessions {
+		serv.endSession(wltID, s)
+	}
+}
+
+// unlockedCopy returns a copy of the unlocked wallet of the session of w if password is
+// the token of the session, or nil if it is not. Callers must erase the copy.
+// If the wallet changed since it was unlocked, the session unlocks it again with the password
+// of the session.
+func (serv *Service) unlockedCopy(w Wallet, password []byte) (Wallet, error) {
+	if len(password) != hex.EncodedLen(unlockTokenSize) {
+		return nil, nil
+	}
+
+	token, err := hex.DecodeString(string(password))
+	if err != nil {
+		return nil, nil
+	}
+
+	serv.sessionsMu.Lock()
+	defer serv.sessionsMu.Unlock()
+
+	wltID := w.Filename()
+	s := serv.sessions[wltID]
+	if s == nil || subtle.ConstantTimeCompare(s.token, token) != 1 {
+		return nil, nil
+	}
+
+	if time.Now().After(s.expires) {
+		serv.endSession(wltID, s)
+		return nil, nil
+	}
+
+	ok, err := s.matches(w)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := s.reset(w); err != nil {
+			// The wallet was replaced or its password was changed
+			serv.endSession(wltID, s)
+			return nil, err
+		}
+	}
+
+	return s.wallet.Clone(), nil
+}
+
+// guardView is GuardView, accepting the token of the unlock session of w in place of its password
+func (serv *Service) guardView(w Wallet, password []byte, f func(Wallet) error) error {
+	uw, err := serv.unlockedCopy(w, password)
+	if err != nil {
+		return err
+	}
+	if uw == nil {
+		return GuardView(w, password, f)
+	}
+
+	defer uw.Erase()
+	return f(uw)
+}
+
+// guardUpdate is GuardUpdate, accepting the token of the unlock session of w in place of its password
+func (serv *Service) guardUpdate(w Wallet, password []byte, f func(Wallet) error) error {
+	uw, err := serv.unlockedCopy(w, password)
+	if err != nil {
+		return err
+	}
+	if uw == nil {
+		return GuardUpdate(w, password, f)
+	}
+
+	defer uw.Erase()
+	if err := f(uw); err != nil {
+		return err
+	}
+
+	serv.sessionsMu.Lock()
+	defer serv.sessionsMu.Unlock()
+
+	s := serv.sessions[w.Filename()]
+	if s == nil {
+		// The session expired while f was running
+		return ErrInvalidPassword
+	}
+
+	// Keeps the updated secrets in the session
+	sw := uw.Clone()
+
+	if err := uw.Lock(s.password); err != nil {
+		sw.Erase()
+		return err
+	}
+
+	w.CopyFromRef(uw)
+	s.setWallet(w.Secrets(), sw)
+	return nil
+}
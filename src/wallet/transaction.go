@@ -197,15 +197,16 @@ func SignTransaction(w Wallet, txn *coin.Transaction, signIndexes []int, uxOuts
 //     if the coinhour cost of adding that output is less than the coinhours that would be lost as change
 // If receiving hours are not explicitly specified, hours are allocated amongst the receiving outputs proportional to the number of coins being sent to them.
 // If the change address is not specified, the address whose bytes are lexically sorted first is chosen from the owners of the outputs being spent.
+// For bip44 wallets, the account that owns the outputs can be selected with OptionAccount.
 // WARNING: This method is not concurrent-safe if operating on the same wallet. Use Service.View or Service.ViewSecrets to lock the wallet, or use your own lock.
-func CreateTransaction(w Wallet, p transaction.Params, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []transaction.UxBalance, error) {
+func CreateTransaction(w Wallet, p transaction.Params, auxs coin.AddressUxOuts, headTime uint64, options ...Option) (*coin.Transaction, []transaction.UxBalance, error) {
 	if err := p.Validate(); err != nil {
 		return nil, nil, err
 	}
 
 	// Check that auxs does not contain addresses that are not known to this wallet
 	for a := range auxs {
-		has, err := w.HasEntry(a)
+		has, err := w.HasEntry(a, options...)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -227,12 +228,24 @@ func CreateTransaction(w Wallet, p transaction.Params, auxs coin.AddressUxOuts,
 // CreateTransactionSigned creates and signs a transaction based upon transaction.Params.
 // Set the password as nil if the wallet is not encrypted, otherwise the password must be provided.
 // Refer to CreateTransaction for information about transaction creation.
-func CreateTransactionSigned(w Wallet, p transaction.Params, auxs coin.AddressUxOuts, headTime uint64) (*coin.Transaction, []transaction.UxBalance, error) {
-	txn, uxb, err := CreateTransaction(w, p, auxs, headTime)
+func CreateTransactionSigned(w Wallet, p transaction.Params, auxs coin.AddressUxOuts, headTime uint64, options ...Option) (*coin.Transaction, []transaction.UxBalance, error) {
+	return CreateTransactionAuthorized(w, p, auxs, headTime, nil, options...)
+}
+
+// CreateTransactionAuthorized creates a transaction like CreateTransactionSigned, but calls authorize with
+// the unsigned transaction and the outputs it spends before signing it. The transaction is not signed if authorize returns an error.
+func CreateTransactionAuthorized(w Wallet, p transaction.Params, auxs coin.AddressUxOuts, headTime uint64, authorize func(*coin.Transaction, []transaction.UxBalance) error, options ...Option) (*coin.Transaction, []transaction.UxBalance, error) {
+	txn, uxb, err := CreateTransaction(w, p, auxs, headTime, options...)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	if authorize != nil {
+		if err := authorize(txn, uxb); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	logger.Infof("CreateTransactionSigned: signing %d inputs", len(uxb))
 
 	// Sign the transaction
@@ -241,7 +254,7 @@ func CreateTransactionSigned(w Wallet, p transaction.Params, auxs coin.AddressUx
 		entry, ok := entriesMap[s.Address]
 		if !ok {
 			var err error
-			entry, err = w.GetEntry(s.Address)
+			entry, err = w.GetEntry(s.Address, options...)
 			if err == ErrEntryNotFound {
 				// This should not occur because CreateTransaction should have checked it already
 				err := fmt.Errorf("Chosen spend address %s not found in wallet", s.Address)
@@ -15,6 +15,7 @@ package wallet
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -24,6 +25,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
+
 	"github.com/skycoin/skycoin/src/cipher"
 	"github.com/skycoin/skycoin/src/cipher/bip44"
 	"github.com/skycoin/skycoin/src/cipher/crypto"
@@ -101,6 +104,20 @@ var (
 	ErrWalletPermission = NewError(errors.New("saving wallet permission denied"))
 	// ErrInvalidPrivateKeys is returned when creating a collection wallet with invalid private keys
 	ErrInvalidPrivateKeys = NewError(errors.New("invalid private keys"))
+	// ErrWalletNotBip44 is returned when an account operation is requested on a wallet that is not a bip44 wallet
+	ErrWalletNotBip44 = NewError(errors.New("wallet is not a bip44 wallet"))
+	// ErrBip44AccountNotFound is returned when the bip44 account does not exist in the wallet
+	ErrBip44AccountNotFound = NewError(errors.New("bip44 account not found"))
+	// ErrMissingAccountName is returned when creating or renaming a bip44 account without a name
+	ErrMissingAccountName = NewError(errors.New("missing account name"))
+	// ErrWalletNoChangeChain is returned when requesting a change address from a wallet that has no change chain
+	ErrWalletNoChangeChain = NewError(errors.New("wallet has no change chain"))
+	// ErrAddressNotInWallet is returned when an address does not belong to the wallet
+	ErrAddressNotInWallet = NewError(errors.New("address not found in wallet"))
+	// ErrInvalidEntryTag is returned when an address tag is empty or contains a comma
+	ErrInvalidEntryTag = NewError(errors.New("address tags must be non-empty and must not contain a comma"))
+	// ErrDuplicateEntryTag is returned when an address has the same tag more than once
+	ErrDuplicateEntryTag = NewError(errors.New("duplicate address tag"))
 
 	// ErrEntryNotFound is returned by GetEntry is the wallet does not contains the entry
 	ErrEntryNotFound = errors.New("entry not found")
@@ -244,6 +261,9 @@ type Wallet interface {
 	// for bip44 wallet, if no options are specified, it will check the external chain of account
 	// of index 0.
 	HasEntry(addr cipher.Addresser, options ...Option) (bool, error)
+	// SetEntryMeta sets the label, tags and note of the entry of given address,
+	// for bip44 wallet, the entries of all accounts and chains are searched.
+	SetEntryMeta(addr cipher.Addresser, m EntryMeta) error
 	// EntriesLen returns the entries length
 	// for bip44 wallet, if no options are specified, the length of the entries on external chain of account
 	// with index 0 will be returned.
@@ -258,6 +278,14 @@ type Wallet interface {
 	IsTemp() bool
 	// SetTemp sets wallet temporary flag
 	SetTemp(temp bool)
+	// SpendingPolicy returns the spending policy, nil if the wallet has no spending policy
+	SpendingPolicy() (*SpendingPolicy, error)
+	// SetSpendingPolicy sets the spending policy, a nil policy removes it
+	SetSpendingPolicy(p *SpendingPolicy) error
+	// PolicySpends returns the spends recorded by the spending policy
+	PolicySpends() ([]PolicySpend, error)
+	// SetPolicySpends sets the spends recorded by the spending policy
+	SetPolicySpends(spends []PolicySpend) error
 }
 
 // Decoder is the interface that wraps the Encode and Decode methods.
@@ -287,6 +315,96 @@ type Bip44Account struct {
 	Index uint32
 }
 
+// Bip44AccountManager is implemented by wallets that can create and rename bip44 accounts
+type Bip44AccountManager interface {
+	// NewAccount creates an account, the wallet seed must be available
+	NewAccount(name string) (uint32, error)
+	// SetAccountName renames the account of given index
+	SetAccountName(index uint32, name string) error
+}
+
+// ChangeAddressPeeker is implemented by wallets that have a change chain, such as bip44 wallets
+type ChangeAddressPeeker interface {
+	// PeekChangeAddress returns an unused address on the change chain,
+	// generating a new one if the last change address has been used
+	PeekChangeAddress(tf TransactionsFinder, options ...Option) (cipher.Addresser, error)
+}
+
+// ValidateBip44Account returns ErrWalletNotBip44 if the wallet is not a bip44 wallet,
+// or ErrBip44AccountNotFound if the wallet has no account of the given index
+func ValidateBip44Account(w Wallet, account uint32) error {
+	if w.Type() != WalletTypeBip44 {
+		return ErrWalletNotBip44
+	}
+
+	for _, a := range w.Accounts() {
+		if a.Index == account {
+			return nil
+		}
+	}
+
+	return ErrBip44AccountNotFound
+}
+
+// AllAddresses returns the addresses of a wallet, including all accounts of bip44 wallets
+func AllAddresses(w Wallet) ([]cipher.Address, error) {
+	if w.Type() != WalletTypeBip44 {
+		addrs, err := w.GetAddresses()
+		if err != nil {
+			return nil, err
+		}
+		return SkycoinAddresses(addrs), nil
+	}
+
+	var addrs []cipher.Address
+	for _, a := range w.Accounts() {
+		accountAddrs, err := w.GetAddresses(OptionAccount(a.Index))
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, SkycoinAddresses(accountAddrs)...)
+	}
+
+	return addrs, nil
+}
+
+// AllChangeAddresses returns the change chain addresses of all accounts of a bip44 wallet.
+// Wallets of other types have no change chain, and return no addresses.
+func AllChangeAddresses(w Wallet) ([]cipher.Address, error) {
+	if w.Type() != WalletTypeBip44 {
+		return nil, nil
+	}
+
+	var addrs []cipher.Address
+	for _, a := range w.Accounts() {
+		accountAddrs, err := w.GetAddresses(OptionAccount(a.Index), OptionChange())
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, SkycoinAddresses(accountAddrs)...)
+	}
+
+	return addrs, nil
+}
+
+// AllEntries returns the entries of a wallet, including the external and change chains of all accounts of bip44 wallets
+func AllEntries(w Wallet) (Entries, error) {
+	if w.Type() != WalletTypeBip44 {
+		return w.GetEntries()
+	}
+
+	var entries Entries
+	for _, a := range w.Accounts() {
+		accountEntries, err := w.GetEntries(OptionAccount(a.Index), OptionExternal(), OptionChange())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, accountEntries...)
+	}
+
+	return entries, nil
+}
+
 // GuardUpdate executes a function within the context of a read-write managed decrypted wallet.
 // Returns ErrWalletNotEncrypted if wallet is not encrypted.
 func GuardUpdate(w Wallet, password []byte, fn func(w Wallet) error) error {
@@ -352,6 +470,8 @@ type walletLoadMeta struct {
 }
 
 // Save saves the wallet to a directory. The wallet's filename is read from its metadata.
+// A wallet file of an older Version, that Load only migrated in memory, is kept in its
+// migration backup before it is rewritten at the current Version.
 func Save(w Wallet, dir string) error {
 	if w.IsTemp() {
 		return nil
@@ -361,10 +481,55 @@ func Save(w Wallet, dir string) error {
 	if err != nil {
 		return err
 	}
-	return file.SaveBinary(filepath.Join(dir, w.Filename()), data, 0600)
+
+	filename := filepath.Join(dir, w.Filename())
+	if err := backupOlderVersion(filename); err != nil {
+		return err
+	}
+
+	return file.SaveBinary(filename, data, 0600)
+}
+
+// backupOlderVersion copies a wallet file of an older Version to the file named by
+// MigrationBackupFilename, unless the file doesn't exist or the backup already exists
+func backupOlderVersion(filename string) error {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return nil
+	}
+
+	m, err := loadWalletMeta(filename)
+	if err != nil {
+		return err
+	}
+
+	if m.Meta.Version == "" || m.Meta.Version == Version {
+		return nil
+	}
+
+	backup := MigrationBackupFilename(filename, m.Meta.Version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	logger.WithFields(logrus.Fields{
+		"filename": filename,
+		"version":  m.Meta.Version,
+		"backup":   backup,
+	}).Info("Save: backing up wallet file of an older version")
+
+	return file.SaveBinary(backup, data, 0600)
 }
 
-// Load loads wallet from a file
+// Load loads wallet from a file. Wallet files of older versions are migrated to the current
+// Version in memory, use MigrateFile to upgrade the file itself. Otherwise Save keeps the
+// file in its migration backup before rewriting it.
 func Load(filename string) (Wallet, error) {
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		return nil, fmt.Errorf("wallet %q doesn't exist", filename)
@@ -382,27 +547,50 @@ func Load(filename string) (Wallet, error) {
 		return nil, err
 	}
 
-	// Depending on the wallet type in the wallet metadata header, load the full wallet data
-	l, ok := getLoader(m.Meta.Type)
-	if !ok {
-		logger.Errorf("wallet loader for type of %q not found", m.Meta.Type)
-		return nil, nil
-	}
-
 	data, err := ioutil.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
 
-	w, err := l.Load(data)
+	if m.Meta.Version != "" && m.Meta.Version != Version {
+		data, _, err = migrateData(data)
+		if err != nil {
+			logger.WithError(err).WithField("filename", filename).Error("Load: migrateData failed")
+			return nil, err
+		}
+	}
+
+	w, err := loadData(data)
 	if err != nil {
 		return nil, err
 	}
 
+	if w == nil {
+		return nil, nil
+	}
+
 	w.SetFilename(filepath.Base(filename))
 	return w, nil
 }
 
+// loadData loads a wallet from the data of a wallet file.
+// Returns nil if there is no Loader for the wallet type.
+func loadData(data []byte) (Wallet, error) {
+	var m walletLoadMeta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	// Depending on the wallet type in the wallet metadata header, load the full wallet data
+	l, ok := getLoader(m.Meta.Type)
+	if !ok {
+		logger.Errorf("wallet loader for type of %q not found", m.Meta.Type)
+		return nil, nil
+	}
+
+	return l.Load(data)
+}
+
 // removeBackupFiles removes any *.wlt.bak files whom have version 0.1 and *.wlt matched in the given directory
 func removeBackupFiles(dir string) error {
 	fs, err := filterDir(dir, ".wlt")
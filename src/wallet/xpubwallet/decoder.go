@@ -85,6 +85,11 @@ func (es readableXPubEntries) toXPubEntries(ad wallet.AddressDecoder) (wallet.En
 			Address:     addr,
 			Public:      p,
 			ChildNumber: e.ChildNumber,
+			EntryMeta: wallet.EntryMeta{
+				Label: e.Label,
+				Tags:  e.Tags,
+				Note:  e.Note,
+			},
 		}
 	}
 
@@ -99,6 +104,9 @@ func newReadableEntries(entries wallet.Entries) readableXPubEntries {
 			Address:     e.Address.String(),
 			Public:      e.Public.Hex(),
 			ChildNumber: e.ChildNumber,
+			Label:       e.Label,
+			Tags:        e.Tags,
+			Note:        e.Note,
 		}
 	}
 
@@ -106,7 +114,10 @@ func newReadableEntries(entries wallet.Entries) readableXPubEntries {
 }
 
 type readableXPubEntry struct {
-	Address     string `json:"address"`
-	Public      string `json:"public"`
-	ChildNumber uint32 `json:"child_number"` // For bip32/bip44
+	Address     string   `json:"address"`
+	Public      string   `json:"public"`
+	ChildNumber uint32   `json:"child_number"` // For bip32/bip44
+	Label       string   `json:"label,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Note        string   `json:"note,omitempty"`
 }
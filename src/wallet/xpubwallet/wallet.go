@@ -310,11 +310,16 @@ func (w *Wallet) ScanAddresses(scanN uint64, tf wallet.TransactionsFinder) ([]ci
 		}
 	}
 
+	// Keep the metadata of the existing entries
+	metas := w2.entries.Metas()
+
 	w2.reset()
 	if _, err := w2.GenerateAddresses(wallet.OptionGenerateN(nExistingAddrs + keepNum)); err != nil {
 		return nil, err
 	}
 
+	w2.entries.SetMetas(metas)
+
 	*w = *w2
 
 	return addrs[:keepNum], nil
@@ -396,6 +401,14 @@ func (w *Wallet) HasEntry(addr cipher.Addresser, _ ...wallet.Option) (bool, erro
 	return w.entries.Has(addr), nil
 }
 
+// SetEntryMeta sets the label, tags and note of the entry of given address
+func (w *Wallet) SetEntryMeta(a cipher.Addresser, m wallet.EntryMeta) error {
+	if !w.entries.SetMeta(a, m) {
+		return wallet.ErrEntryNotFound
+	}
+	return nil
+}
+
 // EntriesLen returns the number of entries in the wallet
 func (w *Wallet) EntriesLen(_ ...wallet.Option) (int, error) {
 	return len(w.entries), nil
@@ -458,3 +458,40 @@ func TestScanAddresses(t *testing.T) {
 		})
 	}
 }
+
+func TestWalletSetEntryMeta(t *testing.T) {
+	w, err := NewWallet("test.wlt", "test", testXPub, wallet.OptionGenerateN(2))
+	require.NoError(t, err)
+
+	addr := testSkycoinAddresses[1]
+	m := wallet.EntryMeta{
+		Label: "alice",
+		Tags:  []string{"customer"},
+		Note:  "watch only",
+	}
+	require.NoError(t, w.SetEntryMeta(addr, m))
+	require.Equal(t, wallet.ErrEntryNotFound, w.SetEntryMeta(testSkycoinAddresses[2], m))
+
+	e, err := w.GetEntry(addr)
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	// The metadata survives serialization
+	b, err := w.Serialize()
+	require.NoError(t, err)
+	w2 := &Wallet{}
+	require.NoError(t, w2.Deserialize(b))
+	e, err = w2.GetEntry(addr)
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+
+	// The metadata survives scanning for new addresses
+	_, err = w.ScanAddresses(5, mockTxnsFinder{testSkycoinAddresses[4]: true})
+	require.NoError(t, err)
+	n, err := w.EntriesLen()
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	e, err = w.GetEntry(addr)
+	require.NoError(t, err)
+	require.Equal(t, m, e.EntryMeta)
+}